@@ -17,6 +17,10 @@ const (
 	// determine download speeds.
 	SpeedEstimationWindow = 60 * time.Second
 
+	// hostPricingChangesVerbose is the number of recent price changes shown by
+	// `siac host -v`.
+	hostPricingChangesVerbose = 10
+
 	// moduleNotReadyStatus is the error message displayed when an API call error
 	// suggests that a modules is not yet ready for usage.
 	moduleNotReadyStatus = "Module not loaded or still starting up"
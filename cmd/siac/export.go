@@ -1,9 +1,12 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -25,6 +28,14 @@ var (
 			"file. Intended for upload to `https://rankings.sia.tech/`.",
 		Run: wrap(renterexportcontracttxnscmd),
 	}
+
+	renterExportSpendingCmd = &cobra.Command{
+		Use:   "spending [destination]",
+		Short: "export the renter's contract spending ledger as CSV",
+		Long: "Export the entries of the renter's contract spending ledger in CSV format to the " +
+			"specified file. Use the --start, --end and --host flags to limit the exported entries.",
+		Run: wrap(renterexportspendingcmd),
+	}
 )
 
 // renterexportcontracttxnscmd is the handler for the command `siac renter export contract-txns`.
@@ -49,3 +60,50 @@ func renterexportcontracttxnscmd(destination string) {
 	}
 	fmt.Println("Exported contract data to", destination)
 }
+
+// renterexportspendingcmd is the handler for the command `siac renter export
+// spending`. Exports the contract spending ledger to CSV.
+func renterexportspendingcmd(destination string) {
+	var host types.SiaPublicKey
+	if renterExportSpendingHost != "" {
+		host.LoadString(renterExportSpendingHost)
+		if host.Key == nil {
+			die("Invalid host public key:", renterExportSpendingHost)
+		}
+	}
+	end := types.EndOfTime
+	if renterExportSpendingEnd != 0 {
+		end = time.Unix(renterExportSpendingEnd, 0)
+	}
+	rcl, err := httpClient.RenterContractsLedgerGet(time.Unix(renterExportSpendingStart, 0), end, host)
+	if err != nil {
+		die("Could not retrieve spending ledger:", err)
+	}
+
+	destination = abs(destination)
+	file, err := os.Create(destination)
+	if err != nil {
+		die("Could not export to file:", err)
+	}
+	w := csv.NewWriter(file)
+	records := [][]string{{"timestamp", "contractid", "hostpublickey", "category", "amount", "ephemeralaccount"}}
+	for _, entry := range rcl.Entries {
+		records = append(records, []string{
+			time.Unix(entry.Timestamp, 0).UTC().Format(time.RFC3339),
+			entry.ContractID.String(),
+			entry.HostPublicKey.String(),
+			string(entry.Category),
+			entry.Amount.String(),
+			strconv.FormatBool(entry.EphemeralAccount),
+		})
+	}
+	err = w.WriteAll(records)
+	if err != nil {
+		die("Could not export to file:", err)
+	}
+	err = file.Close()
+	if err != nil {
+		die("Could not export to file:", err)
+	}
+	fmt.Printf("Exported %v spending ledger entries to %v\n", len(rcl.Entries), destination)
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,6 +20,33 @@ import (
 )
 
 var (
+	hostAccountsCmd = &cobra.Command{
+		Use:   "accounts",
+		Short: "Show the host's ephemeral accounts",
+		Long: `Show the ephemeral accounts on the host, sorted by balance, along with the
+host's aggregate risk. Pending withdrawals are withdrawals that are blocked,
+either because the balance is insufficient or because the host reached its
+maximum ephemeral account risk.`,
+		Run: wrap(hostaccountscmd),
+	}
+
+	hostAccountsRefundCmd = &cobra.Command{
+		Use:   "refund [id] [amount]",
+		Short: "Refund money to an ephemeral account",
+		Long: `Deposit money into an ephemeral account, ignoring the maximum ephemeral
+account balance. If the account doesn't exist, it is opened.
+
+For example: siac host accounts refund ed25519:<key> 100mS`,
+		Run: wrap(hostaccountsrefundcmd),
+	}
+
+	hostAccountsZeroCmd = &cobra.Command{
+		Use:   "zero [id]",
+		Short: "Set the balance of an ephemeral account to zero",
+		Long:  "Set the balance of an ephemeral account to zero and print the balance it had before.",
+		Run:   wrap(hostaccountszerocmd),
+	}
+
 	hostAnnounceCmd = &cobra.Command{
 		Use:   "announce",
 		Short: "Announce yourself as a host",
@@ -39,6 +67,15 @@ Doing so will override the standard connectivity checks.`,
 		Run:   wrap(hostcmd),
 	}
 
+	hostClientsCmd = &cobra.Command{
+		Use:   "clients",
+		Short: "Show the host's top clients",
+		Long: `Show the clients of the host that used the most bandwidth since startup.
+Clients are identified by the ephemeral account or the contract they pay with.
+Per-client quotas can be configured with 'siac host config'.`,
+		Run: wrap(hostclientscmd),
+	}
+
 	hostConfigCmd = &cobra.Command{
 		Use:   "config [setting] [value]",
 		Short: "Modify host settings",
@@ -70,6 +107,15 @@ Available settings:
      registrysize:       filesize
      customregistrypath: string
 
+     sectorscrubrate: bytes / second
+
+     maxclientbandwidth:         bytes / second
+     maxclientprogramsperminute: int
+     maxclientstreams:           int
+
+     mdmtracefailures:   boolean
+     mdmtracesamplerate: float
+
 Currency units can be specified, e.g. 10SC; run 'siac help wallet' for details.
 
 Durations (maxduration and windowsize) must be specified in either blocks (b),
@@ -80,6 +126,17 @@ Timeouts (ephemeralaccountexpiry) must be specified in either seconds (s),
 hours (h), days (d), or weeks (w). One hour is 3600 seconds, a day is 86400
 seconds, and a week is 604800 seconds.
 
+Rates (sectorscrubrate and maxclientbandwidth) must be specified with a unit,
+e.g. 4MB/s. A rate of 0 disables the sector scrubber.
+
+The per-client quotas (maxclientbandwidth, maxclientprogramsperminute and
+maxclientstreams) limit the resources a single renter or ephemeral account can
+use. A value of 0 means no limit.
+
+The MDM execution traces of the host (siac host traces) include the fraction
+of programs given by mdmtracesamplerate, e.g. 0.01 for 1%. If mdmtracefailures
+is set, the traces of all failed programs are kept as well.
+
 For a description of each parameter, see doc/API.md.
 
 To configure the host to accept new contracts, set acceptingcontracts to true:
@@ -100,6 +157,45 @@ Available output types:
 		Run: wrap(hostcontractcmd),
 	}
 
+	hostContractForecastCmd = &cobra.Command{
+		Use:   "forecast",
+		Short: "Show a forecast of the host's storage proofs",
+		Long: `Show the proof windows of the next blocks together with the expected
+revenue, the collateral at risk and the wallet balance required to submit the
+storage proofs. Proof windows during which the wallet is expected to be
+underfunded are flagged.`,
+		Run: wrap(hostcontractforecastcmd),
+	}
+
+	hostDecommissionCmd = &cobra.Command{
+		Use:   "decommission",
+		Short: "Decommission the host",
+		Long: `Put the host into decommission mode. The host stops forming and renewing
+contracts but keeps submitting storage proofs for its existing contracts.
+Storage folders are removed from the host once the sectors stored in them
+expired. Use 'siac host decommission status' to find out when the host can be
+shut down without losing collateral.`,
+		Run: wrap(hostdecommissioncmd),
+	}
+
+	hostDecommissionCancelCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel the decommissioning of the host",
+		Long: `Cancel the decommissioning of the host. The host starts forming and renewing
+contracts again if it is configured to accept contracts. Storage folders that
+were already removed are not added back.`,
+		Run: wrap(hostdecommissioncancelcmd),
+	}
+
+	hostDecommissionStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the progress of the host's decommissioning",
+		Long: `Show the storage obligations the host still needs to submit storage proofs
+for, the earliest height at which the host can be shut down safely and the
+obligations the host can't prove because of a problem with a storage folder.`,
+		Run: wrap(hostdecommissionstatuscmd),
+	}
+
 	hostFolderAddCmd = &cobra.Command{
 		Use:   "add [path] [size]",
 		Short: "Add a storage folder to the host",
@@ -107,10 +203,38 @@ Available output types:
 		Run:   wrap(hostfolderaddcmd),
 	}
 
+	hostFolderCacheCmd = &cobra.Command{
+		Use:   "cache [path] [size]",
+		Short: "Add or remove the host's cache folder",
+		Long: `Designate a folder on a fast disk, e.g. an SSD, as the host's cache folder.
+Frequently read sectors are copied into the cache folder and served from there,
+while the authoritative copies remain in the storage folders. The cache folder
+can be removed with the --remove flag without losing any data.
+
+For example: siac host folder cache /mnt/ssd 100GB`,
+		Run: hostfoldercachecmd,
+	}
+
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
-		Short: "Add, remove, or resize a storage folder",
-		Long:  "Add, remove, or resize a storage folder.",
+		Short: "Add, remove, resize, rebalance, or cache storage folders",
+		Long:  "Add, remove, resize, rebalance, or cache storage folders.",
+	}
+
+	hostFolderRebalanceCmd = &cobra.Command{
+		Use:   "rebalance [path] [path]...",
+		Short: "Migrate sectors between storage folders",
+		Long: `Migrate sectors between the storage folders in the background. If no paths
+are provided, sectors are moved from the most utilised to the least utilised
+folders until the utilisation of all folders is even. Otherwise all sectors are
+moved out of the provided folders, which stay online but don't receive any new
+sectors until the rebalance is complete.
+
+The rate at which sectors are moved can be limited with the --rate flag, e.g.
+--rate 10MB/s. An ongoing rebalance can be stopped with the --cancel flag.
+
+For example: siac host folder rebalance /mnt/olddisk --rate 20MB/s`,
+		Run: hostfolderrebalancecmd,
 	}
 
 	hostFolderRemoveCmd = &cobra.Command{
@@ -131,6 +255,87 @@ other storage folders.`,
 		Run: wrap(hostfolderresizecmd),
 	}
 
+	hostMetricsCmd = &cobra.Command{
+		Use:   "metrics",
+		Short: "Show the host's RPC and instruction metrics",
+		Long: `Show the number of calls, errors, the bandwidth and the average latency of
+the RPCs and MDM instructions the host handled since startup.`,
+		Run: wrap(hostmetricscmd),
+	}
+
+	hostPricingCmd = &cobra.Command{
+		Use:   "pricing [setting] [value]",
+		Short: "View or modify the host's pricing policy",
+		Long: `View or modify the policy of the host's dynamic pricing engine. Without
+arguments, the policy and the most recent price changes are shown.
+
+If enabled, the pricing engine recomputes the host's storage and bandwidth
+prices on every block. The base price is the fiat target converted with the
+exchange rate, the median price of the hosts on the network, or the average of
+both. It is scaled with the utilisation of the host's storage or bandwidth and
+kept between the floor and the ceiling. A ceiling of 0 means no ceiling.
+
+Available settings:
+     enabled:      boolean
+     exchangerate: string, e.g. "0.01 USD"
+     maxbandwidth: bytes / second
+
+     targetstorageprice:           fiat / TB / Month
+     targetdownloadbandwidthprice: fiat / TB
+     targetuploadbandwidthprice:   fiat / TB
+
+     storagepricefloor:             currency / TB / Month
+     storagepriceceiling:           currency / TB / Month
+     downloadbandwidthpricefloor:   currency / TB
+     downloadbandwidthpriceceiling: currency / TB
+     uploadbandwidthpricefloor:     currency / TB
+     uploadbandwidthpriceceiling:   currency / TB
+
+For example: siac host pricing storagepricefloor 50SC`,
+		Run: hostpricingcmd,
+	}
+
+	hostRegistryCmd = &cobra.Command{
+		Use:   "registry",
+		Short: "Show the entries of the host's registry",
+		Long: `Show the entries of the host's registry, sorted by their entry id. Use
+--offset and --numentries to page through the registry.`,
+		Run: wrap(hostregistrycmd),
+	}
+
+	hostRegistryEntryCmd = &cobra.Command{
+		Use:   "entry [entryid] | [publickey] [tweak]",
+		Short: "Show a single entry of the host's registry",
+		Long: `Show a single entry of the host's registry, identified either by its entry id
+or by its public key and tweak.`,
+		Run: hostregistryentrycmd,
+	}
+
+	hostRegistryExportCmd = &cobra.Command{
+		Use:   "export [path]",
+		Short: "Export the host's registry to a file",
+		Long: `Export the entries of the host's registry to a new file. The file only
+contains the entries in use and can be imported by another host with
+'siac host registry import', e.g. when migrating a host.`,
+		Run: wrap(hostregistryexportcmd),
+	}
+
+	hostRegistryImportCmd = &cobra.Command{
+		Use:   "import [path]",
+		Short: "Import the entries of an exported registry",
+		Long: `Import the entries of a file created by 'siac host registry export' into the
+host's registry. Expired entries and entries for which the host already knows a
+more recent revision are skipped.`,
+		Run: wrap(hostregistryimportcmd),
+	}
+
+	hostRegistryPruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the expired entries of the host's registry",
+		Long:  "Delete the expired entries of the host's registry to free up space.",
+		Run:   wrap(hostregistryprunecmd),
+	}
+
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
 		Short: "Add or delete a sector (add not supported)",
@@ -145,6 +350,15 @@ deleting a sector may impact host revenue.`,
 sector may impact host revenue.`,
 		Run: wrap(hostsectordeletecmd),
 	}
+
+	hostTracesCmd = &cobra.Command{
+		Use:   "traces",
+		Short: "Show the host's MDM execution traces",
+		Long: `Show the most recent execution traces of MDM programs, starting with the
+most recent one. Which programs are traced can be configured with the
+mdmtracesamplerate and mdmtracefailures settings of 'siac host config'.`,
+		Run: wrap(hosttracescmd),
+	}
 )
 
 // hostcmd is the handler for the command `siac host`.
@@ -238,6 +452,15 @@ Host Internal Settings:
 	registrysize:       %v
 	customregistrypath: %v
 
+	sectorscrubrate: %v
+
+	maxclientbandwidth:         %v
+	maxclientprogramsperminute: %v
+	maxclientstreams:           %v
+
+	mdmtracefailures:   %v
+	mdmtracesamplerate: %v
+
 Host Financials:
 	Contract Count:               %v
 	Transaction Fee Compensation: %v
@@ -292,6 +515,15 @@ RPC Stats:
 			modules.FilesizeUnits(is.RegistrySize),
 			is.CustomRegistryPath,
 
+			ratelimitUnits(int64(is.SectorScrubRate)),
+
+			ratelimitUnits(int64(is.MaxClientBandwidth)),
+			is.MaxClientProgramsPerMinute,
+			is.MaxClientStreams,
+
+			yesNo(is.MDMTraceFailures),
+			is.MDMTraceSampleRate,
+
 			fm.ContractCount, currencyUnits(fm.ContractCompensation),
 			currencyUnits(fm.PotentialContractCompensation),
 			currencyUnits(fm.TransactionFeeExpenses),
@@ -311,6 +543,13 @@ RPC Stats:
 			nm.ErrorCalls, nm.UnrecognizedCalls, nm.DownloadCalls,
 			nm.RenewCalls, nm.ReviseCalls, nm.SettingsCalls,
 			nm.FormContractCalls)
+
+		hpg, err := httpClient.HostPricingGet()
+		if err != nil {
+			die("Could not fetch host pricing policy:", err)
+		}
+		fmt.Println()
+		printHostPricing(hpg, hostPricingChangesVerbose)
 	} else {
 		fmt.Printf(`Host info:
 	Connectability Status: %v
@@ -344,6 +583,14 @@ RPC Stats:
 		fmt.Println("\nWarning:\n	Your wallet is locked. You must unlock your wallet for the host to function properly.")
 	}
 
+	// if the host is decommissioning print when it can be shut down
+	if ds := hg.DecommissionStatus; ds.Active {
+		fmt.Printf("\nDecommissioning:\n	The host is decommissioning and can be shut down safely at height %v.\n", ds.EarliestSafeShutdownHeight)
+		if len(ds.UnprovableObligations) > 0 {
+			fmt.Printf("	%v obligations can't be proven. Run 'siac host decommission status' for details.\n", len(ds.UnprovableObligations))
+		}
+	}
+
 	fmt.Println("\nStorage Folders:")
 
 	// display storage folder info
@@ -355,11 +602,121 @@ RPC Stats:
 		return
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
-	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\tPath\n")
+	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\t%% Scrubbed\tCorrupt\tPath\n")
 	for _, folder := range sg.Folders {
 		curSize := int64(folder.Capacity - folder.CapacityRemaining)
 		pctUsed := 100 * (float64(curSize) / float64(folder.Capacity))
-		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", modules.FilesizeUnits(uint64(curSize)), modules.FilesizeUnits(folder.Capacity), pctUsed, folder.Path)
+		path := folder.Path
+		if folder.Evacuating {
+			path += " (evacuating)"
+		}
+		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%.2f\t%v\t%s\n", modules.FilesizeUnits(uint64(curSize)), modules.FilesizeUnits(folder.Capacity), pctUsed, 100*folder.ScrubProgress, folder.CorruptSectors, path)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+
+	// display cache folder info
+	if sg.Cache.Path == "" {
+		return
+	}
+	fmt.Println("\nCache Folder:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\t%% Hit Rate\tHits\tMisses\tPath\n")
+	cacheSize := sg.Cache.Capacity - sg.Cache.CapacityRemaining
+	pctUsed := 100 * (float64(cacheSize) / float64(sg.Cache.Capacity))
+	fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%.2f\t%v\t%v\t%s\n", modules.FilesizeUnits(cacheSize), modules.FilesizeUnits(sg.Cache.Capacity), pctUsed, 100*sg.Cache.HitRate, sg.Cache.Hits, sg.Cache.Misses, sg.Cache.Path)
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// hostaccountscmd is the handler for the command `siac host accounts`. Prints
+// the host's ephemeral accounts and its aggregate risk.
+func hostaccountscmd() {
+	hag, err := httpClient.HostAccountsGet()
+	if err != nil {
+		die("Could not fetch host accounts:", err)
+	}
+	fmt.Printf(`Ephemeral Accounts:
+	Accounts:            %v
+	Total Balance:       %v
+	Max Balance:         %v
+	Current Risk:        %v
+	Max Risk:            %v
+	Blocked Deposits:    %v
+	Blocked Withdrawals: %v
+`, len(hag.Accounts), currencyUnits(hag.TotalBalance), currencyUnits(hag.MaxBalance),
+		currencyUnits(hag.CurrentRisk), currencyUnits(hag.MaxRisk), hag.BlockedDeposits, hag.BlockedWithdrawals)
+	if len(hag.Accounts) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "ID\tBalance\tPending Risk\tPending Withdrawals\tLast Used\n")
+	for _, a := range hag.Accounts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v (%v)\t%v\n", a.ID, currencyUnits(a.Balance), currencyUnits(a.PendingRisk),
+			a.PendingWithdrawals, currencyUnits(a.PendingWithdrawalsValue), a.LastUsed.Format(time.RFC822))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// hostaccountsrefundcmd is the handler for the command `siac host accounts
+// refund [id] [amount]`. Deposits money into an ephemeral account.
+func hostaccountsrefundcmd(idStr, amountStr string) {
+	var id modules.AccountID
+	err := id.LoadString(idStr)
+	if err != nil {
+		die("Could not parse account id:", err)
+	}
+	hastings, err := types.ParseCurrency(amountStr)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var amount types.Currency
+	if _, err := fmt.Sscan(hastings, &amount); err != nil {
+		die("Could not parse amount:", err)
+	}
+	err = httpClient.HostAccountsRefundPost(id, amount)
+	if err != nil {
+		die("Could not refund account:", err)
+	}
+	fmt.Printf("Refunded %v to account %v\n", currencyUnits(amount), idStr)
+}
+
+// hostaccountszerocmd is the handler for the command `siac host accounts zero
+// [id]`. Sets the balance of an ephemeral account to zero.
+func hostaccountszerocmd(idStr string) {
+	var id modules.AccountID
+	err := id.LoadString(idStr)
+	if err != nil {
+		die("Could not parse account id:", err)
+	}
+	hazp, err := httpClient.HostAccountsZeroPost(id)
+	if err != nil {
+		die("Could not zero account:", err)
+	}
+	fmt.Printf("Zeroed account %v, its balance was %v\n", idStr, currencyUnits(hazp.Balance))
+}
+
+// hostclientscmd is the handler for the command `siac host clients`. Prints
+// the host's clients that used the most bandwidth.
+func hostclientscmd() {
+	hcg, err := httpClient.HostClientsGet(hostClientsNum)
+	if err != nil {
+		die("Could not fetch host clients:", err)
+	}
+	if len(hcg.Clients) == 0 {
+		fmt.Println("No clients")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Type\tPublic Key\tActive Streams\tStreams\tPrograms\tDownload\tUpload\tRejections\tLast Seen\n")
+	for _, c := range hcg.Clients {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", c.Type, c.PublicKey, c.ActiveStreams, c.Streams, c.Programs,
+			modules.FilesizeUnits(c.Download), modules.FilesizeUnits(c.Upload), c.Rejections, c.LastSeen.Format(time.RFC822))
 	}
 	if err := w.Flush(); err != nil {
 		die("failed to flush writer")
@@ -399,7 +756,7 @@ func hostconfigcmd(param, value string) {
 		value = c.String()
 
 	// bool (allow "yes" and "no")
-	case "acceptingcontracts":
+	case "acceptingcontracts", "mdmtracefailures":
 		switch strings.ToLower(value) {
 		case "yes":
 			value = "true"
@@ -421,6 +778,14 @@ func hostconfigcmd(param, value string) {
 			die("Could not parse "+param+":", err)
 		}
 
+	// rate (convert to bytes per second)
+	case "maxclientbandwidth", "sectorscrubrate":
+		rate, err := parseRatelimit(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		value = fmt.Sprint(rate)
+
 	// timeout (convert to seconds)
 	case "ephemeralaccountexpiry":
 		value, err = parseTimeout(value)
@@ -429,7 +794,7 @@ func hostconfigcmd(param, value string) {
 		}
 
 	// other valid settings
-	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress", "customregistrypath":
+	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress", "customregistrypath", "maxclientprogramsperminute", "maxclientstreams", "mdmtracesamplerate":
 
 	// invalid settings
 	default:
@@ -453,6 +818,173 @@ func hostconfigcmd(param, value string) {
 	fmt.Printf("Estimated conversion rate: %v%%\n", eg.ConversionRate)
 }
 
+// hostmetricscmd is the handler for the command `siac host metrics`. Prints
+// the metrics of the RPCs and MDM instructions handled by the host.
+func hostmetricscmd() {
+	hmg, err := httpClient.HostMetricsGet()
+	if err != nil {
+		die("Could not fetch host metrics:", err)
+	}
+	// avgLatency returns the average latency of a histogram.
+	avgLatency := func(lh modules.HostLatencyHistogram) time.Duration {
+		if lh.Count == 0 {
+			return 0
+		}
+		return lh.Sum / time.Duration(lh.Count)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "RPC\tCalls\tErrors\tReceived\tSent\tAvg Latency\n")
+	for _, m := range hmg.RPCs {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", m.Name, m.Calls, m.Errors,
+			modules.FilesizeUnits(m.BytesIn), modules.FilesizeUnits(m.BytesOut), avgLatency(m.Latency))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Instruction\tExecutions\tErrors\tAvg Latency\n")
+	for _, m := range hmg.Instructions {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", m.Name, m.Executions, m.Errors, avgLatency(m.Latency))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// hostpricingcmd is the handler for the command `siac host pricing [setting]
+// [value]`. Shows or modifies the host's pricing policy.
+func hostpricingcmd(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 0:
+		hpg, err := httpClient.HostPricingGet()
+		if err != nil {
+			die("Could not fetch host pricing policy:", err)
+		}
+		printHostPricing(hpg, len(hpg.Changes))
+		return
+	case 2:
+	default:
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+
+	param, value := args[0], args[1]
+	switch param {
+	// currency/TB (convert to hastings/byte)
+	case "downloadbandwidthpricefloor", "downloadbandwidthpriceceiling", "uploadbandwidthpricefloor", "uploadbandwidthpriceceiling":
+		hastings, err := types.ParseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		value = types.NewCurrency(i).Div(modules.BytesPerTerabyte).String()
+
+	// currency/TB/month (convert to hastings/byte/block)
+	case "storagepricefloor", "storagepriceceiling":
+		hastings, err := types.ParseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		value = types.NewCurrency(i).Div(modules.BlockBytesPerMonthTerabyte).String()
+
+	// bool (allow "yes" and "no")
+	case "enabled":
+		switch strings.ToLower(value) {
+		case "yes":
+			value = "true"
+		case "no":
+			value = "false"
+		}
+
+	// rate (convert to bytes per second)
+	case "maxbandwidth":
+		rate, err := parseRatelimit(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		value = fmt.Sprint(rate)
+
+	// other valid settings
+	case "exchangerate", "targetstorageprice", "targetdownloadbandwidthprice", "targetuploadbandwidthprice":
+
+	// invalid settings
+	default:
+		die("\"" + param + "\" is not a pricing setting")
+	}
+	err := httpClient.HostPricingModifyPost(param, value)
+	if err != nil {
+		die("Failed to update pricing policy:", err)
+	}
+	fmt.Println("Pricing policy updated.")
+}
+
+// printHostPricing prints the host's pricing policy and up to 'numChanges' of
+// the most recent price changes.
+func printHostPricing(hpg api.HostPricingGET, numChanges int) {
+	p := hpg.Policy
+	ceiling := func(c types.Currency) string {
+		if c.IsZero() {
+			return "none"
+		}
+		return currencyUnits(c)
+	}
+	exchangeRate := p.ExchangeRate
+	if exchangeRate == "" {
+		exchangeRate = "none"
+	}
+	fmt.Printf(`Pricing Engine:
+	enabled:      %v
+	exchangerate: %v
+	maxbandwidth: %v
+
+	targetstorageprice:           %v / TB / Month
+	targetdownloadbandwidthprice: %v / TB
+	targetuploadbandwidthprice:   %v / TB
+
+	storagepricefloor:             %v / TB / Month
+	storagepriceceiling:           %v / TB / Month
+	downloadbandwidthpricefloor:   %v / TB
+	downloadbandwidthpriceceiling: %v / TB
+	uploadbandwidthpricefloor:     %v / TB
+	uploadbandwidthpriceceiling:   %v / TB
+`,
+		yesNo(p.Enabled),
+		exchangeRate,
+		ratelimitUnits(int64(p.MaxBandwidth)),
+
+		p.TargetStoragePrice,
+		p.TargetDownloadBandwidthPrice,
+		p.TargetUploadBandwidthPrice,
+
+		currencyUnits(p.StoragePriceFloor.Mul(modules.BlockBytesPerMonthTerabyte)),
+		ceiling(p.StoragePriceCeiling.Mul(modules.BlockBytesPerMonthTerabyte)),
+		currencyUnits(p.DownloadBandwidthPriceFloor.Mul(modules.BytesPerTerabyte)),
+		ceiling(p.DownloadBandwidthPriceCeiling.Mul(modules.BytesPerTerabyte)),
+		currencyUnits(p.UploadBandwidthPriceFloor.Mul(modules.BytesPerTerabyte)),
+		ceiling(p.UploadBandwidthPriceCeiling.Mul(modules.BytesPerTerabyte)),
+	)
+
+	changes := hpg.Changes
+	if len(changes) == 0 || numChanges <= 0 {
+		return
+	}
+	if len(changes) > numChanges {
+		changes = changes[len(changes)-numChanges:]
+	}
+	fmt.Println("\nPrice Changes:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "\tHeight\tTime\tStorage / TB / Month\tDownload / TB\tUpload / TB\t%% Storage Used\t%% Download Used\t%% Upload Used\n")
+	for _, c := range changes {
+		fmt.Fprintf(w, "\t%v\t%v\t%v\t%v\t%v\t%.2f\t%.2f\t%.2f\n", c.BlockHeight, c.Timestamp.Format(time.RFC822),
+			currencyUnits(c.StoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
+			currencyUnits(c.DownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+			currencyUnits(c.UploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+			100*c.StorageUtilisation, 100*c.DownloadBandwidthUtilisation, 100*c.UploadBandwidthUtilisation)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
 // hostcontractcmd is the handler for the command `siac host contracts [type]`.
 func hostcontractcmd() {
 	cg, err := httpClient.HostContractInfoGet()
@@ -483,6 +1015,118 @@ func hostcontractcmd() {
 	}
 }
 
+// hostdecommissioncmd is the handler for the command `siac host decommission`.
+// It puts the host into decommission mode.
+func hostdecommissioncmd() {
+	err := httpClient.HostDecommissionPost()
+	if err != nil {
+		die("Could not decommission host:", err)
+	}
+	fmt.Println(`The host is decommissioning. To follow the progress, run:
+	siac host decommission status`)
+}
+
+// hostdecommissioncancelcmd is the handler for the command
+// `siac host decommission cancel`.
+func hostdecommissioncancelcmd() {
+	err := httpClient.HostDecommissionCancelPost()
+	if err != nil {
+		die("Could not cancel decommissioning:", err)
+	}
+	fmt.Println("Cancelled decommissioning")
+}
+
+// hostcontractforecastcmd is the handler for the command `siac host contracts
+// forecast`. It prints a forecast of the host's storage proofs.
+func hostcontractforecastcmd() {
+	hcfg, err := httpClient.HostContractForecastGet(types.BlockHeight(hostContractForecastBlocks))
+	if err != nil {
+		die("Could not fetch contract forecast:", err)
+	}
+	fmt.Printf(`Contract forecast for heights %v to %v:
+	Proof Windows:      %v
+	Expected Revenue:   %v
+	Collateral At Risk: %v
+	Required Balance:   %v
+	Wallet Balance:     %v
+`, hcfg.StartHeight, hcfg.EndHeight, len(hcfg.ProofWindows), currencyUnits(hcfg.ExpectedRevenue),
+		currencyUnits(hcfg.CollateralAtRisk), currencyUnits(hcfg.RequiredBalance), currencyUnits(hcfg.WalletBalance))
+	if len(hcfg.ProofWindows) == 0 {
+		return
+	}
+
+	fmt.Println("\nExpected Revenue By Height:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Height\tObligations\tExpected Revenue\tCollateral Released\n")
+	for _, h := range hcfg.Heights {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", h.Height, h.Obligations, currencyUnits(h.ExpectedRevenue), currencyUnits(h.CollateralReleased))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+
+	fmt.Println("\nProof Windows:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Obligation ID\tWindow Start\tWindow End\tExpected Revenue\tLocked Collateral\tRequired Fees\tUnderfunded\n")
+	for _, pw := range hcfg.ProofWindows {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", pw.ObligationID, pw.WindowStart, pw.WindowEnd, currencyUnits(pw.ExpectedRevenue),
+			currencyUnits(pw.LockedCollateral), currencyUnits(pw.RequiredFees), yesNo(pw.Underfunded))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+	if hcfg.UnderfundedObligations > 0 {
+		fmt.Printf("\nWarning: the wallet is expected to be underfunded for %v proof windows. Add\nfunds to the wallet to avoid losing collateral.\n", hcfg.UnderfundedObligations)
+	}
+}
+
+// hostdecommissionstatuscmd is the handler for the command
+// `siac host decommission status`.
+func hostdecommissionstatuscmd() {
+	hdg, err := httpClient.HostDecommissionGet()
+	if err != nil {
+		die("Could not fetch decommission status:", err)
+	}
+	if !hdg.Active {
+		fmt.Println("The host is not decommissioning.")
+		return
+	}
+	cg, err := httpClient.ConsensusGet()
+	if err != nil {
+		die("Could not get current blockheight:", err)
+	}
+	safe := "now"
+	if hdg.EarliestSafeShutdownHeight > cg.Height {
+		blocks := hdg.EarliestSafeShutdownHeight - cg.Height
+		safe = fmt.Sprintf("at height %v (%v blocks, ~%v)", hdg.EarliestSafeShutdownHeight, blocks, time.Duration(blocks*cg.BlockFrequency)*time.Second)
+	}
+	fmt.Printf(`Decommission status:
+	Started At Height:     %v
+	Remaining Obligations: %v
+	Collateral At Risk:    %v
+	Last Proof Deadline:   %v
+	Safe Shutdown:         %v
+	Released Folders:      %v
+`, hdg.StartHeight, hdg.ObligationsRemaining, currencyUnits(hdg.CollateralAtRisk),
+		hdg.LastObligationHeight, safe, len(hdg.ReleasedFolders))
+
+	for _, path := range hdg.ReleasedFolders {
+		fmt.Println("		" + path)
+	}
+	if len(hdg.UnprovableObligations) == 0 {
+		return
+	}
+	fmt.Printf("\nWarning: %v obligations can't be proven. Fix the affected storage folders\nbefore the proof deadline to avoid losing collateral.\n", len(hdg.UnprovableObligations))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Obligation ID\tProof Deadline\tLocked Collateral\tUnprovable Sectors\tError\n")
+	for _, uo := range hdg.UnprovableObligations {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", uo.ObligationID, uo.ProofDeadline, currencyUnits(uo.LockedCollateral), uo.UnprovableSectors, uo.Error)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
 // hostannouncecmd is the handler for the command `siac host announce`.
 // Announces yourself as a host to the network. Optionally takes an address to
 // announce as.
@@ -531,6 +1175,75 @@ func hostfolderaddcmd(path, size string) {
 	fmt.Println("Added folder", path)
 }
 
+// hostfoldercachecmd adds or removes the host's cache folder.
+func hostfoldercachecmd(cmd *cobra.Command, args []string) {
+	if hostFolderCacheRemove {
+		err := httpClient.HostStorageCacheRemovePost()
+		if err != nil {
+			die("Could not remove cache folder:", err)
+		}
+		fmt.Println("Removed cache folder")
+		return
+	}
+	if len(args) != 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+
+	path := args[0]
+	size, err := parseFilesize(args[1])
+	if err != nil {
+		die("Could not parse size:", err)
+	}
+	// round size down to nearest multiple of 256MiB
+	var sizeUint64 uint64
+	fmt.Sscan(size, &sizeUint64)
+	sizeUint64 /= 64 * modules.SectorSize
+	sizeUint64 *= 64 * modules.SectorSize
+
+	err = httpClient.HostStorageCacheAddPost(abs(path), sizeUint64)
+	if err != nil {
+		die("Could not add cache folder:", err)
+	}
+	fmt.Println("Added cache folder", path)
+}
+
+// hostfolderrebalancecmd starts or cancels a rebalance of the host's storage
+// folders.
+func hostfolderrebalancecmd(_ *cobra.Command, paths []string) {
+	if hostFolderRebalanceCancel {
+		err := httpClient.HostStorageFoldersRebalanceCancelPost()
+		if err != nil {
+			die("Could not cancel rebalance:", err)
+		}
+		fmt.Println("Cancelled rebalance")
+		return
+	}
+
+	var rate int64
+	if hostFolderRebalanceRate != "" {
+		var err error
+		rate, err = parseRatelimit(hostFolderRebalanceRate)
+		if err != nil {
+			die("Could not parse rate:", err)
+		}
+	}
+	evacuate := make([]string, 0, len(paths))
+	for _, path := range paths {
+		evacuate = append(evacuate, abs(path))
+	}
+	err := httpClient.HostStorageFoldersRebalancePost(evacuate, uint64(rate))
+	if err != nil {
+		die("Could not start rebalance:", err)
+	}
+	if len(evacuate) == 0 {
+		fmt.Println("Started rebalancing the storage folders")
+	} else {
+		fmt.Println("Started evacuating", strings.Join(paths, ", "))
+	}
+	fmt.Println("Use 'siac host -v' to follow the progress.")
+}
+
 // hostfolderremovecmd removes a folder from the host.
 func hostfolderremovecmd(path string) {
 	// Ask for confirm for dangerous --force flag
@@ -569,6 +1282,97 @@ func hostfolderresizecmd(path, newsize string) {
 	fmt.Printf("Resized folder %v to %v\n", path, newsize)
 }
 
+// hostregistrycmd is the handler for the command `siac host registry`. Prints
+// a page of the entries of the host's registry.
+func hostregistrycmd() {
+	hrg, err := httpClient.HostRegistryGet(hostRegistryOffset, hostRegistryNum)
+	if err != nil {
+		die("Could not fetch host registry:", err)
+	}
+	fmt.Printf("Showing %v of %v entries\n", len(hrg.Entries), hrg.Total)
+	if len(hrg.Entries) == 0 {
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Entry ID\tPublic Key\tTweak\tRevision\tExpiry\n")
+	for _, e := range hrg.Entries {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", e.EntryID, e.PublicKey, e.Tweak, e.Revision, e.Expiry)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// hostregistryentrycmd is the handler for the command `siac host registry
+// entry [entryid] | [publickey] [tweak]`. Prints a single registry entry.
+func hostregistryentrycmd(cmd *cobra.Command, args []string) {
+	var hreg api.HostRegistryEntryGET
+	var err error
+	switch len(args) {
+	case 1:
+		var sid crypto.Hash
+		if err := sid.LoadString(args[0]); err != nil {
+			die("Could not parse entry id:", err)
+		}
+		hreg, err = httpClient.HostRegistryEntryIDGet(modules.RegistryEntryID(sid))
+	case 2:
+		var spk types.SiaPublicKey
+		if err := spk.LoadString(args[0]); err != nil {
+			die("Could not parse public key:", err)
+		}
+		var tweak crypto.Hash
+		if err := tweak.LoadString(args[1]); err != nil {
+			die("Could not parse tweak:", err)
+		}
+		hreg, err = httpClient.HostRegistryEntryGet(spk, tweak)
+	default:
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	if err != nil {
+		die("Could not fetch registry entry:", err)
+	}
+	fmt.Printf(`Entry ID:   %v
+Public Key: %v
+Tweak:      %v
+Revision:   %v
+Expiry:     %v
+Data:       %x
+Signature:  %x
+`, hreg.EntryID, hreg.PublicKey, hreg.Tweak, hreg.Revision, hreg.Expiry, hreg.Data, hreg.Signature)
+}
+
+// hostregistryexportcmd is the handler for the command `siac host registry
+// export [path]`. Exports the host's registry to a file.
+func hostregistryexportcmd(path string) {
+	hrep, err := httpClient.HostRegistryExportPost(abs(path))
+	if err != nil {
+		die("Could not export registry:", err)
+	}
+	fmt.Printf("Exported %v entries to %v\n", hrep.Exported, path)
+}
+
+// hostregistryimportcmd is the handler for the command `siac host registry
+// import [path]`. Imports the entries of an exported registry.
+func hostregistryimportcmd(path string) {
+	hrip, err := httpClient.HostRegistryImportPost(abs(path))
+	if err != nil {
+		die("Could not import registry:", err)
+	}
+	fmt.Printf("Imported %v entries from %v\n", hrip.Imported, path)
+}
+
+// hostregistryprunecmd is the handler for the command `siac host registry
+// prune`. Deletes the expired entries of the host's registry.
+func hostregistryprunecmd() {
+	hrpp, err := httpClient.HostRegistryPrunePost()
+	if err != nil {
+		die("Could not prune registry:", err)
+	}
+	fmt.Printf("Pruned %v expired entries\n", hrpp.Pruned)
+}
+
 // hostsectordeletecmd deletes a sector from the host.
 func hostsectordeletecmd(root string) {
 	var hash crypto.Hash
@@ -582,3 +1386,47 @@ func hostsectordeletecmd(root string) {
 	}
 	fmt.Println("Deleted sector", root)
 }
+
+// hosttracescmd is the handler for the command `siac host traces`. Prints the
+// most recent MDM execution traces of the host.
+func hosttracescmd() {
+	var renterKey *types.SiaPublicKey
+	if hostTracesRenterKey != "" {
+		renterKey = new(types.SiaPublicKey)
+		if err := renterKey.LoadString(hostTracesRenterKey); err != nil {
+			die("Could not parse renter key:", err)
+		}
+	}
+	var account *modules.AccountID
+	if hostTracesAccount != "" {
+		account = new(modules.AccountID)
+		if err := account.LoadString(hostTracesAccount); err != nil {
+			die("Could not parse account:", err)
+		}
+	}
+	hmtg, err := httpClient.HostMDMTracesGet(renterKey, account, hostTracesNum)
+	if err != nil {
+		die("Could not fetch host traces:", err)
+	}
+	if len(hmtg.Traces) == 0 {
+		fmt.Println("No traces")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	for _, t := range hmtg.Traces {
+		fmt.Fprintf(w, "%v\t%v %v\tcontract %v\t%v\t%v\t%v\n", t.Start.Format(time.RFC822), t.ClientType, t.ClientKey,
+			t.ContractID, t.ExecutionTime, currencyUnits(t.Cost), t.Error)
+		for _, it := range t.Instructions {
+			name := it.Name
+			if it.Skipped {
+				name += " (skipped)"
+			}
+			fmt.Fprintf(w, "\t%v\t%v\tread %v, written %v\tcost %v, refund %v\t%v\n", name, it.ExecutionTime,
+				modules.FilesizeUnits(it.BytesRead), modules.FilesizeUnits(it.BytesWritten),
+				currencyUnits(it.Cost), currencyUnits(it.Refund), it.Error)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
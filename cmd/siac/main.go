@@ -13,6 +13,7 @@ import (
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/node/api"
 	"go.sia.tech/siad/node/api/client"
+	"go.sia.tech/siad/types"
 )
 
 var (
@@ -31,21 +32,38 @@ var (
 	daemonTraceProfile     bool   // Indicates that the Trace profile should be started
 
 	// Host Flags
-	hostContractOutputType string // output type for host contracts
-	hostFolderRemoveForce  bool   // force folder remove
+	hostClientsNum             int    // number of clients to display
+	hostContractForecastBlocks uint64 // number of blocks covered by the contract forecast
+	hostContractOutputType     string // output type for host contracts
+	hostFolderCacheRemove      bool   // remove the cache folder
+	hostFolderRebalanceCancel  bool   // cancel an ongoing folder rebalance
+	hostFolderRebalanceRate    string // rate at which sectors are moved by a folder rebalance
+	hostFolderRemoveForce      bool   // force folder remove
+	hostRegistryNum            uint64 // number of registry entries to display
+	hostRegistryOffset         uint64 // index of the first registry entry to display
+	hostTracesAccount          string // ephemeral account to filter the traces by
+	hostTracesNum              int    // number of traces to display
+	hostTracesRenterKey        string // renter key to filter the traces by
 
 	// Renter Flags
 	dataPieces                string // the number of data pieces a file should be uploaded with
 	parityPieces              string // the number of parity pieces a file should be uploaded with
 	renterAllContracts        bool   // Show all active and expired contracts
+	renterBackupKeepDaily     uint64 // Number of days for which a scheduled backup is kept.
+	renterBackupKeepLast      uint64 // Number of most recent scheduled backups to keep.
+	renterBackupKeepWeekly    uint64 // Number of weeks for which a scheduled backup is kept.
 	renterBubbleAll           bool   // Bubble the entire directory tree
 	renterDeleteRoot          bool   // Delete path start from root instead of the UserFolder.
 	renterDownloadAsync       bool   // Downloads files asynchronously
 	renterDownloadRecursive   bool   // Downloads folders recursively.
 	renterDownloadRoot        bool   // Download path start from root instead of the UserFolder.
+	renterExportSpendingEnd   int64  // Unix timestamp of the last spending ledger entry to export.
+	renterExportSpendingHost  string // Host to export the spending ledger entries for.
+	renterExportSpendingStart int64  // Unix timestamp of the first spending ledger entry to export.
 	renterFuseMountAllowOther bool   // Mount fuse with 'AllowOther' set to true.
 	renterListRecursive       bool   // List files of folder recursively.
 	renterListRoot            bool   // List path start from root instead of the UserFolder.
+	renterRecoverSeed         bool   // Initialize the wallet from a seed before recovering the renter.
 	renterRenameRoot          bool   // Rename files relative to root instead of the UserFolder.
 	renterShowHistory         bool   // Show download history in addition to download queue.
 
@@ -84,6 +102,8 @@ var (
 	walletStartHeight    uint64 // Start height for transaction search.
 	walletEndHeight      uint64 // End height for transaction search.
 	walletTxnFeeIncluded bool   // include the fee in the balance being sent
+	walletBumpFee        string // fee per KB used to bump a transaction
+	walletBumpRBF        bool   // replace the transaction instead of spending its change
 	insecureInput        bool   // Insecure password/seed input. Disables the shoulder-surfing and Mac secure input feature.
 )
 
@@ -297,11 +317,25 @@ func initCmds() *cobra.Command {
 	gatewayBlocklistCmd.AddCommand(gatewayBlocklistAppendCmd, gatewayBlocklistClearCmd, gatewayBlocklistRemoveCmd, gatewayBlocklistSetCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostAnnounceCmd, hostConfigCmd, hostContractCmd, hostFolderCmd, hostSectorCmd)
-	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostCmd.AddCommand(hostAccountsCmd, hostAnnounceCmd, hostClientsCmd, hostConfigCmd, hostContractCmd, hostDecommissionCmd, hostFolderCmd, hostMetricsCmd, hostPricingCmd, hostRegistryCmd, hostSectorCmd, hostTracesCmd)
+	hostAccountsCmd.AddCommand(hostAccountsRefundCmd, hostAccountsZeroCmd)
+	hostContractCmd.AddCommand(hostContractForecastCmd)
+	hostDecommissionCmd.AddCommand(hostDecommissionCancelCmd, hostDecommissionStatusCmd)
+	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderCacheCmd, hostFolderRebalanceCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostRegistryCmd.AddCommand(hostRegistryEntryCmd, hostRegistryExportCmd, hostRegistryImportCmd, hostRegistryPruneCmd)
 	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
+	hostClientsCmd.Flags().IntVarP(&hostClientsNum, "numclients", "n", 20, "Number of clients to display, 0 displays all clients")
+	hostContractForecastCmd.Flags().Uint64VarP(&hostContractForecastBlocks, "blocks", "b", uint64(types.BlocksPerMonth), "Number of blocks covered by the forecast")
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostFolderCacheCmd.Flags().BoolVar(&hostFolderCacheRemove, "remove", false, "Remove the cache folder")
+	hostFolderRebalanceCmd.Flags().BoolVar(&hostFolderRebalanceCancel, "cancel", false, "Cancel the ongoing rebalance")
+	hostFolderRebalanceCmd.Flags().StringVar(&hostFolderRebalanceRate, "rate", "", "Maximum rate at which sectors are moved, e.g. 10MB/s")
+	hostRegistryCmd.Flags().Uint64VarP(&hostRegistryNum, "numentries", "n", 20, "Number of entries to display, 0 displays all entries")
+	hostRegistryCmd.Flags().Uint64Var(&hostRegistryOffset, "offset", 0, "Index of the first entry to display")
 	hostFolderRemoveCmd.Flags().BoolVarP(&hostFolderRemoveForce, "force", "f", false, "Force the removal of the folder and its data")
+	hostTracesCmd.Flags().StringVar(&hostTracesAccount, "account", "", "Only show the traces of programs paid for by this ephemeral account")
+	hostTracesCmd.Flags().IntVarP(&hostTracesNum, "numtraces", "n", 20, "Number of traces to display, 0 displays all traces")
+	hostTracesCmd.Flags().StringVar(&hostTracesRenterKey, "renterkey", "", "Only show the traces of programs paid for by the renter with this key")
 
 	root.AddCommand(hostdbCmd)
 	hostdbCmd.AddCommand(hostdbFiltermodeCmd, hostdbSetFiltermodeCmd, hostdbViewCmd)
@@ -312,15 +346,18 @@ func initCmds() *cobra.Command {
 
 	root.AddCommand(renterCmd)
 	renterCmd.AddCommand(renterAllowanceCmd, renterBubbleCmd, renterBackupCreateCmd, renterBackupListCmd, renterBackupLoadCmd,
-		renterCleanCmd, renterContractsCmd, renterContractsRecoveryScanProgressCmd, renterDownloadCancelCmd,
+		renterBackupScheduleCmd, renterCleanCmd, renterContractsCmd, renterContractsRecoveryScanProgressCmd, renterDownloadCancelCmd,
 		renterDownloadsCmd, renterExportCmd, renterFilesDeleteCmd, renterFilesDownloadCmd,
 		renterFilesListCmd, renterFilesRenameCmd, renterFilesUnstuckCmd, renterFilesUploadCmd,
 		renterFuseCmd, renterLostCmd, renterPricesCmd, renterRatelimitCmd, renterSetAllowanceCmd,
-		renterSetLocalPathCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterWorkersCmd,
-		renterHealthSummaryCmd)
-	renterWorkersCmd.AddCommand(renterWorkersAccountsCmd, renterWorkersDownloadsCmd, renterWorkersPriceTableCmd, renterWorkersReadJobsCmd, renterWorkersHasSectorJobSCmd, renterWorkersUploadsCmd, renterWorkersReadRegistryCmd, renterWorkersUpdateRegistryCmd)
+		renterRecoverCmd, renterSetLocalPathCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd,
+		renterWorkersCmd, renterHealthSummaryCmd)
+	renterWorkersCmd.AddCommand(renterWorkersAccountsCmd, renterWorkersDownloadsCmd, renterWorkersEstimatesCmd, renterWorkersPriceTableCmd, renterWorkersReadJobsCmd, renterWorkersHasSectorJobSCmd, renterWorkersUploadsCmd, renterWorkersReadRegistryCmd, renterWorkersUpdateRegistryCmd)
 
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
+	renterBackupScheduleCmd.Flags().Uint64Var(&renterBackupKeepLast, "keep-last", 0, "Number of most recent scheduled backups to keep")
+	renterBackupScheduleCmd.Flags().Uint64Var(&renterBackupKeepDaily, "keep-daily", 0, "Number of days for which the most recent scheduled backup is kept")
+	renterBackupScheduleCmd.Flags().Uint64Var(&renterBackupKeepWeekly, "keep-weekly", 0, "Number of weeks for which the most recent scheduled backup is kept")
 	renterBubbleCmd.Flags().BoolVarP(&renterBubbleAll, "all", "A", false, "Bubble the entire directory tree")
 	renterContractsCmd.AddCommand(renterContractsViewCmd)
 	renterFilesUploadCmd.AddCommand(renterFilesUploadPauseCmd, renterFilesUploadResumeCmd)
@@ -335,7 +372,11 @@ func initCmds() *cobra.Command {
 	renterFilesListCmd.Flags().BoolVar(&renterListRoot, "root", false, "List files and folders from root instead of from the user home directory")
 	renterFilesUploadCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces a files should be uploaded with")
 	renterFilesUploadCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces a files should be uploaded with")
-	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
+	renterExportCmd.AddCommand(renterExportContractTxnsCmd, renterExportSpendingCmd)
+	renterExportSpendingCmd.Flags().Int64Var(&renterExportSpendingStart, "start", 0, "unix timestamp of the earliest entry to export")
+	renterExportSpendingCmd.Flags().Int64Var(&renterExportSpendingEnd, "end", 0, "unix timestamp of the latest entry to export")
+	renterExportSpendingCmd.Flags().StringVar(&renterExportSpendingHost, "host", "", "only export entries for the host with this public key")
+	renterRecoverCmd.Flags().BoolVar(&renterRecoverSeed, "seed", false, "Prompt for a seed to initialize the wallet with before recovering")
 	renterFilesRenameCmd.Flags().BoolVar(&renterRenameRoot, "root", false, "Rename files relative to root instead of the user homedir")
 
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceFunds, "amount", "", "amount of money in allowance, specified in currency units")
@@ -374,13 +415,17 @@ func initCmds() *cobra.Command {
 	utilsVerifySeedCmd.Flags().StringVarP(&dictionaryLanguage, "language", "l", "english", "which dictionary you want to use")
 
 	root.AddCommand(walletCmd)
-	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletBalanceCmd, walletBroadcastCmd, walletChangepasswordCmd,
-		walletInitCmd, walletInitSeedCmd, walletLoadCmd, walletLockCmd, walletSeedsCmd, walletSendCmd,
+	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletBalanceCmd, walletBroadcastCmd, walletBumpCmd, walletChangepasswordCmd,
+		walletInitCmd, walletInitSeedCmd, walletLoadCmd, walletLockCmd, walletMultisigCmd, walletSeedsCmd, walletSendCmd,
 		walletSignCmd, walletSweepCmd, walletTransactionsCmd, walletUnlockCmd)
+	walletBumpCmd.Flags().StringVar(&walletBumpFee, "fee", "", "Fee per KB the transaction should pay, e.g. 10mS")
+	walletBumpCmd.Flags().BoolVar(&walletBumpRBF, "rbf", false, "Replace the transaction instead of spending its change with a child transaction")
 	walletInitCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Prompt for a custom password")
 	walletInitCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet and re-encrypt")
 	walletInitSeedCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
 	walletLoadCmd.AddCommand(walletLoad033xCmd, walletLoadSeedCmd, walletLoadSiagCmd)
+	walletMultisigCmd.AddCommand(walletMultisigAddressCmd, walletMultisigBroadcastCmd, walletMultisigCombineCmd,
+		walletMultisigCreateCmd, walletMultisigPubkeyCmd, walletMultisigSignCmd)
 	walletSendCmd.AddCommand(walletSendSiacoinsCmd, walletSendSiafundsCmd)
 	walletSendSiacoinsCmd.Flags().BoolVarP(&walletTxnFeeIncluded, "fee-included", "", false, "Take the transaction fee out of the balance being submitted instead of the fee being additional")
 	walletUnlockCmd.Flags().BoolVarP(&insecureInput, "insecure-input", "", false, "Disable shoulder-surf protection (echoing passwords and seeds)")
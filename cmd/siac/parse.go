@@ -16,6 +16,7 @@ import (
 	"gitlab.com/NebulousLabs/encoding"
 	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
 )
 
@@ -467,3 +468,19 @@ func sizeString(size uint64) string {
 	}
 	return "0 B"
 }
+
+// parsePartiallySignedTxn decodes a JSON-encoded partially-signed transaction
+// from s, which can be the JSON itself or a path to a file containing it.
+func parsePartiallySignedTxn(s string) (modules.PartiallySignedTransaction, error) {
+	pstBytes, err := ioutil.ReadFile(s)
+	if os.IsNotExist(err) {
+		pstBytes = []byte(s)
+	} else if err != nil {
+		return modules.PartiallySignedTransaction{}, errors.New("could not read transaction file: " + err.Error())
+	}
+	var pst modules.PartiallySignedTransaction
+	if err := json.Unmarshal(pstBytes, &pst); err != nil {
+		return modules.PartiallySignedTransaction{}, errors.New("could not decode JSON transaction: " + err.Error())
+	}
+	return pst, nil
+}
@@ -85,6 +85,19 @@ To bubble the root directory pass in '.' as the directory.`,
 		Run:   wrap(renterbackuplistcmd),
 	}
 
+	renterBackupScheduleCmd = &cobra.Command{
+		Use:   "backupschedule [interval]",
+		Short: "View or set the schedule of automatic backups",
+		Long: `View or set the schedule and retention policy of the renter's automatic
+snapshot backups. The interval can be specified in seconds, hours, days or
+weeks, e.g. "12h" or "1d". An interval of "0s" disables scheduled backups.
+Scheduled backups which are neither one of the --keep-last most recent backups,
+nor the most recent backup of one of the --keep-daily most recent days or the
+--keep-weekly most recent weeks are deleted. If none of the retention flags are
+set, scheduled backups are never deleted.`,
+		Run: renterbackupschedulecmd,
+	}
+
 	renterCleanCmd = &cobra.Command{
 		Use:   "clean",
 		Short: "Cleans up lost files",
@@ -274,6 +287,16 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 		Run: rentersetallowancecmd,
 	}
 
+	renterRecoverCmd = &cobra.Command{
+		Use:   "recover",
+		Short: "Recover the renter from its seed.",
+		Long: `Recover the renter's contracts and files after losing its metadata.
+The blockchain is scanned for contracts that were formed with the wallet's seed
+and the newest backup stored on the recovered hosts is restored. Use --seed to
+initialize the wallet from a seed first.`,
+		Run: wrap(renterrecovercmd),
+	}
+
 	renterTriggerContractRecoveryScanCmd = &cobra.Command{
 		Use:   "triggerrecoveryscan",
 		Short: "Triggers a recovery scan.",
@@ -309,6 +332,15 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 		Run:   wrap(renterworkersdownloadscmd),
 	}
 
+	renterWorkersEstimatesCmd = &cobra.Command{
+		Use:   "estimates",
+		Short: "Compare the renter's program estimates with the hosts",
+		Long: `Have the host of every worker dry run a sample download program and compare
+the host's cost with the renter's local estimate. A mismatch indicates that the
+renter's and the host's pricing logic drifted apart.`,
+		Run: wrap(renterworkersestimatescmd),
+	}
+
 	renterWorkersHasSectorJobSCmd = &cobra.Command{
 		Use:   "hsj",
 		Short: "View the workers' has sector jobs",
@@ -1397,6 +1429,63 @@ func renterbackuplistcmd() {
 	}
 }
 
+// renterbackupschedulecmd is the handler for the command `siac renter
+// backupschedule`.
+func renterbackupschedulecmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	rg, err := httpClient.RenterGet()
+	if err != nil {
+		die("Could not get renter settings:", err)
+	}
+	schedule := rg.Settings.BackupSchedule
+
+	// Print the current schedule if nothing is changed.
+	if len(args) == 0 && cmd.Flags().NFlag() == 0 {
+		if schedule.Interval == 0 {
+			fmt.Println("Scheduled backups are disabled.")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Interval:\t%v\n", schedule.Interval)
+		fmt.Fprintf(w, "Keep Last:\t%v\n", schedule.KeepLast)
+		fmt.Fprintf(w, "Keep Daily:\t%v\n", schedule.KeepDaily)
+		fmt.Fprintf(w, "Keep Weekly:\t%v\n", schedule.KeepWeekly)
+		if err := w.Flush(); err != nil {
+			die("failed to flush writer:", err)
+		}
+		return
+	}
+
+	// Update the schedule.
+	if len(args) == 1 {
+		seconds, err := parseTimeout(args[0])
+		if err != nil {
+			die("Could not parse interval:", err)
+		}
+		var interval uint64
+		if _, err := fmt.Sscan(seconds, &interval); err != nil {
+			die("Could not parse interval:", err)
+		}
+		schedule.Interval = time.Duration(interval) * time.Second
+	}
+	if cmd.Flags().Changed("keep-last") {
+		schedule.KeepLast = renterBackupKeepLast
+	}
+	if cmd.Flags().Changed("keep-daily") {
+		schedule.KeepDaily = renterBackupKeepDaily
+	}
+	if cmd.Flags().Changed("keep-weekly") {
+		schedule.KeepWeekly = renterBackupKeepWeekly
+	}
+	if err := httpClient.RenterSetBackupSchedulePost(schedule); err != nil {
+		die("Could not set backup schedule:", err)
+	}
+	fmt.Println("Backup schedule updated.")
+}
+
 // rentercontractscmd is the handler for the command `siac renter contracts`.
 // It lists the Renter's contracts.
 func rentercontractscmd() {
@@ -1678,6 +1767,73 @@ func rentertriggercontractrecoveryrescancmd() {
 	fmt.Println("Successfully triggered contract recovery scan.")
 }
 
+// renterrecovercmd recovers the renter's contracts from the blockchain and its
+// files from the newest backup stored on its hosts.
+func renterrecovercmd() {
+	if renterRecoverSeed {
+		seed, err := passwordPrompt("Seed: ")
+		if err != nil {
+			die("Reading seed failed:", err)
+		}
+		fmt.Println("Initializing wallet from seed; this may take a while...")
+		if err := httpClient.WalletInitSeedPost(seed, "", false); err != nil {
+			die("Could not initialize wallet from seed:", err)
+		}
+		if err := httpClient.WalletUnlockPost(seed); err != nil {
+			die("Could not unlock wallet:", err)
+		}
+		fmt.Println("Wallet initialized and unlocked.")
+	}
+	if err := httpClient.RenterRecoveryPost(); err != nil {
+		die("Failed to start renter recovery:", err)
+	}
+	fmt.Println("Renter recovery started.")
+
+	// Print the progress until the recovery is done.
+	var last modules.RenterRecoveryStatus
+	for {
+		rrs, err := httpClient.RenterRecoveryGet()
+		if err != nil {
+			die("Failed to get renter recovery status:", err)
+		}
+		if rrs.Stage != last.Stage || rrs.ScannedHeight != last.ScannedHeight || rrs.HostsQueried != last.HostsQueried {
+			switch rrs.Stage {
+			case modules.RecoveryStageScanning:
+				fmt.Printf("Scanning blockchain for contracts: height %v\n", rrs.ScannedHeight)
+			case modules.RecoveryStageWaitingForContracts:
+				fmt.Println("Waiting for recovered contracts...")
+			case modules.RecoveryStageFetchingBackups:
+				fmt.Printf("Fetching backups from hosts: %v/%v\n", rrs.HostsQueried, rrs.Contracts)
+			case modules.RecoveryStageDownloading:
+				fmt.Printf("Downloading backup '%v' created %v\n", rrs.Backup.Name, time.Unix(int64(rrs.Backup.CreationDate), 0))
+			case modules.RecoveryStageRestoring:
+				fmt.Printf("Restoring backup '%v'\n", rrs.Backup.Name)
+			}
+		}
+		last = rrs
+		if rrs.Stage == modules.RecoveryStageDone || rrs.Stage == modules.RecoveryStageFailed {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if last.Stage == modules.RecoveryStageFailed {
+		die("Renter recovery failed:", last.Error)
+	}
+
+	fmt.Printf("Recovered %v contracts and restored %v files from backup '%v'.\n", last.Contracts, last.RestoredFiles, last.Backup.Name)
+	if len(last.FailedFiles) == 0 {
+		return
+	}
+	fmt.Printf("%v files could not be restored:\n", len(last.FailedFiles))
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	for _, ff := range last.FailedFiles {
+		fmt.Fprintf(w, "  %v\t%v\n", ff.SiaPath, ff.Error)
+	}
+	if err := w.Flush(); err != nil {
+		die("Failed to flush writer:", err)
+	}
+}
+
 // rentercontractrecoveryscanprogresscmd returns the current progress of a
 // potentially ongoing recovery scan.
 func rentercontractrecoveryscanprogresscmd() {
@@ -2558,6 +2714,77 @@ func writeWorkers(workers []modules.WorkerStatus) {
 	}
 }
 
+// renterworkersestimatescmd is the handler for the command `siac renter
+// workers estimates`. It compares the renter's local estimate of a sample
+// program with the dry run of every worker's host.
+func renterworkersestimatescmd() {
+	rweg, err := httpClient.RenterWorkersEstimatesGet()
+	if err != nil {
+		die("Could not get program estimates:", err)
+	}
+
+	// Sort estimates by public key.
+	estimates := rweg.Estimates
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].HostPubKey.String() < estimates[j].HostPubKey.String()
+	})
+
+	// Create tab writer
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	defer func() {
+		err := w.Flush()
+		if err != nil {
+			die("Could not flush tabwriter:", err)
+		}
+	}()
+
+	// print summary
+	numMismatches := 0
+	for _, estimate := range estimates {
+		if estimate.Error != "" || !estimate.LocalCost.Equals(estimate.HostCost) || !estimate.LocalCollateral.Equals(estimate.HostCollateral) {
+			numMismatches++
+		}
+	}
+	fmt.Fprintf(w, "Program Estimate Summary \n")
+	fmt.Fprintf(w, "  Total Hosts: \t%v\n", len(estimates))
+	fmt.Fprintf(w, "  Mismatches: \t%v\n", numMismatches)
+
+	// print rows
+	fmt.Fprintln(w, "\nHost PubKey\tLocal Cost\tHost Cost\tLocal Collateral\tHost Collateral\tError")
+	for _, estimate := range estimates {
+		hostCost, hostCollateral := "-", "-"
+		if estimate.Error == "" {
+			hostCost = estimate.HostCost.HumanString()
+			hostCollateral = estimate.HostCollateral.HumanString()
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			estimate.HostPubKey.String(),
+			estimate.LocalCost.HumanString(),
+			hostCost,
+			estimate.LocalCollateral.HumanString(),
+			hostCollateral,
+			sanitizeErr(estimate.Error))
+	}
+
+	// print the hosts' breakdown of every estimate
+	if !verbose {
+		return
+	}
+	for _, estimate := range estimates {
+		fmt.Fprintf(w, "\nHost %v\n", estimate.HostPubKey.String())
+		fmt.Fprintln(w, "  Instruction\tCost\tFailure Refund\tCollateral\tMemory\tTime")
+		for _, instruction := range estimate.Instructions {
+			fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\t%v\n",
+				instruction.Specifier,
+				instruction.Cost.HumanString(),
+				instruction.FailureRefund.HumanString(),
+				instruction.Collateral.HumanString(),
+				modules.FilesizeUnits(instruction.Memory),
+				instruction.Time)
+		}
+	}
+}
+
 // renterworkerreadregistrycmd is the handler for the command `siac renter workers
 // rrj`.  It lists the status of the read registry jobs of every worker.
 func renterworkersreadregistrycmd() {
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -56,6 +57,20 @@ be valid. txn may be either JSON, base64, or a file containing either.`,
 		Run: wrap(walletbroadcastcmd),
 	}
 
+	walletBumpCmd = &cobra.Command{
+		Use:   "bump [txid]",
+		Short: "Bump the fee of an unconfirmed transaction",
+		Long: `Increase the fee of an unconfirmed wallet transaction which is stuck in the
+transaction pool. By default the change output of the transaction is spent by a
+child transaction that pays the higher fee (CPFP). With --rbf the transaction is
+replaced in the local transaction pool by a transaction with the same inputs
+that pays the higher fee.
+
+The new fee can be specified per KB, e.g. --fee 10mS. By default the maximum
+fee recommended by the transaction pool is used.`,
+		Run: wrap(walletbumpcmd),
+	}
+
 	walletChangepasswordCmd = &cobra.Command{
 		Use:   "change-password",
 		Short: "Change the wallet password",
@@ -133,6 +148,71 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletlockcmd),
 	}
 
+	walletMultisigCmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "Manage multisig addresses and transactions",
+		Long: `List the multisig addresses tracked by the wallet.
+
+Spending from a multisig address works in four steps. One of the key owners
+creates a partially-signed transaction with 'multisig create'. Every owner
+signs the transaction with 'multisig sign' and passes it on or back. If the
+owners signed copies in parallel, the copies are merged with 'multisig
+combine'. Once enough owners signed, 'multisig broadcast' submits the
+transaction.
+
+Partially-signed transactions are JSON-encoded and may be passed to the
+commands directly or as a path to a file containing them.`,
+		Run: wrap(walletmultisigcmd),
+	}
+
+	walletMultisigAddressCmd = &cobra.Command{
+		Use:   "address [required] [publickey,...]",
+		Short: "Create a multisig address",
+		Long: `Create an address that requires 'required' signatures of the given public keys
+to be spent and track its outputs in the wallet. Every key owner can get a
+public key of their wallet with 'siac wallet multisig pubkey'.`,
+		Example: "siac wallet multisig address 2 ed25519:<key1>,ed25519:<key2>,ed25519:<key3>",
+		Run:     wrap(walletmultisigaddresscmd),
+	}
+
+	walletMultisigBroadcastCmd = &cobra.Command{
+		Use:   "broadcast [transaction]",
+		Short: "Broadcast a partially-signed transaction",
+		Long:  "Broadcast a partially-signed transaction that collected all of its signatures.",
+		Run:   wrap(walletmultisigbroadcastcmd),
+	}
+
+	walletMultisigCombineCmd = &cobra.Command{
+		Use:   "combine [transaction] [transaction]...",
+		Short: "Combine the signatures of partially-signed transactions",
+		Long:  "Merge the signatures of multiple signed copies of the same partially-signed transaction.",
+		Run:   walletmultisigcombinecmd,
+	}
+
+	walletMultisigCreateCmd = &cobra.Command{
+		Use:   "create [address] [amount] [dest]",
+		Short: "Create a partially-signed transaction",
+		Long: `Create a partially-signed transaction which sends siacoins from a multisig
+address tracked by the wallet to 'dest'. The transaction fee is paid by the
+multisig address and the change is sent back to it. 'amount' can be specified
+in units, e.g. 1.23KS. Run 'wallet --help' for a list of units.`,
+		Run: wrap(walletmultisigcreatecmd),
+	}
+
+	walletMultisigPubkeyCmd = &cobra.Command{
+		Use:   "pubkey",
+		Short: "Get a public key for a multisig address",
+		Long:  "Generate a new wallet address and print its public key to share it with the other owners of a multisig address.",
+		Run:   wrap(walletmultisigpubkeycmd),
+	}
+
+	walletMultisigSignCmd = &cobra.Command{
+		Use:   "sign [transaction]",
+		Short: "Sign a partially-signed transaction",
+		Long:  "Add the signatures of the wallet's keys to the inputs of a partially-signed transaction that are still missing signatures.",
+		Run:   wrap(walletmultisigsigncmd),
+	}
+
 	walletSeedsCmd = &cobra.Command{
 		Use:   "seeds",
 		Short: "View information about your seeds",
@@ -389,6 +469,161 @@ func walletlockcmd() {
 	}
 }
 
+// walletmultisigcmd lists the multisig addresses tracked by the wallet.
+func walletmultisigcmd() {
+	wmg, err := httpClient.WalletMultisigGet()
+	if err != nil {
+		die("Could not get multisig addresses:", err)
+	}
+	if len(wmg.Addresses) == 0 {
+		fmt.Println("The wallet doesn't track any multisig addresses.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tRequired\tPublic Keys")
+	for _, addr := range wmg.Addresses {
+		uc := addr.UnlockConditions
+		fmt.Fprintf(w, "%v\t%v of %v\t%v\n", addr.Address, uc.SignaturesRequired, len(uc.PublicKeys), uc.PublicKeys[0])
+		for _, pk := range uc.PublicKeys[1:] {
+			fmt.Fprintf(w, "\t\t%v\n", pk)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletmultisigaddresscmd creates a multisig address.
+func walletmultisigaddresscmd(required, publicKeys string) {
+	n, err := strconv.ParseUint(required, 10, 64)
+	if err != nil {
+		die("Could not parse the number of required signatures:", err)
+	}
+	var keys []types.SiaPublicKey
+	for _, str := range strings.Split(publicKeys, ",") {
+		var key types.SiaPublicKey
+		if err := key.LoadString(strings.TrimSpace(str)); err != nil {
+			die("Could not parse public key", str)
+		}
+		keys = append(keys, key)
+	}
+	wma, err := httpClient.WalletMultisigPost(keys, n, false)
+	if err != nil {
+		die("Could not create multisig address:", err)
+	}
+	fmt.Printf("Created %v-of-%v multisig address: %v\n", n, len(keys), wma.Address)
+}
+
+// walletmultisigbroadcastcmd broadcasts a partially-signed transaction that
+// collected all of its signatures.
+func walletmultisigbroadcastcmd(pstStr string) {
+	pst, err := parsePartiallySignedTxn(pstStr)
+	if err != nil {
+		die("Could not decode transaction:", err)
+	}
+	wmbp, err := httpClient.WalletMultisigBroadcastPost(pst)
+	if err != nil {
+		die("Could not broadcast transaction:", err)
+	}
+	fmt.Println("Transaction has been broadcast successfully:", wmbp.TransactionID)
+}
+
+// walletmultisigcombinecmd merges the signatures of multiple partially-signed
+// transactions.
+func walletmultisigcombinecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var psts []modules.PartiallySignedTransaction
+	for _, arg := range args {
+		pst, err := parsePartiallySignedTxn(arg)
+		if err != nil {
+			die("Could not decode transaction:", err)
+		}
+		psts = append(psts, pst)
+	}
+	wmtp, err := httpClient.WalletMultisigCombinePost(psts)
+	if err != nil {
+		die("Could not combine transactions:", err)
+	}
+	printPartiallySignedTxn(wmtp.Transaction)
+}
+
+// walletmultisigcreatecmd creates a partially-signed transaction which sends
+// siacoins from a multisig address.
+func walletmultisigcreatecmd(addr, amount, dest string) {
+	var from types.UnlockHash
+	if err := from.LoadString(addr); err != nil {
+		die("Failed to parse multisig address", err)
+	}
+	hastings, err := types.ParseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var to types.UnlockHash
+	if err := to.LoadString(dest); err != nil {
+		die("Failed to parse destination address", err)
+	}
+	wmtp, err := httpClient.WalletMultisigCreatePost(from, []types.SiacoinOutput{{Value: value, UnlockHash: to}})
+	if err != nil {
+		die("Could not create transaction:", err)
+	}
+	printPartiallySignedTxn(wmtp.Transaction)
+}
+
+// walletmultisigpubkeycmd prints the public key of a new wallet address.
+func walletmultisigpubkeycmd() {
+	addr, err := httpClient.WalletAddressGet()
+	if err != nil {
+		die("Could not generate new address:", err)
+	}
+	wucg, err := httpClient.WalletUnlockConditionsGet(addr.Address)
+	if err != nil {
+		die("Could not get unlock conditions of new address:", err)
+	}
+	fmt.Println(wucg.UnlockConditions.PublicKeys[0])
+}
+
+// walletmultisigsigncmd signs a partially-signed transaction.
+func walletmultisigsigncmd(pstStr string) {
+	pst, err := parsePartiallySignedTxn(pstStr)
+	if err != nil {
+		die("Could not decode transaction:", err)
+	}
+	wmtp, err := httpClient.WalletMultisigSignPost(pst)
+	if err != nil {
+		die("Could not sign transaction:", err)
+	}
+	printPartiallySignedTxn(wmtp.Transaction)
+}
+
+// printPartiallySignedTxn prints a partially-signed transaction as JSON to
+// stdout and the signatures it is still missing to stderr, which keeps the
+// output usable as the input of the next command.
+func printPartiallySignedTxn(pst modules.PartiallySignedTransaction) {
+	if err := json.NewEncoder(os.Stdout).Encode(pst); err != nil {
+		die("failed to encode transaction:", err)
+	}
+	if pst.Complete() {
+		fmt.Fprintln(os.Stderr, "The transaction is fully signed and can be broadcast.")
+		return
+	}
+	for _, input := range pst.Inputs {
+		if input.MissingSignatures == 0 {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Input %v is missing %v signature(s) from:\n", input.ParentID, input.MissingSignatures)
+		for _, key := range input.UnsignedKeys {
+			fmt.Fprintln(os.Stderr, "  ", key)
+		}
+	}
+}
+
 // walletseedcmd returns the current seed {
 func walletseedscmd() {
 	seedInfo, err := httpClient.WalletSeedsGet()
@@ -500,6 +735,38 @@ Estimated Fee:       %v / KB
 		fees.Maximum.Mul64(1e3).HumanString())
 }
 
+// walletbumpcmd bumps the fee of an unconfirmed transaction.
+func walletbumpcmd(txidStr string) {
+	var txid types.TransactionID
+	if err := txid.UnmarshalJSON([]byte(`"` + txidStr + `"`)); err != nil {
+		die("Could not parse transaction id:", err)
+	}
+	method := modules.FeeBumpCPFP
+	if walletBumpRBF {
+		method = modules.FeeBumpRBF
+	}
+	var feePerByte types.Currency
+	if walletBumpFee != "" {
+		hastings, err := types.ParseCurrency(walletBumpFee)
+		if err != nil {
+			die("Could not parse fee:", err)
+		}
+		var feePerKB types.Currency
+		if _, err := fmt.Sscan(hastings, &feePerKB); err != nil {
+			die("Failed to parse fee", err)
+		}
+		feePerByte = feePerKB.Div64(1e3)
+	}
+	wtbp, err := httpClient.WalletTransactionBumpPost(txid, method, feePerByte)
+	if err != nil {
+		die("Could not bump transaction fee:", err)
+	}
+	fmt.Println("Submitted transaction set:")
+	for _, id := range wtbp.TransactionIDs {
+		fmt.Println("  ", id)
+	}
+}
+
 // walletbroadcastcmd broadcasts a transaction.
 func walletbroadcastcmd(txnStr string) {
 	txn, err := parseTxn(txnStr)
@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"bytes"
+	"io"
 
 	"gitlab.com/NebulousLabs/merkletree/merkletree-blake"
 
@@ -167,12 +168,23 @@ func VerifySegment(base []byte, hashSet []Hash, numSegments, proofIndex uint64,
 //
 // MerkleRangeProof for a single segment is NOT equivalent to MerkleProof.
 func MerkleRangeProof(b []byte, start, end int) []Hash {
-	proof, _ := merkletree.BuildRangeProof(start, end, merkletree.NewReaderSubtreeHasher(bytes.NewReader(b), SegmentSize))
+	proofHashes, _ := MerkleRangeProofFromReader(bytes.NewReader(b), start, end)
+	return proofHashes
+}
+
+// MerkleRangeProofFromReader builds a Merkle proof for the segment range
+// [start,end) of the data read from r. Unlike MerkleRangeProof, it doesn't
+// require the data to be held in memory.
+func MerkleRangeProofFromReader(r io.Reader, start, end int) ([]Hash, error) {
+	proof, err := merkletree.BuildRangeProof(start, end, merkletree.NewReaderSubtreeHasher(r, SegmentSize))
+	if err != nil {
+		return nil, err
+	}
 	proofHashes := make([]Hash, len(proof))
 	for i := range proofHashes {
 		proofHashes[i] = Hash(proof[i])
 	}
-	return proofHashes
+	return proofHashes, nil
 }
 
 // VerifyRangeProof verifies a proof produced by MerkleRangeProof.
@@ -208,6 +220,16 @@ func MerkleSectorRangeProof(roots []Hash, start, end int) []Hash {
 // MerkleMixedRangeProof creates a merkle range proof using both sector hashes
 // and segments.
 func MerkleMixedRangeProof(sectorRoots []Hash, segmentData []byte, sectorSize int, start, end int) []Hash {
+	proofHashes, err := MerkleMixedRangeProofFromReader(sectorRoots, bytes.NewReader(segmentData), sectorSize, start, end)
+	if err != nil {
+		build.Critical("BuildRangeProof failed", err)
+	}
+	return proofHashes
+}
+
+// MerkleMixedRangeProofFromReader creates a merkle range proof using both
+// sector hashes and the segments read from r.
+func MerkleMixedRangeProofFromReader(sectorRoots []Hash, r io.Reader, sectorSize int, start, end int) ([]Hash, error) {
 	sectorHashes := make([][32]byte, len(sectorRoots))
 	for i := range sectorHashes {
 		sectorHashes[i] = [32]byte(sectorRoots[i])
@@ -218,18 +240,17 @@ func MerkleMixedRangeProof(sectorRoots []Hash, segmentData []byte, sectorSize in
 			End:   uint64(end),
 		},
 	}
-	segmentReader := bytes.NewReader(segmentData)
 	segmentsPerSector := sectorSize / SegmentSize
-	msh := merkletree.NewMixedSubtreeHasher(sectorHashes, segmentReader, segmentsPerSector, SegmentSize)
+	msh := merkletree.NewMixedSubtreeHasher(sectorHashes, r, segmentsPerSector, SegmentSize)
 	proof, err := merkletree.BuildMultiRangeProof(ranges, msh)
 	if err != nil {
-		build.Critical("BuildRangeProof failed", err)
+		return nil, err
 	}
 	proofHashes := make([]Hash, len(proof))
 	for i := range proofHashes {
 		proofHashes[i] = Hash(proof[i])
 	}
-	return proofHashes
+	return proofHashes, nil
 }
 
 // VerifyMixedRangeProof verifies a mixed proof given the node hashes,
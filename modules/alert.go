@@ -53,8 +53,27 @@ const (
 	// registered if the host has insufficient collateral budget left to form or
 	// renew a contract
 	AlertIDHostInsufficientCollateral = "host-insufficient-collateral"
+	// AlertIDHostUnprovableObligations is the id of the alert that is
+	// registered when a decommissioning host finds storage obligations it
+	// can't submit a storage proof for.
+	AlertIDHostUnprovableObligations = "host-unprovable-obligations"
+	// AlertIDHostSectorCorruption is the id of the alert that is registered
+	// when the host's sector scrubber finds corrupted or unreadable sectors in
+	// one or more of the host's storage folders.
+	AlertIDHostSectorCorruption = "host-sector-corruption"
+	// AlertIDRenterBackupOverdue is the id of the alert that is registered if
+	// the renter's latest successful snapshot backup is older than its backup
+	// schedule allows.
+	AlertIDRenterBackupOverdue = "renter-backup-overdue"
 )
 
+// AlertIDRenterContractPriceOutlier uses a contract's ID to create a unique
+// AlertID for the decision taken about a contract whose host became a price
+// outlier.
+func AlertIDRenterContractPriceOutlier(fcid string) AlertID {
+	return AlertID(fmt.Sprintf("contract-price-outlier:%v", fcid))
+}
+
 // AlertIDSiafileLowRedundancy uses a Siafile's UID to create a unique AlertID
 // for a low redundancy alert.
 func AlertIDSiafileLowRedundancy(uid string) AlertID {
@@ -195,6 +195,14 @@ var (
 )
 
 var (
+	// HostClientTypeAccount is the type of a host client that is identified
+	// by the ID of an ephemeral account.
+	HostClientTypeAccount = HostClientType("account")
+
+	// HostClientTypeRenter is the type of a host client that is identified by
+	// the renter public key of a file contract.
+	HostClientTypeRenter = HostClientType("renter")
+
 	// HostConnectabilityStatusChecking is returned from ConnectabilityStatus()
 	// if the host is still determining if it is connectable.
 	HostConnectabilityStatusChecking = HostConnectabilityStatus("checking")
@@ -261,6 +269,13 @@ type (
 		PotentialDownloadBandwidthRevenue types.Currency `json:"potentialdownloadbandwidthrevenue"`
 		PotentialUploadBandwidthRevenue   types.Currency `json:"potentialuploadbandwidthrevenue"`
 		UploadBandwidthRevenue            types.Currency `json:"uploadbandwidthrevenue"`
+
+		// Metrics related to the cache folder. They count the sector reads
+		// which were and weren't served from the cache since startup and are
+		// not persisted.
+		CacheHits    uint64  `json:"cachehits"`
+		CacheMisses  uint64  `json:"cachemisses"`
+		CacheHitRate float64 `json:"cachehitrate"`
 	}
 
 	// HostInternalSettings contains a list of settings that can be changed.
@@ -289,6 +304,298 @@ type (
 
 		CustomRegistryPath string `json:"customregistrypath"`
 		RegistrySize       uint64 `json:"registrysize"`
+
+		SectorScrubRate uint64 `json:"sectorscrubrate"`
+
+		// Per-client quotas. A value of 0 means no limit.
+		MaxClientBandwidth         uint64 `json:"maxclientbandwidth"` // bytes / second
+		MaxClientProgramsPerMinute uint64 `json:"maxclientprogramsperminute"`
+		MaxClientStreams           uint64 `json:"maxclientstreams"`
+
+		// MDM execution tracing. MDMTraceSampleRate is the fraction of
+		// programs that are traced. If MDMTraceFailures is set, the traces of
+		// all failed programs are kept as well.
+		MDMTraceSampleRate float64 `json:"mdmtracesamplerate"`
+		MDMTraceFailures   bool    `json:"mdmtracefailures"`
+	}
+
+	// HostPricingPolicy configures the host's dynamic pricing engine. If
+	// enabled, the engine recomputes the host's storage and bandwidth prices
+	// on every consensus change and overwrites the corresponding minimum
+	// prices of the HostInternalSettings.
+	//
+	// The base price is the fiat target converted to siacoins using the
+	// exchange rate, the median price of the hosts observed by the hostdb, or
+	// the average of both if both are available. The base price is then
+	// scaled with the utilisation of the host's storage or bandwidth and
+	// clamped to the floor and ceiling. A ceiling of zero means no ceiling.
+	HostPricingPolicy struct {
+		Enabled bool `json:"enabled"`
+
+		// ExchangeRate is the value of one siacoin in fiat, e.g. "0.01 USD".
+		// The targets are denominated in the same fiat currency.
+		ExchangeRate                 string  `json:"exchangerate"`
+		TargetStoragePrice           float64 `json:"targetstorageprice"`           // fiat / TB / month
+		TargetDownloadBandwidthPrice float64 `json:"targetdownloadbandwidthprice"` // fiat / TB
+		TargetUploadBandwidthPrice   float64 `json:"targetuploadbandwidthprice"`   // fiat / TB
+
+		// MaxBandwidth is the bandwidth in bytes per second at which the
+		// host's bandwidth is considered fully utilised. If it is zero, the
+		// bandwidth prices don't depend on the bandwidth utilisation.
+		MaxBandwidth uint64 `json:"maxbandwidth"`
+
+		StoragePriceFloor             types.Currency `json:"storagepricefloor"`             // hastings / byte / block
+		StoragePriceCeiling           types.Currency `json:"storagepriceceiling"`           // hastings / byte / block
+		DownloadBandwidthPriceFloor   types.Currency `json:"downloadbandwidthpricefloor"`   // hastings / byte
+		DownloadBandwidthPriceCeiling types.Currency `json:"downloadbandwidthpriceceiling"` // hastings / byte
+		UploadBandwidthPriceFloor     types.Currency `json:"uploadbandwidthpricefloor"`     // hastings / byte
+		UploadBandwidthPriceCeiling   types.Currency `json:"uploadbandwidthpriceceiling"`   // hastings / byte
+	}
+
+	// HostPriceChange records a change of the host's prices by the pricing
+	// engine together with the inputs that led to it.
+	HostPriceChange struct {
+		BlockHeight types.BlockHeight `json:"blockheight"`
+		Timestamp   time.Time         `json:"timestamp"`
+
+		StoragePrice           types.Currency `json:"storageprice"`
+		DownloadBandwidthPrice types.Currency `json:"downloadbandwidthprice"`
+		UploadBandwidthPrice   types.Currency `json:"uploadbandwidthprice"`
+
+		StorageUtilisation           float64 `json:"storageutilisation"`
+		DownloadBandwidthUtilisation float64 `json:"downloadbandwidthutilisation"`
+		UploadBandwidthUtilisation   float64 `json:"uploadbandwidthutilisation"`
+	}
+
+	// HostContractForecast is a forecast of the storage proofs the host needs
+	// to submit within the coming blocks, the revenue it expects from them and
+	// the wallet balance it needs to submit them.
+	HostContractForecast struct {
+		StartHeight types.BlockHeight `json:"startheight"`
+		EndHeight   types.BlockHeight `json:"endheight"`
+
+		// ExpectedRevenue is the revenue of the obligations whose proof
+		// window starts within the forecast and CollateralAtRisk is the
+		// collateral locked in them. RequiredBalance is the estimated amount
+		// of transaction fees needed to submit their revisions and storage
+		// proofs.
+		ExpectedRevenue  types.Currency `json:"expectedrevenue"`
+		CollateralAtRisk types.Currency `json:"collateralatrisk"`
+		RequiredBalance  types.Currency `json:"requiredbalance"`
+		WalletBalance    types.Currency `json:"walletbalance"`
+
+		// Heights contains the expected revenue by block height and
+		// ProofWindows the upcoming proof windows, both sorted by height.
+		// UnderfundedObligations is the number of proof windows the host's
+		// wallet can't pay the fees for.
+		Heights                []HostForecastHeight      `json:"heights"`
+		ProofWindows           []HostForecastProofWindow `json:"proofwindows"`
+		UnderfundedObligations uint64                    `json:"underfundedobligations"`
+	}
+
+	// HostForecastHeight contains the revenue the host expects at a block
+	// height. The revenue of an obligation is expected at the end of its proof
+	// window.
+	HostForecastHeight struct {
+		Height             types.BlockHeight `json:"height"`
+		Obligations        uint64            `json:"obligations"`
+		ExpectedRevenue    types.Currency    `json:"expectedrevenue"`
+		CollateralReleased types.Currency    `json:"collateralreleased"`
+	}
+
+	// HostForecastProofWindow describes the proof window of a single storage
+	// obligation. An obligation is underfunded if the host's wallet is
+	// expected to be unable to pay the fees for its revision and storage
+	// proof when the proof window starts, taking into account the fees of
+	// earlier proofs and the payouts of earlier obligations that matured by
+	// then.
+	HostForecastProofWindow struct {
+		ObligationID     types.FileContractID `json:"obligationid"`
+		WindowStart      types.BlockHeight    `json:"windowstart"`
+		WindowEnd        types.BlockHeight    `json:"windowend"`
+		ExpectedRevenue  types.Currency       `json:"expectedrevenue"`
+		LockedCollateral types.Currency       `json:"lockedcollateral"`
+		RiskedCollateral types.Currency       `json:"riskedcollateral"`
+		RequiredFees     types.Currency       `json:"requiredfees"`
+		Underfunded      bool                 `json:"underfunded"`
+	}
+
+	// HostDecommissionStatus contains the progress of the host's
+	// decommissioning. While the host is decommissioning it doesn't form or
+	// renew contracts and releases its storage folders once they are empty.
+	HostDecommissionStatus struct {
+		Active      bool              `json:"active"`
+		StartHeight types.BlockHeight `json:"startheight"`
+
+		// ObligationsRemaining is the number of storage obligations the host
+		// still needs to submit a storage proof for and CollateralAtRisk is
+		// the collateral locked in these obligations.
+		ObligationsRemaining uint64         `json:"obligationsremaining"`
+		CollateralAtRisk     types.Currency `json:"collateralatrisk"`
+
+		// LastObligationHeight is the proof deadline of the last remaining
+		// obligation. The host can be shut down without losing collateral
+		// once the block height reaches EarliestSafeShutdownHeight.
+		LastObligationHeight       types.BlockHeight `json:"lastobligationheight"`
+		EarliestSafeShutdownHeight types.BlockHeight `json:"earliestsafeshutdownheight"`
+
+		// UnprovableObligations are the remaining obligations the host can't
+		// submit a storage proof for because some of their sectors are
+		// missing, corrupted or stored in an unavailable storage folder.
+		UnprovableObligations []HostUnprovableObligation `json:"unprovableobligations"`
+
+		// ReleasedFolders are the paths of the storage folders that were
+		// removed from the host after their sectors were moved to the
+		// remaining folders.
+		ReleasedFolders []string `json:"releasedfolders"`
+	}
+
+	// HostUnprovableObligation describes a storage obligation that the host
+	// can't submit a storage proof for.
+	HostUnprovableObligation struct {
+		ObligationID      types.FileContractID `json:"obligationid"`
+		ProofDeadline     types.BlockHeight    `json:"proofdeadline"`
+		LockedCollateral  types.Currency       `json:"lockedcollateral"`
+		UnprovableSectors uint64               `json:"unprovablesectors"`
+		Error             string               `json:"error"`
+	}
+
+	// HostAccount contains the state of a single ephemeral account on the
+	// host.
+	HostAccount struct {
+		ID       types.SiaPublicKey `json:"id"`
+		Balance  types.Currency     `json:"balance"`
+		LastUsed time.Time          `json:"lastused"`
+
+		// PendingRisk is the amount withdrawn from the account that has not
+		// been persisted yet.
+		PendingRisk types.Currency `json:"pendingrisk"`
+
+		// PendingWithdrawals are the withdrawals from the account that are
+		// blocked, either because the balance is insufficient or because the
+		// host reached its maximum risk.
+		PendingWithdrawals      uint64         `json:"pendingwithdrawals"`
+		PendingWithdrawalsValue types.Currency `json:"pendingwithdrawalsvalue"`
+	}
+
+	// HostAccounts contains the state of the host's ephemeral accounts and the
+	// host's aggregate risk.
+	HostAccounts struct {
+		Accounts     []HostAccount  `json:"accounts"`
+		MaxBalance   types.Currency `json:"maxbalance"`
+		TotalBalance types.Currency `json:"totalbalance"`
+
+		BlockedDeposits    uint64         `json:"blockeddeposits"`
+		BlockedWithdrawals uint64         `json:"blockedwithdrawals"`
+		CurrentRisk        types.Currency `json:"currentrisk"`
+		MaxRisk            types.Currency `json:"maxrisk"`
+	}
+
+	// HostClient contains the resource usage of a single client of the host.
+	// Clients are identified by the ID of the ephemeral account or the renter
+	// public key of the file contract they pay with. The statistics are not
+	// persisted.
+	HostClient struct {
+		PublicKey types.SiaPublicKey `json:"publickey"`
+		Type      HostClientType     `json:"type"`
+
+		ActiveStreams uint64    `json:"activestreams"`
+		Download      uint64    `json:"download"` // bytes sent to the client
+		LastSeen      time.Time `json:"lastseen"`
+		Programs      uint64    `json:"programs"`
+		Rejections    uint64    `json:"rejections"`
+		Streams       uint64    `json:"streams"`
+		Upload        uint64    `json:"upload"` // bytes received from the client
+	}
+
+	// HostClientType indicates how a client of the host is identified.
+	HostClientType string
+
+	// HostPricingMarket provides the hosts observed on the network to the
+	// host's pricing engine. It is usually implemented by the renter.
+	HostPricingMarket interface {
+		ActiveHosts() ([]HostDBEntry, error)
+	}
+
+	// HostInstructionMetrics are the metrics of a single type of MDM
+	// instruction.
+	HostInstructionMetrics struct {
+		Name       string               `json:"name"`
+		Errors     uint64               `json:"errors"`
+		Executions uint64               `json:"executions"`
+		Latency    HostLatencyHistogram `json:"latency"`
+	}
+
+	// HostLatencyBucket is a bucket of a latency histogram. It counts the
+	// observations that took at most UpperBound.
+	HostLatencyBucket struct {
+		Count      uint64        `json:"count"`
+		UpperBound time.Duration `json:"upperbound"`
+	}
+
+	// HostLatencyHistogram is a cumulative histogram of latencies. Count and
+	// Sum include the observations that exceeded the largest bucket.
+	HostLatencyHistogram struct {
+		Buckets []HostLatencyBucket `json:"buckets"`
+		Count   uint64              `json:"count"`
+		Sum     time.Duration       `json:"sum"`
+	}
+
+	// HostMDMInstructionTrace is the execution trace of a single instruction
+	// of an MDM program.
+	HostMDMInstructionTrace struct {
+		Name          string         `json:"name"`
+		ExecutionTime time.Duration  `json:"executiontime"`
+		BytesRead     uint64         `json:"bytesread"`    // sector data read from disk
+		BytesWritten  uint64         `json:"byteswritten"` // sector data added to the contract
+		Cost          types.Currency `json:"cost"`
+		Refund        types.Currency `json:"refund"`
+		Skipped       bool           `json:"skipped"`
+		Error         string         `json:"error,omitempty"`
+	}
+
+	// HostMDMTrace is the execution trace of an MDM program executed by the
+	// host. The client is the renter or ephemeral account that paid for the
+	// program.
+	HostMDMTrace struct {
+		ClientKey  types.SiaPublicKey   `json:"clientkey"`
+		ClientType HostClientType       `json:"clienttype"`
+		ContractID types.FileContractID `json:"contractid"`
+
+		Start         time.Time                 `json:"start"`
+		ExecutionTime time.Duration             `json:"executiontime"`
+		Cost          types.Currency            `json:"cost"`
+		Instructions  []HostMDMInstructionTrace `json:"instructions"`
+		Error         string                    `json:"error,omitempty"`
+	}
+
+	// HostMetrics contains the detailed metrics of the RPCs handled by the
+	// host over the siamux and of the MDM instructions it executed since it
+	// was started.
+	HostMetrics struct {
+		Instructions []HostInstructionMetrics `json:"instructions"`
+		RPCs         []HostRPCMetrics         `json:"rpcs"`
+	}
+
+	// HostRegistryEntry is an entry of the host's registry.
+	HostRegistryEntry struct {
+		EntryID   crypto.Hash        `json:"entryid"`
+		PublicKey types.SiaPublicKey `json:"publickey"`
+		Tweak     crypto.Hash        `json:"tweak"`
+		Data      []byte             `json:"data"`
+		Revision  uint64             `json:"revision"`
+		Signature []byte             `json:"signature"`
+		Expiry    types.BlockHeight  `json:"expiry"`
+	}
+
+	// HostRPCMetrics are the metrics of a single type of RPC.
+	HostRPCMetrics struct {
+		Name     string               `json:"name"`
+		BytesIn  uint64               `json:"bytesin"`  // bytes received from the peer
+		BytesOut uint64               `json:"bytesout"` // bytes sent to the peer
+		Calls    uint64               `json:"calls"`
+		Errors   uint64               `json:"errors"`
+		Latency  HostLatencyHistogram `json:"latency"`
 	}
 
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
@@ -371,6 +678,11 @@ type (
 		// root or not.
 		HasSector(crypto.Hash) bool
 
+		// CheckSector checks whether the host is able to serve the sector with
+		// the given root. It returns an error if the sector is unknown,
+		// corrupted or stored in an unavailable storage folder.
+		CheckSector(crypto.Hash) error
+
 		// AddSectorBatch is a performance optimization over AddSector when
 		// adding a bunch of virtual sectors. It is necessary because otherwise
 		// potentially thousands or even tens-of-thousands of fsync calls would
@@ -378,6 +690,15 @@ type (
 		// successfully renewing.
 		AddSectorBatch(sectorRoots []crypto.Hash) error
 
+		// Accounts returns the state of the host's ephemeral accounts.
+		Accounts() HostAccounts
+
+		// AddCacheFolder designates a folder as the host's cache tier.
+		// Frequently read sectors are copied into the cache folder and served
+		// from there, while the authoritative copies remain in the storage
+		// folders.
+		AddCacheFolder(path string, size uint64) error
+
 		// AddStorageFolder adds a storage folder to the host. The host may not
 		// check that there is enough space available on-disk to support as much
 		// storage as requested, though the manager should gracefully handle
@@ -390,6 +711,20 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// CacheFolder returns the metadata of the host's cache folder.
+		CacheFolder() CacheFolderMetadata
+
+		// CancelDecommission stops the decommissioning of the host. Storage
+		// folders that were already released are not added back.
+		CancelDecommission() error
+
+		// CancelStorageFolderRebalance stops the ongoing rebalance of the
+		// host's storage folders.
+		CancelStorageFolderRebalance() error
+
+		// Clients returns the resource usage of the host's clients.
+		Clients() []HostClient
+
 		// The host needs to be able to shut down.
 		Close() error
 
@@ -397,6 +732,19 @@ type (
 		// that is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
 
+		// ContractForecast returns a forecast of the storage proofs the host
+		// needs to submit within the given number of blocks.
+		ContractForecast(blocks types.BlockHeight) (HostContractForecast, error)
+
+		// Decommission puts the host into decommission mode. The host stops
+		// forming and renewing contracts and releases its storage folders as
+		// the sectors stored in them expire.
+		Decommission() error
+
+		// DecommissionStatus returns the progress of the host's
+		// decommissioning.
+		DecommissionStatus() HostDecommissionStatus
+
 		// DeleteSector deletes a sector, meaning that the host will be
 		// unable to upload that sector and be unable to provide a storage
 		// proof on that sector. DeleteSector is for removing the data
@@ -405,6 +753,10 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// ExportRegistry writes the entries of the host's registry to a new
+		// file at the given path.
+		ExportRegistry(path string) (uint64, error)
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -415,19 +767,41 @@ type (
 		// FinancialMetrics returns the financial statistics of the host.
 		FinancialMetrics() HostFinancialMetrics
 
+		// ImportRegistry adds the entries of a file created by ExportRegistry
+		// to the host's registry.
+		ImportRegistry(path string) (uint64, error)
+
 		// InternalSettings returns the host's internal settings, including
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
 
+		// MDMTraces returns the most recent execution traces of MDM
+		// programs, starting with the most recent one.
+		MDMTraces() []HostMDMTrace
+
+		// Metrics returns the detailed metrics of the RPCs and MDM
+		// instructions the host handled.
+		Metrics() HostMetrics
+
 		// NetworkMetrics returns information on the types of RPC calls that
 		// have been made to the host.
 		NetworkMetrics() HostNetworkMetrics
 
 		PaymentProcessor
 
+		// PriceChanges returns the most recent price changes made by the
+		// host's pricing engine.
+		PriceChanges() []HostPriceChange
+
 		// PriceTable returns the host's current price table.
 		PriceTable() RPCPriceTable
 
+		// PricingPolicy returns the policy of the host's pricing engine.
+		PricingPolicy() HostPricingPolicy
+
+		// PruneRegistry deletes all expired entries from the host's registry.
+		PruneRegistry() (uint64, error)
+
 		// PruneStaleStorageObligations will delete storage obligations from the
 		// host that, for whatever reason, did not make it on the block chain.
 		// As these stale storage obligations have an impact on the host
@@ -438,6 +812,16 @@ type (
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// RebalanceStorageFolders starts migrating sectors between the host's
+		// storage folders in the background, either to even out their
+		// utilisation or, if 'evacuate' is not empty, to empty the storage
+		// folders with the provided indices while they stay online.
+		RebalanceStorageFolders(evacuate []uint16, rate uint64) error
+
+		// RefundAccount deposits the amount into the ephemeral account with
+		// given id, ignoring the maximum account balance.
+		RefundAccount(id AccountID, amount types.Currency) error
+
 		// ReadSector will read a sector from the host, returning the bytes that
 		// match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
@@ -446,6 +830,17 @@ type (
 		// 'length' bytes at offset 'offset' that match the input sector root.
 		ReadPartialSector(sectorRoot crypto.Hash, offset, length uint64) ([]byte, error)
 
+		// RegistryEntries returns up to 'limit' entries of the host's
+		// registry, starting at 'offset', and the total number of entries.
+		RegistryEntries(offset, limit uint64) ([]HostRegistryEntry, uint64, error)
+
+		// RegistryEntry returns the entry of the host's registry with the
+		// given id.
+		RegistryEntry(sid RegistryEntryID) (HostRegistryEntry, bool)
+
+		// RemoveCacheFolder removes the host's cache folder.
+		RemoveCacheFolder() error
+
 		// RemoveSector will remove a sector from the host. The height at which
 		// the sector expires should be provided, so that the auto-expiry
 		// information for that sector can be properly updated.
@@ -482,6 +877,14 @@ type (
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetPricingMarket sets the source of the market prices used by the
+		// host's pricing engine.
+		SetPricingMarket(HostPricingMarket)
+
+		// SetPricingPolicy sets the policy of the host's pricing engine and
+		// recomputes the host's prices.
+		SetPricingPolicy(HostPricingPolicy) error
+
 		// StorageObligation returns the storage obligation matching the id or
 		// an error if it does not exist
 		StorageObligation(obligationID types.FileContractID) (StorageObligation, error)
@@ -497,6 +900,10 @@ type (
 		// WorkingStatus returns the working state of the host, determined by if
 		// settings calls are increasing.
 		WorkingStatus() HostWorkingStatus
+
+		// ZeroAccount sets the balance of the ephemeral account with given id
+		// to zero and returns the balance it had before.
+		ZeroAccount(id AccountID) (types.Currency, error)
 	}
 )
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"math/bits"
+	"sort"
 	"sync"
 	"time"
 
@@ -23,6 +24,10 @@ var (
 	// the account has expired in the meantime.
 	ErrAccountExpired = errors.New("ephemeral account expired")
 
+	// ErrAccountNotFound occurs when an operator tries to modify an ephemeral
+	// account that does not exist.
+	ErrAccountNotFound = errors.New("ephemeral account not found")
+
 	// ErrBalanceInsufficient occurs when a withdrawal could not be successfully
 	// completed because the account balance was insufficient.
 	ErrBalanceInsufficient = errors.New("ephemeral account balance was insufficient")
@@ -955,6 +960,73 @@ func (am *accountManager) callAccountBalance(id modules.AccountID) types.Currenc
 	return account.balance
 }
 
+// callAccounts returns the state of all ephemeral accounts, sorted by balance
+// in descending order, together with the aggregate balance and risk.
+func (am *accountManager) callAccounts() modules.HostAccounts {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	// Withdrawals that are blocked because max risk was reached are queued on
+	// the account manager rather than on the account.
+	blocked := make(map[modules.AccountID][]*blockedWithdrawal)
+	for _, bw := range am.blockedWithdrawals {
+		blocked[bw.withdrawal.Account] = append(blocked[bw.withdrawal.Account], bw)
+	}
+
+	accounts := modules.HostAccounts{
+		Accounts:           make([]modules.HostAccount, 0, len(am.accounts)),
+		BlockedDeposits:    uint64(len(am.blockedDeposits)),
+		BlockedWithdrawals: uint64(len(am.blockedWithdrawals)),
+		CurrentRisk:        am.currentRisk,
+	}
+	for id, acc := range am.accounts {
+		pending := append(blockedWithdrawalHeap(blocked[id]), acc.blockedWithdrawals...)
+		accounts.Accounts = append(accounts.Accounts, modules.HostAccount{
+			ID:                      id.SPK(),
+			Balance:                 acc.balance,
+			LastUsed:                time.Unix(acc.lastTxnTime, 0),
+			PendingRisk:             acc.pendingRisk,
+			PendingWithdrawals:      uint64(len(pending)),
+			PendingWithdrawalsValue: pending.Value(),
+		})
+		accounts.TotalBalance = accounts.TotalBalance.Add(acc.balance)
+	}
+	sort.Slice(accounts.Accounts, func(i, j int) bool {
+		return accounts.Accounts[i].Balance.Cmp(accounts.Accounts[j].Balance) > 0
+	})
+	return accounts
+}
+
+// managedZeroAccount sets the balance of the account with given id to zero and
+// blocks until the account is persisted. It returns the balance the account
+// had before. Zeroing an account is treated like a withdrawal of its entire
+// balance, so the host is at risk for the balance until the account is
+// persisted.
+func (am *accountManager) managedZeroAccount(id modules.AccountID) (types.Currency, error) {
+	pr := &persistResult{
+		errAvail: make(chan struct{}),
+	}
+	am.mu.Lock()
+	acc, exists := am.accounts[id]
+	if !exists {
+		am.mu.Unlock()
+		return types.ZeroCurrency, ErrAccountNotFound
+	}
+	balance := acc.balance
+	acc.balance = types.ZeroCurrency
+	acc.pendingRisk = acc.pendingRisk.Add(balance)
+	am.currentRisk = am.currentRisk.Add(balance)
+	am.schedulePersist(acc, pr)
+	am.mu.Unlock()
+
+	// Wait for the account to be persisted.
+	err := am.staticWaitForDepositResult(pr)
+	if err != nil {
+		return types.ZeroCurrency, errors.AddContext(err, "failed to zero account")
+	}
+	return balance, nil
+}
+
 // openAccount will return an account object. If the account does not exist it
 // will be created.
 func (am *accountManager) openAccount(id modules.AccountID) (*account, error) {
@@ -1090,3 +1162,34 @@ func (a *account) sendResult(result error, waiting int) {
 	}
 	a.persistResults = a.persistResults[waiting:]
 }
+
+// Accounts returns the state of the host's ephemeral accounts along with the
+// host's aggregate risk.
+func (h *Host) Accounts() modules.HostAccounts {
+	accounts := h.staticAccountManager.callAccounts()
+	his := h.managedInternalSettings()
+	accounts.MaxBalance = his.MaxEphemeralAccountBalance
+	accounts.MaxRisk = his.MaxEphemeralAccountRisk
+	return accounts
+}
+
+// RefundAccount deposits the amount into the ephemeral account with given id,
+// ignoring the maximum account balance. If the account does not exist, it is
+// opened.
+func (h *Host) RefundAccount(id modules.AccountID, amount types.Currency) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	return h.staticAccountManager.callRefund(id, amount)
+}
+
+// ZeroAccount sets the balance of the ephemeral account with given id to zero
+// and returns the balance it had before.
+func (h *Host) ZeroAccount(id modules.AccountID) (types.Currency, error) {
+	if err := h.tg.Add(); err != nil {
+		return types.ZeroCurrency, err
+	}
+	defer h.tg.Done()
+	return h.staticAccountManager.managedZeroAccount(id)
+}
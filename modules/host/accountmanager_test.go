@@ -1162,6 +1162,99 @@ func TestAccountWithdrawalsInactive(t *testing.T) {
 	}
 }
 
+// TestAccountAdmin verifies the host's account administration, listing,
+// refunding and zeroing ephemeral accounts.
+func TestAccountAdmin(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := ht.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	h := ht.host
+	am := h.staticAccountManager
+
+	// Fund two accounts.
+	_, small := prepareAccount()
+	_, large := prepareAccount()
+	err = callDeposit(am, small, types.NewCurrency64(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = callDeposit(am, large, types.NewCurrency64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The accounts should be sorted by balance.
+	accounts := h.Accounts()
+	if len(accounts.Accounts) != 2 {
+		t.Fatal("expected 2 accounts, got", len(accounts.Accounts))
+	}
+	if !accounts.Accounts[0].ID.Equals(large.SPK()) || !accounts.Accounts[1].ID.Equals(small.SPK()) {
+		t.Fatal("accounts not sorted by balance")
+	}
+	if !accounts.TotalBalance.Equals64(110) {
+		t.Fatal("wrong total balance", accounts.TotalBalance)
+	}
+	his := h.InternalSettings()
+	if !accounts.MaxRisk.Equals(his.MaxEphemeralAccountRisk) || !accounts.MaxBalance.Equals(his.MaxEphemeralAccountBalance) {
+		t.Fatal("wrong max risk or max balance", accounts.MaxRisk, accounts.MaxBalance)
+	}
+	if accounts.Accounts[0].LastUsed.IsZero() {
+		t.Fatal("last used time not set")
+	}
+
+	// Refund an amount exceeding the max balance.
+	refund := his.MaxEphemeralAccountBalance
+	err = h.RefundAccount(small, refund)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := getAccountBalance(am, small); !balance.Equals(refund.Add64(10)) {
+		t.Fatal("refund was not credited", balance)
+	}
+
+	// Zero the account.
+	balance, err := h.ZeroAccount(small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !balance.Equals(refund.Add64(10)) {
+		t.Fatal("wrong previous balance", balance)
+	}
+	if balance := getAccountBalance(am, small); !balance.IsZero() {
+		t.Fatal("account was not zeroed", balance)
+	}
+
+	// The risk should be lowered once the account is persisted.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if risk := managedCurrentRisk(am); !risk.IsZero() {
+			return fmt.Errorf("expected zero risk, got %v", risk)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Zeroing an unknown account should fail.
+	_, unknown := prepareAccount()
+	_, err = h.ZeroAccount(unknown)
+	if !errors.Contains(err, ErrAccountNotFound) {
+		t.Fatal("expected ErrAccountNotFound, got", err)
+	}
+}
+
 // managedCurrentRisk will return the current risk
 func managedCurrentRisk(am *accountManager) types.Currency {
 	am.mu.Lock()
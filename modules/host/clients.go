@@ -0,0 +1,241 @@
+package host
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/siamux"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+type (
+	// clientQuotas are the per-client limits of the host. A limit of 0 means
+	// no limit.
+	clientQuotas struct {
+		maxBandwidth         uint64 // bytes / second
+		maxProgramsPerMinute uint64
+		maxStreams           uint64
+	}
+
+	// hostClient tracks the resource usage of a single client.
+	hostClient struct {
+		modules.HostClient
+
+		// The usage within the current quota window.
+		windowStart    time.Time
+		windowBytes    uint64
+		windowPrograms uint64
+	}
+
+	// hostClients tracks the resource usage of the host's clients and
+	// enforces the per-client quotas. A client is attached to a stream once
+	// it identified itself by paying for an RPC, and detached when the stream
+	// is closed.
+	hostClients struct {
+		clients map[string]*hostClient
+		streams map[siamux.Stream]*hostClient
+		mu      sync.Mutex
+	}
+)
+
+// newHostClients creates a new client tracker.
+func newHostClients() *hostClients {
+	return &hostClients{
+		clients: make(map[string]*hostClient),
+		streams: make(map[siamux.Stream]*hostClient),
+	}
+}
+
+// accountClient returns the client identifier of an ephemeral account.
+func accountClient(id modules.AccountID) modules.HostClient {
+	return modules.HostClient{
+		PublicKey: id.SPK(),
+		Type:      modules.HostClientTypeAccount,
+	}
+}
+
+// renterClient returns the client identifier of the renter of a file
+// contract.
+func renterClient(rev types.FileContractRevision) modules.HostClient {
+	var pk types.SiaPublicKey
+	if len(rev.UnlockConditions.PublicKeys) > 0 {
+		pk = rev.UnlockConditions.PublicKeys[0]
+	}
+	return modules.HostClient{
+		PublicKey: pk,
+		Type:      modules.HostClientTypeRenter,
+	}
+}
+
+// clientKey returns the key of a client in the client map.
+func clientKey(c modules.HostClient) string {
+	return fmt.Sprintf("%v:%v", c.Type, c.PublicKey.String())
+}
+
+// resetWindow starts a new quota window if the current one has passed.
+func (c *hostClient) resetWindow(now time.Time) {
+	if now.Sub(c.windowStart) < clientQuotaWindow {
+		return
+	}
+	c.windowStart = now
+	c.windowBytes = 0
+	c.windowPrograms = 0
+}
+
+// managedAttachStream attaches a client to a stream. An error is returned if
+// attaching the client would exceed its stream or bandwidth quota. Streams can
+// only be attached to a single client, attaching another client to the same
+// stream is a no-op.
+func (hc *hostClients) managedAttachStream(stream siamux.Stream, id modules.HostClient, quotas clientQuotas) error {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if _, exists := hc.streams[stream]; exists {
+		return nil
+	}
+
+	key := clientKey(id)
+	c, exists := hc.clients[key]
+	if !exists {
+		hc.pruneClients()
+		c = &hostClient{HostClient: id}
+		hc.clients[key] = c
+	}
+	now := time.Now()
+	c.LastSeen = now
+	c.resetWindow(now)
+
+	if quotas.maxStreams > 0 && c.ActiveStreams >= quotas.maxStreams {
+		c.Rejections++
+		return errors.AddContext(modules.ErrClientQuotaExceeded, fmt.Sprintf("too many concurrent streams, the limit is %v", quotas.maxStreams))
+	}
+	if quotas.maxBandwidth > 0 && c.windowBytes >= quotas.maxBandwidth*uint64(clientQuotaWindow.Seconds()) {
+		c.Rejections++
+		return errors.AddContext(modules.ErrClientQuotaExceeded, fmt.Sprintf("bandwidth limit of %v bytes per second exceeded", quotas.maxBandwidth))
+	}
+	c.ActiveStreams++
+	c.Streams++
+	hc.streams[stream] = c
+	return nil
+}
+
+// managedDetachStream detaches the client from a closed stream and accounts
+// for the bandwidth used by the stream.
+func (hc *hostClients) managedDetachStream(stream siamux.Stream, download, upload uint64) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	c, exists := hc.streams[stream]
+	if !exists {
+		return
+	}
+	delete(hc.streams, stream)
+	now := time.Now()
+	c.resetWindow(now)
+	c.ActiveStreams--
+	c.Download += download
+	c.Upload += upload
+	c.LastSeen = now
+	c.windowBytes += download + upload
+}
+
+// managedStartProgram records the execution of an MDM program by the client
+// attached to the stream. An error is returned if the execution would exceed
+// the client's program quota.
+func (hc *hostClients) managedStartProgram(stream siamux.Stream, quotas clientQuotas) error {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	c, exists := hc.streams[stream]
+	if !exists {
+		return nil
+	}
+	c.resetWindow(time.Now())
+	if quotas.maxProgramsPerMinute > 0 && c.windowPrograms >= quotas.maxProgramsPerMinute*uint64(clientQuotaWindow/time.Minute) {
+		c.Rejections++
+		return errors.AddContext(modules.ErrClientQuotaExceeded, fmt.Sprintf("program limit of %v executions per minute exceeded", quotas.maxProgramsPerMinute))
+	}
+	c.Programs++
+	c.windowPrograms++
+	return nil
+}
+
+// managedStreamClient returns the identifier of the client attached to the
+// stream.
+func (hc *hostClients) managedStreamClient(stream siamux.Stream) (modules.HostClient, bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	c, exists := hc.streams[stream]
+	if !exists {
+		return modules.HostClient{}, false
+	}
+	return modules.HostClient{
+		PublicKey: c.PublicKey,
+		Type:      c.Type,
+	}, true
+}
+
+// managedClients returns the usage of all tracked clients.
+func (hc *hostClients) managedClients() []modules.HostClient {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	clients := make([]modules.HostClient, 0, len(hc.clients))
+	for _, c := range hc.clients {
+		clients = append(clients, c.HostClient)
+	}
+	return clients
+}
+
+// pruneClients forgets the idle client that was seen least recently if the
+// number of tracked clients reached the limit.
+func (hc *hostClients) pruneClients() {
+	if len(hc.clients) < maxTrackedClients {
+		return
+	}
+	var oldestKey string
+	var oldest *hostClient
+	for key, c := range hc.clients {
+		if c.ActiveStreams > 0 {
+			continue
+		}
+		if oldest == nil || c.LastSeen.Before(oldest.LastSeen) {
+			oldestKey, oldest = key, c
+		}
+	}
+	if oldest != nil {
+		delete(hc.clients, oldestKey)
+	}
+}
+
+// managedClientQuotas returns the host's per-client quotas.
+func (h *Host) managedClientQuotas() clientQuotas {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return clientQuotas{
+		maxBandwidth:         h.settings.MaxClientBandwidth,
+		maxProgramsPerMinute: h.settings.MaxClientProgramsPerMinute,
+		maxStreams:           h.settings.MaxClientStreams,
+	}
+}
+
+// managedAttachClient attaches the client that pays for an RPC to the stream
+// the RPC is executed on.
+func (h *Host) managedAttachClient(stream siamux.Stream, id modules.HostClient) error {
+	err := h.staticClients.managedAttachStream(stream, id, h.managedClientQuotas())
+	if errors.Contains(err, modules.ErrClientQuotaExceeded) {
+		h.log.Debugf("Rejected %v %v: %v", id.Type, id.PublicKey, err)
+	}
+	return err
+}
+
+// Clients returns the resource usage of the host's clients, sorted by the
+// amount of bandwidth they used.
+func (h *Host) Clients() []modules.HostClient {
+	clients := h.staticClients.managedClients()
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Download+clients[i].Upload > clients[j].Download+clients[j].Upload
+	})
+	return clients
+}
@@ -0,0 +1,193 @@
+package host
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"gitlab.com/NebulousLabs/siamux"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// mockClientStream is a stream that can be used as a key by the client
+// tracker.
+type mockClientStream struct {
+	siamux.Stream
+	id int
+}
+
+// TestHostClients is a unit test for the client tracker.
+func TestHostClients(t *testing.T) {
+	hc := newHostClients()
+	account, _ := modules.NewAccountID()
+	client := accountClient(account)
+	quotas := clientQuotas{
+		maxBandwidth:         1,
+		maxProgramsPerMinute: 1,
+		maxStreams:           1,
+	}
+	s1, s2 := &mockClientStream{id: 1}, &mockClientStream{id: 2}
+
+	// A client can only use a single stream.
+	if err := hc.managedAttachStream(s1, client, quotas); err != nil {
+		t.Fatal(err)
+	}
+	if err := hc.managedAttachStream(s1, client, quotas); err != nil {
+		t.Fatal("attaching the same stream twice should be a no-op", err)
+	}
+	if err := hc.managedAttachStream(s2, client, quotas); !errors.Contains(err, modules.ErrClientQuotaExceeded) {
+		t.Fatal("expected ErrClientQuotaExceeded, got", err)
+	}
+
+	// A client can only execute a single program per minute. Streams without
+	// a client aren't limited.
+	if err := hc.managedStartProgram(s1, quotas); err != nil {
+		t.Fatal(err)
+	}
+	if err := hc.managedStartProgram(s1, quotas); !errors.Contains(err, modules.ErrClientQuotaExceeded) {
+		t.Fatal("expected ErrClientQuotaExceeded, got", err)
+	}
+	if err := hc.managedStartProgram(s2, quotas); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the stream is closed, another one can be opened unless the client
+	// used up its bandwidth.
+	hc.managedDetachStream(s1, 30, 29)
+	if err := hc.managedAttachStream(s2, client, quotas); err != nil {
+		t.Fatal(err)
+	}
+	hc.managedDetachStream(s2, 1, 0)
+	if err := hc.managedAttachStream(s1, client, quotas); !errors.Contains(err, modules.ErrClientQuotaExceeded) {
+		t.Fatal("expected ErrClientQuotaExceeded, got", err)
+	}
+
+	// Check the statistics.
+	clients := hc.managedClients()
+	if len(clients) != 1 {
+		t.Fatal("expected one client", clients)
+	}
+	c := clients[0]
+	if !c.PublicKey.Equals(account.SPK()) || c.Type != modules.HostClientTypeAccount {
+		t.Fatal("wrong client", c)
+	}
+	if c.ActiveStreams != 0 || c.Streams != 2 || c.Programs != 1 || c.Rejections != 3 || c.Download != 31 || c.Upload != 29 {
+		t.Fatal("wrong statistics", c)
+	}
+
+	// Without quotas, nothing is rejected.
+	for i := 0; i < 3; i++ {
+		if err := hc.managedAttachStream(&mockClientStream{id: 3 + i}, client, clientQuotas{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestHostClientsPrune checks that the client tracker forgets idle clients
+// once it tracks too many clients.
+func TestHostClientsPrune(t *testing.T) {
+	hc := newHostClients()
+	active := renterClient(types.FileContractRevision{
+		UnlockConditions: types.UnlockConditions{
+			PublicKeys: []types.SiaPublicKey{{Algorithm: types.SignatureEd25519, Key: fastrand.Bytes(crypto.PublicKeySize)}},
+		},
+	})
+	if err := hc.managedAttachStream(&mockClientStream{}, active, clientQuotas{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxTrackedClients; i++ {
+		account, _ := modules.NewAccountID()
+		s := &mockClientStream{id: i}
+		if err := hc.managedAttachStream(s, accountClient(account), clientQuotas{}); err != nil {
+			t.Fatal(err)
+		}
+		hc.managedDetachStream(s, 0, 0)
+	}
+	if len(hc.clients) != maxTrackedClients {
+		t.Fatal("wrong number of clients", len(hc.clients))
+	}
+	if _, exists := hc.clients[clientKey(active)]; !exists {
+		t.Fatal("active client shouldn't have been pruned")
+	}
+}
+
+// TestHostClientProgramQuota checks that the host rejects programs of clients
+// that exceeded their program quota and refunds them.
+func TestHostClientProgramQuota(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rhp, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := rhp.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	h := rhp.staticHT.host
+
+	// Allow a single program per minute.
+	settings := h.InternalSettings()
+	settings.MaxClientProgramsPerMinute = 1
+	if err := h.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund an account.
+	pt := rhp.managedPriceTable()
+	maxBalance := h.managedInternalSettings().MaxEphemeralAccountBalance
+	_, err = rhp.managedFundEphemeralAccount(maxBalance.Add(pt.FundAccountCost), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a 'HasSector' program.
+	pb := modules.NewProgramBuilder(pt, 0)
+	pb.AddHasSectorInstruction(crypto.Hash{})
+	program, data := pb.Program()
+	programCost, _, _ := pb.Cost(true)
+	epr := modules.RPCExecuteProgramRequest{
+		FileContractID:    rhp.staticFCID,
+		Program:           program,
+		ProgramDataLength: uint64(len(data)),
+	}
+	budget := programCost.Add(pt.DownloadBandwidthCost.Add(pt.UploadBandwidthCost).Mul64(1 << 14))
+
+	// The first program succeeds, the second one is rejected and refunded.
+	_, _, err = rhp.managedExecuteProgram(epr, data, budget, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := getAccountBalance(h.staticAccountManager, rhp.staticAccountID)
+	_, _, err = rhp.managedExecuteProgram(epr, data, budget, false, true)
+	if err == nil || !strings.Contains(err.Error(), modules.ErrClientQuotaExceeded.Error()) {
+		t.Fatal("expected ErrClientQuotaExceeded, got", err)
+	}
+	if err := verifyBalance(h.staticAccountManager, rhp.staticAccountID, balance); err != nil {
+		t.Fatal(err)
+	}
+
+	// The account should be reported as a client.
+	var found bool
+	for _, c := range h.Clients() {
+		if c.Type != modules.HostClientTypeAccount || !c.PublicKey.Equals(rhp.staticAccountID.SPK()) {
+			continue
+		}
+		found = true
+		if c.Programs != 1 || c.Rejections != 1 || c.Streams != 2 {
+			t.Fatal("wrong client statistics", c)
+		}
+	}
+	if !found {
+		t.Fatal("account wasn't reported as a client")
+	}
+}
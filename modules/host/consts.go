@@ -34,9 +34,48 @@ const (
 	// maxObligationLockTimeout is the maximum amount of time the host will wait
 	// to lock a storage obligation.
 	maxObligationLockTimeout = 10 * time.Minute
+
+	// clientQuotaWindow is the window over which the bandwidth and program
+	// quotas of the host's clients are enforced.
+	clientQuotaWindow = time.Minute
+
+	// maxTrackedClients is the number of clients the host keeps statistics
+	// for. Once it is exceeded, the idle client that was seen least recently
+	// is forgotten.
+	maxTrackedClients = 10000
+
+	// maxPriceChanges is the number of price changes made by the pricing
+	// engine that the host remembers.
+	maxPriceChanges = 100
+
+	// maxMDMTraces is the number of MDM execution traces the host keeps. Once
+	// it is exceeded, the oldest trace is overwritten.
+	maxMDMTraces = 1000
+
+	// pricingMinUtilisationMultiplier and pricingMaxUtilisationMultiplier are
+	// the factors that the pricing engine applies to the base prices at a
+	// utilisation of 0% and 100% respectively. Factors in between are
+	// interpolated linearly.
+	pricingMinUtilisationMultiplier = 0.75
+	pricingMaxUtilisationMultiplier = 1.5
 )
 
 var (
+	// metricsLatencyBuckets are the upper bounds of the buckets of the
+	// latency histograms in the host's RPC and instruction metrics.
+	metricsLatencyBuckets = []time.Duration{
+		time.Millisecond,
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		5 * time.Second,
+		10 * time.Second,
+		time.Minute,
+	}
+
 	// connectablityCheckFirstWait defines how often the host's connectability
 	// check is run.
 	connectabilityCheckFirstWait = build.Select(build.Var{
@@ -74,6 +113,16 @@ var (
 	// prevent the host from having too much money at risk.
 	defaultMaxEphemeralAccountRisk = types.SiacoinPrecision.Mul64(5)
 
+	// defaultSectorScrubRate is the default rate in bytes per second at which
+	// the host re-reads its stored sectors to detect corruption. Scrubbing is
+	// disabled by default during testing to avoid competing for disk access
+	// with the tests.
+	defaultSectorScrubRate = build.Select(build.Var{
+		Dev:      uint64(1 << 22), // 4 MiB/s
+		Standard: uint64(1 << 22), // 4 MiB/s
+		Testing:  uint64(0),
+	}).(uint64)
+
 	// logAllLimit is the number of errors of each type that the host will log
 	// before switching to probabilistic logging. If there are not many errors,
 	// it is reasonable that all errors get logged. If there are lots of
@@ -23,6 +23,10 @@ const (
 	// metadata associated with a storage folder.
 	metadataFile = "siahostmetadata.dat"
 
+	// cacheFile is the file that is placed inside of the cache folder to house
+	// the cached copies of sectors.
+	cacheFile = "siahostcache.dat"
+
 	// sectorFile is the file that is placed inside of a storage folder to
 	// house all of the sectors associated with a storage folder.
 	sectorFile = "siahostdata.dat"
@@ -52,6 +56,12 @@ const (
 )
 
 const (
+	// cacheReadsTrackedFactor bounds the number of sectors for which the
+	// sector cache tracks reads to a multiple of the number of sectors that
+	// fit into the cache. Once the bound is reached, the read counts are
+	// halved and sectors which weren't read recently are forgotten.
+	cacheReadsTrackedFactor = 16
+
 	// folderAllocationStepSize is the amount of data that gets allocated at a
 	// time when writing out the sparse sector file during a storageFolderAdd or
 	// a storageFolderGrow.
@@ -139,4 +149,29 @@ var (
 		Standard: time.Second * 60 * 5,
 		Testing:  time.Second * 8,
 	}).(time.Duration)
+
+	// defaultRebalanceRate is the rate in bytes per second at which a storage
+	// folder rebalance moves sectors if no rate was specified.
+	defaultRebalanceRate = build.Select(build.Var{
+		Dev:      uint64(1 << 25), // 32 MiB/s
+		Standard: uint64(1 << 25), // 32 MiB/s
+		Testing:  uint64(1 << 30), // 1 GiB/s
+	}).(uint64)
+
+	// cachePromotionThreshold is the number of reads after which a sector is
+	// promoted to the cache folder.
+	cachePromotionThreshold = build.Select(build.Var{
+		Dev:      uint64(2),
+		Standard: uint64(3),
+		Testing:  uint64(2),
+	}).(uint64)
+
+	// scrubIdleInterval specifies the amount of time that the sector scrubber
+	// waits between two passes over the storage folders, as well as how often
+	// it checks whether scrubbing was enabled.
+	scrubIdleInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testing:  time.Millisecond * 100,
+	}).(time.Duration)
 )
@@ -40,6 +40,13 @@ import (
 // renters, including storing the data, submitting storage proofs, and deleting
 // the data when a contract is complete.
 type ContractManager struct {
+	// atomicScrubRate is the rate in bytes per second at which the sector
+	// scrubber re-reads the stored sectors. A rate of 0 disables the scrubber.
+	//
+	// NOTE: this field must come first in the struct to ensure proper
+	// alignment.
+	atomicScrubRate uint64
+
 	// The contract manager controls many resources which are spread across
 	// multiple files yet must all be consistent and durable. ACID properties
 	// have been achieved by using a write-ahead-logger (WAL). The in-memory
@@ -93,6 +100,20 @@ type ContractManager struct {
 	// or modified.
 	lockedSectors map[sectorID]*sectorLock
 
+	// corruptSectors contains the sectors which the sector scrubber found to
+	// no longer match their Merkle roots. Reading them returns an error until
+	// they are repaired by adding the sector again.
+	corruptSectors map[sectorID]struct{}
+
+	// rebalance contains the state of the ongoing storage folder rebalance,
+	// or nil if there is none. rebalanceCancel is closed to cancel it.
+	rebalance       *savedRebalance
+	rebalanceCancel chan struct{}
+
+	// sectorCache is the cache tier which serves frequently read sectors, or
+	// nil if no cache folder was added.
+	sectorCache *sectorCache
+
 	// Utilities.
 	dependencies  modules.Dependencies
 	staticAlerter *modules.GenericAlerter
@@ -114,7 +135,8 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 		storageFolders:  make(map[uint16]*storageFolder),
 		sectorLocations: make(map[sectorID]sectorLocation),
 
-		lockedSectors: make(map[sectorID]*sectorLock),
+		lockedSectors:  make(map[sectorID]*sectorLock),
+		corruptSectors: make(map[sectorID]struct{}),
 
 		dependencies: dependencies,
 		persistDir:   persistDir,
@@ -200,6 +222,18 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 		}
 	})
 
+	// Close the cache folder on shutdown.
+	cm.tg.AfterStop(func() {
+		cm.wal.mu.Lock()
+		defer cm.wal.mu.Unlock()
+		if cm.sectorCache == nil {
+			return
+		}
+		if err := cm.sectorCache.managedClose(); err != nil {
+			cm.log.Println("Error closing the cache folder file handle", err)
+		}
+	})
+
 	// The sector location data is loaded last. Any corruption that happened
 	// during unclean shutdown has already been fixed by the WAL.
 	for _, sf := range cm.storageFolders {
@@ -224,6 +258,19 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 	// and adds them if they are discovered.
 	go cm.threadedFolderRecheck()
 
+	// Spin up the thread that scrubs the stored sectors and re-register the
+	// alert for any corrupted sectors found before the last shutdown.
+	cm.managedUpdateScrubAlert()
+	go cm.threadedScrubSectors()
+
+	// Resume a storage folder rebalance that was interrupted by shutdown.
+	cm.wal.mu.Lock()
+	if cm.rebalance != nil {
+		cm.log.Printf("Resuming storage folder rebalance, evacuating folders %v", cm.rebalance.Evacuate)
+		cm.startRebalance(*cm.rebalance)
+	}
+	cm.wal.mu.Unlock()
+
 	// Simulate an error to make sure the cleanup code is triggered correctly.
 	if cm.dependencies.Disrupt("erroredStartup") {
 		err = errors.New("startup disrupted")
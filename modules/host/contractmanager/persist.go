@@ -1,9 +1,11 @@
 package contractmanager
 
 import (
+	"bytes"
 	"encoding/binary"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
 
 	"gitlab.com/NebulousLabs/errors"
@@ -28,6 +30,9 @@ type (
 	savedSettings struct {
 		SectorSalt     crypto.Hash
 		StorageFolders []savedStorageFolder
+		CorruptSectors []sectorID
+		Rebalance      *savedRebalance
+		SectorCache    *savedSectorCache
 	}
 )
 
@@ -73,6 +78,18 @@ func (cm *ContractManager) loadSettings() error {
 
 	// Copy the saved settings into the contract manager.
 	cm.sectorSalt = ss.SectorSalt
+	for _, id := range ss.CorruptSectors {
+		cm.corruptSectors[id] = struct{}{}
+	}
+	cm.rebalance = ss.Rebalance
+	if ss.SectorCache != nil {
+		cm.sectorCache, err = cm.newSectorCache(ss.SectorCache.Path, ss.SectorCache.Size, false)
+		if err != nil {
+			// The cache only contains copies of sectors, so the contract
+			// manager can continue without it.
+			cm.log.Printf("ERROR: unable to open the %v cache folder, continuing without cache: %v\n", ss.SectorCache.Path, err)
+		}
+	}
 	for i := range ss.StorageFolders {
 		sf := new(storageFolder)
 		sf.index = ss.StorageFolders[i].Index
@@ -148,6 +165,16 @@ func (cm *ContractManager) savedSettings() savedSettings {
 	ss := savedSettings{
 		SectorSalt: cm.sectorSalt,
 	}
+	if cm.rebalance != nil {
+		rb := *cm.rebalance
+		ss.Rebalance = &rb
+	}
+	if cm.sectorCache != nil {
+		ss.SectorCache = &savedSectorCache{
+			Path: cm.sectorCache.path,
+			Size: cm.sectorCache.size,
+		}
+	}
 	for _, sf := range cm.storageFolders {
 		// Unset all of the usage bits in the storage folder for the queued sectors.
 		for _, sectorIndex := range sf.availableSectors {
@@ -162,5 +189,11 @@ func (cm *ContractManager) savedSettings() savedSettings {
 			sf.setUsage(sectorIndex)
 		}
 	}
+	for id := range cm.corruptSectors {
+		ss.CorruptSectors = append(ss.CorruptSectors, id)
+	}
+	sort.Slice(ss.CorruptSectors, func(i, j int) bool {
+		return bytes.Compare(ss.CorruptSectors[i][:], ss.CorruptSectors[j][:]) < 0
+	})
 	return ss
 }
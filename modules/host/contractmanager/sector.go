@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
@@ -15,6 +16,10 @@ var (
 	// ErrSectorNotFound is returned when a lookup for a sector fails.
 	ErrSectorNotFound = errors.New("could not find the desired sector")
 
+	// ErrStorageFolderUnavailable is returned when a sector is stored in a
+	// storage folder that is currently unavailable.
+	ErrStorageFolderUnavailable = errors.New("sector is stored in an unavailable storage folder")
+
 	// errDiskTrouble is returned when the host is supposed to have enough
 	// storage to hold a new sector but failures that are likely related to the
 	// disk have prevented the host from successfully adding the sector.
@@ -102,6 +107,15 @@ func writeSectorMetadata(f modules.File, sectorIndex uint32, id sectorID, count
 	return nil
 }
 
+// sectorTransferDelay returns the amount of time to wait after reading or
+// moving a single sector to stay within the provided rate.
+func sectorTransferDelay(bytesPerSecond uint64) time.Duration {
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return time.Duration(float64(modules.SectorSize) / float64(bytesPerSecond) * float64(time.Second))
+}
+
 // sectorID returns the id that should be used when referring to a sector.
 // There are lots of sectors, and to minimize their footprint a reduced size
 // hash is used. Hashes are typically 256bits to provide collision resistance
@@ -134,14 +148,32 @@ func (cm *ContractManager) ReadPartialSector(root crypto.Hash, offset, length ui
 	cm.wal.mu.Lock()
 	sl, exists1 := cm.sectorLocations[id]
 	sf, exists2 := cm.storageFolders[sl.storageFolder]
+	_, corrupted := cm.corruptSectors[id]
+	sc := cm.sectorCache
 	cm.wal.mu.Unlock()
 	if !exists1 {
 		return nil, ErrSectorNotFound
 	}
+	if corrupted {
+		return nil, ErrSectorCorrupted
+	}
 	if !exists2 {
 		cm.log.Critical("Unable to load storage folder despite having sector metadata")
 		return nil, ErrSectorNotFound
 	}
+
+	// Serve the read from the cache folder if the sector is cached. Full
+	// sectors are verified against their root, partial reads can't be
+	// verified without reading the whole sector.
+	if sc != nil {
+		data, cached := sc.managedRead(id, offset, length)
+		if cached && offset == 0 && length == modules.SectorSize && crypto.MerkleRoot(data) != root {
+			cm.log.Printf("WARN: cached sector %v doesn't match its root, evicting it", root)
+			sc.managedEvict(id)
+		} else if cached {
+			return data, nil
+		}
+	}
 	if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
 		// TODO: Pick a new error instead.
 		return nil, ErrSectorNotFound
@@ -154,6 +186,15 @@ func (cm *ContractManager) ReadPartialSector(root crypto.Hash, offset, length ui
 		return nil, build.ExtendErr("unable to fetch sector", err)
 	}
 	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+
+	// Promote the sector to the cache folder if it is read frequently.
+	if sc != nil {
+		var fullSector []byte
+		if offset == 0 && length == modules.SectorSize {
+			fullSector = sectorData
+		}
+		cm.managedCacheSector(sc, sf, sl, id, fullSector)
+	}
 	return sectorData, nil
 }
 
@@ -177,6 +218,31 @@ func (cm *ContractManager) HasSector(sectorRoot crypto.Hash) bool {
 	return exists
 }
 
+// CheckSector checks whether the contract manager is able to serve the sector
+// with the given root without reading it from disk. It returns
+// ErrSectorNotFound if the sector is unknown, ErrSectorCorrupted if the sector
+// scrubber found the sector to be corrupted and ErrStorageFolderUnavailable if
+// the sector's storage folder is unavailable.
+func (cm *ContractManager) CheckSector(sectorRoot crypto.Hash) error {
+	id := cm.managedSectorID(sectorRoot)
+
+	cm.wal.mu.Lock()
+	sl, exists1 := cm.sectorLocations[id]
+	sf, exists2 := cm.storageFolders[sl.storageFolder]
+	_, corrupted := cm.corruptSectors[id]
+	cm.wal.mu.Unlock()
+	if !exists1 || !exists2 {
+		return ErrSectorNotFound
+	}
+	if corrupted {
+		return ErrSectorCorrupted
+	}
+	if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return ErrStorageFolderUnavailable
+	}
+	return nil
+}
+
 // managedLockSector grabs a sector lock.
 func (wal *writeAheadLog) managedLockSector(id sectorID) {
 	wal.mu.Lock()
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 
+	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/modules"
 )
 
@@ -61,3 +63,59 @@ func TestHasSector(t *testing.T) {
 		t.Fatal(fmt.Sprintf("Unexpected HasSector response: %v, sector has been deleted", exists))
 	}
 }
+
+// TestCheckSector verifies the behavior of the CheckSector function.
+func TestCheckSector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown sectors can't be served.
+	root, data := randSector()
+	if err := cmt.cm.CheckSector(root); !errors.Contains(err, ErrSectorNotFound) {
+		t.Fatal("expected ErrSectorNotFound, got", err)
+	}
+
+	// Add the sector.
+	err = cmt.cm.AddSector(root, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.CheckSector(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mark the storage folder as unavailable.
+	sf := cmt.cm.availableStorageFolders()[0]
+	atomic.StoreUint64(&sf.atomicUnavailable, 1)
+	if err := cmt.cm.CheckSector(root); !errors.Contains(err, ErrStorageFolderUnavailable) {
+		t.Fatal("expected ErrStorageFolderUnavailable, got", err)
+	}
+	atomic.StoreUint64(&sf.atomicUnavailable, 0)
+
+	// Mark the sector as corrupted.
+	id := cmt.cm.managedSectorID(root)
+	cmt.cm.wal.mu.Lock()
+	cmt.cm.corruptSectors[id] = struct{}{}
+	cmt.cm.wal.mu.Unlock()
+	if err := cmt.cm.CheckSector(root); !errors.Contains(err, ErrSectorCorrupted) {
+		t.Fatal("expected ErrSectorCorrupted, got", err)
+	}
+}
@@ -0,0 +1,342 @@
+package contractmanager
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// ErrCacheFolderExists is returned if a cache folder is added while the
+	// contract manager already has a cache folder.
+	ErrCacheFolderExists = errors.New("a cache folder already exists, remove it first")
+
+	// errNoCacheFolder is returned if the cache folder is removed while there
+	// is none.
+	errNoCacheFolder = errors.New("no cache folder exists")
+)
+
+type (
+	// savedSectorCache contains the persisted settings of the cache folder.
+	// The contents of the cache are not persisted, the cache starts out cold
+	// after every restart.
+	savedSectorCache struct {
+		Path string
+		Size uint64
+	}
+
+	// sectorCache is a cache tier which keeps copies of frequently read
+	// sectors in a storage folder on a faster disk. The authoritative copy of
+	// a cached sector always remains in the regular storage folders, so the
+	// cache can be removed or lost at any time without losing data.
+	//
+	// Sectors are removed from the cache together with their last copy in the
+	// storage folders. Full sector reads served by the cache are verified
+	// against the sector root, a cached sector which doesn't match its root
+	// is evicted and read from its storage folder instead.
+	//
+	// Sectors are promoted to the cache once they have been read
+	// cachePromotionThreshold times. If the cache is full, the cached sector
+	// with the fewest reads is evicted, but only if it was read less often
+	// than the promoted sector. The read counts are halved regularly so that
+	// the cache follows changes in the access pattern.
+	sectorCache struct {
+		// Statistics about the reads served by the cache. They are not
+		// persisted.
+		atomicHits   uint64
+		atomicMisses uint64
+
+		path string
+		size uint64
+
+		// file contains the cached sectors. cached maps the cached sectors to
+		// their slot in the file and free contains the unused slots. closed
+		// is set once the cache folder is removed.
+		cached map[sectorID]uint32
+		closed bool
+		file   modules.File
+		free   []uint32
+		mu     sync.RWMutex
+
+		// reads tracks how often each sector was read recently.
+		reads   map[sectorID]uint64
+		readsMu sync.Mutex
+	}
+)
+
+// newSectorCache opens the cache file in the provided folder and returns an
+// empty sector cache. If 'create' is set, the cache file is created or
+// truncated first.
+func (cm *ContractManager) newSectorCache(path string, size uint64, create bool) (*sectorCache, error) {
+	flags := os.O_RDWR
+	if create {
+		flags |= os.O_CREATE | os.O_TRUNC
+	}
+	file, err := cm.dependencies.OpenFile(filepath.Join(path, cacheFile), flags, 0700)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to open cache file")
+	}
+	if err := file.Truncate(int64(size)); err != nil {
+		return nil, errors.Compose(errors.AddContext(err, "unable to allocate cache file"), file.Close())
+	}
+	sc := &sectorCache{
+		path:   path,
+		size:   size,
+		cached: make(map[sectorID]uint32),
+		file:   file,
+		reads:  make(map[sectorID]uint64),
+	}
+	for slot := uint32(size / modules.SectorSize); slot > 0; slot-- {
+		sc.free = append(sc.free, slot-1)
+	}
+	return sc, nil
+}
+
+// managedClose closes the cache file. Reads which are in progress are
+// completed first.
+func (sc *sectorCache) managedClose() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.closed {
+		return nil
+	}
+	sc.closed = true
+	return sc.file.Close()
+}
+
+// managedRead returns the requested data of a sector if the sector is cached.
+// A read error evicts the sector from the cache.
+func (sc *sectorCache) managedRead(id sectorID, offset, length uint64) ([]byte, bool) {
+	sc.mu.RLock()
+	slot, cached := sc.cached[id]
+	if sc.closed || !cached {
+		sc.mu.RUnlock()
+		atomic.AddUint64(&sc.atomicMisses, 1)
+		return nil, false
+	}
+	data, err := readPartialSector(sc.file, slot, offset, length)
+	sc.mu.RUnlock()
+	if err != nil {
+		sc.managedEvict(id)
+		atomic.AddUint64(&sc.atomicMisses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&sc.atomicHits, 1)
+	sc.managedRecordRead(id)
+	return data, true
+}
+
+// managedRecordRead increments the read count of a sector and returns whether
+// the sector should be promoted to the cache.
+func (sc *sectorCache) managedRecordRead(id sectorID) bool {
+	sc.readsMu.Lock()
+	defer sc.readsMu.Unlock()
+	sc.reads[id]++
+	reads := sc.reads[id]
+
+	// Halve the read counts once too many sectors are tracked to bound the
+	// memory used by the statistics.
+	if uint64(len(sc.reads)) > cacheReadsTrackedFactor*(sc.size/modules.SectorSize) {
+		for id, n := range sc.reads {
+			if n/2 == 0 {
+				delete(sc.reads, id)
+			} else {
+				sc.reads[id] = n / 2
+			}
+		}
+	}
+	return reads >= cachePromotionThreshold
+}
+
+// managedEvict removes a sector from the cache.
+func (sc *sectorCache) managedEvict(id sectorID) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if slot, cached := sc.cached[id]; cached {
+		delete(sc.cached, id)
+		sc.free = append(sc.free, slot)
+	}
+}
+
+// managedForget evicts a sector which was removed from the contract manager
+// from the cache and drops its read statistics.
+func (sc *sectorCache) managedForget(id sectorID) {
+	sc.managedEvict(id)
+	sc.readsMu.Lock()
+	delete(sc.reads, id)
+	sc.readsMu.Unlock()
+}
+
+// managedPromote copies the data of a sector into the cache. If the cache is
+// full, the least read sector is evicted unless it was read more often than
+// the promoted sector.
+func (sc *sectorCache) managedPromote(id sectorID, data []byte) error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if _, cached := sc.cached[id]; sc.closed || cached {
+		return nil
+	}
+
+	// Find a slot for the sector.
+	if len(sc.free) == 0 {
+		sc.readsMu.Lock()
+		reads := sc.reads[id]
+		var victim sectorID
+		var victimFound bool
+		var victimReads uint64
+		for cachedID := range sc.cached {
+			n := sc.reads[cachedID]
+			if !victimFound || n < victimReads {
+				victim, victimReads, victimFound = cachedID, n, true
+			}
+		}
+		sc.readsMu.Unlock()
+		if !victimFound || victimReads >= reads {
+			return nil
+		}
+		sc.free = append(sc.free, sc.cached[victim])
+		delete(sc.cached, victim)
+	}
+	slot := sc.free[len(sc.free)-1]
+	sc.free = sc.free[:len(sc.free)-1]
+
+	if err := writeSector(sc.file, slot, data); err != nil {
+		sc.free = append(sc.free, slot)
+		return errors.AddContext(err, "unable to write sector to the cache")
+	}
+	sc.cached[id] = slot
+	return nil
+}
+
+// managedMetadata returns the metadata of the cache folder.
+func (sc *sectorCache) managedMetadata() modules.CacheFolderMetadata {
+	sc.mu.RLock()
+	used := uint64(len(sc.cached)) * modules.SectorSize
+	sc.mu.RUnlock()
+	hits := atomic.LoadUint64(&sc.atomicHits)
+	misses := atomic.LoadUint64(&sc.atomicMisses)
+	md := modules.CacheFolderMetadata{
+		Path:              sc.path,
+		Capacity:          sc.size,
+		CapacityRemaining: sc.size - used,
+		Hits:              hits,
+		Misses:            misses,
+	}
+	if hits+misses > 0 {
+		md.HitRate = float64(hits) / float64(hits+misses)
+	}
+	return md
+}
+
+// managedCacheSector records a read of a sector which wasn't served by the
+// cache and promotes the sector to the cache if it is read frequently. 'data'
+// is the full sector or nil if only part of the sector was read. The sector
+// lock needs to be held by the caller.
+func (cm *ContractManager) managedCacheSector(sc *sectorCache, sf *storageFolder, sl sectorLocation, id sectorID, data []byte) {
+	if !sc.managedRecordRead(id) {
+		return
+	}
+	if data == nil {
+		var err error
+		data, err = readSector(sf.sectorFile, sl.index)
+		if err != nil {
+			atomic.AddUint64(&sf.atomicFailedReads, 1)
+			return
+		}
+		atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+	}
+	if err := sc.managedPromote(id, data); err != nil {
+		cm.log.Printf("WARN: unable to promote sector to cache folder %v: %v", sc.path, err)
+	}
+}
+
+// AddCacheFolder designates the folder at the provided path as the cache
+// tier of the contract manager. Frequently read sectors are copied into the
+// cache folder and served from there, which is useful if the folder is on a
+// faster disk than the storage folders. The authoritative copies of the
+// sectors remain in the storage folders.
+func (cm *ContractManager) AddCacheFolder(path string, size uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+
+	// The cache folder has the same size requirements as a storage folder.
+	sectors := size / modules.SectorSize
+	if sectors > MaximumSectorsPerStorageFolder {
+		return ErrLargeStorageFolder
+	}
+	if sectors < MinimumSectorsPerStorageFolder {
+		return ErrSmallStorageFolder
+	}
+	if sectors%storageFolderGranularity != 0 {
+		return errStorageFolderGranularity
+	}
+	if !filepath.IsAbs(path) {
+		return errRelativePath
+	}
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !pathInfo.Mode().IsDir() {
+		return errStorageFolderNotFolder
+	}
+
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	if cm.sectorCache != nil {
+		return ErrCacheFolderExists
+	}
+	sc, err := cm.newSectorCache(path, size, true)
+	if err != nil {
+		cm.log.Println("Call to AddCacheFolder has failed:", err)
+		return err
+	}
+	cm.sectorCache = sc
+	cm.log.Printf("Added cache folder %v with a size of %v bytes", path, size)
+	return nil
+}
+
+// RemoveCacheFolder removes the cache folder of the contract manager. No data
+// is lost since the cache only contains copies of sectors.
+func (cm *ContractManager) RemoveCacheFolder() error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+
+	cm.wal.mu.Lock()
+	sc := cm.sectorCache
+	cm.sectorCache = nil
+	cm.wal.mu.Unlock()
+	if sc == nil {
+		return errNoCacheFolder
+	}
+	err = sc.managedClose()
+	err = errors.Compose(err, cm.dependencies.RemoveFile(filepath.Join(sc.path, cacheFile)))
+	if err != nil {
+		cm.log.Printf("WARN: unable to clean up cache folder %v: %v", sc.path, err)
+	}
+	cm.log.Printf("Removed cache folder %v", sc.path)
+	return nil
+}
+
+// CacheFolder returns the metadata of the cache folder. The path of the
+// returned metadata is empty if there is no cache folder.
+func (cm *ContractManager) CacheFolder() modules.CacheFolderMetadata {
+	cm.wal.mu.Lock()
+	sc := cm.sectorCache
+	cm.wal.mu.Unlock()
+	if sc == nil {
+		return modules.CacheFolderMetadata{}
+	}
+	return sc.managedMetadata()
+}
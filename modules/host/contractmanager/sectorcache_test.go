@@ -0,0 +1,306 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestSectorCache checks that frequently read sectors are promoted to the
+// cache folder and served from there.
+func TestSectorCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and a cache folder.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	cacheDir := filepath.Join(cmt.persistDir, "cacheFolder")
+	for _, dir := range []string{storageFolderDir, cacheDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.RemoveCacheFolder(); !errors.Contains(err, errNoCacheFolder) {
+		t.Fatal("expected errNoCacheFolder, got", err)
+	}
+	if err := cmt.cm.AddCacheFolder("cacheFolder", modules.SectorSize*storageFolderGranularity); !errors.Contains(err, errRelativePath) {
+		t.Fatal("expected errRelativePath, got", err)
+	}
+	if err := cmt.cm.AddCacheFolder(cacheDir, modules.SectorSize); !errors.Contains(err, ErrSmallStorageFolder) {
+		t.Fatal("expected ErrSmallStorageFolder, got", err)
+	}
+	cacheSize := modules.SectorSize * storageFolderGranularity
+	if err := cmt.cm.AddCacheFolder(cacheDir, cacheSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddCacheFolder(cacheDir, cacheSize); !errors.Contains(err, ErrCacheFolderExists) {
+		t.Fatal("expected ErrCacheFolderExists, got", err)
+	}
+
+	root, data := randSector()
+	if err := cmt.cm.AddSector(root, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sector is promoted once it has been read often enough.
+	for i := uint64(0); i < cachePromotionThreshold; i++ {
+		if _, err := cmt.cm.ReadSector(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	md := cmt.cm.CacheFolder()
+	if md.Path != cacheDir || md.Capacity != cacheSize || md.CapacityRemaining != cacheSize-modules.SectorSize {
+		t.Fatal("sector wasn't promoted", md)
+	}
+	if md.Hits != 0 || md.Misses != cachePromotionThreshold {
+		t.Fatal("wrong cache statistics", md)
+	}
+
+	// Overwrite the sector in the storage folder. Reads should be served from
+	// the cache and return the original data.
+	cmt.cm.wal.mu.Lock()
+	sl := cmt.cm.sectorLocations[cmt.cm.managedSectorID(root)]
+	cmt.cm.wal.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(storageFolderDir, sectorFile), os.O_RDWR, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteAt(fastrand.Bytes(int(modules.SectorSize)), int64(uint64(sl.index)*modules.SectorSize))
+	if err := errors.Compose(err, f.Close()); err != nil {
+		t.Fatal(err)
+	}
+	read, err := cmt.cm.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatal("sector wasn't served from the cache")
+	}
+	read, err = cmt.cm.ReadPartialSector(root, 64, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data[64:128]) {
+		t.Fatal("partial sector wasn't served from the cache")
+	}
+	if md := cmt.cm.CacheFolder(); md.Hits != 2 || md.HitRate != 2/float64(2+cachePromotionThreshold) {
+		t.Fatal("wrong cache statistics", md)
+	}
+
+	// The cache folder is kept across restarts but starts out empty.
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md := cmt.cm.CacheFolder(); md.Path != cacheDir || md.CapacityRemaining != cacheSize || md.Hits != 0 {
+		t.Fatal("wrong cache folder after restart", md)
+	}
+
+	// Remove the cache folder. The cache file should be deleted.
+	if err := cmt.cm.RemoveCacheFolder(); err != nil {
+		t.Fatal(err)
+	}
+	if md := cmt.cm.CacheFolder(); md.Path != "" {
+		t.Fatal("cache folder wasn't removed", md)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, cacheFile)); !os.IsNotExist(err) {
+		t.Fatal("cache file wasn't deleted", err)
+	}
+}
+
+// TestSectorCacheEviction checks that the least read sectors are evicted from
+// a full cache.
+func TestSectorCacheEviction(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Create a cache with room for two sectors.
+	sc, err := cmt.cm.newSectorCache(cmt.persistDir, 2*modules.SectorSize, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := sc.managedClose(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	var ids [3]sectorID
+	var datas [3][]byte
+	for i := range ids {
+		root, data := randSector()
+		ids[i], datas[i] = cmt.cm.managedSectorID(root), data
+	}
+	read := func(i int, n int) {
+		for j := 0; j < n; j++ {
+			sc.managedRecordRead(ids[i])
+		}
+	}
+
+	// Fill the cache.
+	read(0, 3)
+	read(1, 1)
+	for i := 0; i < 2; i++ {
+		if err := sc.managedPromote(ids[i], datas[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A sector which was read less often than the cached sectors isn't
+	// promoted.
+	read(2, 1)
+	if err := sc.managedPromote(ids[2], datas[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, cached := sc.managedRead(ids[2], 0, modules.SectorSize); cached {
+		t.Fatal("sector shouldn't have been promoted")
+	}
+
+	// Once it was read more often, it replaces the least read sector.
+	read(2, 2)
+	if err := sc.managedPromote(ids[2], datas[2]); err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []bool{true, false, true} {
+		data, cached := sc.managedRead(ids[i], 0, modules.SectorSize)
+		if cached != expected {
+			t.Fatal("wrong sector evicted", i, cached)
+		}
+		if cached && !bytes.Equal(data, datas[i]) {
+			t.Fatal("cached sector has wrong data", i)
+		}
+	}
+}
+
+// TestSectorCacheRemovedSector checks that removed and deleted sectors are
+// evicted from the cache and that corrupt cached sectors aren't served.
+func TestSectorCacheRemovedSector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and a cache folder.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	cacheDir := filepath.Join(cmt.persistDir, "cacheFolder")
+	for _, dir := range []string{storageFolderDir, cacheDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity); err != nil {
+		t.Fatal(err)
+	}
+	cacheSize := modules.SectorSize * storageFolderGranularity
+	if err := cmt.cm.AddCacheFolder(cacheDir, cacheSize); err != nil {
+		t.Fatal(err)
+	}
+
+	// addCachedSector adds a sector and reads it until it is cached.
+	addCachedSector := func() (crypto.Hash, []byte) {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		for i := uint64(0); i < cachePromotionThreshold; i++ {
+			if _, err := cmt.cm.ReadSector(root); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if md := cmt.cm.CacheFolder(); md.CapacityRemaining != cacheSize-modules.SectorSize {
+			t.Fatal("sector wasn't promoted", md)
+		}
+		return root, data
+	}
+
+	// Reading a deleted sector fails and frees its slot in the cache.
+	root, _ := addCachedSector()
+	if err := cmt.cm.DeleteSector(root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.cm.ReadSector(root); !errors.Contains(err, ErrSectorNotFound) {
+		t.Fatal("expected ErrSectorNotFound, got", err)
+	}
+	if md := cmt.cm.CacheFolder(); md.CapacityRemaining != cacheSize {
+		t.Fatal("deleted sector wasn't evicted", md)
+	}
+
+	// A sector which is stored twice is only evicted once its last copy is
+	// removed.
+	root, data := addCachedSector()
+	if err := cmt.cm.AddSector(root, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.RemoveSector(root); err != nil {
+		t.Fatal(err)
+	}
+	if md := cmt.cm.CacheFolder(); md.CapacityRemaining != cacheSize-modules.SectorSize {
+		t.Fatal("sector was evicted while it is still stored", md)
+	}
+	if err := cmt.cm.RemoveSector(root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.cm.ReadSector(root); !errors.Contains(err, ErrSectorNotFound) {
+		t.Fatal("expected ErrSectorNotFound, got", err)
+	}
+	if md := cmt.cm.CacheFolder(); md.CapacityRemaining != cacheSize {
+		t.Fatal("removed sector wasn't evicted", md)
+	}
+
+	// A corrupt cached sector is evicted and read from the storage folder,
+	// which promotes the sector to the cache again.
+	root, data = addCachedSector()
+	sc := cmt.cm.sectorCache
+	sc.mu.RLock()
+	slot := sc.cached[cmt.cm.managedSectorID(root)]
+	sc.mu.RUnlock()
+	if err := writeSector(sc.file, slot, fastrand.Bytes(int(modules.SectorSize))); err != nil {
+		t.Fatal(err)
+	}
+	read, err := cmt.cm.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatal("corrupt cached sector was served")
+	}
+	hits := cmt.cm.CacheFolder().Hits
+	read, err = cmt.cm.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(read, data) || cmt.cm.CacheFolder().Hits != hits+1 {
+		t.Fatal("sector wasn't cached again")
+	}
+}
@@ -0,0 +1,259 @@
+package contractmanager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// ErrSectorCorrupted is returned when a sector is read which the sector
+// scrubber found to no longer match its Merkle root.
+var ErrSectorCorrupted = errors.New("sector data is corrupted")
+
+// threadedScrubSectors continuously iterates over the storage folders and
+// re-reads every sector at the configured scrub rate to verify that the data
+// on disk still matches the sector's Merkle root. The thread group is only
+// held while accessing the disk to avoid blocking calls to Flush.
+func (cm *ContractManager) threadedScrubSectors() {
+	for {
+		// Wait until scrubbing is enabled.
+		if atomic.LoadUint64(&cm.atomicScrubRate) == 0 {
+			select {
+			case <-cm.tg.StopChan():
+				return
+			case <-time.After(scrubIdleInterval):
+			}
+			continue
+		}
+
+		// Scrub the storage folders in order of their indices.
+		cm.wal.mu.Lock()
+		indices := make([]uint16, 0, len(cm.storageFolders))
+		for index := range cm.storageFolders {
+			indices = append(indices, index)
+		}
+		cm.wal.mu.Unlock()
+		sort.Slice(indices, func(i, j int) bool {
+			return indices[i] < indices[j]
+		})
+		for _, index := range indices {
+			if !cm.managedScrubFolder(index) {
+				return
+			}
+			cm.managedUpdateScrubAlert()
+		}
+
+		// Rest between two passes.
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-time.After(scrubIdleInterval):
+		}
+	}
+}
+
+// managedScrubFolder scrubs the sectors of a storage folder, starting at the
+// folder's scrub position. The position is persisted in memory across calls,
+// allowing a pass to be continued if it was interrupted by a storage folder
+// operation or by the scrubbing being disabled. 'false' is returned if the
+// contract manager is shutting down.
+func (cm *ContractManager) managedScrubFolder(index uint16) bool {
+	for {
+		rate := atomic.LoadUint64(&cm.atomicScrubRate)
+		if rate == 0 {
+			return true
+		}
+
+		// Fetch the storage folder and the usage element at the scrub
+		// position.
+		cm.wal.mu.Lock()
+		sf, exists := cm.storageFolders[index]
+		if !exists {
+			cm.wal.mu.Unlock()
+			return true
+		}
+		position := atomic.LoadUint64(&sf.atomicScrubPosition)
+		usageIndex := position / storageFolderGranularity
+		if usageIndex >= uint64(len(sf.usage)) {
+			// The pass over the folder is complete.
+			atomic.StoreUint64(&sf.atomicScrubPosition, 0)
+			atomic.StoreInt64(&sf.atomicLastScrubTime, time.Now().UnixNano())
+			cm.wal.mu.Unlock()
+			return true
+		}
+		usageElement := sf.usage[usageIndex]
+		cm.wal.mu.Unlock()
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			return true
+		}
+
+		// Read the metadata of the sectors which belong to the usage element
+		// to learn their ids.
+		var metadata []byte
+		if usageElement != 0 {
+			if err := cm.tg.Add(); err != nil {
+				return false
+			}
+			// Skip the folder if it is being added, removed or resized.
+			if !sf.mu.TryRLock() {
+				cm.tg.Done()
+				return true
+			}
+			metadata = make([]byte, storageFolderGranularity*sectorMetadataDiskSize)
+			_, err := sf.metadataFile.ReadAt(metadata, int64(usageIndex*storageFolderGranularity*sectorMetadataDiskSize))
+			sf.mu.RUnlock()
+			cm.tg.Done()
+			if err != nil {
+				atomic.AddUint64(&sf.atomicFailedReads, 1)
+				cm.log.Printf("WARN: unable to read sector metadata of folder %v for scrubbing: %v", sf.path, err)
+				metadata = nil
+			}
+		}
+
+		// Scrub the sectors of the usage element.
+		for bit := uint64(0); bit < storageFolderGranularity && metadata != nil; bit++ {
+			if usageElement&(1<<bit) == 0 {
+				continue
+			}
+			var id sectorID
+			copy(id[:], metadata[bit*sectorMetadataDiskSize:])
+			if err := cm.tg.Add(); err != nil {
+				return false
+			}
+			if !sf.mu.TryRLock() {
+				cm.tg.Done()
+				return true
+			}
+			cm.managedScrubSector(sf, uint32(usageIndex*storageFolderGranularity+bit), id)
+			sf.mu.RUnlock()
+			cm.tg.Done()
+
+			// Wait to stay within the scrub rate.
+			select {
+			case <-cm.tg.StopChan():
+				return false
+			case <-time.After(sectorTransferDelay(rate)):
+			}
+		}
+		atomic.StoreUint64(&sf.atomicScrubPosition, (usageIndex+1)*storageFolderGranularity)
+
+		select {
+		case <-cm.tg.StopChan():
+			return false
+		default:
+		}
+	}
+}
+
+// managedScrubSector reads the sector with the provided id from the provided
+// location and verifies that its data matches its Merkle root. Sectors which
+// don't match are marked as corrupted. The caller needs to hold the thread
+// group and a read lock on the storage folder.
+func (cm *ContractManager) managedScrubSector(sf *storageFolder, index uint32, id sectorID) {
+	cm.wal.managedLockSector(id)
+	defer cm.wal.managedUnlockSector(id)
+
+	// Make sure that the sector is still stored at the location. The metadata
+	// on disk might be outdated.
+	cm.wal.mu.Lock()
+	sl, exists := cm.sectorLocations[id]
+	cm.wal.mu.Unlock()
+	if !exists || sl.storageFolder != sf.index || sl.index != index {
+		return
+	}
+
+	data, err := readSector(sf.sectorFile, index)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		atomic.AddUint64(&sf.atomicScrubReadErrors, 1)
+		cm.log.Printf("WARN: unable to read sector %v of folder %v while scrubbing: %v", index, sf.path, err)
+		return
+	}
+	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+	corrupted := cm.managedSectorID(crypto.MerkleRoot(data)) != id
+
+	cm.wal.mu.Lock()
+	_, marked := cm.corruptSectors[id]
+	if corrupted {
+		cm.corruptSectors[id] = struct{}{}
+	} else {
+		delete(cm.corruptSectors, id)
+	}
+	cm.wal.mu.Unlock()
+	if corrupted && !marked {
+		cm.log.Printf("ERROR: sector %v of folder %v is corrupted", index, sf.path)
+		cm.managedUpdateScrubAlert()
+	}
+}
+
+// managedUpdateScrubAlert forgets about corrupted sectors which are no longer
+// stored by the contract manager and registers an alert if any of the storage
+// folders contain corrupted or unreadable sectors.
+func (cm *ContractManager) managedUpdateScrubAlert() {
+	cm.wal.mu.Lock()
+	corrupt := make(map[uint16]uint64)
+	for id := range cm.corruptSectors {
+		sl, exists := cm.sectorLocations[id]
+		if !exists {
+			delete(cm.corruptSectors, id)
+			continue
+		}
+		corrupt[sl.storageFolder]++
+	}
+	var causes []string
+	for _, sf := range cm.storageFolders {
+		readErrors := atomic.LoadUint64(&sf.atomicScrubReadErrors)
+		if corrupt[sf.index] == 0 && readErrors == 0 {
+			continue
+		}
+		causes = append(causes, fmt.Sprintf("%v: %v corrupt sectors, %v read errors", sf.path, corrupt[sf.index], readErrors))
+	}
+	cm.wal.mu.Unlock()
+
+	if len(causes) == 0 {
+		cm.staticAlerter.UnregisterAlert(modules.AlertIDHostSectorCorruption)
+		return
+	}
+	sort.Strings(causes)
+	cm.staticAlerter.RegisterAlert(modules.AlertIDHostSectorCorruption, AlertMSGHostDiskTrouble, strings.Join(causes, "; "), modules.SeverityCritical)
+}
+
+// managedRepairSector overwrites the data of a sector that was marked as
+// corrupted with the provided data, which needs to match the sector's root.
+// The sector lock needs to be held by the caller.
+func (cm *ContractManager) managedRepairSector(id sectorID, sl sectorLocation, data []byte) error {
+	cm.wal.mu.Lock()
+	sf, exists := cm.storageFolders[sl.storageFolder]
+	cm.wal.mu.Unlock()
+	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return errStorageFolderNotFound
+	}
+	if err := writeSector(sf.sectorFile, sl.index, data); err != nil {
+		atomic.AddUint64(&sf.atomicFailedWrites, 1)
+		return err
+	}
+	if err := sf.sectorFile.Sync(); err != nil {
+		atomic.AddUint64(&sf.atomicFailedWrites, 1)
+		return err
+	}
+	atomic.AddUint64(&sf.atomicSuccessfulWrites, 1)
+
+	cm.wal.mu.Lock()
+	delete(cm.corruptSectors, id)
+	cm.wal.mu.Unlock()
+	cm.log.Printf("Repaired corrupted sector %v of folder %v", sl.index, sf.path)
+	return nil
+}
+
+// SetSectorScrubRate sets the rate in bytes per second at which the contract
+// manager re-reads the stored sectors to verify their integrity. A rate of 0
+// disables the scrubbing.
+func (cm *ContractManager) SetSectorScrubRate(bytesPerSecond uint64) {
+	atomic.StoreUint64(&cm.atomicScrubRate, bytesPerSecond)
+}
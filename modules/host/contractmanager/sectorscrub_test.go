@@ -0,0 +1,128 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestSectorScrub checks that the sector scrubber detects corrupted sectors,
+// reports them and that they can be repaired by adding them again.
+func TestSectorScrub(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and a few sectors.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]crypto.Hash, 5)
+	datas := make([][]byte, len(roots))
+	for i := range roots {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		roots[i], datas[i] = root, data
+	}
+
+	// Corrupt the data of the first sector on disk.
+	cmt.cm.wal.mu.Lock()
+	sl := cmt.cm.sectorLocations[cmt.cm.managedSectorID(roots[0])]
+	cmt.cm.wal.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(storageFolderDir, sectorFile), os.O_RDWR, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteAt(fastrand.Bytes(64), int64(uint64(sl.index)*modules.SectorSize))
+	if err := errors.Compose(err, f.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enable the scrubber and wait for it to complete a pass.
+	cmt.cm.SetSectorScrubRate(modules.SectorSize * 1000)
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		sfs := cmt.cm.StorageFolders()
+		if len(sfs) != 1 {
+			return errors.New("wrong number of storage folders")
+		}
+		if sfs[0].LastScrubTime.IsZero() {
+			return errors.New("scrub pass not completed")
+		}
+		if sfs[0].CorruptSectors != 1 {
+			return errors.New("corrupted sector not detected")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm.SetSectorScrubRate(0)
+
+	// The corrupted sector can't be read anymore but the others can.
+	if _, err := cmt.cm.ReadSector(roots[0]); !errors.Contains(err, ErrSectorCorrupted) {
+		t.Fatal("expected ErrSectorCorrupted, got", err)
+	}
+	for _, root := range roots[1:] {
+		if _, err := cmt.cm.ReadSector(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An alert should be registered for the storage folder.
+	crit, _, _ := cmt.cm.Alerts()
+	var found bool
+	for _, alert := range crit {
+		if alert.Msg == AlertMSGHostDiskTrouble && strings.Contains(alert.Cause, storageFolderDir+": 1 corrupt sectors") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected sector corruption alert", crit)
+	}
+
+	// Adding the sector again repairs it.
+	if err := cmt.cm.AddSector(roots[0], datas[0]); err != nil {
+		t.Fatal(err)
+	}
+	data, err := cmt.cm.ReadSector(roots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, datas[0]) {
+		t.Fatal("repaired sector has wrong data")
+	}
+	if sfs := cmt.cm.StorageFolders(); sfs[0].CorruptSectors != 0 {
+		t.Fatal("sector should no longer be corrupted", sfs[0].CorruptSectors)
+	}
+	cmt.cm.managedUpdateScrubAlert()
+	crit, _, _ = cmt.cm.Alerts()
+	for _, alert := range crit {
+		if alert.Cause != "" && strings.Contains(alert.Cause, "corrupt sectors") {
+			t.Fatal("alert should have been unregistered", alert)
+		}
+	}
+}
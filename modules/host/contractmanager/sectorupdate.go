@@ -251,6 +251,9 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 		// Delete the sector and mark the usage as available.
 		delete(wal.cm.sectorLocations, id)
 		sf.availableSectors[id] = location.index
+		if wal.cm.sectorCache != nil {
+			wal.cm.sectorCache.managedForget(id)
+		}
 
 		// Block until the change has been committed.
 		syncChan = wal.syncChan
@@ -312,6 +315,9 @@ func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
 			// Delete the sector and mark it as available.
 			delete(wal.cm.sectorLocations, id)
 			sf.availableSectors[id] = location.index
+			if wal.cm.sectorCache != nil {
+				wal.cm.sectorCache.managedForget(id)
+			}
 		} else {
 			// Reduce the sector usage.
 			wal.cm.sectorLocations[id] = location
@@ -433,10 +439,25 @@ func (cm *ContractManager) AddSector(root crypto.Hash, sectorData []byte) error
 	// Determine whether the sector is virtual or physical.
 	cm.wal.mu.Lock()
 	location, exists := cm.sectorLocations[id]
+	_, corrupted := cm.corruptSectors[id]
 	cm.wal.mu.Unlock()
+	if exists && corrupted && crypto.MerkleRoot(sectorData) == root {
+		// Use the provided data to repair the corrupted sector.
+		err = cm.managedRepairSector(id, location, sectorData)
+		if err != nil {
+			cm.log.Println("ERROR: Unable to repair corrupted sector:", err)
+		}
+	}
 	if exists {
 		err = cm.wal.managedAddVirtualSector(id, location)
 	} else {
+		// A corrupted sector that was removed in the meantime is stored at a
+		// new location with fresh data.
+		if corrupted {
+			cm.wal.mu.Lock()
+			delete(cm.corruptSectors, id)
+			cm.wal.mu.Unlock()
+		}
 		err = cm.wal.managedAddPhysicalSector(id, sectorData)
 	}
 	if errors.Contains(err, errDiskTrouble) {
@@ -89,11 +89,23 @@ type storageFolder struct {
 	atomicSuccessfulReads  uint64
 	atomicSuccessfulWrites uint64
 
+	// Sector scrubbing statistics. atomicScrubPosition is the index of the
+	// next sector that will be scrubbed and atomicLastScrubTime is the unix
+	// timestamp in nanoseconds of the last completed pass over the folder.
+	atomicScrubReadErrors uint64
+	atomicScrubPosition   uint64
+	atomicLastScrubTime   int64
+
 	// Atomic bool indicating whether or not the storage folder is available. If
 	// the storage folder is not available, it will still be loaded but return
 	// an error if it is queried.
 	atomicUnavailable uint64 // uint64 for alignment
 
+	// Atomic bool indicating whether or not the storage folder is being
+	// evacuated by a rebalance. Evacuated storage folders don't receive new
+	// sectors.
+	atomicEvacuating uint64
+
 	// The index, path, and usage are all saved directly to disk.
 	index uint16
 	path  string
@@ -214,6 +226,11 @@ func vacancyStorageFolder(sfs []*storageFolder) (*storageFolder, int) {
 			continue
 		}
 
+		// Skip past this storage folder if it's being evacuated.
+		if atomic.LoadUint64(&sf.atomicEvacuating) == 1 {
+			continue
+		}
+
 		// Skip past this storage folder if it's not available to receive new
 		// data.
 		if !sf.mu.TryRLock() {
@@ -361,6 +378,7 @@ func (cm *ContractManager) ResetStorageFolderHealth(index uint16) error {
 	atomic.StoreUint64(&sf.atomicFailedWrites, 0)
 	atomic.StoreUint64(&sf.atomicSuccessfulReads, 0)
 	atomic.StoreUint64(&sf.atomicSuccessfulWrites, 0)
+	atomic.StoreUint64(&sf.atomicScrubReadErrors, 0)
 	return nil
 }
 
@@ -414,6 +432,12 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 
 	// Iterate over the storage folders that are in memory first, and then
 	// suppliment them with the storage folders that are not in memory.
+	corrupt := make(map[uint16]uint64)
+	for id := range cm.corruptSectors {
+		if sl, exists := cm.sectorLocations[id]; exists {
+			corrupt[sl.storageFolder]++
+		}
+	}
 	var smfs []modules.StorageFolderMetadata
 	for _, sf := range cm.storageFolders {
 		// Grab the non-computational data.
@@ -430,6 +454,17 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 			CapacityRemaining: ((64 * uint64(len(sf.usage))) - sf.sectors) * modules.SectorSize,
 			Index:             sf.index,
 			Path:              sf.path,
+
+			CorruptSectors:  corrupt[sf.index],
+			ScrubReadErrors: atomic.LoadUint64(&sf.atomicScrubReadErrors),
+
+			Evacuating: atomic.LoadUint64(&sf.atomicEvacuating) == 1,
+		}
+		if len(sf.usage) > 0 {
+			sfm.ScrubProgress = float64(atomic.LoadUint64(&sf.atomicScrubPosition)) / float64(uint64(len(sf.usage))*storageFolderGranularity)
+		}
+		if lastScrub := atomic.LoadInt64(&sf.atomicLastScrubTime); lastScrub != 0 {
+			sfm.LastScrubTime = time.Unix(0, lastScrub)
 		}
 
 		// Set some of the values to extreme numbers if the storage folder is
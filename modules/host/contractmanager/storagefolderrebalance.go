@@ -0,0 +1,358 @@
+package contractmanager
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// ErrRebalanceInProgress is returned if a rebalance is started while
+	// another rebalance is still in progress.
+	ErrRebalanceInProgress = errors.New("a storage folder rebalance is already in progress")
+
+	// errNoRebalanceInProgress is returned if a rebalance is cancelled while
+	// there is no rebalance in progress.
+	errNoRebalanceInProgress = errors.New("no storage folder rebalance in progress")
+
+	// errNoRebalanceTarget is returned if a rebalance would evacuate all of
+	// the available storage folders.
+	errNoRebalanceTarget = errors.New("at least one available storage folder must remain to receive the evacuated sectors")
+
+	// errRebalanceCancelled is returned by a rebalance that was cancelled by
+	// the user.
+	errRebalanceCancelled = errors.New("rebalance was cancelled")
+
+	// errStorageFolderBusy is returned if a storage folder is being added,
+	// removed or resized while a rebalance is trying to move sectors out of
+	// it.
+	errStorageFolderBusy = errors.New("storage folder is busy with another operation")
+)
+
+type (
+	// savedRebalance contains the persisted state of an ongoing storage folder
+	// rebalance, allowing the rebalance to resume after a restart. The sector
+	// moves of the rebalance are made atomic by the WAL.
+	savedRebalance struct {
+		Evacuate []uint16
+		Rate     uint64
+	}
+)
+
+// rebalanceExcess returns the number of sectors that need to be moved out of
+// each storage folder for all storage folders to have the same utilisation.
+func rebalanceExcess(used, capacity map[uint16]uint64) map[uint16]uint64 {
+	var totalUsed, totalCapacity uint64
+	for index := range capacity {
+		totalUsed += used[index]
+		totalCapacity += capacity[index]
+	}
+	excess := make(map[uint16]uint64)
+	if totalCapacity == 0 {
+		return excess
+	}
+	for index, c := range capacity {
+		target := uint64(math.Ceil(float64(totalUsed) * float64(c) / float64(totalCapacity)))
+		if used[index] > target {
+			excess[index] = used[index] - target
+		}
+	}
+	return excess
+}
+
+// RebalanceStorageFolders starts migrating sectors between the storage folders
+// in the background. If 'evacuate' is empty, sectors are moved from the most
+// utilised storage folders to the least utilised storage folders until the
+// utilisation of all storage folders is even. Otherwise all sectors are moved
+// out of the storage folders with the provided indices, which don't receive
+// any new sectors until the rebalance is complete. Sectors are moved at a
+// rate of at most 'rate' bytes per second, or at a default rate if 'rate' is
+// 0.
+func (cm *ContractManager) RebalanceStorageFolders(evacuate []uint16, rate uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+
+	if cm.rebalance != nil {
+		return ErrRebalanceInProgress
+	}
+
+	// Validate the storage folders that should be evacuated.
+	rb := savedRebalance{Rate: rate}
+	evacuating := make(map[uint16]struct{})
+	for _, index := range evacuate {
+		sf, exists := cm.storageFolders[index]
+		if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			return errStorageFolderNotFound
+		}
+		if _, exists := evacuating[index]; exists {
+			continue
+		}
+		evacuating[index] = struct{}{}
+		rb.Evacuate = append(rb.Evacuate, index)
+	}
+	sort.Slice(rb.Evacuate, func(i, j int) bool {
+		return rb.Evacuate[i] < rb.Evacuate[j]
+	})
+
+	// Make sure there is a storage folder left to move the sectors to.
+	var targets int
+	for _, sf := range cm.availableStorageFolders() {
+		if _, exists := evacuating[sf.index]; !exists {
+			targets++
+		}
+	}
+	if targets == 0 || (len(rb.Evacuate) == 0 && targets == 1) {
+		return errNoRebalanceTarget
+	}
+
+	cm.log.Printf("Starting storage folder rebalance, evacuating folders %v", rb.Evacuate)
+	cm.startRebalance(rb)
+	return nil
+}
+
+// CancelStorageFolderRebalance stops the ongoing storage folder rebalance.
+// Sectors which were already moved remain in their new storage folders.
+func (cm *ContractManager) CancelStorageFolderRebalance() error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+
+	if cm.rebalanceCancel == nil {
+		return errNoRebalanceInProgress
+	}
+	close(cm.rebalanceCancel)
+	cm.rebalanceCancel = nil
+	return nil
+}
+
+// startRebalance marks the storage folders which are evacuated by the
+// rebalance and launches the thread performing the rebalance. The WAL lock
+// needs to be held by the caller.
+func (cm *ContractManager) startRebalance(rb savedRebalance) {
+	cancel := make(chan struct{})
+	cm.rebalance = &rb
+	cm.rebalanceCancel = cancel
+	for _, index := range rb.Evacuate {
+		if sf, exists := cm.storageFolders[index]; exists {
+			atomic.StoreUint64(&sf.atomicEvacuating, 1)
+		}
+	}
+	go cm.threadedRebalance(rb, cancel)
+}
+
+// threadedRebalance performs a rebalance and clears its state once it is
+// finished. If the contract manager shuts down during the rebalance, the state
+// is kept so that the rebalance resumes on startup.
+func (cm *ContractManager) threadedRebalance(rb savedRebalance, cancel <-chan struct{}) {
+	err := cm.managedRebalance(rb, cancel)
+	select {
+	case <-cm.tg.StopChan():
+		return
+	default:
+	}
+	if err != nil {
+		cm.log.Println("WARN: storage folder rebalance stopped:", err)
+	} else {
+		cm.log.Println("Storage folder rebalance completed")
+	}
+
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for _, index := range rb.Evacuate {
+		if sf, exists := cm.storageFolders[index]; exists {
+			atomic.StoreUint64(&sf.atomicEvacuating, 0)
+		}
+	}
+	cm.rebalance = nil
+	cm.rebalanceCancel = nil
+}
+
+// managedRebalance determines how many sectors need to be moved out of each
+// storage folder and moves them.
+func (cm *ContractManager) managedRebalance(rb savedRebalance, cancel <-chan struct{}) error {
+	rate := rb.Rate
+	if rate == 0 {
+		rate = defaultRebalanceRate
+	}
+	evacuate := make(map[uint16]struct{})
+	for _, index := range rb.Evacuate {
+		evacuate[index] = struct{}{}
+	}
+
+	// Determine how many sectors need to be moved out of each storage folder.
+	excess := make(map[uint16]uint64)
+	cm.wal.mu.Lock()
+	if len(evacuate) > 0 {
+		for index := range evacuate {
+			if sf, exists := cm.storageFolders[index]; exists {
+				excess[index] = sf.sectors
+			}
+		}
+	} else {
+		used := make(map[uint16]uint64)
+		capacity := make(map[uint16]uint64)
+		for _, sf := range cm.availableStorageFolders() {
+			used[sf.index] = sf.sectors
+			capacity[sf.index] = uint64(len(sf.usage)) * storageFolderGranularity
+		}
+		excess = rebalanceExcess(used, capacity)
+	}
+	cm.wal.mu.Unlock()
+
+	sources := make([]uint16, 0, len(excess))
+	for index := range excess {
+		sources = append(sources, index)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i] < sources[j]
+	})
+	for _, index := range sources {
+		err := cm.managedRebalanceFolder(index, excess[index], len(evacuate) > 0, sectorTransferDelay(rate), cancel)
+		if err != nil {
+			return errors.AddContext(err, fmt.Sprintf("unable to move sectors out of storage folder %v", index))
+		}
+	}
+	return nil
+}
+
+// managedRebalanceFolder moves up to 'n' sectors out of the storage folder
+// with the provided index, waiting 'delay' after every sector.
+func (cm *ContractManager) managedRebalanceFolder(index uint16, n uint64, evacuate bool, delay time.Duration, cancel <-chan struct{}) error {
+	if n == 0 {
+		return nil
+	}
+
+	// Read the metadata of the storage folder to learn which sectors it
+	// contains.
+	if err := cm.tg.Add(); err != nil {
+		return err
+	}
+	cm.wal.mu.Lock()
+	sf, exists := cm.storageFolders[index]
+	cm.wal.mu.Unlock()
+	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		cm.tg.Done()
+		return errStorageFolderNotFound
+	}
+	if !sf.mu.TryRLock() {
+		cm.tg.Done()
+		return errStorageFolderBusy
+	}
+	cm.wal.mu.Lock()
+	usage := append([]uint64(nil), sf.usage...)
+	cm.wal.mu.Unlock()
+	metadata, err := readFullMetadata(sf.metadataFile, len(usage)*storageFolderGranularity)
+	sf.mu.RUnlock()
+	cm.tg.Done()
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		return errors.AddContext(err, "unable to read sector metadata")
+	}
+	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+
+	// Select the sectors to move. The metadata on disk might be outdated, so
+	// only sectors which are still stored at the same location are selected.
+	var ids []sectorID
+	cm.wal.mu.Lock()
+	for _, sectorIndex := range usageSectors(usage) {
+		if uint64(len(ids)) >= n {
+			break
+		}
+		var id sectorID
+		copy(id[:], metadata[sectorMetadataDiskSize*sectorIndex:])
+		if sl, exists := cm.sectorLocations[id]; exists && sl.storageFolder == index && sl.index == sectorIndex {
+			ids = append(ids, id)
+		}
+	}
+	cm.wal.mu.Unlock()
+
+	// Report the progress of the rebalance through the progress fields of the
+	// storage folder.
+	atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+	atomic.StoreUint64(&sf.atomicProgressDenominator, uint64(len(ids))*modules.SectorSize)
+	defer func() {
+		atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+		atomic.StoreUint64(&sf.atomicProgressDenominator, 0)
+	}()
+
+	for _, id := range ids {
+		err := cm.managedRebalanceSector(sf, id, evacuate)
+		if err != nil {
+			return err
+		}
+		atomic.AddUint64(&sf.atomicProgressNumerator, modules.SectorSize)
+
+		// Wait to stay within the rate limit.
+		select {
+		case <-cancel:
+			return errRebalanceCancelled
+		case <-cm.tg.StopChan():
+			return errors.New("contract manager is shutting down")
+		case <-time.After(delay):
+		}
+	}
+	return nil
+}
+
+// managedRebalanceSector moves a single sector out of the provided storage
+// folder. When evacuating, the sector is moved to any storage folder that is
+// not being evacuated. Otherwise it is moved to the least utilised storage
+// folder.
+func (cm *ContractManager) managedRebalanceSector(source *storageFolder, id sectorID, evacuate bool) error {
+	if err := cm.tg.Add(); err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+
+	// Prevent the storage folder from being removed or resized while the
+	// sector is moved out of it.
+	if !source.mu.TryRLock() {
+		return errStorageFolderBusy
+	}
+	defer source.mu.RUnlock()
+
+	// Pick the storage folders that the sector can be moved to.
+	var targets []*storageFolder
+	cm.wal.mu.Lock()
+	for _, sf := range cm.availableStorageFolders() {
+		if sf == source || atomic.LoadUint64(&sf.atomicEvacuating) == 1 {
+			continue
+		}
+		if !evacuate {
+			capacity := uint64(len(sf.usage)) * storageFolderGranularity
+			if sf.sectors >= capacity {
+				continue
+			}
+			if len(targets) > 0 && sf.sectors*uint64(len(targets[0].usage)) >= targets[0].sectors*uint64(len(sf.usage)) {
+				continue
+			}
+			targets = targets[:0]
+		}
+		targets = append(targets, sf)
+	}
+	cm.wal.mu.Unlock()
+	if len(targets) == 0 {
+		return errors.New(modules.V1420HostOutOfStorageErrString)
+	}
+
+	err := cm.wal.managedMoveSectorToFolders(id, targets)
+	if errors.Contains(err, errDiskTrouble) {
+		cm.staticAlerter.RegisterAlert(modules.AlertIDHostDiskTrouble, AlertMSGHostDiskTrouble, "", modules.SeverityCritical)
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package contractmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestRebalanceExcess probes the rebalanceExcess function.
+func TestRebalanceExcess(t *testing.T) {
+	t.Parallel()
+
+	// A single folder never needs to be rebalanced.
+	if excess := rebalanceExcess(map[uint16]uint64{1: 10}, map[uint16]uint64{1: 64}); len(excess) != 0 {
+		t.Fatal("unexpected excess", excess)
+	}
+
+	// 90 sectors on 3 folders of equal size.
+	used := map[uint16]uint64{1: 60, 2: 30, 3: 0}
+	capacity := map[uint16]uint64{1: 64, 2: 64, 3: 64}
+	excess := rebalanceExcess(used, capacity)
+	if len(excess) != 1 || excess[1] != 30 {
+		t.Fatal("unexpected excess", excess)
+	}
+
+	// Folders of different sizes should end up with the same utilisation.
+	used = map[uint16]uint64{1: 64, 2: 0}
+	capacity = map[uint16]uint64{1: 64, 2: 192}
+	excess = rebalanceExcess(used, capacity)
+	if len(excess) != 1 || excess[1] != 48 {
+		t.Fatal("unexpected excess", excess)
+	}
+
+	// No capacity, no excess.
+	if excess := rebalanceExcess(nil, nil); len(excess) != 0 {
+		t.Fatal("unexpected excess", excess)
+	}
+}
+
+// TestRebalanceStorageFolders checks that sectors are moved between storage
+// folders to even out the utilisation and to evacuate storage folders, and
+// that an interrupted rebalance is resumed after a restart.
+func TestRebalanceStorageFolders(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cmt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// addFolder is a helper to add a storage folder.
+	addFolder := func(name string) string {
+		dir := filepath.Join(cmt.persistDir, name)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := cmt.cm.AddStorageFolder(dir, modules.SectorSize*storageFolderGranularity); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	// folderSectors is a helper that returns the number of sectors in each
+	// storage folder by path.
+	folderSectors := func() map[string]uint64 {
+		sectors := make(map[string]uint64)
+		for _, sf := range cmt.cm.StorageFolders() {
+			sectors[sf.Path] = (sf.Capacity - sf.CapacityRemaining) / modules.SectorSize
+		}
+		return sectors
+	}
+	// folderIndex is a helper that returns the index of the storage folder
+	// with the provided path.
+	folderIndex := func(path string) uint16 {
+		for _, sf := range cmt.cm.StorageFolders() {
+			if sf.Path == path {
+				return sf.Index
+			}
+		}
+		t.Fatal("storage folder not found", path)
+		return 0
+	}
+	// waitForRebalance is a helper that waits for the rebalance to finish.
+	waitForRebalance := func() {
+		err := build.Retry(200, 50*time.Millisecond, func() error {
+			cmt.cm.wal.mu.Lock()
+			defer cmt.cm.wal.mu.Unlock()
+			if cmt.cm.rebalance != nil {
+				return errors.New("rebalance still in progress")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Fill a single storage folder with sectors and then add an empty one.
+	dir1 := addFolder("folder1")
+	var roots []crypto.Hash
+	for i := 0; i < 40; i++ {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+	dir2 := addFolder("folder2")
+
+	// Rebalance the folders, they should end up with 20 sectors each.
+	if err := cmt.cm.RebalanceStorageFolders(nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	waitForRebalance()
+	if sectors := folderSectors(); sectors[dir1] != 20 || sectors[dir2] != 20 {
+		t.Fatal("storage folders weren't rebalanced", sectors)
+	}
+
+	// Evacuating every storage folder isn't possible.
+	if err := cmt.cm.RebalanceStorageFolders([]uint16{folderIndex(dir1), folderIndex(dir2)}, 0); !errors.Contains(err, errNoRebalanceTarget) {
+		t.Fatal("expected errNoRebalanceTarget, got", err)
+	}
+	if err := cmt.cm.CancelStorageFolderRebalance(); !errors.Contains(err, errNoRebalanceInProgress) {
+		t.Fatal("expected errNoRebalanceInProgress, got", err)
+	}
+
+	// Start evacuating the first folder slowly and restart the contract
+	// manager before the evacuation completes.
+	if err := cmt.cm.RebalanceStorageFolders([]uint16{folderIndex(dir1)}, 4*modules.SectorSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.RebalanceStorageFolders(nil, 0); !errors.Contains(err, ErrRebalanceInProgress) {
+		t.Fatal("expected ErrRebalanceInProgress, got", err)
+	}
+	time.Sleep(time.Second)
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Evacuating != (sf.Path == dir1) {
+			t.Fatal("wrong evacuation status", sf.Path, sf.Evacuating)
+		}
+	}
+	if sectors := folderSectors(); sectors[dir1] == 0 || sectors[dir1] == 20 {
+		t.Fatal("evacuation didn't make progress or finished too quickly", sectors)
+	}
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The evacuation should resume and complete.
+	waitForRebalance()
+	if sectors := folderSectors(); sectors[dir1] != 0 || sectors[dir2] != 40 {
+		t.Fatal("storage folder wasn't evacuated", sectors)
+	}
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Evacuating {
+			t.Fatal("storage folder is still marked as evacuating", sf.Path)
+		}
+	}
+	for _, root := range roots {
+		data, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if crypto.MerkleRoot(data) != root {
+			t.Fatal("sector data corrupted by the rebalance")
+		}
+	}
+
+	// Cancel a rebalance.
+	if err := cmt.cm.RebalanceStorageFolders(nil, modules.SectorSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.CancelStorageFolderRebalance(); err != nil {
+		t.Fatal(err)
+	}
+	waitForRebalance()
+	if sectors := folderSectors(); sectors[dir1] > 2 {
+		t.Fatal("rebalance wasn't cancelled", sectors)
+	}
+}
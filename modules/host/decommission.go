@@ -0,0 +1,281 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/contractmanager"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errAlreadyDecommissioning is returned when the host is asked to start
+	// decommissioning while it is already decommissioning.
+	errAlreadyDecommissioning = errors.New("host is already decommissioning")
+
+	// errNotDecommissioning is returned when the host is asked to cancel
+	// decommissioning while it isn't decommissioning.
+	errNotDecommissioning = errors.New("host is not decommissioning")
+)
+
+// CancelDecommission stops the decommissioning of the host. The host starts
+// forming and renewing contracts again if it is configured to accept
+// contracts. Storage folders that were already released are not added back.
+func (h *Host) CancelDecommission() error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.decommission.Active {
+		return errNotDecommissioning
+	}
+	h.decommission = modules.HostDecommissionStatus{}
+	h.staticAlerter.UnregisterAlert(modules.AlertIDHostUnprovableObligations)
+	h.revisionNumber++
+	h.log.Println("Host decommissioning was cancelled")
+	return h.saveSync()
+}
+
+// Decommission puts the host into decommission mode. While decommissioning
+// the host doesn't form or renew contracts, keeps track of the storage proofs
+// it still needs to submit and releases its storage folders as their sectors
+// expire.
+func (h *Host) Decommission() error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	if h.decommission.Active {
+		h.mu.Unlock()
+		return errAlreadyDecommissioning
+	}
+	h.decommission = modules.HostDecommissionStatus{
+		Active:      true,
+		StartHeight: h.blockHeight,
+	}
+	h.revisionNumber++
+	err := h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "failed to save host")
+	}
+	h.log.Println("Host started decommissioning at height", h.BlockHeight())
+
+	// Compute the initial progress.
+	h.managedUpdateDecommission()
+	return nil
+}
+
+// DecommissionStatus returns the progress of the host's decommissioning.
+func (h *Host) DecommissionStatus() modules.HostDecommissionStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	status := h.decommission
+	status.UnprovableObligations = append([]modules.HostUnprovableObligation(nil), status.UnprovableObligations...)
+	status.ReleasedFolders = append([]string(nil), status.ReleasedFolders...)
+	return status
+}
+
+// threadedUpdateDecommission updates the progress of the host's
+// decommissioning in the background.
+func (h *Host) threadedUpdateDecommission() {
+	if err := h.tg.Add(); err != nil {
+		return
+	}
+	defer h.tg.Done()
+	h.managedUpdateDecommission()
+}
+
+// managedUpdateDecommission recomputes the obligations the host still needs to
+// submit storage proofs for, checks that the host is able to prove them and
+// releases the storage folders which are no longer needed.
+func (h *Host) managedUpdateDecommission() {
+	// Only one update may run at a time.
+	h.decommissionMu.Lock()
+	defer h.decommissionMu.Unlock()
+
+	h.mu.RLock()
+	active := h.decommission.Active
+	bh := h.blockHeight
+	h.mu.RUnlock()
+	if !active {
+		return
+	}
+
+	// Collect the unresolved obligations. The host needs to submit a storage
+	// proof for all of them unless the proof was already confirmed or the
+	// contract was renewed.
+	var unresolved uint64
+	var obligations []storageObligation
+	h.mu.RLock()
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation:", err)
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			unresolved++
+			if so.ProofConfirmed || !so.requiresProof() {
+				return nil
+			}
+			obligations = append(obligations, so)
+			return nil
+		})
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		h.log.Println("ERROR: unable to update decommission status:", err)
+		return
+	}
+
+	// Compute the progress and check whether the host can prove the
+	// remaining obligations.
+	status := modules.HostDecommissionStatus{
+		ObligationsRemaining:       uint64(len(obligations)),
+		CollateralAtRisk:           types.ZeroCurrency,
+		EarliestSafeShutdownHeight: bh,
+	}
+	for _, so := range obligations {
+		status.CollateralAtRisk = status.CollateralAtRisk.Add(so.LockedCollateral)
+		deadline := so.proofDeadline()
+		if deadline > status.LastObligationHeight {
+			status.LastObligationHeight = deadline
+		}
+		var unprovable uint64
+		var firstErr error
+		for _, root := range so.SectorRoots {
+			if err := h.CheckSector(root); err != nil {
+				unprovable++
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+		if unprovable > 0 {
+			status.UnprovableObligations = append(status.UnprovableObligations, modules.HostUnprovableObligation{
+				ObligationID:      so.id(),
+				ProofDeadline:     deadline,
+				LockedCollateral:  so.LockedCollateral,
+				UnprovableSectors: unprovable,
+				Error:             firstErr.Error(),
+			})
+		}
+	}
+	if status.LastObligationHeight > status.EarliestSafeShutdownHeight {
+		status.EarliestSafeShutdownHeight = status.LastObligationHeight
+	}
+
+	// Release the storage folders. As long as there are unresolved
+	// obligations, renters may still upload data to the host, so the host
+	// keeps at least one storage folder.
+	released := h.managedReleaseStorageFolders(unresolved > 0)
+
+	// Update the status unless decommissioning was cancelled in the meantime.
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.decommission.Active {
+		return
+	}
+	status.Active = true
+	status.StartHeight = h.decommission.StartHeight
+	status.ReleasedFolders = append(h.decommission.ReleasedFolders, released...)
+	h.decommission = status
+
+	// Warn about obligations which can't be proven while there is still time
+	// to fix the storage folders.
+	if n := len(status.UnprovableObligations); n > 0 {
+		msg := fmt.Sprintf("host is decommissioning but can't submit storage proofs for %v obligations", n)
+		cause := status.UnprovableObligations[0].Error
+		h.staticAlerter.RegisterAlert(modules.AlertIDHostUnprovableObligations, msg, cause, modules.SeverityError)
+	} else {
+		h.staticAlerter.UnregisterAlert(modules.AlertIDHostUnprovableObligations)
+	}
+	if err := h.saveSync(); err != nil {
+		h.log.Println("ERROR: unable to save decommission status:", err)
+	}
+}
+
+// managedReleaseStorageFolders removes the storage folders whose sectors fit
+// into the remaining folders, starting with the emptiest one. Empty folders
+// are removed right away while the sectors of other folders are moved into
+// the remaining folders first. If a folder's sectors don't fit, the folder is
+// shrunk as far as the free space of the remaining folders allows instead. If
+// keepOne is set, the last folder is never removed. The paths of the removed
+// folders are returned.
+func (h *Host) managedReleaseStorageFolders(keepOne bool) []string {
+	h.mu.RLock()
+	active := h.decommission.Active
+	h.mu.RUnlock()
+	if !active {
+		return nil
+	}
+
+	folders := h.StorageFolders()
+	sort.Slice(folders, func(i, j int) bool {
+		return folders[i].Capacity-folders[i].CapacityRemaining < folders[j].Capacity-folders[j].CapacityRemaining
+	})
+	var free uint64
+	for _, sf := range folders {
+		free += sf.CapacityRemaining
+	}
+
+	var released []string
+	remaining := len(folders)
+	for _, sf := range folders {
+		if keepOne && remaining <= 1 {
+			break
+		}
+		used := sf.Capacity - sf.CapacityRemaining
+		freeElsewhere := free - sf.CapacityRemaining
+
+		// Evacuate the folder if the other folders have room for its
+		// sectors.
+		if used <= freeElsewhere {
+			if err := h.RemoveStorageFolder(sf.Index, false); err != nil {
+				h.log.Printf("WARN: unable to release storage folder %v: %v", sf.Path, err)
+				continue
+			}
+			h.log.Printf("Released storage folder %v after moving %v sectors", sf.Path, used/modules.SectorSize)
+			released = append(released, sf.Path)
+			free -= sf.CapacityRemaining + used
+			remaining--
+			continue
+		}
+
+		// Otherwise shrink the folder by as many sectors as the other folders
+		// can take. The folders are sorted by usage, so the following folders
+		// won't fit into the remaining free space either.
+		sectors := sf.Capacity / modules.SectorSize
+		newSectors := sectors - freeElsewhere/modules.SectorSize
+		if rem := newSectors % contractManagerStorageFolderGranularity; rem != 0 {
+			newSectors += contractManagerStorageFolderGranularity - rem
+		}
+		if newSectors < contractmanager.MinimumSectorsPerStorageFolder {
+			newSectors = contractmanager.MinimumSectorsPerStorageFolder
+		}
+		if newSectors >= sectors {
+			break
+		}
+		if err := h.ResizeStorageFolder(sf.Index, newSectors*modules.SectorSize, false); err != nil {
+			h.log.Printf("WARN: unable to shrink storage folder %v: %v", sf.Path, err)
+			break
+		}
+		h.log.Printf("Shrunk storage folder %v from %v to %v sectors", sf.Path, sectors, newSectors)
+		break
+	}
+	return released
+}
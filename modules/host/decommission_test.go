@@ -0,0 +1,205 @@
+package host
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestDecommission tests decommissioning a host.
+func TestDecommission(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := ht.host
+
+	// Add a storage obligation that requires a proof and contains a sector.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validPayouts, missedPayouts := so.payouts()
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:              so.id(),
+			NewRevisionNumber:     1,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+		}},
+	}}
+	h.managedLockStorageObligation(so.id())
+	err = h.managedAddStorageObligation(so)
+	h.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	h.managedLockStorageObligation(so.id())
+	err = h.managedModifyStorageObligation(so, nil, map[crypto.Hash][]byte{sectorRoot: sectorData})
+	h.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Start decommissioning.
+	is := h.InternalSettings()
+	is.AcceptingContracts = true
+	err = h.SetInternalSettings(is)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should accept contracts")
+	}
+	err = h.Decommission()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Decommission(); !errors.Contains(err, errAlreadyDecommissioning) {
+		t.Fatal("expected errAlreadyDecommissioning, got", err)
+	}
+	if h.ExternalSettings().AcceptingContracts {
+		t.Fatal("decommissioning host shouldn't accept contracts")
+	}
+
+	// The obligation should remain and one of the two storage folders should
+	// have been released.
+	status := h.DecommissionStatus()
+	if !status.Active || status.StartHeight != h.BlockHeight() {
+		t.Fatal("wrong status", status)
+	}
+	if status.ObligationsRemaining != 1 || !status.CollateralAtRisk.Equals(so.LockedCollateral) {
+		t.Fatal("wrong remaining obligations", status)
+	}
+	if status.LastObligationHeight != so.proofDeadline() || status.EarliestSafeShutdownHeight != so.proofDeadline() {
+		t.Fatal("wrong shutdown height", status)
+	}
+	if len(status.UnprovableObligations) != 0 {
+		t.Fatal("obligation should be provable", status.UnprovableObligations)
+	}
+	if len(status.ReleasedFolders) != 1 || len(h.StorageFolders()) != 1 {
+		t.Fatal("expected one released folder", status.ReleasedFolders)
+	}
+
+	// Delete the sector. The obligation can't be proven anymore.
+	_, errs, _ := h.staticAlerter.Alerts()
+	numAlerts := len(errs)
+	err = h.DeleteSector(sectorRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.managedUpdateDecommission()
+	status = h.DecommissionStatus()
+	if len(status.UnprovableObligations) != 1 {
+		t.Fatal("expected one unprovable obligation", status.UnprovableObligations)
+	}
+	uo := status.UnprovableObligations[0]
+	if uo.ObligationID != so.id() || uo.UnprovableSectors != 1 || uo.ProofDeadline != so.proofDeadline() {
+		t.Fatal("wrong unprovable obligation", uo)
+	}
+	_, errs, _ = h.staticAlerter.Alerts()
+	if len(errs) != numAlerts+1 {
+		t.Fatal("expected alert for unprovable obligations")
+	}
+
+	// The last storage folder is kept while there are obligations.
+	if len(h.StorageFolders()) != 1 {
+		t.Fatal("last storage folder shouldn't be released")
+	}
+
+	// Cancel decommissioning.
+	err = h.CancelDecommission()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.CancelDecommission(); !errors.Contains(err, errNotDecommissioning) {
+		t.Fatal("expected errNotDecommissioning, got", err)
+	}
+	if h.DecommissionStatus().Active || !h.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should no longer be decommissioning")
+	}
+	_, errs, _ = h.staticAlerter.Alerts()
+	if len(errs) != numAlerts {
+		t.Fatal("alert should have been unregistered")
+	}
+}
+
+// TestDecommissionEvacuate tests that a decommissioning host releases a
+// storage folder that still contains sectors by moving them to the remaining
+// folder.
+func TestDecommissionEvacuate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := ht.host
+
+	// Add sectors until both storage folders contain at least one.
+	inUse := func() int {
+		var n int
+		for _, sf := range h.StorageFolders() {
+			if sf.CapacityRemaining < sf.Capacity {
+				n++
+			}
+		}
+		return n
+	}
+	var roots []crypto.Hash
+	for i := 0; inUse() < 2; i++ {
+		if i == 50 {
+			t.Fatal("sectors weren't added to both folders")
+		}
+		root, data := randSector()
+		if err := h.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+
+	// Start decommissioning. The emptier folder should be evacuated into the
+	// other one.
+	err = h.Decommission()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := h.DecommissionStatus()
+	folders := h.StorageFolders()
+	if len(status.ReleasedFolders) != 1 || len(folders) != 1 {
+		t.Fatal("expected one released folder", status.ReleasedFolders)
+	}
+	if used := (folders[0].Capacity - folders[0].CapacityRemaining) / modules.SectorSize; used != uint64(len(roots)) {
+		t.Fatalf("remaining folder should contain %v sectors but contains %v", len(roots), used)
+	}
+	for _, root := range roots {
+		if err := h.CheckSector(root); err != nil {
+			t.Fatal("sector wasn't moved", err)
+		}
+	}
+}
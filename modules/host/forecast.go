@@ -0,0 +1,204 @@
+package host
+
+import (
+	"encoding/json"
+	"sort"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errForecastNoBlocks is returned when a forecast over zero blocks is
+	// requested.
+	errForecastNoBlocks = errors.New("forecast needs to cover at least one block")
+)
+
+// forecastObligation contains the information about a storage obligation that
+// is required to forecast its proof window.
+type forecastObligation struct {
+	id               types.FileContractID
+	windowStart      types.BlockHeight
+	windowEnd        types.BlockHeight
+	revenue          types.Currency
+	lockedCollateral types.Currency
+	riskedCollateral types.Currency
+
+	// fees are the estimated fees for submitting the revision and the storage
+	// proof and payout is the host's valid proof output.
+	fees   types.Currency
+	payout types.Currency
+}
+
+// estimatedStorageProofSize returns the estimated size of a storage proof for a
+// file of the given size.
+func estimatedStorageProofSize(fileSize uint64) uint64 {
+	leaves := crypto.CalculateLeaves(fileSize)
+	numHashes := 0
+	for uint64(1)<<uint(numHashes) < leaves {
+		numHashes++
+	}
+	return uint64(len(encoding.Marshal(types.StorageProof{
+		HashSet: make([]crypto.Hash, numHashes),
+	})))
+}
+
+// forecastContracts computes the forecast for the given obligations. The
+// wallet balance is simulated by processing the proof windows in order. The
+// fees of a proof are paid when its window starts and the payout becomes
+// spendable once it matured after the end of the window. If the balance is too
+// low to pay the fees when a window starts, the obligation is underfunded and
+// its payout is not taken into account.
+func forecastContracts(obligations []forecastObligation, start, end, maturityDelay types.BlockHeight, balance types.Currency) modules.HostContractForecast {
+	forecast := modules.HostContractForecast{
+		StartHeight:      start,
+		EndHeight:        end,
+		ExpectedRevenue:  types.ZeroCurrency,
+		CollateralAtRisk: types.ZeroCurrency,
+		RequiredBalance:  types.ZeroCurrency,
+		WalletBalance:    balance,
+		Heights:          []modules.HostForecastHeight{},
+		ProofWindows:     []modules.HostForecastProofWindow{},
+	}
+	sort.Slice(obligations, func(i, j int) bool {
+		if obligations[i].windowStart != obligations[j].windowStart {
+			return obligations[i].windowStart < obligations[j].windowStart
+		}
+		return obligations[i].windowEnd < obligations[j].windowEnd
+	})
+
+	// Process the proof windows.
+	type payout struct {
+		height types.BlockHeight
+		value  types.Currency
+	}
+	var payouts []payout
+	heights := make(map[types.BlockHeight]*modules.HostForecastHeight)
+	for _, o := range obligations {
+		// Add the payouts that matured by the time the window starts.
+		remaining := payouts[:0]
+		for _, p := range payouts {
+			if p.height <= o.windowStart {
+				balance = balance.Add(p.value)
+			} else {
+				remaining = append(remaining, p)
+			}
+		}
+		payouts = remaining
+
+		// Pay the fees.
+		underfunded := balance.Cmp(o.fees) < 0
+		if underfunded {
+			forecast.UnderfundedObligations++
+		} else {
+			balance = balance.Sub(o.fees)
+			payouts = append(payouts, payout{
+				height: o.windowEnd + maturityDelay,
+				value:  o.payout,
+			})
+		}
+
+		forecast.ExpectedRevenue = forecast.ExpectedRevenue.Add(o.revenue)
+		forecast.CollateralAtRisk = forecast.CollateralAtRisk.Add(o.lockedCollateral)
+		forecast.RequiredBalance = forecast.RequiredBalance.Add(o.fees)
+		forecast.ProofWindows = append(forecast.ProofWindows, modules.HostForecastProofWindow{
+			ObligationID:     o.id,
+			WindowStart:      o.windowStart,
+			WindowEnd:        o.windowEnd,
+			ExpectedRevenue:  o.revenue,
+			LockedCollateral: o.lockedCollateral,
+			RiskedCollateral: o.riskedCollateral,
+			RequiredFees:     o.fees,
+			Underfunded:      underfunded,
+		})
+
+		// Add the revenue to the end of the window.
+		h, exists := heights[o.windowEnd]
+		if !exists {
+			h = &modules.HostForecastHeight{
+				Height:             o.windowEnd,
+				ExpectedRevenue:    types.ZeroCurrency,
+				CollateralReleased: types.ZeroCurrency,
+			}
+			heights[o.windowEnd] = h
+		}
+		h.Obligations++
+		h.ExpectedRevenue = h.ExpectedRevenue.Add(o.revenue)
+		h.CollateralReleased = h.CollateralReleased.Add(o.lockedCollateral)
+	}
+	for _, h := range heights {
+		forecast.Heights = append(forecast.Heights, *h)
+	}
+	sort.Slice(forecast.Heights, func(i, j int) bool {
+		return forecast.Heights[i].Height < forecast.Heights[j].Height
+	})
+	return forecast
+}
+
+// ContractForecast returns a forecast of the storage proofs the host needs to
+// submit within the given number of blocks. It contains the proof windows that
+// start before the end of the forecast and haven't ended yet.
+func (h *Host) ContractForecast(blocks types.BlockHeight) (modules.HostContractForecast, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostContractForecast{}, err
+	}
+	defer h.tg.Done()
+	if blocks == 0 {
+		return modules.HostContractForecast{}, errForecastNoBlocks
+	}
+
+	balance, _, _, err := h.wallet.ConfirmedBalance()
+	if err != nil {
+		return modules.HostContractForecast{}, errors.AddContext(err, "failed to get wallet balance")
+	}
+	_, maxFee := h.tpool.FeeEstimation()
+
+	// Collect the obligations the host needs to submit a storage proof for.
+	h.mu.RLock()
+	start := h.blockHeight
+	end := start + blocks
+	var obligations []forecastObligation
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation:", err)
+			}
+			if so.ObligationStatus != obligationUnresolved || so.ProofConfirmed || !so.requiresProof() {
+				return nil
+			}
+			if so.proofDeadline() < start || so.expiration() > end {
+				return nil
+			}
+
+			// Estimate the fees for the revision and the storage proof.
+			size := estimatedStorageProofSize(so.fileSize()) + txnFeeSizeBuffer
+			if len(so.RevisionTransactionSet) > 0 && !so.RevisionConfirmed {
+				size += uint64(len(encoding.MarshalAll(so.RevisionTransactionSet))) + txnFeeSizeBuffer
+			}
+			valid, _ := so.payouts()
+			obligations = append(obligations, forecastObligation{
+				id:               so.id(),
+				windowStart:      so.expiration(),
+				windowEnd:        so.proofDeadline(),
+				revenue:          so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue).Add(so.PotentialAccountFunding),
+				lockedCollateral: so.LockedCollateral,
+				riskedCollateral: so.RiskedCollateral,
+				fees:             maxFee.Mul64(size),
+				payout:           valid[1].Value,
+			})
+			return nil
+		})
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		return modules.HostContractForecast{}, errors.AddContext(err, "failed to fetch storage obligations")
+	}
+	return forecastContracts(obligations, start, end, types.MaturityDelay, balance), nil
+}
@@ -0,0 +1,163 @@
+package host
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestEstimatedStorageProofSize is a unit test for estimatedStorageProofSize.
+func TestEstimatedStorageProofSize(t *testing.T) {
+	// An empty file doesn't need any hashes.
+	base := estimatedStorageProofSize(0)
+	if base != estimatedStorageProofSize(crypto.SegmentSize) {
+		t.Fatal("proof for a single segment shouldn't need any hashes")
+	}
+	// A sector needs one hash per level of the tree.
+	levels := uint64(0)
+	for 1<<levels < modules.SectorSize/crypto.SegmentSize {
+		levels++
+	}
+	if size := estimatedStorageProofSize(modules.SectorSize); size != base+levels*crypto.HashSize {
+		t.Fatal("wrong proof size", size, base+levels*crypto.HashSize)
+	}
+}
+
+// TestForecastContracts is a unit test for forecastContracts.
+func TestForecastContracts(t *testing.T) {
+	obligation := func(id byte, start, end types.BlockHeight, fees uint64) forecastObligation {
+		return forecastObligation{
+			id:               types.FileContractID{id},
+			windowStart:      start,
+			windowEnd:        end,
+			revenue:          types.NewCurrency64(10),
+			lockedCollateral: types.NewCurrency64(90),
+			riskedCollateral: types.NewCurrency64(20),
+			fees:             types.NewCurrency64(fees),
+			payout:           types.NewCurrency64(100),
+		}
+	}
+	obligations := []forecastObligation{
+		obligation(3, 40, 50, 50),
+		obligation(1, 10, 20, 5),
+		obligation(2, 15, 20, 5),
+	}
+
+	// The wallet can pay the fees of the first window. The second window is
+	// underfunded because the payout of the first one hasn't matured yet. The
+	// third window is funded by the payout of the first one.
+	f := forecastContracts(obligations, 5, 45, 10, types.NewCurrency64(8))
+	if f.StartHeight != 5 || f.EndHeight != 45 || !f.WalletBalance.Equals64(8) {
+		t.Fatal("wrong forecast", f)
+	}
+	if !f.ExpectedRevenue.Equals64(30) || !f.CollateralAtRisk.Equals64(270) || !f.RequiredBalance.Equals64(60) {
+		t.Fatal("wrong totals", f)
+	}
+	if f.UnderfundedObligations != 1 || len(f.ProofWindows) != 3 {
+		t.Fatal("wrong proof windows", f.ProofWindows)
+	}
+	for i, pw := range f.ProofWindows {
+		if pw.ObligationID != (types.FileContractID{byte(i + 1)}) {
+			t.Fatal("proof windows aren't sorted", f.ProofWindows)
+		}
+		if pw.Underfunded != (i == 1) {
+			t.Fatal("wrong underfunded window", i, pw)
+		}
+	}
+	if len(f.Heights) != 2 {
+		t.Fatal("wrong heights", f.Heights)
+	}
+	if h := f.Heights[0]; h.Height != 20 || h.Obligations != 2 || !h.ExpectedRevenue.Equals64(20) || !h.CollateralReleased.Equals64(180) {
+		t.Fatal("wrong height", h)
+	}
+	if h := f.Heights[1]; h.Height != 50 || h.Obligations != 1 || !h.ExpectedRevenue.Equals64(10) {
+		t.Fatal("wrong height", h)
+	}
+
+	// An empty forecast.
+	f = forecastContracts(nil, 5, 45, 10, types.ZeroCurrency)
+	if len(f.Heights) != 0 || len(f.ProofWindows) != 0 || f.UnderfundedObligations != 0 {
+		t.Fatal("expected empty forecast", f)
+	}
+}
+
+// TestContractForecast tests forecasting the proof window of a storage
+// obligation.
+func TestContractForecast(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := ht.host
+
+	if _, err := h.ContractForecast(0); !errors.Contains(err, errForecastNoBlocks) {
+		t.Fatal("expected errForecastNoBlocks, got", err)
+	}
+
+	// Add a storage obligation that requires a proof.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.ContractCost = types.NewCurrency64(1000)
+	validPayouts, missedPayouts := so.payouts()
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:              so.id(),
+			NewRevisionNumber:     1,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+		}},
+	}}
+	h.managedLockStorageObligation(so.id())
+	err = h.managedAddStorageObligation(so)
+	h.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The proof window is outside of a short forecast.
+	bh := h.BlockHeight()
+	f, err := h.ContractForecast(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.StartHeight != bh || f.EndHeight != bh+1 || len(f.ProofWindows) != 0 {
+		t.Fatal("expected empty forecast", f)
+	}
+
+	// The proof window is inside of a longer forecast.
+	f, err = h.ContractForecast(so.expiration() - bh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.ProofWindows) != 1 || len(f.Heights) != 1 {
+		t.Fatal("expected one proof window", f)
+	}
+	pw := f.ProofWindows[0]
+	if pw.ObligationID != so.id() || pw.WindowStart != so.expiration() || pw.WindowEnd != so.proofDeadline() {
+		t.Fatal("wrong proof window", pw)
+	}
+	if !pw.ExpectedRevenue.Equals(so.ContractCost) || pw.RequiredFees.IsZero() || pw.Underfunded {
+		t.Fatal("wrong proof window", pw)
+	}
+	if f.WalletBalance.IsZero() || !f.RequiredBalance.Equals(pw.RequiredFees) {
+		t.Fatal("wrong balances", f)
+	}
+}
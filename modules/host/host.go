@@ -159,7 +159,10 @@ type Host struct {
 
 	// Subsystems
 	staticAccountManager        *accountManager
+	staticClients               *hostClients
+	staticMDMTraces             *mdmTraces
 	staticMDM                   *mdm.MDM
+	staticMetrics               *hostMetrics
 	staticRegistry              *registry.Registry
 	staticRegistrySubscriptions *registrySubscriptions
 
@@ -181,6 +184,18 @@ type Host struct {
 	workingStatus        modules.HostWorkingStatus
 	connectabilityStatus modules.HostConnectabilityStatus
 
+	// Pricing engine fields. The policy and the recent price changes are
+	// persisted, the market and the bandwidth snapshot are not.
+	pricingBandwidth pricingBandwidth
+	pricingMarket    modules.HostPricingMarket
+	pricingPolicy    modules.HostPricingPolicy
+	priceChanges     []modules.HostPriceChange
+
+	// Decommission fields. The status is persisted, the mutex serializes the
+	// updates of the status.
+	decommission   modules.HostDecommissionStatus
+	decommissionMu sync.Mutex
+
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
@@ -346,6 +361,10 @@ func (h *Host) managedUpdatePriceTable() {
 		DropSectorsUnitCost: types.NewCurrency64(1),
 		SwapSectorCost:      types.NewCurrency64(1),
 
+		// Update related costs.
+		UpdateSectorBaseCost:   hes.SectorAccessPrice, // roughly equal to reading and writing a sector
+		UpdateSectorLengthCost: types.NewCurrency64(1),
+
 		// Read related costs.
 		ReadBaseCost:   hes.SectorAccessPrice, // roughly equal to 64 kib download
 		ReadLengthCost: types.NewCurrency64(1),
@@ -455,6 +474,9 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 				heap: make([]*hostRPCPriceTable, 0),
 			},
 		},
+		staticClients:               newHostClients(),
+		staticMDMTraces:             newMDMTraces(maxMDMTraces),
+		staticMetrics:               newHostMetrics(),
 		staticRegistrySubscriptions: newRegistrySubscriptions(),
 		persistDir:                  persistDir,
 	}
@@ -511,6 +533,7 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 	if err != nil {
 		return nil, err
 	}
+	h.StorageManager.SetSectorScrubRate(h.settings.SectorScrubRate)
 	h.tg.AfterStop(func() {
 		err := h.saveSync()
 		if err != nil {
@@ -654,8 +677,15 @@ func (h *Host) FinancialMetrics() modules.HostFinancialMetrics {
 	}
 	defer h.tg.Done()
 	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return h.financialMetrics
+	fm := h.financialMetrics
+	h.mu.RUnlock()
+
+	// Add the statistics of the cache folder.
+	cache := h.StorageManager.CacheFolder()
+	fm.CacheHits = cache.Hits
+	fm.CacheMisses = cache.Misses
+	fm.CacheHitRate = cache.HitRate
+	return fm
 }
 
 // PublicKey returns the public key of the host that is used to facilitate
@@ -697,6 +727,10 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 		}
 	}
 
+	if settings.MDMTraceSampleRate < 0 || settings.MDMTraceSampleRate > 1 {
+		return errors.New("internal settings not updated, the MDM trace sample rate must be between 0 and 1")
+	}
+
 	// Check if the net address for the host has changed. If it has, and it's
 	// not equal to the auto address, then the host is going to need to make
 	// another blockchain announcement.
@@ -728,6 +762,7 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 		}
 	}
 
+	h.StorageManager.SetSectorScrubRate(settings.SectorScrubRate)
 	h.settings = settings
 	h.revisionNumber++
 
@@ -779,6 +814,21 @@ func (h *Host) RegistryGet(sid modules.RegistryEntryID) (types.SiaPublicKey, mod
 	return h.staticRegistry.Get(sid)
 }
 
+// StorageObligationSnapshot returns a snapshot of the storage obligation with
+// the given id.
+func (h *Host) StorageObligationSnapshot(id types.FileContractID) (mdm.StorageObligationSnapshot, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+	sos, err := h.managedGetStorageObligationSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	return sos, nil
+}
+
 // RegistryUpdate updates a value in the registry.
 func (h *Host) RegistryUpdate(rv modules.SignedRegistryValue, pubKey types.SiaPublicKey, expiry types.BlockHeight) (modules.SignedRegistryValue, error) {
 	err := h.tg.Add()
@@ -808,6 +858,79 @@ func (h *Host) RegistryUpdate(rv modules.SignedRegistryValue, pubKey types.SiaPu
 	return existingSRV, nil
 }
 
+// RegistryEntries returns up to 'limit' entries of the registry, starting at
+// 'offset', and the total number of entries.
+func (h *Host) RegistryEntries(offset, limit uint64) ([]modules.HostRegistryEntry, uint64, error) {
+	if err := h.tg.Add(); err != nil {
+		return nil, 0, err
+	}
+	defer h.tg.Done()
+	entries, total := h.staticRegistry.Entries(offset, limit)
+	return entries, total, nil
+}
+
+// RegistryEntry returns the registry entry with the given id.
+func (h *Host) RegistryEntry(sid modules.RegistryEntryID) (modules.HostRegistryEntry, bool) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostRegistryEntry{}, false
+	}
+	defer h.tg.Done()
+	return h.staticRegistry.Entry(sid)
+}
+
+// PruneRegistry deletes all expired entries from the registry and returns the
+// number of deleted entries.
+func (h *Host) PruneRegistry() (uint64, error) {
+	if err := h.tg.Add(); err != nil {
+		return 0, err
+	}
+	defer h.tg.Done()
+	return h.staticRegistry.Prune(h.BlockHeight())
+}
+
+// ExportRegistry writes the entries of the registry to a new file at the
+// given path. The file can be imported by another host using ImportRegistry.
+func (h *Host) ExportRegistry(path string) (_ uint64, err error) {
+	if err := h.tg.Add(); err != nil {
+		return 0, err
+	}
+	defer h.tg.Done()
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, modules.DefaultFilePerm)
+	if err != nil {
+		return 0, errors.AddContext(err, "failed to create export file")
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+		if err != nil {
+			err = errors.Compose(err, os.Remove(path))
+		}
+	}()
+	exported, err := h.staticRegistry.Export(f)
+	if err != nil {
+		return 0, errors.AddContext(err, "failed to export registry")
+	}
+	return exported, f.Sync()
+}
+
+// ImportRegistry adds the entries of a file created by ExportRegistry to the
+// registry. Expired entries and entries for which the registry already knows a
+// more recent revision are skipped. It returns the number of imported entries.
+func (h *Host) ImportRegistry(path string) (_ uint64, err error) {
+	if err := h.tg.Add(); err != nil {
+		return 0, err
+	}
+	defer h.tg.Done()
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, errors.AddContext(err, "failed to open export file")
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+	imported, err := h.staticRegistry.Import(f, h.BlockHeight())
+	return imported, errors.AddContext(err, "failed to import registry")
+}
+
 // managedInitRegistry initializes the host's registry on startup. If the
 // registry on disk is larger than the expected size in the settings, it updates
 // the settings to allow the host to boot. Since a registry should not be
@@ -385,7 +385,8 @@ func (p *renterHostPair) Close() error {
 // RPCExecuteProgramResponse together with the output data
 type executeProgramResponse struct {
 	modules.RPCExecuteProgramResponse
-	Output []byte
+	Output      []byte
+	StreamRoots modules.RPCExecuteProgramStreamRoots
 }
 
 // managedExecuteProgram executes an MDM program on the host using an EA payment
@@ -393,6 +394,18 @@ type executeProgramResponse struct {
 // instruction won't result in an error. Instead the returned responses need to
 // be inspected for that depending on the testcase.
 func (p *renterHostPair) managedExecuteProgram(epr modules.RPCExecuteProgramRequest, programData []byte, budget types.Currency, updatePriceTable, finalize bool) (_ []executeProgramResponse, _ mux.BandwidthLimit, err error) {
+	return p.managedExecuteProgramWithRPC(modules.RPCExecuteProgram, epr, programData, budget, updatePriceTable, finalize)
+}
+
+// managedExecuteProgramStream is like managedExecuteProgram but uses the
+// ExecuteProgramStream RPC. The proofs are read after the output data.
+func (p *renterHostPair) managedExecuteProgramStream(epr modules.RPCExecuteProgramRequest, programData []byte, budget types.Currency, updatePriceTable, finalize bool) (_ []executeProgramResponse, _ mux.BandwidthLimit, err error) {
+	return p.managedExecuteProgramWithRPC(modules.RPCExecuteProgramStream, epr, programData, budget, updatePriceTable, finalize)
+}
+
+// managedExecuteProgramWithRPC executes an MDM program using the provided
+// RPC.
+func (p *renterHostPair) managedExecuteProgramWithRPC(rpc types.Specifier, epr modules.RPCExecuteProgramRequest, programData []byte, budget types.Currency, updatePriceTable, finalize bool) (_ []executeProgramResponse, _ mux.BandwidthLimit, err error) {
 	// Only allow a single write program or multiple read programs to run in
 	// parallel. A production worker will have better locking than this but
 	// since we just mock the renter this is used for unit testing the host.
@@ -416,7 +429,7 @@ func (p *renterHostPair) managedExecuteProgram(epr modules.RPCExecuteProgramRequ
 	buffer := bytes.NewBuffer(nil)
 
 	// Write the specifier.
-	err = modules.RPCWrite(buffer, modules.RPCExecuteProgram)
+	err = modules.RPCWrite(buffer, rpc)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -483,6 +496,14 @@ func (p *renterHostPair) managedExecuteProgram(epr modules.RPCExecuteProgramRequ
 			return nil, limit, err
 		}
 
+		// When streaming, the stream roots precede the output data.
+		if rpc == modules.RPCExecuteProgramStream {
+			err = modules.RPCRead(stream, &responses[i].StreamRoots)
+			if err != nil {
+				return nil, limit, err
+			}
+		}
+
 		// Read the output data.
 		outputLen := responses[i].OutputLength
 		responses[i].Output = make([]byte, outputLen, outputLen)
@@ -491,6 +512,16 @@ func (p *renterHostPair) managedExecuteProgram(epr modules.RPCExecuteProgramRequ
 			return nil, limit, err
 		}
 
+		// When streaming, the proof follows the output data.
+		if rpc == modules.RPCExecuteProgramStream {
+			var sp modules.RPCExecuteProgramStreamProof
+			err = modules.RPCRead(stream, &sp)
+			if err != nil {
+				return nil, limit, err
+			}
+			responses[i].Proof = sp.Proof
+		}
+
 		// If the response contains an error we are done.
 		if responses[i].Error != nil {
 			return responses, limit, nil
@@ -521,6 +552,51 @@ func (p *renterHostPair) managedExecuteProgram(epr modules.RPCExecuteProgramRequ
 	return responses, limit, nil
 }
 
+// managedDryRunProgram performs a DryRunProgram RPC on the host and pays for
+// it using the pair's EA.
+func (p *renterHostPair) managedDryRunProgram(drr modules.RPCDryRunProgramRequest, programData []byte, payment types.Currency) (_ modules.RPCDryRunProgramResponse, err error) {
+	stream := p.managedNewStream()
+	defer func() {
+		err = errors.Compose(err, stream.Close())
+	}()
+
+	// Fetch the price table.
+	pt, err := p.managedFetchPriceTable()
+	if err != nil {
+		return modules.RPCDryRunProgramResponse{}, err
+	}
+
+	// Initiate the RPC.
+	err = modules.RPCWriteAll(stream, modules.RPCDryRunProgram, pt.UID)
+	if err != nil {
+		return modules.RPCDryRunProgramResponse{}, err
+	}
+
+	// Provide payment.
+	err = p.managedPayByEphemeralAccount(stream, payment)
+	if err != nil {
+		return modules.RPCDryRunProgramResponse{}, err
+	}
+
+	// Send the request and the program data.
+	err = modules.RPCWrite(stream, drr)
+	if err != nil {
+		return modules.RPCDryRunProgramResponse{}, err
+	}
+	_, err = stream.Write(programData)
+	if err != nil {
+		return modules.RPCDryRunProgramResponse{}, err
+	}
+
+	// Read the response.
+	var resp modules.RPCDryRunProgramResponse
+	err = modules.RPCRead(stream, &resp)
+	if err != nil {
+		return modules.RPCDryRunProgramResponse{}, err
+	}
+	return resp, nil
+}
+
 // managedFetchPriceTable returns the latest price table, if that price table is
 // expired it will fetch a new one from the host.
 func (p *renterHostPair) managedFetchPriceTable() (*modules.RPCPriceTable, error) {
@@ -1076,6 +1152,100 @@ func TestHostInitialization(t *testing.T) {
 	}
 }
 
+// TestHostRegistryAdmin tests paging through, pruning, exporting and importing
+// the host's registry.
+func TestHostRegistryAdmin(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := ht.host
+
+	// Enable the registry.
+	is := h.managedInternalSettings()
+	is.RegistrySize = 64 * modules.RegistryEntrySize
+	err = h.SetInternalSettings(is)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add 2 expired and 3 valid entries.
+	bh := h.BlockHeight()
+	for i := 0; i < 5; i++ {
+		expiry := bh + 100
+		if i < 2 {
+			expiry = bh
+		}
+		sk, pk := crypto.GenerateKeyPair()
+		spk := types.Ed25519PublicKey(pk)
+		var tweak crypto.Hash
+		fastrand.Read(tweak[:])
+		rv := modules.NewRegistryValue(tweak, fastrand.Bytes(modules.RegistryDataSize), 0).Sign(sk)
+		_, err := h.RegistryUpdate(rv, spk, expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, total, err := h.RegistryEntries(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || total != 5 {
+		t.Fatalf("expected 2 of 5 entries, got %v of %v", len(entries), total)
+	}
+	entry, found := h.RegistryEntry(modules.DeriveRegistryEntryID(entries[1].PublicKey, entries[1].Tweak))
+	if !found || !bytes.Equal(entry.Data, entries[1].Data) {
+		t.Fatal("entry not found")
+	}
+
+	// Export the registry.
+	path := filepath.Join(ht.persistDir, "registry.export")
+	exported, err := h.ExportRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported != 5 {
+		t.Fatal("expected 5 exported entries, got", exported)
+	}
+	// Exporting to an existing file should fail.
+	_, err = h.ExportRegistry(path)
+	if err == nil {
+		t.Fatal("export shouldn't overwrite an existing file")
+	}
+
+	// Prune the expired entries.
+	pruned, err := h.PruneRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 || h.staticRegistry.Len() != 3 {
+		t.Fatal("expected 2 pruned entries, got", pruned, h.staticRegistry.Len())
+	}
+
+	// Clear the registry and import the export. The expired entries should
+	// be skipped.
+	_, err = h.staticRegistry.Prune(types.BlockHeight(math.MaxUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := h.ImportRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != 3 || h.staticRegistry.Len() != 3 {
+		t.Fatal("expected 3 imported entries, got", imported, h.staticRegistry.Len())
+	}
+}
+
 // TestHostRegistry tests that changing the internal settings of the host will
 // update the registry as well.
 func TestHostRegistry(t *testing.T) {
@@ -69,6 +69,20 @@ func (tb *testProgramBuilder) AddAppendInstruction(data []byte, merkleProof bool
 	tb.staticValues.AddAppendInstruction(data)
 }
 
+// AddAssertRevisionInstruction adds an assertrevision instruction to the
+// builder, keeping track of running values.
+func (tb *testProgramBuilder) AddAssertRevisionInstruction(revisionNumber uint64) {
+	tb.staticPB.AddAssertRevisionInstruction(revisionNumber)
+	tb.staticValues.AddAssertRevisionInstruction()
+}
+
+// AddCopySectorInstruction adds a copysector instruction to the builder,
+// keeping track of running values.
+func (tb *testProgramBuilder) AddCopySectorInstruction(srcID types.FileContractID, root crypto.Hash, sig crypto.Signature, merkleProof bool) {
+	tb.staticPB.AddCopySectorInstruction(srcID, root, sig, merkleProof)
+	tb.staticValues.AddCopySectorInstruction()
+}
+
 // AddDropSectorsInstruction adds a dropsectors instruction to the builder,
 // keeping track of running values.
 func (tb *testProgramBuilder) AddDropSectorsInstruction(numSectors uint64, merkleProof bool) {
@@ -82,6 +96,20 @@ func (tb *testProgramBuilder) AddHasSectorInstruction(merkleRoot crypto.Hash) {
 	tb.staticValues.AddHasSectorInstruction()
 }
 
+// AddIfHasSectorInstruction adds an ifhassector instruction to the builder,
+// keeping track of running values.
+func (tb *testProgramBuilder) AddIfHasSectorInstruction(merkleRoot crypto.Hash, numSkip uint64) {
+	tb.staticPB.AddIfHasSectorInstruction(merkleRoot, numSkip)
+	tb.staticValues.AddIfHasSectorInstruction()
+}
+
+// AddProveSegmentInstruction adds a provesegment instruction to the builder,
+// keeping track of running values.
+func (tb *testProgramBuilder) AddProveSegmentInstruction(entropy crypto.Hash) {
+	tb.staticPB.AddProveSegmentInstruction(entropy)
+	tb.staticValues.AddProveSegmentInstruction()
+}
+
 // AddReadOffsetInstruction adds a readoffset instruction to the builder,
 // keeping track of running values.
 func (tb *testProgramBuilder) AddReadOffsetInstruction(length, offset uint64, merkleProof bool) {
@@ -110,6 +138,16 @@ func (tb *testProgramBuilder) AddSwapSectorInstruction(sector1Idx, sector2Idx ui
 	tb.staticValues.AddSwapSectorInstruction()
 }
 
+// AddUpdateSectorInstruction adds an UpdateSector instruction to the builder,
+// keeping track of running values.
+func (tb *testProgramBuilder) AddUpdateSectorInstruction(sectorIdx, offset uint64, data []byte, merkleProof bool) {
+	err := tb.staticPB.AddUpdateSectorInstruction(sectorIdx, offset, data, merkleProof)
+	if err != nil {
+		panic(err)
+	}
+	tb.staticValues.AddUpdateSectorInstruction(data)
+}
+
 // AddUpdateRegistryInstruction adds an UpdateRegistry instruction to the
 // builder, keeping track of running values.
 func (tb *testProgramBuilder) AddUpdateRegistryInstruction(spk types.SiaPublicKey, rv modules.SignedRegistryValue) {
@@ -142,6 +180,28 @@ func (tb *testProgramBuilder) AddReadRegistryEIDInstruction(sid modules.Registry
 	return refund
 }
 
+// AddReadRegistryBatchInstruction adds a ReadRegistryBatch instruction to the
+// builder, keeping track of running values.
+func (tb *testProgramBuilder) AddReadRegistryBatchInstruction(sids []modules.RegistryEntryID, numRefunded uint64, needPubKeyAndTweak bool) types.Currency {
+	refund, err := tb.staticPB.AddReadRegistryBatchInstruction(sids, needPubKeyAndTweak)
+	if err != nil {
+		panic(err)
+	}
+	tb.staticValues.AddReadRegistryBatchInstruction(sids, numRefunded)
+	return refund
+}
+
+// AddUpdateRegistryBatchInstruction adds an UpdateRegistryBatch instruction to
+// the builder, keeping track of running values.
+func (tb *testProgramBuilder) AddUpdateRegistryBatchInstruction(updates []modules.RegistryBatchUpdate, numFailed uint64) types.Currency {
+	refund, err := tb.staticPB.AddUpdateRegistryBatchInstruction(updates)
+	if err != nil {
+		panic(err)
+	}
+	tb.staticValues.AddUpdateRegistryBatchInstruction(updates, numFailed)
+	return refund
+}
+
 // Program returns the built program.
 func (tb *testProgramBuilder) Program() (modules.Program, modules.ProgramData) {
 	return tb.staticPB.Program()
@@ -1,6 +1,8 @@
 package mdm
 
 import (
+	"time"
+
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/types"
 )
@@ -46,6 +48,16 @@ type Output struct {
 	// FailureRefund is the amount of money that gets refunded should the
 	// program execution fail.
 	FailureRefund types.Currency
+	// ExecutionTime is the time it took to execute the instruction.
+	ExecutionTime time.Duration
+	// InstructionTime is the time the instruction is priced at.
+	InstructionTime uint64
+	// UsedMemory is the memory used by the program while executing the
+	// instruction.
+	UsedMemory uint64
+	// Skipped indicates that the instruction wasn't executed because a
+	// previous instruction asked for it to be skipped.
+	Skipped bool
 }
 
 // output is the type returned by all instructions when being executed.
@@ -76,6 +88,15 @@ type output struct {
 	// requested by the caller. Using only the proof, the caller will be able to
 	// compute the next Merkle root and size of the contract.
 	Proof []crypto.Hash
+
+	// Stream is set instead of Output and Proof by read instructions of
+	// programs that stream their outputs. The output data and the proof are
+	// then written by the stream.
+	Stream *OutputStream
+
+	// Skip is the number of instructions following this one that the program
+	// skips. It is used by control instructions such as 'IfHasSector'.
+	Skip uint64
 }
 
 // commonInstruction contains all the fields shared by every instruction.
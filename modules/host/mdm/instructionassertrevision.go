@@ -0,0 +1,97 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// instructionAssertRevision is an instruction which aborts the program if the
+// revision number of the contract doesn't match the expected one.
+type instructionAssertRevision struct {
+	commonInstruction
+
+	revisionNumberOffset uint64
+}
+
+// staticDecodeAssertRevisionInstruction creates a new 'AssertRevision'
+// instruction from the provided generic instruction.
+func (p *program) staticDecodeAssertRevisionInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierAssertRevision {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierAssertRevision, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIAssertRevisionLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIAssertRevisionLen, len(instruction.Args))
+	}
+	// Read args.
+	revisionNumberOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	return &instructionAssertRevision{
+		commonInstruction: commonInstruction{
+			staticData:        p.staticData,
+			staticMerkleProof: false,
+			staticState:       p.staticProgramState,
+		},
+		revisionNumberOffset: revisionNumberOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionAssertRevision) Batch() bool {
+	return true
+}
+
+// Collateral is zero for the AssertRevision instruction.
+func (i *instructionAssertRevision) Collateral() types.Currency {
+	return modules.MDMAssertRevisionCollateral()
+}
+
+// Cost returns the cost of executing this instruction.
+func (i *instructionAssertRevision) Cost() (executionCost, _ types.Currency, err error) {
+	executionCost = modules.MDMAssertRevisionCost(i.staticState.priceTable)
+	return
+}
+
+// Memory returns the memory allocated by this instruction beyond the end of its
+// lifetime.
+func (i *instructionAssertRevision) Memory() uint64 {
+	return modules.MDMAssertRevisionMemory()
+}
+
+// Execute executes the 'AssertRevision' instruction.
+func (i *instructionAssertRevision) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the operands.
+	expected, err := i.staticData.Uint64(i.revisionNumberOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Compare the revision numbers.
+	revTxn := i.staticState.staticRevisionTxn
+	if len(revTxn.FileContractRevisions) == 0 {
+		return errOutput(errors.New("program doesn't have access to a contract revision")), types.ZeroCurrency
+	}
+	actual := revTxn.FileContractRevisions[0].NewRevisionNumber
+	if actual != expected {
+		err = errors.AddContext(modules.ErrMDMRevisionMismatch, fmt.Sprintf("expected %v but was %v", expected, actual))
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Return the output.
+	return output{
+		NewSize:       prevOutput.NewSize,       // size stays the same
+		NewMerkleRoot: prevOutput.NewMerkleRoot, // root stays the same
+	}, types.ZeroCurrency
+}
+
+// Time returns the execution time of an 'AssertRevision' instruction.
+func (i *instructionAssertRevision) Time() (uint64, error) {
+	return modules.MDMTimeAssertRevision, nil
+}
@@ -0,0 +1,63 @@
+package mdm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionAssertRevision tests executing a program with a single
+// AssertRevisionInstruction.
+func TestInstructionAssertRevision(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	so := host.newTestStorageObligation(true)
+	so.sectorRoots = randomSectorRoots(1)
+	revisionNumber := so.RecentRevision().NewRevisionNumber
+
+	ics := so.ContractSize()
+	imr := so.MerkleRoot()
+
+	// Asserting the right revision number should succeed.
+	pt := newTestPriceTable()
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddAssertRevisionInstruction(revisionNumber)
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = outputs[0].assert(ics, imr, []crypto.Hash{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Asserting the wrong revision number should abort the program before the
+	// next instruction is executed.
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddAssertRevisionInstruction(revisionNumber + 1)
+	tb.AddRevisionInstruction()
+	program, programData := tb.Program()
+	_, outputChan, err := mdm.ExecuteProgram(context.Background(), pt, program, tb.Cost().Budget(false), types.ZeroCurrency, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs = outputs[:0]
+	for output := range outputChan {
+		outputs = append(outputs, output)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("expected 1 output but got %v", len(outputs))
+	}
+	if !errors.Contains(outputs[0].Error, modules.ErrMDMRevisionMismatch) {
+		t.Fatal("unexpected error", outputs[0].Error)
+	}
+}
@@ -0,0 +1,184 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// ErrCopySectorInvalidSignature is returned if the signature of a
+	// 'CopySector' instruction wasn't created by the renter of the source
+	// contract.
+	ErrCopySectorInvalidSignature = errors.New("copy sector signature is not valid for the source contract")
+
+	// ErrCopySectorSourceExpired is returned if the proof window of the
+	// source contract already started.
+	ErrCopySectorSourceExpired = errors.New("source contract of the copy expired")
+
+	// ErrCopySectorSourceResolved is returned if the source contract already
+	// failed or was resolved otherwise.
+	ErrCopySectorSourceResolved = errors.New("source contract of the copy was already resolved")
+
+	// ErrCopySectorSectorNotFound is returned if the sector that should be
+	// copied isn't stored under the source contract.
+	ErrCopySectorSectorNotFound = errors.New("sector to copy is not part of the source contract")
+)
+
+// instructionCopySector is an instruction that appends a full sector, which
+// the host already stores for another contract of the same renter, to a
+// filecontract.
+type instructionCopySector struct {
+	commonInstruction
+
+	srcIDOffset uint64
+	rootOffset  uint64
+	sigOffset   uint64
+}
+
+// staticDecodeCopySectorInstruction creates a new 'CopySector' instruction
+// from the provided generic instruction.
+func (p *program) staticDecodeCopySectorInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierCopySector {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierCopySector, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCICopySectorLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCICopySectorLen, len(instruction.Args))
+	}
+	// Read args.
+	srcIDOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	rootOffset := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	sigOffset := binary.LittleEndian.Uint64(instruction.Args[16:24])
+	return &instructionCopySector{
+		commonInstruction: commonInstruction{
+			staticData:        p.staticData,
+			staticMerkleProof: instruction.Args[24] == 1,
+			staticState:       p.staticProgramState,
+		},
+		srcIDOffset: srcIDOffset,
+		rootOffset:  rootOffset,
+		sigOffset:   sigOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionCopySector) Batch() bool {
+	return false
+}
+
+// Execute executes the 'CopySector' instruction.
+func (i *instructionCopySector) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the data.
+	srcHash, err := i.staticData.Hash(i.srcIDOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	srcID := types.FileContractID(srcHash)
+	root, err := i.staticData.Hash(i.rootOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	sig, err := i.staticData.Signature(i.sigOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	ps := i.staticState
+	if len(ps.staticRevisionTxn.FileContractRevisions) == 0 {
+		return errOutput(errors.New("no revision available for the destination contract")), types.ZeroCurrency
+	}
+	dstID := ps.staticRevisionTxn.FileContractRevisions[0].ParentID
+
+	// Verify that the renter of the source contract authorized the copy.
+	srcSnapshot, err := ps.host.StorageObligationSnapshot(srcID)
+	if err != nil {
+		return errOutput(errors.AddContext(err, "failed to get snapshot of source contract")), types.ZeroCurrency
+	}
+	srcRevision := srcSnapshot.RecentRevision()
+	uc := srcRevision.UnlockConditions
+	if len(uc.PublicKeys) == 0 || uc.PublicKeys[0].Algorithm != types.SignatureEd25519 {
+		return errOutput(ErrCopySectorInvalidSignature), types.ZeroCurrency
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], uc.PublicKeys[0].Key)
+	if err := crypto.VerifyHash(modules.CopySectorSigHash(srcID, dstID, root), pk, sig); err != nil {
+		return errOutput(errors.Compose(ErrCopySectorInvalidSignature, err)), types.ZeroCurrency
+	}
+
+	// The sectors of a contract that expired or failed might already be
+	// scheduled for removal, so only active contracts can be copied from.
+	if srcSnapshot.Resolved() {
+		return errOutput(ErrCopySectorSourceResolved), types.ZeroCurrency
+	}
+	if ps.host.BlockHeight() >= srcRevision.NewWindowStart {
+		return errOutput(ErrCopySectorSourceExpired), types.ZeroCurrency
+	}
+
+	// Make sure the sector belongs to the source contract.
+	var found bool
+	for _, srcRoot := range srcSnapshot.SectorRoots() {
+		if srcRoot == root {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errOutput(ErrCopySectorSectorNotFound), types.ZeroCurrency
+	}
+
+	// Read the sector and append it to the destination contract.
+	sectorData, err := ps.sectors.readSector(ps.host, root)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	newFileSize := prevOutput.NewSize + modules.SectorSize
+	oldSectors := ps.sectors.merkleRoots
+	newMerkleRoot, err := ps.sectors.appendSector(sectorData)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Construct proof if necessary.
+	var proof []crypto.Hash
+	if i.staticMerkleProof {
+		proof = crypto.MerkleDiffProof(nil, uint64(len(oldSectors)), nil, oldSectors)
+	}
+
+	return output{
+		NewSize:       newFileSize,
+		NewMerkleRoot: newMerkleRoot,
+		Proof:         proof,
+	}, types.ZeroCurrency
+}
+
+// Collateral returns the collateral cost of adding one full sector.
+func (i *instructionCopySector) Collateral() types.Currency {
+	return modules.MDMCopySectorCollateral(i.staticState.priceTable)
+}
+
+// Cost returns the Cost of this `CopySector` instruction.
+func (i *instructionCopySector) Cost() (executionCost, storage types.Currency, err error) {
+	duration := i.staticState.staticRemainingDuration
+	executionCost, storage = modules.MDMCopySectorCost(i.staticState.priceTable, duration)
+	return
+}
+
+// Memory returns the memory allocated by the 'CopySector' instruction beyond
+// the lifetime of the instruction.
+func (i *instructionCopySector) Memory() uint64 {
+	return modules.MDMCopySectorMemory()
+}
+
+// Time returns the execution time of a 'CopySector' instruction.
+func (i *instructionCopySector) Time() (uint64, error) {
+	return modules.MDMTimeCopySector, nil
+}
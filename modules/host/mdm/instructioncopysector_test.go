@@ -0,0 +1,143 @@
+package mdm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionCopySector tests executing a program with a single
+// CopySectorInstruction.
+func TestInstructionCopySector(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Create a source contract with a sector and an empty destination contract
+	// belonging to the same renter.
+	src := host.newTestStorageObligation(true)
+	src.AddRandomSector()
+	root := src.sectorRoots[0]
+	dst := host.newTestStorageObligation(true)
+	dst.pk, dst.sk = src.pk, src.sk
+
+	// Create a program to copy the sector.
+	sig := crypto.SignHash(modules.CopySectorSigHash(src.id, dst.id, root), src.sk)
+	pt := newTestPriceTable()
+	duration := types.BlockHeight(10)
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddCopySectorInstruction(src.id, root, sig, true)
+
+	// Execute it.
+	finalizeFn, budget, outputs, err := mdm.ExecuteProgramWithBuilderManualFinalize(tb, dst, duration, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert the outputs.
+	for _, output := range outputs {
+		err = output.assert(modules.SectorSize, root, []crypto.Hash{}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Finalize the program.
+	if err := finalizeFn(dst); err != nil {
+		t.Fatal(err)
+	}
+	// Budget should be empty now.
+	if !budget.Remaining().IsZero() {
+		t.Fatal("budget wasn't completely depleted")
+	}
+	// Check the storage obligations.
+	if len(dst.sectorRoots) != 1 || dst.sectorRoots[0] != root {
+		t.Fatal("sector wasn't copied", dst.sectorRoots)
+	}
+	if !bytes.Equal(dst.sectorMap[root], host.sectors[root]) {
+		t.Fatal("copied sector has wrong data")
+	}
+	if len(src.sectorRoots) != 1 {
+		t.Fatal("source contract was modified", src.sectorRoots)
+	}
+}
+
+// TestInstructionCopySectorFailures tests that a CopySectorInstruction fails
+// if the renter of the source contract didn't sign it, if the sector isn't
+// part of the source contract or if the source contract isn't active anymore.
+func TestInstructionCopySectorFailures(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	src := host.newTestStorageObligation(true)
+	src.AddRandomSector()
+	root := src.sectorRoots[0]
+	dst := host.newTestStorageObligation(true)
+	pt := newTestPriceTable()
+	duration := types.BlockHeight(10)
+
+	// execute is a helper that runs a copy program against dst and returns
+	// the error of its only output.
+	execute := func(srcID types.FileContractID, root crypto.Hash, sig crypto.Signature) error {
+		tb := newTestProgramBuilder(pt, duration)
+		tb.AddCopySectorInstruction(srcID, root, sig, true)
+		program, programData := tb.Program()
+		values := tb.Cost()
+		_, _, collateral, _ := values.Cost()
+		_, outputChan, err := mdm.ExecuteProgram(context.Background(), pt, program, values.Budget(true), collateral, dst, duration, uint64(len(programData)), bytes.NewReader(programData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var outputs []Output
+		for output := range outputChan {
+			outputs = append(outputs, output)
+		}
+		if len(outputs) != 1 {
+			t.Fatalf("expected 1 output but got %v", len(outputs))
+		}
+		return outputs[0].Error
+	}
+
+	// A signature by the wrong key should be rejected.
+	sig := crypto.SignHash(modules.CopySectorSigHash(src.id, dst.id, root), dst.sk)
+	if err := execute(src.id, root, sig); !errors.Contains(err, ErrCopySectorInvalidSignature) {
+		t.Fatal("expected invalid signature error but got", err)
+	}
+	// A signature for a different destination should be rejected.
+	sig = crypto.SignHash(modules.CopySectorSigHash(src.id, src.id, root), src.sk)
+	if err := execute(src.id, root, sig); !errors.Contains(err, ErrCopySectorInvalidSignature) {
+		t.Fatal("expected invalid signature error but got", err)
+	}
+	// A sector that isn't part of the source contract can't be copied.
+	var unknownRoot crypto.Hash
+	sig = crypto.SignHash(modules.CopySectorSigHash(src.id, dst.id, unknownRoot), src.sk)
+	if err := execute(src.id, unknownRoot, sig); !errors.Contains(err, ErrCopySectorSectorNotFound) {
+		t.Fatal("expected sector not found error but got", err)
+	}
+	// An unknown source contract should fail.
+	var unknownID types.FileContractID
+	sig = crypto.SignHash(modules.CopySectorSigHash(unknownID, dst.id, root), src.sk)
+	if err := execute(unknownID, root, sig); err == nil {
+		t.Fatal("expected error for unknown source contract")
+	}
+	// A source contract that expired should be rejected.
+	sig = crypto.SignHash(modules.CopySectorSigHash(src.id, dst.id, root), src.sk)
+	src.windowStart = host.BlockHeight()
+	if err := execute(src.id, root, sig); !errors.Contains(err, ErrCopySectorSourceExpired) {
+		t.Fatal("expected source expired error but got", err)
+	}
+	// A source contract that failed should be rejected.
+	src.windowStart = types.BlocksPerYear
+	src.resolved = true
+	if err := execute(src.id, root, sig); !errors.Contains(err, ErrCopySectorSourceResolved) {
+		t.Fatal("expected source resolved error but got", err)
+	}
+	// The destination contract should be unchanged.
+	if len(dst.sectorRoots) != 0 {
+		t.Fatal("destination contract was modified", dst.sectorRoots)
+	}
+}
@@ -0,0 +1,102 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// instructionIfHasSector is an instruction which skips the following
+// instructions of the program unless the host stores the sector with the given
+// root.
+type instructionIfHasSector struct {
+	commonInstruction
+
+	merkleRootOffset uint64
+	numSkip          uint64
+}
+
+// staticDecodeIfHasSectorInstruction creates a new 'IfHasSector' instruction
+// from the provided generic instruction.
+func (p *program) staticDecodeIfHasSectorInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierIfHasSector {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierIfHasSector, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIIfHasSectorLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIIfHasSectorLen, len(instruction.Args))
+	}
+	// Read args.
+	rootOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	numSkip := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	return &instructionIfHasSector{
+		commonInstruction: commonInstruction{
+			staticData:        p.staticData,
+			staticMerkleProof: false,
+			staticState:       p.staticProgramState,
+		},
+		merkleRootOffset: rootOffset,
+		numSkip:          numSkip,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionIfHasSector) Batch() bool {
+	return true
+}
+
+// Collateral is zero for the IfHasSector instruction.
+func (i *instructionIfHasSector) Collateral() types.Currency {
+	return modules.MDMIfHasSectorCollateral()
+}
+
+// Cost returns the cost of executing this instruction.
+func (i *instructionIfHasSector) Cost() (executionCost, _ types.Currency, err error) {
+	executionCost = modules.MDMIfHasSectorCost(i.staticState.priceTable)
+	return
+}
+
+// Memory returns the memory allocated by this instruction beyond the end of its
+// lifetime.
+func (i *instructionIfHasSector) Memory() uint64 {
+	return modules.MDMIfHasSectorMemory()
+}
+
+// Execute executes the 'IfHasSector' instruction. Like 'HasSector' the output
+// is a single byte which is 1 if the sector exists and 0 otherwise.
+func (i *instructionIfHasSector) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the operands.
+	sectorRoot, err := i.staticData.Hash(i.merkleRootOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Sectors added earlier within the same program count as well.
+	_, gained := i.staticState.sectors.sectorsGained[sectorRoot]
+	hasSector := gained || i.staticState.host.HasSector(sectorRoot)
+
+	// Return the output. If the host doesn't have the sector, the following
+	// instructions are skipped.
+	out := output{
+		NewSize:       prevOutput.NewSize,       // size stays the same
+		NewMerkleRoot: prevOutput.NewMerkleRoot, // root stays the same
+		Output:        []byte{0},
+	}
+	if hasSector {
+		out.Output[0] = 1
+	} else {
+		out.Skip = i.numSkip
+	}
+	return out, types.ZeroCurrency
+}
+
+// Time returns the execution time of an 'IfHasSector' instruction.
+func (i *instructionIfHasSector) Time() (uint64, error) {
+	return modules.MDMTimeIfHasSector, nil
+}
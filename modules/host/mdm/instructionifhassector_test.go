@@ -0,0 +1,119 @@
+package mdm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionIfHasSector tests executing a program with an
+// IfHasSectorInstruction followed by the instruction it might skip.
+func TestInstructionIfHasSector(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	so := host.newTestStorageObligation(true)
+	so.sectorRoots = randomSectorRoots(1)
+
+	// Add sector to the host.
+	sectorRoot := so.sectorRoots[0]
+	_, err := host.ReadSector(sectorRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ics := so.ContractSize()
+	imr := so.MerkleRoot()
+
+	// If the host has the sector, the next instruction is executed.
+	pt := newTestPriceTable()
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddIfHasSectorInstruction(sectorRoot, 1)
+	tb.AddHasSectorInstruction(sectorRoot)
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = outputs[0].assert(ics, imr, []crypto.Hash{}, []byte{1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = outputs[1].assert(ics, imr, []crypto.Hash{}, []byte{1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Skipped || outputs[1].Skipped {
+		t.Fatal("no instruction should be skipped")
+	}
+
+	// If the host doesn't have the sector, the next instruction is skipped
+	// and refunded.
+	var missingRoot crypto.Hash
+	fastrand.Read(missingRoot[:])
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddIfHasSectorInstruction(missingRoot, 1)
+	tb.AddHasSectorInstruction(sectorRoot)
+	program, programData := tb.Program()
+	values := tb.Cost()
+	budget := values.Budget(false)
+	_, outputChan, err := mdm.ExecuteProgram(context.Background(), pt, program, budget, types.ZeroCurrency, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs = outputs[:0]
+	for output := range outputChan {
+		outputs = append(outputs, output)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 outputs but got %v", len(outputs))
+	}
+	err = outputs[0].assert(ics, imr, []crypto.Hash{}, []byte{0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = outputs[1].assert(ics, imr, []crypto.Hash{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Skipped || !outputs[1].Skipped {
+		t.Fatal("only the second instruction should be skipped")
+	}
+	// The skipped instruction shouldn't cost anything.
+	if !outputs[1].ExecutionCost.Equals(outputs[0].ExecutionCost) {
+		t.Fatalf("skipped instruction wasn't refunded: %v != %v", outputs[1].ExecutionCost, outputs[0].ExecutionCost)
+	}
+	if !outputs[1].FailureRefund.Equals(outputs[0].FailureRefund) {
+		t.Fatalf("failure refund shouldn't change: %v != %v", outputs[1].FailureRefund, outputs[0].FailureRefund)
+	}
+	// The refund should be deposited back into the budget.
+	cost, _, _, _ := values.Cost()
+	if !budget.Remaining().Equals(cost.Sub(outputs[1].ExecutionCost)) {
+		t.Fatalf("remaining budget should be %v but was %v", cost.Sub(outputs[1].ExecutionCost), budget.Remaining())
+	}
+	if budget.Remaining().IsZero() {
+		t.Fatal("expected a refund")
+	}
+
+	// Skipping more instructions than the program contains should fail.
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddIfHasSectorInstruction(missingRoot, 2)
+	tb.AddHasSectorInstruction(sectorRoot)
+	program, programData = tb.Program()
+	_, outputChan, err = mdm.ExecuteProgram(context.Background(), pt, program, tb.Cost().Budget(false), types.ZeroCurrency, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs = outputs[:0]
+	for output := range outputChan {
+		outputs = append(outputs, output)
+	}
+	if len(outputs) != 1 || outputs[0].Error == nil {
+		t.Fatal("expected the program to fail", outputs)
+	}
+}
@@ -0,0 +1,123 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// errProveSegmentEmptyContract is returned by a 'ProveSegment' instruction if
+// the contract doesn't contain any sectors.
+var errProveSegmentEmptyContract = errors.New("can't prove a segment of an empty contract")
+
+// instructionProveSegment is an instruction which proves that the host is
+// storing a randomly selected segment of the contract.
+type instructionProveSegment struct {
+	commonInstruction
+
+	entropyOffset uint64
+}
+
+// staticDecodeProveSegmentInstruction creates a new 'ProveSegment' instruction
+// from the provided generic instruction.
+func (p *program) staticDecodeProveSegmentInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierProveSegment {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierProveSegment, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIProveSegmentLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIProveSegmentLen, len(instruction.Args))
+	}
+	// Read args.
+	entropyOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	return &instructionProveSegment{
+		commonInstruction: commonInstruction{
+			staticData:  p.staticData,
+			staticState: p.staticProgramState,
+		},
+		entropyOffset: entropyOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionProveSegment) Batch() bool {
+	return false
+}
+
+// Execute executes the 'ProveSegment' instruction.
+func (i *instructionProveSegment) Execute(previousOutput output) (output, types.Currency) {
+	// Fetch the operands.
+	entropy, err := i.staticData.Hash(i.entropyOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	roots := i.staticState.sectors.merkleRoots
+	if len(roots) == 0 {
+		return errOutput(errProveSegmentEmptyContract), types.ZeroCurrency
+	}
+
+	// Select the segment.
+	segmentsPerSector := modules.SectorSize / crypto.SegmentSize
+	numSegments := uint64(len(roots)) * segmentsPerSector
+	segmentIndex := modules.ProveSegmentIndex(entropy, numSegments)
+	sectorIndex := segmentIndex / segmentsPerSector
+
+	// Read the sector containing the segment.
+	sectorData, err := i.staticState.sectors.readSector(i.staticState.host, roots[sectorIndex])
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Build the proof for the segment within the sector and extend it to the
+	// contract root the same way the host does for storage proofs.
+	base, cachedHashSet := crypto.MerkleProof(sectorData, segmentIndex%segmentsPerSector)
+	log2SectorSize := uint64(0)
+	for 1<<log2SectorSize < segmentsPerSector {
+		log2SectorSize++
+	}
+	ct := crypto.NewCachedTree(log2SectorSize)
+	ct.SetIndex(segmentIndex)
+	for _, root := range roots {
+		if err := ct.PushSubTree(0, root); err != nil {
+			return errOutput(err), types.ZeroCurrency
+		}
+	}
+
+	// Return the output.
+	return output{
+		NewSize:       previousOutput.NewSize,       // size stays the same
+		NewMerkleRoot: previousOutput.NewMerkleRoot, // root stays the same
+		Output:        base,
+		Proof:         ct.Prove(base, cachedHashSet),
+	}, types.ZeroCurrency
+}
+
+// Collateral is zero for the ProveSegment instruction.
+func (i *instructionProveSegment) Collateral() types.Currency {
+	return modules.MDMProveSegmentCollateral()
+}
+
+// Cost returns the cost of a ProveSegment instruction.
+func (i *instructionProveSegment) Cost() (executionCost, _ types.Currency, err error) {
+	executionCost = modules.MDMProveSegmentCost(i.staticState.priceTable)
+	return
+}
+
+// Memory returns the memory allocated by the 'ProveSegment' instruction beyond
+// the lifetime of the instruction.
+func (i *instructionProveSegment) Memory() uint64 {
+	return modules.MDMProveSegmentMemory()
+}
+
+// Time returns the execution time of a 'ProveSegment' instruction.
+func (i *instructionProveSegment) Time() (uint64, error) {
+	return modules.MDMTimeProveSegment, nil
+}
@@ -0,0 +1,79 @@
+package mdm
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionProveSegment tests executing a program with a single
+// ProveSegmentInstruction.
+func TestInstructionProveSegment(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Prepare a priceTable.
+	pt := newTestPriceTable()
+	// Prepare storage obligation.
+	so := host.newTestStorageObligation(true)
+	so.AddRandomSectors(initialContractSectors)
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+
+	// Use a builder to build the program.
+	var entropy crypto.Hash
+	fastrand.Read(entropy[:])
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddProveSegmentInstruction(entropy)
+
+	ics := so.ContractSize()
+	imr := so.MerkleRoot()
+
+	// Execute it.
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := outputs[0]
+	if output.Error != nil {
+		t.Fatal(output.Error)
+	}
+	if output.NewSize != ics || output.NewMerkleRoot != imr {
+		t.Fatal("contract shouldn't change")
+	}
+
+	// The output should be the selected segment.
+	numSegments := ics / crypto.SegmentSize
+	segmentIndex := modules.ProveSegmentIndex(entropy, numSegments)
+	sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
+	sectorData, err := host.ReadSector(so.sectorRoots[sectorIndex])
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := (segmentIndex % (modules.SectorSize / crypto.SegmentSize)) * crypto.SegmentSize
+	if !bytes.Equal(output.Output, sectorData[offset:offset+crypto.SegmentSize]) {
+		t.Fatal("wrong segment returned")
+	}
+
+	// The proof should be valid for the contract root.
+	if !crypto.VerifySegment(output.Output, output.Proof, numSegments, segmentIndex, imr) {
+		t.Fatal("invalid proof")
+	}
+
+	// Proving a segment of an empty contract should fail.
+	so = host.newTestStorageObligation(true)
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddProveSegmentInstruction(entropy)
+	outputs, err = mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Contains(outputs[0].Error, errProveSegmentEmptyContract) {
+		t.Fatal("unexpected error", outputs[0].Error)
+	}
+}
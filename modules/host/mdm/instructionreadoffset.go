@@ -62,6 +62,9 @@ func (i *instructionReadOffset) Execute(previousOutput output) (output, types.Cu
 	if err != nil {
 		return errOutput(err), types.ZeroCurrency
 	}
+	if i.staticState.staticStreamOutputs {
+		return streamReadOffset(previousOutput, i.staticState, length, offset, i.staticMerkleProof), types.ZeroCurrency
+	}
 	// Translate the offset to a root.
 	relOffset, secIdx, err := i.staticState.sectors.translateOffset(offset)
 	if err != nil {
@@ -88,8 +91,12 @@ func (i *instructionReadOffset) Execute(previousOutput output) (output, types.Cu
 		output.Proof = crypto.MerkleMixedRangeProof(sectorHashes, nil, int(modules.SectorSize), proofStart, proofEnd)
 	} else {
 		// If a partial sector was downloaded, we pass in all sector roots
-		// except for the partial one and pass in the data as well.
-		sectorHashes := append(i.staticState.sectors.merkleRoots[:secIdx], i.staticState.sectors.merkleRoots[secIdx+1:]...)
+		// except for the partial one and pass in the data as well. The roots
+		// are copied to avoid modifying the program's roots.
+		roots := i.staticState.sectors.merkleRoots
+		sectorHashes := make([]crypto.Hash, 0, len(roots)-1)
+		sectorHashes = append(sectorHashes, roots[:secIdx]...)
+		sectorHashes = append(sectorHashes, roots[secIdx+1:]...)
 		output.Proof = crypto.MerkleMixedRangeProof(sectorHashes, fullSec, int(modules.SectorSize), proofStart, proofEnd)
 	}
 	return output, types.ZeroCurrency
@@ -0,0 +1,114 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// instructionReadRegistryBatch defines an instruction to read multiple entries
+// from the registry at once.
+type instructionReadRegistryBatch struct {
+	commonInstruction
+
+	sidsOffset         uint64
+	numEntries         uint64
+	needPubKeyAndTweak bool
+}
+
+// staticDecodeReadRegistryBatchInstruction creates a new 'ReadRegistryBatch'
+// instruction from the provided generic instruction.
+func (p *program) staticDecodeReadRegistryBatchInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierReadRegistryBatch {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierReadRegistryBatch, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIReadRegistryBatchLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIReadRegistryBatchLen, len(instruction.Args))
+	}
+	// Read args.
+	sidsOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	numEntries := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	needPubKeyAndTweak := instruction.Args[16] == 1
+	return &instructionReadRegistryBatch{
+		commonInstruction: commonInstruction{
+			staticData:  p.staticData,
+			staticState: p.staticProgramState,
+		},
+		sidsOffset:         sidsOffset,
+		numEntries:         numEntries,
+		needPubKeyAndTweak: needPubKeyAndTweak,
+	}, nil
+}
+
+// Execute executes the 'ReadRegistryBatch' instruction. The output contains
+// one encoded value per requested entry in the same order as the entries were
+// requested. The encoding of a value matches the output of the 'ReadRegistry'
+// instruction and an empty value means the entry wasn't found. Every entry
+// that isn't found is refunded.
+func (i *instructionReadRegistryBatch) Execute(prevOutput output) (output, types.Currency) {
+	if i.numEntries == 0 {
+		return errOutput(fmt.Errorf("can't read a batch of 0 entries")), types.ZeroCurrency
+	}
+	if i.numEntries > i.staticData.Len()/crypto.HashSize {
+		return errOutput(fmt.Errorf("program data is too short for %v entries", i.numEntries)), types.ZeroCurrency
+	}
+	// Fetch the args.
+	sidsBytes, err := i.staticData.Bytes(i.sidsOffset, i.numEntries*crypto.HashSize)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Look up the entries one by one.
+	values := make([][]byte, 0, i.numEntries)
+	var refund types.Currency
+	for len(sidsBytes) > 0 {
+		var sid modules.RegistryEntryID
+		copy(sid[:], sidsBytes[:crypto.HashSize])
+		sidsBytes = sidsBytes[crypto.HashSize:]
+
+		out, entryRefund := executeReadRegistry(prevOutput, i.staticState, sid, i.needPubKeyAndTweak)
+		values = append(values, out.Output)
+		refund = refund.Add(entryRefund)
+	}
+	return output{
+		NewSize:       prevOutput.NewSize,
+		NewMerkleRoot: prevOutput.NewMerkleRoot,
+		Output:        encoding.Marshal(values),
+	}, refund
+}
+
+// Registry reads can be batched, because they are both tiny, and low latency.
+func (i *instructionReadRegistryBatch) Batch() bool {
+	return true
+}
+
+// Collateral returns the collateral the host has to put up for this
+// instruction.
+func (i *instructionReadRegistryBatch) Collateral() types.Currency {
+	return modules.MDMReadRegistryBatchCollateral()
+}
+
+// Cost returns the Cost of this `ReadRegistryBatch` instruction.
+func (i *instructionReadRegistryBatch) Cost() (executionCost, refund types.Currency, err error) {
+	executionCost, refund = modules.MDMReadRegistryBatchCost(i.staticState.priceTable, i.numEntries)
+	return
+}
+
+// Memory returns the memory allocated by the 'ReadRegistryBatch' instruction
+// beyond the lifetime of the instruction.
+func (i *instructionReadRegistryBatch) Memory() uint64 {
+	return modules.MDMReadRegistryBatchMemory()
+}
+
+// Time returns the execution time of a 'ReadRegistryBatch' instruction.
+func (i *instructionReadRegistryBatch) Time() (uint64, error) {
+	return modules.MDMReadRegistryBatchTime(i.numEntries), nil
+}
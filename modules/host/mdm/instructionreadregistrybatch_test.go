@@ -0,0 +1,88 @@
+package mdm
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionReadRegistryBatch tests the ReadRegistryBatch instruction.
+func TestInstructionReadRegistryBatch(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Add 2 registry values.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.Ed25519PublicKey(pk)
+	var rvs []modules.SignedRegistryValue
+	var sids []modules.RegistryEntryID
+	for i := 0; i < 2; i++ {
+		var tweak crypto.Hash
+		fastrand.Read(tweak[:])
+		rv := modules.NewRegistryValue(tweak, fastrand.Bytes(modules.RegistryDataSize), fastrand.Uint64n(1000)).Sign(sk)
+		_, err := host.RegistryUpdate(rv, spk, types.BlockHeight(fastrand.Uint64n(1000)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rvs = append(rvs, rv)
+		sids = append(sids, modules.DeriveRegistryEntryID(spk, tweak))
+	}
+	// Add an entry that doesn't exist in between.
+	var missing modules.RegistryEntryID
+	fastrand.Read(missing[:])
+	sids = []modules.RegistryEntryID{sids[0], missing, sids[1]}
+
+	so := host.newTestStorageObligation(true)
+	pt := newTestPriceTable()
+	tb := newTestProgramBuilder(pt, 0)
+	refund := tb.AddReadRegistryBatchInstruction(sids, 1, false)
+
+	// The refund should be the refund of a single entry.
+	_, expectedRefund := modules.MDMReadRegistryCost(pt)
+	if !refund.Equals(expectedRefund) {
+		t.Fatalf("wrong refund %v != %v", refund, expectedRefund)
+	}
+
+	// Execute it.
+	outputs, remainingBudget, err := mdm.ExecuteProgramWithBuilderCustomBudget(tb, so, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Error != nil {
+		t.Fatal(outputs[0].Error)
+	}
+	// Only the missing entry should have been refunded.
+	if !remainingBudget.Remaining().Equals(refund) {
+		t.Fatal("remaining budget should equal refund", remainingBudget.Remaining().HumanString(), refund.HumanString())
+	}
+
+	// Decode the output.
+	var values [][]byte
+	if err := encoding.Unmarshal(outputs[0].Output, &values); err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != len(sids) {
+		t.Fatalf("expected %v values but got %v", len(sids), len(values))
+	}
+	if len(values[1]) != 0 {
+		t.Fatal("missing entry should have an empty value")
+	}
+	for i, value := range [][]byte{values[0], values[2]} {
+		_, rv, found := host.RegistryGet(sids[i*2])
+		if !found {
+			t.Fatal("entry not found")
+		}
+		out, _ := executeReadRegistry(output{}, &programState{host: host, priceTable: pt}, sids[i*2], false)
+		if string(out.Output) != string(value) {
+			t.Fatal("value doesn't match single read")
+		}
+		if rv.Revision != rvs[i].Revision {
+			t.Fatal("wrong revision")
+		}
+	}
+}
@@ -60,16 +60,7 @@ func (i instructionReadSector) Batch() bool {
 // executeReadSector executes the 'ReadSector' instruction.
 func executeReadSector(previousOutput output, ps *programState, length, offset uint64, sectorRoot crypto.Hash, merkleProof bool) (output, []byte) {
 	// Validate the request.
-	var err error
-	switch {
-	case offset+length > modules.SectorSize:
-		err = fmt.Errorf("request is out of bounds %v + %v = %v > %v", offset, length, offset+length, modules.SectorSize)
-	case length == 0:
-		err = errors.New("length cannot be zero")
-	case merkleProof && (offset%crypto.SegmentSize != 0 || length%crypto.SegmentSize != 0):
-		err = fmt.Errorf("offset (%v) and length (%v) must be multiples of SegmentSize (%v) when requesting a Merkle proof", offset, length, crypto.SegmentSize)
-	}
-	if err != nil {
+	if err := checkReadSectorArgs(length, offset, merkleProof); err != nil {
 		return errOutput(err), nil
 	}
 
@@ -96,6 +87,19 @@ func executeReadSector(previousOutput output, ps *programState, length, offset u
 	}, sectorData
 }
 
+// checkReadSectorArgs validates the range of a read within a sector.
+func checkReadSectorArgs(length, offset uint64, merkleProof bool) error {
+	switch {
+	case offset+length > modules.SectorSize:
+		return fmt.Errorf("request is out of bounds %v + %v = %v > %v", offset, length, offset+length, modules.SectorSize)
+	case length == 0:
+		return errors.New("length cannot be zero")
+	case merkleProof && (offset%crypto.SegmentSize != 0 || length%crypto.SegmentSize != 0):
+		return fmt.Errorf("offset (%v) and length (%v) must be multiples of SegmentSize (%v) when requesting a Merkle proof", offset, length, crypto.SegmentSize)
+	}
+	return nil
+}
+
 // Execute executes the 'ReadSector' instruction.
 func (i *instructionReadSector) Execute(previousOutput output) (output, types.Currency) {
 	// Fetch the operands.
@@ -111,6 +115,9 @@ func (i *instructionReadSector) Execute(previousOutput output) (output, types.Cu
 	if err != nil {
 		return errOutput(err), types.ZeroCurrency
 	}
+	if i.staticState.staticStreamOutputs {
+		return streamReadSector(previousOutput, i.staticState, length, offset, sectorRoot, i.staticMerkleProof), types.ZeroCurrency
+	}
 	output, _ := executeReadSector(previousOutput, i.staticState, length, offset, sectorRoot, i.staticMerkleProof)
 	return output, types.ZeroCurrency
 }
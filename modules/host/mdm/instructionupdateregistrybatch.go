@@ -0,0 +1,125 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/registry"
+	"go.sia.tech/siad/types"
+)
+
+// instructionUpdateRegistryBatch defines multiple updates to values in the
+// host's registry.
+type instructionUpdateRegistryBatch struct {
+	commonInstruction
+
+	entriesOffset uint64
+	entriesLength uint64
+	numEntries    uint64
+}
+
+// staticDecodeUpdateRegistryBatchInstruction creates a new
+// 'UpdateRegistryBatch' instruction from the provided generic instruction.
+func (p *program) staticDecodeUpdateRegistryBatchInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierUpdateRegistryBatch {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierUpdateRegistryBatch, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIUpdateRegistryBatchLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIUpdateRegistryBatchLen, len(instruction.Args))
+	}
+	// Read args.
+	entriesOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	entriesLength := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	numEntries := binary.LittleEndian.Uint64(instruction.Args[16:24])
+	return &instructionUpdateRegistryBatch{
+		commonInstruction: commonInstruction{
+			staticData:  p.staticData,
+			staticState: p.staticProgramState,
+		},
+		entriesOffset: entriesOffset,
+		entriesLength: entriesLength,
+		numEntries:    numEntries,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionUpdateRegistryBatch) Batch() bool {
+	return true
+}
+
+// Execute executes the 'UpdateRegistryBatch' instruction. A failed update
+// doesn't fail the instruction. Instead the output contains one
+// RegistryBatchUpdateResult per update in the same order as the updates and
+// every failed update is refunded.
+func (i *instructionUpdateRegistryBatch) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the args.
+	entries, err := i.staticData.Bytes(i.entriesOffset, i.entriesLength)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	var updates []modules.RegistryBatchUpdate
+	err = encoding.Unmarshal(entries, &updates)
+	if err != nil {
+		return errOutput(errors.AddContext(err, "failed to decode registry updates")), types.ZeroCurrency
+	}
+	if uint64(len(updates)) != i.numEntries || len(updates) == 0 {
+		return errOutput(fmt.Errorf("expected %v updates but got %v", i.numEntries, len(updates))), types.ZeroCurrency
+	}
+
+	// Add 1 year to the expiry.
+	newExpiry := i.staticState.host.BlockHeight() + types.BlocksPerYear
+
+	// Apply the updates one by one.
+	_, entryRefund := modules.MDMUpdateRegistryCost(i.staticState.priceTable)
+	results := make([]modules.RegistryBatchUpdateResult, len(updates))
+	var refund types.Currency
+	for idx, update := range updates {
+		existingRV, err := i.staticState.host.RegistryUpdate(update.Value, update.PubKey, newExpiry)
+		if err == nil {
+			continue
+		}
+		results[idx].Error = err.Error()
+		// If we weren't able to update the registry due to a ErrLowerRevNum
+		// or ErrSameRevNum, we need to return the existing value as proof.
+		if errors.Contains(err, registry.ErrLowerRevNum) || errors.Contains(err, registry.ErrSameRevNum) {
+			results[idx].Existing = existingRV
+		}
+		refund = refund.Add(entryRefund)
+	}
+	return output{
+		NewSize:       prevOutput.NewSize,
+		NewMerkleRoot: prevOutput.NewMerkleRoot,
+		Output:        encoding.Marshal(results),
+	}, refund
+}
+
+// Collateral returns the collateral the host has to put up for this
+// instruction.
+func (i *instructionUpdateRegistryBatch) Collateral() types.Currency {
+	return modules.MDMUpdateRegistryBatchCollateral()
+}
+
+// Cost returns the Cost of this `UpdateRegistryBatch` instruction.
+func (i *instructionUpdateRegistryBatch) Cost() (executionCost, refund types.Currency, err error) {
+	executionCost, refund = modules.MDMUpdateRegistryBatchCost(i.staticState.priceTable, i.numEntries)
+	return
+}
+
+// Memory returns the memory allocated by the 'UpdateRegistryBatch' instruction
+// beyond the lifetime of the instruction.
+func (i *instructionUpdateRegistryBatch) Memory() uint64 {
+	return modules.MDMUpdateRegistryBatchMemory()
+}
+
+// Time returns the execution time of an 'UpdateRegistryBatch' instruction.
+func (i *instructionUpdateRegistryBatch) Time() (uint64, error) {
+	return modules.MDMUpdateRegistryBatchTime(i.numEntries), nil
+}
@@ -0,0 +1,87 @@
+package mdm
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/registry"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionUpdateRegistryBatch tests the UpdateRegistryBatch
+// instruction.
+func TestInstructionUpdateRegistryBatch(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Create an existing entry with revision 1.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.Ed25519PublicKey(pk)
+	existing := modules.NewRegistryValue(crypto.Hash{1}, fastrand.Bytes(modules.RegistryDataSize), 1).Sign(sk)
+	_, err := host.RegistryUpdate(existing, spk, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prepare 2 new entries and an outdated update for the existing one.
+	updates := []modules.RegistryBatchUpdate{
+		{PubKey: spk, Value: modules.NewRegistryValue(crypto.Hash{2}, fastrand.Bytes(modules.RegistryDataSize), 0).Sign(sk)},
+		{PubKey: spk, Value: modules.NewRegistryValue(existing.Tweak, fastrand.Bytes(modules.RegistryDataSize), 0).Sign(sk)},
+		{PubKey: spk, Value: modules.NewRegistryValue(crypto.Hash{3}, fastrand.Bytes(modules.RegistryDataSize), 0).Sign(sk)},
+	}
+
+	so := host.newTestStorageObligation(true)
+	pt := newTestPriceTable()
+	tb := newTestProgramBuilder(pt, 0)
+	refund := tb.AddUpdateRegistryBatchInstruction(updates, 1)
+
+	// Execute it.
+	outputs, remainingBudget, err := mdm.ExecuteProgramWithBuilderCustomBudget(tb, so, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Error != nil {
+		t.Fatal(outputs[0].Error)
+	}
+	// Only the failed update should have been refunded.
+	if !remainingBudget.Remaining().Equals(refund) {
+		t.Fatal("remaining budget should equal refund", remainingBudget.Remaining().HumanString(), refund.HumanString())
+	}
+
+	// Decode the results.
+	var results []modules.RegistryBatchUpdateResult
+	if err := encoding.Unmarshal(outputs[0].Output, &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(updates) {
+		t.Fatalf("expected %v results but got %v", len(updates), len(results))
+	}
+	if results[0].Error != "" || results[2].Error != "" {
+		t.Fatal("successful updates shouldn't have an error", results[0].Error, results[2].Error)
+	}
+	if results[1].Error != registry.ErrLowerRevNum.Error() {
+		t.Fatal("wrong error", results[1].Error)
+	}
+	if !reflect.DeepEqual(results[1].Existing, existing) {
+		t.Fatal("wrong proof")
+	}
+	if err := results[1].Existing.Verify(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	// The registry should contain the new entries and the old existing one.
+	for _, rv := range []modules.SignedRegistryValue{updates[0].Value, existing, updates[2].Value} {
+		_, rv2, ok := host.RegistryGet(modules.DeriveRegistryEntryID(spk, rv.Tweak))
+		if !ok {
+			t.Fatal("registry doesn't contain entry")
+		}
+		if !reflect.DeepEqual(rv, rv2) {
+			t.Fatal("registry contains wrong entry")
+		}
+	}
+}
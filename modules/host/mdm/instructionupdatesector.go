@@ -0,0 +1,147 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// instructionUpdateSector is an instruction that overwrites a range of bytes
+// within a sector of a file contract.
+type instructionUpdateSector struct {
+	commonInstruction
+
+	sectorIdxOffset uint64
+	offsetOffset    uint64
+	lengthOffset    uint64
+	dataOffset      uint64
+}
+
+// staticDecodeUpdateSectorInstruction creates a new 'UpdateSector' instruction
+// from the provided generic instruction.
+func (p *program) staticDecodeUpdateSectorInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierUpdateSector {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierUpdateSector, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIUpdateSectorLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIUpdateSectorLen, len(instruction.Args))
+	}
+	// Read args.
+	sectorIdxOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	offsetOffset := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	lengthOffset := binary.LittleEndian.Uint64(instruction.Args[16:24])
+	dataOffset := binary.LittleEndian.Uint64(instruction.Args[24:32])
+	return &instructionUpdateSector{
+		commonInstruction: commonInstruction{
+			staticData:        p.staticData,
+			staticMerkleProof: instruction.Args[32] == 1,
+			staticState:       p.staticProgramState,
+		},
+		sectorIdxOffset: sectorIdxOffset,
+		offsetOffset:    offsetOffset,
+		lengthOffset:    lengthOffset,
+		dataOffset:      dataOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionUpdateSector) Batch() bool {
+	return false
+}
+
+// Execute executes the 'UpdateSector' instruction.
+func (i *instructionUpdateSector) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the data.
+	sectorIdx, err := i.staticData.Uint64(i.sectorIdxOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	offset, err := i.staticData.Uint64(i.offsetOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	length, err := i.staticData.Uint64(i.lengthOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	if length == 0 {
+		return errOutput(errors.New("length cannot be zero")), types.ZeroCurrency
+	}
+	if offset > modules.SectorSize || length > modules.SectorSize-offset {
+		return errOutput(fmt.Errorf("request is out of bounds %v + %v > %v", offset, length, modules.SectorSize)), types.ZeroCurrency
+	}
+	data, err := i.staticData.Bytes(i.dataOffset, length)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	ps := i.staticState
+	oldRoot, newMerkleRoot, err := ps.sectors.updateSector(ps.host, sectorIdx, offset, data)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// If no proof was requested we are done.
+	if !i.staticMerkleProof {
+		return output{
+			NewSize:       prevOutput.NewSize,
+			NewMerkleRoot: newMerkleRoot,
+		}, types.ZeroCurrency
+	}
+
+	// Create the proof for the updated sector and return the old leaf hash as
+	// the data. The renter needs it to verify the proof against the old
+	// contract merkle root. Afterwards the renter can compute the new leaf hash
+	// from the updated sector and verify the proof against the new root.
+	newRoots := ps.sectors.merkleRoots
+	ranges := []crypto.ProofRange{
+		{
+			Start: sectorIdx,
+			End:   sectorIdx + 1,
+		},
+	}
+	proof := crypto.MerkleDiffProof(ranges, uint64(len(newRoots)), nil, newRoots)
+	return output{
+		NewSize:       prevOutput.NewSize,
+		NewMerkleRoot: newMerkleRoot,
+		Output:        encoding.Marshal([]crypto.Hash{oldRoot}),
+		Proof:         proof,
+	}, types.ZeroCurrency
+}
+
+// Collateral returns the collateral cost of updating a sector.
+func (i *instructionUpdateSector) Collateral() types.Currency {
+	return modules.MDMUpdateSectorCollateral()
+}
+
+// Cost returns the Cost of this `UpdateSector` instruction.
+func (i *instructionUpdateSector) Cost() (executionCost, storage types.Currency, err error) {
+	var length uint64
+	length, err = i.staticData.Uint64(i.lengthOffset)
+	if err != nil {
+		return
+	}
+	executionCost = modules.MDMUpdateSectorCost(i.staticState.priceTable, length)
+	return
+}
+
+// Memory returns the memory allocated by the 'UpdateSector' instruction beyond
+// the lifetime of the instruction.
+func (i *instructionUpdateSector) Memory() uint64 {
+	return modules.MDMUpdateSectorMemory()
+}
+
+// Time returns the execution time of an 'UpdateSector' instruction.
+func (i *instructionUpdateSector) Time() (uint64, error) {
+	return modules.MDMTimeUpdateSector, nil
+}
@@ -0,0 +1,106 @@
+package mdm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestInstructionUpdateSector tests executing a program with a single
+// UpdateSector instruction.
+func TestInstructionUpdateSector(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Prepare a priceTable and duration.
+	pt := newTestPriceTable()
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+
+	// Append some sectors to a storage obligation and make sure the host can
+	// read them.
+	numSectors := 5
+	so := host.newTestStorageObligation(true)
+	tb := newTestProgramBuilder(pt, duration)
+	sectors := make([][]byte, numSectors)
+	for i := range sectors {
+		sectors[i] = randomSectorData()
+		tb.AddAppendInstruction(sectors[i], false)
+	}
+	_, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for root, data := range so.sectorMap {
+		host.sectors[root] = data
+	}
+
+	// Update a random range of a random sector.
+	idx := fastrand.Uint64n(uint64(numSectors))
+	offset := fastrand.Uint64n(modules.SectorSize / 2)
+	data := fastrand.Bytes(int(1 + fastrand.Uint64n(modules.SectorSize/2)))
+	imr := so.MerkleRoot()
+	oldRoots := append([]crypto.Hash{}, so.sectorRoots...)
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddUpdateSectorInstruction(idx, offset, data, true)
+
+	// Execute it.
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Compute the expected values.
+	newSector := append([]byte{}, sectors[idx]...)
+	copy(newSector[offset:], data)
+	newRoots := append([]crypto.Hash{}, oldRoots...)
+	newRoots[idx] = crypto.MerkleRoot(newSector)
+	nmr := cachedMerkleRoot(newRoots)
+	ranges := []crypto.ProofRange{{Start: idx, End: idx + 1}}
+	expectedProof := crypto.MerkleDiffProof(ranges, uint64(len(oldRoots)), nil, oldRoots)
+	expectedOutput := encoding.Marshal([]crypto.Hash{oldRoots[idx]})
+	err = outputs[0].assert(so.ContractSize(), nmr, expectedProof, expectedOutput, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the proof against the old and new merkle root.
+	var leafHashes []crypto.Hash
+	err = encoding.Unmarshal(outputs[0].Output, &leafHashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto.VerifyDiffProof(ranges, uint64(len(oldRoots)), outputs[0].Proof, leafHashes, imr) {
+		t.Fatal("failed to verify proof against old root")
+	}
+	leafHashes[0] = crypto.MerkleRoot(newSector)
+	if !crypto.VerifyDiffProof(ranges, uint64(len(oldRoots)), outputs[0].Proof, leafHashes, nmr) {
+		t.Fatal("failed to verify proof against new root")
+	}
+
+	// The storage obligation should contain the updated sector instead of the
+	// old one.
+	if so.sectorRoots[idx] != newRoots[idx] || so.MerkleRoot() != nmr {
+		t.Fatal("sector wasn't updated")
+	}
+	if _, exists := so.sectorMap[oldRoots[idx]]; exists {
+		t.Fatal("old sector should have been removed")
+	}
+	if gained, exists := so.sectorMap[newRoots[idx]]; !exists || !bytes.Equal(gained, newSector) {
+		t.Fatal("new sector should have been added")
+	}
+
+	// Updating a sector which doesn't exist should fail.
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddUpdateSectorInstruction(uint64(numSectors), 0, data, true)
+	_, err = mdm.ExecuteProgramWithBuilder(tb, so, duration, true)
+	if err == nil || !strings.Contains(err.Error(), "idx out-of-bounds") {
+		t.Fatal("expected execution to fail with out of bounds error", err)
+	}
+}
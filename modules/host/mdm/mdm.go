@@ -23,6 +23,9 @@ type StorageObligationSnapshot interface {
 	// RecentRevision returns the recent revision at the time the snapshot was
 	// taken.
 	RecentRevision() types.FileContractRevision
+	// Resolved returns whether the storage obligation was already resolved,
+	// e.g. because it failed or was rejected.
+	Resolved() bool
 	// RevisionTxn returns the recent revision txn of the so.
 	RevisionTxn() types.Transaction
 	// SectorRoots returns the roots of the storage obligation.
@@ -35,8 +38,10 @@ type Host interface {
 	BlockHeight() types.BlockHeight
 	HasSector(crypto.Hash) bool
 	ReadSector(sectorRoot crypto.Hash) ([]byte, error)
+	ReadPartialSector(sectorRoot crypto.Hash, offset, length uint64) ([]byte, error)
 	RegistryUpdate(rv modules.SignedRegistryValue, pubKey types.SiaPublicKey, expiry types.BlockHeight) (modules.SignedRegistryValue, error)
 	RegistryGet(sid modules.RegistryEntryID) (types.SiaPublicKey, modules.SignedRegistryValue, bool)
+	StorageObligationSnapshot(id types.FileContractID) (StorageObligationSnapshot, error)
 }
 
 // MDM (Merklized Data Machine) is a virtual machine that executes instructions
@@ -50,12 +55,16 @@ type Host interface {
 type MDM struct {
 	host Host
 	tg   threadgroup.ThreadGroup
+
+	// staticStreamRoots caches the chunk roots of streamed sectors.
+	staticStreamRoots *streamRootsCache
 }
 
 // New creates a new MDM.
 func New(h Host) *MDM {
 	return &MDM{
-		host: h,
+		host:              h,
+		staticStreamRoots: newStreamRootsCache(),
 	}
 }
 
@@ -25,6 +25,7 @@ type (
 	TestHost struct {
 		generateSectors bool
 		blockHeight     types.BlockHeight
+		obligations     map[types.FileContractID]*TestStorageObligation
 		sectors         map[crypto.Hash][]byte
 		registry        map[modules.RegistryEntryID]TestRegistryValue
 		mu              sync.Mutex
@@ -41,7 +42,11 @@ type (
 		sectorRoots []crypto.Hash
 
 		// contract related fields.
-		sk crypto.SecretKey
+		id          types.FileContractID
+		pk          crypto.PublicKey
+		sk          crypto.SecretKey
+		resolved    bool
+		windowStart types.BlockHeight
 	}
 )
 
@@ -52,18 +57,26 @@ func newTestHost() *TestHost {
 func newCustomTestHost(generateSectors bool) *TestHost {
 	return &TestHost{
 		generateSectors: generateSectors,
+		obligations:     make(map[types.FileContractID]*TestStorageObligation),
 		registry:        make(map[modules.RegistryEntryID]TestRegistryValue),
 		sectors:         make(map[crypto.Hash][]byte),
 	}
 }
 
 func (h *TestHost) newTestStorageObligation(locked bool) *TestStorageObligation {
-	sk, _ := crypto.GenerateKeyPair()
-	return &TestStorageObligation{
-		host:      h,
-		sectorMap: make(map[crypto.Hash][]byte),
-		sk:        sk,
+	sk, pk := crypto.GenerateKeyPair()
+	so := &TestStorageObligation{
+		host:        h,
+		sectorMap:   make(map[crypto.Hash][]byte),
+		pk:          pk,
+		sk:          sk,
+		windowStart: types.BlocksPerYear,
 	}
+	fastrand.Read(so.id[:])
+	h.mu.Lock()
+	h.obligations[so.id] = so
+	h.mu.Unlock()
+	return so
 }
 
 // BlockHeight returns an incremented blockheight.
@@ -131,6 +144,32 @@ func (h *TestHost) ReadSector(sectorRoot crypto.Hash) ([]byte, error) {
 	return data, nil
 }
 
+// ReadPartialSector implements the Host interface by returning a part of the
+// sector returned by ReadSector.
+func (h *TestHost) ReadPartialSector(sectorRoot crypto.Hash, offset, length uint64) ([]byte, error) {
+	if offset+length > modules.SectorSize {
+		return nil, errors.New("ReadPartialSector: out of bounds")
+	}
+	data, err := h.ReadSector(sectorRoot)
+	if err != nil {
+		return nil, err
+	}
+	return data[offset : offset+length], nil
+}
+
+// StorageObligationSnapshot returns the storage obligation with the given id.
+// The test obligations don't change during the execution of a program which is
+// why they can be returned directly.
+func (h *TestHost) StorageObligationSnapshot(id types.FileContractID) (StorageObligationSnapshot, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	so, exists := h.obligations[id]
+	if !exists {
+		return nil, errors.New("storage obligation not found")
+	}
+	return so, nil
+}
+
 // AddRandomSector adds a random sector to the obligation and corresponding
 // host.
 func (so *TestStorageObligation) AddRandomSector() {
@@ -164,11 +203,24 @@ func (so *TestStorageObligation) MerkleRoot() crypto.Hash {
 // RecentRevision implements the StorageObligation interface.
 func (so *TestStorageObligation) RecentRevision() types.FileContractRevision {
 	return types.FileContractRevision{
+		ParentID: so.id,
+		UnlockConditions: types.UnlockConditions{
+			PublicKeys: []types.SiaPublicKey{
+				types.Ed25519PublicKey(so.pk),
+			},
+			SignaturesRequired: 1,
+		},
 		NewFileMerkleRoot: so.MerkleRoot(),
 		NewFileSize:       so.ContractSize(),
+		NewWindowStart:    so.windowStart,
 	}
 }
 
+// Resolved implements the StorageObligation interface.
+func (so *TestStorageObligation) Resolved() bool {
+	return so.resolved
+}
+
 // RevisionTxn returns the revision transaction for the obligation including a
 // renter sig.
 func (so *TestStorageObligation) RevisionTxn() types.Transaction {
@@ -178,7 +230,7 @@ func (so *TestStorageObligation) RevisionTxn() types.Transaction {
 		},
 		TransactionSignatures: []types.TransactionSignature{
 			{
-				ParentID:       crypto.Hash(types.FileContractID{}),
+				ParentID:       crypto.Hash(so.id),
 				PublicKeyIndex: 0,
 				CoveredFields: types.CoveredFields{
 					FileContractRevisions: []uint64{0},
@@ -230,15 +282,17 @@ func newTestPriceTable() *modules.RPCPriceTable {
 		CollateralCost:       types.NewCurrency64(1),
 
 		// Instruction costs
-		DropSectorsBaseCost: types.NewCurrency64(1),
-		DropSectorsUnitCost: types.NewCurrency64(1),
-		HasSectorBaseCost:   types.NewCurrency64(1),
-		ReadBaseCost:        types.NewCurrency64(1),
-		ReadLengthCost:      types.NewCurrency64(1),
-		SwapSectorCost:      types.NewCurrency64(1),
-		WriteBaseCost:       types.NewCurrency64(1),
-		WriteLengthCost:     types.NewCurrency64(1),
-		WriteStoreCost:      types.NewCurrency64(1),
+		DropSectorsBaseCost:    types.NewCurrency64(1),
+		DropSectorsUnitCost:    types.NewCurrency64(1),
+		HasSectorBaseCost:      types.NewCurrency64(1),
+		ReadBaseCost:           types.NewCurrency64(1),
+		ReadLengthCost:         types.NewCurrency64(1),
+		SwapSectorCost:         types.NewCurrency64(1),
+		UpdateSectorBaseCost:   types.NewCurrency64(1),
+		UpdateSectorLengthCost: types.NewCurrency64(1),
+		WriteBaseCost:          types.NewCurrency64(1),
+		WriteLengthCost:        types.NewCurrency64(1),
+		WriteStoreCost:         types.NewCurrency64(1),
 
 		// Bandwidth costs
 		DownloadBandwidthCost: types.NewCurrency64(1),
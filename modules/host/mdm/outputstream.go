@@ -0,0 +1,479 @@
+package mdm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/bits"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// outputStreamChunkSize is the size of the chunks in which streamed outputs
+// are read from disk and written to the caller. Every write to a stream is
+// padded to a full packet, so the chunks shouldn't be too small.
+const outputStreamChunkSize = 1 << 18 // 256 KiB
+
+// streamRootsCacheSize is the maximum number of sectors the MDM caches the
+// chunk roots of.
+var streamRootsCacheSize = build.Select(build.Var{
+	Standard: 4096,
+	Dev:      256,
+	Testing:  16,
+}).(int)
+
+// errOutputStreamConsumed is returned if StreamTo is called more than once on
+// the same OutputStream.
+var errOutputStreamConsumed = errors.New("output stream was already consumed")
+
+type (
+	// OutputStream is returned by read instructions of a program that was
+	// started with ExecuteProgramStream instead of the instruction's output
+	// data. It allows the caller to forward the data as it is read from disk
+	// instead of holding the full output in memory. The program doesn't
+	// continue with the next instruction until the stream was consumed.
+	OutputStream struct {
+		staticLength uint64
+		staticRoots  modules.RPCExecuteProgramStreamRoots
+		staticWrite  func(io.Writer) ([]crypto.Hash, error)
+
+		err  error
+		done chan struct{}
+		once sync.Once
+	}
+
+	// sectorReader is an io.Reader which reads a range of a sector in chunks
+	// of outputStreamChunkSize.
+	sectorReader struct {
+		staticState *programState
+		staticRoot  crypto.Hash
+		staticEnd   uint64
+
+		buf    []byte
+		offset uint64
+	}
+
+	// rangeTeeReader is an io.Reader which writes the bytes within
+	// [start,end) of the data read from the underlying reader to a writer.
+	// Since the reader might be consumed by code which doesn't forward read
+	// errors, the first write error is also remembered in werr.
+	rangeTeeReader struct {
+		r io.Reader
+		w io.Writer
+
+		pos        uint64
+		start, end uint64
+		werr       error
+	}
+
+	// streamRootsWriter is an io.Writer which computes the stream roots of a
+	// range of a sector from the sector's data. The data is expected to be
+	// written in order, starting at the beginning of the sector.
+	streamRootsWriter struct {
+		start, end uint64
+
+		buf   []byte
+		pos   uint64
+		roots modules.RPCExecuteProgramStreamRoots
+	}
+
+	// countingWriter is an io.Writer which counts the bytes written to the
+	// underlying writer.
+	countingWriter struct {
+		w io.Writer
+		n uint64
+	}
+
+	// streamRootsCache caches the roots of the MDMStreamChunkSize chunks of
+	// recently streamed sectors. Since a sector's data never changes for a
+	// given root, the entries never need to be invalidated. If the cache is
+	// full, a random entry is evicted.
+	streamRootsCache struct {
+		roots map[crypto.Hash][]crypto.Hash
+		mu    sync.Mutex
+	}
+)
+
+// newOutputStream creates a new OutputStream of the given length.
+func newOutputStream(length uint64, roots modules.RPCExecuteProgramStreamRoots, write func(io.Writer) ([]crypto.Hash, error)) *OutputStream {
+	return &OutputStream{
+		staticLength: length,
+		staticRoots:  roots,
+		staticWrite:  write,
+		done:         make(chan struct{}),
+	}
+}
+
+// Len returns the number of bytes the stream writes.
+func (s *OutputStream) Len() uint64 {
+	return s.staticLength
+}
+
+// Roots returns the roots which are sent to the caller before the output data.
+// They allow for verifying the data as it arrives.
+func (s *OutputStream) Roots() modules.RPCExecuteProgramStreamRoots {
+	return s.staticRoots
+}
+
+// StreamTo writes the output to w as it is read from disk and returns the
+// output's Merkle proof once all of the data was written. The proof is nil if
+// the program didn't request one. StreamTo may only be called once.
+func (s *OutputStream) StreamTo(w io.Writer) (proof []crypto.Hash, err error) {
+	err = errOutputStreamConsumed
+	s.once.Do(func() {
+		defer close(s.done)
+		cw := &countingWriter{w: w}
+		bw := bufio.NewWriterSize(cw, outputStreamChunkSize)
+		proof, err = s.staticWrite(bw)
+		if err == nil {
+			err = bw.Flush()
+		}
+		if err == nil && cw.n != s.staticLength {
+			err = fmt.Errorf("output stream wrote %v bytes but expected %v", cw.n, s.staticLength)
+		}
+		s.err = err
+	})
+	return
+}
+
+// managedWait blocks until the stream was consumed or the context is closed.
+func (s *OutputStream) managedWait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ErrInterrupted
+	case <-s.done:
+	}
+	return s.err
+}
+
+// newSectorReader creates a reader for the range [offset,end) of the sector
+// with the given root. The first chunk is read right away to surface errors
+// such as a missing sector before anything is written.
+func newSectorReader(ps *programState, root crypto.Hash, offset, end uint64) (*sectorReader, error) {
+	sr := &sectorReader{
+		staticState: ps,
+		staticRoot:  root,
+		staticEnd:   end,
+		offset:      offset,
+	}
+	return sr, sr.readChunk()
+}
+
+// readChunk reads the next chunk of the sector into the reader's buffer.
+func (sr *sectorReader) readChunk() error {
+	length := sr.staticEnd - sr.offset
+	if length > outputStreamChunkSize {
+		length = outputStreamChunkSize
+	}
+	data, err := sr.staticState.sectors.readPartialSector(sr.staticState.host, sr.staticRoot, sr.offset, length)
+	if err != nil {
+		return err
+	}
+	sr.buf = data
+	sr.offset += length
+	return nil
+}
+
+// Read implements io.Reader.
+func (sr *sectorReader) Read(b []byte) (int, error) {
+	if len(sr.buf) == 0 {
+		if sr.offset >= sr.staticEnd {
+			return 0, io.EOF
+		}
+		if err := sr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, sr.buf)
+	sr.buf = sr.buf[n:]
+	return n, nil
+}
+
+// Read implements io.Reader.
+func (tr *rangeTeeReader) Read(b []byte) (int, error) {
+	n, err := tr.r.Read(b)
+	if n > 0 {
+		from, to := tr.pos, tr.pos+uint64(n)
+		if from < tr.start {
+			from = tr.start
+		}
+		if to > tr.end {
+			to = tr.end
+		}
+		if from < to {
+			if _, err := tr.w.Write(b[from-tr.pos : to-tr.pos]); err != nil {
+				tr.werr = err
+				return n, err
+			}
+		}
+		tr.pos += uint64(n)
+	}
+	return n, err
+}
+
+// Write implements io.Writer.
+func (rw *streamRootsWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	for len(b) > 0 {
+		missing := int(modules.MDMStreamChunkSize) - len(rw.buf)
+		if missing > len(b) {
+			missing = len(b)
+		}
+		rw.buf = append(rw.buf, b[:missing]...)
+		b = b[missing:]
+		if len(rw.buf) == int(modules.MDMStreamChunkSize) {
+			rw.addChunk()
+		}
+	}
+	return n, nil
+}
+
+// addChunk adds the root of the buffered chunk to the stream roots as well as
+// the proof for the part of the chunk that overlaps the range.
+func (rw *streamRootsWriter) addChunk() {
+	chunkStart, chunkEnd := rw.pos, rw.pos+uint64(len(rw.buf))
+	rw.roots.Roots = append(rw.roots.Roots, crypto.MerkleRoot(rw.buf))
+
+	from, to := rw.start, rw.end
+	if from < chunkStart {
+		from = chunkStart
+	}
+	if to > chunkEnd {
+		to = chunkEnd
+	}
+	if from < to {
+		var proof []crypto.Hash
+		if from != chunkStart || to != chunkEnd {
+			proofStart := int(from-chunkStart) / crypto.SegmentSize
+			proofEnd := int(to-chunkStart) / crypto.SegmentSize
+			proof = crypto.MerkleRangeProof(rw.buf, proofStart, proofEnd)
+		}
+		rw.roots.Proofs = append(rw.roots.Proofs, proof)
+	}
+	rw.pos = chunkEnd
+	rw.buf = rw.buf[:0]
+}
+
+// Write implements io.Writer.
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.n += uint64(n)
+	return n, err
+}
+
+// newStreamRootsCache creates an empty streamRootsCache.
+func newStreamRootsCache() *streamRootsCache {
+	return &streamRootsCache{
+		roots: make(map[crypto.Hash][]crypto.Hash),
+	}
+}
+
+// managedGet returns the cached chunk roots of the sector with the given root.
+func (c *streamRootsCache) managedGet(sectorRoot crypto.Hash) ([]crypto.Hash, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	roots, exists := c.roots[sectorRoot]
+	return roots, exists
+}
+
+// managedAdd adds the chunk roots of the sector with the given root to the
+// cache.
+func (c *streamRootsCache) managedAdd(sectorRoot crypto.Hash, roots []crypto.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.roots[sectorRoot]; !exists && len(c.roots) >= streamRootsCacheSize {
+		for root := range c.roots {
+			delete(c.roots, root)
+			break
+		}
+	}
+	c.roots[sectorRoot] = roots
+}
+
+// streamRootsProof builds the Merkle proof for the range [offset,offset+length)
+// of a sector from the range's stream roots. The proof consists of the chunk
+// roots left of the range, the proofs of the partial chunks at the edges of
+// the range and the chunk roots right of the range.
+func streamRootsProof(roots modules.RPCExecuteProgramStreamRoots, offset, length uint64) []crypto.Hash {
+	firstChunk := offset / modules.MDMStreamChunkSize
+	lastChunk := (offset + length - 1) / modules.MDMStreamChunkSize
+	chunkProof := crypto.MerkleSectorRangeProof(roots.Roots, int(firstChunk), int(lastChunk+1))
+	numLeft := bits.OnesCount64(firstChunk)
+
+	proof := append([]crypto.Hash(nil), chunkProof[:numLeft]...)
+	proof = append(proof, roots.Proofs[0]...)
+	if lastChunk != firstChunk {
+		proof = append(proof, roots.Proofs[len(roots.Proofs)-1]...)
+	}
+	return append(proof, chunkProof[numLeft:]...)
+}
+
+// streamReadSector is the streaming counterpart of executeReadSector. Instead
+// of reading the whole sector into memory, the returned output contains an
+// OutputStream which reads the requested range from disk in chunks.
+//
+// If a proof was requested and the MDM knows the chunk roots of the sector,
+// they are sent as stream roots which allows the caller to verify the range
+// as it arrives. Only the partial chunks at the edges of the range need to be
+// read upfront to prove them and the final proof is built from the stream
+// roots. Otherwise, the whole sector is read in a single pass which streams
+// the range while computing the chunk roots for the proof and for future
+// reads of the sector.
+func streamReadSector(previousOutput output, ps *programState, length, offset uint64, sectorRoot crypto.Hash, merkleProof bool) output {
+	// Validate the request.
+	if err := checkReadSectorArgs(length, offset, merkleProof); err != nil {
+		return errOutput(err)
+	}
+
+	var write func(io.Writer) ([]crypto.Hash, error)
+	var roots modules.RPCExecuteProgramStreamRoots
+	chunkRoots, cached := ps.staticStreamRoots.managedGet(sectorRoot)
+	switch {
+	case !merkleProof:
+		sr, err := newSectorReader(ps, sectorRoot, offset, offset+length)
+		if err != nil {
+			return errOutput(err)
+		}
+		write = func(w io.Writer) ([]crypto.Hash, error) {
+			_, err := io.Copy(w, sr)
+			return nil, err
+		}
+	case cached:
+		var err error
+		roots, err = streamRoots(ps, sectorRoot, chunkRoots, offset, length)
+		if err != nil {
+			return errOutput(err)
+		}
+		sr, err := newSectorReader(ps, sectorRoot, offset, offset+length)
+		if err != nil {
+			return errOutput(err)
+		}
+		write = func(w io.Writer) ([]crypto.Hash, error) {
+			if _, err := io.Copy(w, sr); err != nil {
+				return nil, err
+			}
+			return streamRootsProof(roots, offset, length), nil
+		}
+	default:
+		sr, err := newSectorReader(ps, sectorRoot, 0, modules.SectorSize)
+		if err != nil {
+			return errOutput(err)
+		}
+		write = func(w io.Writer) ([]crypto.Hash, error) {
+			tr := &rangeTeeReader{r: sr, w: w, start: offset, end: offset + length}
+			rw := &streamRootsWriter{start: offset, end: offset + length}
+			if _, err := io.Copy(rw, tr); err != nil {
+				return nil, err
+			}
+			ps.staticStreamRoots.managedAdd(sectorRoot, rw.roots.Roots)
+			return streamRootsProof(rw.roots, offset, length), nil
+		}
+	}
+
+	// Return the output.
+	return output{
+		NewSize:       previousOutput.NewSize,       // size stays the same
+		NewMerkleRoot: previousOutput.NewMerkleRoot, // root stays the same
+		Stream:        newOutputStream(length, roots, write),
+	}
+}
+
+// streamRoots creates the stream roots of the range [offset,offset+length) of
+// a sector from the sector's chunk roots. Chunks that are only partially
+// covered by the range are read to build their proofs.
+func streamRoots(ps *programState, sectorRoot crypto.Hash, chunkRoots []crypto.Hash, offset, length uint64) (modules.RPCExecuteProgramStreamRoots, error) {
+	roots := modules.RPCExecuteProgramStreamRoots{Roots: chunkRoots}
+	end := offset + length
+	for chunkStart := offset / modules.MDMStreamChunkSize * modules.MDMStreamChunkSize; chunkStart < end; chunkStart += modules.MDMStreamChunkSize {
+		chunkEnd := chunkStart + modules.MDMStreamChunkSize
+		from, to := offset, end
+		if from < chunkStart {
+			from = chunkStart
+		}
+		if to > chunkEnd {
+			to = chunkEnd
+		}
+		if from == chunkStart && to == chunkEnd {
+			roots.Proofs = append(roots.Proofs, nil)
+			continue
+		}
+		chunk, err := ps.sectors.readPartialSector(ps.host, sectorRoot, chunkStart, modules.MDMStreamChunkSize)
+		if err != nil {
+			return modules.RPCExecuteProgramStreamRoots{}, err
+		}
+		proofStart := int(from-chunkStart) / crypto.SegmentSize
+		proofEnd := int(to-chunkStart) / crypto.SegmentSize
+		roots.Proofs = append(roots.Proofs, crypto.MerkleRangeProof(chunk, proofStart, proofEnd))
+	}
+	return roots, nil
+}
+
+// streamReadOffset is the streaming counterpart of the 'ReadOffset'
+// instruction's execution. The proof proves the read range against the
+// contract's root.
+func streamReadOffset(previousOutput output, ps *programState, length, offset uint64, merkleProof bool) output {
+	// Translate the offset to a root.
+	relOffset, secIdx, err := ps.sectors.translateOffset(offset)
+	if err != nil {
+		return errOutput(err)
+	}
+	sectorRoot := ps.sectors.merkleRoots[secIdx]
+
+	// Without a proof or when reading a full sector, the data can be streamed
+	// like a regular ReadSector without a proof.
+	if !merkleProof || length == modules.SectorSize {
+		out := streamReadSector(previousOutput, ps, length, relOffset, sectorRoot, false)
+		if !merkleProof || out.Error != nil {
+			return out
+		}
+		// The proof for a full sector only requires the sector roots.
+		roots := append([]crypto.Hash(nil), ps.sectors.merkleRoots...)
+		stream := out.Stream
+		out.Stream = newOutputStream(length, modules.RPCExecuteProgramStreamRoots{}, func(w io.Writer) ([]crypto.Hash, error) {
+			if _, err := stream.staticWrite(w); err != nil {
+				return nil, err
+			}
+			proofStart := int(offset) / crypto.SegmentSize
+			proofEnd := int(offset+length) / crypto.SegmentSize
+			return crypto.MerkleMixedRangeProof(roots, nil, int(modules.SectorSize), proofStart, proofEnd), nil
+		})
+		return out
+	}
+
+	// Validate the request.
+	if err := checkReadSectorArgs(length, relOffset, merkleProof); err != nil {
+		return errOutput(err)
+	}
+	sr, err := newSectorReader(ps, sectorRoot, 0, modules.SectorSize)
+	if err != nil {
+		return errOutput(err)
+	}
+
+	// The proof is built from the roots of all the other sectors and the
+	// segments of the sector that contains the range.
+	otherRoots := make([]crypto.Hash, 0, len(ps.sectors.merkleRoots)-1)
+	otherRoots = append(otherRoots, ps.sectors.merkleRoots[:secIdx]...)
+	otherRoots = append(otherRoots, ps.sectors.merkleRoots[secIdx+1:]...)
+	write := func(w io.Writer) ([]crypto.Hash, error) {
+		tr := &rangeTeeReader{r: sr, w: w, start: relOffset, end: relOffset + length}
+		proofStart := int(offset) / crypto.SegmentSize
+		proofEnd := int(offset+length) / crypto.SegmentSize
+		proof, err := crypto.MerkleMixedRangeProofFromReader(otherRoots, tr, int(modules.SectorSize), proofStart, proofEnd)
+		if tr.werr != nil {
+			return nil, tr.werr
+		}
+		return proof, err
+	}
+
+	// Return the output.
+	return output{
+		NewSize:       previousOutput.NewSize,       // size stays the same
+		NewMerkleRoot: previousOutput.NewMerkleRoot, // root stays the same
+		Stream:        newOutputStream(length, modules.RPCExecuteProgramStreamRoots{}, write),
+	}
+}
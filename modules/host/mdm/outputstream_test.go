@@ -0,0 +1,188 @@
+package mdm
+
+import (
+	"bytes"
+	"context"
+	"math/bits"
+	"reflect"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// errWriterFailed is returned by failingWriter.
+var errWriterFailed = errors.New("writer failed")
+
+// failingWriter is an io.Writer which always fails.
+type failingWriter struct{}
+
+// Write implements io.Writer.
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWriterFailed
+}
+
+// verifyStreamRoots checks that the stream roots of a ReadSector output match
+// the root of the sector and prove the output data read at offset.
+func verifyStreamRoots(roots modules.RPCExecuteProgramStreamRoots, data []byte, offset uint64, sectorRoot crypto.Hash) bool {
+	chunkHeight := bits.TrailingZeros64(modules.MDMStreamChunkSize / crypto.SegmentSize)
+	tree := crypto.NewCachedTree(uint64(chunkHeight))
+	for _, root := range roots.Roots {
+		tree.Push(root)
+	}
+	if tree.Root() != sectorRoot {
+		return false
+	}
+	end := offset + uint64(len(data))
+	firstChunk := offset / modules.MDMStreamChunkSize
+	lastChunk := (end - 1) / modules.MDMStreamChunkSize
+	if uint64(len(roots.Proofs)) != lastChunk-firstChunk+1 {
+		return false
+	}
+	for i, proof := range roots.Proofs {
+		chunk := firstChunk + uint64(i)
+		chunkStart := chunk * modules.MDMStreamChunkSize
+		from, to := chunkStart, chunkStart+modules.MDMStreamChunkSize
+		if from < offset {
+			from = offset
+		}
+		if to > end {
+			to = end
+		}
+		proofStart := int(from-chunkStart) / crypto.SegmentSize
+		proofEnd := int(to-chunkStart) / crypto.SegmentSize
+		if !crypto.VerifyRangeProof(data[from-offset:to-offset], proof, proofStart, proofEnd, roots.Roots[chunk]) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestExecuteProgramStream makes sure that streaming the outputs of read
+// instructions results in the same data and proofs as executing the program
+// without streaming.
+func TestExecuteProgramStream(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Prepare a storage obligation.
+	so := host.newTestStorageObligation(true)
+	so.AddRandomSectors(initialContractSectors)
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+
+	// Build a program which covers full and partial reads with and without
+	// proofs.
+	pt := newTestPriceTable()
+	tb := newTestProgramBuilder(pt, duration)
+	partialLen := uint64(10 * crypto.SegmentSize)
+	partialOff := uint64(3 * crypto.SegmentSize)
+	spanningLen := uint64(2*modules.MDMStreamChunkSize + crypto.SegmentSize)
+	spanningOff := uint64(modules.MDMStreamChunkSize - crypto.SegmentSize)
+	tb.AddReadSectorInstruction(modules.SectorSize, 0, so.sectorRoots[0], true)
+	tb.AddReadSectorInstruction(partialLen, partialOff, so.sectorRoots[1], true)
+	tb.AddReadSectorInstruction(spanningLen, spanningOff, so.sectorRoots[2], true)
+	tb.AddReadSectorInstruction(partialLen+1, partialOff+1, so.sectorRoots[1], false)
+	tb.AddReadOffsetInstruction(modules.SectorSize, modules.SectorSize, true)
+	tb.AddReadOffsetInstruction(partialLen, modules.SectorSize+partialOff, true)
+	tb.AddReadOffsetInstruction(partialLen, partialOff, false)
+	program, programData := tb.Program()
+	values := tb.Cost()
+	_, _, collateral, _ := values.Cost()
+
+	// Execute it without streaming.
+	_, outputChan, err := mdm.ExecuteProgram(context.Background(), pt, program, values.Budget(false), collateral, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected []Output
+	for output := range outputChan {
+		expected = append(expected, output)
+	}
+
+	// The ReadSector instructions with a proof come with stream roots once
+	// the MDM knows the chunk roots of the sectors.
+	type streamedSector struct {
+		offset uint64
+		root   crypto.Hash
+	}
+	streamedSectors := map[int]streamedSector{
+		0: {0, so.sectorRoots[0]},
+		1: {partialOff, so.sectorRoots[1]},
+		2: {spanningOff, so.sectorRoots[2]},
+	}
+
+	// executeStream executes the program with streaming and compares the
+	// outputs to the ones without streaming.
+	executeStream := func(rootsCached bool) {
+		_, outputChan, err := mdm.ExecuteProgramStream(context.Background(), pt, program, values.Budget(false), collateral, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var i int
+		for output := range outputChan {
+			if output.Error != nil {
+				t.Fatal(output.Error)
+			}
+			if output.Stream == nil {
+				t.Fatal("output should be streamed")
+			}
+			if output.Output != nil || output.Proof != nil {
+				t.Fatal("streamed output shouldn't contain data")
+			}
+			if output.Stream.Len() != uint64(len(expected[i].Output)) {
+				t.Fatal("wrong stream length", output.Stream.Len(), len(expected[i].Output))
+			}
+			buf := new(bytes.Buffer)
+			proof, err := output.Stream.StreamTo(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), expected[i].Output) {
+				t.Fatalf("%v: wrong data", i)
+			}
+			if len(proof) != len(expected[i].Proof) || (len(proof) > 0 && !reflect.DeepEqual(proof, expected[i].Proof)) {
+				t.Fatalf("%v: wrong proof", i)
+			}
+			if !output.ExecutionCost.Equals(expected[i].ExecutionCost) {
+				t.Fatalf("%v: wrong cost", i)
+			}
+			roots := output.Stream.Roots()
+			if ss, ok := streamedSectors[i]; ok && rootsCached && !verifyStreamRoots(roots, buf.Bytes(), ss.offset, ss.root) {
+				t.Fatalf("%v: invalid stream roots %v %v", i, len(roots.Roots), len(roots.Proofs))
+			} else if (!ok || !rootsCached) && (len(roots.Roots) > 0 || len(roots.Proofs) > 0) {
+				t.Fatalf("%v: unexpected stream roots", i)
+			}
+			// A stream can only be consumed once.
+			if _, err := output.Stream.StreamTo(buf); !errors.Contains(err, errOutputStreamConsumed) {
+				t.Fatal("unexpected error", err)
+			}
+			i++
+		}
+		if i != len(program) {
+			t.Fatalf("expected %v outputs but got %v", len(program), i)
+		}
+	}
+
+	// The first execution reads the sectors in a single pass and caches
+	// their chunk roots. The second one sends them as stream roots.
+	executeStream(false)
+	executeStream(true)
+
+	// Execute it again but fail streaming the first output. The program
+	// should stop.
+	_, outputChan, err = mdm.ExecuteProgramStream(context.Background(), pt, program, values.Budget(false), collateral, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := <-outputChan
+	if _, err := output.Stream.StreamTo(failingWriter{}); !errors.Contains(err, errWriterFailed) {
+		t.Fatal("unexpected error", err)
+	}
+	if _, ok := <-outputChan; ok {
+		t.Fatal("program should have stopped")
+	}
+}
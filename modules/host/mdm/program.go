@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/threadgroup"
@@ -27,6 +28,50 @@ var (
 // the changes made by the program.
 type FnFinalize func(StorageObligation) error
 
+type (
+	// Trace is the execution trace of a program. A program only records a
+	// trace if it was started with a context returned by WithTrace.
+	Trace struct {
+		// Instructions contains the traces of the instructions the program
+		// got to in the order of the program. If an instruction fails, its
+		// trace is the last one.
+		Instructions []InstructionTrace
+	}
+
+	// InstructionTrace is the execution trace of a single instruction.
+	InstructionTrace struct {
+		// Index is the index of the instruction within the program.
+		Index int
+		// ExecutionTime is the time it took to execute the instruction,
+		// including the time it took to stream its output.
+		ExecutionTime time.Duration
+		// BytesRead is the amount of sector data the instruction read from
+		// the host and BytesWritten the amount of sector data it added to the
+		// contract.
+		BytesRead    uint64
+		BytesWritten uint64
+		// Cost is the cost charged for the instruction and Refund the part of
+		// it that was refunded right after its execution.
+		Cost   types.Currency
+		Refund types.Currency
+		// Skipped indicates that the instruction wasn't executed because a
+		// previous instruction asked for it to be skipped.
+		Skipped bool
+		// Error is the error the instruction failed with.
+		Error error
+	}
+
+	// traceKey is the key of a program's trace within a context.
+	traceKey struct{}
+)
+
+// WithTrace returns a copy of ctx which makes a program record its execution
+// trace in t when passed to ExecuteProgram or ExecuteProgramStream. The trace
+// may only be accessed after the program's output channel was closed.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
 // programState contains some fields needed for the execution of instructions.
 // The program's state is captured when the program is created and remains the
 // same during the execution of the program.
@@ -46,6 +91,13 @@ type programState struct {
 
 	// budget related fields
 	priceTable *modules.RPCPriceTable
+
+	// staticStreamOutputs indicates that read instructions return an
+	// OutputStream instead of reading their whole output into memory.
+	// staticStreamRoots is the MDM's cache of the chunk roots of streamed
+	// sectors.
+	staticStreamOutputs bool
+	staticStreamRoots   *streamRootsCache
 }
 
 // program is a collection of instructions. Within a program, each instruction
@@ -64,9 +116,21 @@ type program struct {
 	failureRefund          types.Currency // This is refunded if the program doesn't commit.
 	usedMemory             uint64
 
+	// instructionIndex is the index of the instruction that is currently
+	// being executed.
+	instructionIndex int
+
 	outputChan chan Output
 	outputErr  error // contains the error of the first instruction of the program that failed
 
+	// staticDryRun indicates that the program's instructions are only priced
+	// but never executed.
+	staticDryRun bool
+
+	// staticTrace is the execution trace of the program. It is nil if the
+	// program isn't traced.
+	staticTrace *Trace
+
 	tg *threadgroup.ThreadGroup
 }
 
@@ -83,16 +147,40 @@ func outputFromError(err error, collateral, cost, refund types.Currency) Output
 	}
 }
 
+// outputError sends an output containing err to the caller and adds it to the
+// program's trace.
+func (p *program) outputError(err error) {
+	p.traceInstruction(InstructionTrace{Error: err})
+	p.outputChan <- outputFromError(err, p.additionalCollateral, p.executionCost, p.failureRefund)
+}
+
+// traceInstruction adds the trace of an instruction to the program's trace if
+// the program is traced.
+func (p *program) traceInstruction(it InstructionTrace) {
+	if p.staticTrace != nil {
+		it.Index = p.instructionIndex
+		p.staticTrace.Instructions = append(p.staticTrace.Instructions, it)
+	}
+}
+
 // decodeInstruction creates a specific instance of an instruction from a
 // specified generic instruction.
 func decodeInstruction(p *program, i modules.Instruction) (instruction, error) {
 	switch i.Specifier {
 	case modules.SpecifierAppend:
 		return p.staticDecodeAppendInstruction(i)
+	case modules.SpecifierAssertRevision:
+		return p.staticDecodeAssertRevisionInstruction(i)
+	case modules.SpecifierCopySector:
+		return p.staticDecodeCopySectorInstruction(i)
 	case modules.SpecifierDropSectors:
 		return p.staticDecodeDropSectorsInstruction(i)
 	case modules.SpecifierHasSector:
 		return p.staticDecodeHasSectorInstruction(i)
+	case modules.SpecifierIfHasSector:
+		return p.staticDecodeIfHasSectorInstruction(i)
+	case modules.SpecifierProveSegment:
+		return p.staticDecodeProveSegmentInstruction(i)
 	case modules.SpecifierReadSector:
 		return p.staticDecodeReadSectorInstruction(i)
 	case modules.SpecifierReadOffset:
@@ -101,12 +189,18 @@ func decodeInstruction(p *program, i modules.Instruction) (instruction, error) {
 		return p.staticDecodeRevisionInstruction(i)
 	case modules.SpecifierSwapSector:
 		return p.staticDecodeSwapSectorInstruction(i)
+	case modules.SpecifierUpdateSector:
+		return p.staticDecodeUpdateSectorInstruction(i)
 	case modules.SpecifierUpdateRegistry:
 		return p.staticDecodeUpdateRegistryInstruction(i)
 	case modules.SpecifierReadRegistry:
 		return p.staticDecodeReadRegistryInstruction(i)
 	case modules.SpecifierReadRegistryEID:
 		return p.staticDecodeReadRegistryEIDInstruction(i)
+	case modules.SpecifierReadRegistryBatch:
+		return p.staticDecodeReadRegistryBatchInstruction(i)
+	case modules.SpecifierUpdateRegistryBatch:
+		return p.staticDecodeUpdateRegistryBatchInstruction(i)
 	default:
 		return nil, fmt.Errorf("unknown instruction specifier: %v", i.Specifier)
 	}
@@ -114,7 +208,33 @@ func decodeInstruction(p *program, i modules.Instruction) (instruction, error) {
 
 // ExecuteProgram initializes a new program from a set of instructions and a
 // reader which can be used to fetch the program's data and executes it.
-func (mdm *MDM) ExecuteProgram(ctx context.Context, pt *modules.RPCPriceTable, p modules.Program, budget *modules.RPCBudget, collateralBudget types.Currency, sos StorageObligationSnapshot, duration types.BlockHeight, programDataLen uint64, data io.Reader) (_ FnFinalize, _ <-chan Output, err error) {
+func (mdm *MDM) ExecuteProgram(ctx context.Context, pt *modules.RPCPriceTable, p modules.Program, budget *modules.RPCBudget, collateralBudget types.Currency, sos StorageObligationSnapshot, duration types.BlockHeight, programDataLen uint64, data io.Reader) (FnFinalize, <-chan Output, error) {
+	return mdm.executeProgram(ctx, pt, p, budget, collateralBudget, sos, duration, programDataLen, data, false, false)
+}
+
+// ExecuteProgramStream executes a program the same way ExecuteProgram does but
+// the outputs of 'ReadSector' and 'ReadOffset' instructions contain an
+// OutputStream instead of the output data and proof. The stream of an output
+// needs to be consumed before the program continues with the next
+// instruction.
+func (mdm *MDM) ExecuteProgramStream(ctx context.Context, pt *modules.RPCPriceTable, p modules.Program, budget *modules.RPCBudget, collateralBudget types.Currency, sos StorageObligationSnapshot, duration types.BlockHeight, programDataLen uint64, data io.Reader) (FnFinalize, <-chan Output, error) {
+	return mdm.executeProgram(ctx, pt, p, budget, collateralBudget, sos, duration, programDataLen, data, false, true)
+}
+
+// DryRunProgram initializes a new program the same way ExecuteProgram does but
+// only prices its instructions without executing them. The outputs contain the
+// running cost, collateral and memory of the program after each instruction.
+// Since no instruction is executed, refunds that an instruction would issue
+// during execution are not included and no state is ever modified.
+func (mdm *MDM) DryRunProgram(ctx context.Context, pt *modules.RPCPriceTable, p modules.Program, budget *modules.RPCBudget, collateralBudget types.Currency, sos StorageObligationSnapshot, duration types.BlockHeight, programDataLen uint64, data io.Reader) (<-chan Output, error) {
+	_, outputs, err := mdm.executeProgram(ctx, pt, p, budget, collateralBudget, sos, duration, programDataLen, data, true, false)
+	return outputs, err
+}
+
+// executeProgram initializes a new program and executes it. If dryRun is set,
+// the instructions are only priced. If stream is set, read instructions stream
+// their outputs.
+func (mdm *MDM) executeProgram(ctx context.Context, pt *modules.RPCPriceTable, p modules.Program, budget *modules.RPCBudget, collateralBudget types.Currency, sos StorageObligationSnapshot, duration types.BlockHeight, programDataLen uint64, data io.Reader, dryRun, stream bool) (_ FnFinalize, _ <-chan Output, err error) {
 	// Sanity check program length.
 	if len(p) == 0 {
 		return nil, nil, ErrEmptyProgram
@@ -135,13 +255,19 @@ func (mdm *MDM) ExecuteProgram(ctx context.Context, pt *modules.RPCPriceTable, p
 			priceTable:              pt,
 			sectors:                 newSectors(sos.SectorRoots()),
 			staticRevisionTxn:       sos.RevisionTxn(),
+			staticStreamOutputs:     stream,
+			staticStreamRoots:       mdm.staticStreamRoots,
 		},
 		staticBudget:           budget,
 		usedMemory:             modules.MDMInitMemory(),
 		staticCollateralBudget: collateralBudget,
 		staticData:             openProgramData(data, programDataLen),
+		staticDryRun:           dryRun,
 		tg:                     &mdm.tg,
 	}
+	if trace, ok := ctx.Value(traceKey{}).(*Trace); ok && !dryRun {
+		program.staticTrace = trace
+	}
 	// Convert the instructions.
 	for _, i := range p {
 		instruction, err := decodeInstruction(program, i)
@@ -174,8 +300,9 @@ func (mdm *MDM) ExecuteProgram(ctx context.Context, pt *modules.RPCPriceTable, p
 		defer close(program.outputChan)
 		program.outputErr = program.executeInstructions(ctx, sos.ContractSize(), sos.MerkleRoot())
 	}()
-	// If the program is readonly there is no need to finalize it.
-	if p.ReadOnly() {
+	// If the program is readonly or a dry run there is no need to finalize
+	// it.
+	if p.ReadOnly() || dryRun {
 		return nil, program.outputChan, nil
 	}
 	return program.managedFinalize, program.outputChan, nil
@@ -227,64 +354,170 @@ func (p *program) executeInstructions(ctx context.Context, fcSize uint64, fcRoot
 		NewMerkleRoot: fcRoot,
 	}
 	var refund types.Currency
+	var skip uint64
 	for idx, i := range p.instructions {
+		p.instructionIndex = idx
 		select {
 		case <-ctx.Done(): // Check for interrupt
-			p.outputChan <- outputFromError(ErrInterrupted, p.additionalCollateral, p.executionCost, p.failureRefund)
+			p.outputError(ErrInterrupted)
 			return ErrInterrupted
 		default:
 		}
+		// Figure out whether to recommend the caller to batch this instruction
+		// with the next one. We batch if the instruction is supposed to be
+		// batched and if it's not the last instruction in the program.
+		batch := idx < len(p.instructions)-1 && p.instructions[idx+1].Batch()
+		// Skip the instruction if a previous instruction asked for it.
+		if skip > 0 {
+			skip--
+			if err := p.skipInstruction(i, output, batch); err != nil {
+				return err
+			}
+			continue
+		}
 		// Increment collateral first.
 		collateral := i.Collateral()
 		err := p.addCollateral(collateral)
 		if err != nil {
-			p.outputChan <- outputFromError(err, p.additionalCollateral, p.executionCost, p.failureRefund)
+			p.outputError(err)
 			return err
 		}
 		// Add the memory the next instruction is going to allocate to the
 		// total.
 		p.usedMemory += i.Memory()
-		time, err := i.Time()
+		instructionTime, err := i.Time()
 		if err != nil {
-			p.outputChan <- outputFromError(err, p.additionalCollateral, p.executionCost, p.failureRefund)
+			p.outputError(err)
+			return err
 		}
-		memoryCost := modules.MDMMemoryCost(p.staticProgramState.priceTable, p.usedMemory, time)
+		memoryCost := modules.MDMMemoryCost(p.staticProgramState.priceTable, p.usedMemory, instructionTime)
 		// Get the instruction cost and storageCost.
 		instructionCost, failureRefund, err := i.Cost()
 		if err != nil {
-			p.outputChan <- outputFromError(err, p.additionalCollateral, p.executionCost, p.failureRefund)
+			p.outputError(err)
 			return err
 		}
 		cost := memoryCost.Add(instructionCost)
 		// Increment the cost.
 		err = p.addCost(cost)
 		if err != nil {
-			p.outputChan <- outputFromError(err, p.additionalCollateral, p.executionCost, p.failureRefund)
+			p.outputError(err)
 			return err
 		}
 		// Add the instruction's potential refund to the total.
 		p.failureRefund = p.failureRefund.Add(failureRefund)
-		// Figure out whether to recommend the caller to batch this instruction
-		// with the next one. We batch if the instruction is supposed to be
-		// batched and if it's not the last instruction in the program.
-		batch := idx < len(p.instructions)-1 && p.instructions[idx+1].Batch()
-		// Execute next instruction.
-		output, refund = i.Execute(output)
+		// Execute next instruction. A dry run skips the execution and keeps
+		// the previous output.
+		var executionTime time.Duration
+		start := time.Now()
+		bytesRead := p.staticProgramState.sectors.bytesRead
+		bytesWritten := p.staticProgramState.sectors.bytesWritten
+		if p.staticDryRun {
+			refund = types.ZeroCurrency
+		} else {
+			output, refund = i.Execute(output)
+			executionTime = time.Since(start)
+		}
+		// Remember how many of the following instructions to skip.
+		if remaining := uint64(len(p.instructions) - idx - 1); output.Skip > remaining {
+			output = errOutput(fmt.Errorf("can't skip %v instructions when there are only %v instructions left", output.Skip, remaining))
+		}
+		skip = output.Skip
 		// Issue potential refund.
 		if !refund.IsZero() {
 			p.refundCost(refund)
 		}
+		p.traceInstruction(InstructionTrace{
+			ExecutionTime: executionTime,
+			BytesRead:     p.staticProgramState.sectors.bytesRead - bytesRead,
+			BytesWritten:  p.staticProgramState.sectors.bytesWritten - bytesWritten,
+			Cost:          cost,
+			Refund:        refund,
+			Error:         output.Error,
+		})
 		p.outputChan <- Output{
 			output:               output,
 			Batch:                batch,
 			ExecutionCost:        p.executionCost,
 			AdditionalCollateral: p.additionalCollateral,
 			FailureRefund:        p.failureRefund,
+			ExecutionTime:        executionTime,
+			InstructionTime:      instructionTime,
+			UsedMemory:           p.usedMemory,
 		}
 		// Abort if the last output contained an error.
 		if output.Error != nil {
 			return output.Error
 		}
+		// Wait for a streamed output to be consumed before continuing. If
+		// streaming failed, the program can't continue either.
+		if output.Stream != nil {
+			err := output.Stream.managedWait(ctx)
+			// The data is read from disk while it is streamed, so the trace
+			// is only complete once the stream was consumed. If the program
+			// was interrupted, the stream might still be reading.
+			if p.staticTrace != nil {
+				it := &p.staticTrace.Instructions[len(p.staticTrace.Instructions)-1]
+				it.ExecutionTime = time.Since(start)
+				it.Error = err
+				if err == nil {
+					it.BytesRead = p.staticProgramState.sectors.bytesRead - bytesRead
+				}
+			}
+			if err != nil {
+				return err
+			}
+			output.Stream = nil
+		}
+	}
+	return nil
+}
+
+// skipInstruction skips an instruction instead of executing it. The cost of
+// the instruction is withdrawn from the budget like the cost of any other
+// instruction, but since the instruction is never executed, the full cost is
+// refunded right away using refundCost. Skipped instructions don't add any
+// collateral or memory to the program and keep the size and root of the
+// contract.
+func (p *program) skipInstruction(i instruction, prevOutput output, batch bool) error {
+	instructionTime, err := i.Time()
+	if err != nil {
+		p.outputError(err)
+		return err
+	}
+	memoryCost := modules.MDMMemoryCost(p.staticProgramState.priceTable, p.usedMemory, instructionTime)
+	instructionCost, _, err := i.Cost()
+	if err != nil {
+		p.outputError(err)
+		return err
+	}
+	cost := memoryCost.Add(instructionCost)
+	err = p.addCost(cost)
+	if err != nil {
+		p.outputError(err)
+		return err
+	}
+	// refundCost also subtracts the refund from the failure refund, so the
+	// cost is added to it first.
+	p.failureRefund = p.failureRefund.Add(cost)
+	p.refundCost(cost)
+	p.traceInstruction(InstructionTrace{
+		Cost:    cost,
+		Refund:  cost,
+		Skipped: true,
+	})
+	p.outputChan <- Output{
+		output: output{
+			NewSize:       prevOutput.NewSize,
+			NewMerkleRoot: prevOutput.NewMerkleRoot,
+		},
+		Batch:                batch,
+		ExecutionCost:        p.executionCost,
+		AdditionalCollateral: p.additionalCollateral,
+		FailureRefund:        p.failureRefund,
+		InstructionTime:      instructionTime,
+		UsedMemory:           p.usedMemory,
+		Skipped:              true,
 	}
 	return nil
 }
@@ -133,3 +133,168 @@ func TestNewProgramLowCollateralBudget(t *testing.T) {
 		t.Fatal("shouldn't be able to finalize program")
 	}
 }
+
+// TestDryRunProgram tests that a dry run of a program charges the same costs
+// as executing it without modifying any state.
+func TestDryRunProgram(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Create a program that appends a sector and updates a registry entry.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.SiaPublicKey{
+		Algorithm: types.SignatureEd25519,
+		Key:       pk[:],
+	}
+	rv := modules.NewRegistryValue(crypto.Hash{1}, fastrand.Bytes(modules.RegistryDataSize), 0).Sign(sk)
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+	pt := newTestPriceTable()
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddAppendInstruction(sectorData, true)
+	tb.AddUpdateRegistryInstruction(spk, rv)
+	program, data := tb.Program()
+	values := tb.Cost()
+	_, _, collateral, _ := values.Cost()
+
+	// Dry run the program.
+	so := host.newTestStorageObligation(true)
+	budget := values.Budget(false)
+	outputChan, err := mdm.DryRunProgram(context.Background(), pt, program, budget, collateral, so, duration, uint64(len(data)), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outputs []Output
+	for output := range outputChan {
+		if output.Error != nil {
+			t.Fatal(output.Error)
+		}
+		if len(output.Output) != 0 || len(output.Proof) != 0 {
+			t.Fatal("dry run shouldn't produce any output data")
+		}
+		outputs = append(outputs, output)
+	}
+
+	// The costs should match the expected values.
+	if err := values.AssertOutputs(outputs); err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].InstructionTime != modules.MDMTimeAppend {
+		t.Fatal("wrong instruction time", outputs[0].InstructionTime)
+	}
+	if outputs[1].UsedMemory != modules.MDMInitMemory()+modules.MDMAppendMemory()+modules.MDMUpdateRegistryMemory() {
+		t.Fatal("wrong memory", outputs[1].UsedMemory)
+	}
+	if !budget.Remaining().IsZero() {
+		t.Fatal("budget should be depleted", budget.Remaining())
+	}
+
+	// Nothing should have changed.
+	if len(so.sectorRoots) != 0 {
+		t.Fatal("sector was appended")
+	}
+	if _, _, exists := host.RegistryGet(modules.DeriveRegistryEntryID(spk, rv.Tweak)); exists {
+		t.Fatal("registry was updated")
+	}
+
+	// Dry running the program with a lower budget should fail the same way
+	// executing it would.
+	cost, _, _, _ := values.Cost()
+	budget = modules.NewBudget(cost.Sub64(1))
+	outputChan, err = mdm.DryRunProgram(context.Background(), pt, program, budget, collateral, so, duration, uint64(len(data)), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs = outputs[:0]
+	for output := range outputChan {
+		outputs = append(outputs, output)
+	}
+	if len(outputs) != 2 || !errors.Contains(outputs[1].Error, modules.ErrMDMInsufficientBudget) {
+		t.Fatal("expected insufficient budget error for last instruction", len(outputs))
+	}
+}
+
+// TestProgramTrace tests that a program records its execution trace if it was
+// started with a traced context.
+func TestProgramTrace(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Prepare a contract with a sector.
+	so := host.newTestStorageObligation(true)
+	so.AddRandomSectors(1)
+	sectorRoot := so.sectorRoots[0]
+	var missingRoot crypto.Hash
+	fastrand.Read(missingRoot[:])
+
+	// Create a program that appends a sector, reads a sector and skips an
+	// instruction.
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+	pt := newTestPriceTable()
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddAppendInstruction(fastrand.Bytes(int(modules.SectorSize)), false)
+	tb.AddReadSectorInstruction(modules.SectorSize, 0, sectorRoot, false)
+	tb.AddIfHasSectorInstruction(missingRoot, 1)
+	tb.AddHasSectorInstruction(sectorRoot)
+	program, data := tb.Program()
+	values := tb.Cost()
+	_, _, collateral, _ := values.Cost()
+
+	// Execute it with a traced context.
+	var trace Trace
+	ctx := WithTrace(context.Background(), &trace)
+	_, outputChan, err := mdm.ExecuteProgram(ctx, pt, program, values.Budget(false), collateral, so, duration, uint64(len(data)), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lastOutput Output
+	for output := range outputChan {
+		if output.Error != nil {
+			t.Fatal(output.Error)
+		}
+		lastOutput = output
+	}
+
+	// Check the trace.
+	if len(trace.Instructions) != len(program) {
+		t.Fatalf("expected %v traces but got %v", len(program), len(trace.Instructions))
+	}
+	if it := trace.Instructions[0]; it.BytesWritten != modules.SectorSize || it.BytesRead != 0 {
+		t.Fatal("wrong bytes for append", it.BytesRead, it.BytesWritten)
+	}
+	if it := trace.Instructions[1]; it.BytesRead != modules.SectorSize || it.BytesWritten != 0 {
+		t.Fatal("wrong bytes for read", it.BytesRead, it.BytesWritten)
+	}
+	if it := trace.Instructions[3]; !it.Skipped || !it.Refund.Equals(it.Cost) {
+		t.Fatal("last instruction should be skipped and refunded", it.Skipped, it.Cost, it.Refund)
+	}
+	totalCost := modules.MDMInitCost(pt, uint64(len(data)), uint64(len(program)))
+	for _, it := range trace.Instructions {
+		if it.Error != nil {
+			t.Fatal(it.Error)
+		}
+		totalCost = totalCost.Add(it.Cost).Sub(it.Refund)
+	}
+	if !totalCost.Equals(lastOutput.ExecutionCost) {
+		t.Fatalf("trace cost %v doesn't match execution cost %v", totalCost, lastOutput.ExecutionCost)
+	}
+
+	// Execute it with an insufficient budget. The failing instruction should
+	// be the last one in the trace.
+	cost, _, _, _ := values.Cost()
+	trace = Trace{}
+	_, outputChan, err = mdm.ExecuteProgram(ctx, pt, program, modules.NewBudget(cost.Sub64(1)), collateral, so, duration, uint64(len(data)), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range outputChan {
+	}
+	if len(trace.Instructions) != len(program) {
+		t.Fatalf("expected %v traces but got %v", len(program), len(trace.Instructions))
+	}
+	if !errors.Contains(trace.Instructions[3].Error, modules.ErrMDMInsufficientBudget) {
+		t.Fatal("expected insufficient budget error", trace.Instructions[3].Error)
+	}
+}
@@ -13,6 +13,12 @@ type sectors struct {
 	sectorsRemoved map[crypto.Hash]struct{}
 	sectorsGained  map[crypto.Hash][]byte
 	merkleRoots    []crypto.Hash
+
+	// bytesRead counts the sector data read from the host and bytesWritten
+	// the sector data added to the contract. They are only used for the
+	// program's execution trace.
+	bytesRead    uint64
+	bytesWritten uint64
 }
 
 // newSectors creates a program cache given an initial list of sector roots.
@@ -44,6 +50,7 @@ func (s *sectors) appendSector(sectorData []byte) (crypto.Hash, error) {
 
 	// Update the roots.
 	s.merkleRoots = append(s.merkleRoots, newRoot)
+	s.bytesWritten += uint64(len(sectorData))
 
 	// Return the new merkle root of the contract.
 	return cachedMerkleRoot(s.merkleRoots), nil
@@ -102,6 +109,47 @@ func (s *sectors) swapSectors(idx1, idx2 uint64) (crypto.Hash, error) {
 	return cachedMerkleRoot(s.merkleRoots), nil
 }
 
+// updateSector overwrites the data at the given offset within the sector at
+// the given index. It returns the root of the sector before the update and the
+// new merkle root.
+func (s *sectors) updateSector(host Host, idx, offset uint64, data []byte) (crypto.Hash, crypto.Hash, error) {
+	if idx >= uint64(len(s.merkleRoots)) {
+		return crypto.Hash{}, crypto.Hash{}, fmt.Errorf("idx out-of-bounds: %v >= %v", idx, len(s.merkleRoots))
+	}
+	if offset > modules.SectorSize || uint64(len(data)) > modules.SectorSize-offset {
+		return crypto.Hash{}, crypto.Hash{}, fmt.Errorf("update is out of bounds %v + %v > %v", offset, len(data), modules.SectorSize)
+	}
+	oldRoot := s.merkleRoots[idx]
+	oldData, err := s.readSector(host, oldRoot)
+	if err != nil {
+		return crypto.Hash{}, crypto.Hash{}, err
+	}
+
+	// Modify a copy of the sector to avoid changing the cached data.
+	sectorData := make([]byte, len(oldData))
+	copy(sectorData, oldData)
+	copy(sectorData[offset:], data)
+	newRoot := crypto.MerkleRoot(sectorData)
+
+	// Update the program cache. The old sector is removed first, then the new
+	// one is added.
+	if _, gained := s.sectorsGained[oldRoot]; gained {
+		delete(s.sectorsGained, oldRoot)
+	} else {
+		s.sectorsRemoved[oldRoot] = struct{}{}
+	}
+	if _, removed := s.sectorsRemoved[newRoot]; removed {
+		delete(s.sectorsRemoved, newRoot)
+	} else {
+		s.sectorsGained[newRoot] = sectorData
+	}
+
+	// Update the roots.
+	s.merkleRoots[idx] = newRoot
+	s.bytesWritten += uint64(len(data))
+	return oldRoot, cachedMerkleRoot(s.merkleRoots), nil
+}
+
 // translateOffset translates an offset within a filecontract into a relative
 // offset within a sector and the sector's index within the contract.
 func (s *sectors) translateOffset(offset uint64) (uint64, uint64, error) {
@@ -123,5 +171,30 @@ func (s *sectors) readSector(host Host, sectorRoot crypto.Hash) ([]byte, error)
 	}
 
 	// Check the host.
-	return host.ReadSector(sectorRoot)
+	data, err := host.ReadSector(sectorRoot)
+	if err != nil {
+		return nil, err
+	}
+	s.bytesRead += uint64(len(data))
+	return data, nil
+}
+
+// readPartialSector reads length bytes at the given offset from the sector
+// with the given root.
+func (s *sectors) readPartialSector(host Host, sectorRoot crypto.Hash, offset, length uint64) ([]byte, error) {
+	// The root exists. First check the gained sectors.
+	if data, exists := s.sectorsGained[sectorRoot]; exists {
+		if offset+length > uint64(len(data)) {
+			return nil, fmt.Errorf("readPartialSector: range %v-%v out of bounds", offset, offset+length)
+		}
+		return data[offset : offset+length], nil
+	}
+
+	// Check the host.
+	data, err := host.ReadPartialSector(sectorRoot, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	s.bytesRead += uint64(len(data))
+	return data, nil
 }
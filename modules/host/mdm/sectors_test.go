@@ -306,3 +306,66 @@ func TestReadSector(t *testing.T) {
 		}
 	}
 }
+
+// TestUpdateSector tests updating a range of a sector in the cache.
+func TestUpdateSector(t *testing.T) {
+	// Initialize the host and sectors. The roots need to match the data to be
+	// able to restore a sector.
+	host := newCustomTestHost(false)
+	var sectorRoots []crypto.Hash
+	for i := 0; i < initialContractSectors; i++ {
+		data := randomSectorData()
+		root := crypto.MerkleRoot(data)
+		host.sectors[root] = data
+		sectorRoots = append(sectorRoots, root)
+	}
+	s := newSectors(append([]crypto.Hash{}, sectorRoots...))
+
+	// Update a sector stored on the host.
+	oldData := append([]byte{}, host.sectors[sectorRoots[3]]...)
+	update := fastrand.Bytes(100)
+	oldRoot, root, err := s.updateSector(host, 3, 64, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := append([]byte{}, oldData...)
+	copy(expectedData[64:], update)
+	newRoot := crypto.MerkleRoot(expectedData)
+	if oldRoot != sectorRoots[3] || s.merkleRoots[3] != newRoot || root != cachedMerkleRoot(s.merkleRoots) {
+		t.Fatal("wrong roots after update")
+	}
+	if !bytes.Equal(host.sectors[sectorRoots[3]], oldData) {
+		t.Fatal("data on host was modified")
+	}
+	if _, removed := s.sectorsRemoved[oldRoot]; !removed || !bytes.Equal(s.sectorsGained[newRoot], expectedData) {
+		t.Fatal("cache wasn't updated")
+	}
+
+	// Update the same sector again. The intermediate sector should be removed
+	// from the cache.
+	update2 := fastrand.Bytes(100)
+	_, _, err = s.updateSector(host, 3, 0, update2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, gained := s.sectorsGained[newRoot]; gained || len(s.sectorsGained) != 1 {
+		t.Fatal("intermediate sector should have been removed from the cache")
+	}
+
+	// Restore the original data. The sector shouldn't be removed anymore.
+	_, root, err = s.updateSector(host, 3, 0, oldData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != cachedMerkleRoot(sectorRoots) || len(s.sectorsGained) != 0 || len(s.sectorsRemoved) != 0 {
+		t.Fatal("cache should be empty after restoring the sector")
+	}
+
+	// Out of bounds updates should fail.
+	if _, _, err := s.updateSector(host, initialContractSectors, 0, update); err == nil {
+		t.Fatal("expected error for out of bounds index")
+	}
+	if _, _, err := s.updateSector(host, 0, modules.SectorSize-1, update); err == nil {
+		t.Fatal("expected error for out of bounds offset")
+	}
+}
@@ -61,6 +61,32 @@ func (v *TestValues) AddAppendInstruction(data []byte) {
 	v.addInstruction(collateral, cost, refund, types.ZeroCurrency, memory, time, newData, readonly, batch)
 }
 
+// AddAssertRevisionInstruction adds an assertrevision instruction to the
+// builder, keeping track of running values.
+func (v *TestValues) AddAssertRevisionInstruction() {
+	collateral := modules.MDMAssertRevisionCollateral()
+	cost := modules.MDMAssertRevisionCost(v.staticPT)
+	memory := modules.MDMAssertRevisionMemory()
+	time := uint64(modules.MDMTimeAssertRevision)
+	newData := 8
+	readonly := true
+	batch := true
+	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
+// AddCopySectorInstruction adds the cost of a copy sector instruction to the
+// object.
+func (v *TestValues) AddCopySectorInstruction() {
+	memory := modules.MDMCopySectorMemory()
+	collateral := modules.MDMCopySectorCollateral(v.staticPT)
+	cost, refund := modules.MDMCopySectorCost(v.staticPT, v.staticDuration)
+	time := uint64(modules.MDMTimeCopySector)
+	newData := 2*crypto.HashSize + crypto.SignatureSize
+	readonly := false
+	batch := false
+	v.addInstruction(collateral, cost, refund, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
 // AddDropSectorsInstruction adds the cost of a drop sectors instruction to the
 // object.
 func (v *TestValues) AddDropSectorsInstruction(numSectors uint64) {
@@ -87,6 +113,32 @@ func (v *TestValues) AddHasSectorInstruction() {
 	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
 }
 
+// AddIfHasSectorInstruction adds an ifhassector instruction to the builder,
+// keeping track of running values.
+func (v *TestValues) AddIfHasSectorInstruction() {
+	collateral := modules.MDMIfHasSectorCollateral()
+	cost := modules.MDMIfHasSectorCost(v.staticPT)
+	memory := modules.MDMIfHasSectorMemory()
+	time := uint64(modules.MDMTimeIfHasSector)
+	newData := crypto.HashSize
+	readonly := true
+	batch := true
+	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
+// AddProveSegmentInstruction adds a provesegment instruction to the builder,
+// keeping track of running values.
+func (v *TestValues) AddProveSegmentInstruction() {
+	collateral := modules.MDMProveSegmentCollateral()
+	cost := modules.MDMProveSegmentCost(v.staticPT)
+	memory := modules.MDMProveSegmentMemory()
+	time := uint64(modules.MDMTimeProveSegment)
+	newData := crypto.HashSize
+	readonly := true
+	batch := false
+	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
 // AddReadOffsetInstruction adds a readoffset instruction to the builder,
 // keeping track of running values.
 func (v *TestValues) AddReadOffsetInstruction(length uint64) {
@@ -138,6 +190,19 @@ func (v *TestValues) AddSwapSectorInstruction() {
 	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
 }
 
+// AddUpdateSectorInstruction adds an UpdateSector instruction to the builder,
+// keeping track of running values.
+func (v *TestValues) AddUpdateSectorInstruction(data []byte) {
+	collateral := modules.MDMUpdateSectorCollateral()
+	cost := modules.MDMUpdateSectorCost(v.staticPT, uint64(len(data)))
+	memory := modules.MDMUpdateSectorMemory()
+	time := uint64(modules.MDMTimeUpdateSector)
+	newData := 8 + 8 + 8 + len(data)
+	readonly := false
+	batch := false
+	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
 // AddUpdateRegistryInstruction adds a revision instruction to the builder, keeping
 // track of running values.
 func (v *TestValues) AddUpdateRegistryInstruction(spk types.SiaPublicKey, rv modules.SignedRegistryValue) {
@@ -168,6 +233,38 @@ func (v *TestValues) AddReadRegistryInstruction(spk types.SiaPublicKey, refunded
 	v.addInstruction(collateral, cost, refund, successRefund, memory, time, newData, readonly, batch)
 }
 
+// AddReadRegistryBatchInstruction adds a read registry batch instruction to
+// the builder, keeping track of running values. numRefunded is the number of
+// entries which are expected to be refunded since they don't exist.
+func (v *TestValues) AddReadRegistryBatchInstruction(sids []modules.RegistryEntryID, numRefunded uint64) {
+	numEntries := uint64(len(sids))
+	memory := modules.MDMReadRegistryBatchMemory()
+	collateral := modules.MDMReadRegistryBatchCollateral()
+	cost, refund := modules.MDMReadRegistryBatchCost(v.staticPT, numEntries)
+	time := modules.MDMReadRegistryBatchTime(numEntries)
+	newData := len(sids) * crypto.HashSize
+	readonly := true
+	batch := true
+	successRefund := refund.Div64(numEntries).Mul64(numRefunded)
+	v.addInstruction(collateral, cost, refund, successRefund, memory, time, newData, readonly, batch)
+}
+
+// AddUpdateRegistryBatchInstruction adds an update registry batch instruction
+// to the builder, keeping track of running values. numFailed is the number of
+// updates which are expected to fail and be refunded.
+func (v *TestValues) AddUpdateRegistryBatchInstruction(updates []modules.RegistryBatchUpdate, numFailed uint64) {
+	numEntries := uint64(len(updates))
+	memory := modules.MDMUpdateRegistryBatchMemory()
+	collateral := modules.MDMUpdateRegistryBatchCollateral()
+	cost, refund := modules.MDMUpdateRegistryBatchCost(v.staticPT, numEntries)
+	time := modules.MDMUpdateRegistryBatchTime(numEntries)
+	newData := len(encoding.Marshal(updates))
+	readonly := true
+	batch := true
+	successRefund := refund.Div64(numEntries).Mul64(numFailed)
+	v.addInstruction(collateral, cost, refund, successRefund, memory, time, newData, readonly, batch)
+}
+
 // AddReadRegistryEIDInstruction adds a revision instruction to the builder,
 // keeping track of running values.
 func (v *TestValues) AddReadRegistryEIDInstruction(sid modules.RegistryEntryID, refunded bool) {
@@ -0,0 +1,110 @@
+package host
+
+import (
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"gitlab.com/NebulousLabs/siamux"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/mdm"
+	"go.sia.tech/siad/types"
+)
+
+// mdmTracePrecision is the precision with which the host samples the programs
+// it traces.
+const mdmTracePrecision = 1e6
+
+// mdmTraces is a ring buffer of the most recent execution traces of MDM
+// programs. The traces are not persisted.
+type mdmTraces struct {
+	traces []modules.HostMDMTrace
+	next   int // index of the oldest trace once the buffer is full
+	mu     sync.Mutex
+}
+
+// newMDMTraces creates a new ring buffer which holds up to size traces.
+func newMDMTraces(size int) *mdmTraces {
+	return &mdmTraces{
+		traces: make([]modules.HostMDMTrace, 0, size),
+	}
+}
+
+// managedAdd adds a trace to the buffer. If the buffer is full, the oldest
+// trace is overwritten.
+func (mt *mdmTraces) managedAdd(trace modules.HostMDMTrace) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if len(mt.traces) < cap(mt.traces) {
+		mt.traces = append(mt.traces, trace)
+		return
+	}
+	mt.traces[mt.next] = trace
+	mt.next = (mt.next + 1) % len(mt.traces)
+}
+
+// managedTraces returns the traces in the buffer, starting with the most
+// recent one.
+func (mt *mdmTraces) managedTraces() []modules.HostMDMTrace {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	traces := make([]modules.HostMDMTrace, 0, len(mt.traces))
+	for i := len(mt.traces) - 1; i >= 0; i-- {
+		traces = append(traces, mt.traces[(mt.next+i)%len(mt.traces)])
+	}
+	return traces
+}
+
+// managedTraceProgram decides whether the next program should be traced. It
+// returns whether the program was sampled and whether its trace should be kept
+// if it fails. If neither is true, the program doesn't need to be traced.
+func (h *Host) managedTraceProgram() (sampled, failures bool) {
+	h.mu.RLock()
+	rate, failures := h.settings.MDMTraceSampleRate, h.settings.MDMTraceFailures
+	h.mu.RUnlock()
+	sampled = fastrand.Uint64n(mdmTracePrecision) < uint64(rate*mdmTracePrecision)
+	return sampled, failures
+}
+
+// managedAddMDMTrace converts the execution trace of a program that was
+// executed on the given stream and adds it to the host's traces.
+func (h *Host) managedAddMDMTrace(stream siamux.Stream, fcid types.FileContractID, program modules.Program, trace *mdm.Trace, start time.Time, lastOutput mdm.Output) {
+	client, _ := h.staticClients.managedStreamClient(stream)
+	t := modules.HostMDMTrace{
+		ClientKey:     client.PublicKey,
+		ClientType:    client.Type,
+		ContractID:    fcid,
+		Start:         start,
+		ExecutionTime: time.Since(start),
+		Cost:          lastOutput.ExecutionCost,
+		Instructions:  make([]modules.HostMDMInstructionTrace, 0, len(trace.Instructions)),
+	}
+	if lastOutput.Error != nil {
+		t.Error = lastOutput.Error.Error()
+	}
+	for _, it := range trace.Instructions {
+		instruction := modules.HostMDMInstructionTrace{
+			ExecutionTime: it.ExecutionTime,
+			BytesRead:     it.BytesRead,
+			BytesWritten:  it.BytesWritten,
+			Cost:          it.Cost,
+			Refund:        it.Refund,
+			Skipped:       it.Skipped,
+		}
+		if it.Index >= 0 && it.Index < len(program) {
+			instruction.Name = types.Specifier(program[it.Index].Specifier).String()
+		}
+		if it.Error != nil {
+			instruction.Error = it.Error.Error()
+		}
+		t.Instructions = append(t.Instructions, instruction)
+	}
+	h.staticMDMTraces.managedAdd(t)
+}
+
+// MDMTraces returns the most recent execution traces of MDM programs,
+// starting with the most recent one.
+func (h *Host) MDMTraces() []modules.HostMDMTrace {
+	return h.staticMDMTraces.managedTraces()
+}
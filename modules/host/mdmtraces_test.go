@@ -0,0 +1,238 @@
+package host
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestMDMTraces is a unit test for the ring buffer of MDM traces.
+func TestMDMTraces(t *testing.T) {
+	mt := newMDMTraces(3)
+	if traces := mt.managedTraces(); len(traces) != 0 {
+		t.Fatal("expected no traces", traces)
+	}
+
+	// Add traces until the buffer wraps around. The most recent trace should
+	// always come first.
+	for i := 0; i < 5; i++ {
+		mt.managedAdd(modules.HostMDMTrace{ExecutionTime: time.Duration(i)})
+		traces := mt.managedTraces()
+		expectedLen := i + 1
+		if expectedLen > 3 {
+			expectedLen = 3
+		}
+		if len(traces) != expectedLen {
+			t.Fatalf("%v: expected %v traces but got %v", i, expectedLen, len(traces))
+		}
+		for j, trace := range traces {
+			if trace.ExecutionTime != time.Duration(i-j) {
+				t.Fatalf("%v: wrong trace at index %v: %v", i, j, trace.ExecutionTime)
+			}
+		}
+	}
+}
+
+// TestHostMDMTraces checks that the host keeps the execution traces of sampled
+// and failed programs.
+func TestHostMDMTraces(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rhp, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := rhp.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	h := rhp.staticHT.host
+
+	// The sample rate must be a fraction.
+	settings := h.InternalSettings()
+	settings.MDMTraceSampleRate = 1.5
+	if err := h.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected invalid sample rate to be rejected")
+	}
+
+	// Only trace failed programs.
+	settings.MDMTraceSampleRate = 0
+	settings.MDMTraceFailures = true
+	if err := h.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund an account.
+	pt := rhp.managedPriceTable()
+	_, err = rhp.managedFundEphemeralAccount(pt.FundAccountCost.Add(pt.InitBaseCost.Mul64(1000)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// executeProgram executes a program that checks for a sector and then
+	// reads it.
+	executeProgram := func(root crypto.Hash) {
+		pb := modules.NewProgramBuilder(pt, 0)
+		pb.AddHasSectorInstruction(root)
+		pb.AddReadSectorInstruction(modules.SectorSize, 0, root, false)
+		program, data := pb.Program()
+		programCost, _, _ := pb.Cost(true)
+		epr := modules.RPCExecuteProgramRequest{
+			FileContractID:    rhp.staticFCID,
+			Program:           program,
+			ProgramDataLength: uint64(len(data)),
+		}
+		budget := programCost.Add(pt.DownloadBandwidthCost.Add(pt.UploadBandwidthCost).Mul64(1 << 16))
+		_, _, err := rhp.managedExecuteProgram(epr, data, budget, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Add a sector to the host.
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	if err := h.AddSector(sectorRoot, sectorData); err != nil {
+		t.Fatal(err)
+	}
+
+	// A successful program shouldn't be traced but a failed one should.
+	executeProgram(sectorRoot)
+	executeProgram(crypto.Hash{})
+	var trace modules.HostMDMTrace
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		traces := h.MDMTraces()
+		if len(traces) != 1 {
+			return fmt.Errorf("expected 1 trace but got %v", len(traces))
+		}
+		trace = traces[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.ClientType != modules.HostClientTypeAccount || !trace.ClientKey.Equals(rhp.staticAccountID.SPK()) {
+		t.Fatal("wrong client", trace.ClientType, trace.ClientKey)
+	}
+	if trace.Error == "" || len(trace.Instructions) != 2 {
+		t.Fatal("expected the second instruction to fail", trace)
+	}
+	if hs := trace.Instructions[0]; hs.Name != "HasSector" || hs.Error != "" || hs.Cost.IsZero() {
+		t.Fatal("unexpected HasSector trace", hs)
+	}
+	if rs := trace.Instructions[1]; rs.Name != "ReadSector" || rs.Error == "" {
+		t.Fatal("unexpected ReadSector trace", rs)
+	}
+
+	// Sample all programs. The successful program should be traced now.
+	settings.MDMTraceSampleRate = 1
+	if err := h.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	executeProgram(sectorRoot)
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		traces := h.MDMTraces()
+		if len(traces) != 2 {
+			return fmt.Errorf("expected 2 traces but got %v", len(traces))
+		}
+		trace = traces[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.Error != "" || len(trace.Instructions) != 2 {
+		t.Fatal("expected a successful trace", trace)
+	}
+	if rs := trace.Instructions[1]; rs.BytesRead != modules.SectorSize || rs.Error != "" {
+		t.Fatal("unexpected ReadSector trace", rs)
+	}
+	if trace.Cost.IsZero() || trace.ContractID != rhp.staticFCID {
+		t.Fatal("unexpected trace", trace)
+	}
+}
+
+// TestHostMDMTracesMalformedProgram checks that tracing a program whose
+// instruction references data outside of the program data doesn't crash the
+// host and results in a single trace for the failed instruction.
+func TestHostMDMTracesMalformedProgram(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rhp, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := rhp.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	h := rhp.staticHT.host
+
+	// Trace all programs.
+	settings := h.InternalSettings()
+	settings.MDMTraceSampleRate = 1
+	settings.MDMTraceFailures = true
+	if err := h.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fund an account.
+	pt := rhp.managedPriceTable()
+	_, err = rhp.managedFundEphemeralAccount(pt.FundAccountCost.Add(pt.InitBaseCost.Mul64(1000)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a DropSectors program and point the instruction's argument
+	// beyond the end of the program data.
+	pb := modules.NewProgramBuilder(pt, 0)
+	pb.AddDropSectorsInstruction(1, false)
+	program, data := pb.Program()
+	program[0] = modules.NewDropSectorsInstruction(uint64(len(data)), false)
+	programCost, _, _ := pb.Cost(true)
+	epr := modules.RPCExecuteProgramRequest{
+		FileContractID:    rhp.staticFCID,
+		Program:           program,
+		ProgramDataLength: uint64(len(data)),
+	}
+	budget := programCost.Add(pt.DownloadBandwidthCost.Add(pt.UploadBandwidthCost).Mul64(1 << 16))
+	resps, _, err := rhp.managedExecuteProgram(epr, data, budget, false, false)
+	if err == nil && (len(resps) == 0 || resps[len(resps)-1].Error == nil) {
+		t.Fatal("expected the program to fail")
+	}
+
+	// The host should have a trace with a single failed instruction.
+	var trace modules.HostMDMTrace
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		traces := h.MDMTraces()
+		if len(traces) != 1 {
+			return fmt.Errorf("expected 1 trace but got %v", len(traces))
+		}
+		trace = traces[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.Error == "" || len(trace.Instructions) != 1 {
+		t.Fatal("expected a single failed instruction", trace)
+	}
+	if ds := trace.Instructions[0]; ds.Name != "DropSectors" || ds.Error == "" {
+		t.Fatal("unexpected DropSectors trace", ds)
+	}
+}
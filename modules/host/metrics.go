@@ -0,0 +1,157 @@
+package host
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+type (
+	// latencyHistogram is a cumulative histogram of latencies using the
+	// buckets defined by metricsLatencyBuckets.
+	latencyHistogram struct {
+		buckets []uint64
+		count   uint64
+		sum     time.Duration
+	}
+
+	// rpcMetrics are the metrics of a single type of RPC.
+	rpcMetrics struct {
+		bytesIn  uint64
+		bytesOut uint64
+		calls    uint64
+		errors   uint64
+		latency  latencyHistogram
+	}
+
+	// instructionMetrics are the metrics of a single type of MDM instruction.
+	instructionMetrics struct {
+		errors     uint64
+		executions uint64
+		latency    latencyHistogram
+	}
+
+	// hostMetrics tracks the metrics of the RPCs handled by the host over the
+	// siamux and of the MDM instructions it executed.
+	hostMetrics struct {
+		instructions map[types.Specifier]*instructionMetrics
+		rpcs         map[types.Specifier]*rpcMetrics
+		mu           sync.Mutex
+	}
+)
+
+// newHostMetrics creates a new metrics tracker.
+func newHostMetrics() *hostMetrics {
+	return &hostMetrics{
+		instructions: make(map[types.Specifier]*instructionMetrics),
+		rpcs:         make(map[types.Specifier]*rpcMetrics),
+	}
+}
+
+// observe adds an observation to the histogram.
+func (lh *latencyHistogram) observe(d time.Duration) {
+	if lh.buckets == nil {
+		lh.buckets = make([]uint64, len(metricsLatencyBuckets))
+	}
+	for i, upperBound := range metricsLatencyBuckets {
+		if d <= upperBound {
+			lh.buckets[i]++
+		}
+	}
+	lh.count++
+	lh.sum += d
+}
+
+// histogram converts the histogram into its modules representation.
+func (lh *latencyHistogram) histogram() modules.HostLatencyHistogram {
+	buckets := make([]modules.HostLatencyBucket, len(metricsLatencyBuckets))
+	for i, upperBound := range metricsLatencyBuckets {
+		buckets[i].UpperBound = upperBound
+		if lh.buckets != nil {
+			buckets[i].Count = lh.buckets[i]
+		}
+	}
+	return modules.HostLatencyHistogram{
+		Buckets: buckets,
+		Count:   lh.count,
+		Sum:     lh.sum,
+	}
+}
+
+// managedRecordRPC records a handled RPC.
+func (hm *hostMetrics) managedRecordRPC(id types.Specifier, bytesIn, bytesOut uint64, latency time.Duration, err error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	m, exists := hm.rpcs[id]
+	if !exists {
+		m = new(rpcMetrics)
+		hm.rpcs[id] = m
+	}
+	m.bytesIn += bytesIn
+	m.bytesOut += bytesOut
+	m.calls++
+	if err != nil {
+		m.errors++
+	}
+	m.latency.observe(latency)
+}
+
+// managedRecordInstruction records an executed MDM instruction.
+func (hm *hostMetrics) managedRecordInstruction(id modules.InstructionSpecifier, latency time.Duration, err error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	m, exists := hm.instructions[types.Specifier(id)]
+	if !exists {
+		m = new(instructionMetrics)
+		hm.instructions[types.Specifier(id)] = m
+	}
+	m.executions++
+	if err != nil {
+		m.errors++
+	}
+	m.latency.observe(latency)
+}
+
+// managedMetrics returns the tracked metrics sorted by name.
+func (hm *hostMetrics) managedMetrics() modules.HostMetrics {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	metrics := modules.HostMetrics{
+		Instructions: make([]modules.HostInstructionMetrics, 0, len(hm.instructions)),
+		RPCs:         make([]modules.HostRPCMetrics, 0, len(hm.rpcs)),
+	}
+	for id, m := range hm.instructions {
+		metrics.Instructions = append(metrics.Instructions, modules.HostInstructionMetrics{
+			Name:       id.String(),
+			Errors:     m.errors,
+			Executions: m.executions,
+			Latency:    m.latency.histogram(),
+		})
+	}
+	for id, m := range hm.rpcs {
+		metrics.RPCs = append(metrics.RPCs, modules.HostRPCMetrics{
+			Name:     id.String(),
+			BytesIn:  m.bytesIn,
+			BytesOut: m.bytesOut,
+			Calls:    m.calls,
+			Errors:   m.errors,
+			Latency:  m.latency.histogram(),
+		})
+	}
+	sort.Slice(metrics.Instructions, func(i, j int) bool {
+		return metrics.Instructions[i].Name < metrics.Instructions[j].Name
+	})
+	sort.Slice(metrics.RPCs, func(i, j int) bool {
+		return metrics.RPCs[i].Name < metrics.RPCs[j].Name
+	})
+	return metrics
+}
+
+// Metrics returns the detailed metrics of the RPCs handled by the host over
+// the siamux and of the MDM instructions it executed since it was started.
+func (h *Host) Metrics() modules.HostMetrics {
+	return h.staticMetrics.managedMetrics()
+}
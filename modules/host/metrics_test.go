@@ -0,0 +1,139 @@
+package host
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestLatencyHistogram is a unit test for the latency histogram.
+func TestLatencyHistogram(t *testing.T) {
+	var lh latencyHistogram
+	if h := lh.histogram(); len(h.Buckets) != len(metricsLatencyBuckets) || h.Count != 0 {
+		t.Fatal("unexpected empty histogram", h)
+	}
+	lh.observe(0)
+	lh.observe(metricsLatencyBuckets[1])
+	lh.observe(metricsLatencyBuckets[len(metricsLatencyBuckets)-1] + 1)
+
+	h := lh.histogram()
+	if h.Count != 3 || h.Sum != metricsLatencyBuckets[1]+metricsLatencyBuckets[len(metricsLatencyBuckets)-1]+1 {
+		t.Fatal("wrong count or sum", h)
+	}
+	for i, b := range h.Buckets {
+		expected := uint64(2)
+		if i == 0 {
+			expected = 1
+		}
+		if b.UpperBound != metricsLatencyBuckets[i] || b.Count != expected {
+			t.Fatalf("bucket %v: expected %v observations <= %v, got %v <= %v", i, expected, metricsLatencyBuckets[i], b.Count, b.UpperBound)
+		}
+	}
+}
+
+// TestHostMetricsRecord is a unit test for recording RPC and instruction
+// metrics.
+func TestHostMetricsRecord(t *testing.T) {
+	hm := newHostMetrics()
+	hm.managedRecordRPC(modules.RPCUpdatePriceTable, 10, 20, time.Millisecond, nil)
+	hm.managedRecordRPC(modules.RPCUpdatePriceTable, 1, 2, time.Second, errors.New("failed"))
+	hm.managedRecordRPC(modules.RPCAccountBalance, 3, 4, time.Millisecond, nil)
+	hm.managedRecordInstruction(modules.SpecifierReadSector, time.Millisecond, nil)
+
+	m := hm.managedMetrics()
+	if len(m.RPCs) != 2 || len(m.Instructions) != 1 {
+		t.Fatal("wrong number of metrics", m)
+	}
+	// The RPCs are sorted by name.
+	if m.RPCs[0].Name != "AccountBalance" || m.RPCs[1].Name != "UpdatePriceTable" {
+		t.Fatal("wrong order", m.RPCs)
+	}
+	rpc := m.RPCs[1]
+	if rpc.Calls != 2 || rpc.Errors != 1 || rpc.BytesIn != 11 || rpc.BytesOut != 22 || rpc.Latency.Count != 2 {
+		t.Fatal("wrong rpc metrics", rpc)
+	}
+	instruction := m.Instructions[0]
+	if instruction.Name != "ReadSector" || instruction.Executions != 1 || instruction.Errors != 0 {
+		t.Fatal("wrong instruction metrics", instruction)
+	}
+}
+
+// TestHostMetrics checks that the host records the metrics of the RPCs it
+// handles and of the instructions it executes.
+func TestHostMetrics(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rhp, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := rhp.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	h := rhp.staticHT.host
+
+	// Fund an account and execute a 'HasSector' program.
+	pt := rhp.managedPriceTable()
+	_, err = rhp.managedFundEphemeralAccount(pt.FundAccountCost.Add(pt.InitBaseCost.Mul64(100)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb := modules.NewProgramBuilder(pt, 0)
+	pb.AddHasSectorInstruction(crypto.Hash{})
+	program, data := pb.Program()
+	programCost, _, _ := pb.Cost(true)
+	epr := modules.RPCExecuteProgramRequest{
+		FileContractID:    rhp.staticFCID,
+		Program:           program,
+		ProgramDataLength: uint64(len(data)),
+	}
+	budget := programCost.Add(pt.DownloadBandwidthCost.Add(pt.UploadBandwidthCost).Mul64(1 << 14))
+	_, _, err = rhp.managedExecuteProgram(epr, data, budget, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The RPCs are recorded after the stream was handled.
+	var execute, fund modules.HostRPCMetrics
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		m := h.Metrics()
+		for _, rpc := range m.RPCs {
+			switch rpc.Name {
+			case modules.RPCExecuteProgram.String():
+				execute = rpc
+			case modules.RPCFundAccount.String():
+				fund = rpc
+			}
+		}
+		if execute.Calls != 1 || fund.Calls != 1 {
+			return fmt.Errorf("expected 1 call of each RPC, got %v and %v", execute.Calls, fund.Calls)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if execute.Errors != 0 || execute.BytesIn == 0 || execute.BytesOut == 0 || execute.Latency.Count != 1 {
+		t.Fatal("unexpected metrics", execute)
+	}
+
+	// The instruction should be recorded as well.
+	m := h.Metrics()
+	if len(m.Instructions) != 1 {
+		t.Fatal("expected a single instruction", m.Instructions)
+	}
+	if hs := m.Instructions[0]; hs.Name != "HasSector" || hs.Executions != 1 || hs.Errors != 0 || hs.Latency.Count != 1 {
+		t.Fatal("unexpected instruction metrics", hs)
+	}
+}
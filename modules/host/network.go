@@ -362,6 +362,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		l := stream.Limit()
 		atomic.AddUint64(&h.atomicStreamUpload, l.Uploaded())
 		atomic.AddUint64(&h.atomicStreamDownload, l.Downloaded())
+		h.staticClients.managedDetachStream(stream, l.Uploaded(), l.Downloaded())
 
 		// Call rpc specific cleanup if necessary.
 		if cleanup != nil {
@@ -406,11 +407,17 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		return
 	}
 
+	start := time.Now()
+	knownRPC := true
 	switch rpcID {
 	case modules.RPCAccountBalance:
 		err = h.managedRPCAccountBalance(stream)
 	case modules.RPCExecuteProgram:
-		err = h.managedRPCExecuteProgram(stream)
+		err = h.managedRPCExecuteProgram(stream, false)
+	case modules.RPCExecuteProgramStream:
+		err = h.managedRPCExecuteProgram(stream, true)
+	case modules.RPCDryRunProgram:
+		err = h.managedRPCDryRunProgram(stream)
 	case modules.RPCUpdatePriceTable:
 		err = h.managedRPCUpdatePriceTable(stream)
 	case modules.RPCFundAccount:
@@ -425,6 +432,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		h.log.Debugf("WARN: incoming stream %v requested unknown RPC \"%v\"", stream.RemoteAddr().String(), rpcID)
 		err = errors.New(fmt.Sprintf("Unrecognized RPC id %v", rpcID))
 		atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
+		knownRPC = false
 	}
 
 	if err != nil {
@@ -432,6 +440,13 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 		atomic.AddUint64(&h.atomicErroredCalls, 1)
 		h.managedLogError(err)
 	}
+
+	// Record the metrics of the RPC. Unrecognized RPCs are only counted by the
+	// network metrics to avoid tracking arbitrary specifiers.
+	if knownRPC {
+		l := stream.Limit()
+		h.staticMetrics.managedRecordRPC(rpcID, l.Downloaded(), l.Uploaded(), time.Since(start), err)
+	}
 }
 
 // threadedListen listens for incoming RPCs and spawns an appropriate handler for each.
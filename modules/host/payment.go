@@ -43,6 +43,12 @@ func (h *Host) staticPayByEphemeralAccount(stream siamux.Stream, bh types.BlockH
 		return nil, errors.AddContext(err, "Could not read PayByEphemeralAccountRequest")
 	}
 
+	// attach the account to the stream, this enforces the client's quotas
+	// before any money is withdrawn.
+	if err := h.managedAttachClient(stream, accountClient(req.Message.Account)); err != nil {
+		return nil, err
+	}
+
 	// process the request
 	if err := h.staticAccountManager.callWithdraw(&req.Message, req.Signature, req.Priority, bh); err != nil {
 		return nil, errors.AddContext(err, "Withdraw failed")
@@ -93,6 +99,12 @@ func (h *Host) managedPayByContract(stream siamux.Stream, bh types.BlockHeight)
 	if err != nil {
 		return nil, errors.AddContext(err, "Could not find the most recent revision")
 	}
+
+	// attach the renter to the stream, this enforces the client's quotas
+	// before the payment is accepted.
+	if err := h.managedAttachClient(stream, renterClient(currentRevision)); err != nil {
+		return nil, err
+	}
 	paymentRevision := revisionFromRequest(currentRevision, pbcr)
 
 	// verify the payment revision
@@ -170,6 +182,12 @@ func (h *Host) managedFundAccount(stream siamux.Stream, request modules.FundAcco
 	if err != nil {
 		return types.ZeroCurrency, errors.AddContext(err, "Could not get the latest revision")
 	}
+
+	// attach the renter to the stream, this enforces the client's quotas
+	// before the payment is accepted.
+	if err := h.managedAttachClient(stream, renterClient(currentRevision)); err != nil {
+		return types.ZeroCurrency, err
+	}
 	paymentRevision := revisionFromRequest(currentRevision, pbcr)
 
 	// verify the payment revision
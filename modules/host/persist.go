@@ -30,6 +30,13 @@ type persistence struct {
 	SecretKey        crypto.SecretKey             `json:"secretkey"`
 	Settings         modules.HostInternalSettings `json:"settings"`
 	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+
+	// Pricing Engine.
+	PriceChanges  []modules.HostPriceChange `json:"pricechanges"`
+	PricingPolicy modules.HostPricingPolicy `json:"pricingpolicy"`
+
+	// Decommissioning.
+	Decommission modules.HostDecommissionStatus `json:"decommission"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -48,6 +55,13 @@ func (h *Host) persistData() persistence {
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
 		UnlockHash:       h.unlockHash,
+
+		// Pricing Engine.
+		PriceChanges:  h.priceChanges,
+		PricingPolicy: h.pricingPolicy,
+
+		// Decommissioning.
+		Decommission: h.decommission,
 	}
 }
 
@@ -75,6 +89,8 @@ func (h *Host) establishDefaults() error {
 		EphemeralAccountExpiry:     modules.DefaultEphemeralAccountExpiry,
 		MaxEphemeralAccountBalance: modules.DefaultMaxEphemeralAccountBalance,
 		MaxEphemeralAccountRisk:    defaultMaxEphemeralAccountRisk,
+
+		SectorScrubRate: defaultSectorScrubRate,
 	}
 
 	// Load the host's key pair, use the same keys as the SiaMux.
@@ -121,6 +137,13 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.settings.NetAddress = ""
 	}
 	h.unlockHash = p.UnlockHash
+
+	// Copy over the pricing engine.
+	h.priceChanges = p.PriceChanges
+	h.pricingPolicy = p.PricingPolicy
+
+	// Copy over the decommission status.
+	h.decommission = p.Decommission
 }
 
 // initDB will check that the database has been initialized and if not, will
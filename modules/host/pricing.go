@@ -0,0 +1,347 @@
+package host
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errPricingFloorAboveCeiling is returned if a pricing policy has a floor
+	// which is above the corresponding ceiling.
+	errPricingFloorAboveCeiling = errors.New("price floor must not be above the price ceiling")
+
+	// errPricingNoExchangeRate is returned if a pricing policy has fiat
+	// targets but no exchange rate to convert them.
+	errPricingNoExchangeRate = errors.New("an exchange rate is required to use fiat targets")
+)
+
+type (
+	// pricingInputs contains the inputs of the pricing engine besides the
+	// pricing policy.
+	pricingInputs struct {
+		// The utilisation of the host's storage and bandwidth between 0 and 1.
+		// The download bandwidth utilisation refers to the data sent to
+		// renters and the upload bandwidth utilisation to the data received
+		// from renters.
+		storageUtilisation           float64
+		downloadBandwidthUtilisation float64
+		uploadBandwidthUtilisation   float64
+
+		// The median prices of the hosts observed on the network, or zero if
+		// they are unknown.
+		marketStoragePrice           types.Currency
+		marketDownloadBandwidthPrice types.Currency
+		marketUploadBandwidthPrice   types.Currency
+	}
+
+	// pricingBandwidth is a snapshot of the host's bandwidth counters, used
+	// to compute the bandwidth utilisation between two price updates.
+	pricingBandwidth struct {
+		download  uint64
+		upload    uint64
+		timestamp time.Time
+	}
+)
+
+// utilisationMultiplier returns the factor that is applied to a base price at
+// the provided utilisation.
+func utilisationMultiplier(utilisation float64) float64 {
+	if utilisation < 0 {
+		utilisation = 0
+	} else if utilisation > 1 {
+		utilisation = 1
+	}
+	return pricingMinUtilisationMultiplier + (pricingMaxUtilisationMultiplier-pricingMinUtilisationMultiplier)*utilisation
+}
+
+// dynamicPrice computes a single price. The base price is the target, the
+// market price or the average of both. If neither is known, the floor is used
+// instead. The base is multiplied with the multiplier and clamped to the floor
+// and ceiling. If the price can't be determined, the current price is
+// returned.
+func dynamicPrice(target, market, floor, ceiling, current types.Currency, multiplier float64) types.Currency {
+	var base types.Currency
+	switch {
+	case !target.IsZero() && !market.IsZero():
+		base = target.Add(market).Div64(2)
+	case !target.IsZero():
+		base = target
+	case !market.IsZero():
+		base = market
+	case !floor.IsZero():
+		base = floor
+	default:
+		return current
+	}
+	price := base.MulFloat(multiplier)
+	if price.Cmp(floor) < 0 {
+		price = floor
+	}
+	if !ceiling.IsZero() && price.Cmp(ceiling) > 0 {
+		price = ceiling
+	}
+	return price
+}
+
+// computePrices returns the storage, download bandwidth and upload bandwidth
+// prices for the provided policy and inputs. The current prices are kept if a
+// price can't be determined.
+func computePrices(policy modules.HostPricingPolicy, in pricingInputs, current modules.HostInternalSettings) (storage, download, upload types.Currency) {
+	// Convert the fiat targets to hastings per byte (per block).
+	var targetStorage, targetDownload, targetUpload types.Currency
+	rate, err := types.ParseExchangeRate(policy.ExchangeRate)
+	if err == nil && rate != nil {
+		targetStorage = rate.Invert(policy.TargetStoragePrice).Div(modules.BlockBytesPerMonthTerabyte)
+		targetDownload = rate.Invert(policy.TargetDownloadBandwidthPrice).Div(modules.BytesPerTerabyte)
+		targetUpload = rate.Invert(policy.TargetUploadBandwidthPrice).Div(modules.BytesPerTerabyte)
+	}
+
+	// The bandwidth utilisation is only taken into account if the host's
+	// bandwidth limit is known.
+	downloadMultiplier, uploadMultiplier := 1.0, 1.0
+	if policy.MaxBandwidth > 0 {
+		downloadMultiplier = utilisationMultiplier(in.downloadBandwidthUtilisation)
+		uploadMultiplier = utilisationMultiplier(in.uploadBandwidthUtilisation)
+	}
+
+	storage = dynamicPrice(targetStorage, in.marketStoragePrice, policy.StoragePriceFloor, policy.StoragePriceCeiling, current.MinStoragePrice, utilisationMultiplier(in.storageUtilisation))
+	download = dynamicPrice(targetDownload, in.marketDownloadBandwidthPrice, policy.DownloadBandwidthPriceFloor, policy.DownloadBandwidthPriceCeiling, current.MinDownloadBandwidthPrice, downloadMultiplier)
+	upload = dynamicPrice(targetUpload, in.marketUploadBandwidthPrice, policy.UploadBandwidthPriceFloor, policy.UploadBandwidthPriceCeiling, current.MinUploadBandwidthPrice, uploadMultiplier)
+	return
+}
+
+// marketPrices returns the median storage, download bandwidth and upload
+// bandwidth prices of the provided hosts, ignoring the host with the provided
+// public key.
+func marketPrices(hosts []modules.HostDBEntry, self types.SiaPublicKey) (storage, download, upload types.Currency) {
+	var storagePrices, downloadPrices, uploadPrices []types.Currency
+	for _, entry := range hosts {
+		if entry.PublicKey.Equals(self) {
+			continue
+		}
+		storagePrices = append(storagePrices, entry.StoragePrice)
+		downloadPrices = append(downloadPrices, entry.DownloadBandwidthPrice)
+		uploadPrices = append(uploadPrices, entry.UploadBandwidthPrice)
+	}
+	return medianPrice(storagePrices), medianPrice(downloadPrices), medianPrice(uploadPrices)
+}
+
+// medianPrice returns the median of the provided prices or zero if there are
+// none.
+func medianPrice(prices []types.Currency) types.Currency {
+	if len(prices) == 0 {
+		return types.ZeroCurrency
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	if len(prices)%2 == 0 {
+		return prices[len(prices)/2-1].Add(prices[len(prices)/2]).Div64(2)
+	}
+	return prices[len(prices)/2]
+}
+
+// validatePricingPolicy checks that a pricing policy is consistent.
+func validatePricingPolicy(policy modules.HostPricingPolicy) error {
+	rate, err := types.ParseExchangeRate(policy.ExchangeRate)
+	if err != nil {
+		return errors.AddContext(err, "invalid exchange rate")
+	}
+	if rate == nil && (policy.TargetStoragePrice > 0 || policy.TargetDownloadBandwidthPrice > 0 || policy.TargetUploadBandwidthPrice > 0) {
+		return errPricingNoExchangeRate
+	}
+	limits := []struct {
+		name           string
+		floor, ceiling types.Currency
+	}{
+		{"storage", policy.StoragePriceFloor, policy.StoragePriceCeiling},
+		{"download bandwidth", policy.DownloadBandwidthPriceFloor, policy.DownloadBandwidthPriceCeiling},
+		{"upload bandwidth", policy.UploadBandwidthPriceFloor, policy.UploadBandwidthPriceCeiling},
+	}
+	for _, l := range limits {
+		if !l.ceiling.IsZero() && l.floor.Cmp(l.ceiling) > 0 {
+			return errors.AddContext(errPricingFloorAboveCeiling, l.name)
+		}
+	}
+	return nil
+}
+
+// threadedUpdatePrices runs the pricing engine in the background.
+func (h *Host) threadedUpdatePrices() {
+	if err := h.tg.Add(); err != nil {
+		return
+	}
+	defer h.tg.Done()
+	h.managedUpdatePrices()
+}
+
+// managedUpdatePrices recomputes the host's prices according to the pricing
+// policy, updates the internal settings and the price table, and records the
+// change.
+func (h *Host) managedUpdatePrices() {
+	h.mu.RLock()
+	policy := h.pricingPolicy
+	market := h.pricingMarket
+	self := h.publicKey
+	h.mu.RUnlock()
+	if !policy.Enabled {
+		return
+	}
+
+	// Determine the storage utilisation.
+	var in pricingInputs
+	var capacity, remaining uint64
+	for _, sf := range h.StorageFolders() {
+		capacity += sf.Capacity
+		remaining += sf.CapacityRemaining
+	}
+	if capacity > 0 {
+		in.storageUtilisation = float64(capacity-remaining) / float64(capacity)
+	}
+
+	// Determine the bandwidth utilisation since the last update.
+	upload, download, _, err := h.BandwidthCounters()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	h.mu.Lock()
+	last := h.pricingBandwidth
+	h.pricingBandwidth = pricingBandwidth{
+		download:  download,
+		upload:    upload,
+		timestamp: now,
+	}
+	h.mu.Unlock()
+	elapsed := now.Sub(last.timestamp).Seconds()
+	if policy.MaxBandwidth > 0 && !last.timestamp.IsZero() && elapsed > 0 && upload >= last.upload && download >= last.download {
+		// The host uploads the data that renters download and vice versa.
+		in.downloadBandwidthUtilisation = float64(upload-last.upload) / elapsed / float64(policy.MaxBandwidth)
+		in.uploadBandwidthUtilisation = float64(download-last.download) / elapsed / float64(policy.MaxBandwidth)
+	}
+
+	// Determine the market prices.
+	if market != nil {
+		hosts, err := market.ActiveHosts()
+		if err != nil {
+			h.log.Println("WARN: pricing engine unable to fetch market prices:", err)
+		} else {
+			in.marketStoragePrice, in.marketDownloadBandwidthPrice, in.marketUploadBandwidthPrice = marketPrices(hosts, self)
+		}
+	}
+
+	// Compute the new prices and apply them if they changed.
+	h.mu.Lock()
+	if !h.pricingPolicy.Enabled {
+		h.mu.Unlock()
+		return
+	}
+	storagePrice, downloadPrice, uploadPrice := computePrices(h.pricingPolicy, in, h.settings)
+	old := h.settings
+	if storagePrice.Equals(old.MinStoragePrice) && downloadPrice.Equals(old.MinDownloadBandwidthPrice) && uploadPrice.Equals(old.MinUploadBandwidthPrice) {
+		h.mu.Unlock()
+		return
+	}
+	h.settings.MinStoragePrice = storagePrice
+	h.settings.MinDownloadBandwidthPrice = downloadPrice
+	h.settings.MinUploadBandwidthPrice = uploadPrice
+
+	// The RPC and sector access prices are limited by the download bandwidth
+	// price.
+	if h.settings.MinBaseRPCPrice.Cmp(h.settings.MaxBaseRPCPrice()) > 0 {
+		h.settings.MinBaseRPCPrice = h.settings.MaxBaseRPCPrice()
+	}
+	if h.settings.MinSectorAccessPrice.Cmp(h.settings.MaxSectorAccessPrice()) > 0 {
+		h.settings.MinSectorAccessPrice = h.settings.MaxSectorAccessPrice()
+	}
+	h.revisionNumber++
+
+	h.priceChanges = append(h.priceChanges, modules.HostPriceChange{
+		BlockHeight: h.blockHeight,
+		Timestamp:   now,
+
+		StoragePrice:           storagePrice,
+		DownloadBandwidthPrice: downloadPrice,
+		UploadBandwidthPrice:   uploadPrice,
+
+		StorageUtilisation:           in.storageUtilisation,
+		DownloadBandwidthUtilisation: in.downloadBandwidthUtilisation,
+		UploadBandwidthUtilisation:   in.uploadBandwidthUtilisation,
+	})
+	if len(h.priceChanges) > maxPriceChanges {
+		h.priceChanges = h.priceChanges[len(h.priceChanges)-maxPriceChanges:]
+	}
+	h.log.Printf("Pricing engine updated prices at height %v: storage %v -> %v, download %v -> %v, upload %v -> %v (storage utilisation %.2f, download utilisation %.2f, upload utilisation %.2f)",
+		h.blockHeight,
+		pricePerTBMonth(old.MinStoragePrice), pricePerTBMonth(storagePrice),
+		pricePerTB(old.MinDownloadBandwidthPrice), pricePerTB(downloadPrice),
+		pricePerTB(old.MinUploadBandwidthPrice), pricePerTB(uploadPrice),
+		in.storageUtilisation, in.downloadBandwidthUtilisation, in.uploadBandwidthUtilisation)
+	err = h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		h.log.Println("ERROR: unable to save host after updating prices:", err)
+	}
+	h.managedUpdatePriceTable()
+}
+
+// pricePerTBMonth formats a storage price for logging.
+func pricePerTBMonth(price types.Currency) string {
+	return fmt.Sprintf("%v/TB/month", price.Mul(modules.BlockBytesPerMonthTerabyte).HumanString())
+}
+
+// pricePerTB formats a bandwidth price for logging.
+func pricePerTB(price types.Currency) string {
+	return fmt.Sprintf("%v/TB", price.Mul(modules.BytesPerTerabyte).HumanString())
+}
+
+// PriceChanges returns the most recent price changes made by the pricing
+// engine, oldest first.
+func (h *Host) PriceChanges() []modules.HostPriceChange {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]modules.HostPriceChange(nil), h.priceChanges...)
+}
+
+// PricingPolicy returns the policy of the host's pricing engine.
+func (h *Host) PricingPolicy() modules.HostPricingPolicy {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.pricingPolicy
+}
+
+// SetPricingMarket sets the source of the market prices used by the pricing
+// engine.
+func (h *Host) SetPricingMarket(market modules.HostPricingMarket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pricingMarket = market
+}
+
+// SetPricingPolicy sets the policy of the host's pricing engine. If the policy
+// is enabled, the host's prices are recomputed immediately.
+func (h *Host) SetPricingPolicy(policy modules.HostPricingPolicy) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	if err := validatePricingPolicy(policy); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.pricingPolicy = policy
+	err := h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "pricing policy updated, but failed saving to disk")
+	}
+	h.managedUpdatePrices()
+	return nil
+}
@@ -0,0 +1,279 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// mockPricingMarket is a pricing market which returns a fixed set of hosts.
+type mockPricingMarket struct {
+	hosts []modules.HostDBEntry
+}
+
+// ActiveHosts implements modules.HostPricingMarket.
+func (m *mockPricingMarket) ActiveHosts() ([]modules.HostDBEntry, error) {
+	return m.hosts, nil
+}
+
+// marketHost returns a hostdb entry with the provided prices.
+func marketHost(storage, download, upload uint64) modules.HostDBEntry {
+	var entry modules.HostDBEntry
+	entry.StoragePrice = types.NewCurrency64(storage)
+	entry.DownloadBandwidthPrice = types.NewCurrency64(download)
+	entry.UploadBandwidthPrice = types.NewCurrency64(upload)
+	return entry
+}
+
+// TestDynamicPrice is a unit test for dynamicPrice.
+func TestDynamicPrice(t *testing.T) {
+	c := types.NewCurrency64
+	tests := []struct {
+		target, market, floor, ceiling, current uint64
+		multiplier                              float64
+		expected                                uint64
+	}{
+		// The base is the average of the target and the market price.
+		{100, 200, 0, 0, 1, 1, 150},
+		{100, 0, 0, 0, 1, 1, 100},
+		{0, 200, 0, 0, 1, 1, 200},
+		// The floor is used if neither is known.
+		{0, 0, 50, 0, 1, 1.5, 75},
+		// The current price is kept if nothing is known.
+		{0, 0, 0, 0, 42, 1.5, 42},
+		// The multiplier is applied before clamping.
+		{100, 0, 0, 0, 1, 0.75, 75},
+		{100, 0, 90, 0, 1, 0.75, 90},
+		{100, 0, 0, 120, 1, 1.5, 120},
+		// A zero ceiling means no ceiling.
+		{100, 0, 0, 0, 1, 1.5, 150},
+	}
+	for i, test := range tests {
+		price := dynamicPrice(c(test.target), c(test.market), c(test.floor), c(test.ceiling), c(test.current), test.multiplier)
+		if !price.Equals64(test.expected) {
+			t.Errorf("%v: expected %v, got %v", i, test.expected, price)
+		}
+	}
+}
+
+// TestUtilisationMultiplier is a unit test for utilisationMultiplier.
+func TestUtilisationMultiplier(t *testing.T) {
+	tests := []struct {
+		utilisation, expected float64
+	}{
+		{-1, pricingMinUtilisationMultiplier},
+		{0, pricingMinUtilisationMultiplier},
+		{0.5, (pricingMinUtilisationMultiplier + pricingMaxUtilisationMultiplier) / 2},
+		{1, pricingMaxUtilisationMultiplier},
+		{2, pricingMaxUtilisationMultiplier},
+	}
+	for _, test := range tests {
+		if m := utilisationMultiplier(test.utilisation); m != test.expected {
+			t.Errorf("utilisation %v: expected %v, got %v", test.utilisation, test.expected, m)
+		}
+	}
+}
+
+// TestMarketPrices is a unit test for marketPrices and medianPrice.
+func TestMarketPrices(t *testing.T) {
+	if !medianPrice(nil).IsZero() {
+		t.Fatal("median of no prices should be zero")
+	}
+
+	self := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	hosts := []modules.HostDBEntry{
+		marketHost(30, 300, 3000),
+		marketHost(10, 100, 1000),
+		marketHost(20, 200, 2000),
+	}
+	storage, download, upload := marketPrices(hosts, self)
+	if !storage.Equals64(20) || !download.Equals64(200) || !upload.Equals64(2000) {
+		t.Fatal("wrong median prices", storage, download, upload)
+	}
+
+	// The host itself is ignored and an even number of prices is averaged.
+	selfEntry := marketHost(1000, 1000, 1000)
+	selfEntry.PublicKey = self
+	hosts = append(hosts, marketHost(40, 400, 4000), selfEntry)
+	storage, download, upload = marketPrices(hosts, self)
+	if !storage.Equals64(25) || !download.Equals64(250) || !upload.Equals64(2500) {
+		t.Fatal("wrong median prices", storage, download, upload)
+	}
+}
+
+// TestComputePrices is a unit test for computePrices.
+func TestComputePrices(t *testing.T) {
+	current := modules.HostInternalSettings{
+		MinStoragePrice:           types.NewCurrency64(1),
+		MinDownloadBandwidthPrice: types.NewCurrency64(2),
+		MinUploadBandwidthPrice:   types.NewCurrency64(3),
+	}
+
+	// Without a target, a market or a floor the prices don't change.
+	storage, download, upload := computePrices(modules.HostPricingPolicy{Enabled: true}, pricingInputs{}, current)
+	if !storage.Equals(current.MinStoragePrice) || !download.Equals(current.MinDownloadBandwidthPrice) || !upload.Equals(current.MinUploadBandwidthPrice) {
+		t.Fatal("prices shouldn't have changed", storage, download, upload)
+	}
+
+	// Fiat targets are converted with the exchange rate. At 0.01 USD/SC a
+	// target of 2 USD/TB/month is 200 SC/TB/month.
+	policy := modules.HostPricingPolicy{
+		Enabled:                      true,
+		ExchangeRate:                 "0.01 USD",
+		TargetStoragePrice:           2,
+		TargetDownloadBandwidthPrice: 1,
+		TargetUploadBandwidthPrice:   0.5,
+	}
+	in := pricingInputs{storageUtilisation: 0.5}
+	storage, download, upload = computePrices(policy, in, current)
+	multiplier := utilisationMultiplier(0.5)
+	expectedStorage := types.SiacoinPrecision.Mul64(200).Div(modules.BlockBytesPerMonthTerabyte).MulFloat(multiplier)
+	expectedDownload := types.SiacoinPrecision.Mul64(100).Div(modules.BytesPerTerabyte)
+	expectedUpload := types.SiacoinPrecision.Mul64(50).Div(modules.BytesPerTerabyte)
+	if !storage.Equals(expectedStorage) || !download.Equals(expectedDownload) || !upload.Equals(expectedUpload) {
+		t.Fatal("wrong prices", storage, download, upload)
+	}
+
+	// The bandwidth utilisation is only taken into account if the maximum
+	// bandwidth is set.
+	in.downloadBandwidthUtilisation = 1
+	in.uploadBandwidthUtilisation = 1
+	_, download, upload = computePrices(policy, in, current)
+	if !download.Equals(expectedDownload) || !upload.Equals(expectedUpload) {
+		t.Fatal("bandwidth utilisation shouldn't affect prices", download, upload)
+	}
+	policy.MaxBandwidth = 1e6
+	_, download, upload = computePrices(policy, in, current)
+	if !download.Equals(expectedDownload.MulFloat(pricingMaxUtilisationMultiplier)) || !upload.Equals(expectedUpload.MulFloat(pricingMaxUtilisationMultiplier)) {
+		t.Fatal("wrong bandwidth prices", download, upload)
+	}
+
+	// The ceiling limits the price.
+	policy.StoragePriceCeiling = types.NewCurrency64(10)
+	storage, _, _ = computePrices(policy, in, current)
+	if !storage.Equals(policy.StoragePriceCeiling) {
+		t.Fatal("storage price should be at the ceiling", storage)
+	}
+}
+
+// TestValidatePricingPolicy is a unit test for validatePricingPolicy.
+func TestValidatePricingPolicy(t *testing.T) {
+	if err := validatePricingPolicy(modules.HostPricingPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validatePricingPolicy(modules.HostPricingPolicy{ExchangeRate: "foo"}); err == nil {
+		t.Fatal("expected invalid exchange rate to fail")
+	}
+	if err := validatePricingPolicy(modules.HostPricingPolicy{TargetStoragePrice: 1}); !errors.Contains(err, errPricingNoExchangeRate) {
+		t.Fatal("expected errPricingNoExchangeRate, got", err)
+	}
+	policy := modules.HostPricingPolicy{
+		UploadBandwidthPriceFloor:   types.NewCurrency64(2),
+		UploadBandwidthPriceCeiling: types.NewCurrency64(1),
+	}
+	if err := validatePricingPolicy(policy); !errors.Contains(err, errPricingFloorAboveCeiling) {
+		t.Fatal("expected errPricingFloorAboveCeiling, got", err)
+	}
+	policy.UploadBandwidthPriceCeiling = types.ZeroCurrency
+	if err := validatePricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHostPricingEngine checks that the host's pricing engine updates the
+// host's prices and price table when the policy is set and on consensus
+// changes.
+func TestHostPricingEngine(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Set a market and enable the pricing engine. The storage price should
+	// follow the market and be clamped to the floor and ceiling.
+	market := &mockPricingMarket{
+		hosts: []modules.HostDBEntry{marketHost(1e9, 1e12, 1e10)},
+	}
+	ht.host.SetPricingMarket(market)
+	policy := modules.HostPricingPolicy{
+		Enabled:                       true,
+		DownloadBandwidthPriceCeiling: types.NewCurrency64(5e11),
+		UploadBandwidthPriceFloor:     types.NewCurrency64(2e10),
+	}
+	if err := ht.host.SetPricingPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	is := ht.host.InternalSettings()
+	expectedStorage := types.NewCurrency64(1e9).MulFloat(pricingMinUtilisationMultiplier)
+	if !is.MinStoragePrice.Equals(expectedStorage) {
+		t.Fatal("wrong storage price", is.MinStoragePrice, expectedStorage)
+	}
+	if !is.MinDownloadBandwidthPrice.Equals(policy.DownloadBandwidthPriceCeiling) {
+		t.Fatal("download price should be at the ceiling", is.MinDownloadBandwidthPrice)
+	}
+	if !is.MinUploadBandwidthPrice.Equals(policy.UploadBandwidthPriceFloor) {
+		t.Fatal("upload price should be at the floor", is.MinUploadBandwidthPrice)
+	}
+	pt := ht.host.PriceTable()
+	if !pt.DownloadBandwidthCost.Equals(is.MinDownloadBandwidthPrice) || !pt.UploadBandwidthCost.Equals(is.MinUploadBandwidthPrice) {
+		t.Fatal("price table wasn't updated", pt.DownloadBandwidthCost, pt.UploadBandwidthCost)
+	}
+	if changes := ht.host.PriceChanges(); len(changes) != 1 || !changes[0].StoragePrice.Equals(expectedStorage) {
+		t.Fatal("price change wasn't recorded", changes)
+	}
+
+	// Change the market. The prices should be updated on the next block.
+	market.hosts = []modules.HostDBEntry{marketHost(2e9, 1e12, 1e10)}
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	expectedStorage = types.NewCurrency64(2e9).MulFloat(pricingMinUtilisationMultiplier)
+	var changes []modules.HostPriceChange
+	for i := 0; i < 100; i++ {
+		changes = ht.host.PriceChanges()
+		if len(changes) == 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(changes) != 2 || !changes[1].StoragePrice.Equals(expectedStorage) {
+		t.Fatal("price change wasn't recorded", changes)
+	}
+	if is := ht.host.InternalSettings(); !is.MinStoragePrice.Equals(expectedStorage) {
+		t.Fatal("wrong storage price", is.MinStoragePrice, expectedStorage)
+	}
+
+	// An invalid policy is rejected.
+	policy.StoragePriceFloor = types.NewCurrency64(2)
+	policy.StoragePriceCeiling = types.NewCurrency64(1)
+	if err := ht.host.SetPricingPolicy(policy); !errors.Contains(err, errPricingFloorAboveCeiling) {
+		t.Fatal("expected errPricingFloorAboveCeiling, got", err)
+	}
+
+	// The policy and the price changes are persisted.
+	if err := ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopenHost(ht); err != nil {
+		t.Fatal(err)
+	}
+	if p := ht.host.PricingPolicy(); !p.Enabled || !p.UploadBandwidthPriceFloor.Equals(types.NewCurrency64(2e10)) {
+		t.Fatal("pricing policy wasn't persisted", p)
+	}
+	if changes := ht.host.PriceChanges(); len(changes) != 2 {
+		t.Fatal("price changes weren't persisted", changes)
+	}
+}
@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// exportHeader is the specifier at the beginning of an exported registry.
+var exportHeader = types.NewSpecifier("RegistryExport")
+
+var (
+	// errInvalidExport is returned when importing a file that is not an
+	// exported registry.
+	errInvalidExport = errors.New("file is not an exported registry")
+)
+
+// Export writes all entries of the registry to w. The export starts with a
+// metadata page containing the export header and the registry version,
+// followed by the entries in the same format they are persisted in. Unlike the
+// registry file itself, the export only contains entries that are in use which
+// makes it independent of the size of the registry.
+func (r *Registry) Export(w io.Writer) (uint64, error) {
+	// Get a slice of entries. We only hold the lock during the map access.
+	r.mu.Lock()
+	entries := make([]*value, 0, len(r.entries))
+	for _, v := range r.entries {
+		entries = append(entries, v)
+	}
+	r.mu.Unlock()
+
+	// Sort the entries by index to make the export deterministic.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].staticIndex < entries[j].staticIndex
+	})
+
+	// Write the metadata.
+	bw := bufio.NewWriter(w)
+	metadata := make([]byte, PersistedEntrySize)
+	copy(metadata, exportHeader[:])
+	copy(metadata[types.SpecifierLen:], registryVersion[:])
+	if _, err := bw.Write(metadata); err != nil {
+		return 0, errors.AddContext(err, "Export: failed to write metadata")
+	}
+
+	// Write the entries.
+	var exported uint64
+	for _, entry := range entries {
+		entry.mu.Lock()
+		if entry.invalid {
+			entry.mu.Unlock()
+			continue // already deleted
+		}
+		pe, err := newPersistedEntry(entry)
+		entry.mu.Unlock()
+		if err != nil {
+			return exported, errors.AddContext(err, "Export: failed to get persistedEntry from value")
+		}
+		pe.Type = persistedEntryType
+		b, err := pe.Marshal()
+		if err != nil {
+			return exported, errors.AddContext(err, "Export: failed to marshal persistedEntry")
+		}
+		if _, err := bw.Write(b); err != nil {
+			return exported, errors.AddContext(err, "Export: failed to write entry")
+		}
+		exported++
+	}
+	return exported, errors.AddContext(bw.Flush(), "Export: failed to flush entries")
+}
+
+// Import adds the entries of an export created by Export to the registry.
+// Entries which expire at a height smaller than or equal to 'expiry' are
+// skipped, as are entries for which the registry already knows a more recent
+// revision. The signatures of all imported entries are verified. Import returns
+// the number of entries that were added or updated.
+func (r *Registry) Import(rd io.Reader, expiry types.BlockHeight) (uint64, error) {
+	br := bufio.NewReader(rd)
+
+	// Read and verify the metadata.
+	var metadata [PersistedEntrySize]byte
+	if _, err := io.ReadFull(br, metadata[:]); err != nil {
+		return 0, errors.Compose(errInvalidExport, err)
+	}
+	header := metadata[:types.SpecifierLen]
+	version := metadata[types.SpecifierLen : 2*types.SpecifierLen]
+	if !bytes.Equal(header, exportHeader[:]) {
+		return 0, errInvalidExport
+	}
+	if !bytes.Equal(version, registryVersion[:]) {
+		return 0, fmt.Errorf("expected export version %v but got %v", registryVersion, version)
+	}
+
+	// Import the entries.
+	var imported uint64
+	var b [PersistedEntrySize]byte
+	for index := 1; ; index++ {
+		_, err := io.ReadFull(br, b[:])
+		if errors.Contains(err, io.EOF) {
+			break
+		} else if err != nil {
+			return imported, errors.AddContext(err, fmt.Sprintf("Import: failed to read entry %v", index))
+		}
+		var pe persistedEntry
+		if err := pe.Unmarshal(b[:]); err != nil {
+			return imported, errors.AddContext(err, fmt.Sprintf("Import: failed to parse entry %v", index))
+		}
+		v, err := pe.Value(0)
+		if err != nil {
+			return imported, errors.AddContext(err, fmt.Sprintf("Import: failed to get value from entry %v", index))
+		}
+		if v.expiry <= expiry {
+			continue // expired
+		}
+		rv := modules.NewSignedRegistryValue(v.tweak, v.data, v.revision, v.signature)
+		_, err = r.Update(rv, v.key, v.expiry)
+		if errors.Contains(err, ErrLowerRevNum) || errors.Contains(err, ErrSameRevNum) {
+			continue // registry knows a more recent revision
+		} else if err != nil {
+			return imported, errors.AddContext(err, fmt.Sprintf("Import: failed to import entry %v", index))
+		}
+		imported++
+	}
+	return imported, nil
+}
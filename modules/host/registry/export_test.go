@@ -0,0 +1,190 @@
+package registry
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestEntries tests paging through the entries of the registry and looking up
+// single entries.
+func TestEntries(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := testDir(t.Name())
+	r, err := New(filepath.Join(dir, "registry"), testingDefaultMaxEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c io.Closer) {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}(r)
+
+	// Add some entries.
+	numEntries := 10
+	for i := 0; i < numEntries; i++ {
+		rv, v, _ := randomValue(0)
+		_, err = r.Update(rv, v.key, v.expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Fetch all entries.
+	all, total := r.Entries(0, 0)
+	if total != uint64(numEntries) || len(all) != numEntries {
+		t.Fatalf("expected %v entries, got %v of %v", numEntries, len(all), total)
+	}
+	for i := 1; i < len(all); i++ {
+		if bytes.Compare(all[i-1].EntryID[:], all[i].EntryID[:]) >= 0 {
+			t.Fatal("entries are not sorted by id")
+		}
+	}
+
+	// Page through the entries.
+	page, total := r.Entries(4, 3)
+	if total != uint64(numEntries) || len(page) != 3 {
+		t.Fatalf("expected 3 entries, got %v of %v", len(page), total)
+	}
+	if page[0].EntryID != all[4].EntryID || page[2].EntryID != all[6].EntryID {
+		t.Fatal("wrong page")
+	}
+	page, _ = r.Entries(8, 3)
+	if len(page) != 2 {
+		t.Fatal("expected 2 entries, got", len(page))
+	}
+	page, _ = r.Entries(uint64(numEntries), 3)
+	if len(page) != 0 {
+		t.Fatal("expected no entries, got", len(page))
+	}
+
+	// Look up an entry.
+	entry, found := r.Entry(modules.RegistryEntryID(all[3].EntryID))
+	if !found {
+		t.Fatal("entry not found")
+	}
+	if modules.DeriveRegistryEntryID(entry.PublicKey, entry.Tweak) != modules.RegistryEntryID(all[3].EntryID) {
+		t.Fatal("wrong entry")
+	}
+	_, found = r.Entry(modules.RegistryEntryID{})
+	if found {
+		t.Fatal("unknown entry shouldn't be found")
+	}
+}
+
+// TestExportImport tests exporting a registry and importing it into another
+// one.
+func TestExportImport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := testDir(t.Name())
+	r, err := New(filepath.Join(dir, "registry"), testingDefaultMaxEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c io.Closer) {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}(r)
+	// The registry to import into is smaller than the exported one.
+	r2, err := New(filepath.Join(dir, "registry2"), testingDefaultMaxEntries/2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c io.Closer) {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}(r2)
+
+	// Add an expired entry and some valid ones.
+	rvExpired, vExpired, _ := randomValue(0)
+	_, err = r.Update(rvExpired, vExpired.key, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numEntries := 5
+	var values []*value
+	var sks []crypto.SecretKey
+	for i := 0; i < numEntries; i++ {
+		rv, v, sk := randomValue(0)
+		v.expiry = 100
+		_, err = r.Update(rv, v.key, v.expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+		sks = append(sks, sk)
+	}
+
+	// Add one of the entries to the second registry with a higher revision.
+	rvNewer := modules.NewRegistryValue(values[0].tweak, values[0].data, values[0].revision+1).Sign(sks[0])
+	_, err = r2.Update(rvNewer, values[0].key, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Export the registry.
+	buf := new(bytes.Buffer)
+	exported, err := r.Export(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exported != uint64(numEntries+1) {
+		t.Fatal("wrong number of exported entries", exported)
+	}
+	if buf.Len() != (numEntries+2)*PersistedEntrySize {
+		t.Fatal("wrong export size", buf.Len())
+	}
+
+	// Import it. The expired entry and the outdated entry should be skipped.
+	imported, err := r2.Import(bytes.NewReader(buf.Bytes()), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported != uint64(numEntries-1) {
+		t.Fatal("wrong number of imported entries", imported)
+	}
+	if r2.Len() != uint64(numEntries) {
+		t.Fatal("wrong number of entries", r2.Len())
+	}
+	for i, v := range values {
+		entry, found := r2.Entry(v.mapKey())
+		if !found {
+			t.Fatal("entry not found", i)
+		}
+		if i == 0 {
+			if entry.Revision != values[0].revision+1 || entry.Expiry != 200 {
+				t.Fatal("newer entry was overwritten", entry)
+			}
+			continue
+		}
+		if entry.Revision != v.revision || entry.Expiry != v.expiry || !bytes.Equal(entry.Data, v.data) {
+			t.Fatal("wrong entry", entry)
+		}
+	}
+	if _, found := r2.Entry(vExpired.mapKey()); found {
+		t.Fatal("expired entry was imported")
+	}
+
+	// Importing something that isn't an export should fail.
+	_, err = r2.Import(bytes.NewReader(make([]byte, PersistedEntrySize)), types.BlockHeight(0))
+	if !errors.Contains(err, errInvalidExport) {
+		t.Fatal("expected errInvalidExport, got", err)
+	}
+}
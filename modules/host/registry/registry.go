@@ -2,6 +2,7 @@ package registry
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -118,6 +119,20 @@ func (v *value) update(rv modules.SignedRegistryValue, newExpiry types.BlockHeig
 	return nil
 }
 
+// hostRegistryEntry converts the value into a modules.HostRegistryEntry.
+// NOTE: v.mu is expected to be acquired.
+func (v *value) hostRegistryEntry() modules.HostRegistryEntry {
+	return modules.HostRegistryEntry{
+		EntryID:   crypto.Hash(v.mapKey()),
+		PublicKey: v.key,
+		Tweak:     v.tweak,
+		Data:      append([]byte{}, v.data...),
+		Revision:  v.revision,
+		Signature: append([]byte{}, v.signature[:]...),
+		Expiry:    v.expiry,
+	}
+}
+
 // Cap returns the capacity of the registry.
 func (r *Registry) Cap() uint64 {
 	r.mu.Lock()
@@ -130,6 +145,59 @@ func (r *Registry) Close() error {
 	return r.staticFile.Close()
 }
 
+// Entries returns up to 'limit' entries of the registry, starting at 'offset',
+// sorted by their entry id. It also returns the total number of entries. A
+// limit of 0 returns all entries after the offset.
+func (r *Registry) Entries(offset, limit uint64) ([]modules.HostRegistryEntry, uint64) {
+	r.mu.Lock()
+	values := make([]*value, 0, len(r.entries))
+	for _, v := range r.entries {
+		values = append(values, v)
+	}
+	r.mu.Unlock()
+
+	// Sort the values without holding the lock. The entry id of a value never
+	// changes.
+	sort.Slice(values, func(i, j int) bool {
+		idI, idJ := values[i].mapKey(), values[j].mapKey()
+		return bytes.Compare(idI[:], idJ[:]) < 0
+	})
+	total := uint64(len(values))
+	if offset >= total {
+		return []modules.HostRegistryEntry{}, total
+	}
+	values = values[offset:]
+	if limit > 0 && limit < uint64(len(values)) {
+		values = values[:limit]
+	}
+
+	entries := make([]modules.HostRegistryEntry, 0, len(values))
+	for _, v := range values {
+		v.mu.Lock()
+		if !v.invalid {
+			entries = append(entries, v.hostRegistryEntry())
+		}
+		v.mu.Unlock()
+	}
+	return entries, total
+}
+
+// Entry returns the entry with the given id including its expiry.
+func (r *Registry) Entry(sid modules.RegistryEntryID) (modules.HostRegistryEntry, bool) {
+	r.mu.Lock()
+	v, ok := r.entries[sid]
+	r.mu.Unlock()
+	if !ok {
+		return modules.HostRegistryEntry{}, false
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.invalid {
+		return modules.HostRegistryEntry{}, false
+	}
+	return v.hostRegistryEntry(), true
+}
+
 // Get fetches the data associated with a key and tweak from the registry.
 func (r *Registry) Get(sid modules.RegistryEntryID) (types.SiaPublicKey, modules.SignedRegistryValue, bool) {
 	r.mu.Lock()
@@ -0,0 +1,119 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/siamux"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// managedRPCDryRunProgram handles incoming DryRunProgram RPCs. The renter only
+// pays for initializing the MDM and the bandwidth of the RPC. The program's
+// instructions are priced but never executed.
+func (h *Host) managedRPCDryRunProgram(stream siamux.Stream) error {
+	// read the price table
+	pt, err := h.staticReadPriceTableID(stream)
+	if err != nil {
+		return errors.AddContext(err, "failed to read price table")
+	}
+
+	// Process payment.
+	pd, err := h.ProcessPayment(stream, pt.HostBlockHeight)
+	if err != nil {
+		return errors.AddContext(err, "failed to process payment")
+	}
+
+	// Add limit to the stream.
+	budget := modules.NewBudget(pd.Amount())
+	bandwidthLimit := modules.NewBudgetLimit(budget, pt.UploadBandwidthCost, pt.DownloadBandwidthCost)
+	err = stream.SetLimit(bandwidthLimit)
+	if err != nil {
+		return errors.AddContext(err, "failed to set budget limit on stream")
+	}
+
+	// Refund all the money we didn't use at the end of the RPC.
+	refundAccount := pd.AccountID()
+	err = h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		go func() {
+			defer h.tg.Done()
+			depositErr := h.staticAccountManager.callRefund(refundAccount, budget.Remaining())
+			if depositErr != nil {
+				h.log.Print("ERROR: failed to refund renter", depositErr)
+			}
+		}()
+	}()
+
+	// Read request
+	var drr modules.RPCDryRunProgramRequest
+	err = modules.RPCRead(stream, &drr)
+	if err != nil {
+		return errors.AddContext(err, "Failed to read RPCDryRunProgramRequest")
+	}
+	fcid, program, dataLength := drr.FileContractID, drr.Program, drr.ProgramDataLength
+
+	// Pay for initializing the MDM.
+	if !budget.Withdraw(modules.MDMInitCost(pt, dataLength, uint64(len(program)))) {
+		return modules.ErrInsufficientPaymentForRPC
+	}
+
+	// Get a snapshot of the storage obligation if required. A dry run never
+	// modifies the obligation so there is no need to lock it.
+	sos := ZeroStorageObligationSnapshot()
+	if program.RequiresSnapshot() {
+		sos, err = h.managedGetStorageObligationSnapshot(fcid)
+		if err != nil {
+			return errors.AddContext(err, fmt.Sprintf("failed to get storage obligation snapshot for contract %v", fcid))
+		}
+	}
+	collateralBudget := sos.UnallocatedCollateral()
+	duration := sos.ProofDeadline() - h.BlockHeight()
+
+	// Get a context that can be used to interrupt the program and cancel it on
+	// shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h.tg.OnStop(cancel)
+
+	// Dry run the program with the renter's budget.
+	outputs, err := h.staticMDM.DryRunProgram(ctx, pt, program, modules.NewBudget(drr.Budget), collateralBudget, sos, duration, dataLength, stream)
+	if err != nil {
+		return errors.AddContext(err, "Failed to start dry run of the program")
+	}
+
+	// Collect the breakdown of every instruction from the running totals of
+	// the outputs.
+	resp := modules.RPCDryRunProgramResponse{
+		InitCost: modules.MDMInitCost(pt, dataLength, uint64(len(program))),
+	}
+	prevCost := resp.InitCost
+	var prevRefund, prevCollateral types.Currency
+	for output := range outputs {
+		if output.Error != nil {
+			resp.Error = output.Error.Error()
+			continue // continue to drain the channel
+		}
+		resp.Instructions = append(resp.Instructions, modules.RPCDryRunInstruction{
+			Specifier:     program[len(resp.Instructions)].Specifier,
+			Cost:          output.ExecutionCost.Sub(prevCost),
+			FailureRefund: output.FailureRefund.Sub(prevRefund),
+			Collateral:    output.AdditionalCollateral.Sub(prevCollateral),
+			Memory:        output.UsedMemory,
+			Time:          output.InstructionTime,
+		})
+		prevCost, prevRefund, prevCollateral = output.ExecutionCost, output.FailureRefund, output.AdditionalCollateral
+	}
+
+	// Send the response.
+	err = modules.RPCWrite(stream, resp)
+	if err != nil {
+		return errors.AddContext(err, "failed to send RPCDryRunProgramResponse")
+	}
+	return nil
+}
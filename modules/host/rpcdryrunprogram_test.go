@@ -0,0 +1,117 @@
+package host
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestDryRunProgram tests pricing a write program with the DryRunProgram RPC.
+func TestDryRunProgram(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// create a blank host tester
+	rhp, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := rhp.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	host := rhp.staticHT.host
+
+	// prefund the EA
+	his := host.managedInternalSettings()
+	_, err = rhp.managedFundEphemeralAccount(his.MaxEphemeralAccountBalance, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Get the contract's state before the dry run.
+	sos, err := host.managedGetStorageObligationSnapshot(rhp.staticFCID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	duration := sos.ProofDeadline() - host.BlockHeight()
+
+	// Create a program that appends a sector and reads it back.
+	pt := rhp.managedPriceTable()
+	pb := modules.NewProgramBuilder(pt, duration)
+	err = pb.AddAppendInstruction(fastrand.Bytes(int(modules.SectorSize)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb.AddReadOffsetInstruction(modules.SectorSize, 0, true)
+	program, data := pb.Program()
+	cost, refund, collateral := pb.Cost(false)
+
+	// Dry run the program with the budget the renter would use.
+	drr := modules.RPCDryRunProgramRequest{
+		FileContractID:    rhp.staticFCID,
+		Program:           program,
+		ProgramDataLength: uint64(len(data)),
+		Budget:            cost,
+	}
+	payment := modules.MDMInitCost(pt, uint64(len(data)), uint64(len(program))).Add(pt.UploadBandwidthCost.Mul64(uint64(len(data)) * 2)).Add(pt.DownloadBandwidthCost.Mul64(1 << 20))
+	resp, err := rhp.managedDryRunProgram(drr, data, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != "" {
+		t.Fatal(resp.Error)
+	}
+
+	// The host's breakdown should match the local estimate.
+	if len(resp.Instructions) != len(program) {
+		t.Fatalf("expected %v instructions but got %v", len(program), len(resp.Instructions))
+	}
+	if !resp.TotalCost().Equals(cost) {
+		t.Fatalf("cost mismatch %v != %v", resp.TotalCost(), cost)
+	}
+	if !resp.TotalCollateral().Equals(collateral) {
+		t.Fatalf("collateral mismatch %v != %v", resp.TotalCollateral(), collateral)
+	}
+	if !resp.Instructions[0].FailureRefund.Add(resp.Instructions[1].FailureRefund).Equals(refund) {
+		t.Fatal("refund mismatch")
+	}
+	for i, instruction := range resp.Instructions {
+		if instruction.Specifier != program[i].Specifier {
+			t.Fatal("wrong specifier", instruction.Specifier)
+		}
+	}
+	if resp.Instructions[0].Time != modules.MDMTimeAppend || resp.Instructions[1].Time != modules.MDMTimeReadOffset {
+		t.Fatal("wrong times", resp.Instructions[0].Time, resp.Instructions[1].Time)
+	}
+
+	// The contract shouldn't have changed.
+	sos2, err := host.managedGetStorageObligationSnapshot(rhp.staticFCID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sos2.ContractSize() != sos.ContractSize() || sos2.MerkleRoot() != sos.MerkleRoot() {
+		t.Fatal("dry run modified the contract")
+	}
+
+	// Dry running the program with a budget that is too small should report
+	// the error of the instruction that would fail.
+	drr.Budget = cost.Sub(types.NewCurrency64(1))
+	resp, err = rhp.managedDryRunProgram(drr, data, payment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(resp.Error, modules.ErrMDMInsufficientBudget.Error()) {
+		t.Fatal("expected insufficient budget error but got", resp.Error)
+	}
+	if len(resp.Instructions) != len(program)-1 {
+		t.Fatal("wrong number of instructions", len(resp.Instructions))
+	}
+}
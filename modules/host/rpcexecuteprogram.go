@@ -17,8 +17,11 @@ import (
 	"go.sia.tech/siad/types"
 )
 
-// managedRPCExecuteProgram handles incoming ExecuteProgram RPCs.
-func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
+// managedRPCExecuteProgram handles incoming ExecuteProgram and
+// ExecuteProgramStream RPCs. When streaming, the output data of read
+// instructions is written to the renter as it is read from disk and the proof
+// of every instruction follows its output data.
+func (h *Host) managedRPCExecuteProgram(stream siamux.Stream, streamOutputs bool) error {
 	// read the price table
 	pt, err := h.staticReadPriceTableID(stream)
 	if err != nil {
@@ -31,6 +34,14 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		return errors.AddContext(err, "failed to process payment")
 	}
 
+	// Enforce the client's program quota. The payment is refunded in full if
+	// the program is rejected.
+	err = h.staticClients.managedStartProgram(stream, h.managedClientQuotas())
+	if err != nil {
+		refundErr := h.staticAccountManager.callRefund(pd.AccountID(), pd.Amount())
+		return errors.Compose(err, refundErr)
+	}
+
 	// Add limit to the stream. The readCost is the UploadBandwidthCost since
 	// reading from the stream means uploading from the host's perspective. That
 	// makes the writeCost the DownloadBandwidthCost.
@@ -110,8 +121,22 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 	// we need to do it this way.
 	h.tg.OnStop(cancel)
 
+	// Trace the program if it was sampled or if failed programs are traced.
+	var trace *mdm.Trace
+	programCtx := ctx
+	traceSampled, traceFailures := h.managedTraceProgram()
+	if traceSampled || traceFailures {
+		trace = new(mdm.Trace)
+		programCtx = mdm.WithTrace(ctx, trace)
+	}
+
 	// Execute the program.
-	finalize, outputs, err := h.staticMDM.ExecuteProgram(ctx, pt, program, budget, collateralBudget, sos, duration, dataLength, stream)
+	start := time.Now()
+	executeProgram := h.staticMDM.ExecuteProgram
+	if streamOutputs {
+		executeProgram = h.staticMDM.ExecuteProgramStream
+	}
+	finalize, outputs, err := executeProgram(programCtx, pt, program, budget, collateralBudget, sos, duration, dataLength, stream)
 	if err != nil {
 		return errors.AddContext(err, "Failed to start execution of the program")
 	}
@@ -158,7 +183,8 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 			build.Critical(err) // don't return on purpose
 		}
 
-		// Prepare the RPC response.
+		// Prepare the RPC response. When streaming, the proof is sent after
+		// the output data.
 		resp := modules.RPCExecuteProgramResponse{
 			AdditionalCollateral: output.AdditionalCollateral,
 			Error:                output.Error,
@@ -169,6 +195,12 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 			Proof:                output.Proof,
 			TotalCost:            output.ExecutionCost,
 		}
+		if output.Stream != nil {
+			resp.OutputLength = output.Stream.Len()
+		}
+		if streamOutputs {
+			resp.Proof = nil
+		}
 		// Update cost and refund.
 		if output.ExecutionCost.Cmp(output.FailureRefund) < 0 {
 			err = errors.New("executionCost can never be smaller than the storage cost")
@@ -181,6 +213,12 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		// Remember that the execution wasn't successful.
 		executionFailed = output.Error != nil
 
+		// Record the metrics of the instruction. Skipped instructions were
+		// never executed.
+		if !output.Skipped {
+			h.staticMetrics.managedRecordInstruction(program[numOutputs-1].Specifier, output.ExecutionTime, output.Error)
+		}
+
 		// Send the response to the peer.
 		err = modules.RPCWrite(buffer, resp)
 		if err != nil {
@@ -190,23 +228,43 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		instructionSpecifier := program[numOutputs-1].Specifier
 		readInstruction := instructionSpecifier == modules.SpecifierReadOffset || instructionSpecifier == modules.SpecifierReadSector
 		updateRegistryInstruction := instructionSpecifier == modules.SpecifierUpdateRegistry
-		if (readInstruction || updateRegistryInstruction) && h.dependencies.Disrupt("CorruptMDMOutput") {
+		corruptOutput := (readInstruction || updateRegistryInstruction) && h.dependencies.Disrupt("CorruptMDMOutput")
+		if corruptOutput {
 			// Replace output with same amount of random data.
 			fastrand.Read(output.Output)
 		}
 
-		// Write output.
-		_, err = buffer.Write(output.Output)
-		if err != nil {
-			return errors.AddContext(err, "failed to send output data to peer")
-		}
-
 		// Increase the write deadline just before writing to it.
 		err = stream.SetWriteDeadline(time.Now().Add(modules.MDMProgramWriteResponseTime))
 		if err != nil {
 			return errors.AddContext(err, "failed to set write deadline on stream")
 		}
 
+		// Write output.
+		if output.Stream != nil {
+			err = streamOutput(stream, buffer, output.Stream, corruptOutput)
+			if err != nil {
+				return errors.AddContext(err, "failed to stream output data to peer")
+			}
+		} else {
+			if streamOutputs {
+				err = modules.RPCWrite(buffer, modules.RPCExecuteProgramStreamRoots{})
+				if err != nil {
+					return errors.AddContext(err, "failed to send roots to peer")
+				}
+			}
+			_, err = buffer.Write(output.Output)
+			if err != nil {
+				return errors.AddContext(err, "failed to send output data to peer")
+			}
+			if streamOutputs {
+				err = modules.RPCWrite(buffer, modules.RPCExecuteProgramStreamProof{Proof: output.Proof})
+				if err != nil {
+					return errors.AddContext(err, "failed to send proof to peer")
+				}
+			}
+		}
+
 		// Disrupt if the delay write dependency is set
 		if h.dependencies.Disrupt("MDMProgramOutputDelayWrite") {
 			// add a write delay
@@ -238,6 +296,12 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		return err
 	}
 
+	// Keep the execution trace of the program. The output channel was
+	// closed, so the program is done recording it.
+	if traceSampled || (executionFailed && traceFailures) {
+		h.managedAddMDMTrace(stream, fcid, program, trace, start, output)
+	}
+
 	// Reset the deadline (set both read and write)
 	err = stream.SetDeadline(time.Now().Add(defaultConnectionDeadline))
 	if err != nil {
@@ -268,6 +332,67 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 	return nil
 }
 
+// streamOutput streams the output of an instruction to the peer, preceded by
+// the output's stream roots and followed by the output's proof. Whatever is
+// left in the buffer is sent together with the first chunk of data. Every chunk
+// of output data extends the write deadline of the stream.
+func streamOutput(stream siamux.Stream, buffer *bytes.Buffer, outputStream *mdm.OutputStream, corrupt bool) error {
+	// Send the roots.
+	err := modules.RPCWrite(buffer, outputStream.Roots())
+	if err != nil {
+		return err
+	}
+
+	// Stream the data.
+	var w io.Writer = &streamWriter{stream: stream, buffer: buffer}
+	if corrupt {
+		w = &corruptingWriter{w}
+	}
+	proof, err := outputStream.StreamTo(w)
+	if err != nil {
+		return err
+	}
+
+	// Send the proof.
+	return modules.RPCWrite(buffer, modules.RPCExecuteProgramStreamProof{Proof: proof})
+}
+
+// streamWriter is an io.Writer which extends the write deadline of a stream
+// before every write. If the buffer isn't empty, the written data is appended
+// to it and the buffer is flushed to avoid sending its contents separately.
+type streamWriter struct {
+	stream siamux.Stream
+	buffer *bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (sw *streamWriter) Write(b []byte) (int, error) {
+	err := sw.stream.SetWriteDeadline(time.Now().Add(modules.MDMProgramWriteResponseTime))
+	if err != nil {
+		return 0, err
+	}
+	if sw.buffer.Len() == 0 {
+		return sw.stream.Write(b)
+	}
+	sw.buffer.Write(b)
+	_, err = sw.buffer.WriteTo(sw.stream)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// corruptingWriter is an io.Writer which replaces the written data with random
+// data of the same length. It is used by the 'CorruptMDMOutput' dependency.
+type corruptingWriter struct {
+	w io.Writer
+}
+
+// Write implements io.Writer.
+func (cw *corruptingWriter) Write(b []byte) (int, error) {
+	return cw.w.Write(fastrand.Bytes(len(b)))
+}
+
 // managedFinalizeWriteProgram conducts the additional steps required to
 // finalize a write program. The blockheight is passed in to make sure we are
 // using the same as when we ran the MDMD.
@@ -722,6 +722,142 @@ func TestExecuteReadOffsetProgram(t *testing.T) {
 	t.Logf("Used bandwidth (read offset program): %v down, %v up", bandwidth.Downloaded(), bandwidth.Uploaded())
 }
 
+// TestExecuteProgramStream tests the managedRPCExecuteProgram with a program
+// that is executed using the ExecuteProgramStream RPC.
+func TestExecuteProgramStream(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// create a blank host tester
+	rhp, err := newRenterHostPair(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := rhp.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	ht := rhp.staticHT
+
+	// create a random sector
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	// modify the host's storage obligation to add the sector
+	so, err := ht.host.managedGetStorageObligation(rhp.staticFCID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	ht.host.managedLockStorageObligation(rhp.staticFCID)
+	err = ht.host.managedModifyStorageObligation(so, []crypto.Hash{}, map[crypto.Hash][]byte{sectorRoot: sectorData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(rhp.staticFCID)
+
+	// create a program that reads the full sector, checks for the sector and
+	// reads part of the sector by offset.
+	offset := uint64(2 * crypto.SegmentSize)
+	length := uint64(3 * crypto.SegmentSize)
+	pt := rhp.managedPriceTable()
+	pb := modules.NewProgramBuilder(pt, 0)
+	pb.AddReadSectorInstruction(modules.SectorSize, 0, sectorRoot, true)
+	pb.AddHasSectorInstruction(sectorRoot)
+	pb.AddReadOffsetInstruction(length, offset, true)
+	program, data := pb.Program()
+	programCost, _, _ := pb.Cost(true)
+
+	// prepare the request.
+	epr := modules.RPCExecuteProgramRequest{
+		FileContractID:    rhp.staticFCID,
+		Program:           program,
+		ProgramDataLength: uint64(len(data)),
+	}
+
+	// fund an account.
+	fundingAmt := rhp.staticHT.host.managedInternalSettings().MaxEphemeralAccountBalance.Add(pt.FundAccountCost)
+	_, err = rhp.managedFundEphemeralAccount(fundingAmt, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// execute program with a budget that covers the bandwidth of the sector.
+	// The host doesn't know the chunk roots of the sector the first time it
+	// is read, so there are no stream roots yet.
+	bandwidthCost := pt.DownloadBandwidthCost.Mul64(1 << 20).Add(pt.UploadBandwidthCost.Mul64(1 << 16))
+	resps, _, err := rhp.managedExecuteProgramStream(epr, data, programCost.Add(bandwidthCost), true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resps) == 0 || len(resps[0].StreamRoots.Roots) != 0 || !bytes.Equal(resps[0].Output, sectorData) {
+		t.Fatal("unexpected response for first read", len(resps))
+	}
+	resps, _, err = rhp.managedExecuteProgramStream(epr, data, programCost.Add(bandwidthCost), true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resps) != len(program) {
+		t.Fatalf("expected %v responses but got %v", len(program), len(resps))
+	}
+	for _, resp := range resps {
+		if resp.Error != nil {
+			t.Fatal(resp.Error)
+		}
+	}
+
+	// check the full sector.
+	if !bytes.Equal(resps[0].Output, sectorData) {
+		t.Fatal("Unexpected data")
+	}
+	if !crypto.VerifyRangeProof(resps[0].Output, resps[0].Proof, 0, int(modules.SectorSize/crypto.SegmentSize), sectorRoot) {
+		t.Fatal("invalid proof for full sector")
+	}
+
+	// check the stream roots of the full sector. Since the read covers every
+	// chunk, no chunk proofs are necessary.
+	numChunks := int(modules.SectorSize / modules.MDMStreamChunkSize)
+	roots := resps[0].StreamRoots
+	if len(roots.Roots) != numChunks || len(roots.Proofs) != numChunks {
+		t.Fatalf("expected %v roots and proofs but got %v and %v", numChunks, len(roots.Roots), len(roots.Proofs))
+	}
+	for i, root := range roots.Roots {
+		chunk := sectorData[uint64(i)*modules.MDMStreamChunkSize:][:modules.MDMStreamChunkSize]
+		if root != crypto.MerkleRoot(chunk) {
+			t.Fatal("wrong root for chunk", i)
+		}
+		if len(roots.Proofs[i]) != 0 {
+			t.Fatal("unexpected proof for chunk", i)
+		}
+	}
+	if len(resps[1].StreamRoots.Roots) != 0 || len(resps[2].StreamRoots.Roots) != 0 {
+		t.Fatal("expected no stream roots for instructions other than ReadSector")
+	}
+
+	// check the HasSector output.
+	if !bytes.Equal(resps[1].Output, []byte{1}) {
+		t.Fatal("expected host to have sector")
+	}
+
+	// check the partial read.
+	if !bytes.Equal(resps[2].Output, sectorData[offset:offset+length]) {
+		t.Fatal("Unexpected data")
+	}
+	proofStart := int(offset) / crypto.SegmentSize
+	proofEnd := int(offset+length) / crypto.SegmentSize
+	if !crypto.VerifyMixedRangeProof(resps[2].Output, resps[2].Proof, sectorRoot, proofStart, proofEnd) {
+		t.Fatal("invalid proof for partial read")
+	}
+
+	// verify the cost
+	if !resps[2].TotalCost.Equals(programCost) {
+		t.Fatalf("wrong TotalCost %v != %v", resps[2].TotalCost.HumanString(), programCost.HumanString())
+	}
+}
+
 // TestVerifyExecuteProgramRevision is a unit test covering
 // verifyExecuteProgramRevision.
 func TestVerifyExecuteProgramRevision(t *testing.T) {
@@ -57,7 +57,7 @@ func (h *Host) managedRPCRenewContract(stream siamux.Stream) error {
 	hsk := h.secretKey
 	contractPrice := pt.ContractPrice
 	is := h.settings // internal settings
-	ac := is.AcceptingContracts
+	ac := is.AcceptingContracts && !h.decommission.Active
 	lockedCollateral := h.financialMetrics.LockedStorageCollateral
 	unlockHash := h.unlockHash
 	h.mu.RUnlock()
@@ -20,25 +20,27 @@ import (
 // TestPriceTableMarshaling tests a PriceTable can be marshaled and unmarshaled
 func TestPriceTableMarshaling(t *testing.T) {
 	pt := modules.RPCPriceTable{
-		Validity:             rpcPriceGuaranteePeriod,
-		HostBlockHeight:      types.BlockHeight(fastrand.Intn(1e3)),
-		UpdatePriceTableCost: types.SiacoinPrecision,
-		InitBaseCost:         types.SiacoinPrecision.Mul64(1e2),
-		MemoryTimeCost:       types.SiacoinPrecision.Mul64(1e3),
-		ReadBaseCost:         types.SiacoinPrecision.Mul64(1e4),
-		ReadLengthCost:       types.SiacoinPrecision.Mul64(1e5),
-		HasSectorBaseCost:    types.SiacoinPrecision.Mul64(1e6),
-		TxnFeeMinRecommended: types.SiacoinPrecision.Mul64(1e7),
-		TxnFeeMaxRecommended: types.SiacoinPrecision.Mul64(1e8),
-		DropSectorsBaseCost:  types.SiacoinPrecision.Mul64(1e9),
-		DropSectorsUnitCost:  types.SiacoinPrecision.Mul64(1e10),
-		WriteBaseCost:        types.SiacoinPrecision.Mul64(1e11),
-		WriteLengthCost:      types.SiacoinPrecision.Mul64(1e12),
-		WriteStoreCost:       types.SiacoinPrecision.Mul64(1e13),
-		LatestRevisionCost:   types.SiacoinPrecision.Mul64(1e14),
-		FundAccountCost:      types.SiacoinPrecision.Mul64(1e15),
-		AccountBalanceCost:   types.SiacoinPrecision.Mul64(1e16),
-		SwapSectorCost:       types.SiacoinPrecision.Mul64(1e17),
+		Validity:               rpcPriceGuaranteePeriod,
+		HostBlockHeight:        types.BlockHeight(fastrand.Intn(1e3)),
+		UpdatePriceTableCost:   types.SiacoinPrecision,
+		InitBaseCost:           types.SiacoinPrecision.Mul64(1e2),
+		MemoryTimeCost:         types.SiacoinPrecision.Mul64(1e3),
+		ReadBaseCost:           types.SiacoinPrecision.Mul64(1e4),
+		ReadLengthCost:         types.SiacoinPrecision.Mul64(1e5),
+		HasSectorBaseCost:      types.SiacoinPrecision.Mul64(1e6),
+		TxnFeeMinRecommended:   types.SiacoinPrecision.Mul64(1e7),
+		TxnFeeMaxRecommended:   types.SiacoinPrecision.Mul64(1e8),
+		DropSectorsBaseCost:    types.SiacoinPrecision.Mul64(1e9),
+		DropSectorsUnitCost:    types.SiacoinPrecision.Mul64(1e10),
+		WriteBaseCost:          types.SiacoinPrecision.Mul64(1e11),
+		WriteLengthCost:        types.SiacoinPrecision.Mul64(1e12),
+		WriteStoreCost:         types.SiacoinPrecision.Mul64(1e13),
+		LatestRevisionCost:     types.SiacoinPrecision.Mul64(1e14),
+		FundAccountCost:        types.SiacoinPrecision.Mul64(1e15),
+		AccountBalanceCost:     types.SiacoinPrecision.Mul64(1e16),
+		SwapSectorCost:         types.SiacoinPrecision.Mul64(1e17),
+		UpdateSectorBaseCost:   types.SiacoinPrecision.Mul64(1e18),
+		UpdateSectorLengthCost: types.SiacoinPrecision.Mul64(1e19),
 	}
 	fastrand.Read(pt.UID[:])
 
@@ -227,6 +227,7 @@ func (h *Host) managedGetStorageObligationSnapshot(id types.FileContractID) (Sto
 		staticContractSize:  so.fileSize(),
 		staticMerkleRoot:    so.merkleRoot(),
 		staticProofDeadline: so.proofDeadline(),
+		staticResolved:      so.ObligationStatus != obligationUnresolved,
 		staticRevisionTxn:   revTxn,
 		staticSectorRoots:   so.SectorRoots,
 	}, nil
@@ -266,6 +267,7 @@ type StorageObligationSnapshot struct {
 	staticContractSize  uint64
 	staticMerkleRoot    crypto.Hash
 	staticProofDeadline types.BlockHeight
+	staticResolved      bool
 	staticRevisionTxn   types.Transaction
 	staticSectorRoots   []crypto.Hash
 }
@@ -306,6 +308,12 @@ func (sos StorageObligationSnapshot) MerkleRoot() crypto.Hash {
 	return sos.staticMerkleRoot
 }
 
+// Resolved returns whether the underlying contract was already resolved at the
+// time the snapshot was taken.
+func (sos StorageObligationSnapshot) Resolved() bool {
+	return sos.staticResolved
+}
+
 // RecentRevision returns the recent revision at the time the snapshot was
 // taken.
 func (sos StorageObligationSnapshot) RecentRevision() types.FileContractRevision {
@@ -312,6 +312,16 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 	// change.
 	h.recentChange = cc.ID
 
+	// Recompute the host's prices once the host is synced.
+	if cc.Synced && h.pricingPolicy.Enabled {
+		go h.threadedUpdatePrices()
+	}
+
+	// Update the progress of the host's decommissioning.
+	if h.decommission.Active {
+		go h.threadedUpdateDecommission()
+	}
+
 	// Save the host.
 	err = h.saveSync()
 	if err != nil {
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"encoding/binary"
+	"math/big"
 	"sync"
 	"time"
 
@@ -28,6 +29,22 @@ type (
 	// MDMCancellationToken is a token that can be used to request cancellation
 	// of a program
 	MDMCancellationToken [MDMCancellationTokenLen]byte
+
+	// RegistryBatchUpdate is a single update within an 'UpdateRegistryBatch'
+	// instruction.
+	RegistryBatchUpdate struct {
+		PubKey types.SiaPublicKey
+		Value  SignedRegistryValue
+	}
+
+	// RegistryBatchUpdateResult is the result of a single update within an
+	// 'UpdateRegistryBatch' instruction. Error is empty if the update was
+	// successful. If the host already knows about an entry with a higher or
+	// equal revision, Existing contains that entry as proof.
+	RegistryBatchUpdateResult struct {
+		Error    string
+		Existing SignedRegistryValue
+	}
 )
 
 const (
@@ -44,6 +61,13 @@ const (
 	// MDMTimeAppend is the time for executing an 'Append' instruction.
 	MDMTimeAppend = 10000
 
+	// MDMTimeAssertRevision is the time for executing an 'AssertRevision'
+	// instruction.
+	MDMTimeAssertRevision = 1
+
+	// MDMTimeCopySector is the time for executing a 'CopySector' instruction.
+	MDMTimeCopySector = 10000
+
 	// MDMTimeCommit is the time used for executing managedFinalize.
 	// TODO: This should scale with the number of added + removed sectors.
 	MDMTimeCommit = 50e3
@@ -58,6 +82,10 @@ const (
 	// MDMTimeHasSector is the time for executing a 'HasSector' instruction.
 	MDMTimeHasSector = 1
 
+	// MDMTimeIfHasSector is the time for executing an 'IfHasSector'
+	// instruction.
+	MDMTimeIfHasSector = 1
+
 	// MDMTimeInitProgram is the base time for initializing a program. `1`
 	// because no disk IO is involved.
 	MDMTimeInitProgram = 1
@@ -69,6 +97,10 @@ const (
 	// MDMTimeReadOffset is the time for executing a 'ReadOffset' instruction.
 	MDMTimeReadOffset = 1000
 
+	// MDMTimeProveSegment is the time for executing a 'ProveSegment'
+	// instruction.
+	MDMTimeProveSegment = 1000
+
 	// MDMTimeReadSector is the time for executing a 'ReadSector' instruction.
 	MDMTimeReadSector = 1000
 
@@ -78,6 +110,10 @@ const (
 	// MDMTimeSwapSector is the time for executing an 'SwapSector' instruction.
 	MDMTimeSwapSector = 1
 
+	// MDMTimeUpdateSector is the time for executing an 'UpdateSector'
+	// instruction.
+	MDMTimeUpdateSector = 10000
+
 	// MDMTimeWriteSector is the time for executing a 'WriteSector' instruction.
 	MDMTimeWriteSector = 10000
 
@@ -93,6 +129,14 @@ const (
 	// instructon.
 	RPCIAppendLen = 9
 
+	// RPCIAssertRevisionLen is the expected length of the 'Args' of an
+	// AssertRevision instruction.
+	RPCIAssertRevisionLen = 8
+
+	// RPCICopySectorLen is the expected length of the 'Args' of a CopySector
+	// instruction.
+	RPCICopySectorLen = 25 // 3 uint64 offsets + merkle proof flag
+
 	// RPCIDropSectorsLen is the expected length of the 'Args' of a DropSectors
 	// Instruction.
 	RPCIDropSectorsLen = 9
@@ -101,6 +145,14 @@ const (
 	// instruction.
 	RPCIHasSectorLen = 8
 
+	// RPCIIfHasSectorLen is the expected length of the 'Args' of an
+	// IfHasSector instruction.
+	RPCIIfHasSectorLen = 16 // merkle root offset + number of skipped instructions
+
+	// RPCIProveSegmentLen is the expected length of the 'Args' of a
+	// ProveSegment instruction.
+	RPCIProveSegmentLen = 8
+
 	// RPCIReadSectorLen is the expected length of the 'Args' of a ReadSector
 	// instruction.
 	RPCIReadSectorLen = 25
@@ -117,6 +169,10 @@ const (
 	// instructon.
 	RPCISwapSectorLen = 17 // 2 uint64 offsets + merkle proof flag
 
+	// RPCIUpdateSectorLen is the expected length of the 'Args' of an
+	// UpdateSector instruction.
+	RPCIUpdateSectorLen = 33 // 4 uint64 offsets + merkle proof flag
+
 	// RPCIUpdateRegistryLen is the expected length of the 'Args' of an
 	// UpdateRegistry instruction.
 	// tweakOffset + revisionOffset + signatureOffset + pubKeyOffset +
@@ -128,6 +184,14 @@ const (
 	// tweakOffset + pubKeyOffset + pubKeyLength = 3 * 8 bytes = 24 byte
 	RPCIReadRegistryLen = 24
 
+	// RPCIReadRegistryBatchLen is the expected length of the 'Args' of a
+	// ReadRegistryBatch instruction.
+	RPCIReadRegistryBatchLen = 17 // 2 uint64 + needPubKeyAndTweak flag
+
+	// RPCIUpdateRegistryBatchLen is the expected length of the 'Args' of an
+	// UpdateRegistryBatch instruction.
+	RPCIUpdateRegistryBatchLen = 24 // 3 uint64
+
 	// RPCIReadRegistryEIDLen is the expected length of the 'Args' of an
 	// ReadRegistryEID instruction.
 	// sidOffset = 8 bytes + pubkey bool 1 byte
@@ -145,15 +209,38 @@ var (
 		Testing:  3 * time.Second,
 	}).(time.Duration)
 
+	// MDMStreamChunkSize is the size of the chunks of a sector for which the
+	// host sends the Merkle roots before streaming the output of a ReadSector
+	// instruction. It allows the renter to verify the output one chunk at a
+	// time instead of waiting for the whole output and its proof.
+	MDMStreamChunkSize = build.Select(build.Var{
+		Standard: uint64(1 << 16), // 64 KiB
+		Dev:      uint64(1 << 12), // 4 KiB
+		Testing:  uint64(1 << 10), // 1 KiB
+	}).(uint64)
+
 	// SpecifierAppend is the specifier for the Append instruction.
 	SpecifierAppend = InstructionSpecifier{'A', 'p', 'p', 'e', 'n', 'd'}
 
+	// SpecifierAssertRevision is the specifier for the AssertRevision
+	// instruction.
+	SpecifierAssertRevision = InstructionSpecifier{'A', 's', 's', 'e', 'r', 't', 'R', 'e', 'v', 'i', 's', 'i', 'o', 'n'}
+
+	// SpecifierCopySector is the specifier for the CopySector instruction.
+	SpecifierCopySector = InstructionSpecifier{'C', 'o', 'p', 'y', 'S', 'e', 'c', 't', 'o', 'r'}
+
 	// SpecifierDropSectors is the specifier for the DropSectors instruction.
 	SpecifierDropSectors = InstructionSpecifier{'D', 'r', 'o', 'p', 'S', 'e', 'c', 't', 'o', 'r', 's'}
 
 	// SpecifierHasSector is the specifier for the HasSector instruction.
 	SpecifierHasSector = InstructionSpecifier{'H', 'a', 's', 'S', 'e', 'c', 't', 'o', 'r'}
 
+	// SpecifierIfHasSector is the specifier for the IfHasSector instruction.
+	SpecifierIfHasSector = InstructionSpecifier{'I', 'f', 'H', 'a', 's', 'S', 'e', 'c', 't', 'o', 'r'}
+
+	// SpecifierProveSegment is the specifier for the ProveSegment instruction.
+	SpecifierProveSegment = InstructionSpecifier{'P', 'r', 'o', 'v', 'e', 'S', 'e', 'g', 'm', 'e', 'n', 't'}
+
 	// SpecifierReadOffset is the specifier for the ReadOffset instruction.
 	SpecifierReadOffset = InstructionSpecifier{'R', 'e', 'a', 'd', 'O', 'f', 'f', 's', 'e', 't'}
 
@@ -166,6 +253,9 @@ var (
 	// SpecifierSwapSector is the specifier for the SwapSector instruction.
 	SpecifierSwapSector = InstructionSpecifier{'S', 'w', 'a', 'p', 'S', 'e', 'c', 't', 'o', 'r'}
 
+	// SpecifierUpdateSector is the specifier for the UpdateSector instruction.
+	SpecifierUpdateSector = InstructionSpecifier{'U', 'p', 'd', 'a', 't', 'e', 'S', 'e', 'c', 't', 'o', 'r'}
+
 	// SpecifierUpdateRegistry is the specifier for the UpdateRegistry
 	// instruction.
 	SpecifierUpdateRegistry = InstructionSpecifier{'U', 'p', 'd', 'a', 't', 'e', 'R', 'e', 'g', 'i', 's', 't', 'r', 'y'}
@@ -174,6 +264,14 @@ var (
 	// instruction.
 	SpecifierReadRegistry = InstructionSpecifier{'R', 'e', 'a', 'd', 'R', 'e', 'g', 'i', 's', 't', 'r', 'y'}
 
+	// SpecifierReadRegistryBatch is the specifier for the ReadRegistryBatch
+	// instruction.
+	SpecifierReadRegistryBatch = InstructionSpecifier{'R', 'e', 'a', 'd', 'R', 'e', 'g', 'B', 'a', 't', 'c', 'h'}
+
+	// SpecifierUpdateRegistryBatch is the specifier for the
+	// UpdateRegistryBatch instruction.
+	SpecifierUpdateRegistryBatch = InstructionSpecifier{'U', 'p', 'd', 'a', 't', 'e', 'R', 'e', 'g', 'B', 'a', 't', 'c', 'h'}
+
 	// SpecifierReadRegistryEID is the specifier for the ReadRegistryEID
 	// instruction.
 	SpecifierReadRegistryEID = InstructionSpecifier{'R', 'e', 'a', 'd', 'R', 'e', 'g', 'i', 's', 't', 'r', 'y', 'S', 'I', 'D'}
@@ -190,6 +288,11 @@ var (
 	// collateral budget of an MDM program is not sufficient to execute the next
 	// instruction.
 	ErrMDMInsufficientCollateralBudget = errors.New("remaining collateral budget is insufficient")
+
+	// ErrMDMRevisionMismatch is the error returned by an 'AssertRevision'
+	// instruction if the revision number of the contract doesn't match the
+	// expected one.
+	ErrMDMRevisionMismatch = errors.New("contract revision number doesn't match the expected revision number")
 )
 
 type (
@@ -244,11 +347,24 @@ func MDMAppendCost(pt *RPCPriceTable, duration types.BlockHeight) (types.Currenc
 	return writeCost.Add(storeCost), storeCost
 }
 
+// MDMAssertRevisionCost is the cost of executing an 'AssertRevision'
+// instruction. It is priced like a 'Revision' instruction.
+func MDMAssertRevisionCost(pt *RPCPriceTable) types.Currency {
+	return MDMRevisionCost(pt)
+}
+
 // MDMCopyCost is the cost of executing a 'Copy' instruction.
 func MDMCopyCost(pt RPCPriceTable, contractSize uint64) types.Currency {
 	return types.SiacoinPrecision // TODO: figure out good cost
 }
 
+// MDMCopySectorCost is the cost of executing a 'CopySector' instruction. The
+// host doesn't receive the data from the renter but it still needs to write and
+// store a full sector which is why it costs the same as an 'Append'.
+func MDMCopySectorCost(pt *RPCPriceTable, duration types.BlockHeight) (types.Currency, types.Currency) {
+	return MDMAppendCost(pt, duration)
+}
+
 // MDMDropSectorsCost is the cost of executing a 'DropSectors' instruction for a
 // certain number of dropped sectors.
 func MDMDropSectorsCost(pt *RPCPriceTable, numSectorsDropped uint64) types.Currency {
@@ -268,6 +384,18 @@ func MDMHasSectorCost(pt *RPCPriceTable) types.Currency {
 	return cost
 }
 
+// MDMIfHasSectorCost is the cost of executing an 'IfHasSector' instruction.
+// It is priced like a 'HasSector' instruction.
+func MDMIfHasSectorCost(pt *RPCPriceTable) types.Currency {
+	return MDMHasSectorCost(pt)
+}
+
+// MDMProveSegmentCost is the cost of executing a 'ProveSegment' instruction.
+// It is priced like reading a single segment.
+func MDMProveSegmentCost(pt *RPCPriceTable) types.Currency {
+	return MDMReadCost(pt, crypto.SegmentSize)
+}
+
 // MDMReadCost is the cost of executing a 'Read' instruction. It is defined as:
 // 'readBaseCost' + 'readLengthCost' * `readLength`
 func MDMReadCost(pt *RPCPriceTable, readLength uint64) types.Currency {
@@ -286,6 +414,13 @@ func MDMSwapSectorCost(pt *RPCPriceTable) types.Currency {
 	return pt.SwapSectorCost
 }
 
+// MDMUpdateSectorCost is the cost of executing an 'UpdateSector' instruction.
+// It is defined as: 'updateSectorBaseCost' + 'updateSectorLengthCost' *
+// `updateLength`
+func MDMUpdateSectorCost(pt *RPCPriceTable, updateLength uint64) types.Currency {
+	return pt.UpdateSectorLengthCost.Mul64(updateLength).Add(pt.UpdateSectorBaseCost)
+}
+
 // V154MDMUpdateRegistryCost is the cost of executing a 'UpdateRegistry'
 // instruction in host versions 1.5.4 and below.
 func V154MDMUpdateRegistryCost(pt *RPCPriceTable) (_, _ types.Currency) {
@@ -319,6 +454,24 @@ func MDMReadRegistryCost(pt *RPCPriceTable) (_, _ types.Currency) {
 	return writeCost.Add(storeCost), storeCost
 }
 
+// MDMReadRegistryBatchCost is the cost of executing a 'ReadRegistryBatch'
+// instruction for numEntries entries. The second return value is the maximum
+// refund, which the host issues if none of the entries are found. Every entry
+// that isn't found is refunded individually.
+func MDMReadRegistryBatchCost(pt *RPCPriceTable, numEntries uint64) (_, _ types.Currency) {
+	cost, refund := MDMReadRegistryCost(pt)
+	return cost.Mul64(numEntries), refund.Mul64(numEntries)
+}
+
+// MDMUpdateRegistryBatchCost is the cost of executing an 'UpdateRegistryBatch'
+// instruction for numEntries entries. The second return value is the maximum
+// refund, which the host issues if none of the updates succeed. Every update
+// that fails is refunded individually.
+func MDMUpdateRegistryBatchCost(pt *RPCPriceTable, numEntries uint64) (_, _ types.Currency) {
+	cost, refund := MDMUpdateRegistryCost(pt)
+	return cost.Mul64(numEntries), refund.Mul64(numEntries)
+}
+
 // MDMWriteCost is the cost of executing a 'Write' instruction of a certain length.
 func MDMWriteCost(pt *RPCPriceTable, writeLength uint64) types.Currency {
 	// Atomic write size for modern disks is 4kib so we round up.
@@ -371,6 +524,18 @@ func MDMAppendMemory() uint64 {
 	return SectorSize // A full sector is added to the program's memory until the program is finalized.
 }
 
+// MDMAssertRevisionMemory returns the additional memory consumption of an
+// 'AssertRevision' instruction.
+func MDMAssertRevisionMemory() uint64 {
+	return 0 // 'AssertRevision' doesn't hold on to any memory beyond the lifetime of the instruction.
+}
+
+// MDMCopySectorMemory returns the additional memory consumption of a
+// 'CopySector' instruction.
+func MDMCopySectorMemory() uint64 {
+	return SectorSize // The copied sector is added to the program's memory until the program is finalized.
+}
+
 // MDMDropSectorsMemory returns the additional memory consumption of a
 // `DropSectors` instruction
 func MDMDropSectorsMemory() uint64 {
@@ -389,6 +554,18 @@ func MDMHasSectorMemory() uint64 {
 	return 0 // 'HasSector' doesn't hold on to any memory beyond the lifetime of the instruction.
 }
 
+// MDMIfHasSectorMemory returns the additional memory consumption of an
+// 'IfHasSector' instruction.
+func MDMIfHasSectorMemory() uint64 {
+	return 0 // 'IfHasSector' doesn't hold on to any memory beyond the lifetime of the instruction.
+}
+
+// MDMProveSegmentMemory returns the additional memory consumption of a
+// 'ProveSegment' instruction.
+func MDMProveSegmentMemory() uint64 {
+	return 0 // 'ProveSegment' doesn't hold on to any memory beyond the lifetime of the instruction.
+}
+
 // MDMReadMemory returns the additional memory consumption of a 'Read' instruction.
 func MDMReadMemory() uint64 {
 	return 0 // 'Read' doesn't hold on to any memory beyond the lifetime of the instruction.
@@ -406,6 +583,12 @@ func MDMSwapSectorMemory() uint64 {
 	return 0 // 'SwapSector' doesn't hold on to any memory beyond the lifetime of the instruction.
 }
 
+// MDMUpdateSectorMemory returns the additional memory consumption of an
+// 'UpdateSector' instruction.
+func MDMUpdateSectorMemory() uint64 {
+	return SectorSize // The updated sector is added to the program's memory until the program is finalized.
+}
+
 // MDMUpdateRegistryMemory returns the additional memory consumption of a
 // 'UpdateRegistry' instruction.
 func MDMUpdateRegistryMemory() uint64 {
@@ -418,6 +601,18 @@ func MDMReadRegistryMemory() uint64 {
 	return 0 // 'ReadRegistry' doesn't hold on to any memory beyond the lifetime of the instruction.
 }
 
+// MDMReadRegistryBatchMemory returns the additional memory consumption of a
+// 'ReadRegistryBatch' instruction.
+func MDMReadRegistryBatchMemory() uint64 {
+	return 0 // 'ReadRegistryBatch' doesn't hold on to any memory beyond the lifetime of the instruction.
+}
+
+// MDMUpdateRegistryBatchMemory returns the additional memory consumption of an
+// 'UpdateRegistryBatch' instruction.
+func MDMUpdateRegistryBatchMemory() uint64 {
+	return 0 // 'UpdateRegistryBatch' doesn't hold on to any memory beyond the lifetime of the instruction.
+}
+
 // MDMBandwidthCost computes the total bandwidth cost given a price table and
 // used up- and download bandwidth.
 func MDMBandwidthCost(pt RPCPriceTable, uploadBandwidth, downloadBandwidth uint64) types.Currency {
@@ -437,12 +632,36 @@ func MDMDropSectorsTime(numSectorsDropped uint64) uint64 {
 	return MDMTimeDropSectorsBase + MDMTimeDropSingleSector*numSectorsDropped
 }
 
+// MDMReadRegistryBatchTime returns the time for a 'ReadRegistryBatch'
+// instruction given `numEntries`.
+func MDMReadRegistryBatchTime(numEntries uint64) uint64 {
+	return MDMTimeReadRegistry * numEntries
+}
+
+// MDMUpdateRegistryBatchTime returns the time for an 'UpdateRegistryBatch'
+// instruction given `numEntries`.
+func MDMUpdateRegistryBatchTime(numEntries uint64) uint64 {
+	return MDMTimeUpdateRegistry * numEntries
+}
+
 // MDMAppendCollateral returns the additional collateral a 'Append' instruction
 // requires the host to put up.
 func MDMAppendCollateral(pt *RPCPriceTable) types.Currency {
 	return pt.CollateralCost.Mul64(SectorSize)
 }
 
+// MDMAssertRevisionCollateral returns the additional collateral an
+// 'AssertRevision' instruction requires the host to put up.
+func MDMAssertRevisionCollateral() types.Currency {
+	return types.ZeroCurrency
+}
+
+// MDMCopySectorCollateral returns the additional collateral a 'CopySector'
+// instruction requires the host to put up.
+func MDMCopySectorCollateral(pt *RPCPriceTable) types.Currency {
+	return MDMAppendCollateral(pt)
+}
+
 // MDMDropSectorsCollateral returns the additional collateral a 'DropSectors'
 // instruction requires the host to put up.
 func MDMDropSectorsCollateral() types.Currency {
@@ -455,6 +674,18 @@ func MDMHasSectorCollateral() types.Currency {
 	return types.ZeroCurrency
 }
 
+// MDMIfHasSectorCollateral returns the additional collateral an
+// 'IfHasSector' instruction requires the host to put up.
+func MDMIfHasSectorCollateral() types.Currency {
+	return types.ZeroCurrency
+}
+
+// MDMProveSegmentCollateral returns the additional collateral a
+// 'ProveSegment' instruction requires the host to put up.
+func MDMProveSegmentCollateral() types.Currency {
+	return types.ZeroCurrency
+}
+
 // MDMReadCollateral returns the additional collateral a 'Read' instruction
 // requires the host to put up.
 func MDMReadCollateral() types.Currency {
@@ -473,6 +704,12 @@ func MDMSwapSectorCollateral() types.Currency {
 	return types.ZeroCurrency
 }
 
+// MDMUpdateSectorCollateral returns the additional collateral an
+// 'UpdateSector' instruction requires the host to put up.
+func MDMUpdateSectorCollateral() types.Currency {
+	return types.ZeroCurrency // The size of the contract doesn't change.
+}
+
 // MDMUpdateRegistryCollateral returns the additional collateral a
 // 'UpdateRegistry' instruction requires the host to put up.
 func MDMUpdateRegistryCollateral() types.Currency {
@@ -485,24 +722,46 @@ func MDMReadRegistryCollateral() types.Currency {
 	return types.ZeroCurrency
 }
 
+// MDMReadRegistryBatchCollateral returns the additional collateral a
+// 'ReadRegistryBatch' instruction requires the host to put up.
+func MDMReadRegistryBatchCollateral() types.Currency {
+	return types.ZeroCurrency
+}
+
+// MDMUpdateRegistryBatchCollateral returns the additional collateral an
+// 'UpdateRegistryBatch' instruction requires the host to put up.
+func MDMUpdateRegistryBatchCollateral() types.Currency {
+	return types.ZeroCurrency
+}
+
 // ReadOnly returns true if the program consists of no write instructions.
 func (p Program) ReadOnly() bool {
 	for _, instruction := range p {
 		switch instruction.Specifier {
 		case SpecifierAppend:
 			return false
+		case SpecifierAssertRevision:
+		case SpecifierCopySector:
+			return false
 		case SpecifierDropSectors:
 			return false
 		case SpecifierHasSector:
+		case SpecifierIfHasSector:
+		case SpecifierProveSegment:
 		case SpecifierReadOffset:
 		case SpecifierReadSector:
 		case SpecifierRevision:
 		case SpecifierSwapSector:
 			return false
+		case SpecifierUpdateSector:
+			return false
 		case SpecifierUpdateRegistry:
 			// considered read-only cause it doesn't update a contract
+		case SpecifierUpdateRegistryBatch:
+			// considered read-only cause it doesn't update a contract
 		case SpecifierReadRegistry:
 		case SpecifierReadRegistryEID:
+		case SpecifierReadRegistryBatch:
 		default:
 			build.Critical("ReadOnly: unknown instruction")
 		}
@@ -519,9 +778,16 @@ func (p Program) RequiresSnapshot() bool {
 		switch instruction.Specifier {
 		case SpecifierAppend:
 			return true
+		case SpecifierAssertRevision:
+			return true
+		case SpecifierCopySector:
+			return true
 		case SpecifierDropSectors:
 			return true
 		case SpecifierHasSector:
+		case SpecifierIfHasSector:
+		case SpecifierProveSegment:
+			return true
 		case SpecifierReadOffset:
 			return true
 		case SpecifierReadSector:
@@ -529,9 +795,13 @@ func (p Program) RequiresSnapshot() bool {
 			return true
 		case SpecifierSwapSector:
 			return true
+		case SpecifierUpdateSector:
+			return true
 		case SpecifierUpdateRegistry:
+		case SpecifierUpdateRegistryBatch:
 		case SpecifierReadRegistry:
 		case SpecifierReadRegistryEID:
+		case SpecifierReadRegistryBatch:
 		default:
 			build.Critical("RequiresSnapshot: unknown instruction")
 		}
@@ -539,6 +809,26 @@ func (p Program) RequiresSnapshot() bool {
 	return false
 }
 
+// CopySectorSigHash returns the hash a renter needs to sign with the renter key
+// of the source contract to authorize the host to copy the sector with the
+// given root from the source contract into the destination contract.
+func CopySectorSigHash(srcID, dstID types.FileContractID, root crypto.Hash) crypto.Hash {
+	return crypto.HashAll(SpecifierCopySector, srcID, dstID, root)
+}
+
+// ProveSegmentIndex returns the index of the segment a 'ProveSegment'
+// instruction proves for a contract with numSegments segments given the
+// renter-supplied entropy. The index is chosen the same way the consensus
+// chooses the segment of a storage proof.
+func ProveSegmentIndex(entropy crypto.Hash, numSegments uint64) uint64 {
+	if numSegments == 0 {
+		return 0
+	}
+	seed := new(big.Int).SetBytes(entropy[:])
+	index := new(big.Int).Mod(seed, new(big.Int).SetUint64(numSegments))
+	return index.Uint64()
+}
+
 // RPCBudget is a helper type for threadsafe budget handling.
 type RPCBudget struct {
 	budget types.Currency
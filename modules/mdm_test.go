@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/types"
 )
 
@@ -196,6 +198,16 @@ func TestRequiresReadonlyAndSnapshot(t *testing.T) {
 			false,
 			true,
 		},
+		{
+			SpecifierAssertRevision,
+			true,
+			true,
+		},
+		{
+			SpecifierCopySector,
+			false,
+			true,
+		},
 		{
 			SpecifierDropSectors,
 			false,
@@ -206,6 +218,16 @@ func TestRequiresReadonlyAndSnapshot(t *testing.T) {
 			true,
 			false,
 		},
+		{
+			SpecifierIfHasSector,
+			true,
+			false,
+		},
+		{
+			SpecifierProveSegment,
+			true,
+			true,
+		},
 		{
 			SpecifierReadOffset,
 			true,
@@ -226,6 +248,11 @@ func TestRequiresReadonlyAndSnapshot(t *testing.T) {
 			false,
 			true,
 		},
+		{
+			SpecifierUpdateSector,
+			false,
+			true,
+		},
 	}
 
 	for i, test := range tests {
@@ -240,3 +267,25 @@ func TestRequiresReadonlyAndSnapshot(t *testing.T) {
 		}
 	}
 }
+
+// TestProveSegmentIndex is a unit test for ProveSegmentIndex.
+func TestProveSegmentIndex(t *testing.T) {
+	// The index should always be within bounds.
+	for i := 0; i < 100; i++ {
+		var entropy crypto.Hash
+		fastrand.Read(entropy[:])
+		numSegments := fastrand.Uint64n(1000) + 1
+		if index := ProveSegmentIndex(entropy, numSegments); index >= numSegments {
+			t.Fatalf("index %v out of bounds for %v segments", index, numSegments)
+		}
+	}
+	// A single segment always results in index 0.
+	if index := ProveSegmentIndex(crypto.Hash{1, 2, 3}, 1); index != 0 {
+		t.Fatal("expected index 0 but got", index)
+	}
+	// The index is deterministic.
+	entropy := crypto.Hash{1}
+	if ProveSegmentIndex(entropy, 100) != ProveSegmentIndex(entropy, 100) {
+		t.Fatal("index isn't deterministic")
+	}
+}
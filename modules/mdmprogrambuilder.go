@@ -65,6 +65,51 @@ func (pb *ProgramBuilder) AddAppendInstruction(data []byte, merkleProof bool) er
 	return nil
 }
 
+// AddAssertRevisionInstruction adds an AssertRevision instruction to the
+// program. The program is aborted if the revision number of the contract
+// doesn't match revisionNumber when the instruction is executed.
+func (pb *ProgramBuilder) AddAssertRevisionInstruction(revisionNumber uint64) {
+	// Compute the argument offsets.
+	revisionNumberOffset := uint64(pb.programData.Len())
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, revisionNumber)
+	// Create the instruction.
+	i := NewAssertRevisionInstruction(revisionNumberOffset)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMAssertRevisionCollateral()
+	cost := MDMAssertRevisionCost(pb.staticPT)
+	memory := MDMAssertRevisionMemory()
+	time := uint64(MDMTimeAssertRevision)
+	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
+}
+
+// AddCopySectorInstruction adds a CopySector instruction to the program. The
+// signature needs to be created by the renter key of the source contract over
+// the hash returned by CopySectorSigHash.
+func (pb *ProgramBuilder) AddCopySectorInstruction(srcID types.FileContractID, root crypto.Hash, sig crypto.Signature, merkleProof bool) {
+	// Compute the argument offsets.
+	srcIDOffset := uint64(pb.programData.Len())
+	rootOffset := srcIDOffset + crypto.HashSize
+	sigOffset := rootOffset + crypto.HashSize
+	// Extend the programData.
+	pb.programData.Write(srcID[:])
+	pb.programData.Write(root[:])
+	pb.programData.Write(sig[:])
+	// Create the instruction.
+	i := NewCopySectorInstruction(srcIDOffset, rootOffset, sigOffset, merkleProof)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMCopySectorCollateral(pb.staticPT)
+	cost, refund := MDMCopySectorCost(pb.staticPT, pb.staticDuration)
+	memory := MDMCopySectorMemory()
+	time := uint64(MDMTimeCopySector)
+	pb.addInstruction(collateral, cost, refund, memory, time)
+	pb.readonly = false
+}
+
 // AddDropSectorsInstruction adds a DropSectors instruction to the program.
 func (pb *ProgramBuilder) AddDropSectorsInstruction(numSectors uint64, merkleProof bool) {
 	// Compute the argument offsets.
@@ -102,6 +147,46 @@ func (pb *ProgramBuilder) AddHasSectorInstruction(merkleRoot crypto.Hash) {
 	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
 }
 
+// AddIfHasSectorInstruction adds an IfHasSector instruction to the program.
+// Unless the host has the sector with the given root, the next numSkip
+// instructions are skipped and refunded. Since the builder can't know whether
+// the instructions will be skipped, they are still included in the program's
+// cost.
+func (pb *ProgramBuilder) AddIfHasSectorInstruction(merkleRoot crypto.Hash, numSkip uint64) {
+	// Compute the argument offsets.
+	merkleRootOffset := uint64(pb.programData.Len())
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, merkleRoot[:])
+	// Create the instruction.
+	i := NewIfHasSectorInstruction(merkleRootOffset, numSkip)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMIfHasSectorCollateral()
+	cost := MDMIfHasSectorCost(pb.staticPT)
+	memory := MDMIfHasSectorMemory()
+	time := uint64(MDMTimeIfHasSector)
+	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
+}
+
+// AddProveSegmentInstruction adds a ProveSegment instruction to the program.
+func (pb *ProgramBuilder) AddProveSegmentInstruction(entropy crypto.Hash) {
+	// Compute the argument offsets.
+	entropyOffset := uint64(pb.programData.Len())
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, entropy[:])
+	// Create the instruction.
+	i := NewProveSegmentInstruction(entropyOffset)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMProveSegmentCollateral()
+	cost := MDMProveSegmentCost(pb.staticPT)
+	memory := MDMProveSegmentMemory()
+	time := uint64(MDMTimeProveSegment)
+	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
+}
+
 // AddReadOffsetInstruction adds a ReadOffset instruction to the program.
 func (pb *ProgramBuilder) AddReadOffsetInstruction(length, offset uint64, merkleProof bool) {
 	// Compute the argument offsets.
@@ -181,6 +266,39 @@ func (pb *ProgramBuilder) AddSwapSectorInstruction(sector1Idx, sector2Idx uint64
 	pb.readonly = false
 }
 
+// AddUpdateSectorInstruction adds an UpdateSector instruction to the program.
+func (pb *ProgramBuilder) AddUpdateSectorInstruction(sectorIdx, offset uint64, data []byte, merkleProof bool) error {
+	length := uint64(len(data))
+	if length == 0 {
+		return errors.New("update data cannot be empty")
+	}
+	if offset > SectorSize || length > SectorSize-offset {
+		return fmt.Errorf("update is out of bounds %v + %v > %v", offset, length, SectorSize)
+	}
+	// Compute the argument offsets.
+	sectorIdxOffset := uint64(pb.programData.Len())
+	offsetOffset := sectorIdxOffset + 8
+	lengthOffset := offsetOffset + 8
+	dataOffset := lengthOffset + 8
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, sectorIdx)
+	binary.Write(pb.programData, binary.LittleEndian, offset)
+	binary.Write(pb.programData, binary.LittleEndian, length)
+	binary.Write(pb.programData, binary.LittleEndian, data)
+	// Create the instruction.
+	i := NewUpdateSectorInstruction(sectorIdxOffset, offsetOffset, lengthOffset, dataOffset, merkleProof)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMUpdateSectorCollateral()
+	cost := MDMUpdateSectorCost(pb.staticPT, length)
+	memory := MDMUpdateSectorMemory()
+	time := uint64(MDMTimeUpdateSector)
+	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
+	pb.readonly = false
+	return nil
+}
+
 // AddUpdateRegistryInstruction adds an UpdateRegistry instruction to the program.
 func (pb *ProgramBuilder) AddUpdateRegistryInstruction(spk types.SiaPublicKey, rv SignedRegistryValue) error {
 	// Marshal pubKey.
@@ -330,6 +448,67 @@ func (pb *ProgramBuilder) AddReadRegistryEIDInstruction(sid RegistryEntryID, nee
 	return refund, nil
 }
 
+// AddReadRegistryBatchInstruction adds a ReadRegistryBatch instruction to the
+// program. The returned currency is the refund the host issues for every entry
+// it doesn't find.
+func (pb *ProgramBuilder) AddReadRegistryBatchInstruction(sids []RegistryEntryID, needPKAndTweak bool) (types.Currency, error) {
+	if len(sids) == 0 {
+		return types.ZeroCurrency, errors.New("AddReadRegistryBatchInstruction: no entries to read")
+	}
+	// Compute the argument offsets.
+	sidsOff := uint64(pb.programData.Len())
+	numEntries := uint64(len(sids))
+	// Extend the programData.
+	for _, sid := range sids {
+		_, err := pb.programData.Write(sid[:])
+		if err != nil {
+			return types.ZeroCurrency, errors.AddContext(err, "AddReadRegistryBatchInstruction: failed to extend programData")
+		}
+	}
+	// Create the instruction.
+	i := NewReadRegistryBatchInstruction(sidsOff, numEntries, needPKAndTweak)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Read cost, collateral and memory usage.
+	collateral := MDMReadRegistryBatchCollateral()
+	cost, refund := MDMReadRegistryBatchCost(pb.staticPT, numEntries)
+	memory := MDMReadRegistryBatchMemory()
+	time := MDMReadRegistryBatchTime(numEntries)
+	pb.addInstruction(collateral, cost, refund, memory, time)
+	return refund.Div64(numEntries), nil
+}
+
+// AddUpdateRegistryBatchInstruction adds an UpdateRegistryBatch instruction to
+// the program. The returned currency is the refund the host issues for every
+// update that fails.
+func (pb *ProgramBuilder) AddUpdateRegistryBatchInstruction(updates []RegistryBatchUpdate) (types.Currency, error) {
+	if len(updates) == 0 {
+		return types.ZeroCurrency, errors.New("AddUpdateRegistryBatchInstruction: no entries to update")
+	}
+	// Marshal the updates.
+	entries := encoding.Marshal(updates)
+	// Compute the argument offsets.
+	entriesOff := uint64(pb.programData.Len())
+	entriesLen := uint64(len(entries))
+	numEntries := uint64(len(updates))
+	// Extend the programData.
+	_, err := pb.programData.Write(entries)
+	if err != nil {
+		return types.ZeroCurrency, errors.AddContext(err, "AddUpdateRegistryBatchInstruction: failed to extend programData")
+	}
+	// Create the instruction.
+	i := NewUpdateRegistryBatchInstruction(entriesOff, entriesLen, numEntries)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMUpdateRegistryBatchCollateral()
+	cost, refund := MDMUpdateRegistryBatchCost(pb.staticPT, numEntries)
+	memory := MDMUpdateRegistryBatchMemory()
+	time := MDMUpdateRegistryBatchTime(numEntries)
+	pb.addInstruction(collateral, cost, refund, memory, time)
+	return refund.Div64(numEntries), nil
+}
+
 // Cost returns the current cost of the program being built by the builder. If
 // 'finalized' is 'true', the memory cost of finalizing the program is included.
 func (pb *ProgramBuilder) Cost(finalized bool) (cost, storage, collateral types.Currency) {
@@ -378,6 +557,31 @@ func NewAppendInstruction(dataOffset uint64, merkleProof bool) Instruction {
 	return i
 }
 
+// NewAssertRevisionInstruction creates an Instruction from arguments.
+func NewAssertRevisionInstruction(revisionNumberOffset uint64) Instruction {
+	i := Instruction{
+		Specifier: SpecifierAssertRevision,
+		Args:      make([]byte, RPCIAssertRevisionLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], revisionNumberOffset)
+	return i
+}
+
+// NewCopySectorInstruction creates an Instruction from arguments.
+func NewCopySectorInstruction(srcIDOffset, rootOffset, sigOffset uint64, merkleProof bool) Instruction {
+	i := Instruction{
+		Specifier: SpecifierCopySector,
+		Args:      make([]byte, RPCICopySectorLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], srcIDOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], rootOffset)
+	binary.LittleEndian.PutUint64(i.Args[16:24], sigOffset)
+	if merkleProof {
+		i.Args[24] = 1
+	}
+	return i
+}
+
 // NewUpdateRegistryInstruction creates an Instruction from arguments.
 func NewUpdateRegistryInstruction(tweakOff, revisionOff, signatureOff, pubKeyOff, pubKeyLen, dataOff, dataLen uint64) Instruction {
 	i := Instruction{
@@ -419,6 +623,32 @@ func NewReadRegistryEIDInstruction(sidOffset uint64, needPKAndTweakOffset bool)
 	return i
 }
 
+// NewReadRegistryBatchInstruction creates an Instruction from arguments.
+func NewReadRegistryBatchInstruction(sidsOffset, numEntries uint64, needPKAndTweak bool) Instruction {
+	i := Instruction{
+		Specifier: SpecifierReadRegistryBatch,
+		Args:      make([]byte, RPCIReadRegistryBatchLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], sidsOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], numEntries)
+	if needPKAndTweak {
+		i.Args[16] = 1
+	}
+	return i
+}
+
+// NewUpdateRegistryBatchInstruction creates an Instruction from arguments.
+func NewUpdateRegistryBatchInstruction(entriesOffset, entriesLength, numEntries uint64) Instruction {
+	i := Instruction{
+		Specifier: SpecifierUpdateRegistryBatch,
+		Args:      make([]byte, RPCIUpdateRegistryBatchLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], entriesOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], entriesLength)
+	binary.LittleEndian.PutUint64(i.Args[16:24], numEntries)
+	return i
+}
+
 // NewDropSectorsInstruction creates an Instruction from arguments.
 func NewDropSectorsInstruction(numSectorsOffset uint64, merkleProof bool) Instruction {
 	i := Instruction{
@@ -442,6 +672,27 @@ func NewHasSectorInstruction(merkleRootOffset uint64) Instruction {
 	return i
 }
 
+// NewIfHasSectorInstruction creates a modules.Instruction from arguments.
+func NewIfHasSectorInstruction(merkleRootOffset, numSkip uint64) Instruction {
+	i := Instruction{
+		Specifier: SpecifierIfHasSector,
+		Args:      make([]byte, RPCIIfHasSectorLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], merkleRootOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], numSkip)
+	return i
+}
+
+// NewProveSegmentInstruction creates a modules.Instruction from arguments.
+func NewProveSegmentInstruction(entropyOffset uint64) Instruction {
+	i := Instruction{
+		Specifier: SpecifierProveSegment,
+		Args:      make([]byte, RPCIProveSegmentLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], entropyOffset)
+	return i
+}
+
 // NewReadOffsetInstruction creates a modules.Instruction from arguments.
 func NewReadOffsetInstruction(lengthOffset, offsetOffset uint64, merkleProof bool) Instruction {
 	i := Instruction{
@@ -485,6 +736,22 @@ func NewSwapSectorInstruction(sector1Offset, sector2Offset uint64, merkleProof b
 	return i
 }
 
+// NewUpdateSectorInstruction creates a modules.Instruction from arguments.
+func NewUpdateSectorInstruction(sectorIdxOffset, offsetOffset, lengthOffset, dataOffset uint64, merkleProof bool) Instruction {
+	i := Instruction{
+		Specifier: SpecifierUpdateSector,
+		Args:      make([]byte, RPCIUpdateSectorLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], sectorIdxOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], offsetOffset)
+	binary.LittleEndian.PutUint64(i.Args[16:24], lengthOffset)
+	binary.LittleEndian.PutUint64(i.Args[24:32], dataOffset)
+	if merkleProof {
+		i.Args[32] = 1
+	}
+	return i
+}
+
 // NewRevisionInstruction creates a modules.Instruction from arguments.
 func NewRevisionInstruction(merkleRootOffset uint64) Instruction {
 	return Instruction{
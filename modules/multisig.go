@@ -0,0 +1,263 @@
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// ErrMultisigTransactionIncomplete is returned when a partially-signed
+	// transaction is broadcast before all of its inputs were signed.
+	ErrMultisigTransactionIncomplete = errors.New("partially-signed transaction is still missing signatures")
+
+	// ErrMultisigTransactionMismatch is returned when partially-signed
+	// transactions that don't spend the same outputs to the same outputs are
+	// combined.
+	ErrMultisigTransactionMismatch = errors.New("partially-signed transactions don't describe the same transaction")
+)
+
+type (
+	// A PartiallySignedTransaction is a transaction that is passed between
+	// the owners of the keys of a multisig address until it has collected
+	// enough signatures to be broadcast. Inputs describes which signatures
+	// every input of the transaction is still missing.
+	PartiallySignedTransaction struct {
+		Transaction types.Transaction      `json:"transaction"`
+		Inputs      []PartiallySignedInput `json:"inputs"`
+	}
+
+	// A PartiallySignedInput describes the signatures that an input of a
+	// PartiallySignedTransaction is still missing. The signatures need to
+	// cover CoveredFields and any MissingSignatures of the UnsignedKeys may
+	// provide them.
+	PartiallySignedInput struct {
+		ParentID          crypto.Hash          `json:"parentid"`
+		CoveredFields     types.CoveredFields  `json:"coveredfields"`
+		MissingSignatures uint64               `json:"missingsignatures"`
+		UnsignedKeys      []types.SiaPublicKey `json:"unsignedkeys"`
+	}
+)
+
+// NewMultisigUnlockConditions returns the unlock conditions of an address that
+// requires 'required' signatures of the given keys to be spent.
+func NewMultisigUnlockConditions(keys []types.SiaPublicKey, required uint64) (types.UnlockConditions, error) {
+	if required == 0 {
+		return types.UnlockConditions{}, errors.New("a multisig address requires at least one signature")
+	}
+	if required > uint64(len(keys)) {
+		return types.UnlockConditions{}, fmt.Errorf("can't require %v signatures from %v keys", required, len(keys))
+	}
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if key.Algorithm != types.SignatureEd25519 || len(key.Key) != crypto.PublicKeySize {
+			return types.UnlockConditions{}, fmt.Errorf("%v is not a valid ed25519 public key", key)
+		}
+		if _, exists := seen[key.String()]; exists {
+			return types.UnlockConditions{}, fmt.Errorf("public key %v was provided more than once", key)
+		}
+		seen[key.String()] = struct{}{}
+	}
+	return types.UnlockConditions{
+		PublicKeys:         append([]types.SiaPublicKey(nil), keys...),
+		SignaturesRequired: required,
+	}, nil
+}
+
+// NewPartiallySignedTransaction creates a PartiallySignedTransaction which
+// requires every input of txn to be signed with the given covered fields.
+// Signatures that txn already contains are verified at the given height.
+func NewPartiallySignedTransaction(txn types.Transaction, cf types.CoveredFields, height types.BlockHeight) (PartiallySignedTransaction, error) {
+	pst := PartiallySignedTransaction{Transaction: txn}
+	for _, sci := range txn.SiacoinInputs {
+		pst.Inputs = append(pst.Inputs, PartiallySignedInput{
+			ParentID:      crypto.Hash(sci.ParentID),
+			CoveredFields: cf,
+		})
+	}
+	for _, sfi := range txn.SiafundInputs {
+		pst.Inputs = append(pst.Inputs, PartiallySignedInput{
+			ParentID:      crypto.Hash(sfi.ParentID),
+			CoveredFields: cf,
+		})
+	}
+	return pst, pst.UpdateInputs(height)
+}
+
+// Complete returns true if none of the transaction's inputs are missing
+// signatures.
+func (pst PartiallySignedTransaction) Complete() bool {
+	for _, input := range pst.Inputs {
+		if input.MissingSignatures > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// UnlockConditions returns the unlock conditions of the input with the given
+// parent ID.
+func (pst PartiallySignedTransaction) UnlockConditions(parentID crypto.Hash) (types.UnlockConditions, bool) {
+	for _, sci := range pst.Transaction.SiacoinInputs {
+		if crypto.Hash(sci.ParentID) == parentID {
+			return sci.UnlockConditions, true
+		}
+	}
+	for _, sfi := range pst.Transaction.SiafundInputs {
+		if crypto.Hash(sfi.ParentID) == parentID {
+			return sfi.UnlockConditions, true
+		}
+	}
+	return types.UnlockConditions{}, false
+}
+
+// UpdateInputs recomputes the missing signatures of the inputs from the
+// signatures of the transaction. It returns an error if the inputs don't
+// describe every input of the transaction exactly once or if any of the
+// signatures isn't a valid signature of the input's key at the given height.
+func (pst *PartiallySignedTransaction) UpdateInputs(height types.BlockHeight) error {
+	numInputs := len(pst.Transaction.SiacoinInputs) + len(pst.Transaction.SiafundInputs)
+	if len(pst.Inputs) != numInputs {
+		return fmt.Errorf("partially-signed transaction describes %v inputs but the transaction has %v", len(pst.Inputs), numInputs)
+	}
+	if err := pst.Transaction.ValidCoveredFields(); err != nil {
+		return err
+	}
+	seen := make(map[crypto.Hash]struct{}, len(pst.Inputs))
+	var numSigs int
+	for i := range pst.Inputs {
+		input := &pst.Inputs[i]
+		if _, exists := seen[input.ParentID]; exists {
+			return fmt.Errorf("input %v is described more than once", input.ParentID)
+		}
+		seen[input.ParentID] = struct{}{}
+		uc, exists := pst.UnlockConditions(input.ParentID)
+		if !exists {
+			return fmt.Errorf("input %v is not part of the transaction", input.ParentID)
+		}
+
+		// Collect the keys that already signed the input.
+		signed := make(map[uint64]struct{})
+		for sigIndex, sig := range pst.Transaction.TransactionSignatures {
+			if sig.ParentID != input.ParentID {
+				continue
+			}
+			if err := pst.verifySignature(sigIndex, uc, input.CoveredFields, height); err != nil {
+				return fmt.Errorf("invalid signature for input %v: %v", input.ParentID, err)
+			}
+			if _, exists := signed[sig.PublicKeyIndex]; exists {
+				return fmt.Errorf("input %v is signed more than once by key %v", input.ParentID, sig.PublicKeyIndex)
+			}
+			signed[sig.PublicKeyIndex] = struct{}{}
+			numSigs++
+		}
+		input.MissingSignatures = 0
+		input.UnsignedKeys = nil
+		if uint64(len(signed)) >= uc.SignaturesRequired {
+			continue
+		}
+		input.MissingSignatures = uc.SignaturesRequired - uint64(len(signed))
+		for j, key := range uc.PublicKeys {
+			if _, exists := signed[uint64(j)]; !exists {
+				input.UnsignedKeys = append(input.UnsignedKeys, key)
+			}
+		}
+	}
+	if numSigs != len(pst.Transaction.TransactionSignatures) {
+		return errors.New("transaction contains signatures for unknown inputs")
+	}
+	return nil
+}
+
+// verifySignature checks that the signature with the given index covers cf
+// and was created by the key of the unlock conditions it refers to.
+func (pst PartiallySignedTransaction) verifySignature(sigIndex int, uc types.UnlockConditions, cf types.CoveredFields, height types.BlockHeight) error {
+	sig := pst.Transaction.TransactionSignatures[sigIndex]
+	if sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)) {
+		return fmt.Errorf("key index %v is out of range for %v keys", sig.PublicKeyIndex, len(uc.PublicKeys))
+	}
+	if !bytes.Equal(encoding.Marshal(sig.CoveredFields), encoding.Marshal(cf)) {
+		return errors.New("signature doesn't cover the required fields")
+	}
+	key := uc.PublicKeys[sig.PublicKeyIndex]
+	if key.Algorithm != types.SignatureEd25519 || len(key.Key) != crypto.PublicKeySize {
+		return fmt.Errorf("%v is not a valid ed25519 public key", key)
+	}
+	if len(sig.Signature) != crypto.SignatureSize {
+		return errors.New("signature has the wrong length")
+	}
+	var cryptoSig crypto.Signature
+	copy(cryptoSig[:], sig.Signature)
+	return crypto.VerifyHash(pst.Transaction.SigHash(sigIndex, height), key.ToPublicKey(), cryptoSig)
+}
+
+// CombinePartiallySignedTransactions merges the signatures of multiple
+// partially-signed versions of the same transaction. Signatures are only
+// added as long as the input they sign is still missing signatures. The
+// signatures are verified at the given height.
+func CombinePartiallySignedTransactions(psts []PartiallySignedTransaction, height types.BlockHeight) (PartiallySignedTransaction, error) {
+	if len(psts) == 0 {
+		return PartiallySignedTransaction{}, errors.New("no partially-signed transactions to combine")
+	}
+	combined := psts[0]
+	combined.Transaction.TransactionSignatures = append([]types.TransactionSignature(nil), combined.Transaction.TransactionSignatures...)
+	combined.Inputs = append([]PartiallySignedInput(nil), combined.Inputs...)
+	if err := combined.UpdateInputs(height); err != nil {
+		return PartiallySignedTransaction{}, err
+	}
+	for _, pst := range psts[1:] {
+		if !pst.sameTransaction(combined) {
+			return PartiallySignedTransaction{}, ErrMultisigTransactionMismatch
+		}
+		for _, sig := range pst.Transaction.TransactionSignatures {
+			if combined.hasSignature(sig.ParentID, sig.PublicKeyIndex) {
+				continue
+			}
+			for _, input := range combined.Inputs {
+				if input.ParentID == sig.ParentID && input.MissingSignatures > 0 {
+					combined.Transaction.TransactionSignatures = append(combined.Transaction.TransactionSignatures, sig)
+					break
+				}
+			}
+			if err := combined.UpdateInputs(height); err != nil {
+				return PartiallySignedTransaction{}, err
+			}
+		}
+	}
+	return combined, nil
+}
+
+// hasSignature returns true if the transaction contains a signature of the
+// key with the given index for the input with the given parent ID.
+func (pst PartiallySignedTransaction) hasSignature(parentID crypto.Hash, keyIndex uint64) bool {
+	for _, sig := range pst.Transaction.TransactionSignatures {
+		if sig.ParentID == parentID && sig.PublicKeyIndex == keyIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTransaction returns true if both partially-signed transactions describe
+// the same transaction apart from the signatures.
+func (pst PartiallySignedTransaction) sameTransaction(other PartiallySignedTransaction) bool {
+	txn, otherTxn := pst.Transaction, other.Transaction
+	txn.TransactionSignatures, otherTxn.TransactionSignatures = nil, nil
+	if !bytes.Equal(encoding.Marshal(txn), encoding.Marshal(otherTxn)) {
+		return false
+	}
+	if len(pst.Inputs) != len(other.Inputs) {
+		return false
+	}
+	for i := range pst.Inputs {
+		if pst.Inputs[i].ParentID != other.Inputs[i].ParentID ||
+			!bytes.Equal(encoding.Marshal(pst.Inputs[i].CoveredFields), encoding.Marshal(other.Inputs[i].CoveredFields)) {
+			return false
+		}
+	}
+	return true
+}
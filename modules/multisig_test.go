@@ -0,0 +1,192 @@
+package modules
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// TestNewMultisigUnlockConditions tests creating the unlock conditions of a
+// multisig address.
+func TestNewMultisigUnlockConditions(t *testing.T) {
+	var keys []types.SiaPublicKey
+	for i := 0; i < 3; i++ {
+		_, pk := crypto.GenerateKeyPair()
+		keys = append(keys, types.Ed25519PublicKey(pk))
+	}
+
+	// Valid 2-of-3.
+	uc, err := NewMultisigUnlockConditions(keys, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.SignaturesRequired != 2 || len(uc.PublicKeys) != 3 || uc.Timelock != 0 {
+		t.Fatal("wrong unlock conditions", uc)
+	}
+
+	// Invalid numbers of required signatures.
+	if _, err := NewMultisigUnlockConditions(keys, 0); err == nil {
+		t.Fatal("expected error for 0 required signatures")
+	}
+	if _, err := NewMultisigUnlockConditions(keys, 4); err == nil {
+		t.Fatal("expected error for more required signatures than keys")
+	}
+
+	// Duplicate key.
+	if _, err := NewMultisigUnlockConditions(append(keys, keys[0]), 2); err == nil {
+		t.Fatal("expected error for duplicate key")
+	}
+
+	// Invalid key.
+	invalid := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: fastrand.Bytes(10)}
+	if _, err := NewMultisigUnlockConditions(append(keys, invalid), 2); err == nil {
+		t.Fatal("expected error for invalid key")
+	}
+}
+
+// TestPartiallySignedTransaction tests signing and combining a
+// PartiallySignedTransaction which spends the output of a 2-of-3 multisig
+// address.
+func TestPartiallySignedTransaction(t *testing.T) {
+	var sks []crypto.SecretKey
+	var keys []types.SiaPublicKey
+	for i := 0; i < 3; i++ {
+		sk, pk := crypto.GenerateKeyPair()
+		sks = append(sks, sk)
+		keys = append(keys, types.Ed25519PublicKey(pk))
+	}
+	uc, err := NewMultisigUnlockConditions(keys, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parentID types.SiacoinOutputID
+	fastrand.Read(parentID[:])
+	txn := types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         parentID,
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			Value: types.SiacoinPrecision,
+		}},
+	}
+	pst, err := NewPartiallySignedTransaction(txn, types.FullCoveredFields, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both signatures should be missing.
+	if len(pst.Inputs) != 1 {
+		t.Fatal("wrong number of inputs", len(pst.Inputs))
+	}
+	if pst.Complete() || pst.Inputs[0].MissingSignatures != 2 || len(pst.Inputs[0].UnsignedKeys) != 3 {
+		t.Fatal("wrong missing signatures", pst.Inputs[0])
+	}
+
+	// sign is a helper to sign a copy of the transaction with one of the keys.
+	sign := func(pst PartiallySignedTransaction, keyIndex uint64) PartiallySignedTransaction {
+		pst.Transaction.TransactionSignatures = append(append([]types.TransactionSignature(nil), pst.Transaction.TransactionSignatures...), types.TransactionSignature{
+			ParentID:       crypto.Hash(parentID),
+			PublicKeyIndex: keyIndex,
+			CoveredFields:  types.FullCoveredFields,
+		})
+		sigIndex := len(pst.Transaction.TransactionSignatures) - 1
+		sig := crypto.SignHash(pst.Transaction.SigHash(sigIndex, 0), sks[keyIndex])
+		pst.Transaction.TransactionSignatures[sigIndex].Signature = sig[:]
+		pst.Inputs = append([]PartiallySignedInput(nil), pst.Inputs...)
+		if err := pst.UpdateInputs(0); err != nil {
+			t.Fatal(err)
+		}
+		return pst
+	}
+
+	// Sign two copies with different keys.
+	pst0 := sign(pst, 0)
+	pst2 := sign(pst, 2)
+	if pst0.Complete() || pst0.Inputs[0].MissingSignatures != 1 || len(pst0.Inputs[0].UnsignedKeys) != 2 {
+		t.Fatal("wrong missing signatures", pst0.Inputs[0])
+	}
+	if !pst0.Inputs[0].UnsignedKeys[0].Equals(keys[1]) || !pst0.Inputs[0].UnsignedKeys[1].Equals(keys[2]) {
+		t.Fatal("wrong unsigned keys", pst0.Inputs[0].UnsignedKeys)
+	}
+
+	// Combining the copies should complete the transaction.
+	combined, err := CombinePartiallySignedTransactions([]PartiallySignedTransaction{pst0, pst2}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !combined.Complete() || len(combined.Transaction.TransactionSignatures) != 2 {
+		t.Fatal("transaction should be complete", combined.Inputs[0])
+	}
+	if err := combined.Transaction.StandaloneValid(0); err != nil {
+		t.Fatal(err)
+	}
+	// The inputs of the first copy shouldn't have been modified.
+	if pst0.Inputs[0].MissingSignatures != 1 {
+		t.Fatal("combining modified the input")
+	}
+
+	// Combining a third copy shouldn't add a frivolous signature.
+	pst1 := sign(pst, 1)
+	combined, err = CombinePartiallySignedTransactions([]PartiallySignedTransaction{combined, pst1, pst0}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(combined.Transaction.TransactionSignatures) != 2 {
+		t.Fatal("wrong number of signatures", len(combined.Transaction.TransactionSignatures))
+	}
+	if err := combined.Transaction.StandaloneValid(0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Combining different transactions should fail.
+	other := pst
+	other.Transaction.SiacoinOutputs = []types.SiacoinOutput{{Value: types.SiacoinPrecision.Mul64(2)}}
+	_, err = CombinePartiallySignedTransactions([]PartiallySignedTransaction{pst0, other}, 0)
+	if !errors.Contains(err, ErrMultisigTransactionMismatch) {
+		t.Fatal("expected mismatch error", err)
+	}
+
+	// A transaction with inputs that don't match the transaction is invalid.
+	invalid := pst
+	invalid.Inputs = nil
+	if err := invalid.UpdateInputs(0); err == nil {
+		t.Fatal("expected error for missing inputs")
+	}
+	invalid.Inputs = []PartiallySignedInput{{ParentID: crypto.Hash{1}}}
+	if err := invalid.UpdateInputs(0); err == nil {
+		t.Fatal("expected error for unknown input")
+	}
+
+	// A signature that wasn't created by the key it claims should be
+	// rejected, both on its own and when combined.
+	badSig := pst0
+	badSig.Transaction.TransactionSignatures = append([]types.TransactionSignature(nil), pst0.Transaction.TransactionSignatures...)
+	badSig.Transaction.TransactionSignatures[0].PublicKeyIndex = 1
+	if err := badSig.UpdateInputs(0); err == nil {
+		t.Fatal("expected error for signature of the wrong key")
+	}
+	if _, err := CombinePartiallySignedTransactions([]PartiallySignedTransaction{pst2, badSig}, 0); err == nil {
+		t.Fatal("expected error when combining a bad signature")
+	}
+	badSig.Transaction.TransactionSignatures[0].PublicKeyIndex = 0
+	badSig.Transaction.TransactionSignatures[0].Signature = fastrand.Bytes(crypto.SignatureSize)
+	if err := badSig.UpdateInputs(0); err == nil {
+		t.Fatal("expected error for invalid signature")
+	}
+
+	// Signatures with out of range key indices or duplicate signatures of
+	// the same key should be rejected.
+	badSig.Transaction.TransactionSignatures[0] = pst0.Transaction.TransactionSignatures[0]
+	badSig.Transaction.TransactionSignatures[0].PublicKeyIndex = 3
+	if err := badSig.UpdateInputs(0); err == nil {
+		t.Fatal("expected error for out of range key index")
+	}
+	badSig.Transaction.TransactionSignatures = append(pst0.Transaction.TransactionSignatures, pst0.Transaction.TransactionSignatures...)
+	if err := badSig.UpdateInputs(0); err == nil {
+		t.Fatal("expected error for duplicate signature")
+	}
+}
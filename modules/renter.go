@@ -39,6 +39,7 @@ type WorkerPool interface {
 // Worker is a minimal interface for a single worker. It's used to be able to
 // use workers within the contractor.
 type Worker interface {
+	PriceTable() (RPCPriceTable, bool)
 	RenewContract(ctx context.Context, fcid types.FileContractID, params ContractParams, txnBuilder TransactionBuilder) (RenterContract, []types.Transaction, error)
 }
 
@@ -327,17 +328,56 @@ type ContractUtility struct {
 	Locked bool `json:"locked"`
 }
 
+// ContractProofOutcome describes whether the host of a contract submitted a
+// valid storage proof within the contract's proof window.
+type ContractProofOutcome string
+
+const (
+	// ProofOutcomePending indicates that the proof window of the contract
+	// hasn't ended yet and no storage proof was found.
+	ProofOutcomePending ContractProofOutcome = "pending"
+
+	// ProofOutcomeValid indicates that a valid storage proof for the contract
+	// was found on chain.
+	ProofOutcomeValid ContractProofOutcome = "valid"
+
+	// ProofOutcomeMissed indicates that the proof window of the contract ended
+	// without a storage proof being found on chain.
+	ProofOutcomeMissed ContractProofOutcome = "missed"
+
+	// ProofOutcomeNone indicates that the contract was double-spent and
+	// therefore never required a storage proof.
+	ProofOutcomeNone ContractProofOutcome = "none"
+
+	// ProofOutcomeNotRequired indicates that the proof window of the contract
+	// ended without a storage proof but the latest revision of the contract
+	// didn't require one. That's the case for empty contracts and renewed
+	// contracts whose missed proof outputs equal the valid ones.
+	ProofOutcomeNotRequired ContractProofOutcome = "notrequired"
+)
+
 // ContractWatchStatus provides information about the status of a contract in
 // the renter's watchdog.
 type ContractWatchStatus struct {
-	Archived                  bool              `json:"archived"`
-	FormationSweepHeight      types.BlockHeight `json:"formationsweepheight"`
-	ContractFound             bool              `json:"contractfound"`
-	LatestRevisionFound       uint64            `json:"latestrevisionfound"`
-	StorageProofFoundAtHeight types.BlockHeight `json:"storageprooffoundatheight"`
-	DoubleSpendHeight         types.BlockHeight `json:"doublespendheight"`
-	WindowStart               types.BlockHeight `json:"windowstart"`
-	WindowEnd                 types.BlockHeight `json:"windowend"`
+	ContractID                types.FileContractID `json:"contractid"`
+	HostPublicKey             types.SiaPublicKey   `json:"hostpublickey"`
+	Archived                  bool                 `json:"archived"`
+	FormationSweepHeight      types.BlockHeight    `json:"formationsweepheight"`
+	ContractFound             bool                 `json:"contractfound"`
+	LatestRevisionFound       uint64               `json:"latestrevisionfound"`
+	StorageProofFoundAtHeight types.BlockHeight    `json:"storageprooffoundatheight"`
+	DoubleSpendHeight         types.BlockHeight    `json:"doublespendheight"`
+	WindowStart               types.BlockHeight    `json:"windowstart"`
+	WindowEnd                 types.BlockHeight    `json:"windowend"`
+	ProofOutcome              ContractProofOutcome `json:"proofoutcome"`
+}
+
+// HostStorageProofStats contains the number of valid and missed storage proofs
+// the renter's watchdog observed for the contracts with a host.
+type HostStorageProofStats struct {
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	ValidProofs   uint64             `json:"validproofs"`
+	MissedProofs  uint64             `json:"missedproofs"`
 }
 
 // DirectoryInfo provides information about a siadir
@@ -613,11 +653,29 @@ type RenterPriceEstimation struct {
 
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
-	Allowance        Allowance     `json:"allowance"`
-	IPViolationCheck bool          `json:"ipviolationcheck"`
-	MaxUploadSpeed   int64         `json:"maxuploadspeed"`
-	MaxDownloadSpeed int64         `json:"maxdownloadspeed"`
-	UploadsStatus    UploadsStatus `json:"uploadsstatus"`
+	Allowance        Allowance      `json:"allowance"`
+	BackupSchedule   BackupSchedule `json:"backupschedule"`
+	IPViolationCheck bool           `json:"ipviolationcheck"`
+	MaxUploadSpeed   int64          `json:"maxuploadspeed"`
+	MaxDownloadSpeed int64          `json:"maxdownloadspeed"`
+	UploadsStatus    UploadsStatus  `json:"uploadsstatus"`
+}
+
+// BackupSchedule describes how often the renter automatically creates snapshot
+// backups and which of the scheduled backups are retained. An Interval of 0
+// disables scheduled backups. Scheduled backups are only deleted if at least
+// one of the Keep fields is set.
+type BackupSchedule struct {
+	// Interval is the amount of time between two scheduled backups.
+	Interval time.Duration `json:"interval"`
+	// KeepLast is the number of most recent scheduled backups to keep.
+	KeepLast uint64 `json:"keeplast"`
+	// KeepDaily is the number of days for which the most recent scheduled
+	// backup of that day is kept.
+	KeepDaily uint64 `json:"keepdaily"`
+	// KeepWeekly is the number of weeks for which the most recent scheduled
+	// backup of that week is kept.
+	KeepWeekly uint64 `json:"keepweekly"`
 }
 
 // UploadsStatus contains information about the Renter's Uploads
@@ -755,6 +813,90 @@ type SpendingDetails struct {
 	UploadSpending      types.Currency
 }
 
+// ContractSpendingCategory describes what a single entry of the contract
+// spending ledger was spent on.
+type ContractSpendingCategory string
+
+const (
+	// SpendingCategoryAccountBalance is the category for syncing the balance
+	// of an ephemeral account with the host.
+	SpendingCategoryAccountBalance ContractSpendingCategory = "accountbalance"
+	// SpendingCategoryDownload is the category for download bandwidth.
+	SpendingCategoryDownload ContractSpendingCategory = "download"
+	// SpendingCategoryDryRun is the category for dry running programs to
+	// compare the host's pricing with the renter's.
+	SpendingCategoryDryRun ContractSpendingCategory = "dryrun"
+	// SpendingCategoryFundAccount is the category for money deposited into an
+	// ephemeral account.
+	SpendingCategoryFundAccount ContractSpendingCategory = "fundaccount"
+	// SpendingCategoryFundAccountFee is the category for the cost of the fund
+	// account RPC itself.
+	SpendingCategoryFundAccountFee ContractSpendingCategory = "fundaccountfee"
+	// SpendingCategoryPriceTable is the category for updating the price table.
+	SpendingCategoryPriceTable ContractSpendingCategory = "pricetable"
+	// SpendingCategoryRegistryRead is the category for registry lookups.
+	SpendingCategoryRegistryRead ContractSpendingCategory = "registryread"
+	// SpendingCategoryRegistryWrite is the category for registry updates.
+	SpendingCategoryRegistryWrite ContractSpendingCategory = "registrywrite"
+	// SpendingCategoryStorage is the category for storage.
+	SpendingCategoryStorage ContractSpendingCategory = "storage"
+	// SpendingCategorySubscription is the category for registry
+	// subscriptions.
+	SpendingCategorySubscription ContractSpendingCategory = "subscription"
+	// SpendingCategoryUpload is the category for upload bandwidth.
+	SpendingCategoryUpload ContractSpendingCategory = "upload"
+)
+
+// ContractSpendingEntry is a single entry in the contractor's spending ledger.
+// Every time money is spent on a contract, or from the ephemeral account that
+// was funded by a contract, an entry is appended to the ledger.
+type ContractSpendingEntry struct {
+	ContractID    types.FileContractID     `json:"contractid"`
+	HostPublicKey types.SiaPublicKey       `json:"hostpublickey"`
+	Category      ContractSpendingCategory `json:"category"`
+	Amount        types.Currency           `json:"amount"`
+
+	// EphemeralAccount indicates that the money was paid from the ephemeral
+	// account on the host rather than through a contract revision. These
+	// funds were already accounted for by a SpendingCategoryFundAccount entry
+	// when the account was funded.
+	EphemeralAccount bool `json:"ephemeralaccount"`
+
+	// Unix timestamp in seconds.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// LedgerEntries breaks the spending details up into spending ledger entries
+// for the given contract. Zero amounts are omitted.
+func (sd SpendingDetails) LedgerEntries(fcid types.FileContractID, hpk types.SiaPublicKey, timestamp int64) []ContractSpendingEntry {
+	amounts := []struct {
+		category ContractSpendingCategory
+		amount   types.Currency
+	}{
+		{SpendingCategoryAccountBalance, sd.MaintenanceSpending.AccountBalanceCost},
+		{SpendingCategoryDownload, sd.DownloadSpending},
+		{SpendingCategoryFundAccount, sd.FundAccountSpending},
+		{SpendingCategoryFundAccountFee, sd.MaintenanceSpending.FundAccountCost},
+		{SpendingCategoryPriceTable, sd.MaintenanceSpending.UpdatePriceTableCost},
+		{SpendingCategoryStorage, sd.StorageSpending},
+		{SpendingCategoryUpload, sd.UploadSpending},
+	}
+	var entries []ContractSpendingEntry
+	for _, a := range amounts {
+		if a.amount.IsZero() {
+			continue
+		}
+		entries = append(entries, ContractSpendingEntry{
+			ContractID:    fcid,
+			HostPublicKey: hpk,
+			Category:      a.category,
+			Amount:        a.amount,
+			Timestamp:     timestamp,
+		})
+	}
+	return entries
+}
+
 // MaintenanceSpending is a helper struct that contains a breakdown of costs
 // related to the maintenance (a.k.a upkeep) of the RHP3 protocol. This includes
 // the costs to sync the account balance, update the price table, etc.
@@ -864,6 +1006,63 @@ type UploadedBackup struct {
 	UploadProgress float64
 }
 
+// RenterRecoveryStage describes the stage a renter recovery is in.
+type RenterRecoveryStage string
+
+const (
+	// RecoveryStageIdle indicates that no recovery was started yet.
+	RecoveryStageIdle RenterRecoveryStage = "idle"
+
+	// RecoveryStageScanning indicates that the blockchain is scanned for
+	// contracts that can be recovered using the renter seed.
+	RecoveryStageScanning RenterRecoveryStage = "scanning"
+
+	// RecoveryStageWaitingForContracts indicates that the recovery waits for
+	// the recovered contracts to become usable.
+	RecoveryStageWaitingForContracts RenterRecoveryStage = "waitingforcontracts"
+
+	// RecoveryStageFetchingBackups indicates that the hosts are queried for
+	// the backups they store.
+	RecoveryStageFetchingBackups RenterRecoveryStage = "fetchingbackups"
+
+	// RecoveryStageDownloading indicates that a backup is downloaded.
+	RecoveryStageDownloading RenterRecoveryStage = "downloading"
+
+	// RecoveryStageRestoring indicates that the siafiles of the downloaded
+	// backup are restored.
+	RecoveryStageRestoring RenterRecoveryStage = "restoring"
+
+	// RecoveryStageDone indicates that the recovery finished successfully.
+	RecoveryStageDone RenterRecoveryStage = "done"
+
+	// RecoveryStageFailed indicates that the recovery failed.
+	RecoveryStageFailed RenterRecoveryStage = "failed"
+)
+
+// RecoveryFailedFile is a siafile of a backup that couldn't be restored.
+type RecoveryFailedFile struct {
+	SiaPath SiaPath `json:"siapath"`
+	Error   string  `json:"error"`
+}
+
+// RenterRecoveryStatus contains the progress of recovering the renter's
+// metadata from the backups stored on its hosts.
+type RenterRecoveryStatus struct {
+	Stage         RenterRecoveryStage `json:"stage"`
+	StartTime     time.Time           `json:"starttime"`
+	EndTime       time.Time           `json:"endtime"`
+	ScannedHeight types.BlockHeight   `json:"scannedheight"`
+	Contracts     int                 `json:"contracts"`
+	HostsQueried  int                 `json:"hostsqueried"`
+	BackupsFound  int                 `json:"backupsfound"`
+
+	// Backup is the backup that is being restored or was restored.
+	Backup        UploadedBackup       `json:"backup"`
+	RestoredFiles uint64               `json:"restoredfiles"`
+	FailedFiles   []RecoveryFailedFile `json:"failedfiles"`
+	Error         string               `json:"error,omitempty"`
+}
+
 type (
 	// WorkerPoolStatus contains information about the status of the workerPool
 	// and the workers
@@ -875,6 +1074,31 @@ type (
 		Workers                  []WorkerStatus `json:"workers"`
 	}
 
+	// WorkerProgramEstimate compares the renter's local estimate of a
+	// program's cost with the host's dry run of the same program.
+	WorkerProgramEstimate struct {
+		HostPubKey types.SiaPublicKey `json:"hostpubkey"`
+
+		LocalCost       types.Currency `json:"localcost"`
+		LocalCollateral types.Currency `json:"localcollateral"`
+		HostCost        types.Currency `json:"hostcost"`
+		HostCollateral  types.Currency `json:"hostcollateral"`
+
+		Instructions []WorkerInstructionEstimate `json:"instructions"`
+		Error        string                      `json:"error,omitempty"`
+	}
+
+	// WorkerInstructionEstimate is the host's cost breakdown of a single
+	// instruction of a WorkerProgramEstimate.
+	WorkerInstructionEstimate struct {
+		Specifier     string         `json:"specifier"`
+		Cost          types.Currency `json:"cost"`
+		FailureRefund types.Currency `json:"failurerefund"`
+		Collateral    types.Currency `json:"collateral"`
+		Memory        uint64         `json:"memory"`
+		Time          uint64         `json:"time"`
+	}
+
 	// WorkerStatus contains information about the status of a worker
 	WorkerStatus struct {
 		// Worker contract information
@@ -909,6 +1133,7 @@ type (
 		PriceTableStatus WorkerPriceTableStatus `json:"pricetablestatus"`
 
 		// Job Queues
+		DeleteSnapshotJobQueueSize   int `json:"deletesnapshotjobqueuesize"`
 		DownloadSnapshotJobQueueSize int `json:"downloadsnapshotjobqueuesize"`
 		UploadSnapshotJobQueueSize   int `json:"uploadsnapshotjobqueuesize"`
 
@@ -1022,6 +1247,10 @@ type Renter interface {
 	// watchdog, and a bool indicating whether or not the watchdog is aware of it.
 	ContractStatus(fcID types.FileContractID) (ContractWatchStatus, bool)
 
+	// ContractStatuses returns the status of all the contracts known to the
+	// watchdog together with the storage proof statistics of their hosts.
+	ContractStatuses() ([]ContractWatchStatus, []HostStorageProofStats)
+
 	// CreateBackup creates a backup of the renter's siafiles. If a secret is not
 	// nil, the backup will be encrypted using the provided secret.
 	CreateBackup(dst string, secret []byte) error
@@ -1035,6 +1264,15 @@ type Renter interface {
 	// use.
 	LoadBackup(src string, secret []byte) error
 
+	// RecoverRenter starts recovering the renter's contracts and metadata
+	// using the wallet's seed. The contracts are recovered from the
+	// blockchain and the newest backup that can be downloaded from the
+	// renter's hosts is restored.
+	RecoverRenter() error
+
+	// RenterRecoveryStatus returns the progress of the renter recovery.
+	RenterRecoveryStatus() (RenterRecoveryStatus, error)
+
 	// InitRecoveryScan starts scanning the whole blockchain for recoverable
 	// contracts within a separate thread.
 	InitRecoveryScan() error
@@ -1069,6 +1307,11 @@ type Renter interface {
 	// billing period.
 	PeriodSpending() (ContractorSpending, error)
 
+	// SpendingLedger returns the entries of the contract spending ledger that
+	// were recorded between start and end. If host is not empty, only the
+	// entries for that host are returned.
+	SpendingLedger(start, end time.Time, host types.SiaPublicKey) ([]ContractSpendingEntry, error)
+
 	// RecoverableContracts returns the contracts that the contractor deems
 	// recoverable. That means they are not expired yet and also not part of the
 	// active contracts. Usually this should return an empty slice unless the host
@@ -1165,6 +1408,11 @@ type Renter interface {
 	// used.
 	ReadRegistry(spk types.SiaPublicKey, tweak crypto.Hash, timeout time.Duration) (SignedRegistryValue, error)
 
+	// ReadRegistryMulti looks up multiple registry entries at once using a
+	// single program per host. The results are returned in the same order as
+	// the requests.
+	ReadRegistryMulti(reqs []RegistryReadRequest, timeout time.Duration) ([]RegistryReadResult, error)
+
 	// ScoreBreakdown will return the score for a host db entry using the
 	// hostdb's weighting algorithm.
 	ScoreBreakdown(entry HostDBEntry) (HostScoreBreakdown, error)
@@ -1183,6 +1431,11 @@ type Renter interface {
 	// registry value.
 	UpdateRegistry(spk types.SiaPublicKey, srv SignedRegistryValue, timeout time.Duration) error
 
+	// UpdateRegistryMulti updates multiple registry entries at once using a
+	// single program per host. The returned errors are in the same order as
+	// the updates and are 'nil' for successful updates.
+	UpdateRegistryMulti(updates []RegistryBatchUpdate, timeout time.Duration) ([]error, error)
+
 	// PauseRepairsAndUploads pauses the renter's repairs and uploads for a time
 	// duration
 	PauseRepairsAndUploads(duration time.Duration) error
@@ -1214,6 +1467,10 @@ type Renter interface {
 	// WorkerPoolStatus returns the current status of the Renter's worker pool
 	WorkerPoolStatus() (WorkerPoolStatus, error)
 
+	// WorkerProgramEstimates has every worker's host dry run a sample program
+	// and compares the host's cost breakdown to the renter's local estimate.
+	WorkerProgramEstimates() ([]WorkerProgramEstimate, error)
+
 	// BubbleMetadata calculates the updated values of a directory's metadata and
 	// updates the siadir metadata on disk then calls callThreadedBubbleMetadata
 	// on the parent directory so that it is only blocking for the current
@@ -1240,6 +1497,21 @@ type SkyfileStreamer interface {
 	io.Closer
 }
 
+// RegistryReadRequest identifies a registry entry to read with
+// ReadRegistryMulti.
+type RegistryReadRequest struct {
+	PubKey types.SiaPublicKey
+	Tweak  crypto.Hash
+}
+
+// RegistryReadResult is the result of reading a single entry with
+// ReadRegistryMulti. If Err is 'nil', Value is the entry with the highest
+// revision number that was found.
+type RegistryReadResult struct {
+	Value SignedRegistryValue
+	Err   error
+}
+
 // RenterDownloadParameters defines the parameters passed to the Renter's
 // Download method.
 type RenterDownloadParameters struct {
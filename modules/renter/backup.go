@@ -129,16 +129,24 @@ func (r *Renter) managedCreateBackup(dst string, secret []byte) (err error) {
 // LoadBackup loads the siafiles of a previously created backup into the
 // renter. If the backup is encrypted, secret will be used to decrypt it.
 // Otherwise the argument is ignored.
-func (r *Renter) LoadBackup(src string, secret []byte) (err error) {
+func (r *Renter) LoadBackup(src string, secret []byte) error {
 	if err := r.tg.Add(); err != nil {
 		return err
 	}
 	defer r.tg.Done()
+	_, _, err := r.managedLoadBackup(src, secret, false)
+	return err
+}
 
+// managedLoadBackup loads the siafiles of a previously created backup into the
+// renter and returns the number of restored siafiles. If skipFailedFiles is
+// true, siafiles that can't be added to the renter don't abort loading the
+// backup but are returned instead.
+func (r *Renter) managedLoadBackup(src string, secret []byte, skipFailedFiles bool) (restored uint64, failed []modules.RecoveryFailedFile, err error) {
 	// Only load a backup if there are no siafiles yet.
 	root, err := r.staticFileSystem.OpenSiaDir(modules.UserFolder)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	defer func() {
 		err = errors.Compose(err, root.Close())
@@ -147,7 +155,7 @@ func (r *Renter) LoadBackup(src string, secret []byte) (err error) {
 	// Open the gzip file.
 	f, err := os.Open(src)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	defer func() {
 		err = errors.Compose(err, f.Close())
@@ -158,53 +166,53 @@ func (r *Renter) LoadBackup(src string, secret []byte) (err error) {
 	var chks crypto.Hash
 	_, err = io.ReadFull(f, chks[:])
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	// Read the header.
 	dec := json.NewDecoder(archive)
 	var bh backupHeader
 	if err := dec.Decode(&bh); err != nil {
-		return err
+		return 0, nil, err
 	}
 	// Check the version number.
 	if bh.Version != encryptionVersion {
-		return errors.New("unknown version")
+		return 0, nil, errors.New("unknown version")
 	}
 	// Wrap the file in the correct streamcipher. Consider the data remaining in
 	// the decoder's buffer by using a multireader.
 	archive = io.MultiReader(dec.Buffered(), archive)
 	_, err = archive.Read(make([]byte, 1)) // Ignore first byte of buffer to get to the body of the backup
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	archive, err = wrapReaderInCipher(io.MultiReader(archive, f), bh, secret)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	// Pipe the remaining file into the hasher to verify that the hash is
 	// correct.
 	h := crypto.NewHash()
 	n, err := io.Copy(h, archive)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	// Verify the hash.
 	if !bytes.Equal(h.Sum(nil), chks[:]) {
-		return errors.New("checksum doesn't match")
+		return 0, nil, errors.New("checksum doesn't match")
 	}
 	// Seek back to the beginning of the body.
 	if _, err := f.Seek(-n, io.SeekCurrent); err != nil {
-		return err
+		return 0, nil, err
 	}
 	// Wrap the file again.
 	archive, err = wrapReaderInCipher(f, bh, secret)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	// Wrap the potentially encrypted reader in a gzip reader.
 	gzr, err := gzip.NewReader(archive)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	defer func() {
 		err = errors.Compose(err, gzr.Close())
@@ -212,8 +220,9 @@ func (r *Renter) LoadBackup(src string, secret []byte) (err error) {
 	// Wrap the gzip reader in a tar reader.
 	tr := tar.NewReader(gzr)
 	// Untar the files.
-	if err := r.managedUntarDir(tr); err != nil {
-		return errors.AddContext(err, "failed to untar dir")
+	restored, failed, err = r.managedUntarDir(tr, skipFailedFiles)
+	if err != nil {
+		return 0, nil, errors.AddContext(err, "failed to untar dir")
 	}
 	// Unmarshal the allowance if available. This needs to happen after adding
 	// decryption and confirming the hash but before adding decompression.
@@ -228,10 +237,10 @@ func (r *Renter) LoadBackup(src string, secret []byte) (err error) {
 	if !reflect.DeepEqual(allowance, modules.Allowance{}) &&
 		reflect.DeepEqual(r.hostContractor.Allowance(), modules.Allowance{}) {
 		if err := r.hostContractor.SetAllowance(allowance); err != nil {
-			return errors.AddContext(err, "unable to set allowance from backup")
+			return 0, nil, errors.AddContext(err, "unable to set allowance from backup")
 		}
 	}
-	return nil
+	return restored, failed, nil
 }
 
 // managedTarSiaFiles creates a tarball from the renter's siafiles and writes
@@ -340,8 +349,10 @@ func (r *Renter) managedTarSiaFiles(tw *tar.Writer) error {
 }
 
 // managedUntarDir untars the archive from src and writes the contents to dstFolder
-// while preserving the relative paths within the archive.
-func (r *Renter) managedUntarDir(tr *tar.Reader) (err error) {
+// while preserving the relative paths within the archive. It returns the number
+// of siafiles that were added to the renter. If skipFailedFiles is true,
+// siafiles that can't be added are returned instead of aborting the untar.
+func (r *Renter) managedUntarDir(tr *tar.Reader, skipFailedFiles bool) (restored uint64, failed []modules.RecoveryFailedFile, err error) {
 	// dirsToUpdate are all the directories that will need bubble to be called
 	// on them so that the renter's directory metadata from the back up is
 	// updated
@@ -357,7 +368,7 @@ func (r *Renter) managedUntarDir(tr *tar.Reader) (err error) {
 		if errors.Contains(err, io.EOF) {
 			break
 		} else if err != nil {
-			return errors.AddContext(err, "could not get next entry in the tar archive")
+			return 0, nil, errors.AddContext(err, "could not get next entry in the tar archive")
 		}
 
 		// nolint:gosec // Disable gosec for this line since directory traversal
@@ -366,26 +377,26 @@ func (r *Renter) managedUntarDir(tr *tar.Reader) (err error) {
 
 		// Check for directory traversal.
 		if header.Name != "" && !strings.HasPrefix(dst, filepath.Clean(dir)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path: %s", dst)
+			return 0, nil, fmt.Errorf("illegal file path: %s", dst)
 		}
 
 		// Check for dir.
 		info := header.FileInfo()
 		if info.IsDir() {
 			if err = os.MkdirAll(dst, info.Mode()); err != nil {
-				return errors.AddContext(err, fmt.Sprintf("could not make directory %v", dst))
+				return 0, nil, errors.AddContext(err, fmt.Sprintf("could not make directory %v", dst))
 			}
 			continue
 		}
 		// Load the new file in memory.
 		b, err := ioutil.ReadAll(tr)
 		if err != nil {
-			return errors.AddContext(err, "could not load the new file in memory")
+			return 0, nil, errors.AddContext(err, "could not load the new file in memory")
 		}
 		if name := filepath.Base(info.Name()); name == modules.SiaDirExtension {
 			// Verify there is enough data for a checksum
 			if len(b) < crypto.HashSize {
-				return siadir.ErrCorruptFile
+				return 0, nil, siadir.ErrCorruptFile
 			}
 
 			// Verify checksum
@@ -393,22 +404,22 @@ func (r *Renter) managedUntarDir(tr *tar.Reader) (err error) {
 			mdBytes := b[crypto.HashSize:]
 			fileChecksum := crypto.HashBytes(mdBytes)
 			if !bytes.Equal(checksum, fileChecksum[:]) {
-				return siadir.ErrInvalidChecksum
+				return 0, nil, siadir.ErrInvalidChecksum
 			}
 			// Load the file as a .siadir
 			var md siadir.Metadata
 			err = json.Unmarshal(mdBytes, &md)
 			if err != nil {
-				return errors.AddContext(err, "could not unmarshal")
+				return 0, nil, errors.AddContext(err, "could not unmarshal")
 			}
 			// Try creating a new SiaDir.
 			var siaPath modules.SiaPath
 			if err := siaPath.LoadSysPath(r.staticFileSystem.DirPath(modules.UserFolder), dst); err != nil {
-				return errors.AddContext(err, "could not load system path")
+				return 0, nil, errors.AddContext(err, "could not load system path")
 			}
 			siaPath, err = siaPath.Dir()
 			if err != nil {
-				return errors.AddContext(err, "could not get directory")
+				return 0, nil, errors.AddContext(err, "could not get directory")
 			}
 			err := r.staticFileSystem.NewSiaDir(siaPath, modules.DefaultDirPerm)
 			if errors.Contains(err, filesystem.ErrExists) {
@@ -416,21 +427,21 @@ func (r *Renter) managedUntarDir(tr *tar.Reader) (err error) {
 				continue
 			} else if err != nil {
 				// unexpected error
-				return errors.AddContext(err, fmt.Sprintf("could not create dir at  %v", siaPath))
+				return 0, nil, errors.AddContext(err, fmt.Sprintf("could not create dir at  %v", siaPath))
 			}
 			// Update the metadata.
 			dirEntry, err := r.staticFileSystem.OpenSiaDir(siaPath)
 			if err != nil {
-				return errors.AddContext(err, fmt.Sprintf("could not open dir at %v", siaPath))
+				return 0, nil, errors.AddContext(err, fmt.Sprintf("could not open dir at %v", siaPath))
 			}
 			if err := dirEntry.UpdateMetadata(md); err != nil {
 				dirEntry.Close()
-				return errors.AddContext(err, "could not update metadata")
+				return 0, nil, errors.AddContext(err, "could not update metadata")
 			}
 			// Metadata was updated so add to list of directories to be updated
 			err = dirsToUpdate.callAdd(siaPath)
 			if err != nil {
-				return errors.AddContext(err, fmt.Sprintf("could not add directory %v to the list of directories to be updated", siaPath))
+				return 0, nil, errors.AddContext(err, fmt.Sprintf("could not add directory %v to the list of directories to be updated", siaPath))
 			}
 			// Close Directory
 			dirEntry.Close()
@@ -439,21 +450,33 @@ func (r *Renter) managedUntarDir(tr *tar.Reader) (err error) {
 			reader := bytes.NewReader(b)
 			siaPath, err := modules.UserFolder.Join(strings.TrimSuffix(header.Name, modules.SiaFileExtension))
 			if err != nil {
-				return errors.AddContext(err, "could not join folders")
+				return 0, nil, errors.AddContext(err, "could not join folders")
 			}
 			err = r.staticFileSystem.AddSiaFileFromReader(reader, siaPath)
+			if err != nil && skipFailedFiles {
+				userSiaPath, rebaseErr := siaPath.Rebase(modules.UserFolder, modules.RootSiaPath())
+				if rebaseErr != nil {
+					return 0, nil, errors.AddContext(rebaseErr, "could not rebase siapath")
+				}
+				failed = append(failed, modules.RecoveryFailedFile{
+					SiaPath: userSiaPath,
+					Error:   err.Error(),
+				})
+				continue
+			}
 			if err != nil {
-				return errors.AddContext(err, "could not add siafile from reader")
+				return 0, nil, errors.AddContext(err, "could not add siafile from reader")
 			}
+			restored++
 			// Add directory that siafile resides in to the list of directories
 			// to be updated
 			err = dirsToUpdate.callAdd(siaPath)
 			if err != nil {
-				return errors.AddContext(err, fmt.Sprintf("could not add directory %v to the list of directories to be updated", siaPath))
+				return 0, nil, errors.AddContext(err, fmt.Sprintf("could not add directory %v to the list of directories to be updated", siaPath))
 			}
 		}
 	}
-	return nil
+	return restored, failed, nil
 }
 
 // wrapReaderInCipher wraps the reader r into another reader according to the
@@ -0,0 +1,303 @@
+package renter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+const (
+	// scheduledBackupPrefix is the prefix of the names of the backups created
+	// by the backup schedule. Only backups with this prefix are subject to the
+	// retention policy of the schedule.
+	scheduledBackupPrefix = "auto-"
+
+	// scheduledBackupTimeFormat is the format of the creation time within the
+	// name of a scheduled backup.
+	scheduledBackupTimeFormat = "20060102-150405"
+
+	// maxDeletedBackups is the maximum number of deleted backups the renter
+	// remembers. Deleted backups are remembered to remove them from the hosts'
+	// snapshot tables and to prevent them from being restored by the snapshot
+	// synchronization.
+	maxDeletedBackups = 1000
+)
+
+var (
+	// backupScheduleCheckInterval is the interval at which the renter checks
+	// whether a scheduled backup is due.
+	backupScheduleCheckInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 5 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// backupOverdueGracePeriod is the amount of time on top of the schedule's
+	// interval that a scheduled backup has to finish uploading before the
+	// renter registers an alert.
+	backupOverdueGracePeriod = build.Select(build.Var{
+		Dev:      10 * time.Minute,
+		Standard: 6 * time.Hour,
+		Testing:  30 * time.Second,
+	}).(time.Duration)
+
+	// minBackupScheduleInterval is the minimum interval of a backup schedule.
+	minBackupScheduleInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Hour,
+		Testing:  time.Second,
+	}).(time.Duration)
+)
+
+// validateBackupSchedule checks that the backup schedule is valid.
+func validateBackupSchedule(schedule modules.BackupSchedule) error {
+	if schedule.Interval < 0 {
+		return errors.New("backup interval cannot be negative")
+	}
+	if schedule.Interval > 0 && schedule.Interval < minBackupScheduleInterval {
+		return fmt.Errorf("backup interval must be at least %v", minBackupScheduleInterval)
+	}
+	return nil
+}
+
+// isScheduledBackup returns true if the backup was created by the backup
+// schedule.
+func isScheduledBackup(ub modules.UploadedBackup) bool {
+	return strings.HasPrefix(ub.Name, scheduledBackupPrefix)
+}
+
+// backupTime converts the creation date of a backup to a time.Time.
+func backupTime(ub modules.UploadedBackup) time.Time {
+	return time.Unix(int64(ub.CreationDate), 0).UTC()
+}
+
+// backupDue returns true if the newest scheduled backup is older than the
+// schedule's interval.
+func backupDue(backups []modules.UploadedBackup, schedule modules.BackupSchedule, now time.Time) bool {
+	for _, ub := range backups {
+		if isScheduledBackup(ub) && now.Sub(backupTime(ub)) < schedule.Interval {
+			return false
+		}
+	}
+	return true
+}
+
+// backupOverdue returns true if the newest successfully uploaded backup is
+// older than the schedule allows. Backups are not considered to be overdue
+// before the schedule has been enabled for at least one interval.
+func backupOverdue(backups []modules.UploadedBackup, schedule modules.BackupSchedule, enabledSince, now time.Time) (bool, time.Time) {
+	var latest time.Time
+	for _, ub := range backups {
+		if ub.UploadProgress == 100 && backupTime(ub).After(latest) {
+			latest = backupTime(ub)
+		}
+	}
+	reference := latest
+	if enabledSince.After(reference) {
+		reference = enabledSince
+	}
+	return now.Sub(reference) > schedule.Interval+backupOverdueGracePeriod, latest
+}
+
+// backupsToPrune returns the scheduled backups which fall out of the retention
+// policy of the schedule. A scheduled backup is retained if it is one of the
+// KeepLast most recent backups, or if it is the most recent backup of one of
+// the KeepDaily most recent days or the KeepWeekly most recent weeks that have
+// a backup. Backups which didn't finish uploading are never pruned.
+func backupsToPrune(backups []modules.UploadedBackup, schedule modules.BackupSchedule) []modules.UploadedBackup {
+	if schedule.KeepLast == 0 && schedule.KeepDaily == 0 && schedule.KeepWeekly == 0 {
+		return nil
+	}
+	var scheduled []modules.UploadedBackup
+	for _, ub := range backups {
+		if isScheduledBackup(ub) && ub.UploadProgress == 100 {
+			scheduled = append(scheduled, ub)
+		}
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].CreationDate > scheduled[j].CreationDate
+	})
+
+	var prune []modules.UploadedBackup
+	var lastDay, lastWeek string
+	var days, weeks uint64
+	for i, ub := range scheduled {
+		t := backupTime(ub)
+		keep := uint64(i) < schedule.KeepLast
+		if day := t.Format("2006-01-02"); day != lastDay {
+			lastDay = day
+			if days < schedule.KeepDaily {
+				keep = true
+				days++
+			}
+		}
+		year, w := t.ISOWeek()
+		if week := fmt.Sprintf("%v-%v", year, w); week != lastWeek {
+			lastWeek = week
+			if weeks < schedule.KeepWeekly {
+				keep = true
+				weeks++
+			}
+		}
+		if !keep {
+			prune = append(prune, ub)
+		}
+	}
+	return prune
+}
+
+// threadedScheduleBackups periodically creates snapshot backups according to
+// the renter's backup schedule and prunes the scheduled backups that fall out
+// of retention.
+func (r *Renter) threadedScheduleBackups() {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	var enabledSince time.Time
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(backupScheduleCheckInterval):
+		}
+
+		id := r.mu.RLock()
+		schedule := r.persist.BackupSchedule
+		backups := append([]modules.UploadedBackup(nil), r.persist.UploadedBackups...)
+		r.mu.RUnlock(id)
+
+		// Nothing to do if the schedule is disabled.
+		if schedule.Interval == 0 {
+			enabledSince = time.Time{}
+			r.staticAlerter.UnregisterAlert(modules.AlertIDRenterBackupOverdue)
+			continue
+		}
+		now := time.Now()
+		if enabledSince.IsZero() {
+			enabledSince = now
+		}
+
+		// Check whether the latest successful backup is overdue.
+		if overdue, latest := backupOverdue(backups, schedule, enabledSince, now); overdue {
+			cause := "no backup was uploaded successfully yet"
+			if !latest.IsZero() {
+				cause = fmt.Sprintf("latest successful backup was created at %v", latest)
+			}
+			r.staticAlerter.RegisterAlert(modules.AlertIDRenterBackupOverdue, AlertMSGBackupOverdue, cause, modules.SeverityWarning)
+		} else {
+			r.staticAlerter.UnregisterAlert(modules.AlertIDRenterBackupOverdue)
+		}
+
+		// Create a new backup if necessary. This requires the wallet to be
+		// unlocked and an allowance to upload the backup with.
+		unlocked, _ := r.w.Unlocked()
+		if unlocked && r.hostContractor.Allowance().Active() && backupDue(backups, schedule, now) {
+			if err := r.managedCreateScheduledBackup(now); err != nil {
+				r.log.Println("WARN: failed to create scheduled backup:", err)
+			}
+		}
+
+		// Delete the backups which fell out of retention.
+		if prune := backupsToPrune(backups, schedule); len(prune) > 0 {
+			if err := r.managedDeleteBackups(prune); err != nil {
+				r.log.Println("WARN: failed to delete scheduled backups:", err)
+			}
+		}
+	}
+}
+
+// managedCreateScheduledBackup creates a backup of the renter and uploads it
+// as a scheduled snapshot.
+func (r *Renter) managedCreateScheduledBackup(now time.Time) error {
+	name := scheduledBackupPrefix + now.UTC().Format(scheduledBackupTimeFormat)
+
+	// Write the backup to a temporary file and delete it after uploading.
+	tmpDir, err := ioutil.TempDir("", "sia-backup")
+	if err != nil {
+		return errors.AddContext(err, "failed to create temporary directory for the backup")
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+	backupPath := filepath.Join(tmpDir, name+".bak")
+
+	// Get the wallet seed.
+	ws, _, err := r.w.PrimarySeed()
+	if err != nil {
+		return errors.AddContext(err, "failed to get wallet's primary seed")
+	}
+	// Derive the renter seed and wipe the memory once we are done using it.
+	rs := modules.DeriveRenterSeed(ws)
+	defer fastrand.Read(rs[:])
+	// Derive the secret and wipe it afterwards.
+	secret := crypto.HashAll(rs, modules.BackupKeySpecifier)
+	defer fastrand.Read(secret[:])
+
+	if err := r.managedCreateBackup(backupPath, secret[:32]); err != nil {
+		return errors.AddContext(err, "failed to create backup")
+	}
+	if err := r.managedUploadBackup(backupPath, name); err != nil {
+		return errors.AddContext(err, "failed to upload backup")
+	}
+	r.log.Printf("Created scheduled backup %q", name)
+	return nil
+}
+
+// managedDeleteBackups removes the backups from the renter's set of backups
+// and remembers them as deleted. They are removed from the hosts' snapshot
+// tables by the snapshot synchronization.
+func (r *Renter) managedDeleteBackups(backups []modules.UploadedBackup) error {
+	deleted := make(map[[16]byte]struct{}, len(backups))
+	for _, ub := range backups {
+		deleted[ub.UID] = struct{}{}
+	}
+
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	remaining := r.persist.UploadedBackups[:0]
+	for _, ub := range r.persist.UploadedBackups {
+		if _, ok := deleted[ub.UID]; ok {
+			r.persist.DeletedBackups = append(r.persist.DeletedBackups, ub.UID)
+			r.log.Printf("Deleting backup %q which fell out of retention", ub.Name)
+			continue
+		}
+		remaining = append(remaining, ub)
+	}
+	r.persist.UploadedBackups = remaining
+	if len(r.persist.DeletedBackups) > maxDeletedBackups {
+		r.persist.DeletedBackups = r.persist.DeletedBackups[len(r.persist.DeletedBackups)-maxDeletedBackups:]
+	}
+	// All hosts need to be synchronized again to remove the deleted backups
+	// from their snapshot tables.
+	r.persist.SyncedContracts = r.persist.SyncedContracts[:0]
+	return r.saveSync()
+}
+
+// deletedBackups returns the set of deleted backups.
+func (r *Renter) deletedBackups() map[[16]byte]struct{} {
+	deleted := make(map[[16]byte]struct{}, len(r.persist.DeletedBackups))
+	for _, uid := range r.persist.DeletedBackups {
+		deleted[uid] = struct{}{}
+	}
+	return deleted
+}
+
+// managedDeletedBackups returns the set of deleted backups.
+func (r *Renter) managedDeletedBackups() map[[16]byte]struct{} {
+	id := r.mu.RLock()
+	defer r.mu.RUnlock(id)
+	return r.deletedBackups()
+}
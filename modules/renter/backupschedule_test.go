@@ -0,0 +1,170 @@
+package renter
+
+import (
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// newTestBackup is a helper that creates a finished backup with the given name
+// and creation time.
+func newTestBackup(name string, t time.Time) modules.UploadedBackup {
+	ub := modules.UploadedBackup{
+		Name:           name,
+		CreationDate:   types.Timestamp(t.Unix()),
+		UploadProgress: 100,
+	}
+	copy(ub.UID[:], name)
+	return ub
+}
+
+// TestBackupsToPrune tests the retention policy of scheduled backups.
+func TestBackupsToPrune(t *testing.T) {
+	t.Parallel()
+
+	// Create 3 scheduled backups per day for 3 weeks, starting on a Monday.
+	start := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	var backups []modules.UploadedBackup
+	for i := 0; i < 21*3; i++ {
+		created := start.Add(time.Duration(i) * 8 * time.Hour)
+		backups = append(backups, newTestBackup(scheduledBackupPrefix+created.Format(scheduledBackupTimeFormat), created))
+	}
+	// Add a manual backup and an unfinished scheduled backup which should
+	// never be pruned.
+	manual := newTestBackup("manual", start)
+	unfinished := newTestBackup(scheduledBackupPrefix+"unfinished", start)
+	unfinished.UploadProgress = 50
+	backups = append(backups, manual, unfinished)
+
+	// retained is a helper that returns the names of the backups that are not
+	// pruned.
+	retained := func(schedule modules.BackupSchedule) map[string]struct{} {
+		pruned := make(map[string]struct{})
+		for _, ub := range backupsToPrune(backups, schedule) {
+			pruned[ub.Name] = struct{}{}
+		}
+		if _, ok := pruned[manual.Name]; ok {
+			t.Fatal("manual backup was pruned")
+		}
+		if _, ok := pruned[unfinished.Name]; ok {
+			t.Fatal("unfinished backup was pruned")
+		}
+		kept := make(map[string]struct{})
+		for _, ub := range backups {
+			if _, ok := pruned[ub.Name]; !ok && isScheduledBackup(ub) && ub.UploadProgress == 100 {
+				kept[ub.Name] = struct{}{}
+			}
+		}
+		return kept
+	}
+	name := func(t time.Time) string {
+		return scheduledBackupPrefix + t.Format(scheduledBackupTimeFormat)
+	}
+	last := start.Add(time.Duration(21*3-1) * 8 * time.Hour)
+
+	// Without any retention nothing is pruned.
+	if pruned := backupsToPrune(backups, modules.BackupSchedule{Interval: time.Hour}); len(pruned) != 0 {
+		t.Fatal("backups were pruned without retention policy", len(pruned))
+	}
+
+	// Keep the last 2 backups.
+	kept := retained(modules.BackupSchedule{KeepLast: 2})
+	if len(kept) != 2 {
+		t.Fatal("wrong number of backups kept", len(kept))
+	}
+	for _, created := range []time.Time{last, last.Add(-8 * time.Hour)} {
+		if _, ok := kept[name(created)]; !ok {
+			t.Fatal("expected backup to be kept", name(created))
+		}
+	}
+
+	// Keep the newest backup of the last 3 days.
+	kept = retained(modules.BackupSchedule{KeepDaily: 3})
+	if len(kept) != 3 {
+		t.Fatal("wrong number of backups kept", len(kept))
+	}
+	for i := 0; i < 3; i++ {
+		created := last.Add(-time.Duration(i) * 24 * time.Hour)
+		if _, ok := kept[name(created)]; !ok {
+			t.Fatal("expected backup to be kept", name(created))
+		}
+	}
+
+	// Keep the newest backup of the last 5 weeks. Only 3 weeks have backups.
+	kept = retained(modules.BackupSchedule{KeepWeekly: 5})
+	if len(kept) != 3 {
+		t.Fatal("wrong number of backups kept", len(kept))
+	}
+	for i := 0; i < 3; i++ {
+		created := last.Add(-time.Duration(i) * 7 * 24 * time.Hour)
+		if _, ok := kept[name(created)]; !ok {
+			t.Fatal("expected backup to be kept", name(created))
+		}
+	}
+
+	// Combine the policies. The last backup is retained by all of them and
+	// the newest backup of the second to last day is also the second to last
+	// backup.
+	kept = retained(modules.BackupSchedule{KeepLast: 4, KeepDaily: 2, KeepWeekly: 2})
+	if len(kept) != 5 {
+		t.Fatal("wrong number of backups kept", len(kept))
+	}
+}
+
+// TestBackupScheduleChecks tests validateBackupSchedule, backupDue and
+// backupOverdue.
+func TestBackupScheduleChecks(t *testing.T) {
+	t.Parallel()
+
+	// Check the validation.
+	if err := validateBackupSchedule(modules.BackupSchedule{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBackupSchedule(modules.BackupSchedule{Interval: -time.Hour}); err == nil {
+		t.Fatal("negative interval should be invalid")
+	}
+	if err := validateBackupSchedule(modules.BackupSchedule{Interval: minBackupScheduleInterval / 2}); err == nil {
+		t.Fatal("interval below minimum should be invalid")
+	}
+
+	now := time.Now()
+	schedule := modules.BackupSchedule{Interval: time.Hour}
+	manual := newTestBackup("manual", now)
+	scheduled := newTestBackup(scheduledBackupPrefix+"1", now.Add(-2*time.Hour))
+
+	// A backup is due if there are no scheduled backups or if the last one is
+	// older than the interval. Manual backups don't count.
+	if !backupDue(nil, schedule, now) {
+		t.Fatal("backup should be due")
+	}
+	if !backupDue([]modules.UploadedBackup{manual, scheduled}, schedule, now) {
+		t.Fatal("backup should be due")
+	}
+	if backupDue([]modules.UploadedBackup{scheduled}, schedule, now.Add(-90*time.Minute)) {
+		t.Fatal("backup shouldn't be due")
+	}
+
+	// Backups are overdue if the latest successful backup is older than the
+	// interval and the grace period.
+	enabledSince := now.Add(-10 * schedule.Interval)
+	overdueAt := now.Add(schedule.Interval + backupOverdueGracePeriod + time.Second)
+	if overdue, _ := backupOverdue(nil, schedule, enabledSince, now); !overdue {
+		t.Fatal("backups should be overdue")
+	}
+	if overdue, _ := backupOverdue(nil, schedule, now, now); overdue {
+		t.Fatal("backups shouldn't be overdue right after enabling the schedule")
+	}
+	if overdue, latest := backupOverdue([]modules.UploadedBackup{manual}, schedule, enabledSince, now); overdue || latest.Unix() != now.Unix() {
+		t.Fatal("backups shouldn't be overdue", overdue, latest)
+	}
+	if overdue, _ := backupOverdue([]modules.UploadedBackup{manual}, schedule, enabledSince, overdueAt); !overdue {
+		t.Fatal("backups should be overdue")
+	}
+	unfinished := manual
+	unfinished.UploadProgress = 50
+	if overdue, latest := backupOverdue([]modules.UploadedBackup{unfinished}, schedule, enabledSince, now); !overdue || !latest.IsZero() {
+		t.Fatal("unfinished backups shouldn't count", overdue, latest)
+	}
+}
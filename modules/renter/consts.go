@@ -21,6 +21,9 @@ const (
 	// AlertSiafileLowRedundancyThreshold is the health threshold at which we start
 	// registering the LowRedundancy alert for a Siafile.
 	AlertSiafileLowRedundancyThreshold = 0.75
+	// AlertMSGBackupOverdue indicates that the latest successful snapshot backup
+	// is older than the backup schedule allows.
+	AlertMSGBackupOverdue = "The latest successful snapshot backup is older than the backup schedule allows"
 )
 
 // AlertCauseSiafileLowRedundancy creates a customized "cause" for a siafile
@@ -11,11 +11,13 @@ import (
 )
 
 // contractScoreAndUtil combines a contract with its host's score and an updated
-// utility.
+// utility. If the update was suggested because the host is a price outlier,
+// priceOutlier contains the details.
 type contractScoreAndUtil struct {
-	contract modules.RenterContract
-	score    types.Currency
-	util     modules.ContractUtility
+	contract     modules.RenterContract
+	score        types.Currency
+	util         modules.ContractUtility
+	priceOutlier *hostPriceOutlier
 }
 
 // churnLimiter keeps track of the aggregate number of bytes stored in contracts
@@ -159,10 +161,22 @@ func (cl *churnLimiter) managedProcessSuggestedUpdates(queue []contractScoreAndU
 			queuedContract.util.GoodForRenew = true
 		}
 
-		if churningThisContract {
+		if churningThisContract && queuedContract.priceOutlier == nil {
 			cl.contractor.log.Println("Churning contract for bad score: ", queuedContract.contract.ID, queuedContract.score)
 		}
 
+		// Explain the decision for contracts with hosts that just became price
+		// outliers.
+		if po := queuedContract.priceOutlier; po != nil && turnedNotGFR {
+			cause := po.cause(queuedContract.contract)
+			msg := AlertMSGContractPriceOutlierKept
+			if churningThisContract {
+				msg = AlertMSGContractPriceOutlierReplaced
+			}
+			cl.contractor.log.Println(msg+":", cause)
+			cl.contractor.staticAlerter.RegisterAlert(po.alertID(queuedContract.contract.ID), msg, cause, modules.SeverityWarning)
+		}
+
 		// Apply changes.
 		err := cl.contractor.managedAcquireAndUpdateContractUtility(queuedContract.contract.ID, queuedContract.util)
 		if err != nil {
@@ -216,11 +230,11 @@ func (cl *churnLimiter) managedCanChurnContract(contract modules.RenterContract)
 // managedMarkContractUtility checks an active contract in the contractor and
 // figures out whether the contract is useful for uploading, and whether the
 // contract should be renewed.
-func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract, minScoreGFR, minScoreGFU types.Currency) (modules.HostScoreBreakdown, modules.ContractUtility, bool, error) {
+func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract, minScoreGFR, minScoreGFU, medianPrice types.Currency) (contractScoreAndUtil, bool, error) {
 	// Acquire contract.
 	sc, ok := c.staticContracts.Acquire(contract.ID)
 	if !ok {
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.New("managedMarkContractUtility: Unable to acquire contract")
+		return contractScoreAndUtil{}, false, errors.New("managedMarkContractUtility: Unable to acquire contract")
 	}
 	defer c.staticContracts.Return(sc)
 
@@ -229,7 +243,7 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 
 	// If the utility is locked, do nothing.
 	if u.Locked {
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
+		return contractScoreAndUtil{}, false, nil
 	}
 
 	// Get host from hostdb and check that it's not filtered.
@@ -237,9 +251,9 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 	if needsUpdate {
 		if err := c.managedUpdateContractUtility(sc, u); err != nil {
 			c.log.Println("Unable to acquire and update contract utility:", err)
-			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after hostdb check")
+			return contractScoreAndUtil{}, false, errors.AddContext(err, "unable to update utility after hostdb check")
 		}
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
+		return contractScoreAndUtil{}, false, nil
 	}
 
 	// Do critical contract checks and update the utility if any checks fail.
@@ -248,15 +262,15 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 		err := c.managedUpdateContractUtility(sc, u)
 		if err != nil {
 			c.log.Println("Unable to acquire and update contract utility:", err)
-			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after criticalUtilityChecks")
+			return contractScoreAndUtil{}, false, errors.AddContext(err, "unable to update utility after criticalUtilityChecks")
 		}
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
+		return contractScoreAndUtil{}, false, nil
 	}
 
 	sb, err := c.hdb.ScoreBreakdown(host)
 	if err != nil {
 		c.log.Println("Unable to get ScoreBreakdown for", host.PublicKey.String(), "got err:", err)
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil // it may just be this host that has an issue.
+		return contractScoreAndUtil{}, false, nil // it may just be this host that has an issue.
 	}
 
 	// Check the host scorebreakdown against the minimum accepted scores.
@@ -268,21 +282,35 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 	// These are contracts with acceptable, but not very good host scores.
 	case suggestedUtilityUpdate:
 		c.log.Debugln("Queueing utility update", contract.ID, sb.Score)
-		return sb, u, true, nil
+		return contractScoreAndUtil{contract: contract, score: sb.Score, util: u}, true, nil
 
 	case necessaryUtilityUpdate:
 		// Apply changes.
 		err = c.managedUpdateContractUtility(sc, u)
 		if err != nil {
 			c.log.Println("Unable to acquire and update contract utility:", err)
-			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after checkHostScore")
+			return contractScoreAndUtil{}, false, errors.AddContext(err, "unable to update utility after checkHostScore")
 		}
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
+		return contractScoreAndUtil{}, false, nil
 
 	default:
 		c.log.Critical("Undefined checkHostScore utilityUpdateStatus", utilityUpdateStatus, contract.ID)
 	}
 
+	// Check whether the host became a price outlier. Outliers are suggested
+	// for churning since renewing would lock in the higher prices. If the
+	// host's renewal prices are unknown, the contract is left alone.
+	if prices, ok := c.managedRenewPrices(host); ok {
+		var outlier *hostPriceOutlier
+		var isOutlier bool
+		u, outlier, isOutlier = c.priceOutlierCheck(contract, prices, c.Allowance(), medianPrice)
+		if isOutlier {
+			c.log.Debugln("Queueing utility update for price outlier", contract.ID, outlier.hostPrice, outlier.medianPrice)
+			return contractScoreAndUtil{contract: contract, score: sb.Score, util: u, priceOutlier: outlier}, true, nil
+		}
+		c.staticAlerter.UnregisterAlert(modules.AlertIDRenterContractPriceOutlier(contract.ID.String()))
+	}
+
 	// All checks passed, marking contract as GFU and GFR.
 	if !u.GoodForUpload || !u.GoodForRenew {
 		c.log.Println("Marking contract as being both GoodForUpload and GoodForRenew", u.GoodForUpload, u.GoodForRenew, contract.ID)
@@ -293,9 +321,9 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 	err = c.managedUpdateContractUtility(sc, u)
 	if err != nil {
 		c.log.Println("Unable to acquire and update contract utility:", err)
-		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after all checks passed.")
+		return contractScoreAndUtil{}, false, errors.AddContext(err, "unable to update utility after all checks passed.")
 	}
-	return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
+	return contractScoreAndUtil{}, false, nil
 }
 
 // managedMarkContractsUtility checks every active contract in the contractor and
@@ -307,18 +335,28 @@ func (c *Contractor) managedMarkContractsUtility() error {
 		return err
 	}
 
+	// Compute the median host price once for all contracts. If there are not
+	// enough hosts, no contract is considered a price outlier.
+	medianPrice, ok, err := c.managedMedianHostPrice(c.Allowance())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		medianPrice = types.ZeroCurrency
+	}
+
 	// Queue for possible contracts to churn. Passed to churnLimiter for final
 	// judgment.
 	suggestedUpdateQueue := make([]contractScoreAndUtil, 0)
 
 	// Update utility fields for each contract.
 	for _, contract := range c.staticContracts.ViewAll() {
-		sb, utility, update, err := c.managedMarkContractUtility(contract, minScoreGFR, minScoreGFU)
+		update, suggested, err := c.managedMarkContractUtility(contract, minScoreGFR, minScoreGFU, medianPrice)
 		if err != nil {
 			return err
 		}
-		if update {
-			suggestedUpdateQueue = append(suggestedUpdateQueue, contractScoreAndUtil{contract, sb.Score, utility})
+		if suggested {
+			suggestedUpdateQueue = append(suggestedUpdateQueue, update)
 		}
 	}
 	// Process the suggested updates through the churn limiter.
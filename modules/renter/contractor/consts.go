@@ -17,6 +17,15 @@ var (
 	// funds.
 	AlertMSGAllowanceLowFunds = "At least one contract formation/renewal failed due to the allowance being low on funds"
 
+	// AlertMSGContractPriceOutlierKept indicates that a contract's host became a
+	// price outlier but the contract is kept because the churn budget doesn't
+	// allow for replacing it yet.
+	AlertMSGContractPriceOutlierKept = "Contract host became a price outlier but is kept until the churn budget allows replacing it"
+
+	// AlertMSGContractPriceOutlierReplaced indicates that a contract is being
+	// replaced because its host became a price outlier.
+	AlertMSGContractPriceOutlierReplaced = "Contract is being replaced because its host became a price outlier"
+
 	// AlertMSGFailedContractRenewal indicates that the contract renewal failed
 	AlertMSGFailedContractRenewal = "Contractor is attempting to renew/refresh contracts but failed"
 
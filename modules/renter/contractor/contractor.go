@@ -105,8 +105,9 @@ type Contractor struct {
 	renewedFrom          map[types.FileContractID]types.FileContractID
 	renewedTo            map[types.FileContractID]types.FileContractID
 
-	staticChurnLimiter *churnLimiter
-	staticWatchdog     *watchdog
+	staticChurnLimiter   *churnLimiter
+	staticSpendingLedger *spendingLedger
+	staticWatchdog       *watchdog
 }
 
 // PaymentDetails is a helper struct that contains extra information on a
@@ -497,11 +498,26 @@ func contractorBlockingStartup(cs modules.ConsensusSet, w modules.Wallet, tp mod
 	c.staticChurnLimiter = newChurnLimiter(c)
 	c.staticWatchdog = newWatchdog(c)
 
-	// Close the contract set and logger upon shutdown.
-	err := c.tg.AfterStop(func() error {
+	// Load the spending ledger and start recording the spending of the
+	// contract set.
+	spendingLedger, err := newSpendingLedger(persistDir)
+	if err != nil {
+		return nil, err
+	}
+	c.staticSpendingLedger = spendingLedger
+	c.staticContracts.SetSpendingCallback(c.staticSpendingLedger.callRecord)
+
+	// Close the contract set, spending ledger and logger upon shutdown.
+	err = c.tg.AfterStop(func() error {
 		if err := c.staticContracts.Close(); err != nil {
 			return errors.AddContext(err, "failed to close contract set")
 		}
+		if err := c.staticSpendingLedger.managedPersist(); err != nil {
+			return errors.AddContext(err, "failed to persist spending ledger")
+		}
+		if err := c.staticSpendingLedger.staticAOP.Close(); err != nil {
+			return errors.AddContext(err, "failed to close spending ledger")
+		}
 		if err := c.log.Close(); err != nil {
 			return errors.AddContext(err, "failed to close the contractor logger")
 		}
@@ -580,6 +596,9 @@ func NewCustomContractor(cs modules.ConsensusSet, w modules.Wallet, tp modules.T
 		return nil, errChan
 	}
 
+	// Periodically flush the spending ledger to disk.
+	go c.threadedPersistSpendingLedger()
+
 	// non-blocking startup.
 	go func() {
 		// Subscribe to the consensus set in a separate goroutine.
@@ -0,0 +1,59 @@
+package contractor
+
+import (
+	"io"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// CopySectorSource returns the ID of a contract with the host, other than the
+// host's current contract, that contains the sector with the given root
+// according to the roots the renter recorded for the contract. Contracts whose
+// proof window already started are skipped since the host won't copy sectors
+// from them anymore.
+func (c *Contractor) CopySectorSource(host types.SiaPublicKey, root crypto.Hash) (types.FileContractID, bool) {
+	c.mu.RLock()
+	currentID := c.pubKeysToContractID[host.String()]
+	blockHeight := c.blockHeight
+	c.mu.RUnlock()
+
+	for _, contract := range c.staticContracts.ViewAll() {
+		if contract.ID == currentID || !contract.HostPublicKey.Equals(host) || blockHeight >= contract.EndHeight {
+			continue
+		}
+		sc, ok := c.staticContracts.Acquire(contract.ID)
+		if !ok {
+			continue
+		}
+		hasRoot, err := sc.HasSectorRoot(root)
+		c.staticContracts.Return(sc)
+		if err != nil {
+			c.log.Printf("Failed to check roots of contract %v: %v", contract.ID, err)
+			continue
+		}
+		if hasRoot {
+			return contract.ID, true
+		}
+	}
+	return types.FileContractID{}, false
+}
+
+// FinalizeAppendProgram conducts the revision signing handshake which
+// finalizes a write program that appended the sector with the given root to
+// the contract with the given id.
+func (c *Contractor) FinalizeAppendProgram(stream io.ReadWriter, id types.FileContractID, root crypto.Hash, lastOutput modules.RPCExecuteProgramResponse, bh types.BlockHeight) error {
+	return c.staticContracts.FinalizeAppendProgram(stream, id, root, lastOutput, bh)
+}
+
+// SignCopySector signs the renter's authorization for the host to copy the
+// sector with the given root from the contract srcID to the contract dstID.
+func (c *Contractor) SignCopySector(srcID, dstID types.FileContractID, root crypto.Hash) (crypto.Signature, error) {
+	sc, ok := c.staticContracts.Acquire(srcID)
+	if !ok {
+		return crypto.Signature{}, errContractNotFound
+	}
+	defer c.staticContracts.Return(sc)
+	return sc.Sign(modules.CopySectorSigHash(srcID, dstID, root)), nil
+}
@@ -63,6 +63,8 @@ func TestSaveLoad(t *testing.T) {
 	}
 
 	expectedArchivedContract := modules.ContractWatchStatus{
+		ContractID:                types.FileContractID{2},
+		HostPublicKey:             types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}},
 		Archived:                  true,
 		FormationSweepHeight:      11,
 		ContractFound:             true,
@@ -71,6 +73,7 @@ func TestSaveLoad(t *testing.T) {
 		DoubleSpendHeight:         12333333,
 		WindowStart:               1111111231209,
 		WindowEnd:                 123808900,
+		ProofOutcome:              modules.ProofOutcomeValid,
 	}
 	c.staticWatchdog.archivedContracts = map[types.FileContractID]modules.ContractWatchStatus{
 		{2}: expectedArchivedContract,
@@ -0,0 +1,209 @@
+package contractor
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// priceOutlierMultiplier is the factor by which the estimated cost of a
+	// contract with a host may exceed the median estimated cost of the active
+	// hosts in the hostdb before the host is considered a price outlier.
+	// Contracts with price outliers are replaced within the churn budget.
+	priceOutlierMultiplier = 2.0
+)
+
+var (
+	// minPriceOutlierSampleSize is the minimum number of active hosts with
+	// known prices within the allowance that are required to compute a
+	// meaningful median host price. With fewer hosts no contract is considered
+	// a price outlier.
+	minPriceOutlierSampleSize = build.Select(build.Var{
+		Dev:      3,
+		Standard: 10,
+		Testing:  3,
+	}).(int)
+)
+
+// hostPriceOutlier contains the information about a host that is considered
+// a price outlier compared to the other hosts in the hostdb.
+type hostPriceOutlier struct {
+	hostPrice   types.Currency
+	medianPrice types.Currency
+}
+
+// alertID returns the id of the alert that is registered for the price
+// outlier decision of the given contract.
+func (po hostPriceOutlier) alertID(fcid types.FileContractID) modules.AlertID {
+	return modules.AlertIDRenterContractPriceOutlier(fcid.String())
+}
+
+// cause returns a human readable explanation of the price delta.
+func (po hostPriceOutlier) cause(contract modules.RenterContract) string {
+	delta := po.hostPrice.Sub(po.medianPrice)
+	percent := new(big.Rat).SetFrac(delta.Big(), po.medianPrice.Big())
+	percent.Mul(percent, big.NewRat(100, 1))
+	return fmt.Sprintf("host %v charges an estimated %v per period for contract %v which is %v (%v%%) more than the median of %v",
+		contract.HostPublicKey, po.hostPrice.HumanString(), contract.ID, delta.HumanString(), percent.FloatString(0), po.medianPrice.HumanString())
+}
+
+// hostPrices contains the prices of a host that are relevant for estimating
+// the cost of a contract with the host.
+type hostPrices struct {
+	contract types.Currency
+	storage  types.Currency
+	upload   types.Currency
+	download types.Currency
+}
+
+// settingsPrices returns the prices of a host's external settings.
+func settingsPrices(settings modules.HostExternalSettings) hostPrices {
+	return hostPrices{
+		contract: settings.ContractPrice,
+		storage:  settings.StoragePrice,
+		upload:   settings.UploadBandwidthPrice,
+		download: settings.DownloadBandwidthPrice,
+	}
+}
+
+// priceTablePrices returns the prices of a host's price table.
+func priceTablePrices(pt modules.RPCPriceTable) hostPrices {
+	return hostPrices{
+		contract: pt.ContractPrice,
+		storage:  pt.WriteStoreCost,
+		upload:   pt.UploadBandwidthCost,
+		download: pt.DownloadBandwidthCost,
+	}
+}
+
+// hostPriceEstimate estimates the cost of using a contract with a host with
+// the given prices for a whole period. The estimate is based on the expected
+// usage of the allowance divided over the number of hosts.
+func hostPriceEstimate(allowance modules.Allowance, prices hostPrices) types.Currency {
+	hosts := allowance.Hosts
+	if hosts == 0 {
+		hosts = 1
+	}
+	period := uint64(allowance.Period)
+	redundancy := allowance.ExpectedRedundancy
+	if redundancy < 1 {
+		redundancy = 1
+	}
+	storage := uint64(float64(allowance.ExpectedStorage)*redundancy) / hosts
+	upload := uint64(float64(allowance.ExpectedUpload)*redundancy) * period / hosts
+	download := allowance.ExpectedDownload * period / hosts
+
+	cost := prices.contract
+	cost = cost.Add(prices.storage.Mul64(storage).Mul64(period))
+	cost = cost.Add(prices.upload.Mul64(upload))
+	cost = cost.Add(prices.download.Mul64(download))
+	return cost
+}
+
+// managedRenewPrices returns the prices the host is going to charge for
+// renewing a contract. For hosts that renew using RHP3 those are the prices of
+// the host's most recent price table, which is fetched by the host's worker.
+// Legacy hosts renew using their external settings. If the worker doesn't have
+// a valid price table for the host, false is returned.
+func (c *Contractor) managedRenewPrices(host modules.HostDBEntry) (hostPrices, bool) {
+	if build.VersionCmp(host.Version, "1.5.4") < 0 {
+		return settingsPrices(host.HostExternalSettings), true
+	}
+	c.mu.RLock()
+	wp := c.workerPool
+	c.mu.RUnlock()
+	w, err := wp.Worker(host.PublicKey)
+	if err != nil {
+		return hostPrices{}, false
+	}
+	pt, ok := w.PriceTable()
+	if !ok {
+		return hostPrices{}, false
+	}
+	return priceTablePrices(pt), true
+}
+
+// managedMedianHostPrice returns the median estimated period cost of the
+// active hosts in the hostdb. If there are not enough active hosts to compute
+// a meaningful median, false is returned.
+func (c *Contractor) managedMedianHostPrice(allowance modules.Allowance) (types.Currency, bool, error) {
+	hosts, err := c.hdb.ActiveHosts()
+	if err != nil {
+		return types.ZeroCurrency, false, err
+	}
+	median, ok := medianHostPrice(allowance, hosts, c.managedRenewPrices)
+	return median, ok, nil
+}
+
+// medianHostPrice returns the median estimated period cost of the given hosts.
+// The prices of every host are taken from renewPrices, which is the same
+// source the prices of a renewing host are taken from, so hosts are compared
+// like for like. Hosts without known prices and hosts that the allowance
+// wouldn't form contracts with are excluded from the sample. If fewer than
+// minPriceOutlierSampleSize hosts remain, false is returned.
+func medianHostPrice(allowance modules.Allowance, hosts []modules.HostDBEntry, renewPrices func(modules.HostDBEntry) (hostPrices, bool)) (types.Currency, bool) {
+	prices := make([]types.Currency, 0, len(hosts))
+	for _, host := range hosts {
+		if checkFormContractGouging(allowance, host.HostExternalSettings) != nil {
+			continue
+		}
+		hp, ok := renewPrices(host)
+		if !ok || checkHostPricesAllowance(allowance, hp) != nil {
+			continue
+		}
+		prices = append(prices, hostPriceEstimate(allowance, hp))
+	}
+	if len(prices) < minPriceOutlierSampleSize {
+		return types.ZeroCurrency, false
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	return prices[len(prices)/2], true
+}
+
+// checkHostPricesAllowance checks whether the prices of a host exceed the
+// maximum prices of the allowance.
+func checkHostPricesAllowance(allowance modules.Allowance, prices hostPrices) error {
+	if !allowance.MaxContractPrice.IsZero() && allowance.MaxContractPrice.Cmp(prices.contract) < 0 {
+		return errors.New("contract price of host is above the allowance's maximum")
+	}
+	if !allowance.MaxStoragePrice.IsZero() && allowance.MaxStoragePrice.Cmp(prices.storage) < 0 {
+		return errors.New("storage price of host is above the allowance's maximum")
+	}
+	if !allowance.MaxUploadBandwidthPrice.IsZero() && allowance.MaxUploadBandwidthPrice.Cmp(prices.upload) < 0 {
+		return errors.New("upload bandwidth price of host is above the allowance's maximum")
+	}
+	if !allowance.MaxDownloadBandwidthPrice.IsZero() && allowance.MaxDownloadBandwidthPrice.Cmp(prices.download) < 0 {
+		return errors.New("download bandwidth price of host is above the allowance's maximum")
+	}
+	return nil
+}
+
+// priceOutlierCheck checks whether the host of a contract became a price
+// outlier compared to the median price of the hosts in the hostdb. The prices
+// are the prices the host is going to charge for renewing the contract.
+// Outliers are suggested to be churned since renewing the contract would lock
+// in the increased prices for another period.
+func (c *Contractor) priceOutlierCheck(contract modules.RenterContract, prices hostPrices, allowance modules.Allowance, medianPrice types.Currency) (modules.ContractUtility, *hostPriceOutlier, bool) {
+	u := contract.Utility
+	if medianPrice.IsZero() {
+		return u, nil, false
+	}
+	hostPrice := hostPriceEstimate(allowance, prices)
+	if hostPrice.Cmp(medianPrice.MulFloat(priceOutlierMultiplier)) <= 0 {
+		return u, nil, false
+	}
+	u.GoodForUpload = false
+	u.GoodForRenew = false
+	return u, &hostPriceOutlier{
+		hostPrice:   hostPrice,
+		medianPrice: medianPrice,
+	}, true
+}
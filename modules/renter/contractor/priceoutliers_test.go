@@ -0,0 +1,159 @@
+package contractor
+
+import (
+	"strings"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestPriceOutlierCheck tests that hosts are only considered price outliers if
+// their estimated price exceeds the median by more than the multiplier.
+func TestPriceOutlierCheck(t *testing.T) {
+	t.Parallel()
+
+	c := &Contractor{}
+	allowance := modules.DefaultAllowance
+	contract := modules.RenterContract{
+		ID:            types.FileContractID{1},
+		HostPublicKey: types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}},
+		Utility: modules.ContractUtility{
+			GoodForUpload: true,
+			GoodForRenew:  true,
+		},
+	}
+	var pt modules.RPCPriceTable
+	pt.WriteStoreCost = types.SiacoinPrecision.Div64(1e9)
+	pt.UploadBandwidthCost = types.SiacoinPrecision.Div64(1e10)
+	pt.DownloadBandwidthCost = types.SiacoinPrecision.Div64(1e10)
+	pt.ContractPrice = types.SiacoinPrecision
+	prices := priceTablePrices(pt)
+	hostPrice := hostPriceEstimate(allowance, prices)
+
+	// Without a median price, no host is an outlier.
+	u, _, outlier := c.priceOutlierCheck(contract, prices, allowance, types.ZeroCurrency)
+	if outlier || !u.GoodForRenew || !u.GoodForUpload {
+		t.Fatal("host shouldn't be an outlier without a median")
+	}
+
+	// A host at the median is not an outlier.
+	u, _, outlier = c.priceOutlierCheck(contract, prices, allowance, hostPrice)
+	if outlier || !u.GoodForRenew || !u.GoodForUpload {
+		t.Fatal("host shouldn't be an outlier at the median")
+	}
+
+	// A host that charges more than the multiplier is an outlier.
+	median := hostPrice.Div64(uint64(priceOutlierMultiplier) + 1)
+	u, po, outlier := c.priceOutlierCheck(contract, prices, allowance, median)
+	if !outlier || u.GoodForRenew || u.GoodForUpload {
+		t.Fatal("host should be an outlier")
+	}
+	if !po.hostPrice.Equals(hostPrice) || !po.medianPrice.Equals(median) {
+		t.Fatal("wrong outlier prices", po.hostPrice, po.medianPrice)
+	}
+	if !strings.Contains(po.cause(contract), contract.ID.String()) {
+		t.Fatal("cause should mention the contract", po.cause(contract))
+	}
+}
+
+// TestHostPriceEstimate tests that the price estimate increases with every
+// price of the host's settings and price table.
+func TestHostPriceEstimate(t *testing.T) {
+	t.Parallel()
+
+	allowance := modules.DefaultAllowance
+	var base modules.HostExternalSettings
+	basePrice := hostPriceEstimate(allowance, settingsPrices(base))
+	if !basePrice.IsZero() {
+		t.Fatal("free host should have a zero estimate", basePrice)
+	}
+
+	settings := []func(*modules.HostExternalSettings){
+		func(s *modules.HostExternalSettings) { s.ContractPrice = types.NewCurrency64(1) },
+		func(s *modules.HostExternalSettings) { s.StoragePrice = types.NewCurrency64(1) },
+		func(s *modules.HostExternalSettings) { s.UploadBandwidthPrice = types.NewCurrency64(1) },
+		func(s *modules.HostExternalSettings) { s.DownloadBandwidthPrice = types.NewCurrency64(1) },
+	}
+	for i, set := range settings {
+		s := base
+		set(&s)
+		if hostPriceEstimate(allowance, settingsPrices(s)).Cmp(basePrice) <= 0 {
+			t.Fatal("estimate didn't increase for setting", i)
+		}
+	}
+
+	var basePT modules.RPCPriceTable
+	if !hostPriceEstimate(allowance, priceTablePrices(basePT)).Equals(basePrice) {
+		t.Fatal("free host should have the same estimate for its price table")
+	}
+	priceTable := []func(*modules.RPCPriceTable){
+		func(pt *modules.RPCPriceTable) { pt.ContractPrice = types.NewCurrency64(1) },
+		func(pt *modules.RPCPriceTable) { pt.WriteStoreCost = types.NewCurrency64(1) },
+		func(pt *modules.RPCPriceTable) { pt.UploadBandwidthCost = types.NewCurrency64(1) },
+		func(pt *modules.RPCPriceTable) { pt.DownloadBandwidthCost = types.NewCurrency64(1) },
+	}
+	for i, set := range priceTable {
+		pt := basePT
+		set(&pt)
+		if hostPriceEstimate(allowance, priceTablePrices(pt)).Cmp(basePrice) <= 0 {
+			t.Fatal("estimate didn't increase for price table field", i)
+		}
+	}
+}
+
+// TestMedianHostPrice tests that the median host price is computed from the
+// renewal prices of the hosts that pass the allowance's price checks.
+func TestMedianHostPrice(t *testing.T) {
+	t.Parallel()
+
+	allowance := modules.DefaultAllowance
+	allowance.MaxContractPrice = types.SiacoinPrecision.Mul64(10)
+	allowance.MaxStoragePrice = types.SiacoinPrecision
+
+	// Every host's settings advertise the same prices but the renewal prices
+	// differ.
+	var hosts []modules.HostDBEntry
+	renewPrices := make(map[string]hostPrices)
+	for i := 0; i < 12; i++ {
+		host := modules.HostDBEntry{PublicKey: types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{byte(i)}}}
+		host.ContractPrice = types.SiacoinPrecision
+		hosts = append(hosts, host)
+		renewPrices[host.PublicKey.String()] = hostPrices{contract: types.SiacoinPrecision.Mul64(uint64(i + 1))}
+	}
+	pricesFn := func(host modules.HostDBEntry) (hostPrices, bool) {
+		hp, ok := renewPrices[host.PublicKey.String()]
+		return hp, ok
+	}
+
+	// Hosts with contract prices above the allowance's maximum are excluded,
+	// which leaves the hosts charging 1 to 10 SC.
+	median, ok := medianHostPrice(allowance, hosts, pricesFn)
+	if !ok {
+		t.Fatal("expected a median")
+	}
+	expected := hostPriceEstimate(allowance, hostPrices{contract: types.SiacoinPrecision.Mul64(6)})
+	if !median.Equals(expected) {
+		t.Fatal("wrong median", median, expected)
+	}
+
+	// Hosts with unknown renewal prices, price gouging settings or storage
+	// prices above the allowance's maximum are excluded as well.
+	delete(renewPrices, hosts[0].PublicKey.String())
+	hosts[1].BaseRPCPrice = types.SiacoinPrecision
+	allowance.MaxRPCPrice = types.SiacoinPrecision.Div64(2)
+	renewPrices[hosts[2].PublicKey.String()] = hostPrices{contract: types.SiacoinPrecision.Mul64(3), storage: types.SiacoinPrecision.Mul64(2)}
+	median, ok = medianHostPrice(allowance, hosts, pricesFn)
+	if !ok {
+		t.Fatal("expected a median")
+	}
+	expected = hostPriceEstimate(allowance, hostPrices{contract: types.SiacoinPrecision.Mul64(7)})
+	if !median.Equals(expected) {
+		t.Fatal("wrong median", median, expected)
+	}
+
+	// Without enough hosts in the sample, there is no median.
+	if _, ok := medianHostPrice(allowance, hosts[:minPriceOutlierSampleSize+2], pricesFn); ok {
+		t.Fatal("expected no median")
+	}
+}
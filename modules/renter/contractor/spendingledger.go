@@ -0,0 +1,292 @@
+package contractor
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// spendingLedgerFile is the name of the file that holds the contract
+	// spending ledger.
+	spendingLedgerFile = "spendingledger.dat"
+
+	// spendingLedgerSegmentSize is the number of persisted entries that are
+	// indexed by a single segment. Only the segment index is kept in memory so
+	// this bounds the memory used by the ledger while still allowing queries
+	// to skip most of the file.
+	spendingLedgerSegmentSize = 1000
+)
+
+var (
+	// spendingLedgerMetadataHeader is the header of the metadata for the
+	// spending ledger persist file.
+	spendingLedgerMetadataHeader = types.NewSpecifier("SpendingLedger\n")
+
+	// spendingLedgerPersistInterval is the interval at which the entries that
+	// were recorded since the last flush are appended to the ledger on disk.
+	// Spending happens on every sector that is uploaded or downloaded so we
+	// batch the writes instead of syncing the file for every single entry.
+	spendingLedgerPersistInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+)
+
+// spendingLedger is an append-only record of every spend of the contractor's
+// contracts. Recorded entries are kept in memory until
+// threadedPersistSpendingLedger appends them to disk in batches. Persisted
+// entries are only indexed by segments and are read back from disk when
+// queried.
+type spendingLedger struct {
+	segments []spendingLedgerSegment
+	unsynced []modules.ContractSpendingEntry
+
+	staticAOP *persist.AppendOnlyPersist
+	mu        sync.Mutex
+	persistMu sync.Mutex
+}
+
+// spendingLedgerSegment describes a contiguous range of persisted entries in
+// the ledger file and the time range they cover. Queries only read the
+// segments that overlap the requested time range.
+type spendingLedgerSegment struct {
+	offset       int64
+	length       int64
+	numEntries   int
+	minTimestamp int64
+	maxTimestamp int64
+}
+
+// extend adds an entry of the given length at the end of the segment.
+func (seg *spendingLedgerSegment) extend(length int64, timestamp int64) {
+	if seg.numEntries == 0 || timestamp < seg.minTimestamp {
+		seg.minTimestamp = timestamp
+	}
+	if seg.numEntries == 0 || timestamp > seg.maxTimestamp {
+		seg.maxTimestamp = timestamp
+	}
+	seg.length += length
+	seg.numEntries++
+}
+
+// overlaps returns whether the segment contains entries within the inclusive
+// time range.
+func (seg spendingLedgerSegment) overlaps(start, end int64) bool {
+	return seg.minTimestamp <= end && seg.maxTimestamp >= start
+}
+
+// newSpendingLedger loads the spending ledger from the given directory or
+// creates a new one if it doesn't exist yet.
+func newSpendingLedger(dir string) (*spendingLedger, error) {
+	aop, reader, err := persist.NewAppendOnlyPersist(dir, spendingLedgerFile, spendingLedgerMetadataHeader, persist.MetadataVersionv156)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to open spending ledger")
+	}
+	sl := &spendingLedger{
+		staticAOP: aop,
+	}
+	err = readSpendingEntries(reader, func(entry modules.ContractSpendingEntry, length int64) {
+		sl.appendSegments(entry, length)
+	})
+	if err != nil {
+		return nil, errors.Compose(errors.AddContext(err, "unable to load spending ledger"), aop.Close())
+	}
+	return sl, nil
+}
+
+// appendSegments adds a persisted entry of the given length to the segment
+// index. The entry starts a new segment if the last one is full.
+func (sl *spendingLedger) appendSegments(entry modules.ContractSpendingEntry, length int64) {
+	n := len(sl.segments)
+	if n == 0 || sl.segments[n-1].numEntries >= spendingLedgerSegmentSize {
+		offset := int64(persist.MetadataPageSize)
+		if n > 0 {
+			offset = sl.segments[n-1].offset + sl.segments[n-1].length
+		}
+		sl.segments = append(sl.segments, spendingLedgerSegment{offset: offset})
+		n++
+	}
+	sl.segments[n-1].extend(length, entry.Timestamp)
+}
+
+// callEntries returns the entries that were recorded within the inclusive
+// time range. If the host is not empty, only entries for that host are
+// returned.
+func (sl *spendingLedger) callEntries(start, end time.Time, host types.SiaPublicKey) ([]modules.ContractSpendingEntry, error) {
+	startUnix, endUnix := start.Unix(), end.Unix()
+	filterHost := host.Key != nil
+	hostStr := host.String()
+	entries := make([]modules.ContractSpendingEntry, 0)
+	addEntry := func(entry modules.ContractSpendingEntry, _ int64) {
+		if entry.Timestamp < startUnix || entry.Timestamp > endUnix {
+			return
+		}
+		if filterHost && entry.HostPublicKey.String() != hostStr {
+			return
+		}
+		entries = append(entries, entry)
+	}
+
+	// Grab the segments to read and the entries that are not on disk yet.
+	// Persisted data is never modified so the segments can be read without
+	// holding the lock.
+	sl.mu.Lock()
+	var segments []spendingLedgerSegment
+	for _, seg := range sl.segments {
+		if seg.overlaps(startUnix, endUnix) {
+			segments = append(segments, seg)
+		}
+	}
+	unsynced := append([]modules.ContractSpendingEntry(nil), sl.unsynced...)
+	sl.mu.Unlock()
+
+	if len(segments) > 0 {
+		f, err := os.Open(sl.staticAOP.FilePath())
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to open spending ledger")
+		}
+		for _, seg := range segments {
+			err = readSpendingEntries(io.NewSectionReader(f, seg.offset, seg.length), addEntry)
+			if err != nil {
+				break
+			}
+		}
+		err = errors.Compose(err, f.Close())
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to read spending ledger")
+		}
+	}
+	for _, entry := range unsynced {
+		addEntry(entry, 0)
+	}
+	return entries, nil
+}
+
+// callRecord adds the entries to the ledger. They are persisted on the next
+// call to managedPersist.
+func (sl *spendingLedger) callRecord(entries []modules.ContractSpendingEntry) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.unsynced = append(sl.unsynced, entries...)
+}
+
+// managedPersist appends the entries that were recorded since the last call to
+// the ledger on disk.
+func (sl *spendingLedger) managedPersist() error {
+	sl.persistMu.Lock()
+	defer sl.persistMu.Unlock()
+
+	// The entries stay in memory until they were written to disk and added to
+	// the segments to make sure queries don't miss them in the meantime.
+	sl.mu.Lock()
+	unsynced := sl.unsynced
+	sl.mu.Unlock()
+	if len(unsynced) == 0 {
+		return nil
+	}
+
+	var data []byte
+	lengths := make([]int64, len(unsynced))
+	for i, entry := range unsynced {
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return errors.AddContext(err, "unable to marshal spending ledger entry")
+		}
+		data = append(append(data, b...), '\n')
+		lengths[i] = int64(len(b) + 1)
+	}
+	if _, err := sl.staticAOP.Write(data); err != nil {
+		return errors.AddContext(err, "unable to persist spending ledger entries")
+	}
+
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	for i, entry := range unsynced {
+		sl.appendSegments(entry, lengths[i])
+	}
+	sl.unsynced = sl.unsynced[len(unsynced):]
+	return nil
+}
+
+// readSpendingEntries reads the persisted entries of the ledger from the
+// reader. Every entry is a json object on its own line. The callback is called
+// with every entry and its length on disk.
+func readSpendingEntries(r io.Reader, fn func(modules.ContractSpendingEntry, int64)) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Contains(err, io.EOF) && len(line) == 0 {
+			return nil
+		} else if err != nil {
+			return errors.AddContext(err, "unable to read from reader")
+		}
+		var entry modules.ContractSpendingEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return errors.AddContext(err, "unable to unmarshal entry")
+		}
+		fn(entry, int64(len(line)))
+	}
+}
+
+// threadedPersistSpendingLedger periodically flushes the recorded spending
+// ledger entries to disk until the contractor is shut down.
+func (c *Contractor) threadedPersistSpendingLedger() {
+	if err := c.tg.Add(); err != nil {
+		return
+	}
+	defer c.tg.Done()
+
+	for {
+		select {
+		case <-c.tg.StopChan():
+			return
+		case <-time.After(spendingLedgerPersistInterval):
+		}
+		if err := c.staticSpendingLedger.managedPersist(); err != nil {
+			c.log.Println("WARN: failed to persist spending ledger:", err)
+		}
+	}
+}
+
+// RecordSpending records money that was spent from the ephemeral account on
+// the given host in the spending ledger. The entry is attributed to the
+// current contract with the host.
+func (c *Contractor) RecordSpending(host types.SiaPublicKey, category modules.ContractSpendingCategory, amount types.Currency) {
+	if amount.IsZero() {
+		return
+	}
+	c.mu.RLock()
+	fcid := c.pubKeysToContractID[host.String()]
+	c.mu.RUnlock()
+	c.staticSpendingLedger.callRecord([]modules.ContractSpendingEntry{{
+		ContractID:       fcid,
+		HostPublicKey:    host,
+		Category:         category,
+		Amount:           amount,
+		EphemeralAccount: true,
+		Timestamp:        time.Now().Unix(),
+	}})
+}
+
+// SpendingLedger returns the entries of the spending ledger that were recorded
+// between start and end. If host is not empty, only the entries for that host
+// are returned.
+func (c *Contractor) SpendingLedger(start, end time.Time, host types.SiaPublicKey) ([]modules.ContractSpendingEntry, error) {
+	if err := c.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer c.tg.Done()
+	return c.staticSpendingLedger.callEntries(start, end, host)
+}
@@ -0,0 +1,179 @@
+package contractor
+
+import (
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestSpendingLedger verifies that the spending ledger records, persists and
+// filters its entries correctly.
+func TestSpendingLedger(t *testing.T) {
+	t.Parallel()
+
+	dir := build.TempDir("contractor", t.Name())
+	sl, err := newSpendingLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record spending for two hosts.
+	hpk1 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	hpk2 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+	sd := modules.SpendingDetails{
+		DownloadSpending: types.NewCurrency64(1),
+		StorageSpending:  types.NewCurrency64(2),
+	}
+	sl.callRecord(sd.LedgerEntries(types.FileContractID{1}, hpk1, 100))
+	sl.callRecord(sd.LedgerEntries(types.FileContractID{2}, hpk2, 200))
+
+	// Zero amounts should be omitted.
+	entries, err := sl.callEntries(time.Unix(0, 0), types.EndOfTime, types.SiaPublicKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatal("wrong number of entries", len(entries))
+	}
+
+	// Filter by host.
+	entries, err = sl.callEntries(time.Unix(0, 0), types.EndOfTime, hpk2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatal("wrong number of entries", len(entries))
+	}
+	for _, entry := range entries {
+		if !entry.HostPublicKey.Equals(hpk2) || entry.ContractID != (types.FileContractID{2}) {
+			t.Fatal("wrong entry", entry)
+		}
+	}
+
+	// Filter by time.
+	entries, err = sl.callEntries(time.Unix(50, 0), time.Unix(150, 0), types.SiaPublicKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatal("wrong number of entries", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Timestamp != 100 {
+			t.Fatal("wrong entry", entry)
+		}
+	}
+
+	// Persist and reload the ledger.
+	if err := sl.managedPersist(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.staticAOP.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sl, err = newSpendingLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sl.segments) != 1 || len(sl.unsynced) != 0 {
+		t.Fatal("persisted entries should only be indexed", len(sl.segments), len(sl.unsynced))
+	}
+	entries, err = sl.callEntries(time.Unix(0, 0), types.EndOfTime, types.SiaPublicKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatal("wrong number of entries after reload", len(entries))
+	}
+	if err := sl.staticAOP.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSpendingLedgerSegments verifies that queries read the right entries from
+// disk when the ledger spans multiple segments.
+func TestSpendingLedgerSegments(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("contractor", t.Name())
+	sl, err := newSpendingLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hpk := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	record := func(start, n int) {
+		for i := start; i < start+n; i++ {
+			sl.callRecord([]modules.ContractSpendingEntry{{
+				HostPublicKey: hpk,
+				Category:      modules.SpendingCategoryUpload,
+				Amount:        types.NewCurrency64(uint64(i)),
+				Timestamp:     int64(i),
+			}})
+		}
+	}
+
+	// Persist the entries in two batches that don't line up with the
+	// segments and keep the last batch in memory.
+	numEntries := 2*spendingLedgerSegmentSize + spendingLedgerSegmentSize/2
+	record(0, spendingLedgerSegmentSize/2)
+	if err := sl.managedPersist(); err != nil {
+		t.Fatal(err)
+	}
+	record(spendingLedgerSegmentSize/2, 2*spendingLedgerSegmentSize)
+	if err := sl.managedPersist(); err != nil {
+		t.Fatal(err)
+	}
+	record(numEntries, 10)
+
+	// checkRange checks that querying the range returns all entries within
+	// it in the order they were recorded.
+	checkRange := func(start, end int) {
+		t.Helper()
+		entries, err := sl.callEntries(time.Unix(int64(start), 0), time.Unix(int64(end), 0), types.SiaPublicKey{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != end-start+1 {
+			t.Fatalf("expected %v entries but got %v", end-start+1, len(entries))
+		}
+		for i, entry := range entries {
+			if entry.Timestamp != int64(start+i) || !entry.Amount.Equals64(uint64(start+i)) {
+				t.Fatal("wrong entry", i, entry)
+			}
+		}
+	}
+	check := func() {
+		t.Helper()
+		if len(sl.segments) != 3 {
+			t.Fatal("wrong number of segments", len(sl.segments))
+		}
+		checkRange(0, numEntries+9)
+		checkRange(10, 20)
+		checkRange(spendingLedgerSegmentSize-5, spendingLedgerSegmentSize+5)
+		checkRange(numEntries-5, numEntries+5)
+		checkRange(numEntries+5, numEntries+9)
+	}
+	check()
+
+	// Reload the ledger and check again.
+	if err := sl.managedPersist(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sl.staticAOP.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sl, err = newSpendingLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check()
+	if err := sl.staticAOP.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
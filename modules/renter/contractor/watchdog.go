@@ -1,11 +1,14 @@
 package contractor
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 
 	"gitlab.com/NebulousLabs/errors"
 
+	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
@@ -23,10 +26,13 @@ import (
 // has ever submitted a valid storage proof, then from the renter's point of
 // view they have fulfilled their obligation for the contract.
 //
-// TODOs:
-// - Perform action when storage proof is found and when missing at the end of
-//   the window.
+// At the end of a contract's proof window the watchdog records whether the
+// host submitted a storage proof. Missed proofs are counted as failed
+// interactions in the hostdb and valid proofs as successful ones, which feeds
+// into the host's score. The outcomes are also aggregated per host so that
+// users can audit the reliability of their hosts.
 //
+// TODOs:
 // - When creating sweep transaction, add parent transactions if the renter's
 //   own dependencies are causing this to be triggered.
 
@@ -40,6 +46,11 @@ type watchdog struct {
 	// archival purposes.
 	archivedContracts map[types.FileContractID]modules.ContractWatchStatus
 
+	// hostProofStats contains the number of valid and missed storage proofs of
+	// every host the watchdog has seen a contract's proof window end for. It is
+	// indexed by the string representation of the hosts' public keys.
+	hostProofStats map[string]modules.HostStorageProofStats
+
 	// outputDependencies maps Siacoin outputs to the file contracts that are
 	// dependent on them. When a contract is first submitted to the watchdog to be
 	// monitored, the outputDependencies created for that contract are the
@@ -74,6 +85,14 @@ type fileContractStatus struct {
 	revisionFound        uint64            // store the revision number found
 	storageProofFound    types.BlockHeight // store the blockheight at which the proof was found.
 
+	// hostPublicKey is the public key of the contract's host. It is used to
+	// attribute the outcome of the storage proof window to the host.
+	hostPublicKey types.SiaPublicKey
+
+	// proofNotRequired indicates that the latest known revision of the
+	// contract doesn't require the host to submit a storage proof.
+	proofNotRequired bool
+
 	// While watching for contract formation, the watchdog may periodically
 	// rebroadcast the initial file contract transaction and unconfirmed parent
 	// transactions. Any transactions in the original txn set that have been found
@@ -120,6 +139,7 @@ func newWatchdog(contractor *Contractor) *watchdog {
 	return &watchdog{
 		contracts:          make(map[types.FileContractID]*fileContractStatus),
 		archivedContracts:  make(map[types.FileContractID]modules.ContractWatchStatus),
+		hostProofStats:     make(map[string]modules.HostStorageProofStats),
 		outputDependencies: make(map[types.SiacoinOutputID]map[types.FileContractID]struct{}),
 
 		renewWindow: renewWindow,
@@ -140,6 +160,16 @@ func (c *Contractor) ContractStatus(fcID types.FileContractID) (modules.Contract
 	return c.staticWatchdog.managedContractStatus(fcID)
 }
 
+// ContractStatuses returns the status of all the contracts in the watchdog
+// together with the storage proof statistics of their hosts.
+func (c *Contractor) ContractStatuses() ([]modules.ContractWatchStatus, []modules.HostStorageProofStats) {
+	if err := c.tg.Add(); err != nil {
+		return nil, nil
+	}
+	defer c.tg.Done()
+	return c.staticWatchdog.managedContractStatuses()
+}
+
 // callAllowanceUpdated informs the watchdog of an allowance change.
 func (w *watchdog) callAllowanceUpdated(a modules.Allowance) {
 	w.mu.Lock()
@@ -189,6 +219,11 @@ func (w *watchdog) callMonitorContract(args monitorContractArgs) error {
 		windowStart:          args.revisionTxn.FileContractRevisions[0].NewWindowStart,
 		windowEnd:            args.revisionTxn.FileContractRevisions[0].NewWindowEnd,
 	}
+	rev := args.revisionTxn.FileContractRevisions[0]
+	if len(rev.UnlockConditions.PublicKeys) > 1 {
+		fileContractStatus.hostPublicKey = rev.HostPublicKey()
+	}
+	fileContractStatus.proofNotRequired = !revisionRequiresProof(rev)
 	w.contracts[args.fcID] = fileContractStatus
 
 	// Watch the parent outputs of this set.
@@ -258,19 +293,130 @@ func (w *watchdog) archiveContract(fcID types.FileContractID, doubleSpendHeight
 	for oid := range contractData.parentOutputs {
 		w.removeOutputDependency(oid, fcID)
 	}
-	w.archivedContracts[fcID] = modules.ContractWatchStatus{
-		Archived:                  true,
-		FormationSweepHeight:      contractData.formationSweepHeight,
-		ContractFound:             contractData.contractFound,
-		LatestRevisionFound:       contractData.revisionFound,
-		StorageProofFoundAtHeight: contractData.storageProofFound,
-		DoubleSpendHeight:         doubleSpendHeight,
-		WindowStart:               contractData.windowStart,
-		WindowEnd:                 contractData.windowEnd,
+	status := contractData.status(fcID, w.blockHeight)
+	status.Archived = true
+	status.DoubleSpendHeight = doubleSpendHeight
+	if doubleSpendHeight != 0 && status.StorageProofFoundAtHeight == 0 {
+		status.ProofOutcome = modules.ProofOutcomeNone
 	}
+	w.archivedContracts[fcID] = status
 	delete(w.contracts, fcID)
 }
 
+// status returns the watch status of a contract that is not archived yet.
+func (d *fileContractStatus) status(fcID types.FileContractID, blockHeight types.BlockHeight) modules.ContractWatchStatus {
+	return modules.ContractWatchStatus{
+		ContractID:                fcID,
+		HostPublicKey:             d.hostPublicKey,
+		Archived:                  false,
+		FormationSweepHeight:      d.formationSweepHeight,
+		ContractFound:             d.contractFound,
+		LatestRevisionFound:       d.revisionFound,
+		StorageProofFoundAtHeight: d.storageProofFound,
+		WindowStart:               d.windowStart,
+		WindowEnd:                 d.windowEnd,
+		ProofOutcome:              d.proofOutcome(blockHeight),
+	}
+}
+
+// proofOutcome returns the outcome of the contract's storage proof window at
+// the given height.
+func (d *fileContractStatus) proofOutcome(blockHeight types.BlockHeight) modules.ContractProofOutcome {
+	if d.storageProofFound != 0 {
+		return modules.ProofOutcomeValid
+	}
+	if blockHeight >= d.windowEnd && d.proofNotRequired {
+		return modules.ProofOutcomeNotRequired
+	}
+	if blockHeight >= d.windowEnd {
+		return modules.ProofOutcomeMissed
+	}
+	return modules.ProofOutcomePending
+}
+
+// revisionRequiresProof returns whether the host has to submit a storage proof
+// for the given revision. Empty contracts don't require a proof and neither do
+// contracts whose missed proof outputs equal the valid ones, e.g. renewed
+// contracts, since the host doesn't lose anything by not submitting one.
+func revisionRequiresProof(rev types.FileContractRevision) bool {
+	if rev.NewFileSize == 0 {
+		return false
+	}
+	if len(rev.NewValidProofOutputs) != len(rev.NewMissedProofOutputs) {
+		return true
+	}
+	for i, output := range rev.NewValidProofOutputs {
+		missed := rev.NewMissedProofOutputs[i]
+		if !output.Value.Equals(missed.Value) || output.UnlockHash != missed.UnlockHash {
+			return true
+		}
+	}
+	return false
+}
+
+// proofOutcome is the outcome of a contract's storage proof that is reported
+// to the hostdb once the watchdog's lock is released.
+type proofOutcome struct {
+	fcID          types.FileContractID
+	hostPublicKey types.SiaPublicKey
+	outcome       modules.ContractProofOutcome
+}
+
+// recordProofOutcome attributes the outcome of a contract's storage proof
+// to the host of the contract. It returns false if the outcome can't be
+// attributed and therefore shouldn't be reported to the hostdb.
+func (w *watchdog) recordProofOutcome(fcID types.FileContractID, hpk types.SiaPublicKey, outcome modules.ContractProofOutcome) bool {
+	if len(hpk.Key) == 0 {
+		// Contracts that were persisted before the host was tracked by the
+		// watchdog can't be attributed.
+		w.contractor.log.Debugln("unable to attribute proof outcome of contract to host", fcID, outcome)
+		return false
+	}
+	if outcome == modules.ProofOutcomeNotRequired {
+		// The host isn't expected to submit a proof so there is nothing to
+		// attribute.
+		w.contractor.log.Debugln("contract didn't require a storage proof", fcID)
+		return false
+	}
+	stats, ok := w.hostProofStats[hpk.String()]
+	if !ok {
+		stats.HostPublicKey = hpk
+	}
+
+	switch outcome {
+	case modules.ProofOutcomeValid:
+		stats.ValidProofs++
+	case modules.ProofOutcomeMissed:
+		stats.MissedProofs++
+		w.contractor.log.Printf("Host %v missed the storage proof for contract %v", hpk, fcID)
+	default:
+		build.Critical("recordProofOutcome called with unexpected outcome", outcome)
+		return false
+	}
+	w.hostProofStats[hpk.String()] = stats
+	return true
+}
+
+// managedReportProofOutcomes updates the interactions of the hosts in the
+// hostdb according to the outcomes of their storage proofs. It must be called
+// without holding the watchdog's lock.
+func (w *watchdog) managedReportProofOutcomes(outcomes []proofOutcome) {
+	for _, po := range outcomes {
+		var err error
+		switch po.outcome {
+		case modules.ProofOutcomeValid:
+			err = w.contractor.hdb.IncrementSuccessfulInteractions(po.hostPublicKey)
+		case modules.ProofOutcomeMissed:
+			err = w.contractor.hdb.IncrementFailedInteractions(po.hostPublicKey)
+		default:
+			continue
+		}
+		if err != nil {
+			w.contractor.log.Println("WARN: failed to update host interactions for proof outcome of contract", po.fcID, err)
+		}
+	}
+}
+
 // addOutputDependency marks the contract with fcID as dependent on this Siacoin
 // output.
 func (w *watchdog) addOutputDependency(outputID types.SiacoinOutputID, fcID types.FileContractID) {
@@ -384,6 +530,7 @@ func (w *watchdog) scanAppliedBlock(block types.Block) {
 		for _, rev := range txn.FileContractRevisions {
 			if contractData, ok := w.contracts[rev.ParentID]; ok {
 				contractData.revisionFound = rev.NewRevisionNumber
+				contractData.proofNotRequired = !revisionRequiresProof(rev)
 				w.contractor.log.Debugln("Found revision for: ", rev.ParentID, rev.NewRevisionNumber)
 			}
 		}
@@ -658,6 +805,12 @@ func (w *watchdog) addDependencyToContractFormationSet(fcID types.FileContractID
 // their expiration window, and notifies the contractor of the storage proof
 // status.
 func (w *watchdog) callCheckContracts() {
+	// Report the proof outcomes to the hostdb after the lock is released by
+	// the deferred Unlock below.
+	var outcomes []proofOutcome
+	defer func() {
+		w.managedReportProofOutcomes(outcomes)
+	}()
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	w.contractor.log.Debugln("Watchdog checking contracts at height:", w.blockHeight)
@@ -684,12 +837,14 @@ func (w *watchdog) callCheckContracts() {
 		}
 
 		if w.blockHeight >= contractData.windowEnd {
-			if contractData.storageProofFound == 0 {
-				// TODO: penalize host / send signal back to watchee
-				w.contractor.log.Debugln("didn't find proof", fcID)
-			} else {
-				// TODO: ++ host / send signal back to watchee
-				w.contractor.log.Debugln("did find proof", fcID)
+			outcome := contractData.proofOutcome(w.blockHeight)
+			w.contractor.log.Debugln("storage proof window ended", fcID, outcome)
+			if w.recordProofOutcome(fcID, contractData.hostPublicKey, outcome) {
+				outcomes = append(outcomes, proofOutcome{
+					fcID:          fcID,
+					hostPublicKey: contractData.hostPublicKey,
+					outcome:       outcome,
+				})
 			}
 			w.archiveContract(fcID, 0)
 		}
@@ -841,15 +996,38 @@ func (w *watchdog) managedContractStatus(fcID types.FileContractID) (modules.Con
 		return modules.ContractWatchStatus{}, false
 	}
 
-	return modules.ContractWatchStatus{
-		Archived:                  false,
-		FormationSweepHeight:      contractData.formationSweepHeight,
-		ContractFound:             contractData.contractFound,
-		LatestRevisionFound:       contractData.revisionFound,
-		StorageProofFoundAtHeight: contractData.storageProofFound,
-		WindowStart:               contractData.windowStart,
-		WindowEnd:                 contractData.windowEnd,
-	}, true
+	return contractData.status(fcID, w.blockHeight), true
+}
+
+// managedContractStatuses returns the status of all the contracts in the
+// watchdog, sorted by the end of their proof window, and the storage proof
+// statistics of their hosts.
+func (w *watchdog) managedContractStatuses() ([]modules.ContractWatchStatus, []modules.HostStorageProofStats) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	statuses := make([]modules.ContractWatchStatus, 0, len(w.contracts)+len(w.archivedContracts))
+	for fcID, contractData := range w.contracts {
+		statuses = append(statuses, contractData.status(fcID, w.blockHeight))
+	}
+	for _, status := range w.archivedContracts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].WindowEnd != statuses[j].WindowEnd {
+			return statuses[i].WindowEnd < statuses[j].WindowEnd
+		}
+		return bytes.Compare(statuses[i].ContractID[:], statuses[j].ContractID[:]) < 0
+	})
+
+	hosts := make([]modules.HostStorageProofStats, 0, len(w.hostProofStats))
+	for _, stats := range w.hostProofStats {
+		hosts = append(hosts, stats)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].HostPublicKey.String() < hosts[j].HostPublicKey.String()
+	})
+	return statuses, hosts
 }
 
 // threadedSendMostRecentRevision sends the most recent revision transaction out.
@@ -11,14 +11,17 @@ import (
 type watchdogPersist struct {
 	Contracts         map[string]fileContractStatusPersist   `json:"contracts"`
 	ArchivedContracts map[string]modules.ContractWatchStatus `json:"archivedcontracts"`
+	HostProofStats    []modules.HostStorageProofStats        `json:"hostproofstats,omitempty"`
 }
 
 // fileContractStatusPersist defines what information from fileContractStatus is persisted.
 type fileContractStatusPersist struct {
-	FormationSweepHeight types.BlockHeight `json:"formationsweepheight,omitempty"`
-	ContractFound        bool              `json:"contractfound,omitempty"`
-	RevisionFound        uint64            `json:"revisionfound,omitempty"`
-	StorageProofFound    types.BlockHeight `json:"storageprooffound,omitempty"`
+	FormationSweepHeight types.BlockHeight  `json:"formationsweepheight,omitempty"`
+	ContractFound        bool               `json:"contractfound,omitempty"`
+	RevisionFound        uint64             `json:"revisionfound,omitempty"`
+	StorageProofFound    types.BlockHeight  `json:"storageprooffound,omitempty"`
+	HostPublicKey        types.SiaPublicKey `json:"hostpublickey,omitempty"`
+	ProofNotRequired     bool               `json:"proofnotrequired,omitempty"`
 
 	FormationTxnSet []types.Transaction     `json:"formationtxnset,omitempty"`
 	ParentOutputs   []types.SiacoinOutputID `json:"parentoutputs,omitempty"`
@@ -43,6 +46,8 @@ func (d *fileContractStatus) persistData() fileContractStatusPersist {
 		ContractFound:        d.contractFound,
 		RevisionFound:        d.revisionFound,
 		StorageProofFound:    d.storageProofFound,
+		HostPublicKey:        d.hostPublicKey,
+		ProofNotRequired:     d.proofNotRequired,
 		FormationTxnSet:      d.formationTxnSet,
 		ParentOutputs:        persistedParentOutputs,
 		SweepTxn:             d.sweepTxn,
@@ -67,6 +72,9 @@ func (w *watchdog) callPersistData() watchdogPersist {
 	for fcID, archivedData := range w.archivedContracts {
 		data.ArchivedContracts[fcID.String()] = archivedData
 	}
+	for _, stats := range w.hostProofStats {
+		data.HostProofStats = append(data.HostProofStats, stats)
+	}
 
 	return data
 }
@@ -88,6 +96,8 @@ func newWatchdogFromPersist(contractor *Contractor, persistData watchdogPersist)
 			contractFound:        data.ContractFound,
 			revisionFound:        data.RevisionFound,
 			storageProofFound:    data.StorageProofFound,
+			hostPublicKey:        data.HostPublicKey,
+			proofNotRequired:     data.ProofNotRequired,
 
 			formationTxnSet: data.FormationTxnSet,
 			parentOutputs:   make(map[types.SiacoinOutputID]struct{}),
@@ -118,8 +128,31 @@ func newWatchdogFromPersist(contractor *Contractor, persistData watchdogPersist)
 		}
 
 		// Add persisted contract data to the watchdog.
+		// Contracts that were archived before the proof outcome was tracked
+		// don't have the id and outcome set.
+		data.ContractID = fcID
+		if data.ProofOutcome == "" {
+			data.ProofOutcome = archivedProofOutcome(data)
+		}
 		w.archivedContracts[fcID] = data
 	}
 
+	for _, stats := range persistData.HostProofStats {
+		w.hostProofStats[stats.HostPublicKey.String()] = stats
+	}
+
 	return w, nil
 }
+
+// archivedProofOutcome derives the proof outcome of an archived contract from
+// its status. Contracts are either archived because they were double-spent or
+// because their proof window ended.
+func archivedProofOutcome(status modules.ContractWatchStatus) modules.ContractProofOutcome {
+	if status.StorageProofFoundAtHeight != 0 {
+		return modules.ProofOutcomeValid
+	}
+	if status.DoubleSpendHeight != 0 {
+		return modules.ProofOutcomeNone
+	}
+	return modules.ProofOutcomeMissed
+}
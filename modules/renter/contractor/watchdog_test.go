@@ -1,6 +1,7 @@
 package contractor
 
 import (
+	"io/ioutil"
 	"math"
 	"sync"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/siatest/dependencies"
 	"go.sia.tech/siad/types"
 )
@@ -568,6 +570,210 @@ func TestWatchdogStorageProofCheck(t *testing.T) {
 	}
 }
 
+// interactionsHostDB is a hostDB that counts the interactions reported by the
+// watchdog.
+type interactionsHostDB struct {
+	modules.HostDB
+	successful map[string]int
+	failed     map[string]int
+}
+
+// IncrementSuccessfulInteractions implements the modules.HostDB interface.
+func (hdb *interactionsHostDB) IncrementSuccessfulInteractions(hpk types.SiaPublicKey) error {
+	hdb.successful[hpk.String()]++
+	return nil
+}
+
+// IncrementFailedInteractions implements the modules.HostDB interface.
+func (hdb *interactionsHostDB) IncrementFailedInteractions(hpk types.SiaPublicKey) error {
+	hdb.failed[hpk.String()]++
+	return nil
+}
+
+// TestWatchdogProofOutcome tests that the watchdog records the outcome of a
+// contract's storage proof window and reports it to the hostdb.
+func TestWatchdogProofOutcome(t *testing.T) {
+	t.Parallel()
+
+	logger, err := persist.NewLogger(ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdb := &interactionsHostDB{
+		successful: make(map[string]int),
+		failed:     make(map[string]int),
+	}
+	c := &Contractor{
+		hdb: hdb,
+		log: logger,
+	}
+	w := newWatchdog(c)
+
+	// Monitor two recovered contracts with the same host.
+	hpk := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	monitor := func(fcID types.FileContractID) {
+		err := w.callMonitorContract(monitorContractArgs{
+			recovered: true,
+			fcID:      fcID,
+			revisionTxn: types.Transaction{
+				FileContractRevisions: []types.FileContractRevision{{
+					ParentID: fcID,
+					UnlockConditions: types.UnlockConditions{
+						PublicKeys: []types.SiaPublicKey{{}, hpk},
+					},
+					NewFileSize:           modules.SectorSize,
+					NewWindowStart:        10,
+					NewWindowEnd:          20,
+					NewValidProofOutputs:  []types.SiacoinOutput{{Value: types.SiacoinPrecision}},
+					NewMissedProofOutputs: []types.SiacoinOutput{{Value: types.ZeroCurrency}},
+				}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	valid, missed := types.FileContractID{1}, types.FileContractID{2}
+	monitor(valid)
+	monitor(missed)
+
+	// Both contracts are pending.
+	statuses, hosts := w.managedContractStatuses()
+	if len(statuses) != 2 || len(hosts) != 0 {
+		t.Fatal("wrong number of statuses", len(statuses), len(hosts))
+	}
+	for _, status := range statuses {
+		if status.ProofOutcome != modules.ProofOutcomePending || !status.HostPublicKey.Equals(hpk) {
+			t.Fatal("wrong status", status)
+		}
+	}
+
+	// Find the proof for one of the contracts and end the window.
+	w.blockHeight = 15
+	w.scanAppliedBlock(types.Block{
+		Transactions: []types.Transaction{{
+			StorageProofs: []types.StorageProof{{ParentID: valid}},
+		}},
+	})
+	w.blockHeight = 20
+	w.callCheckContracts()
+
+	status, ok := w.managedContractStatus(valid)
+	if !ok || !status.Archived || status.ProofOutcome != modules.ProofOutcomeValid || status.ContractID != valid {
+		t.Fatal("wrong status", status)
+	}
+	status, ok = w.managedContractStatus(missed)
+	if !ok || !status.Archived || status.ProofOutcome != modules.ProofOutcomeMissed || status.ContractID != missed {
+		t.Fatal("wrong status", status)
+	}
+	_, hosts = w.managedContractStatuses()
+	if len(hosts) != 1 || hosts[0].ValidProofs != 1 || hosts[0].MissedProofs != 1 || !hosts[0].HostPublicKey.Equals(hpk) {
+		t.Fatal("wrong host stats", hosts)
+	}
+	if hdb.successful[hpk.String()] != 1 || hdb.failed[hpk.String()] != 1 {
+		t.Fatal("wrong interactions", hdb.successful, hdb.failed)
+	}
+
+	// The outcomes should survive a restart.
+	w, err = newWatchdogFromPersist(c, w.callPersistData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses, hosts = w.managedContractStatuses()
+	if len(statuses) != 2 || statuses[0].ProofOutcome != modules.ProofOutcomeValid || statuses[1].ProofOutcome != modules.ProofOutcomeMissed {
+		t.Fatal("wrong statuses after reload", statuses)
+	}
+	if len(hosts) != 1 || hosts[0].ValidProofs != 1 || hosts[0].MissedProofs != 1 {
+		t.Fatal("wrong host stats after reload", hosts)
+	}
+}
+
+// TestWatchdogProofNotRequired tests that the watchdog doesn't penalize hosts
+// for not submitting storage proofs for contracts that don't require one.
+func TestWatchdogProofNotRequired(t *testing.T) {
+	t.Parallel()
+
+	logger, err := persist.NewLogger(ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdb := &interactionsHostDB{
+		successful: make(map[string]int),
+		failed:     make(map[string]int),
+	}
+	c := &Contractor{
+		hdb: hdb,
+		log: logger,
+	}
+	w := newWatchdog(c)
+
+	// Monitor an empty contract and a contract with data that is renewed
+	// later.
+	hpk := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	revision := func(fcID types.FileContractID, size uint64) types.FileContractRevision {
+		return types.FileContractRevision{
+			ParentID: fcID,
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys: []types.SiaPublicKey{{}, hpk},
+			},
+			NewRevisionNumber:     1,
+			NewFileSize:           size,
+			NewWindowStart:        10,
+			NewWindowEnd:          20,
+			NewValidProofOutputs:  []types.SiacoinOutput{{Value: types.SiacoinPrecision}},
+			NewMissedProofOutputs: []types.SiacoinOutput{{Value: types.ZeroCurrency}},
+		}
+	}
+	empty, renewed := types.FileContractID{1}, types.FileContractID{2}
+	for fcID, size := range map[types.FileContractID]uint64{empty: 0, renewed: modules.SectorSize} {
+		err := w.callMonitorContract(monitorContractArgs{
+			recovered: true,
+			fcID:      fcID,
+			revisionTxn: types.Transaction{
+				FileContractRevisions: []types.FileContractRevision{revision(fcID, size)},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The final revision of the renewal clears the contract and sets the
+	// missed outputs to equal the valid ones.
+	finalRev := revision(renewed, modules.SectorSize)
+	finalRev.NewRevisionNumber = math.MaxUint64
+	finalRev.NewMissedProofOutputs = finalRev.NewValidProofOutputs
+	w.blockHeight = 5
+	w.scanAppliedBlock(types.Block{
+		Transactions: []types.Transaction{{
+			FileContractRevisions: []types.FileContractRevision{finalRev},
+		}},
+	})
+
+	// End the window without any proofs. The contractor has no contract set,
+	// so it's stopped to prevent the watchdog from checking the revisions in
+	// the background.
+	if err := c.tg.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	w.blockHeight = 20
+	w.callCheckContracts()
+
+	for _, fcID := range []types.FileContractID{empty, renewed} {
+		status, ok := w.managedContractStatus(fcID)
+		if !ok || !status.Archived || status.ProofOutcome != modules.ProofOutcomeNotRequired {
+			t.Fatal("wrong status", status)
+		}
+	}
+	_, hosts := w.managedContractStatuses()
+	if len(hosts) != 0 {
+		t.Fatal("host shouldn't have any proof stats", hosts)
+	}
+	if len(hdb.successful) != 0 || len(hdb.failed) != 0 {
+		t.Fatal("hostdb shouldn't be updated", hdb.successful, hdb.failed)
+	}
+}
+
 // Test getParentOutputIDs
 func TestWatchdogGetParents(t *testing.T) {
 	// Create a txn set that is a long chain of transactions.
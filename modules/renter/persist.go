@@ -56,6 +56,8 @@ var (
 type (
 	// persist contains all of the persistent renter data.
 	persistence struct {
+		BackupSchedule   modules.BackupSchedule
+		DeletedBackups   [][16]byte
 		MaxDownloadSpeed int64
 		MaxUploadSpeed   int64
 		UploadedBackups  []modules.UploadedBackup
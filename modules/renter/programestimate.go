@@ -0,0 +1,111 @@
+package renter
+
+import (
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// newEstimateProgram creates the sample program that is priced by
+// WorkerProgramEstimates. It resembles the programs used for downloading a
+// sector. The sector doesn't need to exist since the program is never
+// executed.
+func newEstimateProgram(pt *modules.RPCPriceTable) (modules.Program, modules.ProgramData, types.Currency, types.Currency) {
+	var root crypto.Hash
+	fastrand.Read(root[:])
+	pb := modules.NewProgramBuilder(pt, 0) // 0 duration since the program is read-only.
+	pb.AddHasSectorInstruction(root)
+	pb.AddReadSectorInstruction(modules.SectorSize, 0, root, true)
+	program, data := pb.Program()
+	cost, _, collateral := pb.Cost(true)
+	return program, data, cost, collateral
+}
+
+// WorkerProgramEstimates has every worker's host dry run a sample program and
+// compares the host's cost breakdown to the renter's local estimate. This is
+// a debugging tool for detecting a drift between the renter's and host's
+// pricing logic.
+func (r *Renter) WorkerProgramEstimates() ([]modules.WorkerProgramEstimate, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+
+	// Filter out workers that don't support dry runs or don't have a valid
+	// price table.
+	var workers []*worker
+	for _, w := range r.staticWorkerPool.callWorkers() {
+		cache := w.staticCache()
+		if build.VersionCmp(cache.staticHostVersion, minDryRunProgramVersion) < 0 {
+			continue
+		}
+		if !w.staticPriceTable().staticValid() {
+			continue
+		}
+		workers = append(workers, w)
+	}
+
+	// Dry run the program on all workers in parallel.
+	estimates := make([]modules.WorkerProgramEstimate, len(workers))
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			estimates[i] = workers[i].managedProgramEstimate()
+		}(i)
+	}
+	wg.Wait()
+	return estimates, nil
+}
+
+// managedProgramEstimate dry runs the sample program on the worker's host and
+// compares the result with the local estimate.
+func (w *worker) managedProgramEstimate() modules.WorkerProgramEstimate {
+	pt := w.staticPriceTable().staticPriceTable
+	program, data, cost, collateral := newEstimateProgram(&pt)
+	estimate := modules.WorkerProgramEstimate{
+		HostPubKey:      w.staticHostPubKey,
+		LocalCost:       cost,
+		LocalCollateral: collateral,
+	}
+
+	// check for price gouging
+	err := checkProjectDownloadGouging(pt, w.staticCache().staticRenterAllowance)
+	if err != nil {
+		estimate.Error = errors.AddContext(err, "price gouging detected").Error()
+		return estimate
+	}
+
+	// The payment covers initializing the MDM and the bandwidth of the RPC.
+	ul := ethernetMTU + uint64(len(data))
+	dl := ethernetMTU * uint64(len(program)+1)
+	payment := modules.MDMInitCost(&pt, uint64(len(data)), uint64(len(program)))
+	payment = payment.Add(modules.MDMBandwidthCost(pt, ul, dl))
+
+	// Dry run the program with the budget we would execute it with.
+	resp, err := w.managedDryRunProgram(program, data, types.FileContractID{}, cost, payment)
+	if err != nil {
+		estimate.Error = err.Error()
+		return estimate
+	}
+	estimate.HostCost = resp.TotalCost()
+	estimate.HostCollateral = resp.TotalCollateral()
+	for _, instruction := range resp.Instructions {
+		estimate.Instructions = append(estimate.Instructions, modules.WorkerInstructionEstimate{
+			Specifier:     types.Specifier(instruction.Specifier).String(),
+			Cost:          instruction.Cost,
+			FailureRefund: instruction.FailureRefund,
+			Collateral:    instruction.Collateral,
+			Memory:        instruction.Memory,
+			Time:          instruction.Time,
+		})
+	}
+	estimate.Error = resp.Error
+	return estimate
+}
@@ -0,0 +1,44 @@
+package renter
+
+import (
+	"testing"
+)
+
+// TestProgramEstimate tests that the local estimate of the sample program
+// matches the host's dry run.
+func TestProgramEstimate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	estimate := wt.managedProgramEstimate()
+	if estimate.Error != "" {
+		t.Fatal(estimate.Error)
+	}
+	if !estimate.HostPubKey.Equals(wt.staticHostPubKey) {
+		t.Fatal("wrong host", estimate.HostPubKey)
+	}
+	if estimate.LocalCost.IsZero() || !estimate.LocalCost.Equals(estimate.HostCost) {
+		t.Fatalf("cost mismatch %v != %v", estimate.LocalCost, estimate.HostCost)
+	}
+	if !estimate.LocalCollateral.Equals(estimate.HostCollateral) {
+		t.Fatalf("collateral mismatch %v != %v", estimate.LocalCollateral, estimate.HostCollateral)
+	}
+	if len(estimate.Instructions) != 2 {
+		t.Fatal("wrong number of instructions", len(estimate.Instructions))
+	}
+	if estimate.Instructions[0].Specifier != "HasSector" || estimate.Instructions[1].Specifier != "ReadSector" {
+		t.Fatal("wrong specifiers", estimate.Instructions)
+	}
+}
@@ -581,6 +581,11 @@ func (pcws *projectChunkWorkerSet) managedDownload(ctx context.Context, pricePer
 	// extra goroutines to be spawned.
 	workerResponseChan := make(chan *jobReadResponse, ec.NumPieces()*5)
 
+	// Create the workerPartialResponseChan. Workers drop partial responses
+	// instead of blocking when it is full, so it uses the same size as the
+	// workerResponseChan.
+	workerPartialResponseChan := make(chan *jobReadPartialResponse, ec.NumPieces()*5)
+
 	// Build the full pdc.
 	pdc := &projectDownloadChunk{
 		offsetInChunk: offset,
@@ -594,11 +599,12 @@ func (pcws *projectChunkWorkerSet) managedDownload(ctx context.Context, pricePer
 		availablePieces: make([][]*pieceDownload, ec.NumPieces()),
 		dataPieces:      make([][]byte, ec.NumPieces()),
 
-		ctx:                  ctx,
-		workerResponseChan:   workerResponseChan,
-		downloadResponseChan: make(chan *downloadResponse, 1),
-		workerSet:            pcws,
-		workerState:          ws,
+		ctx:                       ctx,
+		workerResponseChan:        workerResponseChan,
+		workerPartialResponseChan: workerPartialResponseChan,
+		downloadResponseChan:      make(chan *downloadResponse, 1),
+		workerSet:                 pcws,
+		workerState:               ws,
 	}
 
 	// Set debug variables on the pdc
@@ -96,12 +96,14 @@ type (
 		dataPieces [][]byte
 
 		// The completed data gets sent down the response chan once the full
-		// download is done.
-		ctx                  context.Context
-		downloadResponseChan chan *downloadResponse
-		workerResponseChan   chan *jobReadResponse
-		workerSet            *projectChunkWorkerSet
-		workerState          *pcwsWorkerState
+		// download is done. The partial response chan receives the data of
+		// pieces that is still being streamed by the workers.
+		ctx                       context.Context
+		downloadResponseChan      chan *downloadResponse
+		workerResponseChan        chan *jobReadResponse
+		workerPartialResponseChan chan *jobReadPartialResponse
+		workerSet                 *projectChunkWorkerSet
+		workerState               *pcwsWorkerState
 
 		// Debug helpers
 		uid             [8]byte
@@ -123,9 +125,14 @@ type (
 		overdriveWorker bool
 
 		launchTime           time.Time
+		firstByteTime        time.Time
 		completeTime         time.Time
 		expectedCompleteTime time.Time
 
+		// decryptedData contains the segments of the piece that were already
+		// decrypted while the worker was still streaming the piece.
+		decryptedData []byte
+
 		// 'jobDuration' is the total amount of time it took to complete the job
 		jobDuration time.Duration
 
@@ -190,6 +197,11 @@ func (lwi *launchedWorkerInfo) String() string {
 	totalDur := lwi.totalDuration.Milliseconds()
 	jobDur := lwi.jobDuration.Milliseconds()
 
+	// if the worker streamed the piece
+	if !lwi.firstByteTime.IsZero() {
+		firstByteDur := lwi.firstByteTime.Sub(lwi.launchTime).Milliseconds()
+		return fmt.Sprintf("%v | %v | piece %v | estimated complete %v ms | first byte after %vms | responded after %vms | read job took %vms | %v", pdcId, wDescr, lwi.pieceIndex, estimate, firstByteDur, totalDur, jobDur, jDescr)
+	}
 	return fmt.Sprintf("%v | %v | piece %v | estimated complete %v ms | responded after %vms | read job took %vms | %v", pdcId, wDescr, lwi.pieceIndex, estimate, totalDur, jobDur, jDescr)
 }
 
@@ -274,13 +286,20 @@ func (pdc *projectDownloadChunk) handleJobReadResponse(jrr *jobReadResponse) {
 		return
 	}
 
-	// Decrypt the piece that has come back.
+	// Decrypt the piece that has come back. The segments that were decrypted
+	// while the piece was streamed only need to be copied over.
 	key := pdc.workerSet.staticMasterKey.Derive(pdc.workerSet.staticChunkIndex, uint64(pieceIndex))
-	_, err := key.DecryptBytesInPlace(jrr.staticData, pdc.pieceOffset/crypto.SegmentSize)
+	decrypted := uint64(len(launchedWorker.decryptedData))
+	if decrypted > uint64(len(jrr.staticData)) {
+		decrypted = 0 // sanity check
+	}
+	_, err := key.DecryptBytesInPlace(jrr.staticData[decrypted:], (pdc.pieceOffset+decrypted)/crypto.SegmentSize)
 	if err != nil {
 		pdc.workerSet.staticRenter.log.Println("decryption of a piece failed")
 		return
 	}
+	copy(jrr.staticData, launchedWorker.decryptedData[:decrypted])
+	launchedWorker.decryptedData = nil
 
 	// The download succeeded, add the piece to the appropriate index.
 	pdc.dataPieces[pieceIndex] = jrr.staticData
@@ -298,6 +317,48 @@ func (pdc *projectDownloadChunk) handleJobReadResponse(jrr *jobReadResponse) {
 	}
 }
 
+// handleJobReadPartialResponse will take a jobReadPartialResponse from a worker
+// job and decrypt the segments of the piece that were received since the last
+// partial response. That way only the remainder of the piece needs to be
+// decrypted once the worker's job completes.
+func (pdc *projectDownloadChunk) handleJobReadPartialResponse(jrpr *jobReadPartialResponse) {
+	// Prevent a production panic.
+	if jrpr == nil {
+		pdc.workerSet.staticRenter.log.Critical("received nil job read partial response in handleJobReadPartialResponse")
+		return
+	}
+	launchedWorker := pdc.launchedWorkers[jrpr.staticMetadata.staticLaunchedWorkerIndex]
+
+	// Ignore partial responses that arrive after the job completed.
+	if !launchedWorker.completeTime.IsZero() {
+		return
+	}
+	if launchedWorker.firstByteTime.IsZero() {
+		launchedWorker.firstByteTime = time.Now()
+	}
+
+	// Twofish doesn't support decrypting a piece starting at an offset, so
+	// the piece can only be decrypted once it is complete.
+	key := pdc.workerSet.staticMasterKey.Derive(pdc.workerSet.staticChunkIndex, jrpr.staticMetadata.staticPieceRootIndex)
+	if key.Type() == crypto.TypeTwofish {
+		return
+	}
+
+	// Decrypt the new segments.
+	decrypted := uint64(len(launchedWorker.decryptedData))
+	received := uint64(len(jrpr.staticData)) / crypto.SegmentSize * crypto.SegmentSize
+	if received <= decrypted {
+		return
+	}
+	segments := append([]byte(nil), jrpr.staticData[decrypted:received]...)
+	_, err := key.DecryptBytesInPlace(segments, (pdc.pieceOffset+decrypted)/crypto.SegmentSize)
+	if err != nil {
+		pdc.workerSet.staticRenter.log.Println("decryption of a partial piece failed")
+		return
+	}
+	launchedWorker.decryptedData = append(launchedWorker.decryptedData, segments...)
+}
+
 // fail will send an error down the download response channel.
 func (pdc *projectDownloadChunk) fail(err error) {
 	dr := &downloadResponse{
@@ -408,8 +469,9 @@ func (pdc *projectDownloadChunk) launchWorker(w *worker, pieceIndex uint64, isOv
 	sectorRoot := pdc.workerSet.staticPieceRoots[pieceIndex]
 	jrs := &jobReadSector{
 		jobRead: jobRead{
-			staticResponseChan: pdc.workerResponseChan,
-			staticLength:       pdc.pieceLength,
+			staticResponseChan:        pdc.workerResponseChan,
+			staticPartialResponseChan: pdc.workerPartialResponseChan,
+			staticLength:              pdc.pieceLength,
 
 			jobGeneric: newJobGeneric(pdc.ctx, w.staticJobReadQueue, jobReadMetadata{
 				staticWorker:              w,
@@ -496,6 +558,8 @@ func (pdc *projectDownloadChunk) threadedCollectAndOverdrivePieces() {
 			return
 		case jrr := <-pdc.workerResponseChan:
 			pdc.handleJobReadResponse(jrr)
+		case jrpr := <-pdc.workerPartialResponseChan:
+			pdc.handleJobReadPartialResponse(jrpr)
 		case <-workersLateChan:
 		case <-workersUpdatedChan:
 		}
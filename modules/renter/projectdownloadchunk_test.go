@@ -357,6 +357,111 @@ func TestProjectDownloadChunk_handleJobResponse(t *testing.T) {
 	pdc.handleJobReadResponse(success)
 }
 
+// TestProjectDownloadChunk_handleJobPartialResponse is a unit test that
+// verifies that partial responses are decrypted as they arrive and that the
+// final response of the worker returns the correctly decrypted piece.
+func TestProjectDownloadChunk_handleJobPartialResponse(t *testing.T) {
+	t.Parallel()
+
+	ec := modules.NewRSSubCodeDefault()
+	key := crypto.GenerateSiaKey(crypto.TypeXChaCha20)
+
+	w := new(worker)
+	w.staticHostPubKeyStr = "w"
+
+	renter := new(Renter)
+	logger, err := persist.NewLogger(ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renter.log = logger
+
+	pcws := new(projectChunkWorkerSet)
+	pcws.staticMasterKey = key
+	pcws.staticErasureCoder = ec
+	pcws.staticPieceRoots = make([]crypto.Hash, ec.NumPieces())
+	pcws.staticRenter = renter
+
+	// encrypt a piece and download it starting at an offset.
+	pieceIndex := uint64(1)
+	piece := fastrand.Bytes(int(modules.SectorSize))
+	encrypted := key.Derive(0, pieceIndex).EncryptBytes(append([]byte(nil), piece...))
+	pieceOffset := uint64(crypto.SegmentSize * 4)
+	downloaded := append([]byte(nil), encrypted[pieceOffset:]...)
+
+	pdc := new(projectDownloadChunk)
+	pdc.workerSet = pcws
+	pdc.pieceOffset = pieceOffset
+	pdc.dataPieces = make([][]byte, ec.NumPieces())
+	pdc.availablePieces = make([][]*pieceDownload, ec.NumPieces())
+	pdc.availablePieces[pieceIndex] = []*pieceDownload{{launched: true, worker: w}}
+	lwi := &launchedWorkerInfo{
+		launchTime: time.Now().Add(-time.Minute),
+		pdc:        pdc,
+		worker:     w,
+	}
+	pdc.launchedWorkers = []*launchedWorkerInfo{lwi}
+	metadata := jobReadMetadata{
+		staticLaunchedWorkerIndex: 0,
+		staticPieceRootIndex:      pieceIndex,
+		staticWorker:              w,
+	}
+
+	// send a partial response which isn't segment aligned, only the full
+	// segments should be decrypted.
+	pdc.handleJobReadPartialResponse(&jobReadPartialResponse{
+		staticData:     downloaded[:3*crypto.SegmentSize+1],
+		staticMetadata: metadata,
+	})
+	if lwi.firstByteTime.IsZero() {
+		t.Fatal("first byte time wasn't set")
+	}
+	firstByteTime := lwi.firstByteTime
+	if !bytes.Equal(lwi.decryptedData, piece[pieceOffset:pieceOffset+3*crypto.SegmentSize]) {
+		t.Fatal("partial data wasn't decrypted correctly")
+	}
+
+	// send another partial response.
+	pdc.handleJobReadPartialResponse(&jobReadPartialResponse{
+		staticData:     downloaded[:len(downloaded)/2],
+		staticMetadata: metadata,
+	})
+	if lwi.firstByteTime != firstByteTime {
+		t.Fatal("first byte time shouldn't change")
+	}
+	if !bytes.Equal(lwi.decryptedData, piece[pieceOffset:pieceOffset+uint64(len(downloaded)/2)]) {
+		t.Fatal("partial data wasn't decrypted correctly")
+	}
+
+	// send the final response.
+	pdc.handleJobReadResponse(&jobReadResponse{
+		staticData:     downloaded,
+		staticJobTime:  time.Second,
+		staticMetadata: metadata,
+	})
+	if !pdc.availablePieces[pieceIndex][0].successful() {
+		t.Fatal("piece should be downloaded")
+	}
+	if !bytes.Equal(pdc.dataPieces[pieceIndex], piece[pieceOffset:]) {
+		t.Fatal("piece wasn't decrypted correctly")
+	}
+	if lwi.decryptedData != nil {
+		t.Fatal("decrypted data should be released")
+	}
+	if !strings.Contains(lwi.String(), "first byte after") {
+		t.Fatal("launched worker info should contain the first byte time", lwi.String())
+	}
+
+	// partial responses that arrive after the final one are ignored.
+	pdc.handleJobReadPartialResponse(&jobReadPartialResponse{
+		staticData:     downloaded,
+		staticMetadata: metadata,
+	})
+	if lwi.decryptedData != nil {
+		t.Fatal("late partial response shouldn't be decrypted")
+	}
+}
+
 // TestProjectDownloadChunk_launchWorker is a unit test for the 'launchWorker'
 // function on the pdc.
 func TestProjectDownloadChunk_launchWorker(t *testing.T) {
@@ -168,9 +168,10 @@ type SafeContract struct {
 	// applied to the contract file.
 	unappliedTxns []*unappliedWalTxn
 
-	staticHeaderFile *os.File
-	staticWal        *writeaheadlog.WAL
-	mu               sync.Mutex
+	staticHeaderFile       *os.File
+	staticSpendingRecorder *spendingRecorder
+	staticWal              *writeaheadlog.WAL
+	mu                     sync.Mutex
 
 	staticRC *refCounter
 
@@ -202,6 +203,7 @@ func (c *SafeContract) CommitPaymentIntent(t *unappliedWalTxn, signedTxn types.T
 	if err := t.SignalUpdatesApplied(); err != nil {
 		return err
 	}
+	c.staticSpendingRecorder.record(newHeader.ID(), newHeader.HostPublicKey(), details)
 	return c.clearUnappliedTxns()
 }
 
@@ -228,6 +230,24 @@ func (c *SafeContract) clearUnappliedTxns() error {
 	return nil
 }
 
+// HasSectorRoot returns whether the sector roots the renter recorded for the
+// contract contain the given root. Sectors which were written with a session
+// don't record their roots and therefore aren't found.
+func (c *SafeContract) HasSectorRoot(root crypto.Hash) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	roots, err := c.merkleRoots.merkleRoots()
+	if err != nil {
+		return false, errors.AddContext(err, "failed to read the contract's roots")
+	}
+	for _, r := range roots {
+		if r == root {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // LastRevision returns the most recent revision
 func (c *SafeContract) LastRevision() types.FileContractRevision {
 	c.mu.Lock()
@@ -541,6 +561,10 @@ func (c *SafeContract) managedCommitAppend(t *unappliedWalTxn, signedTxn types.T
 	if err = t.SignalUpdatesApplied(); err != nil {
 		return err
 	}
+	c.staticSpendingRecorder.record(newHeader.ID(), newHeader.HostPublicKey(), modules.SpendingDetails{
+		StorageSpending: storageCost,
+		UploadSpending:  bandwidthCost,
+	})
 	if err := c.clearUnappliedTxns(); err != nil {
 		return errors.AddContext(err, "failed to clear unapplied txns")
 	}
@@ -591,6 +615,9 @@ func (c *SafeContract) managedCommitDownload(t *unappliedWalTxn, signedTxn types
 	if err := t.SignalUpdatesApplied(); err != nil {
 		return err
 	}
+	c.staticSpendingRecorder.record(newHeader.ID(), newHeader.HostPublicKey(), modules.SpendingDetails{
+		DownloadSpending: bandwidthCost,
+	})
 	if err := c.clearUnappliedTxns(); err != nil {
 		return errors.AddContext(err, "failed to clear unapplied txns")
 	}
@@ -644,6 +671,9 @@ func (c *SafeContract) managedCommitClearContract(t *unappliedWalTxn, signedTxn
 	if err := t.SignalUpdatesApplied(); err != nil {
 		return err
 	}
+	c.staticSpendingRecorder.record(newHeader.ID(), newHeader.HostPublicKey(), modules.SpendingDetails{
+		UploadSpending: bandwidthCost,
+	})
 	if err := c.clearUnappliedTxns(); err != nil {
 		return errors.AddContext(err, "failed to clear unapplied txns")
 	}
@@ -880,11 +910,12 @@ func (cs *ContractSet) managedApplyInsertContractUpdate(update writeaheadlog.Upd
 		}
 	}
 	sc := &SafeContract{
-		header:           h,
-		merkleRoots:      merkleRoots,
-		staticHeaderFile: headerFile,
-		staticWal:        cs.staticWal,
-		staticRC:         rc,
+		header:                 h,
+		merkleRoots:            merkleRoots,
+		staticHeaderFile:       headerFile,
+		staticSpendingRecorder: cs.staticSpendingRecorder,
+		staticWal:              cs.staticWal,
+		staticRC:               rc,
 	}
 	// Compatv144 fix missing void output.
 	cs.mu.Lock()
@@ -992,12 +1023,13 @@ func (cs *ContractSet) loadSafeContract(headerFileName, rootsFileName, refCountF
 	}
 	// add to set
 	sc := &SafeContract{
-		header:           header,
-		merkleRoots:      merkleRoots,
-		unappliedTxns:    unappliedTxns,
-		staticHeaderFile: headerFile,
-		staticWal:        cs.staticWal,
-		staticRC:         rc,
+		header:                 header,
+		merkleRoots:            merkleRoots,
+		unappliedTxns:          unappliedTxns,
+		staticHeaderFile:       headerFile,
+		staticSpendingRecorder: cs.staticSpendingRecorder,
+		staticWal:              cs.staticWal,
+		staticRC:               rc,
 	}
 
 	// apply the wal txns if necessary.
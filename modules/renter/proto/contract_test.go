@@ -703,6 +703,50 @@ func TestContractRefCounter(t *testing.T) {
 	}
 }
 
+// TestContractHasSectorRoot tests that HasSectorRoot finds the roots recorded
+// for a contract.
+func TestContractHasSectorRoot(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// create a contract set
+	dir := build.TempDir(filepath.Join("proto", t.Name()))
+	rl := ratelimit.NewRateLimit(0, 0, 0)
+	cs, err := NewContractSet(dir, rl, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// add a contract
+	header := contractHeader{
+		Transaction: types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				NewRevisionNumber:    1,
+				NewValidProofOutputs: []types.SiacoinOutput{{}, {}},
+				UnlockConditions: types.UnlockConditions{
+					PublicKeys: []types.SiaPublicKey{{}, {}},
+				},
+			}},
+		},
+	}
+	c, err := cs.managedInsertContract(header, []crypto.Hash{{1}, {2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := cs.managedMustAcquire(t, c.ID)
+	defer cs.Return(sc)
+
+	for _, root := range []crypto.Hash{{1}, {2}} {
+		if has, err := sc.HasSectorRoot(root); err != nil || !has {
+			t.Fatal("root should be found", root, err)
+		}
+	}
+	if has, err := sc.HasSectorRoot(crypto.Hash{3}); err != nil || has {
+		t.Fatal("unknown root shouldn't be found", err)
+	}
+}
+
 // TestContractRecordCommitDownloadIntent tests recording and committing
 // downloads and makes sure they use the wal correctly.
 func TestContractRecordCommitDownloadIntent(t *testing.T) {
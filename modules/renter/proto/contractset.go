@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/ratelimit"
@@ -29,6 +30,32 @@ type ContractSet struct {
 	mu         sync.Mutex
 	staticRL   *ratelimit.RateLimit
 	staticWal  *writeaheadlog.WAL
+
+	staticSpendingRecorder *spendingRecorder
+}
+
+// spendingRecorder forwards the spending that is committed to the contracts of
+// a set to an optional callback. It is shared between the set and all of its
+// contracts so that the callback can be set after the contracts were loaded.
+type spendingRecorder struct {
+	callback func([]modules.ContractSpendingEntry)
+	mu       sync.Mutex
+}
+
+// record passes the ledger entries for the given spending details to the
+// callback, if one was set.
+func (sr *spendingRecorder) record(fcid types.FileContractID, hpk types.SiaPublicKey, details modules.SpendingDetails) {
+	sr.mu.Lock()
+	callback := sr.callback
+	sr.mu.Unlock()
+	if callback == nil {
+		return
+	}
+	entries := details.LedgerEntries(fcid, hpk, time.Now().Unix())
+	if len(entries) == 0 {
+		return
+	}
+	callback(entries)
 }
 
 // Acquire looks up the contract for the specified host key and locks it before
@@ -133,6 +160,14 @@ func (cs *ContractSet) Return(c *SafeContract) {
 	c.revisionMu.Unlock()
 }
 
+// SetSpendingCallback sets the callback that is called with the ledger entries
+// of every spend that is committed to a contract of the set.
+func (cs *ContractSet) SetSpendingCallback(callback func([]modules.ContractSpendingEntry)) {
+	cs.staticSpendingRecorder.mu.Lock()
+	defer cs.staticSpendingRecorder.mu.Unlock()
+	cs.staticSpendingRecorder.callback = callback
+}
+
 // View returns a copy of the contract with the specified host key. The contract
 // is not locked. Certain fields, including the MerkleRoots, are set to nil for
 // safety reasons. If the contract is not present in the set, View returns false
@@ -223,6 +258,8 @@ func NewContractSet(dir string, rl *ratelimit.RateLimit, deps modules.Dependenci
 		staticDir:  dir,
 		staticRL:   rl,
 		staticWal:  wal,
+
+		staticSpendingRecorder: &spendingRecorder{},
 	}
 	// Set the initial rate limit to 'unlimited' bandwidth with 4kib packets.
 	cs.staticRL = ratelimit.NewRateLimit(0, 0, 0)
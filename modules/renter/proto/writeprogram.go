@@ -0,0 +1,100 @@
+package proto
+
+import (
+	"io"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errWrongNewSize is returned if the host's new contract size doesn't
+	// match the one the renter expects after appending a sector.
+	errWrongNewSize = errors.New("host returned the wrong new contract size")
+)
+
+// FinalizeAppendProgram finalizes a write program that appended the sector with
+// the given root to the contract with the given id. The program's last output
+// has to contain a Merkle proof for the append which is verified before the
+// new revision is recorded in the WAL and signed by both parties. The program was
+// paid for from an ephemeral account, so no spending is added to the contract.
+func (cs *ContractSet) FinalizeAppendProgram(stream io.ReadWriter, id types.FileContractID, root crypto.Hash, lastOutput modules.RPCExecuteProgramResponse, bh types.BlockHeight) error {
+	// Acquire the contract.
+	sc, haveContract := cs.Acquire(id)
+	if !haveContract {
+		return errors.New("contract not present in contract set")
+	}
+	defer cs.Return(sc)
+
+	// Verify the new size and the proof for the new Merkle root.
+	currentRevision := sc.LastRevision()
+	numSectors := currentRevision.NewFileSize / modules.SectorSize
+	if lastOutput.NewSize != currentRevision.NewFileSize+modules.SectorSize {
+		return errWrongNewSize
+	}
+	if !crypto.VerifyDiffProof(nil, numSectors, lastOutput.Proof, nil, currentRevision.NewFileMerkleRoot) {
+		return errors.New("invalid Merkle proof for old root")
+	}
+	newRanges := []crypto.ProofRange{{Start: numSectors, End: numSectors + 1}}
+	if !crypto.VerifyDiffProof(newRanges, numSectors, lastOutput.Proof, []crypto.Hash{root}, lastOutput.NewMerkleRoot) {
+		return errors.New("invalid Merkle proof for new root")
+	}
+
+	// Construct and sign the new revision.
+	transfer := lastOutput.AdditionalCollateral.Add(lastOutput.FailureRefund)
+	rev, err := currentRevision.ExecuteProgramRevision(currentRevision.NewRevisionNumber+1, transfer, lastOutput.NewMerkleRoot, lastOutput.NewSize)
+	if err != nil {
+		return errors.AddContext(err, "failed to construct execute program revision")
+	}
+	txn := rev.ToTransaction()
+	renterSig := sc.Sign(txn.SigHash(0, bh))
+	txn.TransactionSignatures[0].Signature = renterSig[:]
+
+	// Record the change we are about to make to the contract.
+	walTxn, err := sc.managedRecordAppendIntent(rev, root, types.ZeroCurrency, types.ZeroCurrency)
+	if err != nil {
+		return errors.AddContext(err, "failed to record append intent")
+	}
+
+	// Send the renter's signature and the new payouts to the host.
+	validProofValues := make([]types.Currency, len(rev.NewValidProofOutputs))
+	for i, output := range rev.NewValidProofOutputs {
+		validProofValues[i] = output.Value
+	}
+	missedProofValues := make([]types.Currency, len(rev.NewMissedProofOutputs))
+	for i, output := range rev.NewMissedProofOutputs {
+		missedProofValues[i] = output.Value
+	}
+	err = modules.RPCWrite(stream, modules.RPCExecuteProgramRevisionSigningRequest{
+		Signature:            renterSig[:],
+		NewRevisionNumber:    rev.NewRevisionNumber,
+		NewValidProofValues:  validProofValues,
+		NewMissedProofValues: missedProofValues,
+	})
+	if err != nil {
+		return errors.AddContext(err, "failed to send revision signing request")
+	}
+
+	// Receive and verify the host's signature.
+	var resp modules.RPCExecuteProgramRevisionSigningResponse
+	err = modules.RPCRead(stream, &resp)
+	if err != nil {
+		return errors.AddContext(err, "failed to read revision signing response")
+	}
+	txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+		ParentID:       crypto.Hash(rev.ParentID),
+		CoveredFields:  types.CoveredFields{FileContractRevisions: []uint64{0}},
+		PublicKeyIndex: 1,
+		Signature:      resp.Signature,
+	})
+	err = modules.VerifyFileContractRevisionTransactionSignatures(rev, txn.TransactionSignatures, bh)
+	if err != nil {
+		return errors.AddContext(err, "failed to verify host signature")
+	}
+
+	// Update the contract.
+	return sc.managedCommitAppend(walTxn, txn, types.ZeroCurrency, types.ZeroCurrency)
+}
@@ -0,0 +1,385 @@
+package renter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/registry"
+)
+
+// ReadRegistryMulti starts a batched registry lookup of multiple entries on
+// all available workers. It works like ReadRegistry but every worker only
+// executes a single program for all of the entries.
+func (r *Renter) ReadRegistryMulti(reqs []modules.RegistryReadRequest, timeout time.Duration) ([]modules.RegistryReadResult, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("no registry entries to read")
+	}
+
+	// Create a context. If the timeout is greater than zero, have the context
+	// expire when the timeout triggers.
+	ctx := r.tg.StopCtx()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(r.tg.StopCtx(), timeout)
+		defer cancel()
+	}
+
+	// Block until there is memory available, and then ensure the memory gets
+	// returned.
+	memory := readRegistryMemory * uint64(len(reqs))
+	if !r.registryMemoryManager.Request(ctx, memory, memoryPriorityHigh) {
+		return nil, errors.New("timeout while waiting in job queue - server is busy")
+	}
+	defer r.registryMemoryManager.Return(memory)
+
+	// Start the ReadRegistryBatch jobs.
+	results, err := r.managedReadRegistryMulti(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if errors.Contains(results[i].Err, ErrRegistryLookupTimeout) {
+			results[i].Err = errors.AddContext(results[i].Err, fmt.Sprintf("timed out after %vs", timeout.Seconds()))
+		}
+	}
+	return results, nil
+}
+
+// UpdateRegistryMulti updates multiple registry entries on all workers. It
+// works like UpdateRegistry but every worker only executes a single program
+// for all of the entries.
+func (r *Renter) UpdateRegistryMulti(updates []modules.RegistryBatchUpdate, timeout time.Duration) ([]error, error) {
+	if len(updates) == 0 {
+		return nil, errors.New("no registry entries to update")
+	}
+
+	// Create a context. If the timeout is greater than zero, have the context
+	// expire when the timeout triggers.
+	ctx := r.tg.StopCtx()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(r.tg.StopCtx(), timeout)
+		defer cancel()
+	}
+
+	// Block until there is memory available, and then ensure the memory gets
+	// returned.
+	memory := updateRegistryMemory * uint64(len(updates))
+	if !r.registryMemoryManager.Request(ctx, memory, memoryPriorityHigh) {
+		return nil, errors.New("timeout while waiting in job queue - server is busy")
+	}
+	defer r.registryMemoryManager.Return(memory)
+
+	// Start the UpdateRegistryBatch jobs.
+	errs, err := r.managedUpdateRegistryMulti(ctx, updates)
+	if err != nil {
+		return nil, err
+	}
+	for i := range errs {
+		if errors.Contains(errs[i], ErrRegistryUpdateTimeout) {
+			errs[i] = errors.AddContext(errs[i], fmt.Sprintf("timed out after %vs", timeout.Seconds()))
+		}
+	}
+	return errs, nil
+}
+
+// managedReadRegistryMulti starts a batched registry lookup on all available
+// workers. For every entry, the response with the highest revision number is
+// used.
+func (r *Renter) managedReadRegistryMulti(ctx context.Context, reqs []modules.RegistryReadRequest) ([]modules.RegistryReadResult, error) {
+	// Specify a sane timeout for jobs that is independent of the user specified
+	// timeout. It is the maximum time that we let a job execute in the
+	// background before cancelling it.
+	backgroundCtx, backgroundCancel := context.WithTimeout(r.tg.StopCtx(), ReadRegistryBackgroundTimeout)
+	defer backgroundCancel()
+
+	// Get the full list of workers and create a channel to receive all of the
+	// results from the workers. The channel is buffered with one slot per
+	// worker, so that the workers do not have to block when returning the
+	// result of the job, even if this thread is not listening.
+	workers := r.staticWorkerPool.callWorkers()
+	staticResponseChan := make(chan *jobReadRegistryBatchResponse, len(workers))
+
+	// Filter out hosts that don't support the registry. Hosts that don't
+	// support batched registry lookups look up the entries one by one.
+	numWorkers := 0
+	for _, worker := range workers {
+		cache := worker.staticCache()
+		if build.VersionCmp(cache.staticHostVersion, minRegistryVersion) < 0 {
+			continue
+		}
+
+		// check for price gouging
+		//
+		// TODO: use 'checkProjectDownloadGouging' gouging for some basic
+		// protection. Should be replaced as part of the gouging overhaul.
+		pt := worker.staticPriceTable().staticPriceTable
+		err := checkProjectDownloadGouging(pt, cache.staticRenterAllowance)
+		if err != nil {
+			r.log.Debugf("price gouging detected in worker %v, err: %v\n", worker.staticHostPubKeyStr, err)
+			continue
+		}
+
+		if !worker.callAddReadRegistryBatch(backgroundCtx, staticResponseChan, reqs) {
+			// This will filter out any workers that are on cooldown or
+			// otherwise can't participate in the project.
+			continue
+		}
+		numWorkers++
+	}
+	// If there are no workers remaining, fail early.
+	if numWorkers == 0 {
+		return nil, errors.AddContext(modules.ErrNotEnoughWorkersInWorkerPool, "cannot perform ReadRegistryMulti")
+	}
+
+	// Further restrict the input timeout using historical data.
+	ctx, cancel := context.WithTimeout(ctx, r.staticRRS.Estimate())
+	defer cancel()
+
+	// Prepare a context which will be overwritten by a child context with a
+	// timeout when we receive the first response. Once we have found a value
+	// for every entry, this context is used to abort the search for higher
+	// revision numbers.
+	var useHighestRevCtx context.Context
+
+	srvs := make([]*modules.SignedRegistryValue, len(reqs))
+	numFound := 0
+	responses := 0
+	for responses < numWorkers {
+		// Check cancel condition and block for more responses.
+		waitCtx := ctx
+		if numFound == len(reqs) {
+			waitCtx = useHighestRevCtx
+		}
+		var resp *jobReadRegistryBatchResponse
+		select {
+		case <-waitCtx.Done():
+		case resp = <-staticResponseChan:
+		}
+		if resp == nil {
+			break // context triggered
+		}
+
+		// When we get the first response, we initialize the highest rev
+		// timeout.
+		if responses == 0 {
+			c, cancel := context.WithTimeout(ctx, useHighestRevDefaultTimeout)
+			defer cancel()
+			useHighestRevCtx = c
+		}
+
+		// Increment responses.
+		responses++
+
+		// Ignore error responses.
+		if resp.staticErr != nil {
+			continue
+		}
+
+		// Remember the values with the highest revision numbers.
+		for i, value := range resp.staticSignedRegistryValues {
+			if value == nil {
+				continue
+			}
+			srv := srvs[i]
+			revHigher := srv != nil && value.Revision > srv.Revision
+			revSame := srv != nil && value.Revision == srv.Revision
+			moreWork := srv != nil && value.HasMoreWork(srv.RegistryValue)
+			if srv == nil {
+				numFound++
+			}
+			if srv == nil || revHigher || (revSame && moreWork) {
+				srvs[i] = value
+			}
+		}
+	}
+
+	// Create the results. If we don't have a value for an entry and also not
+	// a response from every worker, we timed out. Otherwise we were unable to
+	// look up the entry.
+	results := make([]modules.RegistryReadResult, len(reqs))
+	for i, srv := range srvs {
+		if srv != nil {
+			results[i].Value = *srv
+		} else if responses < numWorkers {
+			results[i].Err = ErrRegistryLookupTimeout
+		} else {
+			results[i].Err = ErrRegistryEntryNotFound
+		}
+	}
+	return results, nil
+}
+
+// managedUpdateRegistryMulti updates multiple registry entries on all
+// workers. The returned errors are in the same order as the updates.
+// NOTE: the input ctx only unblocks the call if it fails to hit the threshold
+// before the timeout. It doesn't stop the update jobs. That's because we want
+// to always make sure we update as many hosts as possble.
+func (r *Renter) managedUpdateRegistryMulti(ctx context.Context, updates []modules.RegistryBatchUpdate) (_ []error, err error) {
+	// Verify the signatures before updating the hosts. Entries with an
+	// invalid signature are not sent to the hosts.
+	errs := make([]error, len(updates))
+	validUpdates := make([]modules.RegistryBatchUpdate, 0, len(updates))
+	validIndices := make([]int, 0, len(updates))
+	for i, update := range updates {
+		if err := update.Value.Verify(update.PubKey.ToPublicKey()); err != nil {
+			errs[i] = errors.AddContext(err, "managedUpdateRegistryMulti: failed to verify signature of entry")
+			continue
+		}
+		validUpdates = append(validUpdates, update)
+		validIndices = append(validIndices, i)
+	}
+	if len(validUpdates) == 0 {
+		return errs, nil
+	}
+
+	// Get the full list of workers and create a channel to receive all of the
+	// results from the workers. The channel is buffered with one slot per
+	// worker, so that the workers do not have to block when returning the
+	// result of the job, even if this thread is not listening.
+	workers := r.staticWorkerPool.callWorkers()
+	staticResponseChan := make(chan *jobUpdateRegistryBatchResponse, len(workers))
+
+	// Create a context to continue updating registry values in the background.
+	updateTimeoutCtx, updateTimeoutCancel := context.WithTimeout(r.tg.StopCtx(), updateRegistryBackgroundTimeout)
+	defer func() {
+		if err != nil {
+			// If managedUpdateRegistryMulti fails the caller is going to
+			// assume that updating the values failed. Don't let any jobs
+			// linger in that case.
+			updateTimeoutCancel()
+		}
+	}()
+
+	// Filter out hosts that don't support the registry. Hosts that don't
+	// support batched registry updates update the entries one by one.
+	numWorkers := 0
+	for _, worker := range workers {
+		cache := worker.staticCache()
+		if build.VersionCmp(cache.staticHostVersion, minRegistryVersion) < 0 {
+			continue
+		}
+
+		// Skip !goodForUpload workers.
+		if !cache.staticContractUtility.GoodForUpload {
+			continue
+		}
+
+		// check for price gouging
+		// TODO: use upload gouging for some basic protection. Should be
+		// replaced as part of the gouging overhaul.
+		host, ok, err := r.hostDB.Host(worker.staticHostPubKey)
+		if !ok || err != nil {
+			continue
+		}
+		err = checkUploadGouging(cache.staticRenterAllowance, host.HostExternalSettings)
+		if err != nil {
+			r.log.Debugf("price gouging detected in worker %v, err: %v\n", worker.staticHostPubKeyStr, err)
+			continue
+		}
+
+		// Create the job.
+		if !worker.callAddUpdateRegistryBatch(updateTimeoutCtx, staticResponseChan, validUpdates) {
+			// This will filter out any workers that are on cooldown or
+			// otherwise can't participate in the project.
+			continue
+		}
+		numWorkers++
+	}
+	// If there are not enough workers remaining, fail early.
+	if numWorkers < MinUpdateRegistrySuccesses {
+		return nil, errors.AddContext(modules.ErrNotEnoughWorkersInWorkerPool, "cannot perform UpdateRegistryMulti")
+	}
+
+	// Track the responses for every entry individually.
+	workersLeft := numWorkers
+	successfulResponses := make([]int, len(validUpdates))
+	highestInvalidRevNums := make([]uint64, len(validUpdates))
+	invalidRevNums := make([]bool, len(validUpdates))
+
+	// undecided returns whether an entry might still reach the required number
+	// of successful updates but hasn't done so yet.
+	undecided := func(i int) bool {
+		return successfulResponses[i] < MinUpdateRegistrySuccesses && workersLeft+successfulResponses[i] >= MinUpdateRegistrySuccesses
+	}
+	anyUndecided := func() bool {
+		for i := range validUpdates {
+			if undecided(i) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for anyUndecided() {
+		// Check deadline.
+		var resp *jobUpdateRegistryBatchResponse
+		select {
+		case <-ctx.Done():
+		case resp = <-staticResponseChan:
+		}
+		if resp == nil {
+			// Timeout reached. Mark all undecided entries as timed out.
+			for i := range validUpdates {
+				if undecided(i) {
+					errs[validIndices[i]] = ErrRegistryUpdateTimeout
+				}
+			}
+			break
+		}
+
+		// Decrement the number of workers.
+		workersLeft--
+
+		// Ignore failed jobs.
+		if resp.staticErr != nil {
+			continue
+		}
+
+		for i, respErr := range resp.staticErrs {
+			// Ignore error responses except for invalid revision errors.
+			if respErr != nil {
+				// If we receive ErrLowerRevNum or ErrSameRevNum, remember the
+				// revision number that was presented as proof. In the end we
+				// use the highest one to be able to determine the next
+				// revision number that is save to use.
+				if (errors.Contains(respErr, registry.ErrLowerRevNum) || errors.Contains(respErr, registry.ErrSameRevNum)) &&
+					resp.srvs[i] != nil && resp.srvs[i].Revision >= highestInvalidRevNums[i] {
+					highestInvalidRevNums[i] = resp.srvs[i].Revision
+					invalidRevNums[i] = true
+				}
+				continue
+			}
+			successfulResponses[i]++
+		}
+	}
+
+	// Determine the error of every entry that didn't time out.
+	for i, update := range validUpdates {
+		idx := validIndices[i]
+		if errs[idx] != nil {
+			continue
+		}
+		// Check for an invalid revision error and return the right error
+		// according to the highest invalid revision we remembered.
+		var entryErr error
+		if invalidRevNums[i] {
+			if highestInvalidRevNums[i] == update.Value.Revision {
+				entryErr = registry.ErrSameRevNum
+			} else {
+				entryErr = registry.ErrLowerRevNum
+			}
+		}
+		// Check if we ran out of workers.
+		if successfulResponses[i] == 0 {
+			errs[idx] = errors.Compose(entryErr, ErrRegistryUpdateNoSuccessfulUpdates)
+		} else if successfulResponses[i] < MinUpdateRegistrySuccesses {
+			errs[idx] = errors.Compose(entryErr, ErrRegistryUpdateInsufficientRedundancy)
+		}
+	}
+	return errs, nil
+}
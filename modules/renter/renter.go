@@ -101,10 +101,24 @@ type hostContractor interface {
 	// watchdog.
 	ContractStatus(fcID types.FileContractID) (modules.ContractWatchStatus, bool)
 
+	// ContractStatuses returns the status of all the contracts within the
+	// watchdog and the storage proof statistics of their hosts.
+	ContractStatuses() ([]modules.ContractWatchStatus, []modules.HostStorageProofStats)
+
+	// CopySectorSource returns the ID of a contract with the host, other than
+	// the host's current contract, that contains the sector with the given
+	// root and can be copied from.
+	CopySectorSource(host types.SiaPublicKey, root crypto.Hash) (types.FileContractID, bool)
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
 
+	// FinalizeAppendProgram conducts the revision signing handshake which
+	// finalizes a write program that appended the sector with the given root
+	// to the contract with the given id.
+	FinalizeAppendProgram(stream io.ReadWriter, id types.FileContractID, root crypto.Hash, lastOutput modules.RPCExecuteProgramResponse, bh types.BlockHeight) error
+
 	// InitRecoveryScan starts scanning the whole blockchain for recoverable
 	// contracts within a separate thread.
 	InitRecoveryScan() error
@@ -113,6 +127,14 @@ type hostContractor interface {
 	// billing period.
 	PeriodSpending() (modules.ContractorSpending, error)
 
+	// RecordSpending records money that was spent from the ephemeral account
+	// on the given host in the contract spending ledger.
+	RecordSpending(host types.SiaPublicKey, category modules.ContractSpendingCategory, amount types.Currency)
+
+	// SpendingLedger returns the entries of the contract spending ledger
+	// within the time range, optionally filtered by host.
+	SpendingLedger(start, end time.Time, host types.SiaPublicKey) ([]modules.ContractSpendingEntry, error)
+
 	// ProvidePayment takes a stream and a set of payment details and handles
 	// the payment for an RPC by sending and processing payment request and
 	// response objects to the host. It returns an error in case of failure.
@@ -121,6 +143,11 @@ type hostContractor interface {
 	// OldContracts returns the oldContracts of the renter's hostContractor.
 	OldContracts() []modules.RenterContract
 
+	// SignCopySector signs the renter's authorization for the host to copy
+	// the sector with the given root from the contract srcID to the contract
+	// dstID.
+	SignCopySector(srcID, dstID types.FileContractID, root crypto.Hash) (crypto.Signature, error)
+
 	// Editor creates an Editor from the specified contract ID, allowing the
 	// insertion, deletion, and modification of sectors.
 	Editor(types.SiaPublicKey, <-chan struct{}) (contractor.Editor, error)
@@ -257,6 +284,7 @@ type Renter struct {
 	staticAlerter                      *modules.GenericAlerter
 	staticFileSystem                   *filesystem.FileSystem
 	staticFuseManager                  renterFuseManager
+	staticRecovery                     *renterRecovery
 	staticStreamBufferSet              *streamBufferSet
 	tg                                 threadgroup.ThreadGroup
 	tpool                              modules.TransactionPool
@@ -604,6 +632,9 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	if s.MaxDownloadSpeed < 0 || s.MaxUploadSpeed < 0 {
 		return errors.New("bandwidth limits cannot be negative")
 	}
+	if err := validateBackupSchedule(s.BackupSchedule); err != nil {
+		return err
+	}
 
 	// Set allowance.
 	err := r.hostContractor.SetAllowance(s.Allowance)
@@ -624,6 +655,7 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	id := r.mu.Lock()
 	r.persist.MaxDownloadSpeed = s.MaxDownloadSpeed
 	r.persist.MaxUploadSpeed = s.MaxUploadSpeed
+	r.persist.BackupSchedule = s.BackupSchedule
 	err = r.saveSync()
 	r.mu.Unlock(id)
 	if err != nil {
@@ -767,6 +799,12 @@ func (r *Renter) ContractStatus(fcID types.FileContractID) (modules.ContractWatc
 	return r.hostContractor.ContractStatus(fcID)
 }
 
+// ContractStatuses returns the status of all the contracts within the watchdog
+// and the storage proof statistics of their hosts.
+func (r *Renter) ContractStatuses() ([]modules.ContractWatchStatus, []modules.HostStorageProofStats) {
+	return r.hostContractor.ContractStatuses()
+}
+
 // ContractorChurnStatus returns contract churn stats for the current period.
 func (r *Renter) ContractorChurnStatus() modules.ContractorChurnStatus {
 	return r.hostContractor.ChurnStatus()
@@ -794,6 +832,12 @@ func (r *Renter) PeriodSpending() (modules.ContractorSpending, error) {
 	return r.hostContractor.PeriodSpending()
 }
 
+// SpendingLedger returns the entries of the contract spending ledger that were
+// recorded between start and end, optionally filtered by host.
+func (r *Renter) SpendingLedger(start, end time.Time, host types.SiaPublicKey) ([]modules.ContractSpendingEntry, error) {
+	return r.hostContractor.SpendingLedger(start, end, host)
+}
+
 // RecoverableContracts returns the host contractor's recoverable contracts.
 func (r *Renter) RecoverableContracts() []modules.RecoverableContract {
 	return r.hostContractor.RecoverableContracts()
@@ -817,8 +861,12 @@ func (r *Renter) Settings() (modules.RenterSettings, error) {
 		return modules.RenterSettings{}, errors.AddContext(err, "error getting IPViolationsCheck:")
 	}
 	paused, endTime := r.uploadHeap.managedPauseStatus()
+	id := r.mu.RLock()
+	schedule := r.persist.BackupSchedule
+	r.mu.RUnlock(id)
 	return modules.RenterSettings{
 		Allowance:        r.hostContractor.Allowance(),
+		BackupSchedule:   schedule,
 		IPViolationCheck: enabled,
 		MaxDownloadSpeed: download,
 		MaxUploadSpeed:   upload,
@@ -926,6 +974,7 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 		tpool:          tpool,
 	}
 	r.staticBubbleScheduler = newBubbleScheduler(r)
+	r.staticRecovery = newRenterRecovery()
 	r.staticStreamBufferSet = newStreamBufferSet(&r.tg)
 	r.staticUploadChunkDistributionQueue = newUploadChunkDistributionQueue(r)
 	r.staticRRS = newReadRegistryStats(ReadRegistryBackgroundTimeout, readRegistryStatsInterval, readRegistryStatsDecay, readRegistryStatsPercentile)
@@ -1042,6 +1091,7 @@ func renterAsyncStartup(r *Renter, cs modules.ConsensusSet) error {
 	// Spin up the snapshot synchronization thread.
 	if !r.deps.Disrupt("DisableSnapshotSync") {
 		go r.threadedSynchronizeSnapshots()
+		go r.threadedScheduleBackups()
 	}
 	return nil
 }
@@ -0,0 +1,368 @@
+package renter
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errRecoveryInProgress is returned when a renter recovery is started
+	// while another one is still in progress.
+	errRecoveryInProgress = errors.New("renter recovery is already in progress")
+
+	// errNoRecoverableBackup is returned if none of the backups found on the
+	// renter's hosts could be downloaded.
+	errNoRecoverableBackup = errors.New("none of the backups could be downloaded")
+)
+
+var (
+	// recoveryContractsTimeout is the amount of time the renter recovery waits
+	// for the recovered contracts to become usable after the recovery scan
+	// finished.
+	recoveryContractsTimeout = build.Select(build.Var{
+		Dev:      5 * time.Minute,
+		Standard: 30 * time.Minute,
+		Testing:  time.Minute,
+	}).(time.Duration)
+
+	// recoveryPollInterval is the interval at which the renter recovery checks
+	// whether the recovery scan finished and whether contracts are available.
+	recoveryPollInterval = build.Select(build.Var{
+		Dev:      time.Second,
+		Standard: 5 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+)
+
+// renterRecovery tracks the progress of recovering the renter from its seed.
+type renterRecovery struct {
+	inProgress bool
+	status     modules.RenterRecoveryStatus
+	mu         sync.Mutex
+}
+
+// newRenterRecovery creates a new renterRecovery.
+func newRenterRecovery() *renterRecovery {
+	return &renterRecovery{
+		status: modules.RenterRecoveryStatus{
+			Stage: modules.RecoveryStageIdle,
+		},
+	}
+}
+
+// callStart marks the recovery as started. It returns an error if another
+// recovery is already in progress.
+func (rr *renterRecovery) callStart() error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if rr.inProgress {
+		return errRecoveryInProgress
+	}
+	rr.inProgress = true
+	rr.status = modules.RenterRecoveryStatus{
+		Stage:     modules.RecoveryStageScanning,
+		StartTime: time.Now(),
+	}
+	return nil
+}
+
+// callFinish marks the recovery as finished. If err is not nil, the recovery
+// is marked as failed.
+func (rr *renterRecovery) callFinish(err error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.inProgress = false
+	rr.status.EndTime = time.Now()
+	if err != nil {
+		rr.status.Stage = modules.RecoveryStageFailed
+		rr.status.Error = err.Error()
+		return
+	}
+	rr.status.Stage = modules.RecoveryStageDone
+}
+
+// callStatus returns a copy of the recovery status.
+func (rr *renterRecovery) callStatus() modules.RenterRecoveryStatus {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	status := rr.status
+	status.FailedFiles = append([]modules.RecoveryFailedFile(nil), rr.status.FailedFiles...)
+	return status
+}
+
+// callUpdate applies the update to the recovery status.
+func (rr *renterRecovery) callUpdate(update func(*modules.RenterRecoveryStatus)) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	update(&rr.status)
+}
+
+// recoveryCandidates merges the backups found on multiple hosts into a single
+// list without duplicates. The backups are sorted from newest to oldest.
+func recoveryCandidates(hostBackups [][]modules.UploadedBackup) []modules.UploadedBackup {
+	seen := make(map[[16]byte]struct{})
+	var candidates []modules.UploadedBackup
+	for _, backups := range hostBackups {
+		for _, backup := range backups {
+			if _, exists := seen[backup.UID]; exists {
+				continue
+			}
+			seen[backup.UID] = struct{}{}
+			candidates = append(candidates, backup)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CreationDate > candidates[j].CreationDate
+	})
+	return candidates
+}
+
+// RecoverRenter starts recovering the renter's contracts and metadata using
+// the wallet's seed. The progress can be tracked using RenterRecoveryStatus.
+func (r *Renter) RecoverRenter() error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	// The wallet needs to be unlocked to derive the renter seed.
+	unlocked, err := r.w.Unlocked()
+	if err != nil {
+		return errors.AddContext(err, "failed to check if the wallet is unlocked")
+	}
+	if !unlocked {
+		return modules.ErrLockedWallet
+	}
+	if err := r.staticRecovery.callStart(); err != nil {
+		return err
+	}
+	go r.threadedRecoverRenter()
+	return nil
+}
+
+// RenterRecoveryStatus returns the progress of the renter recovery.
+func (r *Renter) RenterRecoveryStatus() (modules.RenterRecoveryStatus, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.RenterRecoveryStatus{}, err
+	}
+	defer r.tg.Done()
+	return r.staticRecovery.callStatus(), nil
+}
+
+// threadedRecoverRenter recovers the renter's contracts from the blockchain
+// and restores the newest backup that can be downloaded from the hosts.
+func (r *Renter) threadedRecoverRenter() {
+	if err := r.tg.Add(); err != nil {
+		r.staticRecovery.callFinish(err)
+		return
+	}
+	defer r.tg.Done()
+
+	err := r.managedRecoverRenter()
+	if err != nil {
+		r.log.Println("WARN: renter recovery failed:", err)
+	}
+	r.staticRecovery.callFinish(err)
+}
+
+// managedRecoverRenter runs the stages of the renter recovery.
+func (r *Renter) managedRecoverRenter() error {
+	// Scan the blockchain for recoverable contracts unless a scan is running
+	// already.
+	if scanning, _ := r.hostContractor.RecoveryScanStatus(); !scanning {
+		if err := r.hostContractor.InitRecoveryScan(); err != nil {
+			return errors.AddContext(err, "failed to start recovery scan")
+		}
+	}
+	err := r.managedRecoveryPoll(0, func() (bool, error) {
+		scanning, height := r.hostContractor.RecoveryScanStatus()
+		r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+			if height > s.ScannedHeight {
+				s.ScannedHeight = height
+			}
+		})
+		return !scanning, nil
+	})
+	if err != nil {
+		return errors.AddContext(err, "failed to wait for recovery scan")
+	}
+
+	// Wait for the recovered contracts to become usable.
+	r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+		s.Stage = modules.RecoveryStageWaitingForContracts
+	})
+	var contracts []modules.RenterContract
+	err = r.managedRecoveryPoll(recoveryContractsTimeout, func() (bool, error) {
+		r.staticWorkerPool.callUpdate()
+		contracts = contracts[:0]
+		for _, c := range r.hostContractor.Contracts() {
+			if _, err := r.staticWorkerPool.callWorker(c.HostPublicKey); err == nil {
+				contracts = append(contracts, c)
+			}
+		}
+		r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+			s.Contracts = len(contracts)
+		})
+		return len(contracts) > 0, nil
+	})
+	if err != nil {
+		return errors.AddContext(err, "no contracts were recovered")
+	}
+
+	// Fetch the backups from all the hosts.
+	r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+		s.Stage = modules.RecoveryStageFetchingBackups
+	})
+	hostBackups := make([][]modules.UploadedBackup, 0, len(contracts))
+	for _, c := range contracts {
+		backups, err := r.managedBackupsOnHost(c.HostPublicKey)
+		if err != nil {
+			r.log.Printf("Fetching backups from host %v during recovery failed: %v", c.HostPublicKey, err)
+		}
+		hostBackups = append(hostBackups, backups)
+		r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+			s.HostsQueried++
+		})
+	}
+	candidates := recoveryCandidates(hostBackups)
+	r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+		s.BackupsFound = len(candidates)
+	})
+	if len(candidates) == 0 {
+		return errors.New("no backups found on the recovered hosts")
+	}
+
+	// Download the newest backup that can be downloaded and restore it.
+	tmpDir, err := ioutil.TempDir("", "sia-recovery")
+	if err != nil {
+		return errors.AddContext(err, "failed to create temporary directory for the backup")
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+	return r.managedRestoreNewestBackup(candidates, func(backup modules.UploadedBackup) (string, error) {
+		return r.managedDownloadRecoveryBackup(tmpDir, backup)
+	})
+}
+
+// managedRestoreNewestBackup downloads and restores the candidates from newest
+// to oldest until one of them is restored successfully. A backup that can't be
+// downloaded or restored, e.g. because it is corrupt, is skipped in favor of
+// the next older one.
+func (r *Renter) managedRestoreNewestBackup(candidates []modules.UploadedBackup, download func(modules.UploadedBackup) (string, error)) error {
+	for _, backup := range candidates {
+		r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+			s.Stage = modules.RecoveryStageDownloading
+			s.Backup = backup
+		})
+		backupPath, err := download(backup)
+		if err != nil {
+			r.log.Printf("Downloading backup %v during recovery failed: %v", backup.Name, err)
+			continue
+		}
+		r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+			s.Stage = modules.RecoveryStageRestoring
+		})
+		err = r.managedRestoreRecoveryBackup(backupPath)
+		if err != nil {
+			r.log.Printf("Restoring backup %v during recovery failed: %v", backup.Name, err)
+			continue
+		}
+		return nil
+	}
+	return errNoRecoverableBackup
+}
+
+// managedRecoveryPoll calls check at the recoveryPollInterval until it returns
+// true, an error, the timeout is reached or the renter is shut down. A timeout
+// of 0 means that there is no timeout.
+func (r *Renter) managedRecoveryPoll(timeout time.Duration, check func() (bool, error)) error {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-r.tg.StopChan():
+			return errors.New("renter was shut down")
+		case <-deadline:
+			return errors.New("timeout reached")
+		case <-time.After(recoveryPollInterval):
+		}
+	}
+}
+
+// managedBackupsOnHost fetches the backups stored on the host.
+func (r *Renter) managedBackupsOnHost(hostKey types.SiaPublicKey) ([]modules.UploadedBackup, error) {
+	w, err := r.staticWorkerPool.callWorker(hostKey)
+	if err != nil {
+		return nil, errors.AddContext(err, "host not found in the worker table")
+	}
+	return w.FetchBackups(r.tg.StopCtx())
+}
+
+// managedDownloadRecoveryBackup downloads the backup into dir and returns the
+// path of the downloaded file.
+func (r *Renter) managedDownloadRecoveryBackup(dir string, backup modules.UploadedBackup) (_ string, err error) {
+	// Don't use the name of the backup for the file since it might not be a
+	// valid filename.
+	f, err := ioutil.TempFile(dir, "backup")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+	// Remember the backup so that it shows up in the list of backups after the
+	// recovery.
+	if !r.managedSnapshotExists(backup.Name) {
+		if err := r.managedSaveSnapshot(backup); err != nil {
+			return "", errors.AddContext(err, "failed to save snapshot metadata")
+		}
+	}
+	if err := r.managedDownloadBackup(f, backup.UID, backup.Name); err != nil {
+		return "", errors.AddContext(err, "failed to download backup")
+	}
+	return f.Name(), nil
+}
+
+// managedRestoreRecoveryBackup restores the siafiles of the downloaded backup
+// and records which files could not be restored.
+func (r *Renter) managedRestoreRecoveryBackup(backupPath string) error {
+	// Get the wallet seed.
+	ws, _, err := r.w.PrimarySeed()
+	if err != nil {
+		return errors.AddContext(err, "failed to get wallet's primary seed")
+	}
+	// Derive the renter seed and wipe the memory once we are done using it.
+	rs := modules.DeriveRenterSeed(ws)
+	defer fastrand.Read(rs[:])
+	// Derive the secret and wipe it afterwards.
+	secret := crypto.HashAll(rs, modules.BackupKeySpecifier)
+	defer fastrand.Read(secret[:])
+
+	restored, failed, err := r.managedLoadBackup(backupPath, secret[:32], true)
+	if err != nil {
+		return errors.AddContext(err, "failed to load backup")
+	}
+	r.staticRecovery.callUpdate(func(s *modules.RenterRecoveryStatus) {
+		s.RestoredFiles = restored
+		s.FailedFiles = failed
+	})
+	return nil
+}
@@ -0,0 +1,150 @@
+package renter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestRecoveryCandidates tests that the backups found on multiple hosts are
+// deduplicated and sorted from newest to oldest.
+func TestRecoveryCandidates(t *testing.T) {
+	t.Parallel()
+
+	older := modules.UploadedBackup{Name: "older", UID: [16]byte{1}, CreationDate: 100}
+	newer := modules.UploadedBackup{Name: "newer", UID: [16]byte{2}, CreationDate: 200}
+	newest := modules.UploadedBackup{Name: "newest", UID: [16]byte{3}, CreationDate: 300}
+
+	// No backups.
+	if candidates := recoveryCandidates(nil); len(candidates) != 0 {
+		t.Fatal("expected no candidates", candidates)
+	}
+
+	// Backups stored on multiple hosts.
+	candidates := recoveryCandidates([][]modules.UploadedBackup{
+		{older, newer},
+		nil,
+		{newest, older},
+		{newer},
+	})
+	if len(candidates) != 3 {
+		t.Fatal("wrong number of candidates", candidates)
+	}
+	for i, expected := range []modules.UploadedBackup{newest, newer, older} {
+		if candidates[i].UID != expected.UID {
+			t.Fatalf("candidate %v should be %v but was %v", i, expected.Name, candidates[i].Name)
+		}
+	}
+}
+
+// TestRenterRecoveryStatus tests the state transitions of the renterRecovery.
+func TestRenterRecoveryStatus(t *testing.T) {
+	t.Parallel()
+
+	rr := newRenterRecovery()
+	if status := rr.callStatus(); status.Stage != modules.RecoveryStageIdle {
+		t.Fatal("wrong initial stage", status.Stage)
+	}
+
+	// Start a recovery. Starting another one should fail.
+	if err := rr.callStart(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rr.callStart(); !errors.Contains(err, errRecoveryInProgress) {
+		t.Fatal("expected errRecoveryInProgress", err)
+	}
+	if status := rr.callStatus(); status.Stage != modules.RecoveryStageScanning || status.StartTime.IsZero() {
+		t.Fatal("wrong status", status)
+	}
+
+	// Finish it successfully.
+	failed := []modules.RecoveryFailedFile{{SiaPath: modules.RandomSiaPath(), Error: "foo"}}
+	rr.callUpdate(func(s *modules.RenterRecoveryStatus) {
+		s.RestoredFiles = 1
+		s.FailedFiles = failed
+	})
+	rr.callFinish(nil)
+	status := rr.callStatus()
+	if status.Stage != modules.RecoveryStageDone || status.EndTime.IsZero() || status.Error != "" {
+		t.Fatal("wrong status", status)
+	}
+	if status.RestoredFiles != 1 || len(status.FailedFiles) != 1 || !status.FailedFiles[0].SiaPath.Equals(failed[0].SiaPath) {
+		t.Fatal("wrong files in status", status)
+	}
+
+	// A new recovery resets the status and can fail.
+	if err := rr.callStart(); err != nil {
+		t.Fatal(err)
+	}
+	if status := rr.callStatus(); status.RestoredFiles != 0 || len(status.FailedFiles) != 0 {
+		t.Fatal("status wasn't reset", status)
+	}
+	rr.callFinish(errNoRecoverableBackup)
+	status = rr.callStatus()
+	if status.Stage != modules.RecoveryStageFailed || status.Error != errNoRecoverableBackup.Error() {
+		t.Fatal("wrong status", status)
+	}
+}
+
+// TestRestoreNewestBackupCorrupt tests that a recovery falls back to an older
+// backup if the newest one is corrupt.
+func TestRestoreNewestBackupCorrupt(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	// Create a valid backup with the recovery secret and a corrupt one.
+	ws, _, err := rt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := modules.DeriveRenterSeed(ws)
+	secret := crypto.HashAll(rs, modules.BackupKeySpecifier)
+	validPath := filepath.Join(rt.dir, "valid")
+	if err := r.managedCreateBackup(validPath, secret[:32]); err != nil {
+		t.Fatal(err)
+	}
+	corruptPath := filepath.Join(rt.dir, "corrupt")
+	if err := ioutil.WriteFile(corruptPath, fastrand.Bytes(1024), 0600); err != nil {
+		t.Fatal(err)
+	}
+	older := modules.UploadedBackup{Name: "older", UID: [16]byte{1}, CreationDate: 100}
+	newest := modules.UploadedBackup{Name: "newest", UID: [16]byte{2}, CreationDate: 200}
+	paths := map[string]string{older.Name: validPath, newest.Name: corruptPath}
+	download := func(backup modules.UploadedBackup) (string, error) {
+		return paths[backup.Name], nil
+	}
+
+	// The older backup should be restored.
+	err = r.managedRestoreNewestBackup([]modules.UploadedBackup{newest, older}, download)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := r.staticRecovery.callStatus(); status.Backup.Name != older.Name {
+		t.Fatal("wrong backup was restored", status.Backup.Name)
+	}
+
+	// If all backups are corrupt, the recovery fails.
+	err = r.managedRestoreNewestBackup([]modules.UploadedBackup{newest}, download)
+	if !errors.Contains(err, errNoRecoverableBackup) {
+		t.Fatal("expected errNoRecoverableBackup", err)
+	}
+}
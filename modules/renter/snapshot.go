@@ -218,6 +218,12 @@ func (r *Renter) DownloadBackup(dst string, name string) (err error) {
 	if !found {
 		return errors.New("no record of a backup with that name")
 	}
+	return r.managedDownloadBackup(dstFile, uid, name)
+}
+
+// managedDownloadBackup downloads the backup with the given uid and writes it
+// to dst.
+func (r *Renter) managedDownloadBackup(dst io.Writer, uid [16]byte, name string) (err error) {
 	// Download snapshot's .sia file.
 	_, dotSia, err := r.managedDownloadSnapshot(uid)
 	if err != nil {
@@ -249,7 +255,7 @@ func (r *Renter) DownloadBackup(dst string, name string) (err error) {
 		return err
 	}
 	s := r.managedStreamer(snap, false)
-	_, err = io.Copy(dstFile, s)
+	_, err = io.Copy(dst, s)
 	return errors.Compose(err, s.Close())
 }
 
@@ -270,6 +276,10 @@ func (r *Renter) managedSnapshotExists(name string) bool {
 func (r *Renter) managedSaveSnapshot(meta modules.UploadedBackup) error {
 	id := r.mu.Lock()
 	defer r.mu.Unlock(id)
+	// Don't bring back deleted snapshots.
+	if _, deleted := r.deletedBackups()[meta.UID]; deleted {
+		return nil
+	}
 	// Check whether we've already saved this snapshot.
 	for i, ub := range r.persist.UploadedBackups {
 		if ub.UID == meta.UID {
@@ -538,15 +548,20 @@ func (r *Renter) threadedSynchronizeSnapshots() {
 		return
 	}
 	defer r.tg.Done()
-	// calcOverlap takes a host's entry table and the set of known snapshots,
-	// and calculates which snapshots the host is missing and which snapshots it
-	// has that we don't.
-	calcOverlap := func(entryTable []snapshotEntry, known map[[16]byte]struct{}) (unknown []modules.UploadedBackup, missing [][16]byte) {
+	// calcOverlap takes a host's entry table, the set of known snapshots and
+	// the set of deleted snapshots, and calculates which snapshots the host is
+	// missing, which snapshots it has that we don't and which deleted snapshots
+	// it still stores.
+	calcOverlap := func(entryTable []snapshotEntry, known, deleted map[[16]byte]struct{}) (unknown []modules.UploadedBackup, missing, stale [][16]byte) {
 		missingMap := make(map[[16]byte]struct{}, len(known))
 		for uid := range known {
 			missingMap[uid] = struct{}{}
 		}
 		for _, e := range entryTable {
+			if _, ok := deleted[e.UID]; ok {
+				stale = append(stale, e.UID)
+				continue
+			}
 			if _, ok := known[e.UID]; !ok {
 				unknown = append(unknown, modules.UploadedBackup{
 					Name:           string(bytes.TrimRight(e.Name[:], types.RuneToString(0))),
@@ -570,6 +585,7 @@ func (r *Renter) threadedSynchronizeSnapshots() {
 	for _, fcid := range r.persist.SyncedContracts {
 		syncedContracts[fcid] = struct{}{}
 	}
+	prevDeleted := r.deletedBackups()
 	r.mu.RUnlock(id)
 
 	for {
@@ -696,8 +712,19 @@ func (r *Renter) threadedSynchronizeSnapshots() {
 				}
 			}
 		}
+		deleted := r.deletedBackups()
 		r.mu.RUnlock(id)
 
+		// If any snapshots were deleted since the last iteration, all hosts
+		// need to be synchronized again to remove them.
+		for uid := range deleted {
+			if _, ok := prevDeleted[uid]; !ok {
+				syncedContracts = make(map[types.FileContractID]struct{})
+				break
+			}
+		}
+		prevDeleted = deleted
+
 		// Select an unsynchronized host.
 		contracts := r.hostContractor.Contracts()
 		var found bool
@@ -749,7 +776,15 @@ func (r *Renter) threadedSynchronizeSnapshots() {
 
 			// Calculate which snapshots the host doesn't have, and which
 			// snapshots it does have that we haven't seen before.
-			unknown, missing := calcOverlap(entryTable, known)
+			unknown, missing, stale := calcOverlap(entryTable, known, deleted)
+
+			// Remove any deleted snapshots from the host.
+			if len(stale) != 0 {
+				if err := w.DeleteSnapshots(r.tg.StopCtx(), stale); err != nil {
+					return err
+				}
+				r.log.Printf("Deleted %v snapshots from host %v", len(stale), c.HostPublicKey)
+			}
 
 			// If *any* snapshots are new, mark all other hosts as not
 			// synchronized.
@@ -35,6 +35,23 @@ const (
 	// host to support the registry.
 	minRegistryVersion = "1.5.1"
 
+	// minRegistryBatchVersion defines the minimum version that is required
+	// for a host to support reading and updating registry entries in batches.
+	minRegistryBatchVersion = "1.5.7"
+
+	// minCopySectorVersion defines the minimum version that is required for
+	// a host to support copying sectors between contracts with the
+	// CopySector instruction.
+	minCopySectorVersion = "1.5.7"
+
+	// minDryRunProgramVersion defines the minimum version that is required
+	// for a host to support the DryRunProgram RPC.
+	minDryRunProgramVersion = "1.5.7"
+
+	// minStreamProgramVersion defines the minimum version that is required
+	// for a host to support streaming the outputs of read instructions.
+	minStreamProgramVersion = "1.5.7"
+
 	// registryCacheSize is the cache size used by a single worker for the
 	// registry cache.
 	registryCacheSize = 1 << 20 // 1 MiB
@@ -79,14 +96,17 @@ type (
 		staticHostPubKeyStr string
 
 		// Job queues for the worker.
-		staticJobDownloadSnapshotQueue *jobDownloadSnapshotQueue
-		staticJobHasSectorQueue        *jobHasSectorQueue
-		staticJobReadQueue             *jobReadQueue
-		staticJobLowPrioReadQueue      *jobReadQueue
-		staticJobReadRegistryQueue     *jobReadRegistryQueue
-		staticJobRenewQueue            *jobRenewQueue
-		staticJobUpdateRegistryQueue   *jobUpdateRegistryQueue
-		staticJobUploadSnapshotQueue   *jobUploadSnapshotQueue
+		staticJobDeleteSnapshotQueue      *jobDeleteSnapshotQueue
+		staticJobDownloadSnapshotQueue    *jobDownloadSnapshotQueue
+		staticJobHasSectorQueue           *jobHasSectorQueue
+		staticJobReadQueue                *jobReadQueue
+		staticJobLowPrioReadQueue         *jobReadQueue
+		staticJobReadRegistryQueue        *jobReadRegistryQueue
+		staticJobReadRegistryBatchQueue   *jobReadRegistryBatchQueue
+		staticJobRenewQueue               *jobRenewQueue
+		staticJobUpdateRegistryQueue      *jobUpdateRegistryQueue
+		staticJobUpdateRegistryBatchQueue *jobUpdateRegistryBatchQueue
+		staticJobUploadSnapshotQueue      *jobUploadSnapshotQueue
 
 		// Upload variables.
 		unprocessedChunks         *uploadChunks // Yet unprocessed work items.
@@ -263,8 +283,11 @@ func (r *Renter) newWorker(hostPubKey types.SiaPublicKey) (*worker, error) {
 	w.initJobRenewQueue()
 	w.initJobDownloadSnapshotQueue()
 	w.initJobReadRegistryQueue()
+	w.initJobReadRegistryBatchQueue()
 	w.initJobUpdateRegistryQueue()
+	w.initJobUpdateRegistryBatchQueue()
 	w.initJobUploadSnapshotQueue()
+	w.initJobDeleteSnapshotQueue()
 
 	// Close the worker when the renter is stopped.
 	err = r.tg.OnStop(func() error {
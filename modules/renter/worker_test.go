@@ -197,6 +197,8 @@ func TestManagedAsyncReady(t *testing.T) {
 	w.initJobLowPrioReadQueue()
 	w.initJobReadRegistryQueue()
 	w.initJobUpdateRegistryQueue()
+	w.initJobReadRegistryBatchQueue()
+	w.initJobUpdateRegistryBatchQueue()
 
 	timeInFuture := time.Now().Add(time.Hour)
 	timeInPast := time.Now().Add(-time.Hour)
@@ -42,6 +42,7 @@ const (
 	// we pay for an rpc request using the ephemeral account as payment method.
 	categoryErr spendingCategory = iota
 	categoryDownload
+	categoryDryRun
 	categoryRegistryRead
 	categoryRegistryWrite
 	categoryRepairDownload
@@ -160,6 +161,7 @@ type (
 	// 'spendingCategory'.
 	spendingDetails struct {
 		downloads         types.Currency
+		dryRuns           types.Currency
 		registryReads     types.Currency
 		registryWrites    types.Currency
 		repairDownloads   types.Currency
@@ -175,6 +177,27 @@ type (
 	spendingCategory uint64
 )
 
+// ledgerCategory returns the category under which a spend of the given
+// category is recorded in the contract spending ledger.
+func (category spendingCategory) ledgerCategory() modules.ContractSpendingCategory {
+	switch category {
+	case categoryDownload, categoryRepairDownload, categorySnapshotDownload:
+		return modules.SpendingCategoryDownload
+	case categoryDryRun:
+		return modules.SpendingCategoryDryRun
+	case categoryRegistryRead:
+		return modules.SpendingCategoryRegistryRead
+	case categoryRegistryWrite:
+		return modules.SpendingCategoryRegistryWrite
+	case categorySubscription:
+		return modules.SpendingCategorySubscription
+	case categoryUpload, categoryRepairUpload, categorySnapshotUpload:
+		return modules.SpendingCategoryUpload
+	}
+	build.Critical("category is not handled, developer error")
+	return ""
+}
+
 // update will add the the spend of given amount to the appropriate field
 // depending on the given category
 func (s *spendingDetails) update(category spendingCategory, amount types.Currency) {
@@ -186,6 +209,8 @@ func (s *spendingDetails) update(category spendingCategory, amount types.Currenc
 	switch category {
 	case categoryDownload:
 		s.downloads = s.downloads.Add(amount)
+	case categoryDryRun:
+		s.dryRuns = s.dryRuns.Add(amount)
 	case categorySnapshotDownload:
 		s.snapshotDownloads = s.snapshotDownloads.Add(amount)
 	case categorySnapshotUpload:
@@ -487,6 +512,12 @@ func (a *account) trackSpending(category spendingCategory, amount types.Currency
 	// update the spending metrics
 	a.spending.update(category, amount)
 
+	// record the spend in the contract spending ledger, accounts that are not
+	// managed by a renter have no ledger to record to
+	if a.staticRenter != nil {
+		a.staticRenter.hostContractor.RecordSpending(a.staticHostKey, category.ledgerCategory(), amount)
+	}
+
 	// every time we update we write the account to disk
 	err := a.persist()
 	if err != nil {
@@ -103,6 +103,11 @@ type (
 		SpendingSnapshotUploads   types.Currency
 		SpendingSubscriptions     types.Currency
 		SpendingUploads           types.Currency
+
+		// SpendingDryRuns was added after the other spending details. It's
+		// appended to remain compatible with persisted accounts, which decode
+		// it from their zero padding.
+		SpendingDryRuns types.Currency
 	}
 
 	// accountPersistenceV150 is how the account persistence struct looked
@@ -161,6 +166,7 @@ func (a *account) persist() error {
 		SpendingSnapshotUploads:   a.spending.snapshotUploads,
 		SpendingSubscriptions:     a.spending.subscriptions,
 		SpendingUploads:           a.spending.uploads,
+		SpendingDryRuns:           a.spending.dryRuns,
 	}
 
 	_, err := a.staticFile.WriteAt(accountData.bytes(), a.staticOffset)
@@ -234,6 +240,7 @@ func (am *accountManager) managedOpenAccount(hostKey types.SiaPublicKey) (acc *a
 
 		staticFile:   am.staticFile,
 		staticOffset: int64(offset),
+		staticRenter: am.staticRenter,
 
 		staticReady: make(chan struct{}),
 	}
@@ -559,6 +566,7 @@ func (am *accountManager) readAccountAt(offset int64) (*account, error) {
 		// spending details
 		spending: spendingDetails{
 			downloads:         accountData.SpendingDownloads,
+			dryRuns:           accountData.SpendingDryRuns,
 			registryReads:     accountData.SpendingRegistryReads,
 			registryWrites:    accountData.SpendingRegistryWrites,
 			repairDownloads:   accountData.SpendingRepairDownloads,
@@ -574,6 +582,7 @@ func (am *accountManager) readAccountAt(offset int64) (*account, error) {
 
 		staticOffset: offset,
 		staticFile:   am.staticFile,
+		staticRenter: am.staticRenter,
 	}
 	close(acc.staticReady)
 	return acc, nil
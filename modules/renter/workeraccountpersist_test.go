@@ -46,6 +46,7 @@ func newRandomAccountPersistence() accountPersistence {
 		SpendingSnapshotUploads:   randomBalance(1e2),
 		SpendingSubscriptions:     randomBalance(1e2),
 		SpendingUploads:           randomBalance(1e2),
+		SpendingDryRuns:           randomBalance(1e2),
 	}
 }
 
@@ -464,7 +465,8 @@ func TestAccountPersistenceToAndFromBytes(t *testing.T) {
 		!ap.SpendingSnapshotDownloads.Equals(uMar.SpendingSnapshotDownloads) ||
 		!ap.SpendingSnapshotUploads.Equals(uMar.SpendingSnapshotUploads) ||
 		!ap.SpendingSubscriptions.Equals(uMar.SpendingSubscriptions) ||
-		!ap.SpendingUploads.Equals(uMar.SpendingUploads) {
+		!ap.SpendingUploads.Equals(uMar.SpendingUploads) ||
+		!ap.SpendingDryRuns.Equals(uMar.SpendingDryRuns) {
 		t.Fatal("Unexpected spending details")
 	}
 
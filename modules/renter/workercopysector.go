@@ -0,0 +1,81 @@
+package renter
+
+import (
+	"io"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errCopySectorNotSupported is returned if the worker's host doesn't
+	// support the CopySector instruction.
+	errCopySectorNotSupported = errors.New("host doesn't support copying sectors")
+)
+
+// copySectorExpectedBandwidth is a helper function that returns the expected
+// bandwidth consumption of copying a sector. A few frames are needed for the
+// program, its output and the revision signing handshake.
+func copySectorExpectedBandwidth() (ul, dl uint64) {
+	return 3 * ethernetMTU, 3 * ethernetMTU
+}
+
+// managedCopySector copies the sector with the given root, which the host
+// stores under the contract srcID, to the worker's contract using the
+// CopySector instruction. This saves uploading the sector again when the host
+// already has it. The program is paid for from the worker's ephemeral account.
+func (w *worker) managedCopySector(srcID types.FileContractID, root crypto.Hash, category spendingCategory) error {
+	// Check the host version.
+	if build.VersionCmp(w.staticCache().staticHostVersion, minCopySectorVersion) < 0 {
+		return errCopySectorNotSupported
+	}
+
+	// Get the contract to copy the sector to.
+	contract, exists := w.renter.hostContractor.ContractByPublicKey(w.staticHostPubKey)
+	if !exists || len(contract.Transaction.FileContractRevisions) == 0 {
+		return errors.New("worker has no contract with the host")
+	}
+	rev := contract.Transaction.FileContractRevisions[0]
+
+	// Authorize the copy with the source contract's key.
+	sig, err := w.renter.hostContractor.SignCopySector(srcID, contract.ID, root)
+	if err != nil {
+		return errors.AddContext(err, "failed to sign copy")
+	}
+
+	// Create the program.
+	pt := w.staticPriceTable().staticPriceTable
+	if rev.NewWindowEnd <= pt.HostBlockHeight {
+		return errors.New("contract with the host already expired")
+	}
+	pb := modules.NewProgramBuilder(&pt, rev.NewWindowEnd-pt.HostBlockHeight)
+	pb.AddCopySectorInstruction(srcID, root, sig, true)
+	program, programData := pb.Program()
+	cost, _, _ := pb.Cost(true)
+
+	// take into account bandwidth costs
+	ulBandwidth, dlBandwidth := copySectorExpectedBandwidth()
+	bandwidthCost := modules.MDMBandwidthCost(pt, ulBandwidth, dlBandwidth)
+	cost = cost.Add(bandwidthCost)
+
+	// Execute the program and finalize it with a new revision.
+	finalize := func(stream io.ReadWriter, lastResponse programResponse) error {
+		return w.renter.hostContractor.FinalizeAppendProgram(stream, contract.ID, root, lastResponse.RPCExecuteProgramResponse, pt.HostBlockHeight)
+	}
+	responses, _, err := w.managedExecuteWriteProgram(program, programData, contract.ID, category, cost, finalize)
+	if err != nil {
+		return errors.AddContext(err, "Unable to execute program")
+	}
+	for _, resp := range responses {
+		if resp.Error != nil {
+			return errors.AddContext(resp.Error, "Output error")
+		}
+	}
+	if len(responses) != len(program) {
+		return errors.New("received invalid number of responses but no error")
+	}
+	return nil
+}
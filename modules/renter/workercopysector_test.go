@@ -0,0 +1,109 @@
+package renter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestWorkerCopySector tests copying a sector the host already stores with
+// managedCopySector.
+func TestWorkerCopySector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	w := wt.worker
+	hpk := wt.host.PublicKey()
+
+	// allow the worker some time to fetch a PT and fund its EA
+	err = build.Retry(600, 100*time.Millisecond, func() error {
+		if w.staticAccount.managedMinExpectedBalance().IsZero() {
+			return errors.New("account not funded yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// upload a sector to the host
+	e, err := w.renter.hostContractor.Editor(hpk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := e.Upload(fastrand.Bytes(int(modules.SectorSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	contract, _ := w.renter.hostContractor.ContractByPublicKey(hpk)
+	revBefore := contract.Transaction.FileContractRevisions[0]
+
+	// the current contract is not a source to copy from even though it
+	// contains the sector
+	if _, exists := w.renter.hostContractor.CopySectorSource(hpk, root); exists {
+		t.Fatal("current contract shouldn't be a copy source")
+	}
+
+	// copying an unknown sector should fail
+	err = w.managedCopySector(contract.ID, crypto.Hash{1}, categoryRepairUpload)
+	if err == nil {
+		t.Fatal("expected copying an unknown sector to fail")
+	}
+
+	// copy the sector within the contract
+	err = w.managedCopySector(contract.ID, root, categoryRepairUpload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the renter's revision should contain the sector twice
+	contract, _ = w.renter.hostContractor.ContractByPublicKey(hpk)
+	rev := contract.Transaction.FileContractRevisions[0]
+	if rev.NewRevisionNumber != revBefore.NewRevisionNumber+1 {
+		t.Fatal("revision number wasn't incremented", rev.NewRevisionNumber, revBefore.NewRevisionNumber)
+	}
+	if rev.NewFileSize != 2*modules.SectorSize {
+		t.Fatal("wrong file size", rev.NewFileSize)
+	}
+	tree := crypto.NewCachedTree(0)
+	tree.Push(root)
+	tree.Push(root)
+	if rev.NewFileMerkleRoot != tree.Root() {
+		t.Fatal("wrong merkle root")
+	}
+
+	// the host should agree once it finalized the program
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		so, err := wt.host.StorageObligation(contract.ID)
+		if err != nil {
+			return err
+		}
+		if so.RevisionNumber != rev.NewRevisionNumber || so.SectorRootsCount != 2 {
+			return fmt.Errorf("host has a different revision %v %v", so.RevisionNumber, so.SectorRootsCount)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
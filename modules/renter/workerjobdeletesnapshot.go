@@ -0,0 +1,193 @@
+package renter
+
+import (
+	"context"
+
+	"go.sia.tech/siad/modules/renter/contractor"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+type (
+	// jobDeleteSnapshot is a job for the worker to remove snapshots from the
+	// snapshot table of its respective host.
+	jobDeleteSnapshot struct {
+		staticUIDs [][16]byte
+
+		staticResponseChan chan *jobDeleteSnapshotResponse
+
+		*jobGeneric
+	}
+
+	// jobDeleteSnapshotQueue contains the set of snapshot deletions that need
+	// to be performed.
+	jobDeleteSnapshotQueue struct {
+		*jobGenericQueue
+	}
+
+	// jobDeleteSnapshotResponse contains the response to a delete snapshot
+	// job.
+	jobDeleteSnapshotResponse struct {
+		staticErr error
+	}
+)
+
+// callDiscard will discard this job, sending an error down the response
+// channel.
+func (j *jobDeleteSnapshot) callDiscard(err error) {
+	resp := &jobDeleteSnapshotResponse{
+		staticErr: errors.Extend(err, ErrJobDiscarded),
+	}
+	w := j.staticQueue.staticWorker()
+	errLaunch := w.renter.tg.Launch(func() {
+		select {
+		case j.staticResponseChan <- resp:
+		case <-j.staticCtx.Done():
+		case <-w.renter.tg.StopChan():
+		}
+	})
+	if errLaunch != nil {
+		w.renter.log.Print("callDiscard: launch failed", err)
+	}
+}
+
+// callExecute will perform a delete snapshot job for the worker.
+func (j *jobDeleteSnapshot) callExecute() {
+	w := j.staticQueue.staticWorker()
+
+	// Defer a function to send the result down a channel.
+	var err error
+	defer func() {
+		// Return the error to the caller, error may be nil.
+		resp := &jobDeleteSnapshotResponse{
+			staticErr: err,
+		}
+		errLaunch := w.renter.tg.Launch(func() {
+			select {
+			case j.staticResponseChan <- resp:
+			case <-j.staticCtx.Done():
+			case <-w.renter.tg.StopChan():
+			}
+		})
+		if errLaunch != nil {
+			w.renter.log.Print("callExecute: launch failed", err)
+		}
+
+		// Report a failure to the queue if this job had an error.
+		if err != nil {
+			j.staticQueue.callReportFailure(err)
+		} else {
+			j.staticQueue.callReportSuccess()
+		}
+	}()
+
+	// Check that the worker is good for upload.
+	if !w.staticCache().staticContractUtility.GoodForUpload {
+		err = errors.New("snapshots were not deleted because the worker is not good for upload")
+		return
+	}
+
+	var sess contractor.Session
+	sess, err = w.renter.hostContractor.Session(w.staticHostPubKey, w.renter.tg.StopChan())
+	if err != nil {
+		w.renter.log.Debugln("unable to grab a session to perform a delete snapshot job:", err)
+		err = errors.AddContext(err, "unable to get host session")
+		return
+	}
+	defer func() {
+		closeErr := sess.Close()
+		if closeErr != nil {
+			w.renter.log.Println("error while closing session:", closeErr)
+		}
+		err = errors.Compose(err, closeErr)
+	}()
+
+	// Removing snapshots uploads a new snapshot table.
+	allowance := w.renter.hostContractor.Allowance()
+	hostSettings := sess.HostSettings()
+	err = checkUploadSnapshotGouging(allowance, hostSettings)
+	if err != nil {
+		err = errors.AddContext(err, "snapshot deletion blocked because potential price gouging was detected")
+		return
+	}
+
+	// Remove the snapshots from the host's snapshot table.
+	err = w.renter.managedDeleteSnapshotsHost(j.staticUIDs, sess, w)
+	if err != nil {
+		w.renter.log.Debugln("deleting snapshots from a host failed:", err)
+		err = errors.AddContext(err, "deleting snapshots from a host failed")
+		return
+	}
+}
+
+// callExpectedBandwidth returns the amount of bandwidth this job is expected to
+// consume.
+func (j *jobDeleteSnapshot) callExpectedBandwidth() (ul, dl uint64) {
+	// Estimate 50kb in overhead for upload and download, and then 4 MiB
+	// necessary to download the table and another 4 MiB to upload the new one.
+	return 50e3 + 1<<22, 50e3 + 1<<22
+}
+
+// initJobDeleteSnapshotQueue will initialize the delete snapshot job queue for
+// the worker.
+func (w *worker) initJobDeleteSnapshotQueue() {
+	if w.staticJobDeleteSnapshotQueue != nil {
+		w.renter.log.Critical("should not be double initializng the delete snapshot queue")
+		return
+	}
+
+	w.staticJobDeleteSnapshotQueue = &jobDeleteSnapshotQueue{
+		jobGenericQueue: newJobGenericQueue(w),
+	}
+}
+
+// managedDeleteSnapshotsHost removes the snapshots with the given UIDs from the
+// snapshot table of a single host.
+func (r *Renter) managedDeleteSnapshotsHost(uids [][16]byte, host contractor.Session, w *worker) error {
+	// download the snapshot table
+	entryTable, err := r.managedDownloadSnapshotTable(w)
+	if errors.Contains(err, errEmptyContract) {
+		return nil // host doesn't store a table
+	}
+	if err != nil {
+		return errors.AddContext(err, "could not download the snapshot table")
+	}
+
+	// remove the entries from the table.
+	deleted := make(map[[16]byte]struct{}, len(uids))
+	for _, uid := range uids {
+		deleted[uid] = struct{}{}
+	}
+	newTable := filterDeletedSnapshots(entryTable, deleted)
+	if len(newTable) == len(entryTable) {
+		return nil // nothing to delete
+	}
+	return r.managedUploadSnapshotTable(newTable, true, host)
+}
+
+// DeleteSnapshots is a helper method to run a DeleteSnapshot job on a worker
+// which removes the snapshots with the given UIDs from the host's snapshot
+// table.
+func (w *worker) DeleteSnapshots(ctx context.Context, uids [][16]byte) error {
+	respChan := make(chan *jobDeleteSnapshotResponse)
+	jds := &jobDeleteSnapshot{
+		staticUIDs:         uids,
+		staticResponseChan: respChan,
+
+		jobGeneric: newJobGeneric(ctx, w.staticJobDeleteSnapshotQueue, nil),
+	}
+
+	// Add the job to the queue.
+	if !w.staticJobDeleteSnapshotQueue.callAdd(jds) {
+		return errors.New("worker unavailable")
+	}
+
+	// Wait for the response.
+	var resp *jobDeleteSnapshotResponse
+	select {
+	case <-ctx.Done():
+		return errors.New("DeleteSnapshots interrupted")
+	case resp = <-respChan:
+	}
+	return resp.staticErr
+}
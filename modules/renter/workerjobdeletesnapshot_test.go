@@ -0,0 +1,69 @@
+package renter
+
+import (
+	"context"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestJobDeleteSnapshot tests removing snapshots from a host's snapshot table
+// with a delete snapshot job.
+func TestJobDeleteSnapshot(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Upload two snapshots.
+	var uids [][16]byte
+	for i := 0; i < 2; i++ {
+		backup := modules.UploadedBackup{
+			Name:         "foo",
+			CreationDate: types.CurrentTimestamp(),
+			Size:         10,
+		}
+		fastrand.Read(backup.UID[:])
+		err = wt.UploadSnapshot(context.Background(), backup, fastrand.Bytes(int(backup.Size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		uids = append(uids, backup.UID)
+	}
+
+	// Delete the first one and an unknown one.
+	err = wt.DeleteSnapshots(context.Background(), [][16]byte{uids[0], {1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := wt.staticJobDeleteSnapshotQueue.callStatus().size; size != 0 {
+		t.Fatal("queue should be empty", size)
+	}
+
+	// Only the second snapshot should remain.
+	table, err := wt.DownloadSnapshotTable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table) != 1 || table[0].UID != uids[1] {
+		t.Fatal("wrong snapshot table", table)
+	}
+
+	// Deleting snapshots that don't exist is a no-op.
+	err = wt.DeleteSnapshots(context.Background(), [][16]byte{uids[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -28,6 +28,11 @@ type (
 		staticLength       uint64
 		staticResponseChan chan *jobReadResponse
 
+		// staticPartialResponseChan is an optional channel which receives the
+		// data that was read so far while the host is streaming it. Partial
+		// responses are dropped if the channel is full.
+		staticPartialResponseChan chan *jobReadPartialResponse
+
 		*jobGeneric
 	}
 
@@ -58,6 +63,13 @@ type (
 		staticJobTime time.Duration
 	}
 
+	// jobReadPartialResponse contains the data a Read query received and
+	// verified so far. The data is not decrypted and must not be modified.
+	jobReadPartialResponse struct {
+		staticData     []byte
+		staticMetadata jobReadMetadata
+	}
+
 	// jobReadMetadata contains meta information about a read job.
 	jobReadMetadata struct {
 		staticSectorRoot          crypto.Hash
@@ -72,6 +84,12 @@ type (
 
 		staticWorker *worker
 	}
+
+	// streamVerifyFn verifies the output data a host is streaming for the
+	// last instruction of a read program using the stream roots the host sent
+	// before the data. It returns the length of the verified prefix of the
+	// data.
+	streamVerifyFn func(roots modules.RPCExecuteProgramStreamRoots, data []byte) uint64
 )
 
 // staticJobReadMetadata returns the read job's metadata.
@@ -155,10 +173,31 @@ func (j *jobRead) callExpectedBandwidth() (ul, dl uint64) {
 }
 
 // managedRead returns the sector data for the given read program and the merkle
-// proof.
-func (j *jobRead) managedRead(w *worker, program modules.Program, programData []byte, cost types.Currency) ([]programResponse, error) {
-	// execute it
-	responses, _, err := w.managedExecuteProgram(program, programData, w.staticCache().staticContractID, j.staticJobReadMetadata().staticSpendingCategory, cost)
+// proof. If verifyFn is set, the data that was verified while the host is
+// streaming it is sent to the job's partial response channel.
+func (j *jobRead) managedRead(w *worker, program modules.Program, programData []byte, cost types.Currency, verifyFn streamVerifyFn) ([]programResponse, error) {
+	// execute it, hosts that support it stream the data to us as it is read
+	// from disk.
+	var responses []programResponse
+	var err error
+	metadata := j.staticJobReadMetadata()
+	cache := w.staticCache()
+	if build.VersionCmp(cache.staticHostVersion, minStreamProgramVersion) >= 0 {
+		var verified uint64
+		progressFn := func(instruction int, roots modules.RPCExecuteProgramStreamRoots, data []byte) {
+			// Only the last instruction is the actual download.
+			if instruction != len(program)-1 || verifyFn == nil {
+				return
+			}
+			if n := verifyFn(roots, data); n > verified {
+				verified = n
+				j.sendPartialResponse(data[:n], metadata)
+			}
+		}
+		responses, _, err = w.managedExecuteProgramStream(program, programData, cache.staticContractID, metadata.staticSpendingCategory, cost, progressFn)
+	} else {
+		responses, _, err = w.managedExecuteProgram(program, programData, cache.staticContractID, metadata.staticSpendingCategory, cost)
+	}
 	if err != nil {
 		return []programResponse{}, err
 	}
@@ -191,6 +230,22 @@ func (j *jobRead) managedRead(w *worker, program modules.Program, programData []
 	return responses, nil
 }
 
+// sendPartialResponse sends the data that was read so far to the partial
+// response channel of the job. Since partial responses are only an
+// optimization, they are dropped instead of blocking the worker.
+func (j *jobRead) sendPartialResponse(data []byte, metadata jobReadMetadata) {
+	if j.staticPartialResponseChan == nil {
+		return
+	}
+	select {
+	case j.staticPartialResponseChan <- &jobReadPartialResponse{
+		staticData:     data,
+		staticMetadata: metadata,
+	}:
+	default:
+	}
+}
+
 // callAddWithEstimate will add a job to the job read queue while providing an
 // estimate for when the job is expected to return.
 func (jq *jobReadQueue) callAddWithEstimate(j *jobReadSector) (time.Time, bool) {
@@ -1,9 +1,12 @@
 package renter
 
 import (
+	"bytes"
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
@@ -132,3 +135,106 @@ func TestJobReadMetadata(t *testing.T) {
 		t.Fatal("unexpected")
 	}
 }
+
+// TestJobReadSectorStream verifies that read sector jobs send the verified
+// data streamed by the host as partial responses and that they fall back to
+// the regular ExecuteProgram RPC for hosts that don't support streaming.
+func TestJobReadSectorStream(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	w := wt.worker
+
+	// allow the worker some time to fetch a PT and fund its EA
+	err = build.Retry(600, 100*time.Millisecond, func() error {
+		if w.staticAccount.managedMinExpectedBalance().IsZero() {
+			return errors.New("account not funded yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add sector data to the host
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	err = wt.host.AddSector(sectorRoot, sectorData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// readSector reads most of the sector and returns the partial responses.
+	offset := uint64(crypto.SegmentSize)
+	length := modules.SectorSize - 2*crypto.SegmentSize
+	readSector := func() [][]byte {
+		responseChan := make(chan *jobReadResponse, 1)
+		partialResponseChan := make(chan *jobReadPartialResponse, modules.SectorSize/crypto.SegmentSize)
+		jrs := w.newJobReadSector(context.Background(), w.staticJobReadQueue, responseChan, categoryDownload, sectorRoot, offset, length)
+		jrs.staticPartialResponseChan = partialResponseChan
+		if !w.staticJobReadQueue.callAdd(jrs) {
+			t.Fatal("Could not add job to queue")
+		}
+		jrr := <-responseChan
+		if jrr.staticErr != nil {
+			t.Fatal(jrr.staticErr)
+		}
+		if !bytes.Equal(jrr.staticData, sectorData[offset:offset+length]) {
+			t.Fatal("wrong data")
+		}
+		close(partialResponseChan)
+		var partials [][]byte
+		for jrpr := range partialResponseChan {
+			partials = append(partials, jrpr.staticData)
+		}
+		return partials
+	}
+
+	// the host doesn't know the chunk roots of the sector the first time it
+	// is read, so the data can only be verified once it was fully received.
+	if partials := readSector(); len(partials) != 0 {
+		t.Fatal("unexpected partial responses", len(partials))
+	}
+
+	// afterwards the host streams the data, every part of it should be sent
+	// as soon as it was verified.
+	partials := readSector()
+	numUpdates := int((length + programOutputStreamChunkSize - 1) / programOutputStreamChunkSize)
+	if len(partials) != numUpdates {
+		t.Fatal("unexpected number of partial responses", len(partials))
+	}
+	if uint64(len(partials[len(partials)-1])) != length {
+		t.Fatal("last partial response should contain all of the data")
+	}
+	for i, partial := range partials {
+		if i > 0 && len(partial) <= len(partials[i-1]) {
+			t.Fatal("partial responses should grow")
+		}
+		if !bytes.Equal(partial, sectorData[offset:offset+uint64(len(partial))]) {
+			t.Fatal("partial response contains wrong data")
+		}
+	}
+
+	// pretend that the host doesn't support streaming. Block cache updates to
+	// keep the version from being overwritten.
+	atomic.StoreUint64(&w.atomicCacheUpdating, 1)
+	wc := *w.staticCache()
+	wc.staticHostVersion = "1.5.6"
+	atomic.StorePointer(&w.atomicCache, unsafe.Pointer(&wc))
+
+	// the data should be read without partial responses.
+	if partials := readSector(); len(partials) != 0 {
+		t.Fatal("unexpected partial responses", len(partials))
+	}
+}
@@ -47,7 +47,7 @@ func (j *jobReadOffset) managedReadOffset() ([]byte, error) {
 	cost = cost.Add(bandwidthCost)
 
 	// Read responses.
-	responses, err := j.jobRead.managedRead(w, program, programData, cost)
+	responses, err := j.jobRead.managedRead(w, program, programData, cost, nil)
 	if err != nil {
 		return nil, errors.AddContext(err, "jobReadOffset: failed to execute managedRead")
 	}
@@ -0,0 +1,335 @@
+package renter
+
+import (
+	"context"
+	"time"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+const (
+	// jobReadRegistryBatchPerformanceDecay defines how much the average
+	// performance is decayed each time a new datapoint is added. The jobs use
+	// an exponential weighted average.
+	jobReadRegistryBatchPerformanceDecay = 0.9
+)
+
+type (
+	// jobReadRegistryBatch contains information about a ReadRegistryBatch
+	// query.
+	jobReadRegistryBatch struct {
+		staticRequests []modules.RegistryReadRequest
+
+		staticResponseChan chan *jobReadRegistryBatchResponse // Channel to send a response down
+
+		*jobGeneric
+	}
+
+	// jobReadRegistryBatchQueue is a list of ReadRegistryBatch jobs that have
+	// been assigned to the worker.
+	jobReadRegistryBatchQueue struct {
+		// These variables contain an exponential weighted average of the
+		// worker's recent performance for jobReadRegistryBatchQueue.
+		weightedJobTime float64
+
+		*jobGenericQueue
+	}
+
+	// jobReadRegistryBatchResponse contains the result of a ReadRegistryBatch
+	// query. The values are in the same order as the requests and a value is
+	// 'nil' if the host didn't find the entry.
+	jobReadRegistryBatchResponse struct {
+		staticSignedRegistryValues []*modules.SignedRegistryValue
+		staticErr                  error
+		staticCompleteTime         time.Time
+	}
+)
+
+// lookupRegistryBatch looks up multiple registry entries on the host and
+// verifies their signatures.
+func lookupRegistryBatch(w *worker, reqs []modules.RegistryReadRequest) ([]*modules.SignedRegistryValue, error) {
+	// Create the program.
+	sids := make([]modules.RegistryEntryID, 0, len(reqs))
+	for _, req := range reqs {
+		sids = append(sids, modules.DeriveRegistryEntryID(req.PubKey, req.Tweak))
+	}
+	pt := w.staticPriceTable().staticPriceTable
+	pb := modules.NewProgramBuilder(&pt, 0) // 0 duration since ReadRegistryBatch doesn't depend on it.
+	refund, err := pb.AddReadRegistryBatchInstruction(sids, false)
+	if err != nil {
+		return nil, errors.AddContext(err, "Unable to add read registry batch instruction")
+	}
+	program, programData := pb.Program()
+	cost, _, _ := pb.Cost(true)
+
+	// take into account bandwidth costs
+	ulBandwidth, dlBandwidth := readRegistryBatchJobExpectedBandwidth(len(reqs))
+	bandwidthCost := modules.MDMBandwidthCost(pt, ulBandwidth, dlBandwidth)
+	cost = cost.Add(bandwidthCost)
+
+	// Execute the program and parse the responses.
+	responses, _, err := w.managedExecuteProgram(program, programData, types.FileContractID{}, categoryRegistryRead, cost)
+	if err != nil {
+		return nil, errors.AddContext(err, "Unable to execute program")
+	}
+	for _, resp := range responses {
+		if resp.Error != nil {
+			return nil, errors.AddContext(resp.Error, "Output error")
+		}
+		break
+	}
+	if len(responses) != len(program) {
+		return nil, errors.New("received invalid number of responses but no error")
+	}
+
+	// Decode the values.
+	var values [][]byte
+	err = encoding.Unmarshal(responses[0].Output, &values)
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to decode batch response")
+	}
+	if len(values) != len(reqs) {
+		return nil, errors.New("host returned wrong number of entries")
+	}
+
+	// Parse and verify the values.
+	srvs := make([]*modules.SignedRegistryValue, len(reqs))
+	var numNotFound uint64
+	for i, value := range values {
+		// If the entry wasn't found, we are issued a refund.
+		if len(value) == 0 {
+			numNotFound++
+			continue
+		}
+		rv, err := parseSignedRegistryValueResponse(value, reqs[i].Tweak)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to parse signed revision response")
+		}
+		if rv.Verify(reqs[i].PubKey.ToPublicKey()) != nil {
+			return nil, errors.New("failed to verify returned registry value's signature")
+		}
+		srvs[i] = &rv
+	}
+	if numNotFound > 0 {
+		w.staticAccount.managedTrackDeposit(refund.Mul64(numNotFound))
+		w.staticAccount.managedCommitDeposit(refund.Mul64(numNotFound), true)
+	}
+	return srvs, nil
+}
+
+// newJobReadRegistryBatch is a helper method to create a new
+// ReadRegistryBatch job.
+func (w *worker) newJobReadRegistryBatch(ctx context.Context, responseChan chan *jobReadRegistryBatchResponse, reqs []modules.RegistryReadRequest) *jobReadRegistryBatch {
+	return &jobReadRegistryBatch{
+		staticRequests:     reqs,
+		staticResponseChan: responseChan,
+		jobGeneric:         newJobGeneric(ctx, w.staticJobReadRegistryBatchQueue, nil),
+	}
+}
+
+// callAddReadRegistryBatch adds a ReadRegistryBatch job for the requests to
+// the worker's queue. Hosts that don't support batched registry lookups get a
+// ReadRegistry job for every request instead and the responses are combined
+// into a single batch response. False is returned if the worker can't
+// participate.
+func (w *worker) callAddReadRegistryBatch(ctx context.Context, responseChan chan *jobReadRegistryBatchResponse, reqs []modules.RegistryReadRequest) bool {
+	if build.VersionCmp(w.staticCache().staticHostVersion, minRegistryBatchVersion) >= 0 {
+		return w.staticJobReadRegistryBatchQueue.callAdd(w.newJobReadRegistryBatch(ctx, responseChan, reqs))
+	}
+
+	// Add a job for every request. Every job gets its own buffered channel to
+	// match the responses to the requests without blocking the jobs.
+	entryChans := make([]chan *jobReadRegistryResponse, 0, len(reqs))
+	for _, req := range reqs {
+		entryChan := make(chan *jobReadRegistryResponse, 1)
+		if !w.staticJobReadRegistryQueue.callAdd(w.newJobReadRegistry(ctx, entryChan, req.PubKey, req.Tweak)) {
+			break
+		}
+		entryChans = append(entryChans, entryChan)
+	}
+	if len(entryChans) == 0 {
+		return false
+	}
+
+	// Combine the responses into a batch response. Like a batch job, the
+	// response fails if any of the lookups failed.
+	err := w.renter.tg.Launch(func() {
+		response := &jobReadRegistryBatchResponse{
+			staticSignedRegistryValues: make([]*modules.SignedRegistryValue, len(reqs)),
+		}
+		if len(entryChans) < len(reqs) {
+			response.staticErr = errors.New("worker unavailable")
+		}
+		for i, entryChan := range entryChans {
+			var resp *jobReadRegistryResponse
+			select {
+			case resp = <-entryChan:
+			case <-ctx.Done():
+				return
+			case <-w.renter.tg.StopChan():
+				return
+			}
+			response.staticSignedRegistryValues[i] = resp.staticSignedRegistryValue
+			response.staticErr = errors.Compose(response.staticErr, resp.staticErr)
+		}
+		if response.staticErr != nil {
+			response.staticSignedRegistryValues = nil
+		}
+		response.staticCompleteTime = time.Now()
+		select {
+		case responseChan <- response:
+		case <-ctx.Done():
+		case <-w.renter.tg.StopChan():
+		}
+	})
+	return err == nil
+}
+
+// callDiscard will discard a job, sending the provided error.
+func (j *jobReadRegistryBatch) callDiscard(err error) {
+	w := j.staticQueue.staticWorker()
+	errLaunch := w.renter.tg.Launch(func() {
+		response := &jobReadRegistryBatchResponse{
+			staticErr:          errors.Extend(err, ErrJobDiscarded),
+			staticCompleteTime: time.Now(),
+		}
+		select {
+		case j.staticResponseChan <- response:
+		case <-j.staticCtx.Done():
+		case <-w.renter.tg.StopChan():
+		}
+	})
+	if errLaunch != nil {
+		w.renter.log.Debugln("callDiscard: launch failed", err)
+	}
+}
+
+// callExecute will run the ReadRegistryBatch job.
+func (j *jobReadRegistryBatch) callExecute() {
+	start := time.Now()
+	w := j.staticQueue.staticWorker()
+
+	// Prepare a method to send a response asynchronously.
+	sendResponse := func(srvs []*modules.SignedRegistryValue, err error) {
+		errLaunch := w.renter.tg.Launch(func() {
+			response := &jobReadRegistryBatchResponse{
+				staticCompleteTime:         time.Now(),
+				staticSignedRegistryValues: srvs,
+				staticErr:                  err,
+			}
+			select {
+			case j.staticResponseChan <- response:
+			case <-j.staticCtx.Done():
+			case <-w.renter.tg.StopChan():
+			}
+		})
+		if errLaunch != nil {
+			w.renter.log.Debugln("callExececute: launch failed", err)
+		}
+	}
+
+	// Read the values.
+	srvs, err := lookupRegistryBatch(w, j.staticRequests)
+	if err != nil {
+		sendResponse(nil, err)
+		j.staticQueue.callReportFailure(err)
+		return
+	}
+
+	// Compare the looked up entries to the cache the same way a single
+	// ReadRegistry job does. If the host returned a lower revision than we
+	// have seen before for any of the entries, the whole job fails.
+	var lowerRevision bool
+	for i, srv := range srvs {
+		if srv == nil {
+			continue
+		}
+		spk := j.staticRequests[i].PubKey
+		cachedRevision, cached := w.staticRegistryCache.Get(spk, srv.Tweak)
+		if cached && cachedRevision > srv.Revision {
+			lowerRevision = true
+			w.staticRegistryCache.Set(spk, *srv, true) // adjust the cache
+		} else if !cached || srv.Revision > cachedRevision {
+			w.staticRegistryCache.Set(spk, *srv, false) // adjust the cache
+		}
+	}
+	if lowerRevision {
+		sendResponse(nil, errHostLowerRevisionThanCache)
+		j.staticQueue.callReportFailure(errHostLowerRevisionThanCache)
+		return
+	}
+
+	// Success.
+	jobTime := time.Since(start)
+
+	// Send the response and report success.
+	sendResponse(srvs, nil)
+	j.staticQueue.callReportSuccess()
+
+	// Update the performance stats on the queue.
+	jq := j.staticQueue.(*jobReadRegistryBatchQueue)
+	jq.mu.Lock()
+	jq.weightedJobTime = expMovingAvg(jq.weightedJobTime, float64(jobTime), jobReadRegistryBatchPerformanceDecay)
+	jq.mu.Unlock()
+}
+
+// callExpectedBandwidth returns the bandwidth that is expected to be consumed
+// by the job.
+func (j *jobReadRegistryBatch) callExpectedBandwidth() (ul, dl uint64) {
+	return readRegistryBatchJobExpectedBandwidth(len(j.staticRequests))
+}
+
+// initJobReadRegistryBatchQueue will init the queue for the ReadRegistryBatch
+// jobs.
+func (w *worker) initJobReadRegistryBatchQueue() {
+	// Sanity check that there is no existing job queue.
+	if w.staticJobReadRegistryBatchQueue != nil {
+		w.renter.log.Critical("incorret call on initJobReadRegistryBatchQueue")
+		return
+	}
+
+	w.staticJobReadRegistryBatchQueue = &jobReadRegistryBatchQueue{
+		jobGenericQueue: newJobGenericQueue(w),
+	}
+}
+
+// ReadRegistryBatch is a helper method to run a ReadRegistryBatch job on a
+// worker.
+func (w *worker) ReadRegistryBatch(ctx context.Context, reqs []modules.RegistryReadRequest) ([]*modules.SignedRegistryValue, error) {
+	readRegistryBatchRespChan := make(chan *jobReadRegistryBatchResponse)
+	jrrb := w.newJobReadRegistryBatch(ctx, readRegistryBatchRespChan, reqs)
+
+	// Add the job to the queue.
+	if !w.staticJobReadRegistryBatchQueue.callAdd(jrrb) {
+		return nil, errors.New("worker unavailable")
+	}
+
+	// Wait for the response.
+	var resp *jobReadRegistryBatchResponse
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("ReadRegistryBatch interrupted")
+	case resp = <-readRegistryBatchRespChan:
+	}
+
+	// Sanity check that the finish time was set.
+	if resp.staticCompleteTime.IsZero() {
+		build.Critical("finish time wasn't set")
+	}
+	return resp.staticSignedRegistryValues, resp.staticErr
+}
+
+// readRegistryBatchJobExpectedBandwidth is a helper function that returns the
+// expected bandwidth consumption of a ReadRegistryBatch job for numEntries
+// entries.
+func readRegistryBatchJobExpectedBandwidth(numEntries int) (ul, dl uint64) {
+	ul = ethernetMTU + uint64(numEntries)*crypto.HashSize
+	dl = ethernetMTU + uint64(numEntries)*modules.RegistryEntrySize
+	return
+}
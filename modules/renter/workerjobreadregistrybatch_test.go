@@ -0,0 +1,177 @@
+package renter
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/registry"
+	"go.sia.tech/siad/types"
+)
+
+// TestReadRegistryBatchJob tests running a ReadRegistryBatch job on a host.
+func TestReadRegistryBatchJob(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create two registry values and upload them.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.SiaPublicKey{
+		Algorithm: types.SignatureEd25519,
+		Key:       pk[:],
+	}
+	var rvs []modules.SignedRegistryValue
+	for i := 0; i < 2; i++ {
+		var tweak crypto.Hash
+		fastrand.Read(tweak[:])
+		data := fastrand.Bytes(modules.RegistryDataSize)
+		rev := fastrand.Uint64n(1000)
+		rv := modules.NewRegistryValue(tweak, data, rev).Sign(sk)
+		err = wt.UpdateRegistry(context.Background(), spk, rv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rvs = append(rvs, rv)
+	}
+
+	// Read both entries and one that doesn't exist.
+	var unknownTweak crypto.Hash
+	fastrand.Read(unknownTweak[:])
+	reqs := []modules.RegistryReadRequest{
+		{PubKey: spk, Tweak: rvs[0].Tweak},
+		{PubKey: spk, Tweak: unknownTweak},
+		{PubKey: spk, Tweak: rvs[1].Tweak},
+	}
+	srvs, err := wt.ReadRegistryBatch(context.Background(), reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(srvs) != len(reqs) {
+		t.Fatalf("expected %v values but got %v", len(reqs), len(srvs))
+	}
+
+	// The entries should match.
+	if srvs[0] == nil || !reflect.DeepEqual(*srvs[0], rvs[0]) {
+		t.Fatal("entries don't match", srvs[0], rvs[0])
+	}
+	if srvs[1] != nil {
+		t.Fatal("unknown entry shouldn't be found", srvs[1])
+	}
+	if srvs[2] == nil || !reflect.DeepEqual(*srvs[2], rvs[1]) {
+		t.Fatal("entries don't match", srvs[2], rvs[1])
+	}
+}
+
+// TestRegistryBatchFallback tests that hosts which don't support batched
+// registry instructions have their entries read and updated one by one.
+func TestRegistryBatchFallback(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Pretend that the host doesn't support batched registry instructions.
+	// Block cache updates to keep the version from being overwritten.
+	atomic.StoreUint64(&wt.atomicCacheUpdating, 1)
+	wc := *wt.staticCache()
+	wc.staticHostVersion = "1.5.6"
+	atomic.StorePointer(&wt.atomicCache, unsafe.Pointer(&wc))
+
+	// Update two entries and one with an invalid revision.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.SiaPublicKey{
+		Algorithm: types.SignatureEd25519,
+		Key:       pk[:],
+	}
+	var updates []modules.RegistryBatchUpdate
+	for i := 0; i < 2; i++ {
+		var tweak crypto.Hash
+		fastrand.Read(tweak[:])
+		rv := modules.NewRegistryValue(tweak, fastrand.Bytes(modules.RegistryDataSize), 1).Sign(sk)
+		updates = append(updates, modules.RegistryBatchUpdate{PubKey: spk, Value: rv})
+	}
+	err = wt.UpdateRegistry(context.Background(), spk, updates[1].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateChan := make(chan *jobUpdateRegistryBatchResponse, 1)
+	if !wt.callAddUpdateRegistryBatch(context.Background(), updateChan, updates) {
+		t.Fatal("failed to add update jobs")
+	}
+	updateResp := <-updateChan
+	if updateResp.staticErr != nil {
+		t.Fatal(updateResp.staticErr)
+	}
+	if updateResp.staticErrs[0] != nil {
+		t.Fatal(updateResp.staticErrs[0])
+	}
+	if !errors.Contains(updateResp.staticErrs[1], registry.ErrSameRevNum) || updateResp.srvs[1] == nil {
+		t.Fatal("expected ErrSameRevNum with proof", updateResp.staticErrs[1], updateResp.srvs[1])
+	}
+
+	// Read both entries and one that doesn't exist.
+	var unknownTweak crypto.Hash
+	fastrand.Read(unknownTweak[:])
+	reqs := []modules.RegistryReadRequest{
+		{PubKey: spk, Tweak: updates[0].Value.Tweak},
+		{PubKey: spk, Tweak: unknownTweak},
+		{PubKey: spk, Tweak: updates[1].Value.Tweak},
+	}
+	readChan := make(chan *jobReadRegistryBatchResponse, 1)
+	if !wt.callAddReadRegistryBatch(context.Background(), readChan, reqs) {
+		t.Fatal("failed to add read jobs")
+	}
+	readResp := <-readChan
+	if readResp.staticErr != nil {
+		t.Fatal(readResp.staticErr)
+	}
+	srvs := readResp.staticSignedRegistryValues
+	if srvs[0] == nil || !reflect.DeepEqual(*srvs[0], updates[0].Value) {
+		t.Fatal("entries don't match", srvs[0], updates[0].Value)
+	}
+	if srvs[1] != nil {
+		t.Fatal("unknown entry shouldn't be found", srvs[1])
+	}
+	if srvs[2] == nil || !reflect.DeepEqual(*srvs[2], updates[1].Value) {
+		t.Fatal("entries don't match", srvs[2], updates[1].Value)
+	}
+
+	// No batch jobs should have been executed.
+	wt.staticJobReadRegistryBatchQueue.mu.Lock()
+	readTime := wt.staticJobReadRegistryBatchQueue.weightedJobTime
+	wt.staticJobReadRegistryBatchQueue.mu.Unlock()
+	wt.staticJobUpdateRegistryBatchQueue.mu.Lock()
+	updateTime := wt.staticJobUpdateRegistryBatchQueue.weightedJobTime
+	wt.staticJobUpdateRegistryBatchQueue.mu.Unlock()
+	if readTime != 0 || updateTime != 0 {
+		t.Fatal("batch jobs were executed", readTime, updateTime)
+	}
+}
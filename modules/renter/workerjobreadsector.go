@@ -2,6 +2,7 @@ package renter
 
 import (
 	"context"
+	"math/bits"
 	"time"
 
 	"gitlab.com/NebulousLabs/errors"
@@ -17,8 +18,27 @@ type (
 		staticOffset uint64
 		staticSector crypto.Hash
 	}
+
+	// sectorStreamVerifier verifies the data of a sector range while a host
+	// is streaming it. The host sends the roots of the sector's chunks before
+	// the data, which allows for verifying the data one chunk at a time.
+	sectorStreamVerifier struct {
+		staticRoot   crypto.Hash
+		staticOffset uint64
+		staticLength uint64
+
+		// verified is the length of the verified prefix of the data. Once
+		// the verification fails, the data is left to the final proof.
+		verified      uint64
+		rootsVerified bool
+		failed        bool
+	}
 )
 
+// streamChunkHeight is the height of the subtree of a sector's Merkle tree
+// that covers a single stream chunk.
+var streamChunkHeight = uint64(bits.TrailingZeros64(modules.MDMStreamChunkSize / crypto.SegmentSize))
+
 // callExecute executes the jobReadSector.
 func (j *jobReadSector) callExecute() {
 	// Track how long the job takes.
@@ -45,7 +65,8 @@ func (j *jobReadSector) managedReadSector() ([]byte, error) {
 	bandwidthCost := modules.MDMBandwidthCost(pt, ulBandwidth, dlBandwidth)
 	cost = cost.Add(bandwidthCost)
 
-	responses, err := j.jobRead.managedRead(w, program, programData, cost)
+	v := newSectorStreamVerifier(j.staticSector, j.staticOffset, j.staticLength)
+	responses, err := j.jobRead.managedRead(w, program, programData, cost, v.verify)
 	if err != nil {
 		return nil, errors.AddContext(err, "jobReadSector: failed to execute managedRead")
 	}
@@ -61,6 +82,74 @@ func (j *jobReadSector) managedReadSector() ([]byte, error) {
 	return data, nil
 }
 
+// newSectorStreamVerifier creates a verifier for the range of the sector with
+// the given root.
+func newSectorStreamVerifier(root crypto.Hash, offset, length uint64) *sectorStreamVerifier {
+	return &sectorStreamVerifier{
+		staticRoot:   root,
+		staticOffset: offset,
+		staticLength: length,
+	}
+}
+
+// verify verifies the chunks of data that were fully received since the
+// last call and returns the length of the verified prefix of data.
+func (v *sectorStreamVerifier) verify(roots modules.RPCExecuteProgramStreamRoots, data []byte) uint64 {
+	if v.failed {
+		return v.verified
+	}
+	if !v.rootsVerified {
+		v.rootsVerified = v.verifyRoots(roots)
+		v.failed = !v.rootsVerified
+		if v.failed {
+			return v.verified
+		}
+	}
+
+	firstChunk := v.staticOffset / modules.MDMStreamChunkSize
+	end := v.staticOffset + v.staticLength
+	for v.verified < uint64(len(data)) {
+		from := v.staticOffset + v.verified
+		chunk := from / modules.MDMStreamChunkSize
+		chunkStart := chunk * modules.MDMStreamChunkSize
+		to := chunkStart + modules.MDMStreamChunkSize
+		if to > end {
+			to = end
+		}
+		// Wait for the rest of the chunk.
+		if to-v.staticOffset > uint64(len(data)) {
+			break
+		}
+		proofStart := int(from-chunkStart) / crypto.SegmentSize
+		proofEnd := int(to-chunkStart) / crypto.SegmentSize
+		segments := data[from-v.staticOffset : to-v.staticOffset]
+		if !crypto.VerifyRangeProof(segments, roots.Proofs[chunk-firstChunk], proofStart, proofEnd, roots.Roots[chunk]) {
+			v.failed = true
+			break
+		}
+		v.verified = to - v.staticOffset
+	}
+	return v.verified
+}
+
+// verifyRoots checks that the chunk roots add up to the sector root and that
+// there is a proof for every chunk the range overlaps.
+func (v *sectorStreamVerifier) verifyRoots(roots modules.RPCExecuteProgramStreamRoots) bool {
+	if v.staticLength == 0 || len(roots.Roots) != int(modules.SectorSize/modules.MDMStreamChunkSize) {
+		return false
+	}
+	firstChunk := v.staticOffset / modules.MDMStreamChunkSize
+	lastChunk := (v.staticOffset + v.staticLength - 1) / modules.MDMStreamChunkSize
+	if uint64(len(roots.Proofs)) != lastChunk-firstChunk+1 {
+		return false
+	}
+	tree := crypto.NewCachedTree(streamChunkHeight)
+	for _, root := range roots.Roots {
+		tree.Push(root)
+	}
+	return tree.Root() == v.staticRoot
+}
+
 // newJobReadSector creates a new read sector job.
 func (w *worker) newJobReadSector(ctx context.Context, queue *jobReadQueue, respChan chan *jobReadResponse, category spendingCategory, root crypto.Hash, offset, length uint64) *jobReadSector {
 	return &jobReadSector{
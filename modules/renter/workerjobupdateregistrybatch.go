@@ -0,0 +1,350 @@
+package renter
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/registry"
+	"go.sia.tech/siad/types"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+const (
+	// jobUpdateRegistryBatchPerformanceDecay defines how much the average
+	// performance is decayed each time a new datapoint is added. The jobs use
+	// an exponential weighted average.
+	jobUpdateRegistryBatchPerformanceDecay = 0.9
+)
+
+type (
+	// jobUpdateRegistryBatch contains information about an
+	// UpdateRegistryBatch query.
+	jobUpdateRegistryBatch struct {
+		staticUpdates []modules.RegistryBatchUpdate
+
+		staticResponseChan chan *jobUpdateRegistryBatchResponse // Channel to send a response down
+
+		*jobGeneric
+	}
+
+	// jobUpdateRegistryBatchQueue is a list of UpdateRegistryBatch jobs that
+	// have been assigned to the worker.
+	jobUpdateRegistryBatchQueue struct {
+		// These variables contain an exponential weighted average of the
+		// worker's recent performance for jobUpdateRegistryBatchQueue.
+		weightedJobTime float64
+
+		*jobGenericQueue
+	}
+
+	// jobUpdateRegistryBatchResponse contains the result of an
+	// UpdateRegistryBatch query. If staticErr is 'nil', srvs and staticErrs
+	// contain one element per update in the same order as the updates.
+	jobUpdateRegistryBatchResponse struct {
+		srvs       []*modules.SignedRegistryValue // only set on ErrLowerRevNum and ErrSameRevNum
+		staticErrs []error
+		staticErr  error
+	}
+)
+
+// newJobUpdateRegistryBatch is a helper method to create a new
+// UpdateRegistryBatch job.
+func (w *worker) newJobUpdateRegistryBatch(ctx context.Context, responseChan chan *jobUpdateRegistryBatchResponse, updates []modules.RegistryBatchUpdate) *jobUpdateRegistryBatch {
+	return &jobUpdateRegistryBatch{
+		staticUpdates:      updates,
+		staticResponseChan: responseChan,
+		jobGeneric:         newJobGeneric(ctx, w.staticJobUpdateRegistryBatchQueue, nil),
+	}
+}
+
+// callAddUpdateRegistryBatch adds an UpdateRegistryBatch job for the updates
+// to the worker's queue. Hosts that don't support batched registry updates get
+// an UpdateRegistry job for every update instead and the responses are
+// combined into a single batch response. False is returned if the worker can't
+// participate.
+func (w *worker) callAddUpdateRegistryBatch(ctx context.Context, responseChan chan *jobUpdateRegistryBatchResponse, updates []modules.RegistryBatchUpdate) bool {
+	if build.VersionCmp(w.staticCache().staticHostVersion, minRegistryBatchVersion) >= 0 {
+		return w.staticJobUpdateRegistryBatchQueue.callAdd(w.newJobUpdateRegistryBatch(ctx, responseChan, updates))
+	}
+
+	// Add a job for every update. Every job gets its own buffered channel to
+	// match the responses to the updates without blocking the jobs.
+	entryChans := make([]chan *jobUpdateRegistryResponse, 0, len(updates))
+	for _, update := range updates {
+		entryChan := make(chan *jobUpdateRegistryResponse, 1)
+		if !w.staticJobUpdateRegistryQueue.callAdd(w.newJobUpdateRegistry(ctx, entryChan, update.PubKey, update.Value)) {
+			break
+		}
+		entryChans = append(entryChans, entryChan)
+	}
+	if len(entryChans) == 0 {
+		return false
+	}
+
+	// Combine the responses into a batch response. Updates that couldn't be
+	// added to the queue count as failed.
+	err := w.renter.tg.Launch(func() {
+		response := &jobUpdateRegistryBatchResponse{
+			srvs:       make([]*modules.SignedRegistryValue, len(updates)),
+			staticErrs: make([]error, len(updates)),
+		}
+		for i := len(entryChans); i < len(updates); i++ {
+			response.staticErrs[i] = errors.New("worker unavailable")
+		}
+		for i, entryChan := range entryChans {
+			var resp *jobUpdateRegistryResponse
+			select {
+			case resp = <-entryChan:
+			case <-ctx.Done():
+				return
+			case <-w.renter.tg.StopChan():
+				return
+			}
+			response.srvs[i] = resp.srv
+			response.staticErrs[i] = resp.staticErr
+		}
+		select {
+		case responseChan <- response:
+		case <-ctx.Done():
+		case <-w.renter.tg.StopChan():
+		}
+	})
+	return err == nil
+}
+
+// callDiscard will discard a job, sending the provided error.
+func (j *jobUpdateRegistryBatch) callDiscard(err error) {
+	w := j.staticQueue.staticWorker()
+	errLaunch := w.renter.tg.Launch(func() {
+		response := &jobUpdateRegistryBatchResponse{
+			staticErr: errors.Extend(err, ErrJobDiscarded),
+		}
+		select {
+		case j.staticResponseChan <- response:
+		case <-j.staticCtx.Done():
+		case <-w.renter.tg.StopChan():
+		}
+	})
+	if errLaunch != nil {
+		w.renter.log.Debugln("callDiscard: launch failed", err)
+	}
+}
+
+// callExecute will run the UpdateRegistryBatch job.
+func (j *jobUpdateRegistryBatch) callExecute() {
+	start := time.Now()
+	w := j.staticQueue.staticWorker()
+
+	// Prepare a method to send a response asynchronously.
+	sendResponse := func(srvs []*modules.SignedRegistryValue, errs []error, err error) {
+		errLaunch := w.renter.tg.Launch(func() {
+			response := &jobUpdateRegistryBatchResponse{
+				srvs:       srvs,
+				staticErrs: errs,
+				staticErr:  err,
+			}
+			select {
+			case j.staticResponseChan <- response:
+			case <-j.staticCtx.Done():
+			case <-w.renter.tg.StopChan():
+			}
+		})
+		if errLaunch != nil {
+			w.renter.log.Debugln("callExececute: launch failed", err)
+		}
+	}
+
+	// Update the entries.
+	rvs, errs, err := j.managedUpdateRegistryBatch()
+	if err != nil {
+		sendResponse(nil, nil, err)
+		j.staticQueue.callReportFailure(err)
+		return
+	}
+
+	// Check the individual results the same way a single UpdateRegistry job
+	// does. ErrSameRevNum and ErrLowerRevNum don't count as a failure of the
+	// host as long as the host can prove them.
+	srvs := make([]*modules.SignedRegistryValue, len(errs))
+	var hostErr error
+	for i, err := range errs {
+		update := j.staticUpdates[i]
+		rv := rvs[i]
+		if errors.Contains(err, registry.ErrLowerRevNum) || errors.Contains(err, registry.ErrSameRevNum) {
+			// The host needs to provide a signed registry entry with the
+			// error.
+			if err := rv.Verify(update.PubKey.ToPublicKey()); err != nil {
+				errs[i] = err
+				hostErr = errors.Compose(hostErr, err)
+				continue
+			}
+			// Check if the revision number is actually invalid or if the
+			// revision numbers match but the PoW is too low.
+			if update.Value.Revision > rv.Revision ||
+				(update.Value.Revision == rv.Revision && update.Value.HasMoreWork(rv.RegistryValue)) {
+				errs[i] = errHostOutdatedProof
+				hostErr = errors.Compose(hostErr, errHostOutdatedProof)
+				continue
+			}
+			// Check if we have a higher revision number in the cache than
+			// the provided one.
+			cachedRevision, cached := w.staticRegistryCache.Get(update.PubKey, update.Value.Tweak)
+			if cached && cachedRevision > rv.Revision {
+				errs[i] = errHostLowerRevisionThanCache
+				hostErr = errors.Compose(hostErr, errHostLowerRevisionThanCache)
+				w.staticRegistryCache.Set(update.PubKey, rv, true) // adjust the cache
+				continue
+			}
+			srvs[i] = &rv
+			continue
+		} else if err != nil {
+			hostErr = errors.Compose(hostErr, err)
+			continue
+		}
+		// Success. Update the registry cache.
+		w.staticRegistryCache.Set(update.PubKey, update.Value, false)
+	}
+
+	// Send the response.
+	sendResponse(srvs, errs, nil)
+	if hostErr != nil {
+		j.staticQueue.callReportFailure(hostErr)
+		return
+	}
+	j.staticQueue.callReportSuccess()
+
+	// Update the performance stats on the queue.
+	jobTime := time.Since(start)
+	jq := j.staticQueue.(*jobUpdateRegistryBatchQueue)
+	jq.mu.Lock()
+	jq.weightedJobTime = expMovingAvg(jq.weightedJobTime, float64(jobTime), jobUpdateRegistryBatchPerformanceDecay)
+	jq.mu.Unlock()
+}
+
+// callExpectedBandwidth returns the bandwidth that is expected to be consumed
+// by the job.
+func (j *jobUpdateRegistryBatch) callExpectedBandwidth() (ul, dl uint64) {
+	return updateRegistryBatchJobExpectedBandwidth(len(j.staticUpdates))
+}
+
+// managedUpdateRegistryBatch updates multiple registry entries on a host. It
+// returns one error per update. If such an error is ErrLowerRevNum or
+// ErrSameRevNum, the corresponding signed registry value is the proof.
+func (j *jobUpdateRegistryBatch) managedUpdateRegistryBatch() ([]modules.SignedRegistryValue, []error, error) {
+	w := j.staticQueue.staticWorker()
+	// Create the program.
+	pt := w.staticPriceTable().staticPriceTable
+	pb := modules.NewProgramBuilder(&pt, 0) // 0 duration since UpdateRegistryBatch doesn't depend on it.
+	refund, err := pb.AddUpdateRegistryBatchInstruction(j.staticUpdates)
+	if err != nil {
+		return nil, nil, errors.AddContext(err, "Unable to add update registry batch instruction")
+	}
+	program, programData := pb.Program()
+	cost, _, _ := pb.Cost(true)
+
+	// take into account bandwidth costs
+	ulBandwidth, dlBandwidth := j.callExpectedBandwidth()
+	bandwidthCost := modules.MDMBandwidthCost(pt, ulBandwidth, dlBandwidth)
+	cost = cost.Add(bandwidthCost)
+
+	// Execute the program and parse the responses.
+	responses, _, err := w.managedExecuteProgram(program, programData, types.FileContractID{}, categoryRegistryWrite, cost)
+	if err != nil {
+		return nil, nil, errors.AddContext(err, "Unable to execute program")
+	}
+	for _, resp := range responses {
+		if resp.Error != nil {
+			return nil, nil, errors.AddContext(resp.Error, "Output error")
+		}
+		break
+	}
+	if len(responses) != len(program) {
+		return nil, nil, errors.New("received invalid number of responses but no error")
+	}
+
+	// Decode the results.
+	var results []modules.RegistryBatchUpdateResult
+	err = encoding.Unmarshal(responses[0].Output, &results)
+	if err != nil {
+		return nil, nil, errors.AddContext(err, "failed to decode batch response")
+	}
+	if len(results) != len(j.staticUpdates) {
+		return nil, nil, errors.New("host returned wrong number of results")
+	}
+
+	// Convert the results.
+	rvs := make([]modules.SignedRegistryValue, len(results))
+	errs := make([]error, len(results))
+	var numFailed uint64
+	for i, result := range results {
+		if result.Error == "" {
+			continue
+		}
+		numFailed++
+		switch {
+		case strings.Contains(result.Error, registry.ErrLowerRevNum.Error()):
+			errs[i] = registry.ErrLowerRevNum
+		case strings.Contains(result.Error, registry.ErrSameRevNum.Error()):
+			errs[i] = registry.ErrSameRevNum
+		default:
+			errs[i] = errors.New(result.Error)
+		}
+		rvs[i] = result.Existing
+	}
+
+	// Failed updates are refunded.
+	if numFailed > 0 {
+		w.staticAccount.managedTrackDeposit(refund.Mul64(numFailed))
+		w.staticAccount.managedCommitDeposit(refund.Mul64(numFailed), true)
+	}
+	return rvs, errs, nil
+}
+
+// initJobUpdateRegistryBatchQueue will init the queue for the
+// UpdateRegistryBatch jobs.
+func (w *worker) initJobUpdateRegistryBatchQueue() {
+	// Sanity check that there is no existing job queue.
+	if w.staticJobUpdateRegistryBatchQueue != nil {
+		w.renter.log.Critical("incorret call on initJobUpdateRegistryBatchQueue")
+		return
+	}
+
+	w.staticJobUpdateRegistryBatchQueue = &jobUpdateRegistryBatchQueue{
+		jobGenericQueue: newJobGenericQueue(w),
+	}
+}
+
+// UpdateRegistryBatch is a helper method to run an UpdateRegistryBatch job on
+// a worker.
+func (w *worker) UpdateRegistryBatch(ctx context.Context, updates []modules.RegistryBatchUpdate) ([]error, error) {
+	updateRegistryBatchRespChan := make(chan *jobUpdateRegistryBatchResponse)
+	jurb := w.newJobUpdateRegistryBatch(ctx, updateRegistryBatchRespChan, updates)
+
+	// Add the job to the queue.
+	if !w.staticJobUpdateRegistryBatchQueue.callAdd(jurb) {
+		return nil, errors.New("worker unavailable")
+	}
+
+	// Wait for the response.
+	var resp *jobUpdateRegistryBatchResponse
+	select {
+	case <-ctx.Done():
+		return nil, errors.New("UpdateRegistryBatch interrupted")
+	case resp = <-updateRegistryBatchRespChan:
+	}
+	return resp.staticErrs, resp.staticErr
+}
+
+// updateRegistryBatchJobExpectedBandwidth is a helper function that returns
+// the expected bandwidth consumption of an UpdateRegistryBatch job for
+// numEntries entries.
+func updateRegistryBatchJobExpectedBandwidth(numEntries int) (ul, dl uint64) {
+	ul = ethernetMTU + uint64(numEntries)*modules.RegistryEntrySize
+	dl = ethernetMTU + uint64(numEntries)*modules.RegistryEntrySize
+	return
+}
@@ -0,0 +1,99 @@
+package renter
+
+import (
+	"context"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/registry"
+	"go.sia.tech/siad/types"
+)
+
+// TestUpdateRegistryBatchJob tests running an UpdateRegistryBatch job on a
+// host.
+func TestUpdateRegistryBatchJob(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a few registry values.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.SiaPublicKey{
+		Algorithm: types.SignatureEd25519,
+		Key:       pk[:],
+	}
+	updates := make([]modules.RegistryBatchUpdate, 3)
+	for i := range updates {
+		var tweak crypto.Hash
+		fastrand.Read(tweak[:])
+		data := fastrand.Bytes(modules.RegistryDataSize)
+		rev := fastrand.Uint64n(1000) + 1
+		updates[i] = modules.RegistryBatchUpdate{
+			PubKey: spk,
+			Value:  modules.NewRegistryValue(tweak, data, rev).Sign(sk),
+		}
+	}
+
+	// Run the UpdateRegistryBatch job.
+	errs, err := wt.UpdateRegistryBatch(context.Background(), updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal(i, err)
+		}
+	}
+
+	// The cache should contain all the entries.
+	for _, update := range updates {
+		rev, cached := wt.staticRegistryCache.Get(spk, update.Value.Tweak)
+		if !cached || rev != update.Value.Revision {
+			t.Fatal("entry wasn't cached", cached, rev, update.Value.Revision)
+		}
+	}
+
+	// Run the job again with the same revision for the first entry, a lower
+	// one for the second entry and a higher one for the third entry. Only the
+	// last update should succeed.
+	updates[1].Value.Revision--
+	updates[1].Value = updates[1].Value.Sign(sk)
+	updates[2].Value.Revision++
+	updates[2].Value = updates[2].Value.Sign(sk)
+	errs, err = wt.UpdateRegistryBatch(context.Background(), updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Contains(errs[0], registry.ErrSameRevNum) {
+		t.Fatal("wrong error", errs[0])
+	}
+	if !errors.Contains(errs[1], registry.ErrLowerRevNum) {
+		t.Fatal("wrong error", errs[1])
+	}
+	if errs[2] != nil {
+		t.Fatal(errs[2])
+	}
+
+	// The queue shouldn't be on cooldown since the host was able to prove the
+	// failed updates.
+	wt.staticJobUpdateRegistryBatchQueue.mu.Lock()
+	recentErr := wt.staticJobUpdateRegistryBatchQueue.recentErr
+	wt.staticJobUpdateRegistryBatchQueue.mu.Unlock()
+	if recentErr != nil {
+		t.Fatal("unexpected recent error", recentErr)
+	}
+}
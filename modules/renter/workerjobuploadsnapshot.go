@@ -211,18 +211,6 @@ func (w *worker) initJobUploadSnapshotQueue() {
 
 // managedUploadSnapshotHost uploads a snapshot to a single host.
 func (r *Renter) managedUploadSnapshotHost(meta modules.UploadedBackup, dotSia []byte, host contractor.Session, w *worker) error {
-	// Get the wallet seed.
-	ws, _, err := r.w.PrimarySeed()
-	if err != nil {
-		return errors.AddContext(err, "failed to get wallet's primary seed")
-	}
-	// Derive the renter seed and wipe the memory once we are done using it.
-	rs := modules.DeriveRenterSeed(ws)
-	defer fastrand.Read(rs[:])
-	// Derive the secret and wipe it afterwards.
-	secret := crypto.HashAll(rs, snapshotKeySpecifier)
-	defer fastrand.Read(secret[:])
-
 	// split the snapshot .sia file into sectors
 	var sectors [][]byte
 	for buf := bytes.NewBuffer(dotSia); buf.Len() > 0; {
@@ -239,6 +227,10 @@ func (r *Renter) managedUploadSnapshotHost(meta modules.UploadedBackup, dotSia [
 	if err != nil && !errors.Contains(err, errEmptyContract) {
 		return errors.AddContext(err, "could not download the snapshot table")
 	}
+	shouldOverwrite := len(entryTable) != 0 // only overwrite if the sector already contained an entryTable
+
+	// drop the entries of deleted snapshots from the table.
+	entryTable = filterDeletedSnapshots(entryTable, r.managedDeletedBackups())
 
 	// check if the table already contains the entry.
 	for _, existingEntry := range entryTable {
@@ -264,7 +256,6 @@ func (r *Renter) managedUploadSnapshotHost(meta modules.UploadedBackup, dotSia [
 		entry.DataSectors[j] = root
 	}
 
-	shouldOverwrite := len(entryTable) != 0 // only overwrite if the sector already contained an entryTable
 	entryTable = append(entryTable, entry)
 
 	// if entryTable is too large to fit in a sector, repeatedly remove the
@@ -274,10 +265,40 @@ func (r *Renter) managedUploadSnapshotHost(meta modules.UploadedBackup, dotSia [
 		return r.persist.UploadedBackups[i].CreationDate > r.persist.UploadedBackups[j].CreationDate
 	})
 	r.mu.Unlock(id)
-	c, _ := crypto.NewSiaKey(crypto.TypeThreefish, secret[:])
 	for len(encoding.Marshal(entryTable)) > int(modules.SectorSize) {
 		entryTable = entryTable[:len(entryTable)-1]
 	}
+	return r.managedUploadSnapshotTable(entryTable, shouldOverwrite, host)
+}
+
+// filterDeletedSnapshots returns the entries of the table which don't belong to
+// deleted snapshots.
+func filterDeletedSnapshots(entryTable []snapshotEntry, deleted map[[16]byte]struct{}) []snapshotEntry {
+	filtered := make([]snapshotEntry, 0, len(entryTable))
+	for _, entry := range entryTable {
+		if _, ok := deleted[entry.UID]; !ok {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// managedUploadSnapshotTable encrypts the snapshot table and stores it in the
+// first sector of the host's contract. If shouldOverwrite is true, the table
+// replaces the existing table.
+func (r *Renter) managedUploadSnapshotTable(entryTable []snapshotEntry, shouldOverwrite bool, host contractor.Session) error {
+	// Get the wallet seed.
+	ws, _, err := r.w.PrimarySeed()
+	if err != nil {
+		return errors.AddContext(err, "failed to get wallet's primary seed")
+	}
+	// Derive the renter seed and wipe the memory once we are done using it.
+	rs := modules.DeriveRenterSeed(ws)
+	defer fastrand.Read(rs[:])
+	// Derive the secret and wipe it afterwards.
+	secret := crypto.HashAll(rs, snapshotKeySpecifier)
+	defer fastrand.Read(secret[:])
+	c, _ := crypto.NewSiaKey(crypto.TypeThreefish, secret[:])
 
 	// encode and encrypt the table
 	newTable := make([]byte, modules.SectorSize)
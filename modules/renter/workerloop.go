@@ -129,6 +129,11 @@ func (w *worker) externTryLaunchSerialJob() {
 		w.externLaunchSerialJob(job.callExecute)
 		return
 	}
+	job = w.staticJobDeleteSnapshotQueue.callNext()
+	if job != nil {
+		w.externLaunchSerialJob(job.callExecute)
+		return
+	}
 	job = w.staticJobUploadSnapshotQueue.callNext()
 	if job != nil {
 		w.externLaunchSerialJob(job.callExecute)
@@ -247,6 +252,19 @@ func (w *worker) externTryLaunchAsyncJob() bool {
 			return true
 		}
 	}
+	// Check if batched registry jobs are supported.
+	if build.VersionCmp(cache.staticHostVersion, minRegistryBatchVersion) >= 0 {
+		job = w.staticJobUpdateRegistryBatchQueue.callNext()
+		if job != nil {
+			w.externLaunchAsyncJob(job)
+			return true
+		}
+		job = w.staticJobReadRegistryBatchQueue.callNext()
+		if job != nil {
+			w.externLaunchAsyncJob(job)
+			return true
+		}
+	}
 	job = w.staticJobReadQueue.callNext()
 	if job != nil {
 		w.externLaunchAsyncJob(job)
@@ -283,6 +301,8 @@ func (w *worker) managedDiscardAsyncJobs(err error) {
 	w.staticJobHasSectorQueue.callDiscardAll(err)
 	w.staticJobUpdateRegistryQueue.callDiscardAll(err)
 	w.staticJobReadRegistryQueue.callDiscardAll(err)
+	w.staticJobUpdateRegistryBatchQueue.callDiscardAll(err)
+	w.staticJobReadRegistryBatchQueue.callDiscardAll(err)
 	w.staticJobReadQueue.callDiscardAll(err)
 	w.staticJobLowPrioReadQueue.callDiscardAll(err)
 }
@@ -303,9 +323,12 @@ func (w *worker) threadedWorkLoop() {
 	defer w.staticJobLowPrioReadQueue.callKill()
 	defer w.staticJobHasSectorQueue.callKill()
 	defer w.staticJobUpdateRegistryQueue.callKill()
+	defer w.staticJobUpdateRegistryBatchQueue.callKill()
+	defer w.staticJobReadRegistryBatchQueue.callKill()
 	defer w.staticJobReadQueue.callKill()
 	defer w.staticJobDownloadSnapshotQueue.callKill()
 	defer w.staticJobUploadSnapshotQueue.callKill()
+	defer w.staticJobDeleteSnapshotQueue.callKill()
 
 	// Ensure the renter's revision number of the underlying file contract
 	// is in sync with the host's revision number. This check must happen at
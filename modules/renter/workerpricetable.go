@@ -116,6 +116,16 @@ func (w *worker) newPriceTable() {
 	w.staticSetPriceTable(new(workerPriceTable))
 }
 
+// PriceTable returns the worker's most recent price table for the host. If the
+// worker doesn't have a valid price table, false is returned.
+func (w *worker) PriceTable() (modules.RPCPriceTable, bool) {
+	wpt := w.staticPriceTable()
+	if wpt == nil || !wpt.staticValid() {
+		return modules.RPCPriceTable{}, false
+	}
+	return wpt.staticPriceTable, true
+}
+
 // staticPriceTable will return the most recent price table for the worker's
 // host.
 func (w *worker) staticPriceTable() *workerPriceTable {
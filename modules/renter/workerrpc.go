@@ -44,8 +44,47 @@ type programResponse struct {
 	Output []byte
 }
 
+// programOutputProgressFn is called by managedExecuteProgramStream whenever
+// more output data of an instruction was received. It is passed the index of
+// the instruction, the stream roots the host sent for the instruction and all
+// of the instruction's output data received so far. The data is not verified
+// yet and must not be modified.
+type programOutputProgressFn func(instruction int, roots modules.RPCExecuteProgramStreamRoots, data []byte)
+
+// programFinalizeFn is called by managedExecuteWriteProgram after all outputs
+// of a write program were received without an error. It conducts the
+// handshake which finalizes the program on the stream, given the program's
+// last response.
+type programFinalizeFn func(stream io.ReadWriter, lastResponse programResponse) error
+
+// programOutputStreamChunkSize is the size of the chunks in which streamed
+// output data is read from the host.
+const programOutputStreamChunkSize = 1 << 16 // 64 KiB
+
 // managedExecuteProgram performs the ExecuteProgramRPC on the host
 func (w *worker) managedExecuteProgram(p modules.Program, data []byte, fcid types.FileContractID, category spendingCategory, cost types.Currency) (responses []programResponse, limit mux.BandwidthLimit, err error) {
+	return w.managedExecuteProgramRPC(modules.RPCExecuteProgram, nil, nil, p, data, fcid, category, cost)
+}
+
+// managedExecuteWriteProgram performs the ExecuteProgramRPC on the host for a
+// program that modifies the contract. finalizeFn is called to finalize the
+// program once it was executed successfully.
+func (w *worker) managedExecuteWriteProgram(p modules.Program, data []byte, fcid types.FileContractID, category spendingCategory, cost types.Currency, finalizeFn programFinalizeFn) (responses []programResponse, limit mux.BandwidthLimit, err error) {
+	return w.managedExecuteProgramRPC(modules.RPCExecuteProgram, nil, finalizeFn, p, data, fcid, category, cost)
+}
+
+// managedExecuteProgramStream performs the ExecuteProgramStreamRPC on the
+// host. Instead of sending the output of read instructions once the data was
+// read from disk, the host streams it as it is read. progressFn is called
+// whenever more output data was received and may be nil.
+func (w *worker) managedExecuteProgramStream(p modules.Program, data []byte, fcid types.FileContractID, category spendingCategory, cost types.Currency, progressFn programOutputProgressFn) (responses []programResponse, limit mux.BandwidthLimit, err error) {
+	return w.managedExecuteProgramRPC(modules.RPCExecuteProgramStream, progressFn, nil, p, data, fcid, category, cost)
+}
+
+// managedExecuteProgramRPC performs either the ExecuteProgramRPC or the
+// ExecuteProgramStreamRPC on the host. If finalizeFn is set, it is called
+// after the program was executed successfully.
+func (w *worker) managedExecuteProgramRPC(rpc types.Specifier, progressFn programOutputProgressFn, finalizeFn programFinalizeFn, p modules.Program, data []byte, fcid types.FileContractID, category spendingCategory, cost types.Currency) (responses []programResponse, limit mux.BandwidthLimit, err error) {
 	// Defer a function that schedules a price table update in case we received
 	// an error that indicates the host deems our price table invalid.
 	defer func() {
@@ -81,7 +120,7 @@ func (w *worker) managedExecuteProgram(p modules.Program, data []byte, fcid type
 	buffer := bytes.NewBuffer(nil)
 
 	// write the specifier
-	err = modules.RPCWrite(buffer, modules.RPCExecuteProgram)
+	err = modules.RPCWrite(buffer, rpc)
 	if err != nil {
 		return
 	}
@@ -142,9 +181,29 @@ func (w *worker) managedExecuteProgram(p modules.Program, data []byte, fcid type
 		// Read the output data.
 		outputLen := response.OutputLength
 		response.Output = make([]byte, outputLen)
-		_, err = io.ReadFull(stream, response.Output)
-		if err != nil {
-			return
+		if rpc != modules.RPCExecuteProgramStream {
+			_, err = io.ReadFull(stream, response.Output)
+			if err != nil {
+				return
+			}
+		} else {
+			// When streaming, the stream roots precede the output data.
+			var roots modules.RPCExecuteProgramStreamRoots
+			err = modules.RPCRead(stream, &roots)
+			if err != nil {
+				return
+			}
+			err = readOutputStream(stream, response.Output, i, roots, progressFn)
+			if err != nil {
+				return
+			}
+			// When streaming, the proof follows the output data.
+			var sp modules.RPCExecuteProgramStreamProof
+			err = modules.RPCRead(stream, &sp)
+			if err != nil {
+				return
+			}
+			response.Proof = sp.Proof
 		}
 
 		refund = refund.Add(response.FailureRefund)
@@ -154,9 +213,107 @@ func (w *worker) managedExecuteProgram(p modules.Program, data []byte, fcid type
 
 		// If the response contains an error we are done.
 		if response.Error != nil {
-			break
+			return
+		}
+	}
+
+	// Finalize the program.
+	if finalizeFn != nil && len(responses) > 0 {
+		err = finalizeFn(stream, responses[len(responses)-1])
+	}
+	return
+}
+
+// readOutputStream reads the streamed output data of an instruction into
+// output in chunks and reports the progress after every chunk.
+func readOutputStream(r io.Reader, output []byte, instruction int, roots modules.RPCExecuteProgramStreamRoots, progressFn programOutputProgressFn) error {
+	for n := 0; n < len(output); {
+		end := n + programOutputStreamChunkSize
+		if end > len(output) {
+			end = len(output)
+		}
+		read, err := io.ReadFull(r, output[n:end])
+		n += read
+		if err != nil {
+			return err
+		}
+		if progressFn != nil {
+			progressFn(instruction, roots, output[:n])
+		}
+	}
+	return nil
+}
+
+// managedDryRunProgram performs the DryRunProgramRPC on the host. The host
+// prices the program as if it was executed with the provided budget. The
+// payment only needs to cover the MDM's init cost and the RPC's bandwidth.
+func (w *worker) managedDryRunProgram(p modules.Program, data []byte, fcid types.FileContractID, budget, payment types.Currency) (resp modules.RPCDryRunProgramResponse, err error) {
+	// Defer a function that schedules a price table update in case we received
+	// an error that indicates the host deems our price table invalid.
+	defer func() {
+		if modules.IsPriceTableInvalidErr(err) {
+			w.staticTryForcePriceTableUpdate()
+		}
+	}()
+
+	// track the withdrawal
+	w.staticAccount.managedTrackWithdrawal(payment)
+	defer func() {
+		w.staticAccount.managedCommitWithdrawal(categoryDryRun, payment, types.ZeroCurrency, err == nil)
+	}()
+
+	// create a new stream
+	stream, err := w.staticNewStream()
+	if err != nil {
+		err = errors.AddContext(err, "Unable to create a new stream")
+		return
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			w.renter.log.Println("ERROR: failed to close stream", err)
 		}
+	}()
+
+	// prepare a buffer so we can optimize our writes
+	buffer := bytes.NewBuffer(nil)
+
+	// write the specifier and price table uid
+	pt := w.staticPriceTable().staticPriceTable
+	err = modules.RPCWriteAll(buffer, modules.RPCDryRunProgram, pt.UID)
+	if err != nil {
+		return
+	}
+
+	// provide payment
+	err = w.staticAccount.ProvidePayment(buffer, payment, pt.HostBlockHeight)
+	if err != nil {
+		return
 	}
+
+	// send the request and the program data
+	drr := modules.RPCDryRunProgramRequest{
+		FileContractID:    fcid,
+		Program:           p,
+		ProgramDataLength: uint64(len(data)),
+		Budget:            budget,
+	}
+	err = modules.RPCWrite(buffer, drr)
+	if err != nil {
+		return
+	}
+	_, err = buffer.Write(data)
+	if err != nil {
+		return
+	}
+
+	// write contents of the buffer to the stream
+	_, err = stream.Write(buffer.Bytes())
+	if err != nil {
+		return
+	}
+
+	// read the response
+	err = modules.RPCRead(stream, &resp)
 	return
 }
 
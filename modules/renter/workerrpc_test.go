@@ -1,7 +1,9 @@
 package renter
 
 import (
+	"bytes"
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -201,3 +203,139 @@ func testExecuteProgramUsedBandwidthReadSector(t *testing.T, wt *workerTester) {
 	// log the bandwidth used
 	t.Logf("Used bandwidth (read sector program): %v down, %v up", limit.Downloaded(), limit.Uploaded())
 }
+
+// TestExecuteProgramStream verifies that streaming the outputs of a program
+// returns the same outputs as executing it regularly and that the progress is
+// reported while the output is received.
+func TestExecuteProgramStream(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// create a new worker tester
+	wt, err := newWorkerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := wt.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	w := wt.worker
+
+	// add a sector to the host
+	sectorData := fastrand.Bytes(int(modules.SectorSize))
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	err = wt.host.AddSector(sectorRoot, sectorData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create a program which reads a part of the sector
+	pt := wt.staticPriceTable().staticPriceTable
+	pb := modules.NewProgramBuilder(&pt, 0)
+	pb.AddHasSectorInstruction(sectorRoot)
+	offset, length := uint64(crypto.SegmentSize), modules.SectorSize-2*crypto.SegmentSize
+	pb.AddReadSectorInstruction(length, offset, sectorRoot, true)
+	p, data := pb.Program()
+	cost, _, _ := pb.Cost(true)
+	ulBandwidth, dlBandwidth := readSectorJobExpectedBandwidth(length)
+	cost = cost.Add(modules.MDMBandwidthCost(pt, ulBandwidth, dlBandwidth))
+
+	// execute it regularly
+	expected, _, err := w.managedExecuteProgram(p, data, types.FileContractID{}, categoryDownload, cost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stream it once, the host doesn't know the chunk roots of the sector the
+	// first time so the data can't be verified before the final proof
+	_, _, err = w.managedExecuteProgramStream(p, data, types.FileContractID{}, categoryDownload, cost, func(instruction int, roots modules.RPCExecuteProgramStreamRoots, _ []byte) {
+		if instruction == 1 && len(roots.Roots) != 0 {
+			t.Error("expected no stream roots before the host cached them")
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stream it again and verify the data as it arrives
+	var progress [][]byte
+	var verified []uint64
+	var streamRoots modules.RPCExecuteProgramStreamRoots
+	v := newSectorStreamVerifier(sectorRoot, offset, length)
+	progressFn := func(instruction int, roots modules.RPCExecuteProgramStreamRoots, data []byte) {
+		if instruction == 1 {
+			progress = append(progress, append([]byte(nil), data...))
+			verified = append(verified, v.verify(roots, data))
+			streamRoots = roots
+		}
+	}
+	responses, _, err := w.managedExecuteProgramStream(p, data, types.FileContractID{}, categoryDownload, cost, progressFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// compare the responses
+	if len(responses) != len(expected) {
+		t.Fatalf("expected %v responses but got %v", len(expected), len(responses))
+	}
+	for i := range responses {
+		if responses[i].Error != nil {
+			t.Fatal(responses[i].Error)
+		}
+		if !bytes.Equal(responses[i].Output, expected[i].Output) {
+			t.Fatal("output mismatch", i)
+		}
+		if !reflect.DeepEqual(responses[i].Proof, expected[i].Proof) {
+			t.Fatal("proof mismatch", i)
+		}
+	}
+	proofStart := int(offset) / crypto.SegmentSize
+	proofEnd := int(offset+length) / crypto.SegmentSize
+	if !crypto.VerifyRangeProof(responses[1].Output, responses[1].Proof, proofStart, proofEnd, sectorRoot) {
+		t.Fatal("proof verification failed")
+	}
+
+	// the progress should contain growing prefixes of the output
+	numChunks := int((length + programOutputStreamChunkSize - 1) / programOutputStreamChunkSize)
+	if len(progress) != numChunks {
+		t.Fatalf("expected %v progress updates but got %v", numChunks, len(progress))
+	}
+	for i, prefix := range progress {
+		if i > 0 && len(prefix) <= len(progress[i-1]) {
+			t.Fatal("progress didn't grow")
+		}
+		if !bytes.Equal(prefix, sectorData[offset:offset+uint64(len(prefix))]) {
+			t.Fatal("progress contains wrong data")
+		}
+	}
+	if len(progress[len(progress)-1]) != int(length) {
+		t.Fatal("last progress update should contain the full output")
+	}
+
+	// every update completes a chunk of the sector which can be verified
+	// using the stream roots
+	for i, n := range verified {
+		if n%crypto.SegmentSize != 0 || n > uint64(len(progress[i])) {
+			t.Fatal("invalid verified prefix", n)
+		}
+		if i > 0 && n <= verified[i-1] {
+			t.Fatal("verified prefix didn't grow", i, n)
+		}
+	}
+	if verified[len(verified)-1] != length {
+		t.Fatal("the full output should be verified", verified[len(verified)-1])
+	}
+
+	// corrupted data fails the verification
+	v = newSectorStreamVerifier(sectorRoot, offset, length)
+	corrupted := append([]byte(nil), progress[len(progress)-1]...)
+	corrupted[modules.MDMStreamChunkSize] ^= 1
+	if n := v.verify(streamRoots, corrupted); n != modules.MDMStreamChunkSize-offset {
+		t.Fatal("verification should stop at the corrupted chunk", n)
+	}
+}
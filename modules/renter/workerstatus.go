@@ -60,6 +60,7 @@ func (w *worker) callStatus() modules.WorkerStatus {
 		UploadTerminated:    w.uploadTerminated,
 
 		// Job Queues
+		DeleteSnapshotJobQueueSize:   int(w.staticJobDeleteSnapshotQueue.callStatus().size),
 		DownloadSnapshotJobQueueSize: int(w.staticJobDownloadSnapshotQueue.callStatus().size),
 		UploadSnapshotJobQueueSize:   int(w.staticJobUploadSnapshotQueue.callStatus().size),
 
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/modules/renter/filesystem/siafile"
 
@@ -178,47 +179,24 @@ func (w *worker) managedPerformUploadChunkJob() {
 	if uc == nil {
 		return
 	}
-	// Open an editing connection to the host.
-	e, err := w.renter.hostContractor.Editor(w.staticHostPubKey, w.renter.tg.StopChan())
-	if err != nil {
-		failureErr := fmt.Errorf("Worker failed to acquire an editor: %v", err)
-		w.managedUploadFailed(uc, pieceIndex, failureErr)
-		return
-	}
-	defer func() {
-		if err := e.Close(); err != nil {
-			w.renter.log.Print("managedPerformUploadChunkJob: failed to close editor", err)
-		}
-	}()
-
-	// Before performing the upload, check for price gouging.
-	allowance := w.renter.hostContractor.Allowance()
-	hostSettings := e.HostSettings()
-	err = checkUploadGouging(allowance, hostSettings)
-	if err != nil && !w.renter.deps.Disrupt("DisableUploadGouging") {
-		failureErr := errors.AddContext(err, "worker uploader is not being used because price gouging was detected")
-		w.managedUploadFailed(uc, pieceIndex, failureErr)
-		return
-	}
 
-	// Perform the upload, and update the failure stats based on the success of
-	// the upload attempt.
-	//
-	// Ignore the error if it's a ErrMaxVirtualSectors coming from a pre-1.5.5
-	// host.
-	root, err := e.Upload(uc.physicalChunkData[pieceIndex])
-	ignoreErr := build.VersionCmp(hostSettings.Version, "1.5.5") < 0 && err != nil && strings.Contains(err.Error(), modules.ErrMaxVirtualSectors.Error())
-	if err != nil && !ignoreErr {
-		failureErr := fmt.Errorf("Worker failed to upload root %v via the editor: %v", root, err)
-		w.managedUploadFailed(uc, pieceIndex, failureErr)
-		return
+	// If the host already stores the piece under another one of the renter's
+	// contracts, copy it instead of uploading it again.
+	root, copied := w.managedCopyPiece(uc, pieceIndex)
+	if !copied {
+		var err error
+		root, err = w.managedUploadPiece(uc, pieceIndex)
+		if err != nil {
+			w.managedUploadFailed(uc, pieceIndex, err)
+			return
+		}
 	}
 	w.mu.Lock()
 	w.uploadConsecutiveFailures = 0
 	w.mu.Unlock()
 
 	// Add piece to renterFile
-	err = uc.fileEntry.AddPiece(w.staticHostPubKey, uc.staticIndex, pieceIndex, root)
+	err := uc.fileEntry.AddPiece(w.staticHostPubKey, uc.staticIndex, pieceIndex, root)
 	if err != nil {
 		failureErr := fmt.Errorf("Worker failed to add new piece to SiaFile: %v", err)
 		w.managedUploadFailed(uc, pieceIndex, failureErr)
@@ -242,6 +220,65 @@ func (w *worker) managedPerformUploadChunkJob() {
 	w.renter.managedCleanUpUploadChunk(uc)
 }
 
+// managedCopyPiece tries to copy a piece of the chunk that is being repaired
+// with the CopySector instruction from another one of the renter's contracts
+// with the host. This is the case when the host is still storing the piece
+// under a contract that is being migrated. The root of the piece is taken from
+// the file and the piece is only copied from a contract which is known to
+// contain it. It returns false if the piece wasn't copied and needs to be
+// uploaded instead.
+func (w *worker) managedCopyPiece(uc *unfinishedUploadChunk, pieceIndex uint64) (crypto.Hash, bool) {
+	// The root of the piece is only known if the chunk is being repaired.
+	root := uc.staticExpectedPieceRoots[pieceIndex]
+	if root == (crypto.Hash{}) {
+		return crypto.Hash{}, false
+	}
+	srcID, exists := w.renter.hostContractor.CopySectorSource(w.staticHostPubKey, root)
+	if !exists {
+		return crypto.Hash{}, false
+	}
+	err := w.managedCopySector(srcID, root, categoryRepairUpload)
+	if err != nil {
+		w.renter.repairLog.Printf("Worker failed to copy piece, uploading it instead. Worker: %v, Chunk: %v of %s, Error: %v", w.staticHostPubKey, uc.staticIndex, uc.staticSiaPath, err)
+		return crypto.Hash{}, false
+	}
+	return root, true
+}
+
+// managedUploadPiece uploads a piece of the chunk to the host using an editor
+// and returns the piece's root.
+func (w *worker) managedUploadPiece(uc *unfinishedUploadChunk, pieceIndex uint64) (_ crypto.Hash, err error) {
+	// Open an editing connection to the host.
+	e, err := w.renter.hostContractor.Editor(w.staticHostPubKey, w.renter.tg.StopChan())
+	if err != nil {
+		return crypto.Hash{}, fmt.Errorf("Worker failed to acquire an editor: %v", err)
+	}
+	defer func() {
+		if err := e.Close(); err != nil {
+			w.renter.log.Print("managedUploadPiece: failed to close editor", err)
+		}
+	}()
+
+	// Before performing the upload, check for price gouging.
+	allowance := w.renter.hostContractor.Allowance()
+	hostSettings := e.HostSettings()
+	err = checkUploadGouging(allowance, hostSettings)
+	if err != nil && !w.renter.deps.Disrupt("DisableUploadGouging") {
+		return crypto.Hash{}, errors.AddContext(err, "worker uploader is not being used because price gouging was detected")
+	}
+
+	// Perform the upload.
+	//
+	// Ignore the error if it's a ErrMaxVirtualSectors coming from a pre-1.5.5
+	// host.
+	root, err := e.Upload(uc.physicalChunkData[pieceIndex])
+	ignoreErr := build.VersionCmp(hostSettings.Version, "1.5.5") < 0 && err != nil && strings.Contains(err.Error(), modules.ErrMaxVirtualSectors.Error())
+	if err != nil && !ignoreErr {
+		return crypto.Hash{}, fmt.Errorf("Worker failed to upload root %v via the editor: %v", root, err)
+	}
+	return root, nil
+}
+
 // onUploadCooldown returns true if the worker is on cooldown from failed
 // uploads and the amount of cooldown time remaining for the worker.
 func (w *worker) onUploadCooldown() (bool, time.Duration) {
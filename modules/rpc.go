@@ -49,6 +49,10 @@ var (
 	// table for the provided price table UID.
 	ErrPriceTableNotFound = errors.New("Price table not found")
 
+	// ErrClientQuotaExceeded is returned by the host if a client exceeds one
+	// of the host's per-client quotas.
+	ErrClientQuotaExceeded = errors.New("client quota exceeded")
+
 	// ErrPriceTableExpired is returned by the host when the specified price
 	// table has expired.
 	ErrPriceTableExpired = errors.New("Price table requested is expired")
@@ -137,6 +141,10 @@ type RPCPriceTable struct {
 	// SwapSectorCost is the cost of swapping 2 full sectors by root.
 	SwapSectorCost types.Currency `json:"swapsectorcost"`
 
+	// Cost values specific to the UpdateSector instruction.
+	UpdateSectorBaseCost   types.Currency `json:"updatesectorbasecost"`   // per update
+	UpdateSectorLengthCost types.Currency `json:"updatesectorlengthcost"` // per byte written
+
 	// Cost values specific to the Write instruction.
 	WriteBaseCost   types.Currency `json:"writebasecost"`   // per write
 	WriteLengthCost types.Currency `json:"writelengthcost"` // per byte written
@@ -183,6 +191,12 @@ var (
 	// RPCExecuteProgram specifier
 	RPCExecuteProgram = types.NewSpecifier("ExecuteProgram")
 
+	// RPCExecuteProgramStream specifier
+	RPCExecuteProgramStream = types.NewSpecifier("ExecProgStream")
+
+	// RPCDryRunProgram specifier
+	RPCDryRunProgram = types.NewSpecifier("DryRunProgram")
+
 	// RPCFundAccount specifier
 	RPCFundAccount = types.NewSpecifier("FundAccount")
 
@@ -247,6 +261,29 @@ type (
 		FailureRefund        types.Currency
 	}
 
+	// RPCExecuteProgramStreamRoots is sent by the host before the output data
+	// of every instruction when executing a program with
+	// RPCExecuteProgramStream. For ReadSector instructions that request a
+	// proof, Roots contains the Merkle roots of all the MDMStreamChunkSize
+	// chunks of the sector and Proofs contains a range proof for the part of
+	// every chunk that overlaps the output. The proofs of chunks the output
+	// covers completely are empty. Both are also empty if the host doesn't
+	// know the chunk roots of the sector yet, in which case the output can
+	// only be verified with the proof sent after the data. For all other
+	// instructions both are empty.
+	RPCExecuteProgramStreamRoots struct {
+		Roots  []crypto.Hash
+		Proofs [][]crypto.Hash
+	}
+
+	// RPCExecuteProgramStreamProof is sent by the host after the output data
+	// of every instruction when executing a program with
+	// RPCExecuteProgramStream. Since the host streams the output data as it
+	// is read from disk, the proof can only be sent after the data.
+	RPCExecuteProgramStreamProof struct {
+		Proof []crypto.Hash
+	}
+
 	// RPCExecuteProgramRevisionSigningRequest is the request sent by the renter
 	// for updating a contract when executing a write MDM program.
 	RPCExecuteProgramRevisionSigningRequest struct {
@@ -262,6 +299,47 @@ type (
 		Signature []byte
 	}
 
+	// RPCDryRunProgramRequest is the request sent by the renter to have the
+	// host price a program without executing it. It is followed by the
+	// program's data.
+	RPCDryRunProgramRequest struct {
+		// FileContractID is the id of the filecontract the program would
+		// modify.
+		FileContractID types.FileContractID
+		// Instructions to be priced as a program.
+		Program Program
+		// ProgramDataLength is the length of the programData following this
+		// request.
+		ProgramDataLength uint64
+		// Budget is the budget the renter would execute the program with.
+		Budget types.Currency
+	}
+
+	// RPCDryRunProgramResponse is the response sent by the host after pricing
+	// a program. If the program would fail, Error contains the error of the
+	// first failing instruction and Instructions only contains the
+	// instructions before it.
+	RPCDryRunProgramResponse struct {
+		InitCost     types.Currency
+		Instructions []RPCDryRunInstruction
+		Error        string
+	}
+
+	// RPCDryRunInstruction contains the breakdown of a single instruction of
+	// a dry run. The costs are the instruction's share of the program's costs
+	// and include the memory cost.
+	RPCDryRunInstruction struct {
+		Specifier InstructionSpecifier
+		// Cost is an upper bound. Refunds an instruction issues during
+		// execution, e.g. for registry entries that aren't found, are not
+		// subtracted since the instruction isn't executed.
+		Cost          types.Currency
+		FailureRefund types.Currency
+		Collateral    types.Currency
+		Memory        uint64
+		Time          uint64
+	}
+
 	// RPCLatestRevisionRequest contains the id of the contract for which to
 	// retrieve the latest revision.
 	RPCLatestRevisionRequest struct {
@@ -390,6 +468,26 @@ func (epr *RPCExecuteProgramResponse) UnmarshalSia(r io.Reader) error {
 	return dc.Err()
 }
 
+// TotalCost returns the total cost of the program including the cost of
+// initializing the MDM.
+func (r RPCDryRunProgramResponse) TotalCost() types.Currency {
+	cost := r.InitCost
+	for _, instruction := range r.Instructions {
+		cost = cost.Add(instruction.Cost)
+	}
+	return cost
+}
+
+// TotalCollateral returns the total collateral the host would need to add for
+// the program.
+func (r RPCDryRunProgramResponse) TotalCollateral() types.Currency {
+	var collateral types.Currency
+	for _, instruction := range r.Instructions {
+		collateral = collateral.Add(instruction.Collateral)
+	}
+	return collateral
+}
+
 // RPCReadMaxLen tries to read the given object from the stream. It will
 // allocate at most maxLen bytes for the object.
 func RPCReadMaxLen(r io.Reader, obj interface{}, maxLen uint64) error {
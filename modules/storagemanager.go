@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"go.sia.tech/siad/crypto"
 )
 
@@ -40,6 +42,37 @@ type (
 		// folder. Progress is always reported in bytes.
 		ProgressNumerator   uint64
 		ProgressDenominator uint64
+
+		// Below are statistics about the sector scrubber, which periodically
+		// re-reads the sectors of the storage folder to verify that their
+		// data still matches their Merkle roots. CorruptSectors is the number
+		// of sectors whose data doesn't match their root anymore.
+		// ScrubReadErrors counts the sectors that the scrubber failed to read
+		// since the last health reset. ScrubProgress is the fraction of the
+		// storage folder that was scrubbed during the current pass and
+		// LastScrubTime is the time at which the last full pass completed.
+		CorruptSectors  uint64    `json:"corruptsectors"`
+		ScrubReadErrors uint64    `json:"scrubreaderrors"`
+		ScrubProgress   float64   `json:"scrubprogress"`
+		LastScrubTime   time.Time `json:"lastscrubtime"`
+
+		// Evacuating indicates that the storage folder is being emptied by a
+		// rebalance and doesn't receive any new sectors.
+		Evacuating bool `json:"evacuating"`
+	}
+
+	// CacheFolderMetadata contains metadata about the cache folder of the
+	// storage manager, which keeps copies of frequently read sectors on a
+	// faster disk. Hits and Misses count the reads which were and weren't
+	// served from the cache since startup.
+	CacheFolderMetadata struct {
+		Capacity          uint64 `json:"capacity"`          // bytes
+		CapacityRemaining uint64 `json:"capacityremaining"` // bytes
+		Path              string `json:"path"`
+
+		Hits    uint64  `json:"hits"`
+		Misses  uint64  `json:"misses"`
+		HitRate float64 `json:"hitrate"`
 	}
 
 	// A StorageManager is responsible for managing storage folders and
@@ -62,6 +95,11 @@ type (
 		// a given root or not.
 		HasSector(crypto.Hash) bool
 
+		// CheckSector checks whether the storage manager is able to serve the
+		// sector with the given root. It returns an error if the sector is
+		// unknown, corrupted or stored in an unavailable storage folder.
+		CheckSector(crypto.Hash) error
+
 		// AddSectorBatch is a performance optimization over AddSector when
 		// adding a bunch of virtual sectors. It is necessary because otherwise
 		// potentially thousands or even tens-of-thousands of fsync calls would
@@ -69,12 +107,26 @@ type (
 		// successfully renewing.
 		AddSectorBatch(sectorRoots []crypto.Hash) error
 
+		// AddCacheFolder designates a folder as the cache tier of the
+		// manager. Frequently read sectors are copied into the cache folder
+		// and served from there, while the authoritative copies remain in the
+		// storage folders.
+		AddCacheFolder(path string, size uint64) error
+
 		// AddStorageFolder adds a storage folder to the manager. The manager
 		// may not check that there is enough space available on-disk to
 		// support as much storage as requested, though the manager should
 		// gracefully handle running out of storage unexpectedly.
 		AddStorageFolder(path string, size uint64) error
 
+		// CacheFolder returns the metadata of the cache folder. The path is
+		// empty if there is no cache folder.
+		CacheFolder() CacheFolderMetadata
+
+		// CancelStorageFolderRebalance stops the ongoing storage folder
+		// rebalance.
+		CancelStorageFolderRebalance() error
+
 		// The storage manager needs to be able to shut down.
 		Close() error
 
@@ -86,6 +138,13 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// RebalanceStorageFolders starts migrating sectors between the
+		// storage folders in the background, either to even out their
+		// utilisation or, if 'evacuate' is not empty, to empty the storage
+		// folders with the provided indices. Sectors are moved at a rate of at
+		// most 'rate' bytes per second, or at a default rate if 'rate' is 0.
+		RebalanceStorageFolders(evacuate []uint16, rate uint64) error
+
 		// ReadSector will read a sector from the storage manager, returning the
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
@@ -94,6 +153,10 @@ type (
 		// returning the bytes that match the input sector root.
 		ReadPartialSector(sectorRoot crypto.Hash, offset, length uint64) ([]byte, error)
 
+		// RemoveCacheFolder removes the cache folder of the manager. No data
+		// is lost since the cache only contains copies of sectors.
+		RemoveCacheFolder() error
+
 		// RemoveSector will remove a sector from the storage manager. The
 		// height at which the sector expires should be provided, so that the
 		// auto-expiry information for that sector can be properly updated.
@@ -128,6 +191,11 @@ type (
 		// that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// SetSectorScrubRate sets the rate in bytes per second at which the
+		// manager re-reads stored sectors to verify their integrity. A rate of
+		// 0 disables the scrubbing.
+		SetSectorScrubRate(bytesPerSecond uint64)
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// manager.
 		StorageFolders() []StorageFolderMetadata
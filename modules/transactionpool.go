@@ -130,6 +130,11 @@ type (
 		// that make this condition necessary.
 		PurgeTransactionPool()
 
+		// PurgeTransactionSet removes the transaction set containing the
+		// transaction with the given id from the transaction pool and returns
+		// the removed set.
+		PurgeTransactionSet(id types.TransactionID) (set []types.Transaction, exists bool)
+
 		// Transaction returns the transaction and unconfirmed parents
 		// corresponding to the provided transaction id.
 		Transaction(id types.TransactionID) (txn types.Transaction, unconfirmedParents []types.Transaction, exists bool)
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"time"
 
+	"gitlab.com/NebulousLabs/encoding"
 	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
@@ -453,3 +454,46 @@ func (tp *TransactionPool) PurgeTransactionPool() {
 	tp.purge()
 	tp.mu.Unlock()
 }
+
+// PurgeTransactionSet removes the transaction set containing the transaction
+// with the given id from the transaction pool and returns the removed set. It
+// is used to replace transactions which are stuck in the pool. The set is only
+// removed locally, peers might still hold on to it.
+func (tp *TransactionPool) PurgeTransactionSet(id types.TransactionID) ([]types.Transaction, bool) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	var setID modules.TransactionSetID
+	var set []types.Transaction
+	for tSetID, tSet := range tp.transactionSets {
+		for _, txn := range tSet {
+			if txn.ID() == id {
+				setID, set = tSetID, tSet
+				break
+			}
+		}
+		if set != nil {
+			break
+		}
+	}
+	if set == nil {
+		return nil, false
+	}
+
+	// Remove the objects which point to the set.
+	for oid, tSetID := range tp.knownObjects {
+		if tSetID == setID {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	for _, txn := range set {
+		delete(tp.transactionHeights, txn.ID())
+	}
+	tp.transactionListSize -= len(encoding.Marshal(set))
+	delete(tp.transactionSets, setID)
+	delete(tp.transactionSetDiffs, setID)
+
+	// Inform the subscribers about the removed set.
+	tp.updateSubscribersTransactions()
+	return set, true
+}
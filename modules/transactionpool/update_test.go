@@ -511,3 +511,74 @@ func TestDatabaseUpgrade(t *testing.T) {
 		t.Fatal("testers did not have the same block height after one minute")
 	}
 }
+
+// TestPurgeTransactionSet checks that purging a transaction set only removes
+// that set from the transaction pool and that it can be accepted again
+// afterwards.
+func TestPurgeTransactionSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tpt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Add an unrelated transaction and a wallet transaction to the pool.
+	arbTxn := types.Transaction{
+		ArbitraryData: [][]byte{
+			append(modules.PrefixNonSia[:], []byte("arb-data")...),
+		},
+	}
+	if err := tpt.tpool.AcceptTransactionSet([]types.Transaction{arbTxn}); err != nil {
+		t.Fatal(err)
+	}
+	uc, err := tpt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision, uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	numTxns := len(tpt.tpool.TransactionList())
+	if numTxns != len(set)+1 {
+		t.Fatalf("expected %v transactions in the pool but got %v", len(set)+1, numTxns)
+	}
+	size := tpt.tpool.transactionListSize
+
+	// Purge the wallet transaction.
+	txid := set[len(set)-1].ID()
+	purged, exists := tpt.tpool.PurgeTransactionSet(txid)
+	if !exists {
+		t.Fatal("transaction set wasn't found")
+	}
+	if len(purged) != len(set) {
+		t.Fatalf("expected %v purged transactions but got %v", len(set), len(purged))
+	}
+	if _, _, exists := tpt.tpool.Transaction(txid); exists {
+		t.Fatal("transaction is still in the pool")
+	}
+	if _, _, exists := tpt.tpool.Transaction(arbTxn.ID()); !exists {
+		t.Fatal("unrelated transaction was purged")
+	}
+	if len(tpt.tpool.knownObjects) != 0 {
+		t.Fatal("purged objects are still known", len(tpt.tpool.knownObjects))
+	}
+	if _, exists := tpt.tpool.PurgeTransactionSet(txid); exists {
+		t.Fatal("transaction set was purged twice")
+	}
+
+	// The purged set can be accepted again.
+	if err := tpt.tpool.AcceptTransactionSet(purged); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != numTxns || tpt.tpool.transactionListSize != size {
+		t.Fatal("purged set wasn't accepted again")
+	}
+}
@@ -27,6 +27,16 @@ const (
 	WalletDir = "wallet"
 )
 
+const (
+	// FeeBumpCPFP bumps the fee of a transaction by spending one of its change
+	// outputs with a child transaction that pays the fee for both of them.
+	FeeBumpCPFP FeeBumpMethod = "cpfp"
+
+	// FeeBumpRBF bumps the fee of a transaction by replacing it with a
+	// transaction that spends the same inputs but pays a higher fee.
+	FeeBumpRBF FeeBumpMethod = "rbf"
+)
+
 var (
 	// ErrBadEncryptionKey is returned if the incorrect encryption key to a
 	// file is provided.
@@ -59,6 +69,10 @@ type (
 	// WalletTransactionID is a unique identifier for a wallet transaction.
 	WalletTransactionID crypto.Hash
 
+	// FeeBumpMethod describes how the fee of an unconfirmed transaction is
+	// increased.
+	FeeBumpMethod string
+
 	// A ProcessedInput represents funding to a transaction. The input is
 	// coming from an address and going to the outputs. The fund types are
 	// 'SiacoinInput', 'SiafundInput'.
@@ -423,6 +437,12 @@ type (
 		EncryptionManager
 		KeyManager
 
+		// AddMultisigAddress creates an address that requires 'required'
+		// signatures of the given public keys to be spent and starts tracking
+		// its outputs. The unused flag has the same meaning as for
+		// AddWatchAddresses.
+		AddMultisigAddress(keys []types.SiaPublicKey, required uint64, unused bool) (types.UnlockConditions, error)
+
 		// AddUnlockConditions adds a set of UnlockConditions to the wallet database.
 		AddUnlockConditions(uc types.UnlockConditions) error
 
@@ -433,9 +453,24 @@ type (
 		// the blockchain to search for transactions containing the addresses.
 		AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 
+		// BroadcastMultisigTransaction submits a partially-signed transaction
+		// which collected all of its signatures to the transaction pool.
+		BroadcastMultisigTransaction(pst PartiallySignedTransaction) (types.Transaction, error)
+
+		// BumpTransactionFee increases the fee of an unconfirmed wallet
+		// transaction to feePerByte, or the maximum recommended fee of the
+		// transaction pool if feePerByte is zero. It returns the transaction
+		// set which was submitted to the transaction pool.
+		BumpTransactionFee(txid types.TransactionID, method FeeBumpMethod, feePerByte types.Currency) ([]types.Transaction, error)
+
 		// Close permits clean shutdown during testing and serving.
 		Close() error
 
+		// CreateMultisigTransaction creates a partially-signed transaction
+		// which funds the outputs from a multisig address tracked by the
+		// wallet. The change is sent back to the multisig address.
+		CreateMultisigTransaction(addr types.UnlockHash, outputs []types.SiacoinOutput) (PartiallySignedTransaction, error)
+
 		// ConfirmedBalance returns the confirmed balance of the wallet, minus
 		// any outgoing transactions. ConfirmedBalance will include unconfirmed
 		// refund transactions.
@@ -473,6 +508,10 @@ type (
 		// relative to the wallet.
 		UnconfirmedTransactions() ([]ProcessedTransaction, error)
 
+		// MultisigAddresses returns the unlock conditions of the multisig
+		// addresses tracked by the wallet.
+		MultisigAddresses() ([]types.UnlockConditions, error)
+
 		// RegisterTransaction takes a transaction and its parents and returns
 		// a TransactionBuilder which can be used to expand the transaction.
 		RegisterTransaction(t types.Transaction, parents []types.Transaction) (TransactionBuilder, error)
@@ -515,6 +554,11 @@ type (
 		// are also returned to the caller.
 		SendSiafunds(amount types.Currency, dest types.UnlockHash) ([]types.Transaction, error)
 
+		// SignMultisigTransaction adds the signatures of the wallet's keys to
+		// the inputs of a partially-signed transaction that are still missing
+		// signatures.
+		SignMultisigTransaction(pst PartiallySignedTransaction) (PartiallySignedTransaction, error)
+
 		// DustThreshold returns the quantity per byte below which a Currency is
 		// considered to be Dust.
 		DustThreshold() (types.Currency, error)
@@ -0,0 +1,484 @@
+package wallet
+
+import (
+	"fmt"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errFeeNotIncreased is returned when the fee of a transaction is bumped
+	// to a fee that it already pays.
+	errFeeNotIncreased = errors.New("transaction already pays at least the requested fee")
+
+	// errNoChangeOutput is returned when neither a transaction nor its
+	// unconfirmed parents have an unspent wallet output that can pay for the
+	// higher fee.
+	errNoChangeOutput = errors.New("transaction has no change output that can pay for the higher fee")
+
+	// errNotUnconfirmedTransaction is returned when the fee of a transaction is
+	// bumped that isn't an unconfirmed transaction of the wallet.
+	errNotUnconfirmedTransaction = errors.New("transaction is not an unconfirmed wallet transaction")
+)
+
+// changeOutput identifies an unspent wallet output of a transaction set.
+type changeOutput struct {
+	txnIndex    int
+	outputIndex int
+	id          types.SiacoinOutputID
+	output      types.SiacoinOutput
+}
+
+// encodedSize returns the encoded size of a transaction set in bytes.
+func encodedSize(txns []types.Transaction) uint64 {
+	return uint64(len(encoding.Marshal(txns)))
+}
+
+// minerFees returns the sum of the miner fees of a transaction set.
+func minerFees(txns []types.Transaction) (fees types.Currency) {
+	for _, txn := range txns {
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	return fees
+}
+
+// BumpTransactionFee increases the fee of an unconfirmed wallet transaction to
+// feePerByte, or the maximum recommended fee of the transaction pool if
+// feePerByte is zero. The fee is paid from a change output of the transaction
+// or one of its unconfirmed parents. FeeBumpCPFP spends the change output with
+// a child transaction while FeeBumpRBF replaces the transaction set in the
+// local transaction pool with one that pays the fee from the change output.
+func (w *Wallet) BumpTransactionFee(txid types.TransactionID, method modules.FeeBumpMethod, feePerByte types.Currency) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if method != modules.FeeBumpCPFP && method != modules.FeeBumpRBF {
+		return nil, fmt.Errorf("unknown fee bump method %q", method)
+	}
+	if feePerByte.IsZero() {
+		_, feePerByte = w.tpool.FeeEstimation()
+	}
+
+	w.mu.RLock()
+	unlocked := w.unlocked
+	var found bool
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		if pt.TransactionID == txid {
+			found = true
+			break
+		}
+	}
+	w.mu.RUnlock()
+	if !unlocked {
+		return nil, modules.ErrLockedWallet
+	}
+	if !found {
+		return nil, errNotUnconfirmedTransaction
+	}
+
+	txn, parents, exists := w.tpool.Transaction(txid)
+	if !exists {
+		return nil, errNotUnconfirmedTransaction
+	}
+	stuck := append(parents, txn)
+	if method == modules.FeeBumpCPFP {
+		return w.managedBumpFeeCPFP(stuck, feePerByte)
+	}
+	return w.managedBumpFeeRBF(stuck, feePerByte)
+}
+
+// managedBumpFeeCPFP spends a change output of the stuck transaction set with
+// a child transaction which pays the fee that is missing for the set and the
+// child to pay feePerByte.
+func (w *Wallet) managedBumpFeeCPFP(stuck []types.Transaction, feePerByte types.Currency) (_ []types.Transaction, err error) {
+	paid := minerFees(stuck)
+	stuckSize := encodedSize(stuck)
+
+	w.mu.Lock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, err
+	}
+	candidates := w.changeOutputs(stuck)
+	if len(candidates) == 0 {
+		w.mu.Unlock()
+		return nil, errNoChangeOutput
+	}
+	var child types.Transaction
+	var childFee types.Currency
+	var increased bool
+	for _, co := range candidates {
+		// Build the child with the full value of the output as the fee to get
+		// an upper bound of its size.
+		uc := w.keys[co.output.UnlockHash].UnlockConditions
+		child = types.Transaction{
+			SiacoinInputs: []types.SiacoinInput{{
+				ParentID:         co.id,
+				UnlockConditions: uc,
+			}},
+			SiacoinOutputs: []types.SiacoinOutput{{
+				Value:      co.output.Value,
+				UnlockHash: co.output.UnlockHash,
+			}},
+			MinerFees: []types.Currency{co.output.Value},
+			TransactionSignatures: []types.TransactionSignature{{
+				ParentID:      crypto.Hash(co.id),
+				CoveredFields: types.FullCoveredFields,
+				Signature:     make([]byte, crypto.SignatureSize),
+			}},
+		}
+		fee := feePerByte.Mul64(stuckSize + encodedSize([]types.Transaction{child}))
+		if fee.Cmp(paid) <= 0 {
+			break
+		}
+		increased = true
+		if childFee = fee.Sub(paid); co.output.Value.Cmp(childFee) > 0 {
+			break
+		}
+		child = types.Transaction{}
+	}
+	if !increased {
+		w.mu.Unlock()
+		return nil, errFeeNotIncreased
+	}
+	if len(child.SiacoinInputs) == 0 {
+		w.mu.Unlock()
+		return nil, errNoChangeOutput
+	}
+
+	// Send the remaining value to a new address of the wallet and sign the
+	// child.
+	uc, err := w.nextPrimarySeedAddress(w.dbTx)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, errors.AddContext(err, "failed to get address for child transaction")
+	}
+	parentID := types.OutputID(child.SiacoinInputs[0].ParentID)
+	defer func() {
+		if err != nil {
+			w.mu.Lock()
+			w.markAddressUnused(uc)
+			dbDeleteSpentOutput(w.dbTx, parentID)
+			w.mu.Unlock()
+		}
+	}()
+	child.SiacoinOutputs[0] = types.SiacoinOutput{
+		Value:      child.SiacoinOutputs[0].Value.Sub(childFee),
+		UnlockHash: uc.UnlockHash(),
+	}
+	child.MinerFees[0] = childFee
+	err = signTransaction(&child, w.keys, []crypto.Hash{child.TransactionSignatures[0].ParentID}, height)
+	if err != nil {
+		w.mu.Unlock()
+		return nil, errors.AddContext(err, "unable to sign child transaction")
+	}
+	// Mark the change output as spent to prevent the wallet from funding other
+	// transactions with it.
+	err = dbPutSpentOutput(w.dbTx, parentID, height)
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	set := append(stuck, child)
+	if err = w.tpool.AcceptTransactionSet(set); err != nil {
+		return nil, errors.AddContext(err, "unable to get child transaction accepted")
+	}
+	w.log.Printf("Bumped the fee of transaction %v with child %v paying %v", stuck[len(stuck)-1].ID(), child.ID(), childFee.HumanString())
+	return set, nil
+}
+
+// managedBumpFeeRBF replaces the stuck transaction set in the transaction pool
+// with a set which pays the fee that is missing for it to pay feePerByte from
+// one of its change outputs. The transaction pool might have merged the stuck
+// set with its dependents, so the fee is computed for the whole purged set.
+// The replaced transactions keep their inputs but their ids change, so every
+// transaction of the set that depends on them is signed again. Dependents the
+// wallet can't sign are dropped.
+func (w *Wallet) managedBumpFeeRBF(stuck []types.Transaction, feePerByte types.Currency) (_ []types.Transaction, err error) {
+	// Purge the set from the transaction pool and replace it. If the
+	// replacement fails, the original set is restored and the outputs marked
+	// as spent by the replacements are released.
+	txid := stuck[len(stuck)-1].ID()
+	set, exists := w.tpool.PurgeTransactionSet(txid)
+	if !exists {
+		return nil, errNotUnconfirmedTransaction
+	}
+	var marked []types.OutputID
+	defer func() {
+		if err == nil {
+			return
+		}
+		w.managedUnmarkSpentOutputs(marked)
+		if restoreErr := w.tpool.AcceptTransactionSet(set); restoreErr != nil {
+			err = errors.Compose(err, errors.AddContext(restoreErr, "unable to restore the original transaction set"))
+		}
+	}()
+
+	paid := minerFees(set)
+	fee := feePerByte.Mul64(encodedSize(set))
+	if fee.Cmp(paid) <= 0 {
+		return nil, errFeeNotIncreased
+	}
+	delta := fee.Sub(paid)
+
+	w.mu.RLock()
+	var payer changeOutput
+	var found bool
+	for _, co := range w.changeOutputs(set) {
+		if co.output.Value.Cmp(delta) > 0 {
+			payer, found = co, true
+			break
+		}
+	}
+	w.mu.RUnlock()
+	if !found {
+		return nil, errNoChangeOutput
+	}
+
+	payerID := set[payer.txnIndex].ID()
+	var replacement []types.Transaction
+	for {
+		// Release the outputs spent by the previous, insufficient replacement.
+		w.managedUnmarkSpentOutputs(marked)
+		replacement, marked, err = w.managedReplaceTransaction(set, payerID, payer.outputIndex, delta)
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to replace transaction")
+		}
+		// The encoding of the higher fee might increase the size of the set,
+		// which requires an even higher fee. Dropped dependents reduce both
+		// the size and the fees of the set.
+		fees, required := minerFees(replacement), feePerByte.Mul64(encodedSize(replacement))
+		if fees.Cmp(required) >= 0 {
+			break
+		}
+		delta = delta.Add(required.Sub(fees))
+		if payer.output.Value.Cmp(delta) <= 0 {
+			return nil, errNoChangeOutput
+		}
+	}
+	if err = w.tpool.AcceptTransactionSet(replacement); err != nil {
+		return nil, errors.AddContext(err, "unable to replace transaction")
+	}
+	w.log.Printf("Replaced transaction %v to pay an additional fee of %v, dropped %v dependent transactions", txid, delta.HumanString(), len(set)-len(replacement))
+	return replacement, nil
+}
+
+// managedUnmarkSpentOutputs releases outputs which were marked as spent by a
+// replacement that was never accepted.
+func (w *Wallet) managedUnmarkSpentOutputs(ids []types.OutputID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		if err := dbDeleteSpentOutput(w.dbTx, id); err != nil {
+			w.log.Println("WARN: failed to unmark spent output:", err)
+		}
+	}
+}
+
+// managedReplaceTransaction moves delta from an output of the transaction with
+// the id payerID to its miner fees and updates every transaction of the set
+// that spends outputs of replaced transactions. Every changed transaction is
+// signed again and its inputs are marked as spent. Changed transactions that
+// the wallet can't sign are dropped together with their own dependents. The
+// outputs that weren't marked as spent before are returned.
+func (w *Wallet) managedReplaceTransaction(set []types.Transaction, payerID types.TransactionID, outputIndex int, delta types.Currency) (_ []types.Transaction, marked []types.OutputID, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer func() {
+		if err != nil {
+			for _, id := range marked {
+				dbDeleteSpentOutput(w.dbTx, id)
+			}
+			marked = nil
+		}
+	}()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make(map[crypto.Hash]crypto.Hash)
+	dropped := make(map[crypto.Hash]struct{})
+	replacement := make([]types.Transaction, 0, len(set))
+	for _, orig := range set {
+		if dependsOn(orig, dropped) {
+			dropObjects(orig, dropped)
+			continue
+		}
+
+		txn := orig
+		txn.SiacoinInputs = append([]types.SiacoinInput(nil), orig.SiacoinInputs...)
+		txn.SiacoinOutputs = append([]types.SiacoinOutput(nil), orig.SiacoinOutputs...)
+		txn.FileContractRevisions = append([]types.FileContractRevision(nil), orig.FileContractRevisions...)
+		txn.StorageProofs = append([]types.StorageProof(nil), orig.StorageProofs...)
+		txn.SiafundInputs = append([]types.SiafundInput(nil), orig.SiafundInputs...)
+		txn.MinerFees = append([]types.Currency(nil), orig.MinerFees...)
+		txn.TransactionSignatures = append([]types.TransactionSignature(nil), orig.TransactionSignatures...)
+
+		var changed bool
+		isPayer := orig.ID() == payerID
+		if isPayer {
+			txn.SiacoinOutputs[outputIndex].Value = txn.SiacoinOutputs[outputIndex].Value.Sub(delta)
+			if len(txn.MinerFees) > 0 {
+				txn.MinerFees[0] = txn.MinerFees[0].Add(delta)
+			} else {
+				txn.MinerFees = []types.Currency{delta}
+			}
+			changed = true
+		}
+		for i := range txn.SiacoinInputs {
+			if id, exists := ids[crypto.Hash(txn.SiacoinInputs[i].ParentID)]; exists {
+				txn.SiacoinInputs[i].ParentID = types.SiacoinOutputID(id)
+				changed = true
+			}
+		}
+		for i := range txn.SiafundInputs {
+			if id, exists := ids[crypto.Hash(txn.SiafundInputs[i].ParentID)]; exists {
+				txn.SiafundInputs[i].ParentID = types.SiafundOutputID(id)
+				changed = true
+			}
+		}
+		for i := range txn.FileContractRevisions {
+			if id, exists := ids[crypto.Hash(txn.FileContractRevisions[i].ParentID)]; exists {
+				txn.FileContractRevisions[i].ParentID = types.FileContractID(id)
+				changed = true
+			}
+		}
+		for i := range txn.StorageProofs {
+			if id, exists := ids[crypto.Hash(txn.StorageProofs[i].ParentID)]; exists {
+				txn.StorageProofs[i].ParentID = types.FileContractID(id)
+				changed = true
+			}
+		}
+		for i := range txn.TransactionSignatures {
+			if id, exists := ids[txn.TransactionSignatures[i].ParentID]; exists {
+				txn.TransactionSignatures[i].ParentID = id
+			}
+		}
+		if !changed {
+			replacement = append(replacement, txn)
+			continue
+		}
+
+		// Sign the changed transaction again and remember the new ids of its
+		// outputs. A dependent that can't be signed is dropped since its
+		// inputs no longer exist.
+		toSign := make([]crypto.Hash, 0, len(txn.TransactionSignatures))
+		for _, sig := range txn.TransactionSignatures {
+			toSign = append(toSign, sig.ParentID)
+		}
+		if err := signTransaction(&txn, w.keys, toSign, height); err != nil && isPayer {
+			return nil, nil, errors.AddContext(err, fmt.Sprintf("unable to sign replacement of transaction %v", orig.ID()))
+		} else if err != nil {
+			dropObjects(orig, dropped)
+			continue
+		}
+		for _, sci := range txn.SiacoinInputs {
+			id := types.OutputID(sci.ParentID)
+			if _, err := dbGetSpentOutput(w.dbTx, id); err == nil {
+				continue
+			}
+			if err := dbPutSpentOutput(w.dbTx, id, height); err != nil {
+				return nil, nil, err
+			}
+			marked = append(marked, id)
+		}
+		for i := range orig.SiacoinOutputs {
+			ids[crypto.Hash(orig.SiacoinOutputID(uint64(i)))] = crypto.Hash(txn.SiacoinOutputID(uint64(i)))
+		}
+		for i := range orig.SiafundOutputs {
+			ids[crypto.Hash(orig.SiafundOutputID(uint64(i)))] = crypto.Hash(txn.SiafundOutputID(uint64(i)))
+		}
+		for i := range orig.FileContracts {
+			ids[crypto.Hash(orig.FileContractID(uint64(i)))] = crypto.Hash(txn.FileContractID(uint64(i)))
+		}
+		replacement = append(replacement, txn)
+	}
+	return replacement, marked, nil
+}
+
+// dependsOn returns whether a transaction spends or revises one of the given
+// objects.
+func dependsOn(txn types.Transaction, objects map[crypto.Hash]struct{}) bool {
+	for _, sci := range txn.SiacoinInputs {
+		if _, exists := objects[crypto.Hash(sci.ParentID)]; exists {
+			return true
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if _, exists := objects[crypto.Hash(sfi.ParentID)]; exists {
+			return true
+		}
+	}
+	for _, fcr := range txn.FileContractRevisions {
+		if _, exists := objects[crypto.Hash(fcr.ParentID)]; exists {
+			return true
+		}
+	}
+	for _, sp := range txn.StorageProofs {
+		if _, exists := objects[crypto.Hash(sp.ParentID)]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// dropObjects adds the ids of the objects a transaction creates to dropped.
+func dropObjects(txn types.Transaction, dropped map[crypto.Hash]struct{}) {
+	for i := range txn.SiacoinOutputs {
+		dropped[crypto.Hash(txn.SiacoinOutputID(uint64(i)))] = struct{}{}
+	}
+	for i := range txn.SiafundOutputs {
+		dropped[crypto.Hash(txn.SiafundOutputID(uint64(i)))] = struct{}{}
+	}
+	for i := range txn.FileContracts {
+		dropped[crypto.Hash(txn.FileContractID(uint64(i)))] = struct{}{}
+	}
+}
+
+// changeOutputs returns the outputs of a transaction set which are spendable
+// by the wallet and not spent by any unconfirmed transaction, starting with
+// the last transaction of the set. It must be called with the lock held.
+func (w *Wallet) changeOutputs(txns []types.Transaction) []changeOutput {
+	spent := make(map[types.OutputID]struct{})
+	for _, txn := range txns {
+		for _, sci := range txn.SiacoinInputs {
+			spent[types.OutputID(sci.ParentID)] = struct{}{}
+		}
+	}
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		for _, input := range pt.Inputs {
+			spent[input.ParentID] = struct{}{}
+		}
+	}
+
+	var outputs []changeOutput
+	for i := len(txns) - 1; i >= 0; i-- {
+		for j, sco := range txns[i].SiacoinOutputs {
+			id := txns[i].SiacoinOutputID(uint64(j))
+			if _, exists := spent[types.OutputID(id)]; exists {
+				continue
+			}
+			if _, exists := w.keys[sco.UnlockHash]; !exists {
+				continue
+			}
+			outputs = append(outputs, changeOutput{
+				txnIndex:    i,
+				outputIndex: j,
+				id:          id,
+				output:      sco,
+			})
+		}
+	}
+	return outputs
+}
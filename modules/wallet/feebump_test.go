@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestBumpTransactionFee tests bumping the fee of a stuck transaction with
+// both CPFP and RBF.
+func TestBumpTransactionFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	_, maxFee := wt.tpool.FeeEstimation()
+	feePerByte := maxFee.Mul64(10)
+
+	// send is a helper that sends coins to the void and returns the id of the
+	// transaction.
+	send := func() types.TransactionID {
+		txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockHash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txns[len(txns)-1].ID()
+	}
+
+	// Invalid bumps.
+	txid := send()
+	if _, err := wt.wallet.BumpTransactionFee(txid, "foo", feePerByte); err == nil {
+		t.Fatal("expected error for unknown method")
+	}
+	if _, err := wt.wallet.BumpTransactionFee(types.TransactionID{}, modules.FeeBumpCPFP, feePerByte); !errors.Contains(err, errNotUnconfirmedTransaction) {
+		t.Fatal("expected errNotUnconfirmedTransaction", err)
+	}
+	for _, method := range []modules.FeeBumpMethod{modules.FeeBumpCPFP, modules.FeeBumpRBF} {
+		if _, err := wt.wallet.BumpTransactionFee(txid, method, types.NewCurrency64(1)); !errors.Contains(err, errFeeNotIncreased) {
+			t.Fatal("expected errFeeNotIncreased", err)
+		}
+	}
+
+	// Bump the fee with a child.
+	set, err := wt.wallet.BumpTransactionFee(txid, modules.FeeBumpCPFP, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set[len(set)-2].ID() != txid {
+		t.Fatal("child doesn't follow the stuck transaction")
+	}
+	if minerFees(set).Cmp(feePerByte.Mul64(encodedSize(set))) < 0 {
+		t.Fatal("set doesn't pay the requested fee")
+	}
+	child := set[len(set)-1]
+	if _, _, exists := wt.tpool.Transaction(child.ID()); !exists {
+		t.Fatal("child isn't in the transaction pool")
+	}
+	if _, _, exists := wt.tpool.Transaction(txid); !exists {
+		t.Fatal("stuck transaction was removed from the transaction pool")
+	}
+
+	// Bump the fee of another transaction by replacing it.
+	txid = send()
+	set, err = wt.wallet.BumpTransactionFee(txid, modules.FeeBumpRBF, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replacement := set[len(set)-1]
+	if replacement.ID() == txid {
+		t.Fatal("transaction wasn't replaced")
+	}
+	if _, _, exists := wt.tpool.Transaction(txid); exists {
+		t.Fatal("stuck transaction is still in the transaction pool")
+	}
+	_, parents, exists := wt.tpool.Transaction(replacement.ID())
+	if !exists {
+		t.Fatal("replacement isn't in the transaction pool")
+	}
+	replaced := append(parents, replacement)
+	if minerFees(replaced).Cmp(feePerByte.Mul64(encodedSize(replaced))) < 0 {
+		t.Fatal("replacement doesn't pay the requested fee")
+	}
+	if !replacement.SiacoinOutputs[0].Value.Equals(types.SiacoinPrecision.Mul64(100)) {
+		t.Fatal("replacement changed the sent value", replacement.SiacoinOutputs[0].Value)
+	}
+	// The replaced transaction is no longer an unconfirmed wallet
+	// transaction.
+	if _, err := wt.wallet.BumpTransactionFee(txid, modules.FeeBumpRBF, feePerByte.Mul64(2)); !errors.Contains(err, errNotUnconfirmedTransaction) {
+		t.Fatal("expected errNotUnconfirmedTransaction", err)
+	}
+
+	// All transactions should be confirmed by the next block.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []types.TransactionID{child.ID(), replacement.ID()} {
+		if _, _, exists := wt.tpool.Transaction(id); exists {
+			t.Fatal("transaction wasn't confirmed", id)
+		}
+		if _, found, err := wt.wallet.Transaction(id); err != nil || !found {
+			t.Fatal("wallet doesn't know the confirmed transaction", id, err)
+		}
+	}
+}
+
+// TestBumpTransactionFeeWithChild tests replacing a stuck transaction which
+// already has an unconfirmed child.
+func TestBumpTransactionFeeWithChild(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	_, maxFee := wt.tpool.FeeEstimation()
+	feePerByte := maxFee.Mul64(10)
+
+	// Send coins and give the transaction a child.
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txid := txns[len(txns)-1].ID()
+	set, err := wt.wallet.BumpTransactionFee(txid, modules.FeeBumpCPFP, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := set[len(set)-1]
+
+	// Replacing the transaction with a fee its change can't pay restores the
+	// original set.
+	_, err = wt.wallet.BumpTransactionFee(txid, modules.FeeBumpRBF, types.SiacoinPrecision.Mul64(1e9))
+	if !errors.Contains(err, errNoChangeOutput) {
+		t.Fatal("expected errNoChangeOutput", err)
+	}
+	for _, id := range []types.TransactionID{txid, child.ID()} {
+		if _, _, exists := wt.tpool.Transaction(id); !exists {
+			t.Fatal("transaction wasn't restored", id)
+		}
+	}
+
+	// Replace the transaction. The fee has to cover the child as well.
+	feePerByte = feePerByte.Mul64(2)
+	set, err = wt.wallet.BumpTransactionFee(txid, modules.FeeBumpRBF, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set) != len(txns)+1 {
+		t.Fatalf("expected %v transactions but got %v", len(txns)+1, len(set))
+	}
+	if minerFees(set).Cmp(feePerByte.Mul64(encodedSize(set))) < 0 {
+		t.Fatal("replacement doesn't pay the requested fee")
+	}
+	for _, txn := range set {
+		if _, _, exists := wt.tpool.Transaction(txn.ID()); !exists {
+			t.Fatal("replacement isn't in the transaction pool", txn.ID())
+		}
+	}
+	if _, _, exists := wt.tpool.Transaction(child.ID()); exists {
+		t.Fatal("replaced child is still in the transaction pool")
+	}
+
+	// The replacements should be confirmed by the next block.
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	for _, txn := range set {
+		if _, found, err := wt.wallet.Transaction(txn.ID()); err != nil || !found {
+			t.Fatal("wallet doesn't know the confirmed transaction", txn.ID(), err)
+		}
+	}
+}
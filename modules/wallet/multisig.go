@@ -0,0 +1,336 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errNoMultisigKeys is returned when the wallet doesn't own any of the
+	// keys that are still missing a signature of a partially-signed
+	// transaction.
+	errNoMultisigKeys = errors.New("wallet doesn't own any of the keys that still need to sign the transaction")
+
+	// errNotMultisigAddress is returned when a transaction is created for an
+	// address that the wallet doesn't track as a multisig address.
+	errNotMultisigAddress = errors.New("address is not a multisig address tracked by the wallet")
+
+	// errPartialCoveredFields is returned when the wallet is asked to sign an
+	// input of a partially-signed transaction that doesn't require the whole
+	// transaction to be signed.
+	errPartialCoveredFields = errors.New("wallet only signs inputs that cover the whole transaction")
+)
+
+// isMultisig returns true if the unlock conditions belong to a multisig
+// address.
+func isMultisig(uc types.UnlockConditions) bool {
+	return len(uc.PublicKeys) > 1
+}
+
+// AddMultisigAddress creates an address that requires 'required' signatures of
+// the given public keys to be spent. The unlock conditions of the address are
+// stored in the wallet and the address is added to the watched addresses to
+// track its outputs.
+func (w *Wallet) AddMultisigAddress(keys []types.SiaPublicKey, required uint64, unused bool) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	uc, err := modules.NewMultisigUnlockConditions(keys, required)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	if !isMultisig(uc) {
+		return types.UnlockConditions{}, errors.New("a multisig address requires at least two public keys")
+	}
+	if err := w.AddUnlockConditions(uc); err != nil {
+		return types.UnlockConditions{}, errors.AddContext(err, "failed to store unlock conditions")
+	}
+	if err := w.AddWatchAddresses([]types.UnlockHash{uc.UnlockHash()}, unused); err != nil {
+		return types.UnlockConditions{}, errors.AddContext(err, "failed to watch multisig address")
+	}
+	return uc, nil
+}
+
+// MultisigAddresses returns the unlock conditions of the multisig addresses
+// tracked by the wallet, sorted by address.
+func (w *Wallet) MultisigAddresses() ([]types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var ucs []types.UnlockConditions
+	for addr := range w.watchedAddrs {
+		uc, err := dbGetUnlockConditions(w.dbTx, addr)
+		if err == nil && isMultisig(uc) {
+			ucs = append(ucs, uc)
+		}
+	}
+	sort.Slice(ucs, func(i, j int) bool {
+		return ucs[i].UnlockHash().String() < ucs[j].UnlockHash().String()
+	})
+	return ucs, nil
+}
+
+// CreateMultisigTransaction creates a partially-signed transaction which
+// funds the outputs and the transaction fee with the confirmed outputs of a
+// multisig address. The change is sent back to the multisig address. Every
+// input needs to be signed with FullCoveredFields. The outputs used to fund
+// the transaction are marked as spent to prevent them from being used by
+// another transaction until RespendTimeout blocks have passed.
+func (w *Wallet) CreateMultisigTransaction(addr types.UnlockHash, outputs []types.SiacoinOutput) (modules.PartiallySignedTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartiallySignedTransaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	if len(outputs) == 0 {
+		return modules.PartiallySignedTransaction{}, errors.New("transaction needs at least one output")
+	}
+	_, feePerByte := w.tpool.FeeEstimation()
+	var outputTotal types.Currency
+	for _, sco := range outputs {
+		outputTotal = outputTotal.Add(sco.Value)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	uc, err := w.multisigUnlockConditions(addr)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	if height < uc.Timelock {
+		return modules.PartiallySignedTransaction{}, errOutputTimelock
+	}
+
+	// Collect the outputs of the address that aren't spent by unconfirmed
+	// transactions and weren't used recently, largest first to keep the
+	// number of inputs and therefore the number of signatures low.
+	pending := make(map[types.OutputID]struct{})
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		for _, input := range pt.Inputs {
+			pending[input.ParentID] = struct{}{}
+		}
+	}
+	type output struct {
+		id    types.SiacoinOutputID
+		value types.Currency
+	}
+	var available []output
+	dbForEachSiacoinOutput(w.dbTx, func(id types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if _, spent := pending[types.OutputID(id)]; spent || sco.UnlockHash != addr {
+			return
+		}
+		if spendHeight, err := dbGetSpentOutput(w.dbTx, types.OutputID(id)); err == nil && spendHeight+RespendTimeout > height {
+			return
+		}
+		available = append(available, output{id: id, value: sco.Value})
+	})
+	sort.Slice(available, func(i, j int) bool {
+		return available[i].value.Cmp(available[j].value) > 0
+	})
+
+	// Fund the transaction. The fee depends on the size of the transaction
+	// including the change output and the signatures it still needs, so it
+	// is recomputed for every input that is added.
+	txn := types.Transaction{
+		SiacoinOutputs: append([]types.SiacoinOutput(nil), outputs...),
+	}
+	var funded, fee types.Currency
+	for _, o := range available {
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         o.id,
+			UnlockConditions: uc,
+		})
+		funded = funded.Add(o.value)
+		fee = feePerByte.Mul64(multisigTransactionSize(txn, addr, funded))
+		if funded.Cmp(outputTotal.Add(fee)) >= 0 {
+			break
+		}
+	}
+	total := outputTotal.Add(fee)
+	if len(txn.SiacoinInputs) == 0 || funded.Cmp(total) < 0 {
+		return modules.PartiallySignedTransaction{}, errors.AddContext(modules.ErrLowBalance, "multisig address can't fund the transaction")
+	}
+	txn.MinerFees = []types.Currency{fee}
+	if funded.Cmp(total) > 0 {
+		txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+			Value:      funded.Sub(total),
+			UnlockHash: addr,
+		})
+	}
+	pst, err := modules.NewPartiallySignedTransaction(txn, types.FullCoveredFields, height)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+
+	// Mark the inputs as spent.
+	for _, sci := range txn.SiacoinInputs {
+		if err := dbPutSpentOutput(w.dbTx, types.OutputID(sci.ParentID), height); err != nil {
+			return modules.PartiallySignedTransaction{}, err
+		}
+	}
+	return pst, nil
+}
+
+// multisigTransactionSize returns the size of a multisig transaction once all
+// of its inputs are signed. The size includes a miner fee and a change output
+// back to addr of at most the funded amount.
+func multisigTransactionSize(txn types.Transaction, addr types.UnlockHash, funded types.Currency) uint64 {
+	txn.MinerFees = []types.Currency{funded}
+	txn.SiacoinOutputs = append(append([]types.SiacoinOutput(nil), txn.SiacoinOutputs...), types.SiacoinOutput{
+		Value:      funded,
+		UnlockHash: addr,
+	})
+	for _, sci := range txn.SiacoinInputs {
+		for i := uint64(0); i < sci.UnlockConditions.SignaturesRequired; i++ {
+			txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+				ParentID:      crypto.Hash(sci.ParentID),
+				CoveredFields: types.FullCoveredFields,
+				Signature:     make([]byte, crypto.SignatureSize),
+			})
+		}
+	}
+	return uint64(txn.MarshalSiaSize())
+}
+
+// SignMultisigTransaction adds signatures to the inputs of a partially-signed
+// transaction that are still missing signatures, using the keys of the wallet
+// that didn't sign the inputs yet.
+func (w *Wallet) SignMultisigTransaction(pst modules.PartiallySignedTransaction) (modules.PartiallySignedTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartiallySignedTransaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.unlocked {
+		return modules.PartiallySignedTransaction{}, modules.ErrLockedWallet
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+
+	pst.Transaction.TransactionSignatures = append([]types.TransactionSignature(nil), pst.Transaction.TransactionSignatures...)
+	pst.Inputs = append([]modules.PartiallySignedInput(nil), pst.Inputs...)
+	if err := pst.UpdateInputs(height); err != nil {
+		return modules.PartiallySignedTransaction{}, errors.AddContext(err, "invalid partially-signed transaction")
+	}
+	// Only sign inputs that cover the whole transaction. Otherwise the
+	// parts of the transaction that aren't covered could be changed after
+	// the wallet signed it.
+	for _, input := range pst.Inputs {
+		if !bytes.Equal(encoding.Marshal(input.CoveredFields), encoding.Marshal(types.FullCoveredFields)) {
+			return modules.PartiallySignedTransaction{}, errPartialCoveredFields
+		}
+	}
+
+	// Find the secret keys of the unsigned keys.
+	unsigned := make(map[string]struct{})
+	for _, input := range pst.Inputs {
+		for _, key := range input.UnsignedKeys {
+			unsigned[key.String()] = struct{}{}
+		}
+	}
+	secretKeys := make(map[string]crypto.SecretKey)
+	for _, sk := range w.keys {
+		for _, key := range sk.SecretKeys {
+			pk := types.Ed25519PublicKey(key.PublicKey()).String()
+			if _, exists := unsigned[pk]; exists {
+				secretKeys[pk] = key
+			}
+		}
+	}
+	if len(secretKeys) == 0 {
+		return modules.PartiallySignedTransaction{}, errNoMultisigKeys
+	}
+
+	// Sign the inputs.
+	for _, input := range pst.Inputs {
+		uc, _ := pst.UnlockConditions(input.ParentID)
+		unsignedKeys := make(map[string]struct{}, len(input.UnsignedKeys))
+		for _, key := range input.UnsignedKeys {
+			unsignedKeys[key.String()] = struct{}{}
+		}
+		var added uint64
+		for i, key := range uc.PublicKeys {
+			if added == input.MissingSignatures {
+				break
+			}
+			if _, exists := unsignedKeys[key.String()]; !exists {
+				continue
+			}
+			sk, exists := secretKeys[key.String()]
+			if !exists {
+				continue
+			}
+			pst.Transaction.TransactionSignatures = append(pst.Transaction.TransactionSignatures, types.TransactionSignature{
+				ParentID:       input.ParentID,
+				PublicKeyIndex: uint64(i),
+				CoveredFields:  input.CoveredFields,
+			})
+			sigIndex := len(pst.Transaction.TransactionSignatures) - 1
+			sig := crypto.SignHash(pst.Transaction.SigHash(sigIndex, height), sk)
+			pst.Transaction.TransactionSignatures[sigIndex].Signature = sig[:]
+			added++
+		}
+	}
+	return pst, pst.UpdateInputs(height)
+}
+
+// BroadcastMultisigTransaction submits a partially-signed transaction which
+// collected all of its signatures to the transaction pool.
+func (w *Wallet) BroadcastMultisigTransaction(pst modules.PartiallySignedTransaction) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	height, err := w.Height()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if err := pst.UpdateInputs(height); err != nil {
+		return types.Transaction{}, errors.AddContext(err, "invalid partially-signed transaction")
+	}
+	if !pst.Complete() {
+		return types.Transaction{}, modules.ErrMultisigTransactionIncomplete
+	}
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{pst.Transaction})
+	if err != nil {
+		return types.Transaction{}, errors.AddContext(err, "unable to get transaction accepted")
+	}
+	w.log.Println("Submitted a multisig transaction:", pst.Transaction.ID())
+	return pst.Transaction, nil
+}
+
+// multisigUnlockConditions returns the unlock conditions of a multisig address
+// tracked by the wallet. It must be called with the lock held.
+func (w *Wallet) multisigUnlockConditions(addr types.UnlockHash) (types.UnlockConditions, error) {
+	if _, watched := w.watchedAddrs[addr]; !watched {
+		return types.UnlockConditions{}, errNotMultisigAddress
+	}
+	uc, err := dbGetUnlockConditions(w.dbTx, addr)
+	if err != nil || !isMultisig(uc) {
+		return types.UnlockConditions{}, errNotMultisigAddress
+	}
+	return uc, nil
+}
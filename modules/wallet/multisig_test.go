@@ -0,0 +1,181 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestMultisig tests creating a 2-of-3 multisig address with one key of the
+// wallet and spending its outputs with a partially-signed transaction.
+func TestMultisig(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a 2-of-3 address from a key of the wallet and two other keys.
+	walletUC, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk1, pk1 := crypto.GenerateKeyPair()
+	_, pk2 := crypto.GenerateKeyPair()
+	keys := []types.SiaPublicKey{walletUC.PublicKeys[0], types.Ed25519PublicKey(pk1), types.Ed25519PublicKey(pk2)}
+	uc, err := wt.wallet.AddMultisigAddress(keys, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := uc.UnlockHash()
+	ucs, err := wt.wallet.MultisigAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ucs) != 1 || ucs[0].UnlockHash() != addr {
+		t.Fatal("multisig address isn't tracked", ucs)
+	}
+
+	// A single key address is not a multisig address.
+	if _, err := wt.wallet.AddMultisigAddress(keys[:1], 1, true); err == nil {
+		t.Fatal("expected error for single key address")
+	}
+	if _, err := wt.wallet.CreateMultisigTransaction(walletUC.UnlockHash(), []types.SiacoinOutput{{Value: types.SiacoinPrecision}}); !errors.Contains(err, errNotMultisigAddress) {
+		t.Fatal("expected errNotMultisigAddress", err)
+	}
+
+	// Fund the multisig address.
+	funds := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(funds, addr); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Spending more than the address owns should fail.
+	if _, err := wt.wallet.CreateMultisigTransaction(addr, []types.SiacoinOutput{{Value: funds}}); !errors.Contains(err, modules.ErrLowBalance) {
+		t.Fatal("expected ErrLowBalance", err)
+	}
+
+	// Create a transaction which sends half of the funds to the void.
+	amount := funds.Div64(2)
+	pst, err := wt.wallet.CreateMultisigTransaction(addr, []types.SiacoinOutput{{Value: amount}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := pst.Transaction
+	if len(txn.SiacoinInputs) != 1 || len(txn.SiacoinOutputs) != 2 || len(txn.MinerFees) != 1 {
+		t.Fatal("unexpected transaction", txn)
+	}
+	change := funds.Sub(amount).Sub(txn.MinerFees[0])
+	if txn.SiacoinOutputs[1].UnlockHash != addr || !txn.SiacoinOutputs[1].Value.Equals(change) {
+		t.Fatal("wrong change output", txn.SiacoinOutputs[1])
+	}
+	if len(pst.Inputs) != 1 || pst.Inputs[0].MissingSignatures != 2 {
+		t.Fatal("wrong missing signatures", pst.Inputs)
+	}
+
+	// The fee should cover the size of the fully signed transaction.
+	_, feePerByte := wt.tpool.FeeEstimation()
+	if minFee := feePerByte.Mul64(uint64(txn.MarshalSiaSize())); txn.MinerFees[0].Cmp(minFee) < 0 {
+		t.Fatal("fee doesn't cover the size of the transaction", txn.MinerFees[0], minFee)
+	}
+
+	// The output funding the transaction is reserved, so another transaction
+	// can't be created.
+	if _, err := wt.wallet.CreateMultisigTransaction(addr, []types.SiacoinOutput{{Value: types.SiacoinPrecision}}); !errors.Contains(err, modules.ErrLowBalance) {
+		t.Fatal("expected ErrLowBalance", err)
+	}
+
+	// The wallet doesn't sign inputs that don't cover the whole transaction.
+	partial := pst
+	partial.Inputs = append([]modules.PartiallySignedInput(nil), pst.Inputs...)
+	partial.Inputs[0].CoveredFields = types.CoveredFields{SiacoinInputs: []uint64{0}}
+	if _, err := wt.wallet.SignMultisigTransaction(partial); !errors.Contains(err, errPartialCoveredFields) {
+		t.Fatal("expected errPartialCoveredFields", err)
+	}
+
+	// An unsigned transaction can't be broadcast.
+	if _, err := wt.wallet.BroadcastMultisigTransaction(pst); !errors.Contains(err, modules.ErrMultisigTransactionIncomplete) {
+		t.Fatal("expected ErrMultisigTransactionIncomplete", err)
+	}
+
+	// Sign with the wallet's key.
+	signed, err := wt.wallet.SignMultisigTransaction(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Inputs[0].MissingSignatures != 1 || len(signed.Transaction.TransactionSignatures) != 1 {
+		t.Fatal("wallet didn't sign", signed.Inputs)
+	}
+	if len(pst.Transaction.TransactionSignatures) != 0 {
+		t.Fatal("signing modified the original transaction")
+	}
+	// The wallet can't sign a second time.
+	if _, err := wt.wallet.SignMultisigTransaction(signed); !errors.Contains(err, errNoMultisigKeys) {
+		t.Fatal("expected errNoMultisigKeys", err)
+	}
+	if _, err := wt.wallet.BroadcastMultisigTransaction(signed); !errors.Contains(err, modules.ErrMultisigTransactionIncomplete) {
+		t.Fatal("expected ErrMultisigTransactionIncomplete", err)
+	}
+
+	// Sign a copy of the original with the second key and combine it with the
+	// wallet's signature.
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := pst
+	other.Transaction.TransactionSignatures = []types.TransactionSignature{{
+		ParentID:       pst.Inputs[0].ParentID,
+		PublicKeyIndex: 1,
+		CoveredFields:  types.FullCoveredFields,
+	}}
+	sig := crypto.SignHash(other.Transaction.SigHash(0, height), sk1)
+	other.Transaction.TransactionSignatures[0].Signature = sig[:]
+	combined, err := modules.CombinePartiallySignedTransactions([]modules.PartiallySignedTransaction{signed, other}, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !combined.Complete() {
+		t.Fatal("transaction should be complete", combined.Inputs)
+	}
+
+	// Broadcast the transaction and confirm it.
+	if _, err := wt.wallet.BroadcastMultisigTransaction(combined); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The multisig address should only own the change.
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var balance types.Currency
+	for _, o := range outputs {
+		if o.UnlockHash == addr {
+			balance = balance.Add(o.Value)
+			if !o.IsWatchOnly {
+				t.Error("multisig output should be watch-only")
+			}
+		}
+	}
+	if !balance.Equals(change) {
+		t.Fatalf("expected balance %v but was %v", change, balance)
+	}
+}
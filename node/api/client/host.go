@@ -68,6 +68,24 @@ const (
 	// HostParamCustomRegistryPath is the locataion of the host's registry on
 	// disk.
 	HostParamCustomRegistryPath = HostParam("customregistrypath")
+	// HostParamMaxClientBandwidth is the maximum bandwidth in bytes per
+	// second that a single client may use.
+	HostParamMaxClientBandwidth = HostParam("maxclientbandwidth")
+	// HostParamMaxClientProgramsPerMinute is the maximum number of MDM
+	// programs a single client may execute per minute.
+	HostParamMaxClientProgramsPerMinute = HostParam("maxclientprogramsperminute")
+	// HostParamMaxClientStreams is the maximum number of concurrent streams a
+	// single client may open.
+	HostParamMaxClientStreams = HostParam("maxclientstreams")
+	// HostParamMDMTraceSampleRate is the fraction of MDM programs the host
+	// traces.
+	HostParamMDMTraceSampleRate = HostParam("mdmtracesamplerate")
+	// HostParamMDMTraceFailures indicates whether the host keeps the traces
+	// of all failed MDM programs.
+	HostParamMDMTraceFailures = HostParam("mdmtracefailures")
+	// HostParamSectorScrubRate is the rate in bytes per second at which the
+	// host re-reads its stored sectors to detect corruption.
+	HostParamSectorScrubRate = HostParam("sectorscrubrate")
 )
 
 // HostAnnouncePost uses the /host/announce endpoint to announce the host to
@@ -77,6 +95,32 @@ func (c *Client) HostAnnouncePost() (err error) {
 	return
 }
 
+// HostAccountsGet uses the /host/accounts endpoint to get the state of the
+// host's ephemeral accounts.
+func (c *Client) HostAccountsGet() (hag api.HostAccountsGET, err error) {
+	err = c.get("/host/accounts", &hag)
+	return
+}
+
+// HostAccountsRefundPost uses the /host/accounts/refund endpoint to deposit the
+// amount into the ephemeral account with given id.
+func (c *Client) HostAccountsRefundPost(id modules.AccountID, amount types.Currency) (err error) {
+	values := url.Values{}
+	values.Set("account", id.SPK().String())
+	values.Set("amount", amount.String())
+	err = c.post("/host/accounts/refund", values.Encode(), nil)
+	return
+}
+
+// HostAccountsZeroPost uses the /host/accounts/zero endpoint to set the
+// balance of the ephemeral account with given id to zero.
+func (c *Client) HostAccountsZeroPost(id modules.AccountID) (hazp api.HostAccountsZeroPOST, err error) {
+	values := url.Values{}
+	values.Set("account", id.SPK().String())
+	err = c.post("/host/accounts/zero", values.Encode(), &hazp)
+	return
+}
+
 // HostAnnounceAddrPost uses the /host/anounce endpoint to announce the host to
 // the network using the provided address.
 func (c *Client) HostAnnounceAddrPost(address modules.NetAddress) (err error) {
@@ -84,6 +128,17 @@ func (c *Client) HostAnnounceAddrPost(address modules.NetAddress) (err error) {
 	return
 }
 
+// HostClientsGet uses the /host/clients endpoint to get the resource usage of
+// the host's clients. A limit of 0 returns all clients.
+func (c *Client) HostClientsGet(limit int) (hcg api.HostClientsGET, err error) {
+	query := "/host/clients"
+	if limit > 0 {
+		query += fmt.Sprintf("?limit=%v", limit)
+	}
+	err = c.get(query, &hcg)
+	return
+}
+
 // HostContractInfoGet uses the /host/contracts endpoint to get information
 // about contracts on the host.
 func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
@@ -98,6 +153,15 @@ func (c *Client) HostContractGet(obligationID types.FileContractID) (cg api.Host
 	return
 }
 
+// HostContractForecastGet uses the /host/contracts/forecast endpoint to get a
+// forecast of the host's storage proofs over the given number of blocks.
+func (c *Client) HostContractForecastGet(blocks types.BlockHeight) (hcfg api.HostContractForecastGET, err error) {
+	values := url.Values{}
+	values.Set("blocks", fmt.Sprint(blocks))
+	err = c.get("/host/contracts/forecast?"+values.Encode(), &hcfg)
+	return
+}
+
 // HostEstimateScoreGet requests the /host/estimatescore endpoint.
 func (c *Client) HostEstimateScoreGet(param, value string) (eg api.HostEstimateScoreGET, err error) {
 	err = c.get(fmt.Sprintf("/host/estimatescore?%v=%v", param, value), &eg)
@@ -123,6 +187,167 @@ func (c *Client) HostBandwidthGet() (gbg api.GatewayBandwidthGET, err error) {
 	return
 }
 
+// HostMDMTracesGet uses the /host/mdm/traces endpoint to get the most recent
+// execution traces of MDM programs. The traces can be filtered by the renter
+// key or the ephemeral account that paid for the program. A limit of 0 returns
+// all traces.
+func (c *Client) HostMDMTracesGet(renterKey *types.SiaPublicKey, account *modules.AccountID, limit int) (hmtg api.HostMDMTracesGET, err error) {
+	values := url.Values{}
+	if renterKey != nil {
+		values.Set("renterkey", renterKey.String())
+	}
+	if account != nil {
+		values.Set("account", account.SPK().String())
+	}
+	if limit > 0 {
+		values.Set("limit", fmt.Sprint(limit))
+	}
+	err = c.get("/host/mdm/traces?"+values.Encode(), &hmtg)
+	return
+}
+
+// HostMetricsGet requests the /host/metrics endpoint.
+func (c *Client) HostMetricsGet() (hmg api.HostMetricsGET, err error) {
+	err = c.get("/host/metrics", &hmg)
+	return
+}
+
+// HostMetricsPrometheusGet requests the /host/metrics/prometheus endpoint and
+// returns the host's metrics in the Prometheus text exposition format.
+func (c *Client) HostMetricsPrometheusGet() (string, error) {
+	_, resp, err := c.getRawResponse("/host/metrics/prometheus")
+	return string(resp), err
+}
+
+// HostDecommissionGet requests the /host/decommission endpoint.
+func (c *Client) HostDecommissionGet() (hdg api.HostDecommissionGET, err error) {
+	err = c.get("/host/decommission", &hdg)
+	return
+}
+
+// HostDecommissionPost uses the /host/decommission endpoint to start the
+// decommissioning of the host.
+func (c *Client) HostDecommissionPost() (err error) {
+	err = c.post("/host/decommission", "", nil)
+	return
+}
+
+// HostDecommissionCancelPost uses the /host/decommission endpoint to cancel the
+// decommissioning of the host.
+func (c *Client) HostDecommissionCancelPost() (err error) {
+	values := url.Values{}
+	values.Set("cancel", "true")
+	err = c.post("/host/decommission", values.Encode(), nil)
+	return
+}
+
+// HostPricingGet requests the /host/pricing endpoint.
+func (c *Client) HostPricingGet() (hpg api.HostPricingGET, err error) {
+	err = c.get("/host/pricing", &hpg)
+	return
+}
+
+// HostPricingPost uses the /host/pricing endpoint to set the policy of the
+// host's pricing engine.
+func (c *Client) HostPricingPost(policy modules.HostPricingPolicy) (err error) {
+	values := url.Values{}
+	values.Set("enabled", strconv.FormatBool(policy.Enabled))
+	values.Set("exchangerate", policy.ExchangeRate)
+	values.Set("targetstorageprice", strconv.FormatFloat(policy.TargetStoragePrice, 'g', -1, 64))
+	values.Set("targetdownloadbandwidthprice", strconv.FormatFloat(policy.TargetDownloadBandwidthPrice, 'g', -1, 64))
+	values.Set("targetuploadbandwidthprice", strconv.FormatFloat(policy.TargetUploadBandwidthPrice, 'g', -1, 64))
+	values.Set("maxbandwidth", strconv.FormatUint(policy.MaxBandwidth, 10))
+	values.Set("storagepricefloor", policy.StoragePriceFloor.String())
+	values.Set("storagepriceceiling", policy.StoragePriceCeiling.String())
+	values.Set("downloadbandwidthpricefloor", policy.DownloadBandwidthPriceFloor.String())
+	values.Set("downloadbandwidthpriceceiling", policy.DownloadBandwidthPriceCeiling.String())
+	values.Set("uploadbandwidthpricefloor", policy.UploadBandwidthPriceFloor.String())
+	values.Set("uploadbandwidthpriceceiling", policy.UploadBandwidthPriceCeiling.String())
+	err = c.post("/host/pricing", values.Encode(), nil)
+	return
+}
+
+// HostPricingModifyPost uses the /host/pricing endpoint to change a single
+// field of the host's pricing policy.
+func (c *Client) HostPricingModifyPost(param, value string) (err error) {
+	values := url.Values{}
+	values.Set(param, value)
+	err = c.post("/host/pricing", values.Encode(), nil)
+	return
+}
+
+// HostRegistryGet uses the /host/registry endpoint to get up to 'limit'
+// entries of the host's registry, starting at 'offset'. A limit of 0 returns
+// all entries.
+func (c *Client) HostRegistryGet(offset, limit uint64) (hrg api.HostRegistryGET, err error) {
+	values := url.Values{}
+	values.Set("offset", strconv.FormatUint(offset, 10))
+	values.Set("limit", strconv.FormatUint(limit, 10))
+	err = c.get("/host/registry?"+values.Encode(), &hrg)
+	return
+}
+
+// HostRegistryEntryGet uses the /host/registry/entry endpoint to get the entry
+// of the host's registry with the given public key and tweak.
+func (c *Client) HostRegistryEntryGet(spk types.SiaPublicKey, tweak crypto.Hash) (hreg api.HostRegistryEntryGET, err error) {
+	values := url.Values{}
+	values.Set("publickey", spk.String())
+	values.Set("tweak", tweak.String())
+	err = c.get("/host/registry/entry?"+values.Encode(), &hreg)
+	return
+}
+
+// HostRegistryEntryIDGet uses the /host/registry/entry endpoint to get the
+// entry of the host's registry with the given entry id.
+func (c *Client) HostRegistryEntryIDGet(sid modules.RegistryEntryID) (hreg api.HostRegistryEntryGET, err error) {
+	values := url.Values{}
+	values.Set("entryid", crypto.Hash(sid).String())
+	err = c.get("/host/registry/entry?"+values.Encode(), &hreg)
+	return
+}
+
+// HostRegistryExportPost uses the /host/registry/export endpoint to export the
+// host's registry to a file at the given path.
+func (c *Client) HostRegistryExportPost(path string) (hrep api.HostRegistryExportPOST, err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	err = c.post("/host/registry/export", values.Encode(), &hrep)
+	return
+}
+
+// HostRegistryImportPost uses the /host/registry/import endpoint to import the
+// entries of an exported registry into the host's registry.
+func (c *Client) HostRegistryImportPost(path string) (hrip api.HostRegistryImportPOST, err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	err = c.post("/host/registry/import", values.Encode(), &hrip)
+	return
+}
+
+// HostRegistryPrunePost uses the /host/registry/prune endpoint to delete the
+// expired entries of the host's registry.
+func (c *Client) HostRegistryPrunePost() (hrpp api.HostRegistryPrunePOST, err error) {
+	err = c.post("/host/registry/prune", "", &hrpp)
+	return
+}
+
+// HostStorageCacheAddPost uses the /host/storage/cache/add api endpoint to
+// designate a folder as the host's cache folder.
+func (c *Client) HostStorageCacheAddPost(path string, size uint64) (err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	values.Set("size", strconv.FormatUint(size, 10))
+	err = c.post("/host/storage/cache/add", values.Encode(), nil)
+	return
+}
+
+// HostStorageCacheRemovePost uses the /host/storage/cache/remove api endpoint
+// to remove the host's cache folder.
+func (c *Client) HostStorageCacheRemovePost() (err error) {
+	err = c.post("/host/storage/cache/remove", "", nil)
+	return
+}
+
 // HostStorageFoldersAddPost uses the /host/storage/folders/add api endpoint to
 // add a storage folder to a host
 func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error) {
@@ -133,6 +358,32 @@ func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error)
 	return
 }
 
+// HostStorageFoldersRebalancePost uses the /host/storage/folders/rebalance
+// api endpoint to start a rebalance of the host's storage folders. The storage
+// folders with the provided paths are evacuated. If no paths are provided, the
+// utilisation of the storage folders is evened out instead.
+func (c *Client) HostStorageFoldersRebalancePost(evacuate []string, rate uint64) (err error) {
+	values := url.Values{}
+	for _, path := range evacuate {
+		values.Add("evacuate", path)
+	}
+	if rate > 0 {
+		values.Set("rate", strconv.FormatUint(rate, 10))
+	}
+	err = c.post("/host/storage/folders/rebalance", values.Encode(), nil)
+	return
+}
+
+// HostStorageFoldersRebalanceCancelPost uses the
+// /host/storage/folders/rebalance api endpoint to cancel the ongoing rebalance
+// of the host's storage folders.
+func (c *Client) HostStorageFoldersRebalanceCancelPost() (err error) {
+	values := url.Values{}
+	values.Set("cancel", "true")
+	err = c.post("/host/storage/folders/rebalance", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersRemovePost uses the /host/storage/folders/remove api
 // endpoint to remove a storage folder from a host.
 func (c *Client) HostStorageFoldersRemovePost(path string, force bool) (err error) {
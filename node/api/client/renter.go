@@ -188,6 +188,20 @@ func (c *Client) RenterContractsGet() (rc api.RenterContracts, err error) {
 	return
 }
 
+// RenterContractsLedgerGet requests the /renter/contracts/ledger resource and
+// returns the spending ledger entries within the time range. If the host is
+// empty, the entries of all hosts are returned.
+func (c *Client) RenterContractsLedgerGet(start, end time.Time, host types.SiaPublicKey) (rcl api.RenterContractsLedger, err error) {
+	values := url.Values{}
+	values.Set("start", strconv.FormatInt(start.Unix(), 10))
+	values.Set("end", strconv.FormatInt(end.Unix(), 10))
+	if host.Key != nil {
+		values.Set("host", host.String())
+	}
+	err = c.get("/renter/contracts/ledger?"+values.Encode(), &rcl)
+	return
+}
+
 // RenterContractStatus requests the /watchdog/contractstatus resource and returns
 // the status of a contract.
 func (c *Client) RenterContractStatus(fcID types.FileContractID) (status modules.ContractWatchStatus, err error) {
@@ -197,6 +211,13 @@ func (c *Client) RenterContractStatus(fcID types.FileContractID) (status modules
 	return
 }
 
+// RenterContractStatusesGet requests the /renter/contractstatus resource
+// without an id and returns the status of all contracts in the watchdog.
+func (c *Client) RenterContractStatusesGet() (rcs api.RenterContractStatuses, err error) {
+	err = c.get("/renter/contractstatus", &rcs)
+	return
+}
+
 // RenterDisabledContractsGet requests the /renter/contracts resource with the
 // disabled flag set to true
 func (c *Client) RenterDisabledContractsGet() (rc api.RenterContracts, err error) {
@@ -229,6 +250,19 @@ func (c *Client) RenterContractRecoveryProgressGet() (rrs api.RenterRecoveryStat
 	return
 }
 
+// RenterRecoveryPost starts recovering the renter's contracts and metadata
+// from the blockchain and the backups stored on its hosts.
+func (c *Client) RenterRecoveryPost() (err error) {
+	err = c.post("/renter/recovery", "", nil)
+	return
+}
+
+// RenterRecoveryGet returns the progress of the renter recovery.
+func (c *Client) RenterRecoveryGet() (rrs modules.RenterRecoveryStatus, err error) {
+	err = c.get("/renter/recovery", &rrs)
+	return
+}
+
 // RenterExpiredContractsGet requests the /renter/contracts resource with the
 // expired flag set to true
 func (c *Client) RenterExpiredContractsGet() (rc api.RenterContracts, err error) {
@@ -525,6 +559,18 @@ func (c *Client) RenterSetCheckIPViolationPost(enabled bool) (err error) {
 	return
 }
 
+// RenterSetBackupSchedulePost uses the /renter endpoint to set the schedule
+// and retention policy of the renter's automatic snapshot backups.
+func (c *Client) RenterSetBackupSchedulePost(schedule modules.BackupSchedule) (err error) {
+	values := url.Values{}
+	values.Set("backupinterval", fmt.Sprint(uint64(schedule.Interval.Seconds())))
+	values.Set("backupkeeplast", fmt.Sprint(schedule.KeepLast))
+	values.Set("backupkeepdaily", fmt.Sprint(schedule.KeepDaily))
+	values.Set("backupkeepweekly", fmt.Sprint(schedule.KeepWeekly))
+	err = c.post("/renter", values.Encode(), nil)
+	return
+}
+
 // RenterStreamGet uses the /renter/stream endpoint to download data as a
 // stream.
 func (c *Client) RenterStreamGet(siaPath modules.SiaPath, disableLocalFetch, root bool) (resp []byte, err error) {
@@ -763,6 +809,13 @@ func (c *Client) RenterWorkersGet() (wps modules.WorkerPoolStatus, err error) {
 	return
 }
 
+// RenterWorkersEstimatesGet uses the /renter/workers/estimates endpoint to
+// compare the renter's local program estimates with the hosts' dry runs.
+func (c *Client) RenterWorkersEstimatesGet() (rweg api.RenterWorkersEstimatesGET, err error) {
+	err = c.get("/renter/workers/estimates", &rweg)
+	return
+}
+
 // RenterBubblePost uses the /renter/bubble endpoint to manually trigger an
 // update to the directories metadata.
 func (c *Client) RenterBubblePost(siaPath modules.SiaPath, force, recursive bool) (err error) {
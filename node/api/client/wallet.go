@@ -128,6 +128,84 @@ func (c *Client) WalletSeedsGet() (wsg api.WalletSeedsGET, err error) {
 	return
 }
 
+// WalletMultisigGet requests the /wallet/multisig endpoint and returns the
+// multisig addresses tracked by the wallet.
+func (c *Client) WalletMultisigGet() (wmg api.WalletMultisigGET, err error) {
+	err = c.get("/wallet/multisig", &wmg)
+	return
+}
+
+// WalletMultisigPost uses the /wallet/multisig endpoint to create a multisig
+// address which requires 'required' signatures of the given keys. The unused
+// flag should be set to true if the address has never appeared in the
+// blockchain.
+func (c *Client) WalletMultisigPost(keys []types.SiaPublicKey, required uint64, unused bool) (wma api.WalletMultisigAddress, err error) {
+	json, err := json.Marshal(api.WalletMultisigPOSTParams{
+		PublicKeys:         keys,
+		SignaturesRequired: required,
+		Unused:             unused,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig", string(json), &wma)
+	return
+}
+
+// WalletMultisigBroadcastPost uses the /wallet/multisig/broadcast endpoint to
+// broadcast a fully signed multisig transaction.
+func (c *Client) WalletMultisigBroadcastPost(pst modules.PartiallySignedTransaction) (wmbp api.WalletMultisigBroadcastPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigTransactionPOST{
+		Transaction: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/broadcast", string(json), &wmbp)
+	return
+}
+
+// WalletMultisigCombinePost uses the /wallet/multisig/combine endpoint to
+// merge the signatures of multiple partially-signed transactions.
+func (c *Client) WalletMultisigCombinePost(psts []modules.PartiallySignedTransaction) (wmtp api.WalletMultisigTransactionPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigCombinePOSTParams{
+		Transactions: psts,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/combine", string(json), &wmtp)
+	return
+}
+
+// WalletMultisigCreatePost uses the /wallet/multisig/create endpoint to create
+// a partially-signed transaction which sends the outputs from a multisig
+// address.
+func (c *Client) WalletMultisigCreatePost(addr types.UnlockHash, outputs []types.SiacoinOutput) (wmtp api.WalletMultisigTransactionPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigCreatePOSTParams{
+		Address: addr,
+		Outputs: outputs,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/create", string(json), &wmtp)
+	return
+}
+
+// WalletMultisigSignPost uses the /wallet/multisig/sign endpoint to add the
+// wallet's signatures to a partially-signed transaction.
+func (c *Client) WalletMultisigSignPost(pst modules.PartiallySignedTransaction) (wmtp api.WalletMultisigTransactionPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigTransactionPOST{
+		Transaction: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/sign", string(json), &wmtp)
+	return
+}
+
 // WalletSiacoinsMultiPost uses the /wallet/siacoin api endpoint to send money
 // to multiple addresses at once
 func (c *Client) WalletSiacoinsMultiPost(outputs []types.SiacoinOutput) (wsp api.WalletSiacoinsPOST, err error) {
@@ -202,6 +280,19 @@ func (c *Client) WalletTransactionsGet(startHeight types.BlockHeight, endHeight
 	return
 }
 
+// WalletTransactionBumpPost uses the /wallet/transaction/:id/bump endpoint to
+// bump the fee of an unconfirmed transaction. A zero feePerByte uses the
+// maximum recommended fee of the transaction pool.
+func (c *Client) WalletTransactionBumpPost(id types.TransactionID, method modules.FeeBumpMethod, feePerByte types.Currency) (wtbp api.WalletTransactionBumpPOST, err error) {
+	values := url.Values{}
+	values.Set("method", string(method))
+	if !feePerByte.IsZero() {
+		values.Set("feeperbyte", feePerByte.String())
+	}
+	err = c.post(fmt.Sprintf("/wallet/transaction/%v/bump", id), values.Encode(), &wtbp)
+	return
+}
+
 // WalletTransactionGet requests the /wallet/transaction/:id api resource for a
 // certain TransactionID.
 func (c *Client) WalletTransactionGet(id types.TransactionID) (wtg api.WalletTransactionGETid, err error) {
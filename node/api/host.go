@@ -4,11 +4,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 
+	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
 )
@@ -41,16 +44,44 @@ type (
 		Contracts []modules.StorageObligation `json:"contracts"`
 	}
 
+	// HostAccountsGET contains the information that is returned after a GET
+	// request to /host/accounts - the state of the host's ephemeral accounts
+	// and the host's aggregate risk.
+	HostAccountsGET struct {
+		modules.HostAccounts
+	}
+
+	// HostAccountsZeroPOST contains the information that is returned after a
+	// POST request to /host/accounts/zero - the balance the account had before
+	// it was zeroed.
+	HostAccountsZeroPOST struct {
+		Balance types.Currency `json:"balance"`
+	}
+
+	// HostClientsGET contains the information that is returned after a GET
+	// request to /host/clients - the resource usage of the host's clients.
+	HostClientsGET struct {
+		Clients []modules.HostClient `json:"clients"`
+	}
+
 	// HostContractGET contains information about the storage contract returned
 	// by a GET request to /host/contracts/:id
 	HostContractGET struct {
 		Contract modules.StorageObligation `json:"contract"`
 	}
 
+	// HostContractForecastGET contains the information that is returned after
+	// a GET request to /host/contracts/forecast - the host's upcoming proof
+	// windows and the expected revenue and collateral at risk.
+	HostContractForecastGET struct {
+		modules.HostContractForecast
+	}
+
 	// HostGET contains the information that is returned after a GET request to
 	// /host - a bunch of information about the status of the host.
 	HostGET struct {
 		ConnectabilityStatus modules.HostConnectabilityStatus `json:"connectabilitystatus"`
+		DecommissionStatus   modules.HostDecommissionStatus   `json:"decommissionstatus"`
 		ExternalSettings     modules.HostExternalSettings     `json:"externalsettings"`
 		FinancialMetrics     modules.HostFinancialMetrics     `json:"financialmetrics"`
 		InternalSettings     modules.HostInternalSettings     `json:"internalsettings"`
@@ -60,6 +91,13 @@ type (
 		WorkingStatus        modules.HostWorkingStatus        `json:"workingstatus"`
 	}
 
+	// HostDecommissionGET contains the information that is returned after a
+	// GET request to /host/decommission - the progress of the host's
+	// decommissioning.
+	HostDecommissionGET struct {
+		modules.HostDecommissionStatus
+	}
+
 	// HostEstimateScoreGET contains the information that is returned from a
 	// /host/estimatescore call.
 	HostEstimateScoreGET struct {
@@ -67,10 +105,66 @@ type (
 		ConversionRate float64        `json:"conversionrate"`
 	}
 
+	// HostMDMTracesGET contains the information that is returned after a GET
+	// request to /host/mdm/traces - the most recent execution traces of MDM
+	// programs, starting with the most recent one.
+	HostMDMTracesGET struct {
+		Traces []modules.HostMDMTrace `json:"traces"`
+	}
+
+	// HostMetricsGET contains the information that is returned after a GET
+	// request to /host/metrics - the detailed metrics of the RPCs and MDM
+	// instructions handled by the host.
+	HostMetricsGET struct {
+		Instructions []modules.HostInstructionMetrics `json:"instructions"`
+		RPCs         []modules.HostRPCMetrics         `json:"rpcs"`
+	}
+
+	// HostRegistryGET contains the information that is returned after a GET
+	// request to /host/registry - a page of the entries of the host's registry.
+	HostRegistryGET struct {
+		Entries []modules.HostRegistryEntry `json:"entries"`
+		Total   uint64                      `json:"total"`
+	}
+
+	// HostRegistryEntryGET contains the information that is returned after a
+	// GET request to /host/registry/entry - a single entry of the host's
+	// registry.
+	HostRegistryEntryGET struct {
+		modules.HostRegistryEntry
+	}
+
+	// HostRegistryExportPOST contains the information that is returned after
+	// a POST request to /host/registry/export.
+	HostRegistryExportPOST struct {
+		Exported uint64 `json:"exported"`
+	}
+
+	// HostRegistryImportPOST contains the information that is returned after
+	// a POST request to /host/registry/import.
+	HostRegistryImportPOST struct {
+		Imported uint64 `json:"imported"`
+	}
+
+	// HostRegistryPrunePOST contains the information that is returned after a
+	// POST request to /host/registry/prune.
+	HostRegistryPrunePOST struct {
+		Pruned uint64 `json:"pruned"`
+	}
+
+	// HostPricingGET contains the information that is returned after a GET
+	// request to /host/pricing - the policy of the host's pricing engine and
+	// the most recent price changes it made.
+	HostPricingGET struct {
+		Changes []modules.HostPriceChange `json:"changes"`
+		Policy  modules.HostPricingPolicy `json:"policy"`
+	}
+
 	// StorageGET contains the information that is returned after a GET request
 	// to /host/storage - a bunch of information about the status of storage
 	// management on the host.
 	StorageGET struct {
+		Cache   modules.CacheFolderMetadata     `json:"cache"`
 		Folders []modules.StorageFolderMetadata `json:"folders"`
 	}
 )
@@ -84,26 +178,89 @@ func RegisterRoutesHost(router *httprouter.Router, h modules.Host, deps modules.
 	router.POST("/host", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostHandlerPOST(h, w, req, ps)
 	}, requiredPassword))
+	router.GET("/host/accounts", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostAccountsHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/accounts/refund", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostAccountsRefundHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/accounts/zero", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostAccountsZeroHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/announce", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostAnnounceHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.GET("/host/clients", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostClientsHandlerGET(h, w, req, ps)
+	})
 	router.GET("/host/contracts", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostContractInfoHandler(h, w, req, ps)
 	})
 	router.GET("/host/contracts/:contractID", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		// httprouter doesn't allow for a static route next to the contract id
+		// so the forecast is served from here.
+		if ps.ByName("contractID") == "forecast" {
+			hostContractForecastHandlerGET(h, w, req, ps)
+			return
+		}
 		hostContractGetHandler(h, w, req, ps)
 	})
+	router.GET("/host/decommission", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostDecommissionHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/decommission", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostDecommissionHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.GET("/host/bandwidth", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostBandwidthHandlerGET(h, w, req, ps)
 	})
+	router.GET("/host/mdm/traces", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostMDMTracesHandlerGET(h, w, req, ps)
+	})
+	router.GET("/host/metrics", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostMetricsHandlerGET(h, w, req, ps)
+	})
+	router.GET("/host/metrics/prometheus", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostMetricsPrometheusHandlerGET(h, w, req, ps)
+	})
+	router.GET("/host/pricing", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostPricingHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/pricing", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostPricingHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 
 	// Calls pertaining to the storage manager that the host uses.
+	router.GET("/host/registry", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryHandlerGET(h, w, req, ps)
+	})
+	router.GET("/host/registry/entry", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryEntryHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/registry/export", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryExportHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/registry/import", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryImportHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/registry/prune", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryPruneHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.GET("/host/storage", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageHandler(h, w, req, ps)
 	})
+	router.POST("/host/storage/cache/add", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageCacheAddHandler(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/storage/cache/remove", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageCacheRemoveHandler(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/folders/add", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersAddHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.POST("/host/storage/folders/rebalance", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageFoldersRebalanceHandler(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/folders/remove", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersRemoveHandler(h, w, req, ps)
 	}, requiredPassword))
@@ -150,6 +307,223 @@ func hostContractGetHandler(host modules.Host, w http.ResponseWriter, _ *http.Re
 	})
 }
 
+// hostAccountsHandlerGET handles the API call to get the state of the host's
+// ephemeral accounts.
+func hostAccountsHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostAccountsGET{host.Accounts()})
+}
+
+// hostAccountsRefundHandlerPOST handles the API call to refund an amount to an
+// ephemeral account on the host.
+func hostAccountsRefundHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var id modules.AccountID
+	if err := id.LoadString(req.FormValue("account")); err != nil {
+		WriteError(w, Error{"unable to parse account: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	amount, ok := scanAmount(req.FormValue("amount"))
+	if !ok || amount.IsZero() {
+		WriteError(w, Error{"unable to parse amount"}, http.StatusBadRequest)
+		return
+	}
+	err := host.RefundAccount(id, amount)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostAccountsZeroHandlerPOST handles the API call to set the balance of an
+// ephemeral account on the host to zero.
+func hostAccountsZeroHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var id modules.AccountID
+	if err := id.LoadString(req.FormValue("account")); err != nil {
+		WriteError(w, Error{"unable to parse account: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	balance, err := host.ZeroAccount(id)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostAccountsZeroPOST{
+		Balance: balance,
+	})
+}
+
+// hostClientsHandlerGET handles the API call to get the resource usage of the
+// host's clients, sorted by the amount of bandwidth they used.
+func hostClientsHandlerGET(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	clients := host.Clients()
+	if req.FormValue("limit") != "" {
+		var limit int
+		_, err := fmt.Sscan(req.FormValue("limit"), &limit)
+		if err != nil || limit < 0 {
+			WriteError(w, Error{"unable to parse limit"}, http.StatusBadRequest)
+			return
+		}
+		if limit < len(clients) {
+			clients = clients[:limit]
+		}
+	}
+	WriteJSON(w, HostClientsGET{
+		Clients: clients,
+	})
+}
+
+// hostRegistryHandlerGET handles the API call to page through the entries of
+// the host's registry.
+func hostRegistryHandlerGET(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var offset, limit uint64
+	if o := req.FormValue("offset"); o != "" {
+		_, err := fmt.Sscan(o, &offset)
+		if err != nil {
+			WriteError(w, Error{"unable to parse offset: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if l := req.FormValue("limit"); l != "" {
+		_, err := fmt.Sscan(l, &limit)
+		if err != nil {
+			WriteError(w, Error{"unable to parse limit: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	entries, total, err := host.RegistryEntries(offset, limit)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostRegistryGET{
+		Entries: entries,
+		Total:   total,
+	})
+}
+
+// hostRegistryEntryHandlerGET handles the API call to look up an entry of the
+// host's registry, either by its entry id or by its public key and tweak.
+func hostRegistryEntryHandlerGET(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var sid modules.RegistryEntryID
+	if entryID := req.FormValue("entryid"); entryID != "" {
+		var h crypto.Hash
+		if err := h.LoadString(entryID); err != nil {
+			WriteError(w, Error{"unable to parse entryid: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		sid = modules.RegistryEntryID(h)
+	} else {
+		var spk types.SiaPublicKey
+		if err := spk.LoadString(req.FormValue("publickey")); err != nil {
+			WriteError(w, Error{"unable to parse publickey: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		var tweak crypto.Hash
+		if err := tweak.LoadString(req.FormValue("tweak")); err != nil {
+			WriteError(w, Error{"unable to parse tweak: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		sid = modules.DeriveRegistryEntryID(spk, tweak)
+	}
+	entry, found := host.RegistryEntry(sid)
+	if !found {
+		WriteError(w, Error{"registry entry not found"}, http.StatusNotFound)
+		return
+	}
+	WriteJSON(w, HostRegistryEntryGET{entry})
+}
+
+// hostRegistryExportHandlerPOST handles the API call to export the host's
+// registry to a file.
+func hostRegistryExportHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	path := req.FormValue("path")
+	if !filepath.IsAbs(path) {
+		WriteError(w, Error{"path needs to be absolute"}, http.StatusBadRequest)
+		return
+	}
+	exported, err := host.ExportRegistry(path)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostRegistryExportPOST{
+		Exported: exported,
+	})
+}
+
+// hostRegistryImportHandlerPOST handles the API call to import the entries of
+// an exported registry into the host's registry.
+func hostRegistryImportHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	path := req.FormValue("path")
+	if !filepath.IsAbs(path) {
+		WriteError(w, Error{"path needs to be absolute"}, http.StatusBadRequest)
+		return
+	}
+	imported, err := host.ImportRegistry(path)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostRegistryImportPOST{
+		Imported: imported,
+	})
+}
+
+// hostRegistryPruneHandlerPOST handles the API call to delete the expired
+// entries of the host's registry.
+func hostRegistryPruneHandlerPOST(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	pruned, err := host.PruneRegistry()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostRegistryPrunePOST{
+		Pruned: pruned,
+	})
+}
+
+// hostContractForecastHandlerGET handles GET requests to the
+// /host/contracts/forecast API endpoint, returning a forecast of the host's
+// storage proofs over the next blocks.
+func hostContractForecastHandlerGET(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	blocks := types.BlocksPerMonth
+	if b := req.FormValue("blocks"); b != "" {
+		_, err := fmt.Sscan(b, &blocks)
+		if err != nil {
+			WriteError(w, Error{"unable to parse blocks: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	forecast, err := host.ContractForecast(blocks)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostContractForecastGET{forecast})
+}
+
+// hostDecommissionHandlerGET handles GET requests to the /host/decommission API
+// endpoint, returning the progress of the host's decommissioning.
+func hostDecommissionHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostDecommissionGET{host.DecommissionStatus()})
+}
+
+// hostDecommissionHandlerPOST handles the API call to start or cancel the
+// decommissioning of the host.
+func hostDecommissionHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var err error
+	if req.FormValue("cancel") == "true" {
+		err = host.CancelDecommission()
+	} else {
+		err = host.Decommission()
+	}
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // hostContractInfoHandler handles the API call to get the contract information of the host.
 // Information is retrieved via the storage obligations from the host database.
 func hostContractInfoHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
@@ -167,11 +541,13 @@ func hostHandlerGET(host modules.Host, w http.ResponseWriter, deps modules.Depen
 	is := host.InternalSettings()
 	nm := host.NetworkMetrics()
 	cs := host.ConnectabilityStatus()
+	ds := host.DecommissionStatus()
 	ws := host.WorkingStatus()
 	pk := host.PublicKey()
 	pt := host.PriceTable()
 	hg := HostGET{
 		ConnectabilityStatus: cs,
+		DecommissionStatus:   ds,
 		ExternalSettings:     es,
 		FinancialMetrics:     fm,
 		InternalSettings:     is,
@@ -366,6 +742,56 @@ func parseHostSettings(host modules.Host, req *http.Request) (modules.HostIntern
 	if req.FormValue("customregistrypath") != "" {
 		settings.CustomRegistryPath = req.FormValue("customregistrypath")
 	}
+	if req.FormValue("sectorscrubrate") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("sectorscrubrate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.SectorScrubRate = x
+	}
+
+	if req.FormValue("maxclientbandwidth") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxclientbandwidth"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxClientBandwidth = x
+	}
+	if req.FormValue("maxclientprogramsperminute") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxclientprogramsperminute"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxClientProgramsPerMinute = x
+	}
+	if req.FormValue("maxclientstreams") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxclientstreams"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxClientStreams = x
+	}
+
+	if req.FormValue("mdmtracesamplerate") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("mdmtracesamplerate"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MDMTraceSampleRate = x
+	}
+	if req.FormValue("mdmtracefailures") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("mdmtracefailures"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MDMTraceFailures = x
+	}
 
 	// Validate the RPC, Sector Access, and Download Prices
 	minBaseRPCPrice := settings.MinBaseRPCPrice
@@ -458,6 +884,234 @@ func hostHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request
 	WriteSuccess(w)
 }
 
+// hostMDMTracesHandlerGET handles GET requests to the /host/mdm/traces API
+// endpoint, returning the most recent execution traces of MDM programs. The
+// traces can be filtered by the renter key or the ephemeral account that paid
+// for the program.
+func hostMDMTracesHandlerGET(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var renterKey *types.SiaPublicKey
+	if req.FormValue("renterkey") != "" {
+		renterKey = new(types.SiaPublicKey)
+		if err := renterKey.LoadString(req.FormValue("renterkey")); err != nil {
+			WriteError(w, Error{"unable to parse renterkey: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	var account *modules.AccountID
+	if req.FormValue("account") != "" {
+		account = new(modules.AccountID)
+		if err := account.LoadString(req.FormValue("account")); err != nil {
+			WriteError(w, Error{"unable to parse account: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	limit := -1
+	if req.FormValue("limit") != "" {
+		_, err := fmt.Sscan(req.FormValue("limit"), &limit)
+		if err != nil || limit < 0 {
+			WriteError(w, Error{"unable to parse limit"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	traces := make([]modules.HostMDMTrace, 0)
+	for _, trace := range host.MDMTraces() {
+		if limit >= 0 && len(traces) >= limit {
+			break
+		}
+		if renterKey != nil && (trace.ClientType != modules.HostClientTypeRenter || !trace.ClientKey.Equals(*renterKey)) {
+			continue
+		}
+		if account != nil && (trace.ClientType != modules.HostClientTypeAccount || !trace.ClientKey.Equals(account.SPK())) {
+			continue
+		}
+		traces = append(traces, trace)
+	}
+	WriteJSON(w, HostMDMTracesGET{
+		Traces: traces,
+	})
+}
+
+// hostMetricsHandlerGET handles GET requests to the /host/metrics API
+// endpoint, returning the detailed metrics of the RPCs and MDM instructions
+// handled by the host.
+func hostMetricsHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	hm := host.Metrics()
+	WriteJSON(w, HostMetricsGET{
+		Instructions: hm.Instructions,
+		RPCs:         hm.RPCs,
+	})
+}
+
+// hostMetricsPrometheusHandlerGET handles GET requests to the
+// /host/metrics/prometheus API endpoint, returning the host's metrics in the
+// Prometheus text exposition format.
+func hostMetricsPrometheusHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeHostMetricsPrometheus(w, host.Metrics(), host.NetworkMetrics())
+}
+
+// writeHostMetricsPrometheus writes the host's metrics to w in the Prometheus
+// text exposition format.
+func writeHostMetricsPrometheus(w io.Writer, hm modules.HostMetrics, nm modules.HostNetworkMetrics) {
+	// writeHeader writes the HELP and TYPE lines of a metric.
+	writeHeader := func(name, help, typ string) {
+		fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, typ)
+	}
+	// writeHistogram writes the samples of a latency histogram.
+	writeHistogram := func(name, labels string, lh modules.HostLatencyHistogram) {
+		for _, b := range lh.Buckets {
+			fmt.Fprintf(w, "%v_bucket{%v,le=\"%v\"} %v\n", name, labels, b.UpperBound.Seconds(), b.Count)
+		}
+		fmt.Fprintf(w, "%v_bucket{%v,le=\"+Inf\"} %v\n", name, labels, lh.Count)
+		fmt.Fprintf(w, "%v_sum{%v} %v\n", name, labels, lh.Sum.Seconds())
+		fmt.Fprintf(w, "%v_count{%v} %v\n", name, labels, lh.Count)
+	}
+	rpcLabel := func(m modules.HostRPCMetrics) string {
+		return fmt.Sprintf("rpc=%q", m.Name)
+	}
+	instructionLabel := func(m modules.HostInstructionMetrics) string {
+		return fmt.Sprintf("instruction=%q", m.Name)
+	}
+
+	// RPC metrics.
+	writeHeader("sia_host_rpc_calls_total", "Number of RPCs handled by the host.", "counter")
+	for _, m := range hm.RPCs {
+		fmt.Fprintf(w, "sia_host_rpc_calls_total{%v} %v\n", rpcLabel(m), m.Calls)
+	}
+	writeHeader("sia_host_rpc_errors_total", "Number of RPCs that failed.", "counter")
+	for _, m := range hm.RPCs {
+		fmt.Fprintf(w, "sia_host_rpc_errors_total{%v} %v\n", rpcLabel(m), m.Errors)
+	}
+	writeHeader("sia_host_rpc_received_bytes_total", "Number of bytes received by the host during RPCs.", "counter")
+	for _, m := range hm.RPCs {
+		fmt.Fprintf(w, "sia_host_rpc_received_bytes_total{%v} %v\n", rpcLabel(m), m.BytesIn)
+	}
+	writeHeader("sia_host_rpc_sent_bytes_total", "Number of bytes sent by the host during RPCs.", "counter")
+	for _, m := range hm.RPCs {
+		fmt.Fprintf(w, "sia_host_rpc_sent_bytes_total{%v} %v\n", rpcLabel(m), m.BytesOut)
+	}
+	writeHeader("sia_host_rpc_duration_seconds", "Time it took the host to handle RPCs.", "histogram")
+	for _, m := range hm.RPCs {
+		writeHistogram("sia_host_rpc_duration_seconds", rpcLabel(m), m.Latency)
+	}
+
+	// Instruction metrics.
+	writeHeader("sia_host_instruction_executions_total", "Number of MDM instructions executed by the host.", "counter")
+	for _, m := range hm.Instructions {
+		fmt.Fprintf(w, "sia_host_instruction_executions_total{%v} %v\n", instructionLabel(m), m.Executions)
+	}
+	writeHeader("sia_host_instruction_errors_total", "Number of MDM instructions that failed.", "counter")
+	for _, m := range hm.Instructions {
+		fmt.Fprintf(w, "sia_host_instruction_errors_total{%v} %v\n", instructionLabel(m), m.Errors)
+	}
+	writeHeader("sia_host_instruction_duration_seconds", "Time it took the host to execute MDM instructions.", "histogram")
+	for _, m := range hm.Instructions {
+		writeHistogram("sia_host_instruction_duration_seconds", instructionLabel(m), m.Latency)
+	}
+
+	// Network metrics, which also cover the legacy protocol.
+	writeHeader("sia_host_legacy_calls_total", "Number of legacy RPCs handled by the host.", "counter")
+	fmt.Fprintf(w, "sia_host_legacy_calls_total{rpc=\"download\"} %v\n", nm.DownloadCalls)
+	fmt.Fprintf(w, "sia_host_legacy_calls_total{rpc=\"formcontract\"} %v\n", nm.FormContractCalls)
+	fmt.Fprintf(w, "sia_host_legacy_calls_total{rpc=\"renew\"} %v\n", nm.RenewCalls)
+	fmt.Fprintf(w, "sia_host_legacy_calls_total{rpc=\"revise\"} %v\n", nm.ReviseCalls)
+	fmt.Fprintf(w, "sia_host_legacy_calls_total{rpc=\"settings\"} %v\n", nm.SettingsCalls)
+	writeHeader("sia_host_errored_calls_total", "Number of calls to the host that failed.", "counter")
+	fmt.Fprintf(w, "sia_host_errored_calls_total %v\n", nm.ErrorCalls)
+	writeHeader("sia_host_unrecognized_calls_total", "Number of calls to the host with an unrecognized RPC.", "counter")
+	fmt.Fprintf(w, "sia_host_unrecognized_calls_total %v\n", nm.UnrecognizedCalls)
+}
+
+// hostPricingHandlerGET handles GET requests to the /host/pricing API endpoint,
+// returning the policy of the host's pricing engine and its recent price
+// changes.
+func hostPricingHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostPricingGET{
+		Changes: host.PriceChanges(),
+		Policy:  host.PricingPolicy(),
+	})
+}
+
+// parseHostPricingPolicy parses the pricing policy fields of a request. Fields
+// which are not provided keep their current values.
+func parseHostPricingPolicy(host modules.Host, req *http.Request) (modules.HostPricingPolicy, error) {
+	policy := host.PricingPolicy()
+	if err := req.ParseForm(); err != nil {
+		return modules.HostPricingPolicy{}, err
+	}
+
+	if req.FormValue("enabled") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("enabled"), &x)
+		if err != nil {
+			return modules.HostPricingPolicy{}, err
+		}
+		policy.Enabled = x
+	}
+	// An empty exchange rate removes the exchange rate.
+	if _, ok := req.Form["exchangerate"]; ok {
+		policy.ExchangeRate = req.FormValue("exchangerate")
+	}
+	if req.FormValue("maxbandwidth") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxbandwidth"), &x)
+		if err != nil {
+			return modules.HostPricingPolicy{}, err
+		}
+		policy.MaxBandwidth = x
+	}
+
+	targets := map[string]*float64{
+		"targetstorageprice":           &policy.TargetStoragePrice,
+		"targetdownloadbandwidthprice": &policy.TargetDownloadBandwidthPrice,
+		"targetuploadbandwidthprice":   &policy.TargetUploadBandwidthPrice,
+	}
+	for name, target := range targets {
+		if req.FormValue(name) == "" {
+			continue
+		}
+		_, err := fmt.Sscan(req.FormValue(name), target)
+		if err != nil {
+			return modules.HostPricingPolicy{}, fmt.Errorf("unable to parse %v: %v", name, err)
+		}
+	}
+	limits := map[string]*types.Currency{
+		"storagepricefloor":             &policy.StoragePriceFloor,
+		"storagepriceceiling":           &policy.StoragePriceCeiling,
+		"downloadbandwidthpricefloor":   &policy.DownloadBandwidthPriceFloor,
+		"downloadbandwidthpriceceiling": &policy.DownloadBandwidthPriceCeiling,
+		"uploadbandwidthpricefloor":     &policy.UploadBandwidthPriceFloor,
+		"uploadbandwidthpriceceiling":   &policy.UploadBandwidthPriceCeiling,
+	}
+	for name, limit := range limits {
+		if req.FormValue(name) == "" {
+			continue
+		}
+		_, err := fmt.Sscan(req.FormValue(name), limit)
+		if err != nil {
+			return modules.HostPricingPolicy{}, fmt.Errorf("unable to parse %v: %v", name, err)
+		}
+	}
+	return policy, nil
+}
+
+// hostPricingHandlerPOST handles POST requests to the /host/pricing API
+// endpoint, which sets the policy of the host's pricing engine.
+func hostPricingHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	policy, err := parseHostPricingPolicy(host, req)
+	if err != nil {
+		WriteError(w, Error{"error parsing pricing policy: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = host.SetPricingPolicy(policy)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // hostAnnounceHandler handles the API call to get the host to announce itself
 // to the network.
 func hostAnnounceHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -478,10 +1132,38 @@ func hostAnnounceHandler(host modules.Host, w http.ResponseWriter, req *http.Req
 // the host.
 func storageHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	WriteJSON(w, StorageGET{
+		Cache:   host.CacheFolder(),
 		Folders: host.StorageFolders(),
 	})
 }
 
+// storageCacheAddHandler designates a folder as the host's cache folder.
+func storageCacheAddHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	folderPath := req.FormValue("path")
+	var folderSize uint64
+	_, err := fmt.Sscan(req.FormValue("size"), &folderSize)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = host.AddCacheFolder(folderPath, folderSize)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// storageCacheRemoveHandler removes the host's cache folder.
+func storageCacheRemoveHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	err := host.RemoveCacheFolder()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageFoldersAddHandler adds a storage folder to the storage manager.
 func storageFoldersAddHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	folderPath := req.FormValue("path")
@@ -553,6 +1235,49 @@ func storageFoldersRemoveHandler(host modules.Host, w http.ResponseWriter, req *
 	WriteSuccess(w)
 }
 
+// storageFoldersRebalanceHandler handles the call to start or cancel a
+// rebalance of the storage folders.
+func storageFoldersRebalanceHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := req.ParseForm(); err != nil {
+		WriteError(w, Error{"failed to parse form: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if req.FormValue("cancel") == "true" {
+		err := host.CancelStorageFolderRebalance()
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	var rate uint64
+	if r := req.FormValue("rate"); r != "" {
+		_, err := fmt.Sscan(r, &rate)
+		if err != nil {
+			WriteError(w, Error{"unable to parse rate: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	storageFolders := host.StorageFolders()
+	var evacuate []uint16
+	for _, folderPath := range req.Form["evacuate"] {
+		index, err := folderIndex(folderPath, storageFolders)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		evacuate = append(evacuate, uint16(index))
+	}
+	err := host.RebalanceStorageFolders(evacuate, rate)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageSectorsDeleteHandler handles the call to delete a sector from the
 // storage manager.
 func storageSectorsDeleteHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
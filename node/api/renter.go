@@ -141,6 +141,19 @@ type (
 		BadContract bool `json:"badcontract"`
 	}
 
+	// RenterContractsLedger contains the entries of the renter's contract
+	// spending ledger.
+	RenterContractsLedger struct {
+		Entries []modules.ContractSpendingEntry `json:"entries"`
+	}
+
+	// RenterContractStatuses contains the watchdog status of all the renter's
+	// contracts and the storage proof statistics of their hosts.
+	RenterContractStatuses struct {
+		Contracts []modules.ContractWatchStatus   `json:"contracts"`
+		Hosts     []modules.HostStorageProofStats `json:"hosts"`
+	}
+
 	// RenterContracts contains the renter's contracts.
 	RenterContracts struct {
 		// Compatibility Fields
@@ -238,6 +251,12 @@ type (
 		ParityPieces int `json:"paritypieces"`
 	}
 
+	// RenterWorkersEstimatesGET contains the comparison of the renter's local
+	// estimate of a sample program with the dry run of every worker's host.
+	RenterWorkersEstimatesGET struct {
+		Estimates []modules.WorkerProgramEstimate `json:"estimates"`
+	}
+
 	// DownloadInfo contains all client-facing information of a file.
 	DownloadInfo struct {
 		Destination     string          `json:"destination"`     // The destination of the download.
@@ -955,6 +974,34 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		settings.IPViolationCheck = ipviolationcheck
 	}
 
+	// Scan the backup schedule fields.
+	if bi := req.FormValue("backupinterval"); bi != "" {
+		interval, err := strconv.ParseUint(bi, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"unable to parse backupinterval: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.BackupSchedule.Interval = time.Duration(interval) * time.Second
+	}
+	if kl := req.FormValue("backupkeeplast"); kl != "" {
+		if _, err := fmt.Sscan(kl, &settings.BackupSchedule.KeepLast); err != nil {
+			WriteError(w, Error{"unable to parse backupkeeplast: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if kd := req.FormValue("backupkeepdaily"); kd != "" {
+		if _, err := fmt.Sscan(kd, &settings.BackupSchedule.KeepDaily); err != nil {
+			WriteError(w, Error{"unable to parse backupkeepdaily: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if kw := req.FormValue("backupkeepweekly"); kw != "" {
+		if _, err := fmt.Sscan(kw, &settings.BackupSchedule.KeepWeekly); err != nil {
+			WriteError(w, Error{"unable to parse backupkeepweekly: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Set the settings in the renter.
 	err = api.renter.SetSettings(settings)
 	if err != nil {
@@ -1228,6 +1275,47 @@ func (api *API) parseRenterContracts(disabled, inactive, expired bool) RenterCon
 	return rc
 }
 
+// renterContractsLedgerHandler handles the API call to request the entries of
+// the contract spending ledger.
+func (api *API) renterContractsLedgerHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var startTime time.Time
+	endTime := types.EndOfTime
+	startStr, endStr := req.FormValue("start"), req.FormValue("end")
+	if startStr != "" {
+		startInt, err := strconv.ParseInt(startStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `start` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		startTime = time.Unix(startInt, 0)
+	}
+	if endStr != "" {
+		endInt, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `end` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		endTime = time.Unix(endInt, 0)
+	}
+	var hostKey types.SiaPublicKey
+	if hostStr := req.FormValue("host"); hostStr != "" {
+		hostKey.LoadString(hostStr)
+		if hostKey.Key == nil {
+			WriteError(w, Error{"invalid host public key"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := api.renter.SpendingLedger(startTime, endTime, hostKey)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterContractsLedger{
+		Entries: entries,
+	})
+}
+
 // renterClearDownloadsHandler handles the API call to request to clear the download queue.
 func (api *API) renterClearDownloadsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var afterTime time.Time
@@ -1417,6 +1505,25 @@ func (api *API) renterRecoveryScanHandlerPOST(w http.ResponseWriter, _ *http.Req
 	WriteSuccess(w)
 }
 
+// renterRecoveryHandlerPOST handles the API call to /renter/recovery.
+func (api *API) renterRecoveryHandlerPOST(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	if err := api.renter.RecoverRenter(); err != nil {
+		WriteError(w, Error{"failed to start renter recovery: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterRecoveryHandlerGET handles the API call to /renter/recovery.
+func (api *API) renterRecoveryHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	status, err := api.renter.RenterRecoveryStatus()
+	if err != nil {
+		WriteError(w, Error{"failed to get renter recovery status: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, status)
+}
+
 // renterRecoveryScanHandlerGET handles the API call to /renter/recoveryscan.
 func (api *API) renterRecoveryScanHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	scanInProgress, height := api.renter.RecoveryScanStatus()
@@ -2273,8 +2380,18 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 }
 
 // renterContractStatusHandler  handles the API call to check the status of a
-// contract monitored by the renter.
+// contract monitored by the renter. If no id is provided, the status of all
+// contracts and the storage proof statistics of their hosts are returned.
 func (api *API) renterContractStatusHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if req.FormValue("id") == "" {
+		contracts, hosts := api.renter.ContractStatuses()
+		WriteJSON(w, RenterContractStatuses{
+			Contracts: contracts,
+			Hosts:     hosts,
+		})
+		return
+	}
+
 	var fcID types.FileContractID
 	if err := fcID.LoadString(req.FormValue("id")); err != nil {
 		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
@@ -2290,6 +2407,19 @@ func (api *API) renterContractStatusHandler(w http.ResponseWriter, req *http.Req
 	WriteJSON(w, contractStatus)
 }
 
+// renterWorkersEstimatesHandler handles the API call to compare the renter's
+// local program estimates with the dry runs of the workers' hosts.
+func (api *API) renterWorkersEstimatesHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	estimates, err := api.renter.WorkerProgramEstimates()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterWorkersEstimatesGET{
+		Estimates: estimates,
+	})
+}
+
 // renterWorkersHandler handles the API call to check the status of the renter's
 // workers
 func (api *API) renterWorkersHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
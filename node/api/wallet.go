@@ -54,6 +54,53 @@ type (
 		PrimarySeed string `json:"primaryseed"`
 	}
 
+	// WalletMultisigAddress is a multisig address tracked by the wallet.
+	WalletMultisigAddress struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+	}
+
+	// WalletMultisigGET contains the multisig addresses tracked by the
+	// wallet.
+	WalletMultisigGET struct {
+		Addresses []WalletMultisigAddress `json:"addresses"`
+	}
+
+	// WalletMultisigPOSTParams contains the public keys and the number of
+	// required signatures of a new multisig address.
+	WalletMultisigPOSTParams struct {
+		PublicKeys         []types.SiaPublicKey `json:"publickeys"`
+		SignaturesRequired uint64               `json:"signaturesrequired"`
+		Unused             bool                 `json:"unused"`
+	}
+
+	// WalletMultisigBroadcastPOST contains the ID of a broadcast multisig
+	// transaction.
+	WalletMultisigBroadcastPOST struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+	}
+
+	// WalletMultisigCombinePOSTParams contains the partially-signed
+	// transactions to combine.
+	WalletMultisigCombinePOSTParams struct {
+		Transactions []modules.PartiallySignedTransaction `json:"transactions"`
+	}
+
+	// WalletMultisigCreatePOSTParams contains the multisig address and the
+	// outputs of a new partially-signed transaction.
+	WalletMultisigCreatePOSTParams struct {
+		Address types.UnlockHash      `json:"address"`
+		Outputs []types.SiacoinOutput `json:"outputs"`
+	}
+
+	// WalletMultisigTransactionPOST contains a partially-signed transaction.
+	// It is the request body of /wallet/multisig/sign and
+	// /wallet/multisig/broadcast and the response of the create, combine and
+	// sign endpoints.
+	WalletMultisigTransactionPOST struct {
+		Transaction modules.PartiallySignedTransaction `json:"transaction"`
+	}
+
 	// WalletSiacoinsPOST contains the transaction sent in the POST call to
 	// /wallet/siacoins.
 	WalletSiacoinsPOST struct {
@@ -94,6 +141,13 @@ type (
 		Funds types.Currency `json:"funds"`
 	}
 
+	// WalletTransactionBumpPOST contains the transaction set submitted to the
+	// transaction pool by a POST call to /wallet/transaction/:id/bump.
+	WalletTransactionBumpPOST struct {
+		Transactions   []types.Transaction   `json:"transactions"`
+		TransactionIDs []types.TransactionID `json:"transactionids"`
+	}
+
 	// WalletTransactionGETid contains the transaction returned by a call to
 	// /wallet/transaction/:id
 	WalletTransactionGETid struct {
@@ -189,6 +243,24 @@ func RegisterRoutesWallet(router *httprouter.Router, wallet modules.Wallet, requ
 	router.POST("/wallet/lock", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletLockHandler(wallet, w, req, ps)
 	}, requiredPassword))
+	router.GET("/wallet/multisig", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/multisig", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/multisig/broadcast", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigBroadcastHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/multisig/combine", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigCombineHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/multisig/create", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigCreateHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/multisig/sign", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletMultisigSignHandler(wallet, w, req, ps)
+	}, requiredPassword))
 	router.POST("/wallet/seed", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletSeedHandler(wallet, w, req, ps)
 	}, requiredPassword))
@@ -210,6 +282,9 @@ func RegisterRoutesWallet(router *httprouter.Router, wallet modules.Wallet, requ
 	router.GET("/wallet/transaction/:id", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletTransactionHandler(wallet, w, req, ps)
 	})
+	router.POST("/wallet/transaction/:id/bump", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletTransactionBumpHandler(wallet, w, req, ps)
+	}, requiredPassword))
 	router.GET("/wallet/transactions", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletTransactionsHandler(wallet, w, req, ps)
 	})
@@ -472,6 +547,122 @@ func walletInitSeedHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 	WriteSuccess(w)
 }
 
+// walletMultisigHandlerGET handles GET calls to /wallet/multisig.
+func walletMultisigHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	ucs, err := wallet.MultisigAddresses()
+	if err != nil {
+		WriteError(w, Error{"failed to get multisig addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	addrs := make([]WalletMultisigAddress, 0, len(ucs))
+	for _, uc := range ucs {
+		addrs = append(addrs, WalletMultisigAddress{
+			Address:          uc.UnlockHash(),
+			UnlockConditions: uc,
+		})
+	}
+	WriteJSON(w, WalletMultisigGET{
+		Addresses: addrs,
+	})
+}
+
+// walletMultisigHandlerPOST handles POST calls to /wallet/multisig.
+func walletMultisigHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigPOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	uc, err := wallet.AddMultisigAddress(params.PublicKeys, params.SignaturesRequired, params.Unused)
+	if err != nil {
+		WriteError(w, Error{"failed to add multisig address: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigAddress{
+		Address:          uc.UnlockHash(),
+		UnlockConditions: uc,
+	})
+}
+
+// walletMultisigBroadcastHandler handles API calls to
+// /wallet/multisig/broadcast.
+func walletMultisigBroadcastHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigTransactionPOST
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txn, err := wallet.BroadcastMultisigTransaction(params.Transaction)
+	if err != nil {
+		WriteError(w, Error{"failed to broadcast transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigBroadcastPOST{
+		TransactionID: txn.ID(),
+	})
+}
+
+// walletMultisigCombineHandler handles API calls to /wallet/multisig/combine.
+func walletMultisigCombineHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigCombinePOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	height, err := wallet.Height()
+	if err != nil {
+		WriteError(w, Error{"failed to get wallet height: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	pst, err := modules.CombinePartiallySignedTransactions(params.Transactions, height)
+	if err != nil {
+		WriteError(w, Error{"failed to combine transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigTransactionPOST{
+		Transaction: pst,
+	})
+}
+
+// walletMultisigCreateHandler handles API calls to /wallet/multisig/create.
+func walletMultisigCreateHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigCreatePOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := wallet.CreateMultisigTransaction(params.Address, params.Outputs)
+	if err != nil {
+		WriteError(w, Error{"failed to create transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigTransactionPOST{
+		Transaction: pst,
+	})
+}
+
+// walletMultisigSignHandler handles API calls to /wallet/multisig/sign.
+func walletMultisigSignHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigTransactionPOST
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := wallet.SignMultisigTransaction(params.Transaction)
+	if err != nil {
+		WriteError(w, Error{"failed to sign transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigTransactionPOST{
+		Transaction: pst,
+	})
+}
+
 // walletSeedHandler handles API calls to /wallet/seed.
 func walletSeedHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	// Get the seed using the dictionary + phrase
@@ -716,6 +907,48 @@ func walletTransactionHandler(wallet modules.Wallet, w http.ResponseWriter, _ *h
 	})
 }
 
+// walletTransactionBumpHandler handles API calls to
+// /wallet/transaction/:id/bump.
+func walletTransactionBumpHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Parse the id from the url.
+	var id types.TransactionID
+	jsonID := "\"" + ps.ByName("id") + "\""
+	err := id.UnmarshalJSON([]byte(jsonID))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transaction/id/bump: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	method := modules.FeeBumpMethod(req.FormValue("method"))
+	if method != modules.FeeBumpCPFP && method != modules.FeeBumpRBF {
+		WriteError(w, Error{fmt.Sprintf("'method' must be either %q or %q", modules.FeeBumpCPFP, modules.FeeBumpRBF)}, http.StatusBadRequest)
+		return
+	}
+	var feePerByte types.Currency
+	if req.FormValue("feeperbyte") != "" {
+		fee, ok := scanAmount(req.FormValue("feeperbyte"))
+		if !ok {
+			WriteError(w, Error{"could not read 'feeperbyte' from POST call to /wallet/transaction/id/bump"}, http.StatusBadRequest)
+			return
+		}
+		feePerByte = fee
+	}
+
+	txns, err := wallet.BumpTransactionFee(id, method, feePerByte)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transaction/id/bump: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletTransactionBumpPOST{
+		Transactions:   txns,
+		TransactionIDs: txids,
+	})
+}
+
 // walletTransactionsHandler handles API calls to /wallet/transactions.
 func walletTransactionsHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	startheightStr, endheightStr := req.FormValue("startheight"), req.FormValue("endheight")
@@ -524,6 +524,12 @@ func New(params NodeParams, loadStartTime time.Time) (*Node, <-chan error) {
 		return nil, errChan
 	}
 
+	// Let the host's pricing engine use the hosts observed by the renter as
+	// market prices.
+	if h != nil && r != nil {
+		h.SetPricingMarket(r)
+	}
+
 	// Accounting.
 	acc, err := func() (modules.Accounting, error) {
 		if params.CreateAccounting && params.Accounting != nil {
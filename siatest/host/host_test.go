@@ -3,19 +3,24 @@ package host
 import (
 	"bytes"
 	"errors"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"gitlab.com/NebulousLabs/fastrand"
 	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/modules/host"
 	"go.sia.tech/siad/modules/host/contractmanager"
+	"go.sia.tech/siad/modules/host/registry"
 	"go.sia.tech/siad/node"
 	"go.sia.tech/siad/node/api"
 	"go.sia.tech/siad/node/api/client"
+	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/siatest"
 	"go.sia.tech/siad/siatest/dependencies"
 	"go.sia.tech/siad/types"
@@ -698,3 +703,864 @@ func TestHostGetPriceTable(t *testing.T) {
 		t.Fatal("wrong subscription notification cost")
 	}
 }
+
+// TestHostStorageFolderRebalance tests starting and cancelling a storage folder
+// rebalance through the API.
+func TestHostStorageFolderRebalance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:  1,
+		Miners: 1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// The only storage folder can't be evacuated.
+	sg, err := h.HostStorageGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sg.Folders) != 1 {
+		t.Fatal("expected one storage folder", len(sg.Folders))
+	}
+	oldFolder := sg.Folders[0].Path
+	if err := h.HostStorageFoldersRebalancePost([]string{oldFolder}, 0); err == nil {
+		t.Fatal("evacuating the only storage folder should fail")
+	}
+
+	// Unknown storage folders can't be evacuated.
+	if err := h.HostStorageFoldersRebalancePost([]string{hostTestDir("unknown")}, 0); err == nil {
+		t.Fatal("evacuating an unknown storage folder should fail")
+	}
+
+	// Cancelling without a rebalance in progress fails.
+	if err := h.HostStorageFoldersRebalanceCancelPost(); err == nil {
+		t.Fatal("cancelling without a rebalance in progress should fail")
+	}
+
+	// Add a second folder and evacuate the first one.
+	newFolder := filepath.Join(h.Dir, "newfolder")
+	if err := os.MkdirAll(newFolder, persist.DefaultDiskPermissionsTest); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HostStorageFoldersAddPost(newFolder, sg.Folders[0].Capacity); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HostStorageFoldersRebalancePost([]string{oldFolder}, 0); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		sg, err := h.HostStorageGet()
+		if err != nil {
+			return err
+		}
+		for _, sf := range sg.Folders {
+			if sf.Evacuating {
+				return errors.New("folder is still being evacuated")
+			}
+			if sf.Path == oldFolder && sf.CapacityRemaining != sf.Capacity {
+				return errors.New("folder wasn't evacuated")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Even out the utilisation of the folders.
+	if err := h.HostStorageFoldersRebalancePost(nil, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHostStorageCache tests adding and removing the host's cache folder
+// through the API.
+func TestHostStorageCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:  1,
+		Miners: 1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// Add a cache folder.
+	cacheFolder := filepath.Join(h.Dir, "cache")
+	if err := os.MkdirAll(cacheFolder, persist.DefaultDiskPermissionsTest); err != nil {
+		t.Fatal(err)
+	}
+	cacheSize := modules.SectorSize * contractmanager.MinimumSectorsPerStorageFolder
+	if err := h.HostStorageCacheAddPost(cacheFolder, cacheSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HostStorageCacheAddPost(cacheFolder, cacheSize); err == nil || !strings.Contains(err.Error(), contractmanager.ErrCacheFolderExists.Error()) {
+		t.Fatal("expected ErrCacheFolderExists, got", err)
+	}
+	sg, err := h.HostStorageGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sg.Cache.Path != cacheFolder || sg.Cache.Capacity != cacheSize || sg.Cache.CapacityRemaining != cacheSize {
+		t.Fatal("unexpected cache folder", sg.Cache)
+	}
+	hg, err := h.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hg.FinancialMetrics.CacheHits != sg.Cache.Hits || hg.FinancialMetrics.CacheMisses != sg.Cache.Misses {
+		t.Fatal("cache statistics don't match", hg.FinancialMetrics, sg.Cache)
+	}
+
+	// Remove the cache folder.
+	if err := h.HostStorageCacheRemovePost(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.HostStorageCacheRemovePost(); err == nil {
+		t.Fatal("removing a non-existent cache folder should fail")
+	}
+	sg, err = h.HostStorageGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sg.Cache.Path != "" {
+		t.Fatal("cache folder wasn't removed", sg.Cache)
+	}
+}
+
+// TestHostPricing tests the /host/pricing endpoints.
+func TestHostPricing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:  1,
+		Miners: 1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// The pricing engine is disabled by default.
+	hpg, err := h.HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hpg.Policy.Enabled || len(hpg.Changes) != 0 {
+		t.Fatal("pricing engine shouldn't be enabled", hpg)
+	}
+
+	// An invalid policy is rejected.
+	if err := h.HostPricingModifyPost("targetstorageprice", "2"); err == nil {
+		t.Fatal("expected a target without exchange rate to fail")
+	}
+
+	// Enable the pricing engine with a fiat target. The storage price should
+	// be set immediately.
+	policy := modules.HostPricingPolicy{
+		Enabled:             true,
+		ExchangeRate:        "0.01 USD",
+		TargetStoragePrice:  2,
+		StoragePriceCeiling: types.SiacoinPrecision.Mul64(1000).Div(modules.BlockBytesPerMonthTerabyte),
+	}
+	if err := h.HostPricingPost(policy); err != nil {
+		t.Fatal(err)
+	}
+	hpg, err = h.HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hpg.Policy, policy) {
+		t.Fatal("policy wasn't set", hpg.Policy, policy)
+	}
+	if len(hpg.Changes) != 1 {
+		t.Fatal("expected one price change", hpg.Changes)
+	}
+	hg, err := h.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hg.InternalSettings.MinStoragePrice.Equals(hpg.Changes[0].StoragePrice) {
+		t.Fatal("storage price wasn't updated", hg.InternalSettings.MinStoragePrice, hpg.Changes[0].StoragePrice)
+	}
+
+	// Disable the pricing engine.
+	if err := h.HostPricingModifyPost("enabled", "false"); err != nil {
+		t.Fatal(err)
+	}
+	hpg, err = h.HostPricingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hpg.Policy.Enabled || hpg.Policy.ExchangeRate != policy.ExchangeRate {
+		t.Fatal("pricing engine wasn't disabled", hpg.Policy)
+	}
+}
+
+// TestHostClients verifies that the host reports the renters that are using
+// it at /host/clients.
+func TestHostClients(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// The quotas can be set through the host's settings.
+	err = h.HostModifySettingPost(client.HostParamMaxClientStreams, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hg, err := h.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hg.InternalSettings.MaxClientStreams != 100 {
+		t.Fatal("setting wasn't updated", hg.InternalSettings.MaxClientStreams)
+	}
+
+	// The renter's workers should show up as clients of the host once they
+	// paid for an RPC.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hcg, err := h.HostClientsGet(0)
+		if err != nil {
+			return err
+		}
+		if len(hcg.Clients) == 0 {
+			return errors.New("no clients reported")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The number of returned clients can be limited.
+	hcg, err := h.HostClientsGet(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hcg.Clients) != 1 {
+		t.Fatal("expected a single client", hcg.Clients)
+	}
+	if hcg.Clients[0].Streams == 0 || hcg.Clients[0].Rejections != 0 {
+		t.Fatal("unexpected client statistics", hcg.Clients[0])
+	}
+}
+
+// TestHostMetrics verifies that the host reports the metrics of the RPCs it
+// handled at /host/metrics and /host/metrics/prometheus.
+func TestHostMetrics(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// The renter's workers should update their price tables.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hmg, err := h.HostMetricsGet()
+		if err != nil {
+			return err
+		}
+		for _, rpc := range hmg.RPCs {
+			if rpc.Name == modules.RPCUpdatePriceTable.String() && rpc.Calls > 0 {
+				return nil
+			}
+		}
+		return errors.New("no price table updates recorded")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The metrics should be exported in the Prometheus format.
+	metrics, err := h.HostMetricsPrometheusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE sia_host_rpc_calls_total counter",
+		`sia_host_rpc_duration_seconds_bucket{rpc="UpdatePriceTable",le="+Inf"}`,
+		"# TYPE sia_host_instruction_duration_seconds histogram",
+		`sia_host_legacy_calls_total{rpc="settings"}`,
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Fatalf("metrics don't contain %q:\n%v", line, metrics)
+		}
+	}
+}
+
+// TestHostMDMTraces verifies that the host reports the execution traces of
+// sampled MDM programs at /host/mdm/traces.
+func TestHostMDMTraces(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// Trace all programs on both hosts.
+	for _, h := range tg.Hosts() {
+		err = h.HostModifySettingPost(client.HostParamMDMTraceSampleRate, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := tg.Hosts()[0]
+	hg, err := h.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hg.InternalSettings.MDMTraceSampleRate != 1 {
+		t.Fatal("setting wasn't updated", hg.InternalSettings.MDMTraceSampleRate)
+	}
+
+	// Upload and download a file to execute some programs.
+	_, rf, err := r.UploadNewFileBlocking(100, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = r.DownloadByStream(rf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The host should report the traces of the programs.
+	var traces []modules.HostMDMTrace
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hmtg, err := h.HostMDMTracesGet(nil, nil, 0)
+		if err != nil {
+			return err
+		}
+		if len(hmtg.Traces) == 0 {
+			return errors.New("no traces reported")
+		}
+		traces = hmtg.Traces
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := traces[0]
+	if len(trace.Instructions) == 0 || trace.Instructions[0].Name == "" {
+		t.Fatal("trace doesn't contain any instructions", trace)
+	}
+
+	// The traces can be filtered and limited.
+	hmtg, err := h.HostMDMTracesGet(nil, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hmtg.Traces) != 1 {
+		t.Fatal("expected a single trace", len(hmtg.Traces))
+	}
+	if trace.ClientType == modules.HostClientTypeAccount {
+		var id modules.AccountID
+		if err := id.LoadString(trace.ClientKey.String()); err != nil {
+			t.Fatal(err)
+		}
+		hmtg, err = h.HostMDMTracesGet(nil, &id, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hmtg.Traces) == 0 {
+			t.Fatal("expected traces for the account")
+		}
+		for _, tr := range hmtg.Traces {
+			if !tr.ClientKey.Equals(trace.ClientKey) {
+				t.Fatal("trace of a different client returned", tr.ClientKey)
+			}
+		}
+	}
+	randomKey := types.Ed25519PublicKey(crypto.PublicKey{1, 2, 3})
+	hmtg, err = h.HostMDMTracesGet(&randomKey, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hmtg.Traces) != 0 {
+		t.Fatal("expected no traces for an unknown renter", len(hmtg.Traces))
+	}
+}
+
+// TestHostAccounts verifies that the host's ephemeral accounts can be listed,
+// refunded and zeroed through the API.
+func TestHostAccounts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// The renter's workers should fund an account on the host.
+	var funded modules.HostAccount
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hag, err := h.HostAccountsGet()
+		if err != nil {
+			return err
+		}
+		if len(hag.Accounts) == 0 || hag.Accounts[0].Balance.IsZero() {
+			return errors.New("no funded accounts reported")
+		}
+		if hag.MaxRisk.IsZero() || hag.TotalBalance.IsZero() {
+			return errors.New("aggregate risk not reported")
+		}
+		funded = hag.Accounts[0]
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Zero the funded account.
+	var id modules.AccountID
+	id.FromSPK(funded.ID)
+	hazp, err := h.HostAccountsZeroPost(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hazp.Balance.IsZero() {
+		t.Fatal("expected the account to have a balance before it was zeroed")
+	}
+
+	// Refund a new account.
+	newID, _ := modules.NewAccountID()
+	amount := types.SiacoinPrecision
+	err = h.HostAccountsRefundPost(newID, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hag, err := h.HostAccountsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, acc := range hag.Accounts {
+		if acc.ID.Equals(newID.SPK()) {
+			found = acc.Balance.Equals(amount)
+		}
+	}
+	if !found {
+		t.Fatal("refunded account not found", hag.Accounts)
+	}
+
+	// Zeroing an unknown account should fail.
+	unknownID, _ := modules.NewAccountID()
+	_, err = h.HostAccountsZeroPost(unknownID)
+	if err == nil || !strings.Contains(err.Error(), host.ErrAccountNotFound.Error()) {
+		t.Fatal("expected ErrAccountNotFound, got", err)
+	}
+}
+
+// TestHostRegistryAPI verifies that the host's registry can be paged through,
+// pruned, exported and imported through the API.
+func TestHostRegistryAPI(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:  2,
+		Miners: 1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	hosts := tg.Hosts()
+	src, dst := hosts[0], hosts[1]
+	for _, h := range hosts {
+		err = h.HostModifySettingPost(client.HostParamRegistrySize, 64*modules.RegistryEntrySize)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The registry should be empty.
+	hrg, err := src.HostRegistryGet(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hrg.Entries) != 0 || hrg.Total != 0 {
+		t.Fatal("expected an empty registry", hrg)
+	}
+	var tweak crypto.Hash
+	_, err = src.HostRegistryEntryGet(types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: make([]byte, crypto.PublicKeySize)}, tweak)
+	if err == nil || !strings.Contains(err.Error(), "registry entry not found") {
+		t.Fatal("expected entry not to be found", err)
+	}
+
+	// Prune the registry.
+	hrpp, err := src.HostRegistryPrunePost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrpp.Pruned != 0 {
+		t.Fatal("expected no pruned entries", hrpp.Pruned)
+	}
+
+	// Create an export with some entries and import it into the first host.
+	// The registry API doesn't allow for adding entries directly.
+	numEntries := 3
+	var entries []modules.HostRegistryEntry
+	r, err := registry.New(filepath.Join(testDir, "registry"), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < numEntries; i++ {
+		sk, pk := crypto.GenerateKeyPair()
+		spk := types.Ed25519PublicKey(pk)
+		var tweak crypto.Hash
+		fastrand.Read(tweak[:])
+		rv := modules.NewRegistryValue(tweak, fastrand.Bytes(10), uint64(i)).Sign(sk)
+		expiry := types.BlockHeight(1000 + i)
+		if _, err := r.Update(rv, spk, expiry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, modules.HostRegistryEntry{
+			EntryID:   crypto.Hash(modules.DeriveRegistryEntryID(spk, tweak)),
+			PublicKey: spk,
+			Tweak:     tweak,
+			Data:      rv.Data,
+			Revision:  rv.Revision,
+			Signature: rv.Signature[:],
+			Expiry:    expiry,
+		})
+	}
+	seedPath := filepath.Join(testDir, "seed.export")
+	f, err := os.Create(seedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Export(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	hrip, err := src.HostRegistryImportPost(seedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrip.Imported != uint64(numEntries) {
+		t.Fatalf("expected %v imported entries but got %v", numEntries, hrip.Imported)
+	}
+
+	// Export the registry of the first host and import it into the other
+	// host.
+	path := filepath.Join(src.Dir, "registry.export")
+	hrep, err := src.HostRegistryExportPost(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrep.Exported != uint64(numEntries) {
+		t.Fatalf("expected %v exported entries but got %v", numEntries, hrep.Exported)
+	}
+	hrip, err = dst.HostRegistryImportPost(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrip.Imported != uint64(numEntries) {
+		t.Fatalf("expected %v imported entries but got %v", numEntries, hrip.Imported)
+	}
+
+	// Every entry should be available on the other host.
+	hrg, err = dst.HostRegistryGet(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrg.Total != uint64(numEntries) {
+		t.Fatalf("expected %v entries but got %v", numEntries, hrg.Total)
+	}
+	for _, entry := range entries {
+		hreg, err := dst.HostRegistryEntryGet(entry.PublicKey, entry.Tweak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(hreg.HostRegistryEntry, entry) {
+			t.Fatal("entry mismatch", hreg.HostRegistryEntry, entry)
+		}
+	}
+
+	// Importing the same entries again shouldn't import anything.
+	hrip, err = dst.HostRegistryImportPost(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrip.Imported != 0 {
+		t.Fatal("expected no imported entries", hrip.Imported)
+	}
+
+	// Relative paths should be rejected.
+	_, err = src.HostRegistryExportPost("registry.export")
+	if err == nil {
+		t.Fatal("expected relative path to be rejected")
+	}
+}
+
+// TestHostDecommission tests decommissioning a host through the API.
+func TestHostDecommission(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// The host isn't decommissioning.
+	hdg, err := h.HostDecommissionGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdg.Active {
+		t.Fatal("host shouldn't be decommissioning")
+	}
+	err = h.HostDecommissionCancelPost()
+	if err == nil {
+		t.Fatal("expected cancelling to fail")
+	}
+
+	// Decommission the host.
+	err = h.HostDecommissionPost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hg, err := h.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := hg.DecommissionStatus
+	if !ds.Active || hg.ExternalSettings.AcceptingContracts {
+		t.Fatal("host should be decommissioning", ds)
+	}
+	cg, err := h.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.EarliestSafeShutdownHeight < cg.Height || ds.EarliestSafeShutdownHeight < ds.LastObligationHeight {
+		t.Fatal("wrong safe shutdown height", ds)
+	}
+	if len(ds.UnprovableObligations) != 0 {
+		t.Fatal("expected all obligations to be provable", ds.UnprovableObligations)
+	}
+
+	// The status should be updated with new blocks.
+	err = tg.Miners()[0].MineBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hdg, err := h.HostDecommissionGet()
+		if err != nil {
+			return err
+		}
+		if hdg.EarliestSafeShutdownHeight < cg.Height+1 {
+			return errors.New("status wasn't updated")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancel decommissioning.
+	err = h.HostDecommissionCancelPost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hg, err = h.HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hg.DecommissionStatus.Active || !hg.ExternalSettings.AcceptingContracts {
+		t.Fatal("host should no longer be decommissioning")
+	}
+}
+
+// TestHostContractForecast tests the /host/contracts/forecast endpoint.
+func TestHostContractForecast(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := tg.Hosts()[0]
+
+	// A forecast over zero blocks isn't possible.
+	_, err = h.HostContractForecastGet(0)
+	if err == nil {
+		t.Fatal("expected forecast over zero blocks to fail")
+	}
+
+	// Get a forecast.
+	cg, err := h.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := types.BlocksPerMonth
+	hcfg, err := h.HostContractForecastGet(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hcfg.StartHeight != cg.Height || hcfg.EndHeight != cg.Height+blocks {
+		t.Fatal("wrong forecast heights", hcfg.StartHeight, hcfg.EndHeight)
+	}
+
+	// The proof windows should be sorted and within the forecast.
+	revenue := types.ZeroCurrency
+	for i, pw := range hcfg.ProofWindows {
+		if pw.WindowStart > hcfg.EndHeight || pw.WindowEnd < hcfg.StartHeight {
+			t.Fatal("proof window outside of forecast", pw)
+		}
+		if i > 0 && pw.WindowStart < hcfg.ProofWindows[i-1].WindowStart {
+			t.Fatal("proof windows aren't sorted")
+		}
+		revenue = revenue.Add(pw.ExpectedRevenue)
+	}
+	if !revenue.Equals(hcfg.ExpectedRevenue) {
+		t.Fatal("wrong expected revenue", revenue, hcfg.ExpectedRevenue)
+	}
+	if hcfg.WalletBalance.IsZero() {
+		t.Fatal("expected wallet balance to be set")
+	}
+}
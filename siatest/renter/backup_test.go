@@ -13,6 +13,7 @@ import (
 	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter"
 	"go.sia.tech/siad/modules/renter/filesystem"
 	"go.sia.tech/siad/node"
 	"go.sia.tech/siad/siatest"
@@ -596,6 +597,218 @@ func TestRemoteBackup(t *testing.T) {
 	}
 }
 
+// TestRenterRecovery tests that a renter can recover its contracts and files
+// from its seed using the /renter/recovery endpoint.
+func TestRenterRecovery(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a testgroup.
+	groupParams := siatest.GroupParams{
+		Hosts:   5,
+		Miners:  1,
+		Renters: 1,
+	}
+	testDir := renterTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Upload a file and back it up.
+	r := tg.Renters()[0]
+	_, rf, err := r.UploadNewFileBlocking(int(20e3), 2, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenterCreateBackupPost("foo"); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(60, time.Second, func() error {
+		ubs, err := r.RenterBackups()
+		if err != nil {
+			return err
+		}
+		if len(ubs.Backups) != 1 || ubs.Backups[0].UploadProgress != 100 {
+			return fmt.Errorf("backup not uploaded: %v", ubs.Backups)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the renter with a new renter using the same seed.
+	wsg, err := r.WalletSeedsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.RemoveNode(r); err != nil {
+		t.Fatal(err)
+	}
+	renterParams := node.Renter(filepath.Join(testDir, "renter"))
+	renterParams.PrimarySeed = wsg.PrimarySeed
+	nodes, err := tg.AddNodes(renterParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = nodes[0]
+
+	// Recover the renter.
+	if err := r.RenterRecoveryPost(); err != nil {
+		t.Fatal(err)
+	}
+	var rrs modules.RenterRecoveryStatus
+	err = build.Retry(120, time.Second, func() error {
+		rrs, err = r.RenterRecoveryGet()
+		if err != nil {
+			return err
+		}
+		if rrs.Stage != modules.RecoveryStageDone && rrs.Stage != modules.RecoveryStageFailed {
+			return fmt.Errorf("recovery not done: %v", rrs.Stage)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rrs.Stage != modules.RecoveryStageDone {
+		t.Fatal("recovery failed", rrs.Error)
+	}
+	if rrs.Backup.Name != "foo" || rrs.RestoredFiles != 1 || len(rrs.FailedFiles) != 0 {
+		t.Fatal("unexpected recovery status", rrs)
+	}
+	if rrs.Contracts == 0 || rrs.HostsQueried != rrs.Contracts || rrs.BackupsFound != 1 {
+		t.Fatal("unexpected recovery progress", rrs)
+	}
+
+	// The file should be downloadable.
+	if _, _, err := r.DownloadToDisk(rf, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestScheduledBackups tests that the renter periodically creates backups
+// according to its backup schedule and deletes the backups that fall out of
+// retention from its hosts.
+func TestScheduledBackups(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a testgroup.
+	groupParams := siatest.GroupParams{
+		Hosts:   3,
+		Miners:  1,
+		Renters: 1,
+	}
+	testDir := renterTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Set the backup schedule.
+	r := tg.Renters()[0]
+	schedule := modules.BackupSchedule{
+		Interval: 5 * time.Second,
+		KeepLast: 2,
+	}
+	if err := r.RenterSetBackupSchedulePost(schedule); err != nil {
+		t.Fatal(err)
+	}
+	rg, err := r.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Settings.BackupSchedule != schedule {
+		t.Fatal("schedule wasn't set", rg.Settings.BackupSchedule)
+	}
+
+	// Wait for multiple scheduled backups to be created and for the first one
+	// to be pruned.
+	var first string
+	err = build.Retry(120, time.Second, func() error {
+		ubs, err := r.RenterBackups()
+		if err != nil {
+			return err
+		}
+		finished := 0
+		found := false
+		for _, ub := range ubs.Backups {
+			if !strings.HasPrefix(ub.Name, "auto-") {
+				return fmt.Errorf("unexpected backup %v", ub.Name)
+			}
+			if first == "" {
+				first = ub.Name
+			}
+			if ub.Name == first {
+				found = true
+			}
+			if ub.UploadProgress == 100 {
+				finished++
+			}
+		}
+		if first == "" || found {
+			return errors.New("first scheduled backup wasn't pruned yet")
+		}
+		if finished > int(schedule.KeepLast) {
+			return fmt.Errorf("too many finished backups: %v", finished)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pruned backup should be removed from the hosts.
+	rc, err := r.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(60, time.Second, func() error {
+		for _, c := range rc.ActiveContracts {
+			ubs, err := r.RenterBackupsOnHost(c.HostPublicKey)
+			if err != nil {
+				return err
+			}
+			for _, ub := range ubs.Backups {
+				if ub.Name == first {
+					return fmt.Errorf("pruned backup still stored on host %v", c.HostPublicKey)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The renter shouldn't have an overdue backup alert.
+	dag, err := r.DaemonAlertsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alert := range dag.Alerts {
+		if alert.Msg == renter.AlertMSGBackupOverdue {
+			t.Fatal("unexpected alert", alert)
+		}
+	}
+}
+
 // TestBackupRenew tests that a backup can be restored after a set of contract
 // has been renewed.
 func TestBackupRenew(t *testing.T) {
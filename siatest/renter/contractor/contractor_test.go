@@ -404,6 +404,26 @@ func TestRenterContracts(t *testing.T) {
 		}
 	}
 
+	// Confirm that all active contracts are listed with their host and a
+	// pending proof outcome.
+	rcs, err := r.RenterContractStatusesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[types.FileContractID]modules.ContractWatchStatus)
+	for _, status := range rcs.Contracts {
+		statuses[status.ContractID] = status
+	}
+	for _, c := range rc.ActiveContracts {
+		status, ok := statuses[c.ID]
+		if !ok {
+			t.Fatal("contract missing from contract statuses", c.ID)
+		}
+		if !status.HostPublicKey.Equals(c.HostPublicKey) || status.ProofOutcome != modules.ProofOutcomePending {
+			t.Fatal("wrong contract status", status)
+		}
+	}
+
 	// Record original Contracts and create Maps for comparison
 	originalContracts := rc.ActiveContracts
 	originalContractIDMap := make(map[types.FileContractID]struct{})
@@ -969,3 +969,246 @@ func TestWalletVerifyPassword(t *testing.T) {
 		t.Error("Password should not be valid")
 	}
 }
+
+// TestMultisigWallet tests spending the funds of a 2-of-3 multisig address
+// whose keys are owned by two different wallets and a third party.
+func TestMultisigWallet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup with two miners.
+	groupParams := siatest.GroupParams{
+		Miners: 2,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miners := tg.Miners()
+	miner1, miner2 := miners[0], miners[1]
+
+	// Get a public key of both wallets.
+	publicKey := func(tn *siatest.TestNode) types.SiaPublicKey {
+		wag, err := tn.WalletAddressGet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wucg, err := tn.WalletUnlockConditionsGet(wag.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wucg.UnlockConditions.PublicKeys[0]
+	}
+	_, pk := crypto.GenerateKeyPair()
+	keys := []types.SiaPublicKey{publicKey(miner1), publicKey(miner2), types.Ed25519PublicKey(pk)}
+
+	// Create the multisig address on the first miner and fund it.
+	wma, err := miner1.WalletMultisigPost(keys, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wmg, err := miner1.WalletMultisigGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wmg.Addresses) != 1 || wmg.Addresses[0].Address != wma.Address {
+		t.Fatal("multisig address isn't tracked", wmg.Addresses)
+	}
+	funds := types.SiacoinPrecision.Mul64(1000)
+	if _, err := miner1.WalletSiacoinsPost(funds, wma.Address, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := miner1.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a transaction which sends coins to the second miner.
+	dest, err := miner2.WalletAddressGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	wmtp, err := miner1.WalletMultisigCreatePost(wma.Address, []types.SiacoinOutput{{Value: amount, UnlockHash: dest.Address}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pst := wmtp.Transaction
+
+	// Both miners sign a copy of the transaction.
+	wmtp, err = miner1.WalletMultisigSignPost(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed1 := wmtp.Transaction
+	wmtp, err = miner2.WalletMultisigSignPost(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed2 := wmtp.Transaction
+	if signed1.Complete() || signed2.Complete() {
+		t.Fatal("a single signature shouldn't complete the transaction")
+	}
+
+	// Broadcasting a copy with a single signature should fail.
+	_, err = miner1.WalletMultisigBroadcastPost(signed1)
+	if err == nil || !strings.Contains(err.Error(), modules.ErrMultisigTransactionIncomplete.Error()) {
+		t.Fatal("expected ErrMultisigTransactionIncomplete", err)
+	}
+
+	// Combine and broadcast the copies.
+	wmtp, err = miner2.WalletMultisigCombinePost([]modules.PartiallySignedTransaction{signed1, signed2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wmtp.Transaction.Complete() {
+		t.Fatal("combined transaction should be complete", wmtp.Transaction.Inputs)
+	}
+	wmbp, err := miner2.WalletMultisigBroadcastPost(wmtp.Transaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wmbp.TransactionID != wmtp.Transaction.Transaction.ID() {
+		t.Fatal("wrong transaction ID")
+	}
+	if err := miner2.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second miner should have received the coins and the multisig
+	// address should own the change.
+	wug, err := miner2.WalletUnspentGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received bool
+	for _, o := range wug.Outputs {
+		received = received || (o.UnlockHash == dest.Address && o.Value.Equals(amount))
+	}
+	if !received {
+		t.Fatal("second miner didn't receive the coins")
+	}
+	wug, err = miner1.WalletUnspentGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var balance types.Currency
+	for _, o := range wug.Outputs {
+		if o.UnlockHash == wma.Address {
+			balance = balance.Add(o.Value)
+		}
+	}
+	expected := funds.Sub(amount).Sub(wmtp.Transaction.Transaction.MinerFees[0])
+	if !balance.Equals(expected) {
+		t.Fatalf("expected multisig balance %v but was %v", expected, balance)
+	}
+}
+
+// TestWalletTransactionBump tests bumping the fee of unconfirmed transactions
+// through the API with both CPFP and RBF.
+func TestWalletTransactionBump(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup with a single miner.
+	groupParams := siatest.GroupParams{
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	miner := tg.Miners()[0]
+
+	fees, err := miner.TransactionPoolFeeGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feePerByte := fees.Maximum.Mul64(10)
+
+	// send is a helper that sends coins to the void and returns the id of the
+	// transaction.
+	send := func() types.TransactionID {
+		wsp, err := miner.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(100), types.UnlockHash{}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wsp.TransactionIDs[len(wsp.TransactionIDs)-1]
+	}
+	// inPool is a helper that returns true if the transaction pool contains
+	// the transaction.
+	inPool := func(id types.TransactionID) bool {
+		tptg, err := miner.TransactionPoolTransactionsGet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, txn := range tptg.Transactions {
+			if txn.ID() == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	// An invalid method should be rejected.
+	txid := send()
+	if _, err := miner.WalletTransactionBumpPost(txid, "foo", feePerByte); err == nil {
+		t.Fatal("expected error for invalid method")
+	}
+
+	// Bump the fee with a child.
+	wtbp, err := miner.WalletTransactionBumpPost(txid, modules.FeeBumpCPFP, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := wtbp.TransactionIDs[len(wtbp.TransactionIDs)-1]
+	if !inPool(txid) || !inPool(child) {
+		t.Fatal("transaction or child is missing from the transaction pool")
+	}
+
+	// Bump the fee of another transaction by replacing it.
+	txid = send()
+	wtbp, err = miner.WalletTransactionBumpPost(txid, modules.FeeBumpRBF, feePerByte)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replacement := wtbp.TransactionIDs[len(wtbp.TransactionIDs)-1]
+	if inPool(txid) || !inPool(replacement) {
+		t.Fatal("transaction wasn't replaced in the transaction pool")
+	}
+
+	// Both transactions should be confirmed by the next block.
+	if err := miner.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	wtg, err := miner.WalletTransactionsGet(0, math.MaxUint64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed := make(map[types.TransactionID]struct{})
+	for _, pt := range wtg.ConfirmedTransactions {
+		confirmed[pt.TransactionID] = struct{}{}
+	}
+	for _, id := range []types.TransactionID{child, replacement} {
+		if _, exists := confirmed[id]; !exists {
+			t.Fatal("transaction wasn't confirmed", id)
+		}
+	}
+}
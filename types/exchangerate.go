@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math/big"
 	"regexp"
+	"strconv"
 )
 
 type (
@@ -92,3 +93,26 @@ func (r *ExchangeRate) ApplyAndFormat(c Currency) string {
 	result = fmt.Sprintf("~ %s %s", result, r.staticSymbol)
 	return result
 }
+
+// Invert returns the amount of currency which is worth the provided amount of
+// the exchange rate's symbol, rounded down to the nearest hasting. Negative
+// amounts return zero.
+func (r *ExchangeRate) Invert(amount float64) Currency {
+	if amount <= 0 {
+		return ZeroCurrency
+	}
+	// use the shortest decimal representations to avoid binary rounding
+	// errors, e.g. for a rate of 0.01
+	asRatio, _ := new(big.Rat).SetString(r.staticValue.Text('g', -1))
+	amountRat, _ := new(big.Rat).SetString(strconv.FormatFloat(amount, 'g', -1, 64))
+	precisionRat := new(big.Rat).SetInt(SiacoinPrecision.Big())
+
+	// calculate (amountRat * precisionRat) / asRatio
+	resultRat := new(big.Rat).Quo(new(big.Rat).Mul(amountRat, precisionRat), asRatio)
+	return NewCurrency(new(big.Int).Quo(resultRat.Num(), resultRat.Denom()))
+}
+
+// Symbol returns the symbol of the exchange rate.
+func (r *ExchangeRate) Symbol() string {
+	return r.staticSymbol
+}
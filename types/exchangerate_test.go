@@ -122,3 +122,34 @@ func TestApplyAndFormat(t *testing.T) {
 		}
 	}
 }
+
+// TestInvert checks that an amount of fiat is correctly converted to currency.
+func TestInvert(t *testing.T) {
+	mustParse := func(s string) *ExchangeRate {
+		rate, err := ParseExchangeRate(s)
+		if err != nil {
+			t.Fatalf("test case uses invalid exchange rate: %v", err)
+		}
+
+		return rate
+	}
+	tests := []struct {
+		rate   *ExchangeRate
+		amount float64
+		result Currency
+	}{
+		{mustParse("1 USD"), 1, SiacoinPrecision},
+		{mustParse("1 USD"), 0.5, SiacoinPrecision.Div64(2)},
+		{mustParse("0.01 USD"), 1, SiacoinPrecision.Mul64(100)},
+		{mustParse("2 EUR"), 10, SiacoinPrecision.Mul64(5)},
+		{mustParse("1 USD"), 0, ZeroCurrency},
+		{mustParse("1 USD"), -1, ZeroCurrency},
+	}
+	for _, test := range tests {
+		result := test.rate.Invert(test.amount)
+		if !test.result.Equals(result) {
+			t.Errorf("TestInvert with %v %v and %v: expected %v, got %v",
+				test.rate.staticValue, test.rate.staticSymbol, test.amount, test.result, result)
+		}
+	}
+}
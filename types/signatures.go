@@ -401,6 +401,13 @@ func (t Transaction) validCoveredFields() error {
 	return nil
 }
 
+// ValidCoveredFields returns an error if the covered fields of any of the
+// transaction's signatures don't follow the rules. It allows checking the
+// signatures of transactions that aren't fully signed yet.
+func (t Transaction) ValidCoveredFields() error {
+	return t.validCoveredFields()
+}
+
 // validSignatures checks the validaty of all signatures in a transaction.
 func (t *Transaction) validSignatures(currentHeight BlockHeight) error {
 	// Check that all covered fields objects follow the rules.